@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -11,14 +12,22 @@ import (
 	"time"
 
 	_ "github.com/williamkoller/golang-domain-driven-design/docs"
+	category_repository "github.com/williamkoller/golang-domain-driven-design/internal/domain/category/repository"
+	product_events "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/events"
 	product_repository "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/repository"
+	webhook_repository "github.com/williamkoller/golang-domain-driven-design/internal/domain/webhook/repository"
 	product_handlers "github.com/williamkoller/golang-domain-driven-design/internal/infra/http/handlers"
+	http_middleware "github.com/williamkoller/golang-domain-driven-design/internal/infra/http/middleware"
 	product_router "github.com/williamkoller/golang-domain-driven-design/internal/infra/http/router"
+	http_sse "github.com/williamkoller/golang-domain-driven-design/internal/infra/http/sse"
 	"github.com/williamkoller/golang-domain-driven-design/internal/infra/persistence"
+	webhook_notifier "github.com/williamkoller/golang-domain-driven-design/internal/infra/webhook"
 	"github.com/williamkoller/golang-domain-driven-design/internal/metrics"
 	"github.com/williamkoller/golang-domain-driven-design/internal/shared/config"
 	"github.com/williamkoller/golang-domain-driven-design/internal/shared/database"
 	shared_events "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/events"
+	shared_idempotency "github.com/williamkoller/golang-domain-driven-design/internal/shared/idempotency"
+	shared_jobs "github.com/williamkoller/golang-domain-driven-design/internal/shared/jobs"
 )
 
 //	@title			Servidor HTTP com Domain Driven Design
@@ -54,27 +63,89 @@ func main() {
 	log.Println("✅ Conectado ao banco de dados PostgreSQL")
 
 	// Inicializar componentes
-	dispatcher := shared_events.NewEventDispatcher()
+	m := metrics.NewMetrics()
+	dispatcher := newEventDispatcher(cfg.Events, m)
+	if cfg.Server.GinMode == "debug" {
+		dispatcher.SetDebugLogger(log.New(os.Stdout, "[events] ", log.LstdFlags))
+	}
+
+	// Métricas de negócio mantidas a partir dos eventos de produto
+	dispatcher.RegisterProjection("product.*", metrics.NewBusinessProjection(m))
 
 	// Usar repositório PostgreSQL ao invés de in-memory
-	var repo product_repository.IProductRepository
+	var (
+		repo         product_repository.IProductRepository
+		categoryRepo category_repository.ICategoryRepository
+		webhookRepo  webhook_repository.IWebhookRepository
+		relay        *persistence.OutboxRelay
+		idemStore    shared_idempotency.Store
+		jobStore     shared_jobs.Store
+	)
 	if db != nil {
-		repo = persistence.NewPostgresProductRepository(db)
+		repo = persistence.NewPostgresProductRepositoryWithTimeouts(db, queryTimeouts(cfg.Database))
+		categoryRepo = persistence.NewPostgresCategoryRepositoryWithTimeouts(db, queryTimeouts(cfg.Database))
+		webhookRepo = persistence.NewPostgresWebhookRepositoryWithTimeouts(db, queryTimeouts(cfg.Database))
+		idemStore = persistence.NewPostgresIdempotencyStore(db)
+		jobStore = persistence.NewPostgresJobStore(db)
 		log.Println("📊 Usando repositório PostgreSQL")
+
+		registry := product_events.NewEventRegistry()
+		webhook_notifier.RegisterEvents(registry)
+		dispatcher.SetEventStore(persistence.NewPostgresEventStoreWithTimeouts(db, registry, queryTimeouts(cfg.Database)))
+		dispatcher.SetDeadLetterStore(persistence.NewPostgresDeadLetterStore(db, registry))
+		rebuildProjections(dispatcher)
+
+		// Eventos gravados no outbox são entregues ao dispatcher em segundo plano
+		relay = persistence.NewOutboxRelay(db, dispatcher, registry, persistence.DefaultOutboxRelayConfig())
+		relay.Start()
+		log.Println("📬 Relay do outbox iniciado")
 	} else {
-		repo = product_repository.NewRepository()
+		// As renomeações e fusões de categorias são aplicadas aos produtos in-memory
+		products := product_repository.NewRepositoryWithDispatcher(dispatcher)
+		repo = products
+		categoryRepo = category_repository.NewRepository(products)
+		webhookRepo = webhook_repository.NewRepository()
+		idemStore = shared_idempotency.NewInMemoryStore()
+		jobStore = shared_jobs.NewInMemoryStore()
+		dispatcher.SetEventStore(shared_events.NewInMemoryEventStore())
 		log.Println("💾 Usando repositório in-memory")
 	}
 
-	m := metrics.NewMetrics()
+	// Eventos de produto entregues aos webhooks inscritos, em segundo plano
+	notifier := webhook_notifier.NewNotifier(webhookRepo, webhookConfig(cfg.Webhooks))
+	notifier.Subscribe(dispatcher, "product.*")
 
-	productHandler := product_handlers.NewProductHandler(repo, dispatcher, m)
+	// Eventos de produto transmitidos aos clientes de /api/v1/events/stream
+	broker := http_sse.NewBroker(http_sse.DefaultBufferSize, http_sse.DefaultSubscriberSize)
+	dispatcher.Register("product.*", broker.Handle)
 
-	r := product_router.SetupProductRouter(productHandler, m)
+	productHandler := product_handlers.NewProductHandler(repo, m)
+	productHandler.SetCategories(categoryRepo, cfg.Categories.Strict)
+	streamHandler := product_handlers.NewEventStreamHandler(broker, product_handlers.DefaultHeartbeatInterval)
+
+	// Reenvios das rotas de criação com o mesmo Idempotency-Key recebem a resposta original
+	idempotency := http_middleware.Idempotency(idemStore, time.Duration(cfg.Idempotency.TTLSeconds)*time.Second)
+
+	r := product_router.SetupProductRouter(productHandler, streamHandler, idempotency, m)
+	product_router.SetupAdminRoutes(r, product_handlers.NewEventAdminHandler(dispatcher))
+	product_router.SetupWebhookRoutes(r, product_handlers.NewWebhookHandler(webhookRepo), idempotency)
+	product_router.SetupCategoryRoutes(r, product_handlers.NewCategoryHandler(categoryRepo, repo), idempotency)
+
+	// Importações, exportações e reindexações executadas em segundo plano
+	jobs := shared_jobs.NewPool(jobStore, jobsConfig(cfg.Jobs))
+	productJobHandler := product_handlers.NewProductJobHandler(productHandler, jobs, cfg.Jobs.ResultDir)
+	product_router.SetupJobRoutes(r, product_handlers.NewJobHandler(jobs), productJobHandler, idempotency)
+	jobs.Start()
+	log.Printf("🧵 Jobs em segundo plano: %d workers, resultados em %s", cfg.Jobs.Workers, cfg.Jobs.ResultDir)
+
+	// Contexto base das requisições: cancelado se o shutdown estourar o timeout,
+	// interrompendo as consultas ao banco ainda em andamento
+	baseCtx, cancelRequests := context.WithCancel(context.Background())
 
 	server := &http.Server{
-		Addr:    ":" + cfg.Server.Port,
-		Handler: r,
+		Addr:        ":" + cfg.Server.Port,
+		Handler:     r,
+		BaseContext: func(net.Listener) context.Context { return baseCtx },
 	}
 
 	go func() {
@@ -88,10 +159,66 @@ func main() {
 		}
 	}()
 
-	GracefulShutdown(server, db, 5*time.Second)
+	GracefulShutdown(server, cancelRequests, broker, jobs, relay, notifier, dispatcher, db, 5*time.Second)
+}
+
+// webhookConfig monta a configuração do notifier a partir das variáveis de ambiente
+func webhookConfig(cfg config.WebhooksConfig) webhook_notifier.Config {
+	webhooks := webhook_notifier.DefaultConfig()
+	webhooks.Timeout = time.Duration(cfg.TimeoutSeconds) * time.Second
+	webhooks.Retry.MaxAttempts = cfg.MaxAttempts
+	return webhooks
+}
+
+// jobsConfig monta a configuração do pool de jobs a partir das variáveis de ambiente
+func jobsConfig(cfg config.JobsConfig) shared_jobs.PoolConfig {
+	jobs := shared_jobs.DefaultPoolConfig()
+	jobs.Workers = cfg.Workers
+	jobs.Retention = time.Duration(cfg.RetentionHours) * time.Hour
+	return jobs
+}
+
+// queryTimeouts monta os timeouts das consultas de produtos a partir das variáveis de ambiente
+func queryTimeouts(cfg config.DatabaseConfig) persistence.QueryTimeouts {
+	return persistence.QueryTimeouts{
+		Read:  time.Duration(cfg.ReadTimeoutMs) * time.Millisecond,
+		Write: time.Duration(cfg.WriteTimeoutMs) * time.Millisecond,
+	}
 }
 
-func GracefulShutdown(server *http.Server, db interface{ Close() error }, timeout time.Duration) {
+// rebuildProjections reconstrói as projeções a partir do histórico de eventos antes de
+// a aplicação começar a receber requisições
+func rebuildProjections(dispatcher *shared_events.EventDispatcher) {
+	replayed, err := dispatcher.RebuildProjections(context.Background())
+	if err != nil {
+		log.Printf("❌ Erro ao reconstruir projeções: %v", err)
+		return
+	}
+	log.Printf("🔁 Projeções reconstruídas a partir de %d eventos", replayed)
+}
+
+// newEventDispatcher cria o dispatcher com pool de workers, ou sem limite quando Workers é 0
+func newEventDispatcher(cfg config.EventsConfig, m *metrics.Metrics) *shared_events.EventDispatcher {
+	if cfg.Workers <= 0 {
+		log.Println("⚡ Dispatcher de eventos sem limite de goroutines")
+		return shared_events.NewEventDispatcher()
+	}
+
+	policy, err := shared_events.ParseQueuePolicy(cfg.QueuePolicy)
+	if err != nil {
+		log.Fatalf("❌ EVENTS_QUEUE_POLICY inválida: %q", cfg.QueuePolicy)
+	}
+
+	log.Printf("⚡ Dispatcher de eventos: %d workers, fila de %d (%s)", cfg.Workers, cfg.QueueSize, policy)
+	return shared_events.NewEventDispatcherWithPool(shared_events.DispatcherConfig{
+		Workers:   cfg.Workers,
+		QueueSize: cfg.QueueSize,
+		Policy:    policy,
+		OnDrop:    m.IncrementEventsDropped,
+	})
+}
+
+func GracefulShutdown(server *http.Server, cancelRequests context.CancelFunc, broker *http_sse.Broker, jobs *shared_jobs.Pool, relay *persistence.OutboxRelay, notifier *webhook_notifier.Notifier, dispatcher *shared_events.EventDispatcher, db interface{ Close() error }, timeout time.Duration) {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 
@@ -101,13 +228,53 @@ func GracefulShutdown(server *http.Server, db interface{ Close() error }, timeou
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
+	// Encerrar os streams de eventos antes: server.Shutdown espera as conexões
+	// ativas terminarem, e um stream SSE só termina quando o broker o fecha
+	broker.Close()
+	log.Println("✅ Event streams closed")
+
 	// Shutdown HTTP server
 	if err := server.Shutdown(ctx); err != nil {
 		log.Printf("❌ Error during server shutdown: %v\n", err)
+
+		// Requisições que não terminaram a tempo: cancelar suas consultas e fechar as conexões
+		cancelRequests()
+		server.Close()
 	} else {
 		log.Println("✅ HTTP server shut down gracefully")
 	}
 
+	// Jobs interrompidos voltam para a fila e recomeçam na próxima execução
+	if err := jobs.Stop(ctx); err != nil {
+		log.Printf("❌ Error stopping job workers: %v\n", err)
+	} else {
+		log.Println("✅ Job workers stopped")
+	}
+
+	// Parar o relay do outbox antes de fechar o banco; eventos pendentes são entregues na próxima execução
+	if relay != nil {
+		if err := relay.Stop(ctx); err != nil {
+			log.Printf("❌ Error stopping outbox relay: %v\n", err)
+		} else {
+			log.Println("✅ Outbox relay stopped")
+		}
+	}
+
+	// Processar os eventos já aceitos pelo dispatcher antes de encerrar
+	if err := dispatcher.Shutdown(ctx); err != nil {
+		log.Printf("❌ Error draining event dispatcher: %v\n", err)
+	} else {
+		log.Println("✅ Event dispatcher drained")
+	}
+
+	// Depois do dispatcher, que ainda enfileira entregas; webhooks aguardando nova
+	// tentativa vão para a dead-letter store em vez de segurar o encerramento
+	if err := notifier.Stop(ctx); err != nil {
+		log.Printf("❌ Error stopping webhook deliveries: %v\n", err)
+	} else {
+		log.Println("✅ Webhook deliveries stopped")
+	}
+
 	// Close database connection
 	if db != nil {
 		if err := db.Close(); err != nil {
@@ -11,6 +11,28 @@ INSERT INTO categories (name) VALUES
     ('Tecnologia')
 ON CONFLICT (name) DO NOTHING;
 
+-- Hierarquia e descrições das categorias (colunas criadas na V15); categorias já
+-- reorganizadas pela API não são alteradas
+UPDATE categories c
+SET parent_id = parent.id
+FROM categories parent
+WHERE parent.name = 'Eletrônicos'
+  AND c.name IN ('Computadores', 'Smartphones', 'Periféricos')
+  AND c.parent_id IS NULL;
+
+UPDATE categories
+SET description = CASE name
+    WHEN 'Eletrônicos' THEN 'Aparelhos eletrônicos e acessórios'
+    WHEN 'Computadores' THEN 'Notebooks, desktops e componentes'
+    WHEN 'Smartphones' THEN 'Celulares e acessórios'
+    WHEN 'Periféricos' THEN 'Mouses, teclados, monitores e áudio'
+    WHEN 'Gaming' THEN 'Produtos para jogos'
+    WHEN 'Livros' THEN 'Livros impressos e digitais'
+    WHEN 'Tecnologia' THEN 'Livros e produtos sobre tecnologia'
+END
+WHERE name IN ('Eletrônicos', 'Computadores', 'Smartphones', 'Periféricos', 'Gaming', 'Livros', 'Tecnologia')
+  AND description = '';
+
 -- Inserir produtos de exemplo
 INSERT INTO products (name, sku, price) VALUES
     ('Notebook Dell Inspiron', 12345, 350000),  -- R$ 3.500,00
@@ -71,3 +93,6 @@ INSERT INTO product_categories (product_id, category_id)
 SELECT p.id, c.id FROM products p, categories c
 WHERE p.name = 'Memória RAM 16GB' AND c.name IN ('Computadores')
 ON CONFLICT DO NOTHING;
+
+-- Histórico de eventos dos produtos inseridos acima (função criada na V9)
+SELECT backfill_product_events();
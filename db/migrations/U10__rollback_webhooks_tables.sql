@@ -0,0 +1,5 @@
+-- Migration Rollback: Remover tabelas de webhooks e do log de entregas
+
+DROP INDEX IF EXISTS idx_webhook_deliveries_webhook_id;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhooks;
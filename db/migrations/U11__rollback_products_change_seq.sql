@@ -0,0 +1,11 @@
+-- Migration Rollback: Remover a sequência de alterações de produtos
+
+DROP INDEX IF EXISTS idx_products_change_seq;
+
+DROP TRIGGER IF EXISTS set_products_change_seq ON products;
+DROP FUNCTION IF EXISTS products_change_seq_trigger();
+
+ALTER TABLE products DROP COLUMN IF EXISTS created_seq;
+ALTER TABLE products DROP COLUMN IF EXISTS change_seq;
+
+DROP SEQUENCE IF EXISTS products_change_seq;
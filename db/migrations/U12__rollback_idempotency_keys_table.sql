@@ -0,0 +1,4 @@
+-- Migration Rollback: Remover tabela idempotency_keys
+
+DROP INDEX IF EXISTS idx_idempotency_keys_expires_at;
+DROP TABLE IF EXISTS idempotency_keys;
@@ -0,0 +1,5 @@
+-- Migration Rollback: Remover a versão dos produtos
+
+ALTER TABLE products DROP CONSTRAINT IF EXISTS chk_products_version;
+
+ALTER TABLE products DROP COLUMN IF EXISTS version;
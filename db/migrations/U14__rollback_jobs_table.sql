@@ -0,0 +1,6 @@
+-- Migration Rollback: Remover tabela de jobs assíncronos
+
+DROP INDEX IF EXISTS idx_jobs_finished_at;
+DROP INDEX IF EXISTS idx_jobs_running;
+DROP INDEX IF EXISTS idx_jobs_queued;
+DROP TABLE IF EXISTS jobs;
@@ -0,0 +1,19 @@
+-- Migration Rollback: Remover hierarquia, slug e descrição das categorias
+
+DROP INDEX IF EXISTS idx_categories_parent_id;
+DROP INDEX IF EXISTS idx_categories_slug;
+DROP INDEX IF EXISTS idx_categories_public_id;
+
+DROP TRIGGER IF EXISTS update_categories_updated_at ON categories;
+DROP TRIGGER IF EXISTS set_categories_slug ON categories;
+
+DROP FUNCTION IF EXISTS categories_slug_trigger();
+DROP FUNCTION IF EXISTS next_category_slug(TEXT);
+DROP FUNCTION IF EXISTS category_slug(TEXT);
+
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS chk_categories_parent;
+ALTER TABLE categories DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE categories DROP COLUMN IF EXISTS parent_id;
+ALTER TABLE categories DROP COLUMN IF EXISTS description;
+ALTER TABLE categories DROP COLUMN IF EXISTS slug;
+ALTER TABLE categories DROP COLUMN IF EXISTS public_id;
@@ -0,0 +1,4 @@
+-- Migration Rollback: Remover tabela dead_letters
+
+DROP INDEX IF EXISTS idx_dead_letters_failed_at;
+DROP TABLE IF EXISTS dead_letters;
@@ -0,0 +1,6 @@
+-- Migration Rollback: Voltar a reindexar produtos em qualquer UPDATE do nome da categoria
+
+DROP TRIGGER IF EXISTS refresh_categories_search_vector ON categories;
+
+CREATE TRIGGER refresh_categories_search_vector AFTER UPDATE OF name ON categories
+FOR EACH ROW EXECUTE FUNCTION categories_search_vector_trigger();
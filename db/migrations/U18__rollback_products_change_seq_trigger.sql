@@ -0,0 +1,6 @@
+-- Migration Rollback: Voltar a numerar no change feed qualquer UPDATE de produtos
+
+DROP TRIGGER IF EXISTS set_products_change_seq ON products;
+
+CREATE TRIGGER set_products_change_seq BEFORE INSERT OR UPDATE ON products
+FOR EACH ROW EXECUTE FUNCTION products_change_seq_trigger();
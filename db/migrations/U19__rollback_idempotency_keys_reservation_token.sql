@@ -0,0 +1,3 @@
+-- Migration Rollback: Remover o token de reserva das chaves de idempotência
+
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS reservation_token;
@@ -0,0 +1,5 @@
+-- Migration Rollback: Remover soft delete dos produtos
+
+DROP INDEX IF EXISTS idx_products_active;
+
+ALTER TABLE products DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,5 @@
+-- Migration Rollback: Remover identificador público dos produtos
+
+DROP INDEX IF EXISTS idx_products_public_id;
+
+ALTER TABLE products DROP COLUMN IF EXISTS public_id;
@@ -0,0 +1,11 @@
+-- Migration Rollback: Remover moeda do preço dos produtos
+
+DROP INDEX IF EXISTS idx_products_currency;
+
+ALTER TABLE products DROP CONSTRAINT IF EXISTS chk_products_currency;
+
+ALTER TABLE products DROP COLUMN IF EXISTS currency;
+
+ALTER TABLE products ALTER COLUMN price TYPE INTEGER;
+
+COMMENT ON COLUMN products.price IS 'Preço em centavos (evita problemas com ponto flutuante)';
@@ -0,0 +1,5 @@
+-- Migration Rollback: Remover índices da listagem paginada de produtos
+
+DROP INDEX IF EXISTS idx_products_name_public_id;
+DROP INDEX IF EXISTS idx_products_price_public_id;
+DROP INDEX IF EXISTS idx_products_created_at_public_id;
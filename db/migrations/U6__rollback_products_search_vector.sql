@@ -0,0 +1,14 @@
+-- Migration Rollback: Remover busca textual de produtos
+
+DROP INDEX IF EXISTS idx_products_search_vector;
+
+DROP TRIGGER IF EXISTS refresh_categories_search_vector ON categories;
+DROP TRIGGER IF EXISTS refresh_product_categories_search_vector ON product_categories;
+DROP TRIGGER IF EXISTS refresh_products_search_vector ON products;
+
+DROP FUNCTION IF EXISTS categories_search_vector_trigger();
+DROP FUNCTION IF EXISTS product_categories_search_vector_trigger();
+DROP FUNCTION IF EXISTS products_search_vector_trigger();
+DROP FUNCTION IF EXISTS refresh_product_search_vector(INTEGER);
+
+ALTER TABLE products DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,5 @@
+-- Migration Rollback: Remover tabela outbox
+
+DROP INDEX IF EXISTS idx_outbox_delivered_at;
+DROP INDEX IF EXISTS idx_outbox_pending;
+DROP TABLE IF EXISTS outbox;
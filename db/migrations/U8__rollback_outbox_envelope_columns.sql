@@ -0,0 +1,9 @@
+-- Migration Rollback: Remover metadados do envelope de eventos do outbox
+
+DROP INDEX IF EXISTS idx_outbox_event_id;
+
+ALTER TABLE outbox
+    DROP COLUMN IF EXISTS occurred_at,
+    DROP COLUMN IF EXISTS correlation_id,
+    DROP COLUMN IF EXISTS event_version,
+    DROP COLUMN IF EXISTS event_id;
@@ -0,0 +1,7 @@
+-- Migration Rollback: Remover tabela events
+
+DROP FUNCTION IF EXISTS backfill_product_events();
+
+DROP INDEX IF EXISTS idx_events_aggregate_id;
+DROP INDEX IF EXISTS idx_events_event_id;
+DROP TABLE IF EXISTS events;
@@ -0,0 +1,37 @@
+-- Migration: Criar tabelas de webhooks e do log de entregas
+-- Autor: Sistema Alderaan
+-- Data: 2026-10-17
+
+-- Inscrições de parceiros para receber eventos de domínio por HTTP
+CREATE TABLE webhooks (
+    id UUID PRIMARY KEY,
+    url TEXT NOT NULL,
+    events TEXT[] NOT NULL,
+    secret VARCHAR(128) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Uma linha por tentativa de entrega
+CREATE TABLE webhook_deliveries (
+    id UUID PRIMARY KEY,
+    webhook_id UUID NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+    event_id UUID NOT NULL,
+    event_name VARCHAR(100) NOT NULL,
+    attempt INTEGER NOT NULL,
+    status_code INTEGER NULL,
+    error TEXT NULL,
+    duration_ms BIGINT NOT NULL DEFAULT 0,
+    success BOOLEAN NOT NULL,
+    delivered_at TIMESTAMP NOT NULL
+);
+
+-- Log de entregas de um webhook, da mais recente para a mais antiga
+CREATE INDEX idx_webhook_deliveries_webhook_id ON webhook_deliveries(webhook_id, delivered_at DESC);
+
+COMMENT ON TABLE webhooks IS 'Inscrições de webhooks para eventos de domínio';
+COMMENT ON COLUMN webhooks.events IS 'Padrões de eventos (nome exato ou glob, como product.*)';
+COMMENT ON COLUMN webhooks.secret IS 'Segredo da assinatura HMAC-SHA256 das entregas';
+COMMENT ON TABLE webhook_deliveries IS 'Log das tentativas de entrega dos webhooks';
+COMMENT ON COLUMN webhook_deliveries.status_code IS 'Status HTTP da resposta (NULL = sem resposta)';
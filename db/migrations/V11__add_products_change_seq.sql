@@ -0,0 +1,44 @@
+-- Migration: Sequência de alterações de produtos para o change feed
+-- Autor: Sistema Alderaan
+-- Data: 2026-10-17
+
+CREATE SEQUENCE products_change_seq;
+
+-- change_seq: posição da última escrita do produto no feed
+-- created_seq: posição da escrita que criou o produto
+ALTER TABLE products ADD COLUMN change_seq BIGINT;
+ALTER TABLE products ADD COLUMN created_seq BIGINT;
+
+-- Preencher produtos existentes na ordem de criação
+UPDATE products p
+SET change_seq = o.seq, created_seq = o.seq
+FROM (SELECT id, row_number() OVER (ORDER BY created_at, id) AS seq FROM products) o
+WHERE p.id = o.id;
+
+SELECT setval('products_change_seq', COALESCE((SELECT MAX(change_seq) FROM products), 0) + 1, false);
+
+ALTER TABLE products ALTER COLUMN change_seq SET NOT NULL;
+ALTER TABLE products ALTER COLUMN created_seq SET NOT NULL;
+
+-- Numerar cada escrita. O advisory lock serializa as transações que escrevem produtos
+-- até o commit, então a ordem da sequência é a ordem de commit: um cliente que leu até
+-- a posição N nunca perde uma escrita com posição menor confirmada depois.
+CREATE OR REPLACE FUNCTION products_change_seq_trigger()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('products_change_seq'));
+    NEW.change_seq = nextval('products_change_seq');
+    IF TG_OP = 'INSERT' THEN
+        NEW.created_seq = NEW.change_seq;
+    END IF;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER set_products_change_seq BEFORE INSERT OR UPDATE ON products
+FOR EACH ROW EXECUTE FUNCTION products_change_seq_trigger();
+
+CREATE UNIQUE INDEX idx_products_change_seq ON products(change_seq);
+
+COMMENT ON COLUMN products.change_seq IS 'Posição da última escrita do produto no change feed, mantida por trigger';
+COMMENT ON COLUMN products.created_seq IS 'Posição da escrita que criou o produto no change feed';
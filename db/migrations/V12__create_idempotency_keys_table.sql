@@ -0,0 +1,22 @@
+-- Migration: Criar tabela idempotency_keys
+-- Autor: Sistema Alderaan
+-- Data: 2026-10-17
+
+-- Chaves do header Idempotency-Key com a resposta da primeira requisição.
+-- status_code NULL indica que a requisição original ainda está em andamento.
+CREATE TABLE idempotency_keys (
+    key VARCHAR(255) PRIMARY KEY,
+    fingerprint CHAR(64) NOT NULL,
+    status_code INTEGER NULL,
+    headers JSONB NULL,
+    body BYTEA NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL
+);
+
+-- Limpeza das chaves expiradas
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
+
+COMMENT ON TABLE idempotency_keys IS 'Respostas guardadas para reenvios com o header Idempotency-Key';
+COMMENT ON COLUMN idempotency_keys.fingerprint IS 'SHA-256 do método, URI e corpo da requisição original';
+COMMENT ON COLUMN idempotency_keys.status_code IS 'Status HTTP da resposta (NULL = requisição em andamento)';
@@ -0,0 +1,10 @@
+-- Migration: Adicionar versão aos produtos (concorrência otimista)
+-- Autor: Sistema Alderaan
+-- Data: 2026-10-17
+
+-- Incrementada a cada alteração; a escrita só é aplicada se a versão lida ainda for a atual
+ALTER TABLE products ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+
+ALTER TABLE products ADD CONSTRAINT chk_products_version CHECK (version > 0);
+
+COMMENT ON COLUMN products.version IS 'Versão do produto, incrementada a cada alteração (ETag da API)';
@@ -0,0 +1,41 @@
+-- Migration: Criar tabela de jobs assíncronos
+-- Autor: Sistema Alderaan
+-- Data: 2026-10-17
+
+-- Operações demoradas (importação, exportação, reindexação) executadas pelos workers
+CREATE TABLE jobs (
+    id UUID PRIMARY KEY,
+    type VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'queued',
+    params JSONB NULL,
+    input BYTEA NULL,
+    correlation_id VARCHAR(64) NULL,
+    processed INTEGER NOT NULL DEFAULT 0,
+    total INTEGER NOT NULL DEFAULT 0,
+    result JSONB NULL,
+    result_file TEXT NULL,
+    result_type VARCHAR(100) NULL,
+    error TEXT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    cancel_requested BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMP NULL,
+    heartbeat_at TIMESTAMP NULL,
+    finished_at TIMESTAMP NULL,
+    CONSTRAINT chk_jobs_status CHECK (status IN ('queued', 'running', 'succeeded', 'failed', 'canceled'))
+);
+
+-- Fila: o job mais antigo aguardando um worker
+CREATE INDEX idx_jobs_queued ON jobs(created_at, id) WHERE status = 'queued';
+
+-- Recuperação dos jobs em execução sem heartbeat recente
+CREATE INDEX idx_jobs_running ON jobs(heartbeat_at) WHERE status = 'running';
+
+-- Limpeza dos jobs terminados
+CREATE INDEX idx_jobs_finished_at ON jobs(finished_at) WHERE finished_at IS NOT NULL;
+
+COMMENT ON TABLE jobs IS 'Jobs assíncronos executados pelo pool de workers';
+COMMENT ON COLUMN jobs.input IS 'Conteúdo enviado na criação (arquivo da importação), descartado ao terminar';
+COMMENT ON COLUMN jobs.result_file IS 'Caminho do arquivo gerado pelo job, como o da exportação';
+COMMENT ON COLUMN jobs.heartbeat_at IS 'Último sinal do worker; sem sinal recente o job volta para a fila';
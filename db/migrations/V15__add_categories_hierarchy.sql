@@ -0,0 +1,84 @@
+-- Migration: Categorias como agregado: identificador público, slug, descrição e hierarquia
+-- Autor: Sistema Alderaan
+-- Data: 2026-10-17
+
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS public_id UUID;
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS slug VARCHAR(120);
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS description TEXT NOT NULL DEFAULT '';
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS parent_id INTEGER NULL REFERENCES categories(id) ON DELETE RESTRICT;
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+-- Slug do nome: minúsculo, sem acentos e com hífens no lugar dos demais caracteres.
+-- Equivale a category_entity.Slugify na aplicação.
+CREATE OR REPLACE FUNCTION category_slug(p_name TEXT)
+RETURNS TEXT AS $$
+DECLARE
+    slug TEXT;
+BEGIN
+    slug := trim(both '-' from regexp_replace(lower(unaccent(p_name)), '[^a-z0-9]+', '-', 'g'));
+    IF slug = '' THEN
+        RETURN 'category';
+    END IF;
+    RETURN rtrim(left(slug, 100), '-');
+END;
+$$ language 'plpgsql';
+
+-- Primeiro slug livre a partir do nome: "eletronicos", "eletronicos-2", "eletronicos-3"...
+CREATE OR REPLACE FUNCTION next_category_slug(p_name TEXT)
+RETURNS TEXT AS $$
+DECLARE
+    base TEXT := category_slug(p_name);
+    candidate TEXT := base;
+    suffix INTEGER := 1;
+BEGIN
+    WHILE EXISTS (SELECT 1 FROM categories WHERE slug = candidate) LOOP
+        suffix := suffix + 1;
+        candidate := base || '-' || suffix;
+    END LOOP;
+    RETURN candidate;
+END;
+$$ language 'plpgsql';
+
+-- Preencher categorias existentes na ordem de criação
+DO $$
+DECLARE
+    category RECORD;
+BEGIN
+    FOR category IN SELECT id, name FROM categories WHERE slug IS NULL ORDER BY id LOOP
+        UPDATE categories SET slug = next_category_slug(category.name) WHERE id = category.id;
+    END LOOP;
+END;
+$$;
+UPDATE categories SET public_id = gen_random_uuid() WHERE public_id IS NULL;
+UPDATE categories SET updated_at = created_at;
+
+ALTER TABLE categories ALTER COLUMN public_id SET NOT NULL;
+ALTER TABLE categories ALTER COLUMN public_id SET DEFAULT gen_random_uuid();
+ALTER TABLE categories ALTER COLUMN slug SET NOT NULL;
+ALTER TABLE categories ADD CONSTRAINT chk_categories_parent CHECK (parent_id <> id);
+
+-- Categorias criadas implicitamente pelos produtos (INSERT só com o nome) recebem um slug livre
+CREATE OR REPLACE FUNCTION categories_slug_trigger()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.slug IS NULL THEN
+        NEW.slug := next_category_slug(NEW.name);
+    END IF;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER set_categories_slug BEFORE INSERT ON categories
+FOR EACH ROW EXECUTE FUNCTION categories_slug_trigger();
+
+-- O upsert dos produtos (ON CONFLICT DO UPDATE SET name = EXCLUDED.name) não altera a categoria
+CREATE TRIGGER update_categories_updated_at BEFORE UPDATE ON categories
+FOR EACH ROW WHEN (OLD.* IS DISTINCT FROM NEW.*) EXECUTE FUNCTION update_updated_at_column();
+
+CREATE UNIQUE INDEX idx_categories_public_id ON categories(public_id);
+CREATE UNIQUE INDEX idx_categories_slug ON categories(slug);
+CREATE INDEX idx_categories_parent_id ON categories(parent_id);
+
+COMMENT ON COLUMN categories.public_id IS 'Identificador estável da categoria exposto pela API';
+COMMENT ON COLUMN categories.slug IS 'Identificador legível e único da categoria, usado nas URLs';
+COMMENT ON COLUMN categories.parent_id IS 'Categoria pai; nulo nas categorias da raiz';
@@ -0,0 +1,27 @@
+-- Migration: Criar tabela dead_letters (eventos cujos handlers esgotaram as tentativas)
+-- Autor: Sistema Alderaan
+-- Data: 2026-10-17
+
+-- Registros da dead-letter store do EventDispatcher, mantidos entre reinícios para
+-- consulta e replay pelos endpoints /api/v1/admin/dead-letters
+CREATE TABLE dead_letters (
+    id UUID PRIMARY KEY,
+    handler VARCHAR(100) NOT NULL,
+    event_id UUID NOT NULL,
+    event_name VARCHAR(100) NOT NULL,
+    event_version INTEGER NOT NULL DEFAULT 1,
+    aggregate_id VARCHAR(64) NOT NULL DEFAULT '',
+    correlation_id VARCHAR(64) NULL,
+    occurred_at TIMESTAMP NOT NULL,
+    payload JSONB NOT NULL,
+    error TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    failed_at TIMESTAMP NOT NULL
+);
+
+-- Listagem do registro mais antigo para o mais recente
+CREATE INDEX idx_dead_letters_failed_at ON dead_letters(failed_at, id);
+
+COMMENT ON TABLE dead_letters IS 'Eventos cujos handlers com retry esgotaram as tentativas';
+COMMENT ON COLUMN dead_letters.handler IS 'Nome do handler que falhou, usado no replay';
+COMMENT ON COLUMN dead_letters.attempts IS 'Tentativas feitas, somando as dos replays';
@@ -0,0 +1,12 @@
+-- Migration: Reindexar produtos só quando o nome da categoria muda de fato
+-- Autor: Sistema Alderaan
+-- Data: 2026-10-17
+
+-- O UPDATE de categorias grava o nome junto com slug, descrição e pai; sem o WHEN,
+-- qualquer edição da categoria refazia o search_vector de todos os seus produtos
+DROP TRIGGER IF EXISTS refresh_categories_search_vector ON categories;
+
+CREATE TRIGGER refresh_categories_search_vector AFTER UPDATE OF name ON categories
+FOR EACH ROW
+WHEN (OLD.name IS DISTINCT FROM NEW.name)
+EXECUTE FUNCTION categories_search_vector_trigger();
@@ -0,0 +1,12 @@
+-- Migration: Numerar no change feed só as escritas que alteram o produto
+-- Autor: Sistema Alderaan
+-- Data: 2026-10-17
+
+-- Sem a lista de colunas, o UPDATE de search_vector feito pelos triggers da busca textual
+-- e pela reindexação (refresh_product_search_vector) avançava o change_seq de todos os
+-- produtos, que reapareciam no feed sem nenhuma alteração. Toda escrita do produto
+-- incrementa version, então ela sempre dispara o trigger.
+DROP TRIGGER IF EXISTS set_products_change_seq ON products;
+
+CREATE TRIGGER set_products_change_seq BEFORE INSERT OR UPDATE OF name, sku, price, currency, deleted_at, version ON products
+FOR EACH ROW EXECUTE FUNCTION products_change_seq_trigger();
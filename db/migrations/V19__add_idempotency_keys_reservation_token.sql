@@ -0,0 +1,11 @@
+-- Migration: Identificar a reserva das chaves de idempotência
+-- Autor: Sistema Alderaan
+-- Data: 2026-10-17
+
+-- Uma reserva sem resposta após o PendingTimeout pode ser tomada por outra requisição.
+-- O token gerado a cada reserva impede que a requisição original, se ainda terminar,
+-- sobrescreva ou libere a reserva nova. Reservas anteriores ficam sem token e só
+-- expiram ou são abandonadas.
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS reservation_token UUID NULL;
+
+COMMENT ON COLUMN idempotency_keys.reservation_token IS 'Token da reserva atual; só quem a fez pode completá-la ou liberá-la';
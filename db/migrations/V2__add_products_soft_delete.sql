@@ -0,0 +1,10 @@
+-- Migration: Adicionar soft delete aos produtos
+-- Autor: Sistema Alderaan
+-- Data: 2026-10-17
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP NULL;
+
+-- Índice parcial: a maioria das consultas busca apenas produtos ativos
+CREATE INDEX idx_products_active ON products(created_at) WHERE deleted_at IS NULL;
+
+COMMENT ON COLUMN products.deleted_at IS 'Data da exclusão lógica (NULL para produtos ativos)';
@@ -0,0 +1,15 @@
+-- Migration: Adicionar identificador público (UUID) aos produtos
+-- Autor: Sistema Alderaan
+-- Data: 2026-10-17
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS public_id UUID;
+
+-- Preencher produtos existentes antes de tornar a coluna obrigatória
+UPDATE products SET public_id = gen_random_uuid() WHERE public_id IS NULL;
+
+ALTER TABLE products ALTER COLUMN public_id SET NOT NULL;
+ALTER TABLE products ALTER COLUMN public_id SET DEFAULT gen_random_uuid();
+
+CREATE UNIQUE INDEX idx_products_public_id ON products(public_id);
+
+COMMENT ON COLUMN products.public_id IS 'Identificador estável do produto exposto pela API';
@@ -0,0 +1,15 @@
+-- Migration: Adicionar moeda ao preço dos produtos
+-- Autor: Sistema Alderaan
+-- Data: 2026-10-17
+
+-- Preços passam a ser um valor monetário (valor em unidades menores + moeda ISO 4217)
+ALTER TABLE products ALTER COLUMN price TYPE BIGINT;
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'BRL';
+
+ALTER TABLE products ADD CONSTRAINT chk_products_currency CHECK (currency ~ '^[A-Z]{3}$');
+
+CREATE INDEX idx_products_currency ON products(currency);
+
+COMMENT ON COLUMN products.price IS 'Preço em unidades menores da moeda (centavos para BRL/USD/EUR)';
+COMMENT ON COLUMN products.currency IS 'Código ISO 4217 da moeda do preço';
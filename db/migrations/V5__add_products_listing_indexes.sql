@@ -0,0 +1,8 @@
+-- Migration: Índices para a listagem paginada de produtos
+-- Autor: Sistema Alderaan
+-- Data: 2026-10-17
+
+-- A paginação por cursor ordena pelo campo escolhido e desempata por public_id
+CREATE INDEX idx_products_created_at_public_id ON products(created_at, public_id);
+CREATE INDEX idx_products_price_public_id ON products(price, public_id);
+CREATE INDEX idx_products_name_public_id ON products(name, public_id);
@@ -0,0 +1,75 @@
+-- Migration: Busca textual de produtos por nome e categoria
+-- Autor: Sistema Alderaan
+-- Data: 2026-10-17
+
+-- unaccent permite casar "eletronicos" com "Eletrônicos"
+CREATE EXTENSION IF NOT EXISTS unaccent;
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS search_vector tsvector;
+
+-- Monta o documento de busca: nome com peso A e categorias com peso B.
+-- A configuração 'simple' não aplica stemming, para casar com o tokenizador da aplicação.
+CREATE OR REPLACE FUNCTION refresh_product_search_vector(p_product_id INTEGER)
+RETURNS VOID AS $$
+BEGIN
+    UPDATE products p
+    SET search_vector =
+        setweight(to_tsvector('simple', unaccent(p.name)), 'A') ||
+        setweight(to_tsvector('simple', unaccent(COALESCE((
+            SELECT string_agg(c.name, ' ')
+            FROM product_categories pc
+            INNER JOIN categories c ON c.id = pc.category_id
+            WHERE pc.product_id = p.id
+        ), ''))), 'B')
+    WHERE p.id = p_product_id;
+END;
+$$ language 'plpgsql';
+
+-- Atualizar o documento quando o nome do produto muda
+CREATE OR REPLACE FUNCTION products_search_vector_trigger()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM refresh_product_search_vector(NEW.id);
+    RETURN NULL;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER refresh_products_search_vector AFTER INSERT OR UPDATE OF name ON products
+FOR EACH ROW EXECUTE FUNCTION products_search_vector_trigger();
+
+-- Atualizar o documento quando as categorias do produto mudam
+CREATE OR REPLACE FUNCTION product_categories_search_vector_trigger()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        PERFORM refresh_product_search_vector(OLD.product_id);
+    ELSE
+        PERFORM refresh_product_search_vector(NEW.product_id);
+    END IF;
+    RETURN NULL;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER refresh_product_categories_search_vector AFTER INSERT OR DELETE ON product_categories
+FOR EACH ROW EXECUTE FUNCTION product_categories_search_vector_trigger();
+
+-- Atualizar os produtos de uma categoria renomeada
+CREATE OR REPLACE FUNCTION categories_search_vector_trigger()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM refresh_product_search_vector(pc.product_id)
+    FROM product_categories pc
+    WHERE pc.category_id = NEW.id;
+    RETURN NULL;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER refresh_categories_search_vector AFTER UPDATE OF name ON categories
+FOR EACH ROW EXECUTE FUNCTION categories_search_vector_trigger();
+
+-- Preencher produtos existentes
+SELECT refresh_product_search_vector(id) FROM products;
+
+CREATE INDEX idx_products_search_vector ON products USING GIN(search_vector);
+
+COMMENT ON COLUMN products.search_vector IS 'Documento de busca textual (nome peso A, categorias peso B), mantido por triggers';
@@ -0,0 +1,24 @@
+-- Migration: Criar tabela outbox para publicação confiável de eventos de domínio
+-- Autor: Sistema Alderaan
+-- Data: 2026-10-17
+
+-- Eventos são gravados na mesma transação da escrita do produto e entregues
+-- ao dispatcher pelo relay do outbox (at-least-once, em ordem de id)
+CREATE TABLE outbox (
+    id BIGSERIAL PRIMARY KEY,
+    aggregate_id VARCHAR(64) NOT NULL,
+    event_name VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    delivered_at TIMESTAMP NULL
+);
+
+-- Leitura dos eventos pendentes pelo relay
+CREATE INDEX idx_outbox_pending ON outbox(id) WHERE delivered_at IS NULL;
+
+-- Limpeza dos eventos já entregues
+CREATE INDEX idx_outbox_delivered_at ON outbox(delivered_at) WHERE delivered_at IS NOT NULL;
+
+COMMENT ON TABLE outbox IS 'Eventos de domínio aguardando entrega (transactional outbox)';
+COMMENT ON COLUMN outbox.aggregate_id IS 'ID público do agregado que gerou o evento';
+COMMENT ON COLUMN outbox.delivered_at IS 'Data de entrega ao dispatcher (NULL = pendente)';
@@ -0,0 +1,27 @@
+-- Migration: Adicionar metadados do envelope de eventos ao outbox
+-- Autor: Sistema Alderaan
+-- Data: 2026-10-17
+
+-- Cada evento passa a ter ID, versão de schema, ID de correlação e data de ocorrência,
+-- preservados do momento da gravação até a entrega aos handlers
+ALTER TABLE outbox
+    ADD COLUMN event_id UUID NULL,
+    ADD COLUMN event_version INTEGER NOT NULL DEFAULT 1,
+    ADD COLUMN correlation_id VARCHAR(64) NULL,
+    ADD COLUMN occurred_at TIMESTAMP NULL;
+
+-- Eventos gravados antes desta migration
+UPDATE outbox
+SET event_id = gen_random_uuid(),
+    occurred_at = created_at
+WHERE event_id IS NULL;
+
+ALTER TABLE outbox
+    ALTER COLUMN event_id SET NOT NULL,
+    ALTER COLUMN occurred_at SET NOT NULL;
+
+CREATE UNIQUE INDEX idx_outbox_event_id ON outbox(event_id);
+
+COMMENT ON COLUMN outbox.event_id IS 'ID do envelope do evento, usado para deduplicação';
+COMMENT ON COLUMN outbox.event_version IS 'Versão do schema do payload';
+COMMENT ON COLUMN outbox.correlation_id IS 'ID de correlação da requisição que gerou o evento';
@@ -0,0 +1,75 @@
+-- Migration: Criar tabela events (histórico de eventos de domínio)
+-- Autor: Sistema Alderaan
+-- Data: 2026-10-17
+
+-- Todo evento entregue pelo EventDispatcher é gravado aqui, em ordem global de sequência.
+-- O histórico permite reconstruir projeções (como as métricas de negócio) do zero.
+CREATE TABLE events (
+    sequence BIGSERIAL PRIMARY KEY,
+    event_id UUID NOT NULL,
+    event_name VARCHAR(100) NOT NULL,
+    event_version INTEGER NOT NULL DEFAULT 1,
+    aggregate_id VARCHAR(64) NOT NULL DEFAULT '',
+    correlation_id VARCHAR(64) NULL,
+    occurred_at TIMESTAMP NOT NULL,
+    payload JSONB NOT NULL,
+    recorded_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Deduplicação de eventos reentregues pelo outbox (at-least-once)
+CREATE UNIQUE INDEX idx_events_event_id ON events(event_id);
+
+-- Histórico de um agregado
+CREATE INDEX idx_events_aggregate_id ON events(aggregate_id, sequence);
+
+-- Gera eventos product.created (e product.deleted, para produtos excluídos) para os
+-- produtos que ainda não têm histórico, como os existentes antes desta migration
+-- e os inseridos pelo seed. O payload segue o JSON dos eventos de produto.
+CREATE OR REPLACE FUNCTION backfill_product_events()
+RETURNS void AS $$
+BEGIN
+    CREATE TEMPORARY TABLE backfill_products ON COMMIT DROP AS
+    SELECT p.id, p.public_id, p.name, p.sku, p.price, p.currency, p.created_at, p.deleted_at
+    FROM products p
+    WHERE NOT EXISTS (
+        SELECT 1 FROM events e WHERE e.aggregate_id = p.public_id::text
+    );
+
+    INSERT INTO events (event_id, event_name, event_version, aggregate_id, occurred_at, payload)
+    SELECT gen_random_uuid(), 'product.created', 1, b.public_id::text, b.created_at,
+           jsonb_build_object(
+               'ID', b.public_id::text,
+               'Name', b.name,
+               'Sku', b.sku,
+               'Categories', COALESCE((
+                   SELECT jsonb_agg(c.name ORDER BY c.name)
+                   FROM product_categories pc
+                   JOIN categories c ON c.id = pc.category_id
+                   WHERE pc.product_id = b.id
+               ), '[]'::jsonb),
+               'Price', jsonb_build_object('amount', b.price, 'currency', b.currency)
+           )
+    FROM backfill_products b
+    ORDER BY b.created_at, b.id;
+
+    INSERT INTO events (event_id, event_name, event_version, aggregate_id, occurred_at, payload)
+    SELECT gen_random_uuid(), 'product.deleted', 1, b.public_id::text, b.deleted_at,
+           jsonb_build_object(
+               'ID', b.public_id::text,
+               'Name', b.name,
+               'Sku', b.sku,
+               'DeletedAt', to_char(b.deleted_at, 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"')
+           )
+    FROM backfill_products b
+    WHERE b.deleted_at IS NOT NULL
+    ORDER BY b.deleted_at, b.id;
+
+    DROP TABLE backfill_products;
+END;
+$$ LANGUAGE plpgsql;
+
+SELECT backfill_product_events();
+
+COMMENT ON TABLE events IS 'Histórico de eventos de domínio (event store)';
+COMMENT ON COLUMN events.sequence IS 'Posição global do evento no histórico';
+COMMENT ON COLUMN events.event_id IS 'ID do envelope do evento';
@@ -0,0 +1,204 @@
+package category_entity
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+
+	shared_identity "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/identity"
+	shared_validation "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/validation"
+)
+
+// Limites dos campos da categoria; o slug gerado automaticamente reserva espaço para o
+// sufixo que o desambigua ("eletronicos-2")
+const (
+	MaxNameLength        = 100
+	MaxSlugLength        = 100
+	MaxDescriptionLength = 1000
+)
+
+// defaultSlug é o slug de um nome sem letras nem números
+const defaultSlug = "category"
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// Category é uma categoria do catálogo. Os produtos a referenciam pelo nome; o slug
+// identifica a categoria nas URLs e ParentID a posiciona na hierarquia (vazio na raiz).
+type Category struct {
+	ID          string    `json:"id" example:"8c2d4e6f-1a3b-4c5d-9e7f-0a1b2c3d4e5f"`
+	Name        string    `json:"name" example:"Eletrônicos"`
+	Slug        string    `json:"slug" example:"eletronicos"`
+	Description string    `json:"description" example:"Aparelhos eletrônicos e acessórios"`
+	ParentID    string    `json:"parent_id,omitempty" example:"5b1c3d5e-7f9a-4b2c-8d4e-6f8a0b2c4d6e"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// NewCategory cria uma categoria; sem slug informado, ele é gerado a partir do nome
+func NewCategory(name, slug, description, parentID string) (*Category, error) {
+	if slug == "" {
+		slug = Slugify(name)
+	}
+
+	if ok, err := Validate(name, slug, description, parentID); !ok {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	return &Category{
+		ID:          shared_identity.NewUUID(),
+		Name:        name,
+		Slug:        slug,
+		Description: description,
+		ParentID:    parentID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}, nil
+}
+
+// Update substitui os dados da categoria após validá-los; slug vazio mantém o atual, para
+// que as URLs continuem válidas depois de uma renomeação
+func (c *Category) Update(name, slug, description, parentID string) error {
+	if slug == "" {
+		slug = c.Slug
+	}
+
+	violations := validate(name, slug, description, parentID)
+	if parentID != "" && parentID == c.ID {
+		violations = append(violations, shared_validation.NewError("parent_id", shared_validation.CodeInvalid, "category cannot be its own parent"))
+	}
+	if err := violations.Err(); err != nil {
+		return err
+	}
+
+	c.Name = name
+	c.Slug = slug
+	c.Description = description
+	c.ParentID = parentID
+	c.UpdatedAt = time.Now().UTC()
+
+	return nil
+}
+
+// Validate verifica os dados da categoria; o erro é um shared_validation.Errors com todas
+// as violações encontradas
+func Validate(name, slug, description, parentID string) (bool, error) {
+	if err := validate(name, slug, description, parentID).Err(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func validate(name, slug, description, parentID string) shared_validation.Errors {
+	var violations shared_validation.Errors
+
+	switch {
+	case strings.TrimSpace(name) == "":
+		violations = append(violations, shared_validation.NewError("name", shared_validation.CodeRequired, "name is required"))
+	case name != strings.TrimSpace(name):
+		violations = append(violations, shared_validation.NewError("name", shared_validation.CodeInvalid, "name must not start or end with spaces"))
+	case len([]rune(name)) > MaxNameLength:
+		violations = append(violations, shared_validation.NewError("name", shared_validation.CodeTooLong, fmt.Sprintf("name must have at most %d characters", MaxNameLength)))
+	}
+
+	switch {
+	case !slugPattern.MatchString(slug):
+		violations = append(violations, shared_validation.NewError("slug", shared_validation.CodeInvalid, "slug must have only lowercase letters, numbers and single hyphens"))
+	case len(slug) > MaxSlugLength:
+		violations = append(violations, shared_validation.NewError("slug", shared_validation.CodeTooLong, fmt.Sprintf("slug must have at most %d characters", MaxSlugLength)))
+	}
+
+	if len([]rune(description)) > MaxDescriptionLength {
+		violations = append(violations, shared_validation.NewError("description", shared_validation.CodeTooLong, fmt.Sprintf("description must have at most %d characters", MaxDescriptionLength)))
+	}
+
+	if parentID != "" && !shared_identity.IsValidUUID(parentID) {
+		violations = append(violations, shared_validation.NewError("parent_id", shared_validation.CodeInvalid, "invalid parent_id"))
+	}
+
+	return violations
+}
+
+// Slugify gera o slug do nome: minúsculo, sem acentos e com hífens no lugar dos demais
+// caracteres. "Eletrônicos & Informática" vira "eletronicos-informatica". Equivale à função
+// category_slug do Postgres, que gera o slug das categorias criadas pelos produtos.
+func Slugify(name string) string {
+	var slug strings.Builder
+	hyphen := false
+
+	for _, r := range norm.NFD.String(strings.ToLower(name)) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// Acentos decompostos pelo NFD são descartados
+		case (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9'):
+			if hyphen && slug.Len() > 0 {
+				slug.WriteByte('-')
+			}
+			hyphen = false
+			slug.WriteRune(r)
+		default:
+			hyphen = true
+		}
+	}
+
+	if slug.Len() == 0 {
+		return defaultSlug
+	}
+	return strings.TrimSuffix(truncate(slug.String(), MaxSlugLength), "-")
+}
+
+// truncate corta o slug (só ASCII) em max bytes
+func truncate(slug string, max int) string {
+	if len(slug) > max {
+		return slug[:max]
+	}
+	return slug
+}
+
+// Subtree retorna a categoria rootID seguida das suas descendentes, nível a nível;
+// vazio se rootID não está entre as categorias
+func Subtree(categories []Category, rootID string) []Category {
+	children := make(map[string][]Category)
+	var subtree []Category
+	for _, category := range categories {
+		if category.ID == rootID {
+			subtree = append(subtree, category)
+		} else if category.ParentID != "" {
+			children[category.ParentID] = append(children[category.ParentID], category)
+		}
+	}
+
+	seen := map[string]bool{rootID: true}
+	for i := 0; i < len(subtree); i++ {
+		for _, child := range children[subtree[i].ID] {
+			if !seen[child.ID] {
+				seen[child.ID] = true
+				subtree = append(subtree, child)
+			}
+		}
+	}
+	return subtree
+}
+
+// IsInSubtree informa se a categoria id é rootID ou uma das suas descendentes
+func IsInSubtree(categories []Category, rootID, id string) bool {
+	for _, category := range Subtree(categories, rootID) {
+		if category.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Names retorna os nomes das categorias, na mesma ordem
+func Names(categories []Category) []string {
+	names := make([]string, len(categories))
+	for i, category := range categories {
+		names[i] = category.Name
+	}
+	return names
+}
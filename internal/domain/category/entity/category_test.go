@@ -0,0 +1,142 @@
+package category_entity
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	shared_validation "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/validation"
+)
+
+func TestNewCategory(t *testing.T) {
+	category, err := NewCategory("Eletrônicos & Informática", "", "Aparelhos eletrônicos", "")
+	if err != nil {
+		t.Fatalf("NewCategory() error = %v", err)
+	}
+	if category.ID == "" || category.CreatedAt.IsZero() || !category.CreatedAt.Equal(category.UpdatedAt) {
+		t.Errorf("NewCategory() = %+v", category)
+	}
+	if category.Slug != "eletronicos-informatica" {
+		t.Errorf("Slug = %q, want eletronicos-informatica", category.Slug)
+	}
+
+	category, err = NewCategory("Computadores", "pcs", "", "5b1c3d5e-7f9a-4b2c-8d4e-6f8a0b2c4d6e")
+	if err != nil {
+		t.Fatalf("NewCategory() error = %v", err)
+	}
+	if category.Slug != "pcs" || category.ParentID != "5b1c3d5e-7f9a-4b2c-8d4e-6f8a0b2c4d6e" {
+		t.Errorf("NewCategory() = %+v", category)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		categoryArg string
+		slug        string
+		description string
+		parentID    string
+		wantErr     string
+	}{
+		{"valid", "Livros", "livros", "", "", ""},
+		{"empty name", " ", "livros", "", "", "name is required"},
+		{"name with spaces", " Livros", "livros", "", "", "name must not start or end with spaces"},
+		{"name too long", strings.Repeat("a", MaxNameLength+1), "livros", "", "", "name must have at most 100 characters"},
+		{"uppercase slug", "Livros", "Livros", "", "", "slug must have only lowercase letters, numbers and single hyphens"},
+		{"double hyphen slug", "Livros", "livros--usados", "", "", "slug must have only lowercase letters, numbers and single hyphens"},
+		{"slug too long", "Livros", strings.Repeat("a", MaxSlugLength+1), "", "", "slug must have at most 100 characters"},
+		{"description too long", "Livros", "livros", strings.Repeat("a", MaxDescriptionLength+1), "", "description must have at most 1000 characters"},
+		{"invalid parent", "Livros", "livros", "", "eletronicos", "invalid parent_id"},
+		{"every violation is reported", "", "Livros", strings.Repeat("a", MaxDescriptionLength+1), "eletronicos", "name is required; slug must have only lowercase letters, numbers and single hyphens; description must have at most 1000 characters; invalid parent_id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := Validate(tt.categoryArg, tt.slug, tt.description, tt.parentID)
+			if tt.wantErr == "" {
+				if !ok || err != nil {
+					t.Errorf("Validate() = %v, %v, want valid", ok, err)
+				}
+				return
+			}
+			if ok || err == nil || err.Error() != tt.wantErr || !errors.Is(err, shared_validation.ErrValidation) {
+				t.Errorf("Validate() = %v, %v, want validation error %q", ok, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCategory_Update(t *testing.T) {
+	category, err := NewCategory("Eletronicos", "", "", "")
+	if err != nil {
+		t.Fatalf("NewCategory() error = %v", err)
+	}
+
+	// Slug vazio mantém o atual
+	if err := category.Update("Eletrônicos", "", "Aparelhos", ""); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if category.Name != "Eletrônicos" || category.Slug != "eletronicos" || category.Description != "Aparelhos" {
+		t.Errorf("Update() = %+v", category)
+	}
+
+	var violations shared_validation.Errors
+	err = category.Update("Eletrônicos", "", "", category.ID)
+	if !errors.As(err, &violations) || len(violations) != 1 || violations[0].Field != "parent_id" || violations[0].Code != shared_validation.CodeInvalid || err.Error() != "category cannot be its own parent" {
+		t.Errorf("Update() own parent error = %v", err)
+	}
+	if err := category.Update("", "", "", ""); err == nil {
+		t.Error("Update() with empty name error = nil")
+	}
+	if category.Name != "Eletrônicos" {
+		t.Errorf("failed Update() changed the category: %+v", category)
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	tests := map[string]string{
+		"Eletrônicos":                  "eletronicos",
+		"Eletronicos":                  "eletronicos",
+		"Periféricos":                  "perifericos",
+		"  Cama, Mesa & Banho!  ":      "cama-mesa-banho",
+		"Memória RAM 16GB":             "memoria-ram-16gb",
+		"???":                          defaultSlug,
+		strings.Repeat("a", 99) + " b": strings.Repeat("a", 99),
+	}
+
+	for name, want := range tests {
+		if got := Slugify(name); got != want {
+			t.Errorf("Slugify(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestSubtree(t *testing.T) {
+	categories := []Category{
+		{ID: "1", Name: "Eletrônicos"},
+		{ID: "2", Name: "Computadores", ParentID: "1"},
+		{ID: "3", Name: "Notebooks", ParentID: "2"},
+		{ID: "4", Name: "Smartphones", ParentID: "1"},
+		{ID: "5", Name: "Livros"},
+	}
+
+	got := Names(Subtree(categories, "1"))
+	want := []string{"Eletrônicos", "Computadores", "Smartphones", "Notebooks"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Subtree() = %v, want %v", got, want)
+	}
+
+	if got := Subtree(categories, "5"); len(got) != 1 || got[0].Name != "Livros" {
+		t.Errorf("Subtree() of a leaf = %+v", got)
+	}
+	if got := Subtree(categories, "missing"); len(got) != 0 {
+		t.Errorf("Subtree() of a missing category = %+v", got)
+	}
+
+	if !IsInSubtree(categories, "1", "3") || !IsInSubtree(categories, "2", "2") {
+		t.Error("IsInSubtree() = false for a descendant")
+	}
+	if IsInSubtree(categories, "2", "1") || IsInSubtree(categories, "2", "4") {
+		t.Error("IsInSubtree() = true outside the subtree")
+	}
+}
@@ -0,0 +1,297 @@
+package category_repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	category_entity "github.com/williamkoller/golang-domain-driven-design/internal/domain/category/entity"
+	shared_identity "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/identity"
+)
+
+var (
+	ErrCategoryNotFound      = errors.New("category not found")
+	ErrCategoryAlreadyExists = errors.New("category with this name or slug already exists")
+	ErrParentNotFound        = errors.New("parent category not found")
+	ErrCategoryCycle         = errors.New("category cannot be moved under itself or its subcategories")
+	ErrInvalidMerge          = errors.New("category cannot be merged into itself or its subcategories")
+	ErrCategoryInUse         = errors.New("category has subcategories or products")
+	ErrUnavailable           = errors.New("category storage unavailable")
+)
+
+// ICategoryRepository persiste as categorias do catálogo. Os produtos referenciam as
+// categorias pelo nome, então as alterações de nome se propagam a eles: Update com um novo
+// nome renomeia a categoria em todos os produtos e Merge move os produtos da categoria de
+// origem para a de destino. Os produtos alterados têm a versão incrementada.
+type ICategoryRepository interface {
+	// Add inclui a categoria; ErrCategoryAlreadyExists se o nome ou o slug estão em uso e
+	// ErrParentNotFound se a categoria pai não existe
+	Add(ctx context.Context, category category_entity.Category) error
+	// FindAll retorna todas as categorias em ordem de nome
+	FindAll(ctx context.Context) ([]category_entity.Category, error)
+	FindByID(ctx context.Context, id string) (category_entity.Category, error)
+	FindBySlug(ctx context.Context, slug string) (category_entity.Category, error)
+	// Update grava a categoria; ErrCategoryCycle se a nova categoria pai é a própria
+	// categoria ou uma descendente
+	Update(ctx context.Context, category category_entity.Category) error
+	// Delete remove a categoria; ErrCategoryInUse se ela tem subcategorias ou produtos
+	Delete(ctx context.Context, id string) error
+	// Merge move os produtos e as subcategorias de sourceID para targetID e remove a
+	// categoria de origem; retorna quantos produtos foram alterados
+	Merge(ctx context.Context, sourceID, targetID string) (int, error)
+	// Ensure cadastra, na raiz e com um slug livre, as categorias dos nomes que ainda não
+	// existem; é como os produtos criam categorias fora do modo estrito
+	Ensure(ctx context.Context, names []string) error
+}
+
+// ProductCategories são os produtos do repositório in-memory, que guardam os nomes das
+// suas categorias. No Postgres os produtos referenciam a linha da categoria e a propagação
+// faz parte da transação da própria categoria.
+type ProductCategories interface {
+	// CountCategory conta os produtos, inclusive excluídos, com a categoria
+	CountCategory(ctx context.Context, name string) (int, error)
+	// ReplaceCategory troca as categorias from por to em todos os produtos, inclusive
+	// excluídos, sem repeti-la; retorna quantos produtos foram alterados
+	ReplaceCategory(ctx context.Context, from []string, to string) (int, error)
+}
+
+type CategoryRepository struct {
+	data     map[string]category_entity.Category
+	products ProductCategories
+	mu       sync.RWMutex
+}
+
+// NewRepository cria um repositório in-memory que propaga as renomeações e fusões aos
+// produtos; products pode ser nil quando não há produtos a atualizar
+func NewRepository(products ProductCategories) *CategoryRepository {
+	return &CategoryRepository{
+		data:     make(map[string]category_entity.Category),
+		products: products,
+	}
+}
+
+func (r *CategoryRepository) Add(ctx context.Context, category category_entity.Category) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.data[category.ID]; exists {
+		return ErrCategoryAlreadyExists
+	}
+	if err := r.checkUnique(category); err != nil {
+		return err
+	}
+	if category.ParentID != "" {
+		if _, exists := r.data[category.ParentID]; !exists {
+			return ErrParentNotFound
+		}
+	}
+	r.data[category.ID] = category
+
+	return nil
+}
+
+func (r *CategoryRepository) FindAll(ctx context.Context) ([]category_entity.Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.sorted(), nil
+}
+
+func (r *CategoryRepository) FindByID(ctx context.Context, id string) (category_entity.Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	category, exists := r.data[id]
+	if !exists {
+		return category_entity.Category{}, ErrCategoryNotFound
+	}
+
+	return category, nil
+}
+
+func (r *CategoryRepository) FindBySlug(ctx context.Context, slug string) (category_entity.Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, category := range r.data {
+		if category.Slug == slug {
+			return category, nil
+		}
+	}
+
+	return category_entity.Category{}, ErrCategoryNotFound
+}
+
+func (r *CategoryRepository) Update(ctx context.Context, category category_entity.Category) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, exists := r.data[category.ID]
+	if !exists {
+		return ErrCategoryNotFound
+	}
+	if err := r.checkUnique(category); err != nil {
+		return err
+	}
+	if category.ParentID != "" {
+		if _, exists := r.data[category.ParentID]; !exists {
+			return ErrParentNotFound
+		}
+		if category_entity.IsInSubtree(r.sorted(), category.ID, category.ParentID) {
+			return ErrCategoryCycle
+		}
+	}
+
+	if category.Name != current.Name && r.products != nil {
+		if _, err := r.products.ReplaceCategory(ctx, []string{current.Name}, category.Name); err != nil {
+			return err
+		}
+	}
+	r.data[category.ID] = category
+
+	return nil
+}
+
+func (r *CategoryRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	category, exists := r.data[id]
+	if !exists {
+		return ErrCategoryNotFound
+	}
+	for _, other := range r.data {
+		if other.ParentID == id {
+			return ErrCategoryInUse
+		}
+	}
+	if r.products != nil {
+		count, err := r.products.CountCategory(ctx, category.Name)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrCategoryInUse
+		}
+	}
+	delete(r.data, id)
+
+	return nil
+}
+
+func (r *CategoryRepository) Merge(ctx context.Context, sourceID, targetID string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	source, exists := r.data[sourceID]
+	if !exists {
+		return 0, ErrCategoryNotFound
+	}
+	target, exists := r.data[targetID]
+	if !exists {
+		return 0, ErrCategoryNotFound
+	}
+	if category_entity.IsInSubtree(r.sorted(), sourceID, targetID) {
+		return 0, ErrInvalidMerge
+	}
+
+	merged := 0
+	if r.products != nil {
+		var err error
+		if merged, err = r.products.ReplaceCategory(ctx, []string{source.Name}, target.Name); err != nil {
+			return 0, err
+		}
+	}
+
+	now := time.Now().UTC()
+	for id, child := range r.data {
+		if child.ParentID == sourceID {
+			child.ParentID = targetID
+			child.UpdatedAt = now
+			r.data[id] = child
+		}
+	}
+	delete(r.data, sourceID)
+
+	return merged, nil
+}
+
+func (r *CategoryRepository) Ensure(ctx context.Context, names []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing := make(map[string]bool, len(r.data))
+	for _, category := range r.data {
+		existing[category.Name] = true
+	}
+
+	for _, name := range names {
+		if existing[name] {
+			continue
+		}
+		if len([]rune(name)) > category_entity.MaxNameLength {
+			return fmt.Errorf("category name must have at most %d characters", category_entity.MaxNameLength)
+		}
+
+		// Como no Postgres, o nome é guardado como o produto o informou
+		now := time.Now().UTC()
+		category := category_entity.Category{
+			ID:        shared_identity.NewUUID(),
+			Name:      name,
+			Slug:      r.nextSlug(name),
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		r.data[category.ID] = category
+		existing[name] = true
+	}
+
+	return nil
+}
+
+// checkUnique garante, como os índices únicos do Postgres, que nenhuma outra categoria usa
+// o nome ou o slug; exige o lock
+func (r *CategoryRepository) checkUnique(category category_entity.Category) error {
+	for id, other := range r.data {
+		if id != category.ID && (other.Name == category.Name || other.Slug == category.Slug) {
+			return ErrCategoryAlreadyExists
+		}
+	}
+	return nil
+}
+
+// nextSlug retorna o primeiro slug livre para o nome: "eletronicos", "eletronicos-2"...;
+// exige o lock
+func (r *CategoryRepository) nextSlug(name string) string {
+	used := make(map[string]bool, len(r.data))
+	for _, category := range r.data {
+		used[category.Slug] = true
+	}
+
+	base := category_entity.Slugify(name)
+	slug := base
+	for suffix := 2; used[slug]; suffix++ {
+		slug = fmt.Sprintf("%s-%d", base, suffix)
+	}
+	return slug
+}
+
+// sorted retorna as categorias em ordem de nome; exige o lock
+func (r *CategoryRepository) sorted() []category_entity.Category {
+	categories := make([]category_entity.Category, 0, len(r.data))
+	for _, category := range r.data {
+		categories = append(categories, category)
+	}
+
+	sort.Slice(categories, func(i, j int) bool {
+		if categories[i].Name != categories[j].Name {
+			return categories[i].Name < categories[j].Name
+		}
+		return categories[i].ID < categories[j].ID
+	})
+
+	return categories
+}
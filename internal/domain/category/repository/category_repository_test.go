@@ -0,0 +1,183 @@
+package category_repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	category_entity "github.com/williamkoller/golang-domain-driven-design/internal/domain/category/entity"
+	product_entity "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/entity"
+	product_repository "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/repository"
+	product_valueobject "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/valueobject"
+)
+
+func newTestCategory(t *testing.T, name, parentID string) category_entity.Category {
+	t.Helper()
+
+	category, err := category_entity.NewCategory(name, "", "", parentID)
+	if err != nil {
+		t.Fatalf("NewCategory() error = %v", err)
+	}
+	return *category
+}
+
+func addTestProduct(t *testing.T, products *product_repository.ProductRepository, name string, sku int, categories ...string) {
+	t.Helper()
+
+	price, _ := product_valueobject.NewMoney(1000, "BRL")
+	product, err := product_entity.NewProduct(name, sku, categories, price)
+	if err != nil {
+		t.Fatalf("NewProduct() error = %v", err)
+	}
+	if err := products.Add(context.Background(), *product); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+}
+
+func TestCategoryRepository_CRUD(t *testing.T) {
+	ctx := context.Background()
+	repo := NewRepository(nil)
+
+	electronics := newTestCategory(t, "Eletrônicos", "")
+	if err := repo.Add(ctx, electronics); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := repo.Add(ctx, newTestCategory(t, "Eletrônicos", "")); !errors.Is(err, ErrCategoryAlreadyExists) {
+		t.Errorf("Add() duplicate name error = %v, want ErrCategoryAlreadyExists", err)
+	}
+	if err := repo.Add(ctx, newTestCategory(t, "Celulares", "5b1c3d5e-7f9a-4b2c-8d4e-6f8a0b2c4d6e")); !errors.Is(err, ErrParentNotFound) {
+		t.Errorf("Add() missing parent error = %v, want ErrParentNotFound", err)
+	}
+
+	computers := newTestCategory(t, "Computadores", electronics.ID)
+	if err := repo.Add(ctx, computers); err != nil {
+		t.Fatalf("Add() child error = %v", err)
+	}
+
+	found, err := repo.FindBySlug(ctx, "eletronicos")
+	if err != nil || found.ID != electronics.ID {
+		t.Fatalf("FindBySlug() = %+v, %v", found, err)
+	}
+	if _, err := repo.FindByID(ctx, "missing"); !errors.Is(err, ErrCategoryNotFound) {
+		t.Errorf("FindByID() missing error = %v", err)
+	}
+
+	all, _ := repo.FindAll(ctx)
+	if names := category_entity.Names(all); strings.Join(names, ",") != "Computadores,Eletrônicos" {
+		t.Errorf("FindAll() = %v, want ordered by name", names)
+	}
+
+	// A categoria não pode ser movida para dentro da sua própria subárvore
+	electronics.ParentID = computers.ID
+	if err := repo.Update(ctx, electronics); !errors.Is(err, ErrCategoryCycle) {
+		t.Errorf("Update() cycle error = %v, want ErrCategoryCycle", err)
+	}
+	computers.Slug = "eletronicos"
+	if err := repo.Update(ctx, computers); !errors.Is(err, ErrCategoryAlreadyExists) {
+		t.Errorf("Update() duplicate slug error = %v, want ErrCategoryAlreadyExists", err)
+	}
+
+	if err := repo.Delete(ctx, electronics.ID); !errors.Is(err, ErrCategoryInUse) {
+		t.Errorf("Delete() with subcategories error = %v, want ErrCategoryInUse", err)
+	}
+	if err := repo.Delete(ctx, computers.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := repo.Delete(ctx, computers.ID); !errors.Is(err, ErrCategoryNotFound) {
+		t.Errorf("Delete() missing error = %v", err)
+	}
+}
+
+func TestCategoryRepository_RenamePropagatesToProducts(t *testing.T) {
+	ctx := context.Background()
+	products := product_repository.NewRepository()
+	repo := NewRepository(products)
+
+	category := newTestCategory(t, "Eletronicos", "")
+	_ = repo.Add(ctx, category)
+	addTestProduct(t, products, "Notebook", 1, "Eletronicos", "Computadores")
+
+	if err := repo.Delete(ctx, category.ID); !errors.Is(err, ErrCategoryInUse) {
+		t.Errorf("Delete() with products error = %v, want ErrCategoryInUse", err)
+	}
+
+	if err := category.Update("Eletrônicos", "", "", ""); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if err := repo.Update(ctx, category); err != nil {
+		t.Fatalf("repo.Update() error = %v", err)
+	}
+
+	notebook, _ := products.FindOne(ctx, "Notebook", false)
+	if strings.Join(notebook.Categories, ",") != "Eletrônicos,Computadores" || notebook.Version != 2 {
+		t.Errorf("Notebook = %v (version %d), want renamed category (version 2)", notebook.Categories, notebook.Version)
+	}
+}
+
+func TestCategoryRepository_Merge(t *testing.T) {
+	ctx := context.Background()
+	products := product_repository.NewRepository()
+	repo := NewRepository(products)
+
+	target := newTestCategory(t, "Eletrônicos", "")
+	source := newTestCategory(t, "Eletronicos", "")
+	source.Slug = "eletronicos-2"
+	_ = repo.Add(ctx, target)
+	_ = repo.Add(ctx, source)
+	child := newTestCategory(t, "Celulares", source.ID)
+	_ = repo.Add(ctx, child)
+
+	addTestProduct(t, products, "Notebook", 1, "Eletronicos")
+	addTestProduct(t, products, "Phone", 2, "Eletrônicos", "Eletronicos")
+	addTestProduct(t, products, "Book", 3, "Livros")
+
+	if _, err := repo.Merge(ctx, source.ID, child.ID); !errors.Is(err, ErrInvalidMerge) {
+		t.Errorf("Merge() into a subcategory error = %v, want ErrInvalidMerge", err)
+	}
+	if _, err := repo.Merge(ctx, source.ID, "missing"); !errors.Is(err, ErrCategoryNotFound) {
+		t.Errorf("Merge() into a missing category error = %v, want ErrCategoryNotFound", err)
+	}
+
+	merged, err := repo.Merge(ctx, source.ID, target.ID)
+	if err != nil || merged != 2 {
+		t.Fatalf("Merge() = %d, %v; want 2", merged, err)
+	}
+
+	if _, err := repo.FindByID(ctx, source.ID); !errors.Is(err, ErrCategoryNotFound) {
+		t.Errorf("FindByID() of the merged category error = %v", err)
+	}
+	if moved, _ := repo.FindByID(ctx, child.ID); moved.ParentID != target.ID {
+		t.Errorf("child ParentID = %q, want %q", moved.ParentID, target.ID)
+	}
+	phone, _ := products.FindOne(ctx, "Phone", false)
+	if strings.Join(phone.Categories, ",") != "Eletrônicos" {
+		t.Errorf("Phone categories = %v, want [Eletrônicos]", phone.Categories)
+	}
+	if count, _ := products.CountCategory(ctx, "Eletronicos"); count != 0 {
+		t.Errorf("CountCategory() of the merged category = %d, want 0", count)
+	}
+}
+
+func TestCategoryRepository_Ensure(t *testing.T) {
+	ctx := context.Background()
+	repo := NewRepository(nil)
+	_ = repo.Add(ctx, newTestCategory(t, "Eletrônicos", ""))
+
+	if err := repo.Ensure(ctx, []string{"Eletrônicos", "Eletronicos", "Livros", "Livros"}); err != nil {
+		t.Fatalf("Ensure() error = %v", err)
+	}
+
+	all, _ := repo.FindAll(ctx)
+	if len(all) != 3 {
+		t.Fatalf("FindAll() = %d categories, want 3", len(all))
+	}
+	typo, err := repo.FindBySlug(ctx, "eletronicos-2")
+	if err != nil || typo.Name != "Eletronicos" || typo.ParentID != "" {
+		t.Errorf("FindBySlug(eletronicos-2) = %+v, %v", typo, err)
+	}
+
+	if err := repo.Ensure(ctx, []string{strings.Repeat("a", category_entity.MaxNameLength+1)}); err == nil {
+		t.Error("Ensure() with a long name error = nil")
+	}
+}
@@ -1,58 +1,226 @@
 package product_entity
 
 import (
-	"errors"
+	"strings"
+	"time"
 
+	product_errors "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/errors"
 	product_events "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/events"
+	product_valueobject "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/valueobject"
 	shared_events "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/events"
+	shared_identity "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/identity"
 )
 
 type Product struct {
+	ID         string
 	Name       string
 	Sku        int
 	Categories []string
-	Price      int
-	*product_events.ProductCreatedEvent
+	Price      product_valueobject.Money
+	CreatedAt  time.Time
+	DeletedAt  *time.Time
+	// Version começa em 1 e é incrementada a cada Update, Delete ou Restore
+	Version int
+
+	// Versão com que o produto foi lido do repositório; veja MarkLoaded
+	loadedVersion int
+
+	// Eventos de domínio ainda não publicados; veja PullEvents
+	events []shared_events.Event
+}
+
+// NewProduct cria o produto e registra o evento product.created
+func NewProduct(name string, sku int, categories []string, price product_valueobject.Money) (*Product, error) {
+	ok, err := Validate(name, sku, categories, price)
+
+	if !ok {
+		return nil, err
+	}
+
+	p := &Product{ID: shared_identity.NewUUID(), Name: name, Sku: sku, Categories: categories, Price: price, CreatedAt: time.Now().UTC(), Version: 1}
+
+	p.record(product_events.NewProductCreatedEvent(p.GetID(), p.GetName(), p.GetSku(), p.GetCategories(), p.GetPrice()))
+
+	return p, nil
 }
 
-func NewProduct(name string, sku int, categories []string, price int, dispatcher *shared_events.EventDispatcher) (*Product, *product_events.ProductCreatedEvent, error) {
+// Update altera os dados do produto após validá-los e registra o evento product.updated
+func (p *Product) Update(name string, sku int, categories []string, price product_valueobject.Money) error {
 	ok, err := Validate(name, sku, categories, price)
 
 	if !ok {
-		return nil, nil, err
+		return err
 	}
 
-	p := &Product{Name: name, Sku: sku, Categories: categories, Price: price}
+	before := p.Snapshot()
+
+	p.Name = name
+	p.Sku = sku
+	p.Categories = categories
+	p.Price = price
+	p.Version++
 
-	event := product_events.NewProductCreatedEvent(p.GetName(), p.GetSku(), p.GetCategories(), p.GetPrice())
-	if dispatcher != nil {
-		dispatcher.Dispatch(event.EventName(), event)
+	p.record(product_events.NewProductUpdatedEvent(before, p.Snapshot()))
+
+	return nil
+}
+
+// Delete marca o produto como excluído e registra o evento product.deleted
+func (p *Product) Delete() error {
+	if p.IsDeleted() {
+		return product_errors.ErrAlreadyDeleted
 	}
 
-	return p, event, nil
+	now := time.Now().UTC()
+	p.DeletedAt = &now
+	p.Version++
+
+	p.record(product_events.NewProductDeletedEvent(p.ID, p.Name, p.Sku, now))
 
+	return nil
 }
 
-func Validate(name string, sku int, categories []string, price int) (bool, error) {
+// Restore desfaz a exclusão do produto e registra o evento product.restored
+func (p *Product) Restore() error {
+	if !p.IsDeleted() {
+		return product_errors.ErrNotDeleted
+	}
+
+	p.DeletedAt = nil
+	p.Version++
+
+	p.record(product_events.NewProductRestoredEvent(p.ID, p.Name, p.Sku))
+
+	return nil
+}
+
+// ReplaceCategories troca as categorias from por to, sem repeti-la, como na renomeação ou
+// fusão de categorias, e registra o evento product.updated. Retorna false, sem alterar o
+// produto, se ele não tem nenhuma das categorias from.
+func (p *Product) ReplaceCategories(from []string, to string) bool {
+	categories, changed := replaceCategories(p.Categories, from, to)
+	if !changed {
+		return false
+	}
+
+	before := p.Snapshot()
+
+	p.Categories = categories
+	p.Version++
+
+	p.record(product_events.NewProductUpdatedEvent(before, p.Snapshot()))
+
+	return true
+}
+
+// replaceCategories retorna as categorias com from trocadas por to, na posição da primeira
+// ocorrência, e se alguma foi trocada
+func replaceCategories(categories, from []string, to string) ([]string, bool) {
+	replace := make(map[string]bool, len(from))
+	for _, name := range from {
+		replace[name] = name != to
+	}
+
+	var (
+		result  = make([]string, 0, len(categories))
+		changed bool
+		hasTo   bool
+	)
+	for _, category := range categories {
+		if replace[category] {
+			changed = true
+			category = to
+		}
+		if category == to {
+			if hasTo {
+				continue
+			}
+			hasTo = true
+		}
+		result = append(result, category)
+	}
+
+	return result, changed
+}
+
+// PullEvents retorna os eventos registrados desde a última chamada e os remove do produto.
+// Quem persiste o produto é responsável por publicá-los depois que a gravação for confirmada.
+func (p *Product) PullEvents() []shared_events.Event {
+	events := p.events
+	p.events = nil
+	return events
+}
+
+func (p *Product) record(event shared_events.Event) {
+	p.events = append(p.events, event)
+}
+
+// IsDeleted indica se o produto foi excluído logicamente
+func (p *Product) IsDeleted() bool {
+	return p.DeletedAt != nil
+}
+
+func Validate(name string, sku int, categories []string, price product_valueobject.Money) (bool, error) {
+	var violations product_errors.ValidationErrors
+
 	if name == "" {
-		return false, errors.New("name is required")
+		violations = append(violations, product_errors.NewValidationError("name", product_errors.CodeRequired, "name is required"))
+	} else if isReservedName(name) {
+		violations = append(violations, product_errors.NewValidationError("name", product_errors.CodeReserved, "name is reserved"))
 	}
 
 	if sku <= 0 {
-		return false, errors.New("sku is required")
+		violations = append(violations, product_errors.NewValidationError("sku", product_errors.CodeMustBePositive, "sku must be positive"))
 	}
 
 	if len(categories) == 0 {
-		return false, errors.New("categories is required")
+		violations = append(violations, product_errors.NewValidationError("categories", product_errors.CodeRequired, "categories is required"))
+	} else if hasDuplicates(categories) {
+		violations = append(violations, product_errors.NewValidationError("categories", product_errors.CodeInvalid, "categories must not contain duplicates"))
 	}
 
-	if price <= 0 {
-		return false, errors.New("price is required")
+	if !price.IsPositive() {
+		violations = append(violations, product_errors.NewValidationError("price", product_errors.CodeMustBePositive, "price must be positive"))
+	}
+
+	if len(violations) > 0 {
+		return false, violations
 	}
 
 	return true, nil
 }
 
+// reservedNames são os segmentos de GET /api/v1/products/{segmento} usados por outras rotas
+// (busca, exportação, change feed e busca por ID e SKU). Um produto com um desses nomes, ou
+// com um nome que comece por um deles seguido de "/", não seria encontrado pelo nome.
+var reservedNames = []string{"search", "export", "changes", "id", "sku"}
+
+// isReservedName informa se o nome coincide com uma das rotas de produtos
+func isReservedName(name string) bool {
+	for _, reserved := range reservedNames {
+		if name == reserved || strings.HasPrefix(name, reserved+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// hasDuplicates informa se alguma categoria aparece mais de uma vez
+func hasDuplicates(categories []string) bool {
+	seen := make(map[string]bool, len(categories))
+	for _, category := range categories {
+		if seen[category] {
+			return true
+		}
+		seen[category] = true
+	}
+	return false
+}
+
+func (p *Product) GetID() string {
+	return p.ID
+}
+
 func (p *Product) GetName() string {
 	return p.Name
 }
@@ -65,6 +233,33 @@ func (p *Product) GetCategories() []string {
 	return p.Categories
 }
 
-func (p *Product) GetPrice() int {
+func (p *Product) GetPrice() product_valueobject.Money {
 	return p.Price
 }
+
+// MarkLoaded registra a versão atual como a versão com que o produto foi lido. Os
+// repositórios a chamam ao ler o produto, antes de qualquer alteração.
+func (p *Product) MarkLoaded() {
+	p.loadedVersion = p.Version
+}
+
+// ExpectedVersion é a versão com que o produto foi carregado, antes das alterações
+// pendentes de gravação, quantas forem; o repositório só grava se a versão armazenada
+// for esta
+func (p *Product) ExpectedVersion() int {
+	return p.loadedVersion
+}
+
+// Snapshot retorna uma cópia do estado atual do produto
+func (p *Product) Snapshot() product_events.ProductSnapshot {
+	categories := make([]string, len(p.Categories))
+	copy(categories, p.Categories)
+
+	return product_events.ProductSnapshot{
+		ID:         p.ID,
+		Name:       p.Name,
+		Sku:        p.Sku,
+		Categories: categories,
+		Price:      p.Price,
+	}
+}
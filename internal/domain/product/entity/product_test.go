@@ -1,18 +1,37 @@
 package product_entity
 
 import (
+	"encoding/json"
+	"errors"
+	"reflect"
 	"testing"
 
+	product_errors "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/errors"
+	product_events "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/events"
+	product_valueobject "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/valueobject"
+
 	shared_events "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/events"
+	shared_identity "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/identity"
 )
 
+// pullSingleEvent retira os eventos pendentes do produto e exige que haja exatamente um
+func pullSingleEvent(t *testing.T, product *Product) shared_events.Event {
+	t.Helper()
+
+	events := product.PullEvents()
+	if len(events) != 1 {
+		t.Fatalf("PullEvents() returned %d events, want 1", len(events))
+	}
+	return events[0]
+}
+
 func TestNewProduct(t *testing.T) {
 	tests := []struct {
 		name           string
 		productName    string
 		sku            int
 		categories     []string
-		price          int
+		price          product_valueobject.Money
 		wantErr        bool
 		expectedErrMsg string
 	}{
@@ -21,7 +40,7 @@ func TestNewProduct(t *testing.T) {
 			productName: "Notebook",
 			sku:         12345,
 			categories:  []string{"Electronics", "Computers"},
-			price:       3500,
+			price:       product_valueobject.MustBRL(3500),
 			wantErr:     false,
 		},
 		{
@@ -29,7 +48,7 @@ func TestNewProduct(t *testing.T) {
 			productName:    "",
 			sku:            12345,
 			categories:     []string{"Electronics"},
-			price:          3500,
+			price:          product_valueobject.MustBRL(3500),
 			wantErr:        true,
 			expectedErrMsg: "name is required",
 		},
@@ -38,25 +57,25 @@ func TestNewProduct(t *testing.T) {
 			productName:    "Notebook",
 			sku:            0,
 			categories:     []string{"Electronics"},
-			price:          3500,
+			price:          product_valueobject.MustBRL(3500),
 			wantErr:        true,
-			expectedErrMsg: "sku is required",
+			expectedErrMsg: "sku must be positive",
 		},
 		{
 			name:           "negative sku",
 			productName:    "Notebook",
 			sku:            -1,
 			categories:     []string{"Electronics"},
-			price:          3500,
+			price:          product_valueobject.MustBRL(3500),
 			wantErr:        true,
-			expectedErrMsg: "sku is required",
+			expectedErrMsg: "sku must be positive",
 		},
 		{
 			name:           "empty categories",
 			productName:    "Notebook",
 			sku:            12345,
 			categories:     []string{},
-			price:          3500,
+			price:          product_valueobject.MustBRL(3500),
 			wantErr:        true,
 			expectedErrMsg: "categories is required",
 		},
@@ -65,7 +84,7 @@ func TestNewProduct(t *testing.T) {
 			productName:    "Notebook",
 			sku:            12345,
 			categories:     nil,
-			price:          3500,
+			price:          product_valueobject.MustBRL(3500),
 			wantErr:        true,
 			expectedErrMsg: "categories is required",
 		},
@@ -74,34 +93,32 @@ func TestNewProduct(t *testing.T) {
 			productName:    "Notebook",
 			sku:            12345,
 			categories:     []string{"Electronics"},
-			price:          0,
+			price:          product_valueobject.MustBRL(0),
 			wantErr:        true,
-			expectedErrMsg: "price is required",
+			expectedErrMsg: "price must be positive",
 		},
 		{
 			name:           "negative price",
 			productName:    "Notebook",
 			sku:            12345,
 			categories:     []string{"Electronics"},
-			price:          -100,
+			price:          product_valueobject.MustBRL(-100),
 			wantErr:        true,
-			expectedErrMsg: "price is required",
+			expectedErrMsg: "price must be positive",
 		},
 		{
 			name:        "multiple categories",
 			productName: "Gaming Mouse",
 			sku:         99999,
 			categories:  []string{"Electronics", "Gaming", "Accessories"},
-			price:       299,
+			price:       product_valueobject.MustBRL(299),
 			wantErr:     false,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			dispatcher := shared_events.NewEventDispatcher()
-
-			product, event, err := NewProduct(tt.productName, tt.sku, tt.categories, tt.price, dispatcher)
+			product, err := NewProduct(tt.productName, tt.sku, tt.categories, tt.price)
 
 			if tt.wantErr {
 				if err == nil {
@@ -114,9 +131,6 @@ func TestNewProduct(t *testing.T) {
 				if product != nil {
 					t.Errorf("NewProduct() expected nil product, got %v", product)
 				}
-				if event != nil {
-					t.Errorf("NewProduct() expected nil event, got %v", event)
-				}
 			} else {
 				if err != nil {
 					t.Errorf("NewProduct() unexpected error = %v", err)
@@ -126,12 +140,21 @@ func TestNewProduct(t *testing.T) {
 					t.Error("NewProduct() expected product, got nil")
 					return
 				}
-				if event == nil {
-					t.Error("NewProduct() expected event, got nil")
-					return
+				event, ok := pullSingleEvent(t, product).(*product_events.ProductCreatedEvent)
+				if !ok {
+					t.Fatal("NewProduct() did not record a ProductCreatedEvent")
 				}
 
 				// Verificar campos do produto
+				if !shared_identity.IsValidUUID(product.GetID()) {
+					t.Errorf("Product.ID = %q, want a valid uuid", product.GetID())
+				}
+				if product.CreatedAt.IsZero() {
+					t.Error("Product.CreatedAt should be set")
+				}
+				if event.ID != product.GetID() {
+					t.Errorf("event.ID = %v, want %v", event.ID, product.GetID())
+				}
 				if product.GetName() != tt.productName {
 					t.Errorf("Product.Name = %v, want %v", product.GetName(), tt.productName)
 				}
@@ -144,22 +167,65 @@ func TestNewProduct(t *testing.T) {
 				if len(product.GetCategories()) != len(tt.categories) {
 					t.Errorf("Product.Categories length = %v, want %v", len(product.GetCategories()), len(tt.categories))
 				}
+				if product.Version != 1 {
+					t.Errorf("Product.Version = %v, want 1", product.Version)
+				}
 			}
 		})
 	}
 }
 
-func TestNewProduct_WithNilDispatcher(t *testing.T) {
-	product, event, err := NewProduct("Test Product", 123, []string{"Test"}, 100, nil)
+func TestNewProduct_GeneratesUniqueIDs(t *testing.T) {
+	first, _ := NewProduct("First", 1, []string{"Test"}, product_valueobject.MustBRL(100))
+	second, _ := NewProduct("Second", 2, []string{"Test"}, product_valueobject.MustBRL(100))
+
+	if first.GetID() == second.GetID() {
+		t.Errorf("NewProduct() generated the same ID twice: %v", first.GetID())
+	}
+}
 
+func TestProduct_PullEvents(t *testing.T) {
+	product, err := NewProduct("Test Product", 123, []string{"Test"}, product_valueobject.MustBRL(100))
 	if err != nil {
-		t.Errorf("NewProduct() with nil dispatcher unexpected error = %v", err)
+		t.Fatalf("NewProduct() unexpected error = %v", err)
+	}
+	if err := product.Update("Test Product 2", 123, []string{"Test"}, product_valueobject.MustBRL(200)); err != nil {
+		t.Fatalf("Update() unexpected error = %v", err)
 	}
-	if product == nil {
-		t.Error("NewProduct() with nil dispatcher expected product, got nil")
+
+	events := product.PullEvents()
+	if len(events) != 2 {
+		t.Fatalf("PullEvents() returned %d events, want 2", len(events))
 	}
-	if event == nil {
-		t.Error("NewProduct() with nil dispatcher expected event, got nil")
+	if events[0].EventName() != "product.created" || events[1].EventName() != "product.updated" {
+		t.Errorf("PullEvents() = [%s %s], want events in the order they were recorded", events[0].EventName(), events[1].EventName())
+	}
+
+	if again := product.PullEvents(); len(again) != 0 {
+		t.Errorf("PullEvents() second call returned %d events, want 0", len(again))
+	}
+}
+
+func TestProduct_JSONDoesNotExposeEvents(t *testing.T) {
+	product, _ := NewProduct("Test Product", 123, []string{"Test"}, product_valueobject.MustBRL(100))
+
+	data, err := json.Marshal(product)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	want := []string{"ID", "Name", "Sku", "Categories", "Price", "CreatedAt", "DeletedAt", "Version"}
+	if len(fields) != len(want) {
+		t.Errorf("Product JSON has fields %v, want only %v", fields, want)
+	}
+	for _, field := range want {
+		if _, ok := fields[field]; !ok {
+			t.Errorf("Product JSON is missing field %s", field)
+		}
 	}
 }
 
@@ -169,7 +235,7 @@ func TestValidate(t *testing.T) {
 		inputName  string
 		sku        int
 		categories []string
-		price      int
+		price      product_valueobject.Money
 		wantValid  bool
 		wantErr    string
 	}{
@@ -178,7 +244,7 @@ func TestValidate(t *testing.T) {
 			inputName:  "Product",
 			sku:        100,
 			categories: []string{"Category"},
-			price:      50,
+			price:      product_valueobject.MustBRL(50),
 			wantValid:  true,
 			wantErr:    "",
 		},
@@ -187,36 +253,81 @@ func TestValidate(t *testing.T) {
 			inputName:  "",
 			sku:        100,
 			categories: []string{"Category"},
-			price:      50,
+			price:      product_valueobject.MustBRL(50),
 			wantValid:  false,
 			wantErr:    "name is required",
 		},
+		{
+			name:       "reserved name",
+			inputName:  "search",
+			sku:        100,
+			categories: []string{"Category"},
+			price:      product_valueobject.MustBRL(50),
+			wantValid:  false,
+			wantErr:    "name is reserved",
+		},
+		{
+			name:       "name under a reserved route",
+			inputName:  "sku/100",
+			sku:        100,
+			categories: []string{"Category"},
+			price:      product_valueobject.MustBRL(50),
+			wantValid:  false,
+			wantErr:    "name is reserved",
+		},
+		{
+			name:       "name starting with a reserved word",
+			inputName:  "search engine",
+			sku:        100,
+			categories: []string{"Category"},
+			price:      product_valueobject.MustBRL(50),
+			wantValid:  true,
+			wantErr:    "",
+		},
 		{
 			name:       "invalid sku",
 			inputName:  "Product",
 			sku:        0,
 			categories: []string{"Category"},
-			price:      50,
+			price:      product_valueobject.MustBRL(50),
 			wantValid:  false,
-			wantErr:    "sku is required",
+			wantErr:    "sku must be positive",
 		},
 		{
 			name:       "invalid categories",
 			inputName:  "Product",
 			sku:        100,
 			categories: []string{},
-			price:      50,
+			price:      product_valueobject.MustBRL(50),
 			wantValid:  false,
 			wantErr:    "categories is required",
 		},
+		{
+			name:       "duplicated categories",
+			inputName:  "Product",
+			sku:        100,
+			categories: []string{"Category", "Other", "Category"},
+			price:      product_valueobject.MustBRL(50),
+			wantValid:  false,
+			wantErr:    "categories must not contain duplicates",
+		},
 		{
 			name:       "invalid price",
 			inputName:  "Product",
 			sku:        100,
 			categories: []string{"Category"},
-			price:      0,
+			price:      product_valueobject.MustBRL(0),
+			wantValid:  false,
+			wantErr:    "price must be positive",
+		},
+		{
+			name:       "every violation is reported",
+			inputName:  "",
+			sku:        -1,
+			categories: nil,
+			price:      product_valueobject.MustBRL(-10),
 			wantValid:  false,
-			wantErr:    "price is required",
+			wantErr:    "name is required; sku must be positive; categories is required; price must be positive",
 		},
 	}
 
@@ -231,8 +342,8 @@ func TestValidate(t *testing.T) {
 			if tt.wantErr != "" {
 				if err == nil {
 					t.Errorf("Validate() expected error %v, got nil", tt.wantErr)
-				} else if err.Error() != tt.wantErr {
-					t.Errorf("Validate() error = %v, want %v", err.Error(), tt.wantErr)
+				} else if err.Error() != tt.wantErr || !errors.Is(err, product_errors.ErrValidation) {
+					t.Errorf("Validate() error = %v, want validation error %v", err.Error(), tt.wantErr)
 				}
 			} else {
 				if err != nil {
@@ -245,12 +356,19 @@ func TestValidate(t *testing.T) {
 
 func TestProduct_Getters(t *testing.T) {
 	product := &Product{
+		ID:         "3f2b8c1e-4d5a-4b6c-8d7e-9f0a1b2c3d4e",
 		Name:       "Test Product",
 		Sku:        12345,
 		Categories: []string{"Cat1", "Cat2"},
-		Price:      999,
+		Price:      product_valueobject.MustBRL(999),
 	}
 
+	t.Run("GetID", func(t *testing.T) {
+		if got := product.GetID(); got != "3f2b8c1e-4d5a-4b6c-8d7e-9f0a1b2c3d4e" {
+			t.Errorf("GetID() = %v, want %v", got, "3f2b8c1e-4d5a-4b6c-8d7e-9f0a1b2c3d4e")
+		}
+	})
+
 	t.Run("GetName", func(t *testing.T) {
 		if got := product.GetName(); got != "Test Product" {
 			t.Errorf("GetName() = %v, want %v", got, "Test Product")
@@ -274,24 +392,259 @@ func TestProduct_Getters(t *testing.T) {
 	})
 
 	t.Run("GetPrice", func(t *testing.T) {
-		if got := product.GetPrice(); got != 999 {
-			t.Errorf("GetPrice() = %v, want %v", got, 999)
+		if got := product.GetPrice(); got != product_valueobject.MustBRL(999) {
+			t.Errorf("GetPrice() = %v, want %v", got, product_valueobject.MustBRL(999))
 		}
 	})
 }
 
+func TestProduct_Update(t *testing.T) {
+	tests := []struct {
+		name           string
+		newName        string
+		sku            int
+		categories     []string
+		price          product_valueobject.Money
+		wantErr        bool
+		expectedErrMsg string
+	}{
+		{
+			name:       "valid update",
+			newName:    "Notebook Pro",
+			sku:        54321,
+			categories: []string{"Electronics", "Computers"},
+			price:      product_valueobject.MustBRL(4500),
+			wantErr:    false,
+		},
+		{
+			name:           "empty name",
+			newName:        "",
+			sku:            54321,
+			categories:     []string{"Electronics"},
+			price:          product_valueobject.MustBRL(4500),
+			wantErr:        true,
+			expectedErrMsg: "name is required",
+		},
+		{
+			name:           "zero price",
+			newName:        "Notebook Pro",
+			sku:            54321,
+			categories:     []string{"Electronics"},
+			price:          product_valueobject.MustBRL(0),
+			wantErr:        true,
+			expectedErrMsg: "price must be positive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			product := &Product{
+				Name:       "Notebook",
+				Sku:        12345,
+				Categories: []string{"Electronics"},
+				Price:      product_valueobject.MustBRL(3500),
+				Version:    3,
+			}
+			product.MarkLoaded()
+			err := product.Update(tt.newName, tt.sku, tt.categories, tt.price)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Update() expected error, got nil")
+					return
+				}
+				if err.Error() != tt.expectedErrMsg {
+					t.Errorf("Update() error = %v, want %v", err.Error(), tt.expectedErrMsg)
+				}
+				if events := product.PullEvents(); len(events) != 0 {
+					t.Errorf("Update() recorded %d events on error, want 0", len(events))
+				}
+				if product.GetName() != "Notebook" || product.GetPrice() != product_valueobject.MustBRL(3500) || product.Version != 3 {
+					t.Errorf("Update() changed product on error: %+v", product)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Update() unexpected error = %v", err)
+			}
+			event, ok := pullSingleEvent(t, product).(*product_events.ProductUpdatedEvent)
+			if !ok {
+				t.Fatal("Update() did not record a ProductUpdatedEvent")
+			}
+			if event.Before.Name != "Notebook" || event.Before.Price != product_valueobject.MustBRL(3500) {
+				t.Errorf("Update() event.Before = %+v, want original values", event.Before)
+			}
+			if event.After.Name != tt.newName || event.After.Price != tt.price {
+				t.Errorf("Update() event.After = %+v, want updated values", event.After)
+			}
+			if product.GetName() != tt.newName {
+				t.Errorf("Product.Name = %v, want %v", product.GetName(), tt.newName)
+			}
+			if product.GetSku() != tt.sku {
+				t.Errorf("Product.Sku = %v, want %v", product.GetSku(), tt.sku)
+			}
+			if product.GetPrice() != tt.price {
+				t.Errorf("Product.Price = %v, want %v", product.GetPrice(), tt.price)
+			}
+			if product.Version != 4 || product.ExpectedVersion() != 3 {
+				t.Errorf("Product.Version = %d (expected %d), want 4 (expected 3)", product.Version, product.ExpectedVersion())
+			}
+		})
+	}
+}
+
+func TestProduct_DeleteAndRestore(t *testing.T) {
+	product := &Product{
+		Name:       "Notebook",
+		Sku:        12345,
+		Categories: []string{"Electronics"},
+		Price:      product_valueobject.MustBRL(3500),
+		Version:    1,
+	}
+
+	if product.IsDeleted() {
+		t.Fatal("IsDeleted() = true for new product")
+	}
+
+	t.Run("restore active product", func(t *testing.T) {
+		if err := product.Restore(); !errors.Is(err, product_errors.ErrConflict) || err.Error() != "product is not deleted" {
+			t.Errorf("Restore() error = %v, want 'product is not deleted'", err)
+		}
+	})
+
+	t.Run("delete active product", func(t *testing.T) {
+		if err := product.Delete(); err != nil {
+			t.Fatalf("Delete() unexpected error = %v", err)
+		}
+		event := pullSingleEvent(t, product).(*product_events.ProductDeletedEvent)
+		if event.Name != "Notebook" || event.Sku != 12345 {
+			t.Errorf("Delete() event = %+v, want product values", event)
+		}
+		if !product.IsDeleted() {
+			t.Error("IsDeleted() = false after Delete()")
+		}
+		if !product.DeletedAt.Equal(event.DeletedAt) {
+			t.Errorf("DeletedAt = %v, want %v", product.DeletedAt, event.DeletedAt)
+		}
+		if product.Version != 2 {
+			t.Errorf("Version = %d after Delete(), want 2", product.Version)
+		}
+	})
+
+	t.Run("delete deleted product", func(t *testing.T) {
+		if err := product.Delete(); !errors.Is(err, product_errors.ErrConflict) || err.Error() != "product already deleted" {
+			t.Errorf("Delete() error = %v, want 'product already deleted'", err)
+		}
+	})
+
+	t.Run("restore deleted product", func(t *testing.T) {
+		if err := product.Restore(); err != nil {
+			t.Fatalf("Restore() unexpected error = %v", err)
+		}
+		event := pullSingleEvent(t, product).(*product_events.ProductRestoredEvent)
+		if event.Name != "Notebook" {
+			t.Errorf("Restore() event.Name = %v, want Notebook", event.Name)
+		}
+		if product.IsDeleted() {
+			t.Error("IsDeleted() = true after Restore()")
+		}
+		if product.Version != 3 {
+			t.Errorf("Version = %d after Restore(), want 3", product.Version)
+		}
+	})
+}
+
+func TestProduct_ExpectedVersion(t *testing.T) {
+	product := &Product{
+		Name:       "Notebook",
+		Sku:        12345,
+		Categories: []string{"Electronics"},
+		Price:      product_valueobject.MustBRL(3500),
+		Version:    2,
+	}
+	product.MarkLoaded()
+
+	// Várias alterações antes da gravação continuam esperando a versão carregada
+	if err := product.Update("Notebook Pro", 12345, []string{"Electronics"}, product_valueobject.MustBRL(4500)); err != nil {
+		t.Fatalf("Update() unexpected error = %v", err)
+	}
+	if err := product.Delete(); err != nil {
+		t.Fatalf("Delete() unexpected error = %v", err)
+	}
+	if product.Version != 4 || product.ExpectedVersion() != 2 {
+		t.Errorf("Product.Version = %d (expected %d), want 4 (expected 2)", product.Version, product.ExpectedVersion())
+	}
+
+	product.MarkLoaded()
+	if product.ExpectedVersion() != 4 {
+		t.Errorf("ExpectedVersion() after MarkLoaded() = %d, want 4", product.ExpectedVersion())
+	}
+}
+
+func TestProduct_ReplaceCategories(t *testing.T) {
+	product := &Product{
+		Name:       "Notebook",
+		Sku:        12345,
+		Categories: []string{"Eletronicos", "Computadores", "Eletrônicos"},
+		Price:      product_valueobject.MustBRL(3500),
+		Version:    1,
+	}
+
+	t.Run("product without the category", func(t *testing.T) {
+		if product.ReplaceCategories([]string{"Livros"}, "Eletrônicos") {
+			t.Error("ReplaceCategories() = true, want false")
+		}
+		if events := product.PullEvents(); len(events) != 0 || product.Version != 1 {
+			t.Errorf("ReplaceCategories() changed the product: %d events, version %d", len(events), product.Version)
+		}
+	})
+
+	t.Run("replaces without repeating the target", func(t *testing.T) {
+		if !product.ReplaceCategories([]string{"Eletronicos"}, "Eletrônicos") {
+			t.Fatal("ReplaceCategories() = false, want true")
+		}
+		event := pullSingleEvent(t, product).(*product_events.ProductUpdatedEvent)
+		if !reflect.DeepEqual(event.Before.Categories, []string{"Eletronicos", "Computadores", "Eletrônicos"}) {
+			t.Errorf("event.Before.Categories = %v", event.Before.Categories)
+		}
+		if want := []string{"Eletrônicos", "Computadores"}; !reflect.DeepEqual(product.Categories, want) || !reflect.DeepEqual(event.After.Categories, want) {
+			t.Errorf("Categories = %v, event.After.Categories = %v; want %v", product.Categories, event.After.Categories, want)
+		}
+		if product.Version != 2 {
+			t.Errorf("Version = %d after ReplaceCategories(), want 2", product.Version)
+		}
+	})
+}
+
+func TestProduct_Snapshot(t *testing.T) {
+	product := &Product{
+		Name:       "Test Product",
+		Sku:        12345,
+		Categories: []string{"Cat1", "Cat2"},
+		Price:      product_valueobject.MustBRL(999),
+	}
+
+	snapshot := product.Snapshot()
+	product.Categories[0] = "Changed"
+
+	if snapshot.Name != "Test Product" || snapshot.Sku != 12345 || snapshot.Price != product_valueobject.MustBRL(999) {
+		t.Errorf("Snapshot() = %+v, want product values", snapshot)
+	}
+	if snapshot.Categories[0] != "Cat1" {
+		t.Errorf("Snapshot() categories share memory with product: %v", snapshot.Categories)
+	}
+}
+
 // Benchmark tests
 func BenchmarkNewProduct(b *testing.B) {
-	dispatcher := shared_events.NewEventDispatcher()
-
-	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, _, _ = NewProduct("Test Product", 123, []string{"Category"}, 100, dispatcher)
+		_, _ = NewProduct("Test Product", 123, []string{"Category"}, product_valueobject.MustBRL(100))
 	}
 }
 
 func BenchmarkValidate(b *testing.B) {
 	for i := 0; i < b.N; i++ {
-		_, _ = Validate("Product", 100, []string{"Category"}, 50)
+		_, _ = Validate("Product", 100, []string{"Category"}, product_valueobject.MustBRL(50))
 	}
 }
@@ -0,0 +1,108 @@
+package product_errors
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Tipos de erro do domínio de produtos. Use errors.Is para classificar um erro:
+// os erros de Error casam com o seu Kind.
+var (
+	ErrNotFound      = errors.New("product not found")
+	ErrAlreadyExists = errors.New("product already exists")
+	ErrValidation    = errors.New("invalid product")
+	ErrConflict      = errors.New("product state conflict")
+	ErrUnavailable   = errors.New("product storage unavailable")
+)
+
+// Códigos das violações, estáveis para que os clientes possam tratá-las sem ler a mensagem
+const (
+	CodeRequired        = "required"
+	CodeMustBePositive  = "must_be_positive"
+	CodeInvalid         = "invalid"
+	CodeAlreadyExists   = "already_exists"
+	CodeVersionConflict = "version_conflict"
+	CodeUnknownCategory = "unknown_category"
+	CodeReserved        = "reserved"
+)
+
+// Transições de estado inválidas do produto
+var (
+	ErrAlreadyDeleted = &Error{Kind: ErrConflict, Message: "product already deleted"}
+	ErrNotDeleted     = &Error{Kind: ErrConflict, Message: "product is not deleted"}
+)
+
+// ErrVersionConflict indica que o produto foi alterado por outra requisição depois de
+// carregado: a versão esperada não é mais a armazenada
+var ErrVersionConflict = &Error{Kind: ErrConflict, Code: CodeVersionConflict, Message: "product was modified by another request"}
+
+// Error é um erro do domínio de produtos com a mensagem para o cliente.
+// Field e Code indicam o campo envolvido e a regra violada, quando houver.
+type Error struct {
+	Kind    error
+	Field   string
+	Code    string
+	Message string
+	cause   error
+}
+
+// NewValidationError indica que o valor do campo viola a regra identificada por code
+func NewValidationError(field, code, message string) *Error {
+	return &Error{Kind: ErrValidation, Field: field, Code: code, Message: message}
+}
+
+// NewAlreadyExistsError indica que outro produto já usa o valor do campo único (name ou sku)
+func NewAlreadyExistsError(field string) *Error {
+	message := ErrAlreadyExists.Error()
+	if field != "" {
+		message = fmt.Sprintf("product with this %s already exists", field)
+	}
+	return &Error{Kind: ErrAlreadyExists, Field: field, Code: CodeAlreadyExists, Message: message}
+}
+
+// Unavailable marca uma falha de infraestrutura (conexão, timeout) na operação.
+// A mensagem para o cliente não expõe a causa.
+func Unavailable(cause error) *Error {
+	return &Error{Kind: ErrUnavailable, Message: ErrUnavailable.Error(), cause: cause}
+}
+
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return e.Message + ": " + e.cause.Error()
+	}
+	return e.Message
+}
+
+// Is faz errors.Is(err, ErrValidation) casar com qualquer erro de validação, por exemplo
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// ValidationErrors reúne todas as violações encontradas ao validar um produto
+type ValidationErrors []*Error
+
+func (v ValidationErrors) Error() string {
+	messages := make([]string, len(v))
+	for i, err := range v {
+		messages[i] = err.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (v ValidationErrors) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// Unwrap expõe cada violação para errors.As
+func (v ValidationErrors) Unwrap() []error {
+	errs := make([]error, len(v))
+	for i, err := range v {
+		errs[i] = err
+	}
+	return errs
+}
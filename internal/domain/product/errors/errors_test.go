@@ -0,0 +1,68 @@
+package product_errors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestError_Kinds(t *testing.T) {
+	cause := errors.New("dial tcp: connection refused")
+
+	tests := []struct {
+		name        string
+		err         error
+		kind        error
+		wantMessage string
+	}{
+		{name: "validation", err: NewValidationError("sku", CodeRequired, "sku is required"), kind: ErrValidation, wantMessage: "sku is required"},
+		{name: "already exists on a field", err: NewAlreadyExistsError("sku"), kind: ErrAlreadyExists, wantMessage: "product with this sku already exists"},
+		{name: "already exists without field", err: NewAlreadyExistsError(""), kind: ErrAlreadyExists, wantMessage: "product already exists"},
+		{name: "state conflict", err: ErrNotDeleted, kind: ErrConflict, wantMessage: "product is not deleted"},
+		{name: "version conflict", err: ErrVersionConflict, kind: ErrConflict, wantMessage: "product was modified by another request"},
+		{name: "unavailable", err: Unavailable(cause), kind: ErrUnavailable, wantMessage: "product storage unavailable: dial tcp: connection refused"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wrapped := fmt.Errorf("erro ao inserir produto: %w", tt.err)
+			if !errors.Is(wrapped, tt.kind) {
+				t.Errorf("errors.Is(%v, %v) = false", wrapped, tt.kind)
+			}
+			if errors.Is(wrapped, ErrNotFound) {
+				t.Errorf("errors.Is(%v, ErrNotFound) = true", wrapped)
+			}
+			if tt.err.Error() != tt.wantMessage {
+				t.Errorf("Error() = %q, want %q", tt.err.Error(), tt.wantMessage)
+			}
+		})
+	}
+
+	if !errors.Is(Unavailable(cause), cause) {
+		t.Error("Unavailable() should wrap the cause")
+	}
+}
+
+func TestValidationErrors(t *testing.T) {
+	err := fmt.Errorf("erro ao validar produto: %w", ValidationErrors{
+		NewValidationError("name", CodeRequired, "name is required"),
+		NewValidationError("price", CodeMustBePositive, "price must be positive"),
+	})
+
+	if !errors.Is(err, ErrValidation) {
+		t.Errorf("errors.Is(%v, ErrValidation) = false", err)
+	}
+	if err.Error() != "erro ao validar produto: name is required; price must be positive" {
+		t.Errorf("Error() = %q", err.Error())
+	}
+
+	var violations ValidationErrors
+	if !errors.As(err, &violations) || len(violations) != 2 || violations[1].Field != "price" || violations[1].Code != CodeMustBePositive {
+		t.Errorf("errors.As(ValidationErrors) = %v", violations)
+	}
+
+	var first *Error
+	if !errors.As(err, &first) || first.Field != "name" {
+		t.Errorf("errors.As(*Error) = %v, want the first violation", first)
+	}
+}
@@ -0,0 +1,22 @@
+package product_events
+
+import (
+	shared_events "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/events"
+)
+
+// RegisterEvents registra os eventos de produto no registry, usado para desserializar
+// eventos persistidos (por exemplo, no outbox). Ao mudar o schema de um evento,
+// implemente SchemaVersion e registre aqui o upcaster da versão anterior.
+func RegisterEvents(registry *shared_events.EventRegistry) {
+	registry.Register(func() shared_events.Event { return &ProductCreatedEvent{} })
+	registry.Register(func() shared_events.Event { return &ProductUpdatedEvent{} })
+	registry.Register(func() shared_events.Event { return &ProductDeletedEvent{} })
+	registry.Register(func() shared_events.Event { return &ProductRestoredEvent{} })
+}
+
+// NewEventRegistry cria um registry com os eventos de produto
+func NewEventRegistry() *shared_events.EventRegistry {
+	registry := shared_events.NewEventRegistry()
+	RegisterEvents(registry)
+	return registry
+}
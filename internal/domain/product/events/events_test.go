@@ -0,0 +1,48 @@
+package product_events
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	product_valueobject "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/valueobject"
+	shared_events "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/events"
+)
+
+func TestRegisterEvents(t *testing.T) {
+	deletedAt := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	events := []shared_events.AggregateEvent{
+		NewProductCreatedEvent("id-1", "Notebook", 1, []string{"Electronics"}, product_valueobject.MustBRL(3500)),
+		NewProductUpdatedEvent(ProductSnapshot{ID: "id-1", Name: "Notebook", Price: product_valueobject.MustBRL(3500)}, ProductSnapshot{ID: "id-1", Name: "Notebook Pro", Price: product_valueobject.MustBRL(4500)}),
+		NewProductDeletedEvent("id-1", "Notebook Pro", 1, deletedAt),
+		NewProductRestoredEvent("id-1", "Notebook Pro", 1),
+	}
+
+	registry := NewEventRegistry()
+	if names := registry.Names(); len(names) != len(events) {
+		t.Errorf("Names() = %v, want %d events", names, len(events))
+	}
+
+	for _, event := range events {
+		t.Run(event.EventName(), func(t *testing.T) {
+			if event.AggregateID() != "id-1" {
+				t.Errorf("AggregateID() = %q, want id-1", event.AggregateID())
+			}
+
+			data, err := registry.Marshal(event)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			envelope, err := registry.Unmarshal(data)
+			if err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if envelope.Name != event.EventName() || envelope.Version != 1 || envelope.AggregateID != "id-1" {
+				t.Errorf("envelope = %+v", envelope)
+			}
+			if !reflect.DeepEqual(envelope.Payload, event) {
+				t.Errorf("round trip = %+v, want %+v", envelope.Payload, event)
+			}
+		})
+	}
+}
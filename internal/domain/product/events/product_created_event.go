@@ -1,14 +1,20 @@
 package product_events
 
+import (
+	product_valueobject "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/valueobject"
+)
+
 type ProductCreatedEvent struct {
+	ID         string
 	Name       string
 	Sku        int
 	Categories []string
-	Price      int
+	Price      product_valueobject.Money
 }
 
-func NewProductCreatedEvent(name string, sku int, categories []string, price int) *ProductCreatedEvent {
+func NewProductCreatedEvent(id string, name string, sku int, categories []string, price product_valueobject.Money) *ProductCreatedEvent {
 	return &ProductCreatedEvent{
+		ID:         id,
 		Name:       name,
 		Sku:        sku,
 		Categories: categories,
@@ -19,3 +25,7 @@ func NewProductCreatedEvent(name string, sku int, categories []string, price int
 func (e *ProductCreatedEvent) EventName() string {
 	return "product.created"
 }
+
+func (e *ProductCreatedEvent) AggregateID() string {
+	return e.ID
+}
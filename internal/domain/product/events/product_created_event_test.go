@@ -2,48 +2,56 @@ package product_events
 
 import (
 	"testing"
+
+	product_valueobject "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/valueobject"
 )
 
 func TestNewProductCreatedEvent(t *testing.T) {
 	tests := []struct {
 		name       string
+		id         string
 		prodName   string
 		sku        int
 		categories []string
-		price      int
+		price      product_valueobject.Money
 	}{
 		{
 			name:       "valid event",
+			id:         "3f2b8c1e-4d5a-4b6c-8d7e-9f0a1b2c3d4e",
 			prodName:   "Notebook",
 			sku:        12345,
 			categories: []string{"Electronics", "Computers"},
-			price:      3500,
+			price:      product_valueobject.MustBRL(3500),
 		},
 		{
 			name:       "minimal event",
 			prodName:   "Test",
 			sku:        1,
 			categories: []string{"Test"},
-			price:      1,
+			price:      product_valueobject.MustBRL(1),
 		},
 		{
 			name:       "multiple categories",
 			prodName:   "Gaming Setup",
 			sku:        99999,
 			categories: []string{"Gaming", "Electronics", "Computers", "Peripherals"},
-			price:      15000,
+			price:      product_valueobject.MustBRL(15000),
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			event := NewProductCreatedEvent(tt.prodName, tt.sku, tt.categories, tt.price)
+			event := NewProductCreatedEvent(tt.id, tt.prodName, tt.sku, tt.categories, tt.price)
 
 			if event == nil {
 				t.Fatal("NewProductCreatedEvent() returned nil")
 			}
 
 			// Verificar campos
+			if event.ID != tt.id {
+				t.Errorf("ID = %v, want %v", event.ID, tt.id)
+			}
+
 			if event.Name != tt.prodName {
 				t.Errorf("Name = %v, want %v", event.Name, tt.prodName)
 			}
@@ -70,7 +78,7 @@ func TestNewProductCreatedEvent(t *testing.T) {
 }
 
 func TestProductCreatedEvent_EventName(t *testing.T) {
-	event := NewProductCreatedEvent("Test", 123, []string{"Test"}, 100)
+	event := NewProductCreatedEvent("3f2b8c1e-4d5a-4b6c-8d7e-9f0a1b2c3d4e", "Test", 123, []string{"Test"}, product_valueobject.MustBRL(100))
 
 	expectedName := "product.created"
 	if event.EventName() != expectedName {
@@ -83,7 +91,7 @@ func TestProductCreatedEvent_Fields(t *testing.T) {
 		Name:       "Test Product",
 		Sku:        999,
 		Categories: []string{"Cat1", "Cat2", "Cat3"},
-		Price:      500,
+		Price:      product_valueobject.MustBRL(500),
 	}
 
 	t.Run("Name", func(t *testing.T) {
@@ -105,7 +113,7 @@ func TestProductCreatedEvent_Fields(t *testing.T) {
 	})
 
 	t.Run("Price", func(t *testing.T) {
-		if event.Price != 500 {
+		if event.Price != product_valueobject.MustBRL(500) {
 			t.Errorf("Price = %v, want %v", event.Price, 500)
 		}
 	})
@@ -117,12 +125,12 @@ func BenchmarkNewProductCreatedEvent(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_ = NewProductCreatedEvent("Notebook", 12345, categories, 3500)
+		_ = NewProductCreatedEvent("3f2b8c1e-4d5a-4b6c-8d7e-9f0a1b2c3d4e", "Notebook", 12345, categories, product_valueobject.MustBRL(3500))
 	}
 }
 
 func BenchmarkProductCreatedEvent_EventName(b *testing.B) {
-	event := NewProductCreatedEvent("Test", 123, []string{"Test"}, 100)
+	event := NewProductCreatedEvent("3f2b8c1e-4d5a-4b6c-8d7e-9f0a1b2c3d4e", "Test", 123, []string{"Test"}, product_valueobject.MustBRL(100))
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
@@ -0,0 +1,27 @@
+package product_events
+
+import "time"
+
+type ProductDeletedEvent struct {
+	ID        string
+	Name      string
+	Sku       int
+	DeletedAt time.Time
+}
+
+func NewProductDeletedEvent(id string, name string, sku int, deletedAt time.Time) *ProductDeletedEvent {
+	return &ProductDeletedEvent{
+		ID:        id,
+		Name:      name,
+		Sku:       sku,
+		DeletedAt: deletedAt,
+	}
+}
+
+func (e *ProductDeletedEvent) EventName() string {
+	return "product.deleted"
+}
+
+func (e *ProductDeletedEvent) AggregateID() string {
+	return e.ID
+}
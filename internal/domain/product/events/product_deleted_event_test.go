@@ -0,0 +1,41 @@
+package product_events
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewProductDeletedEvent(t *testing.T) {
+	deletedAt := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	event := NewProductDeletedEvent("3f2b8c1e-4d5a-4b6c-8d7e-9f0a1b2c3d4e", "Notebook", 12345, deletedAt)
+
+	if event == nil {
+		t.Fatal("NewProductDeletedEvent() returned nil")
+	}
+
+	if event.ID != "3f2b8c1e-4d5a-4b6c-8d7e-9f0a1b2c3d4e" {
+		t.Errorf("ID = %v, want %v", event.ID, "3f2b8c1e-4d5a-4b6c-8d7e-9f0a1b2c3d4e")
+	}
+
+	if event.Name != "Notebook" {
+		t.Errorf("Name = %v, want %v", event.Name, "Notebook")
+	}
+
+	if event.Sku != 12345 {
+		t.Errorf("Sku = %v, want %v", event.Sku, 12345)
+	}
+
+	if !event.DeletedAt.Equal(deletedAt) {
+		t.Errorf("DeletedAt = %v, want %v", event.DeletedAt, deletedAt)
+	}
+}
+
+func TestProductDeletedEvent_EventName(t *testing.T) {
+	event := NewProductDeletedEvent("3f2b8c1e-4d5a-4b6c-8d7e-9f0a1b2c3d4e", "Test", 123, time.Now())
+
+	expectedName := "product.deleted"
+	if event.EventName() != expectedName {
+		t.Errorf("EventName() = %v, want %v", event.EventName(), expectedName)
+	}
+}
@@ -0,0 +1,23 @@
+package product_events
+
+type ProductRestoredEvent struct {
+	ID   string
+	Name string
+	Sku  int
+}
+
+func NewProductRestoredEvent(id string, name string, sku int) *ProductRestoredEvent {
+	return &ProductRestoredEvent{
+		ID:   id,
+		Name: name,
+		Sku:  sku,
+	}
+}
+
+func (e *ProductRestoredEvent) EventName() string {
+	return "product.restored"
+}
+
+func (e *ProductRestoredEvent) AggregateID() string {
+	return e.ID
+}
@@ -0,0 +1,34 @@
+package product_events
+
+import (
+	"testing"
+)
+
+func TestNewProductRestoredEvent(t *testing.T) {
+	event := NewProductRestoredEvent("3f2b8c1e-4d5a-4b6c-8d7e-9f0a1b2c3d4e", "Notebook", 12345)
+
+	if event == nil {
+		t.Fatal("NewProductRestoredEvent() returned nil")
+	}
+
+	if event.ID != "3f2b8c1e-4d5a-4b6c-8d7e-9f0a1b2c3d4e" {
+		t.Errorf("ID = %v, want %v", event.ID, "3f2b8c1e-4d5a-4b6c-8d7e-9f0a1b2c3d4e")
+	}
+
+	if event.Name != "Notebook" {
+		t.Errorf("Name = %v, want %v", event.Name, "Notebook")
+	}
+
+	if event.Sku != 12345 {
+		t.Errorf("Sku = %v, want %v", event.Sku, 12345)
+	}
+}
+
+func TestProductRestoredEvent_EventName(t *testing.T) {
+	event := NewProductRestoredEvent("3f2b8c1e-4d5a-4b6c-8d7e-9f0a1b2c3d4e", "Test", 123)
+
+	expectedName := "product.restored"
+	if event.EventName() != expectedName {
+		t.Errorf("EventName() = %v, want %v", event.EventName(), expectedName)
+	}
+}
@@ -0,0 +1,34 @@
+package product_events
+
+import (
+	product_valueobject "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/valueobject"
+)
+
+// ProductSnapshot representa o estado de um produto em um determinado momento
+type ProductSnapshot struct {
+	ID         string
+	Name       string
+	Sku        int
+	Categories []string
+	Price      product_valueobject.Money
+}
+
+type ProductUpdatedEvent struct {
+	Before ProductSnapshot
+	After  ProductSnapshot
+}
+
+func NewProductUpdatedEvent(before, after ProductSnapshot) *ProductUpdatedEvent {
+	return &ProductUpdatedEvent{
+		Before: before,
+		After:  after,
+	}
+}
+
+func (e *ProductUpdatedEvent) EventName() string {
+	return "product.updated"
+}
+
+func (e *ProductUpdatedEvent) AggregateID() string {
+	return e.After.ID
+}
@@ -0,0 +1,57 @@
+package product_events
+
+import (
+	"testing"
+
+	product_valueobject "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/valueobject"
+)
+
+func TestNewProductUpdatedEvent(t *testing.T) {
+	before := ProductSnapshot{
+		Name:       "Notebook",
+		Sku:        12345,
+		Categories: []string{"Electronics"},
+		Price:      product_valueobject.MustBRL(3500),
+	}
+	after := ProductSnapshot{
+		Name:       "Notebook Pro",
+		Sku:        12345,
+		Categories: []string{"Electronics", "Computers"},
+		Price:      product_valueobject.MustBRL(4500),
+	}
+
+	event := NewProductUpdatedEvent(before, after)
+
+	if event == nil {
+		t.Fatal("NewProductUpdatedEvent() returned nil")
+	}
+
+	if event.Before.Name != "Notebook" {
+		t.Errorf("Before.Name = %v, want %v", event.Before.Name, "Notebook")
+	}
+
+	if event.Before.Price != product_valueobject.MustBRL(3500) {
+		t.Errorf("Before.Price = %v, want %v", event.Before.Price, product_valueobject.MustBRL(3500))
+	}
+
+	if event.After.Name != "Notebook Pro" {
+		t.Errorf("After.Name = %v, want %v", event.After.Name, "Notebook Pro")
+	}
+
+	if event.After.Price != product_valueobject.MustBRL(4500) {
+		t.Errorf("After.Price = %v, want %v", event.After.Price, product_valueobject.MustBRL(4500))
+	}
+
+	if len(event.After.Categories) != 2 {
+		t.Errorf("After.Categories length = %d, want 2", len(event.After.Categories))
+	}
+}
+
+func TestProductUpdatedEvent_EventName(t *testing.T) {
+	event := NewProductUpdatedEvent(ProductSnapshot{}, ProductSnapshot{})
+
+	expectedName := "product.updated"
+	if event.EventName() != expectedName {
+		t.Errorf("EventName() = %v, want %v", event.EventName(), expectedName)
+	}
+}
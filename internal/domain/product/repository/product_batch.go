@@ -0,0 +1,62 @@
+package product_repository
+
+import (
+	product_entity "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/entity"
+	product_errors "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/errors"
+	shared_events "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/events"
+)
+
+// BatchEntry é um produto a incluir em lote com os eventos de domínio da sua criação,
+// publicados somente se o produto for incluído
+type BatchEntry struct {
+	Product product_entity.Product
+	Events  []shared_events.Event
+}
+
+// BatchResult é o resultado de AddBatch. Errors segue a ordem das entradas e traz o
+// motivo pelo qual cada uma não pôde ser incluída (nil se não houve conflito).
+// Created é a quantidade de produtos incluídos.
+type BatchResult struct {
+	Errors  []error
+	Created int
+}
+
+// Failed indica se alguma entrada do lote não pôde ser incluída
+func (r BatchResult) Failed() bool {
+	for _, err := range r.Errors {
+		if err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// BatchUniqueness acompanha os nomes e SKUs já usados, no repositório e nas entradas
+// anteriores do lote, para detectar os conflitos de um lote antes de gravá-lo
+type BatchUniqueness struct {
+	names map[string]bool
+	skus  map[int]bool
+}
+
+func NewBatchUniqueness() *BatchUniqueness {
+	return &BatchUniqueness{names: make(map[string]bool), skus: make(map[int]bool)}
+}
+
+// Use registra o nome e o SKU de um produto existente
+func (u *BatchUniqueness) Use(name string, sku int) {
+	u.names[name] = true
+	u.skus[sku] = true
+}
+
+// Reserve registra o nome e o SKU do produto, ou retorna o erro de duplicidade
+// se algum deles já estiver em uso
+func (u *BatchUniqueness) Reserve(product product_entity.Product) error {
+	if u.names[product.Name] {
+		return product_errors.NewAlreadyExistsError("name")
+	}
+	if u.skus[product.Sku] {
+		return product_errors.NewAlreadyExistsError("sku")
+	}
+	u.Use(product.Name, product.Sku)
+	return nil
+}
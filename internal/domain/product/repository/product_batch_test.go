@@ -0,0 +1,52 @@
+package product_repository
+
+import (
+	"errors"
+	"testing"
+
+	product_entity "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/entity"
+	product_errors "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/errors"
+)
+
+func TestBatchUniqueness(t *testing.T) {
+	uniqueness := NewBatchUniqueness()
+	uniqueness.Use("Notebook", 1)
+
+	tests := []struct {
+		name      string
+		product   product_entity.Product
+		wantField string
+	}{
+		{name: "existing name", product: product_entity.Product{Name: "Notebook", Sku: 2}, wantField: "name"},
+		{name: "existing sku", product: product_entity.Product{Name: "Mouse", Sku: 1}, wantField: "sku"},
+		{name: "new product", product: product_entity.Product{Name: "Mouse", Sku: 2}},
+		{name: "name reserved by an earlier entry", product: product_entity.Product{Name: "Mouse", Sku: 3}, wantField: "name"},
+		{name: "sku reserved by an earlier entry", product: product_entity.Product{Name: "Keyboard", Sku: 2}, wantField: "sku"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := uniqueness.Reserve(tt.product)
+			if tt.wantField == "" {
+				if err != nil {
+					t.Fatalf("Reserve() unexpected error = %v", err)
+				}
+				return
+			}
+
+			var productErr *product_errors.Error
+			if !errors.As(err, &productErr) || !errors.Is(err, product_errors.ErrAlreadyExists) || productErr.Field != tt.wantField {
+				t.Errorf("Reserve() error = %v, want already exists on %s", err, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestBatchResult_Failed(t *testing.T) {
+	if (BatchResult{Errors: []error{nil, nil}}).Failed() {
+		t.Error("Failed() = true without errors")
+	}
+	if !(BatchResult{Errors: []error{nil, product_errors.NewAlreadyExistsError("sku")}}).Failed() {
+		t.Error("Failed() = false with a conflicting entry")
+	}
+}
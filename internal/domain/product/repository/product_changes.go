@@ -0,0 +1,94 @@
+package product_repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	product_entity "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/entity"
+)
+
+// Tipos de alteração do change feed
+const (
+	ChangeCreated = "created"
+	ChangeUpdated = "updated"
+	ChangeDeleted = "deleted"
+)
+
+var ErrInvalidChangeToken = errors.New("invalid change token")
+
+// ProductChange é o estado atual de um produto escrito depois da posição consultada.
+// Várias escritas do mesmo produto aparecem uma única vez, na posição da última.
+type ProductChange struct {
+	Type     string
+	Sequence int64
+	Product  product_entity.Product
+}
+
+// ChangeType classifica a alteração de um produto em relação à posição since:
+// excluído, criado depois de since ou atualizado (inclusive restaurado)
+func ChangeType(product product_entity.Product, createdSeq, since int64) string {
+	switch {
+	case product.IsDeleted():
+		return ChangeDeleted
+	case createdSeq > since:
+		return ChangeCreated
+	default:
+		return ChangeUpdated
+	}
+}
+
+// ChangePage é uma página do change feed, em ordem de commit.
+// NextToken continua a partir da última alteração da página; sem alterações,
+// é o próprio token consultado, para o cliente repetir a consulta mais tarde.
+type ChangePage struct {
+	Changes   []ProductChange
+	NextToken string
+	HasMore   bool
+}
+
+// NewChangePage monta a página a partir de até limit+1 alterações posteriores a since;
+// a alteração excedente só indica que existe próxima página
+func NewChangePage(changes []ProductChange, since int64, limit int) ChangePage {
+	page := ChangePage{Changes: changes}
+
+	if limit > 0 && len(changes) > limit {
+		page.Changes, page.HasMore = changes[:limit], true
+	}
+	if len(page.Changes) > 0 {
+		since = page.Changes[len(page.Changes)-1].Sequence
+	}
+	page.NextToken = ChangeToken{Sequence: since}.Encode()
+
+	return page
+}
+
+// ChangeToken é a posição do cliente no change feed
+type ChangeToken struct {
+	Sequence int64 `json:"s"`
+}
+
+// Encode serializa a posição em um token opaco para a API
+func (t ChangeToken) Encode() string {
+	data, _ := json.Marshal(t)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeChangeToken lê um token gerado por Encode; vazio é o início do feed
+func DecodeChangeToken(token string) (ChangeToken, error) {
+	if token == "" {
+		return ChangeToken{}, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return ChangeToken{}, ErrInvalidChangeToken
+	}
+
+	var t ChangeToken
+	if err := json.Unmarshal(data, &t); err != nil || t.Sequence < 0 {
+		return ChangeToken{}, ErrInvalidChangeToken
+	}
+
+	return t, nil
+}
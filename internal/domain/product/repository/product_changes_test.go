@@ -0,0 +1,98 @@
+package product_repository
+
+import (
+	"testing"
+	"time"
+
+	product_entity "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/entity"
+)
+
+func TestChangeToken_EncodeDecode(t *testing.T) {
+	token := ChangeToken{Sequence: 42}.Encode()
+
+	decoded, err := DecodeChangeToken(token)
+	if err != nil {
+		t.Fatalf("DecodeChangeToken() error = %v", err)
+	}
+	if decoded.Sequence != 42 {
+		t.Errorf("DecodeChangeToken() = %+v, want sequence 42", decoded)
+	}
+
+	start, err := DecodeChangeToken("")
+	if err != nil || start.Sequence != 0 {
+		t.Errorf("DecodeChangeToken(\"\") = %+v, %v; want start of feed", start, err)
+	}
+}
+
+func TestDecodeChangeToken_Invalid(t *testing.T) {
+	tokens := map[string]string{
+		"not base64":        "%%%",
+		"not json":          "bm90IGpzb24",
+		"negative sequence": ChangeToken{Sequence: -1}.Encode(),
+	}
+
+	for name, token := range tokens {
+		t.Run(name, func(t *testing.T) {
+			if _, err := DecodeChangeToken(token); err != ErrInvalidChangeToken {
+				t.Errorf("DecodeChangeToken(%q) error = %v, want ErrInvalidChangeToken", token, err)
+			}
+		})
+	}
+}
+
+func TestChangeType(t *testing.T) {
+	now := time.Now()
+	active := product_entity.Product{Name: "Notebook"}
+	deleted := product_entity.Product{Name: "Notebook", DeletedAt: &now}
+
+	tests := []struct {
+		name       string
+		product    product_entity.Product
+		createdSeq int64
+		since      int64
+		want       string
+	}{
+		{name: "created after since", product: active, createdSeq: 5, since: 4, want: ChangeCreated},
+		{name: "created before since", product: active, createdSeq: 3, since: 4, want: ChangeUpdated},
+		{name: "deleted", product: deleted, createdSeq: 3, since: 4, want: ChangeDeleted},
+		{name: "created and deleted after since", product: deleted, createdSeq: 5, since: 4, want: ChangeDeleted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ChangeType(tt.product, tt.createdSeq, tt.since); got != tt.want {
+				t.Errorf("ChangeType() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewChangePage(t *testing.T) {
+	changes := []ProductChange{{Sequence: 3}, {Sequence: 5}, {Sequence: 8}}
+
+	tests := []struct {
+		name      string
+		changes   []ProductChange
+		limit     int
+		wantCount int
+		wantNext  int64
+		wantMore  bool
+	}{
+		{name: "more than limit", changes: changes, limit: 2, wantCount: 2, wantNext: 5, wantMore: true},
+		{name: "exactly limit", changes: changes[:2], limit: 2, wantCount: 2, wantNext: 5},
+		{name: "no limit", changes: changes, wantCount: 3, wantNext: 8},
+		{name: "no changes keeps since", changes: nil, limit: 2, wantNext: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := NewChangePage(tt.changes, 1, tt.limit)
+
+			next, _ := DecodeChangeToken(page.NextToken)
+			if len(page.Changes) != tt.wantCount || next.Sequence != tt.wantNext || page.HasMore != tt.wantMore {
+				t.Errorf("NewChangePage() = %d changes, next %d, more %v; want %d, %d, %v",
+					len(page.Changes), next.Sequence, page.HasMore, tt.wantCount, tt.wantNext, tt.wantMore)
+			}
+		})
+	}
+}
@@ -0,0 +1,276 @@
+package product_repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	product_entity "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/entity"
+)
+
+// Campos aceitos para ordenação da listagem de produtos
+const (
+	SortByCreatedAt = "created_at"
+	SortByName      = "name"
+	SortByPrice     = "price"
+)
+
+var (
+	ErrInvalidSort      = errors.New("invalid sort")
+	ErrInvalidCursor    = errors.New("invalid cursor")
+	ErrCurrencyRequired = errors.New("currency is required to filter or sort by price")
+)
+
+// ProductSort define o campo e a direção da ordenação
+type ProductSort struct {
+	Field      string
+	Descending bool
+}
+
+// DefaultProductSort mantém a ordem histórica da listagem: mais recentes primeiro
+var DefaultProductSort = ProductSort{Field: SortByCreatedAt, Descending: true}
+
+// ParseProductSort interpreta valores como "price", "-price", "name" ou "created_at".
+// O prefixo "-" inverte a direção; vazio retorna DefaultProductSort.
+func ParseProductSort(value string) (ProductSort, error) {
+	if value == "" {
+		return DefaultProductSort, nil
+	}
+
+	sort := ProductSort{Field: strings.TrimPrefix(value, "-"), Descending: strings.HasPrefix(value, "-")}
+	switch sort.Field {
+	case SortByCreatedAt, SortByName, SortByPrice:
+		return sort, nil
+	default:
+		return ProductSort{}, ErrInvalidSort
+	}
+}
+
+// String retorna a ordenação no formato aceito por ParseProductSort
+func (s ProductSort) String() string {
+	if s.Descending {
+		return "-" + s.Field
+	}
+	return s.Field
+}
+
+// ProductCriteria reúne os filtros, a ordenação e a paginação da listagem de produtos.
+// Campos nulos ou vazios não filtram; Limit zero retorna todos os produtos.
+//
+// Categories seleciona os produtos com ao menos uma das categorias, como as de uma
+// subárvore da hierarquia; Category, se também informada, precisa casar.
+//
+// Preços em moedas diferentes não são comparáveis, então MinPrice, MaxPrice e a ordenação
+// por preço exigem Currency (veja Validate).
+type ProductCriteria struct {
+	IncludeDeleted bool
+	Category       string
+	Categories     []string
+	Sku            *int
+	Currency       string
+	MinPrice       *int64
+	MaxPrice       *int64
+	Sort           ProductSort
+	Limit          int
+	After          *ProductCursor
+}
+
+// Validate retorna ErrCurrencyRequired se os critérios filtram ou ordenam por preço sem Currency
+func (c ProductCriteria) Validate() error {
+	if c.Currency == "" && (c.MinPrice != nil || c.MaxPrice != nil || c.OrderBy().Field == SortByPrice) {
+		return ErrCurrencyRequired
+	}
+	return nil
+}
+
+// OrderBy retorna a ordenação dos critérios, DefaultProductSort quando não informada
+func (c ProductCriteria) OrderBy() ProductSort {
+	if c.Sort.Field == "" {
+		return DefaultProductSort
+	}
+	return c.Sort
+}
+
+// ProductPage é uma página do resultado da listagem.
+// Total conta todos os produtos que satisfazem os filtros, independente da paginação.
+type ProductPage struct {
+	Products   []product_entity.Product
+	NextCursor string
+	Total      int
+}
+
+// ProductCursor identifica o último produto de uma página: o valor do campo de
+// ordenação e o ID, usado como desempate
+type ProductCursor struct {
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+// NewProductCursor monta o cursor do produto para a ordenação informada
+func NewProductCursor(product product_entity.Product, sort ProductSort) ProductCursor {
+	var value string
+	switch sort.Field {
+	case SortByName:
+		value = product.Name
+	case SortByPrice:
+		value = strconv.FormatInt(product.Price.Amount(), 10)
+	default:
+		value = product.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+
+	return ProductCursor{Value: value, ID: product.ID}
+}
+
+// Encode serializa o cursor em um token opaco para a API
+func (c ProductCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeProductCursor lê um token gerado por Encode e confere se o valor
+// é compatível com a ordenação informada
+func DecodeProductCursor(token string, sort ProductSort) (ProductCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return ProductCursor{}, ErrInvalidCursor
+	}
+
+	var cursor ProductCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == "" {
+		return ProductCursor{}, ErrInvalidCursor
+	}
+
+	switch sort.Field {
+	case SortByPrice:
+		_, err = cursor.PriceValue()
+	case SortByCreatedAt:
+		_, err = cursor.TimeValue()
+	}
+	if err != nil {
+		return ProductCursor{}, err
+	}
+
+	return cursor, nil
+}
+
+// PriceValue retorna o valor do cursor para ordenação por preço
+func (c ProductCursor) PriceValue() (int64, error) {
+	amount, err := strconv.ParseInt(c.Value, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+	return amount, nil
+}
+
+// TimeValue retorna o valor do cursor para ordenação por data de criação
+func (c ProductCursor) TimeValue() (time.Time, error) {
+	t, err := time.Parse(time.RFC3339Nano, c.Value)
+	if err != nil {
+		return time.Time{}, ErrInvalidCursor
+	}
+	return t, nil
+}
+
+// Matches informa se o produto satisfaz os filtros (sem considerar paginação)
+func (c ProductCriteria) Matches(product product_entity.Product) bool {
+	if product.IsDeleted() && !c.IncludeDeleted {
+		return false
+	}
+	if c.Sku != nil && product.Sku != *c.Sku {
+		return false
+	}
+	if c.Currency != "" && product.Price.Currency() != c.Currency {
+		return false
+	}
+	if c.MinPrice != nil && product.Price.Amount() < *c.MinPrice {
+		return false
+	}
+	if c.MaxPrice != nil && product.Price.Amount() > *c.MaxPrice {
+		return false
+	}
+	if c.Category != "" && !hasCategory(product, c.Category) {
+		return false
+	}
+	if len(c.Categories) > 0 && !hasAnyCategory(product, c.Categories) {
+		return false
+	}
+
+	return true
+}
+
+// Less compara dois produtos segundo a ordenação; o ID desempata em ordem crescente
+func (s ProductSort) Less(a, b product_entity.Product) bool {
+	if cmp := s.compare(a, b); cmp != 0 {
+		if s.Descending {
+			return cmp > 0
+		}
+		return cmp < 0
+	}
+	return a.ID < b.ID
+}
+
+// IsAfter informa se o produto vem depois do cursor na ordenação
+func (s ProductSort) IsAfter(product product_entity.Product, cursor ProductCursor) bool {
+	var cmp int
+	switch s.Field {
+	case SortByName:
+		cmp = strings.Compare(product.Name, cursor.Value)
+	case SortByPrice:
+		amount, _ := cursor.PriceValue()
+		cmp = compareInt64(product.Price.Amount(), amount)
+	default:
+		createdAt, _ := cursor.TimeValue()
+		cmp = product.CreatedAt.Compare(createdAt)
+	}
+
+	if cmp != 0 {
+		if s.Descending {
+			return cmp < 0
+		}
+		return cmp > 0
+	}
+	return product.ID > cursor.ID
+}
+
+func (s ProductSort) compare(a, b product_entity.Product) int {
+	switch s.Field {
+	case SortByName:
+		return strings.Compare(a.Name, b.Name)
+	case SortByPrice:
+		return compareInt64(a.Price.Amount(), b.Price.Amount())
+	default:
+		return a.CreatedAt.Compare(b.CreatedAt)
+	}
+}
+
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func hasCategory(product product_entity.Product, category string) bool {
+	for _, c := range product.Categories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAnyCategory(product product_entity.Product, categories []string) bool {
+	for _, category := range categories {
+		if hasCategory(product, category) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package product_repository
+
+import (
+	"testing"
+	"time"
+
+	product_entity "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/entity"
+	product_valueobject "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/valueobject"
+)
+
+func TestParseProductSort(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    ProductSort
+		wantErr bool
+	}{
+		{value: "", want: DefaultProductSort},
+		{value: "price", want: ProductSort{Field: SortByPrice}},
+		{value: "-price", want: ProductSort{Field: SortByPrice, Descending: true}},
+		{value: "name", want: ProductSort{Field: SortByName}},
+		{value: "created_at", want: ProductSort{Field: SortByCreatedAt}},
+		{value: "sku", wantErr: true},
+		{value: "--price", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseProductSort(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseProductSort(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseProductSort(%q) = %+v, want %+v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProductCursor_EncodeDecode(t *testing.T) {
+	product := product_entity.Product{
+		ID:        "00000000-0000-4000-8000-000000000001",
+		Name:      "Notebook",
+		Price:     product_valueobject.MustBRL(3500),
+		CreatedAt: time.Date(2026, 10, 17, 12, 30, 0, 123456000, time.UTC),
+	}
+
+	for _, sort := range []ProductSort{{Field: SortByPrice}, {Field: SortByName}, DefaultProductSort} {
+		t.Run(sort.String(), func(t *testing.T) {
+			cursor := NewProductCursor(product, sort)
+
+			decoded, err := DecodeProductCursor(cursor.Encode(), sort)
+			if err != nil {
+				t.Fatalf("DecodeProductCursor() error = %v", err)
+			}
+			if decoded != cursor {
+				t.Errorf("DecodeProductCursor() = %+v, want %+v", decoded, cursor)
+			}
+		})
+	}
+}
+
+func TestDecodeProductCursor_Invalid(t *testing.T) {
+	nameCursor := ProductCursor{Value: "Notebook", ID: "00000000-0000-4000-8000-000000000001"}.Encode()
+
+	tests := []struct {
+		name  string
+		token string
+		sort  ProductSort
+	}{
+		{name: "not base64", token: "%%%", sort: DefaultProductSort},
+		{name: "not json", token: "bm90LWpzb24", sort: DefaultProductSort},
+		{name: "value incompatible with price sort", token: nameCursor, sort: ProductSort{Field: SortByPrice}},
+		{name: "value incompatible with created_at sort", token: nameCursor, sort: DefaultProductSort},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeProductCursor(tt.token, tt.sort); err != ErrInvalidCursor {
+				t.Errorf("DecodeProductCursor() error = %v, want %v", err, ErrInvalidCursor)
+			}
+		})
+	}
+}
+
+func TestProductCriteria_Matches(t *testing.T) {
+	sku := 12345
+	minPrice, maxPrice := int64(1000), int64(5000)
+	deletedAt := time.Now()
+
+	product := product_entity.Product{Name: "Notebook", Sku: 12345, Categories: []string{"Electronics"}, Price: product_valueobject.MustBRL(3500)}
+	deleted := product
+	deleted.DeletedAt = &deletedAt
+
+	tests := []struct {
+		name     string
+		criteria ProductCriteria
+		product  product_entity.Product
+		want     bool
+	}{
+		{name: "no filters", criteria: ProductCriteria{}, product: product, want: true},
+		{name: "category match", criteria: ProductCriteria{Category: "Electronics"}, product: product, want: true},
+		{name: "category mismatch", criteria: ProductCriteria{Category: "Books"}, product: product, want: false},
+		{name: "categories match any", criteria: ProductCriteria{Categories: []string{"Books", "Electronics"}}, product: product, want: true},
+		{name: "categories mismatch", criteria: ProductCriteria{Categories: []string{"Books", "Games"}}, product: product, want: false},
+		{name: "sku match", criteria: ProductCriteria{Sku: &sku}, product: product, want: true},
+		{name: "price in range", criteria: ProductCriteria{MinPrice: &minPrice, MaxPrice: &maxPrice}, product: product, want: true},
+		{name: "price below min", criteria: ProductCriteria{MinPrice: &maxPrice}, product: product, want: false},
+		{name: "price above max", criteria: ProductCriteria{MaxPrice: &minPrice}, product: product, want: false},
+		{name: "deleted excluded", criteria: ProductCriteria{}, product: deleted, want: false},
+		{name: "deleted included", criteria: ProductCriteria{IncludeDeleted: true}, product: deleted, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.criteria.Matches(tt.product); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,91 +1,532 @@
 package product_repository
 
 import (
-	"errors"
+	"context"
+	"log"
+	"sort"
 	"sync"
+	"time"
 
 	product_entity "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/entity"
+	product_errors "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/errors"
+	shared_events "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/events"
 )
 
+// IProductRepository persiste produtos. Os métodos de escrita recebem os eventos de
+// domínio gerados pela operação; eles só são publicados se a escrita for confirmada.
+// O ctx de cada método cancela a operação quando o cliente desconecta ou o servidor encerra.
+//
+// As alterações usam concorrência otimista: Update grava somente se a versão armazenada
+// for a ExpectedVersion do produto, e Delete e Restore somente se for expectedVersion;
+// caso contrário retornam ErrVersionConflict. Cada alteração incrementa a versão.
+//
+// AddBatch inclui vários produtos de uma vez. As entradas cujo nome ou SKU já está em uso,
+// no repositório ou em uma entrada anterior do lote, não são incluídas e têm o erro de
+// duplicidade no resultado; com atomic, um conflito impede a inclusão de todo o lote.
+//
+// Export entrega a fn, um a um e na ordem dos critérios, os produtos que satisfazem os
+// filtros, sem carregar todos em memória; Limit e After são ignorados. Um erro de fn
+// interrompe a exportação e é retornado.
+//
+// Reindex reconstrói o índice da busca textual de todos os produtos, incluindo os excluídos,
+// em lotes de batchSize, informando a progress quantos já foram reconstruídos e o total.
 type IProductRepository interface {
-	Add(product product_entity.Product) error
-	Find() ([]product_entity.Product, error)
-	FindOne(name string) (product_entity.Product, error)
-	GetMetrics() RepositoryMetrics
+	Add(ctx context.Context, product product_entity.Product, events ...shared_events.Event) error
+	AddBatch(ctx context.Context, entries []BatchEntry, atomic bool) (BatchResult, error)
+	Find(ctx context.Context, criteria ProductCriteria) (ProductPage, error)
+	Export(ctx context.Context, criteria ProductCriteria, fn func(product_entity.Product) error) error
+	FindOne(ctx context.Context, name string, includeDeleted bool) (product_entity.Product, error)
+	FindByID(ctx context.Context, id string, includeDeleted bool) (product_entity.Product, error)
+	FindBySku(ctx context.Context, sku int, includeDeleted bool) (product_entity.Product, error)
+	Search(ctx context.Context, query string, limit int) ([]product_entity.Product, error)
+	Reindex(ctx context.Context, batchSize int, progress func(done, total int)) (int, error)
+	Changes(ctx context.Context, since int64, limit int) (ChangePage, error)
+	Update(ctx context.Context, name string, product product_entity.Product, events ...shared_events.Event) error
+	Delete(ctx context.Context, name string, expectedVersion int, events ...shared_events.Event) error
+	Restore(ctx context.Context, name string, expectedVersion int, events ...shared_events.Event) error
+	GetMetrics(ctx context.Context) RepositoryMetrics
 }
 
-// RepositoryMetrics contém métricas calculadas do repositório
+// RepositoryMetrics contém métricas calculadas do repositório.
+// TotalValue e AveragePrice são agrupados por moeda (ISO-4217) e expressos em unidades menores.
 type RepositoryMetrics struct {
 	TotalProducts      int
-	TotalValue         float64
-	AveragePrice       float64
+	TotalValue         map[string]float64
+	AveragePrice       map[string]float64
 	ProductsByCategory map[string]int
 }
 
 type ProductRepository struct {
-	data map[string]product_entity.Product
-	mu   sync.RWMutex
+	data       map[string]product_entity.Product
+	sequences  map[string]changeSequence // posições no change feed por ID do produto
+	lastSeq    int64
+	dispatcher *shared_events.EventDispatcher
+	mu         sync.RWMutex
+
+	// publishMu mantém a ordem das escritas na publicação dos eventos, feita sem mu
+	publishMu sync.Mutex
+}
+
+// changeSequence guarda as posições da criação e da última escrita de um produto
+type changeSequence struct {
+	created int64
+	changed int64
 }
 
 func NewRepository() *ProductRepository {
 	return &ProductRepository{
-		data: make(map[string]product_entity.Product),
+		data:      make(map[string]product_entity.Product),
+		sequences: make(map[string]changeSequence),
 	}
 }
 
-func (r *ProductRepository) Add(product product_entity.Product) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
+// NewRepositoryWithDispatcher cria um repositório in-memory que publica os eventos
+// de domínio no dispatcher logo após cada escrita bem-sucedida
+func NewRepositoryWithDispatcher(dispatcher *shared_events.EventDispatcher) *ProductRepository {
+	repo := NewRepository()
+	repo.dispatcher = dispatcher
+	return repo
+}
+
+// checkUnique garante, como as constraints do Postgres, que nenhum outro produto
+// (inclusive excluído) usa o nome ou o SKU; current é o nome do produto sendo
+// alterado, vazio na inclusão. Exige o lock.
+func (r *ProductRepository) checkUnique(product product_entity.Product, current string) error {
+	if _, exists := r.data[product.Name]; exists && product.Name != current {
+		return product_errors.NewAlreadyExistsError("name")
+	}
+	for name, other := range r.data {
+		if name != current && other.Sku == product.Sku {
+			return product_errors.NewAlreadyExistsError("sku")
+		}
+	}
+	return nil
+}
 
-	if _, exists := r.data[product.Name]; exists {
-		return errors.New("product already exists")
+// touch registra uma escrita do produto no change feed; exige o lock
+func (r *ProductRepository) touch(id string, created bool) {
+	r.lastSeq++
+	seq := r.sequences[id]
+	if created {
+		seq.created = r.lastSeq
 	}
+	seq.changed = r.lastSeq
+	r.sequences[id] = seq
+}
 
-	r.data[product.Name] = product
+// write executa a escrita com o lock e, se ela tiver sucesso, publica os eventos que
+// retornou depois de liberá-lo: com a política de fila block, um handler que lê o
+// repositório esperaria pelo lock enquanto a escrita espera espaço na fila
+func (r *ProductRepository) write(fn func() ([]shared_events.Event, error)) error {
+	r.mu.Lock()
+	events, err := fn()
+	if err != nil {
+		r.mu.Unlock()
+		return err
+	}
+
+	r.publishMu.Lock()
+	defer r.publishMu.Unlock()
+	r.mu.Unlock()
 
+	r.publish(events)
 	return nil
 }
 
-func (r *ProductRepository) Find() ([]product_entity.Product, error) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
+// store guarda o produto; a versão gravada passa a ser a versão com que ele é lido
+func (r *ProductRepository) store(product product_entity.Product) {
+	product.MarkLoaded()
+	r.data[product.Name] = product
+}
+
+// publish entrega os eventos de uma escrita confirmada
+func (r *ProductRepository) publish(events []shared_events.Event) {
+	if r.dispatcher == nil {
+		return
+	}
+	for _, event := range events {
+		if event == nil {
+			continue
+		}
+		// A escrita já foi confirmada; uma recusa do dispatcher (fila cheia) só é registrada
+		if err := r.dispatcher.Dispatch(event.EventName(), event); err != nil {
+			log.Printf("⚠️  Evento %s não publicado: %v", event.EventName(), err)
+		}
+	}
+}
+
+func (r *ProductRepository) Add(ctx context.Context, product product_entity.Product, events ...shared_events.Event) error {
+	// Como no Postgres, uma requisição já cancelada não grava nada
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return r.write(func() ([]shared_events.Event, error) {
+		if err := r.checkUnique(product, ""); err != nil {
+			return nil, err
+		}
+
+		// Como no Postgres, todo produto começa na versão 1
+		product.Version = 1
+		r.store(product)
+		r.touch(product.ID, true)
+
+		return events, nil
+	})
+}
+
+// AddBatch inclui as entradas sem conflito de nome ou SKU; com atomic, nenhuma é incluída
+// se alguma conflitar
+func (r *ProductRepository) AddBatch(ctx context.Context, entries []BatchEntry, atomic bool) (BatchResult, error) {
+	if err := ctx.Err(); err != nil {
+		return BatchResult{}, err
+	}
+
+	result := BatchResult{Errors: make([]error, len(entries))}
+	err := r.write(func() ([]shared_events.Event, error) {
+		uniqueness := NewBatchUniqueness()
+		for _, product := range r.data {
+			uniqueness.Use(product.Name, product.Sku)
+		}
+
+		for i, entry := range entries {
+			result.Errors[i] = uniqueness.Reserve(entry.Product)
+		}
+		if atomic && result.Failed() {
+			return nil, nil
+		}
+
+		var events []shared_events.Event
+		for i, entry := range entries {
+			if result.Errors[i] != nil {
+				continue
+			}
+
+			product := entry.Product
+			product.Version = 1
+			r.store(product)
+			r.touch(product.ID, true)
+			events = append(events, entry.Events...)
+			result.Created++
+		}
+
+		return events, nil
+	})
+
+	return result, err
+}
+
+// Find retorna a página de produtos que satisfaz os critérios
+func (r *ProductRepository) Find(ctx context.Context, criteria ProductCriteria) (ProductPage, error) {
+	if err := criteria.Validate(); err != nil {
+		return ProductPage{}, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 
 	products := make([]product_entity.Product, 0, len(r.data))
 
 	for _, p := range r.data {
-		products = append(products, p)
+		if criteria.Matches(p) {
+			products = append(products, p)
+		}
+	}
+
+	order := criteria.OrderBy()
+	sort.Slice(products, func(i, j int) bool {
+		return order.Less(products[i], products[j])
+	})
+
+	page := ProductPage{Total: len(products)}
+
+	// Pular os produtos até o cursor (inclusive)
+	if criteria.After != nil {
+		start := sort.Search(len(products), func(i int) bool {
+			return order.IsAfter(products[i], *criteria.After)
+		})
+		products = products[start:]
+	}
+
+	if criteria.Limit > 0 && len(products) > criteria.Limit {
+		products = products[:criteria.Limit]
+		page.NextCursor = NewProductCursor(products[len(products)-1], order).Encode()
 	}
+	page.Products = products
 
-	return products, nil
+	return page, nil
 }
 
-func (r *ProductRepository) FindOne(name string) (product_entity.Product, error) {
+// Export entrega os produtos que satisfazem os filtros a fn. Os produtos já estão em
+// memória; fn é chamada fora do lock para não bloquear as escritas durante o download.
+func (r *ProductRepository) Export(ctx context.Context, criteria ProductCriteria, fn func(product_entity.Product) error) error {
+	criteria.Limit, criteria.After = 0, nil
+
+	page, err := r.Find(ctx, criteria)
+	if err != nil {
+		return err
+	}
+
+	for _, product := range page.Products {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(product); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *ProductRepository) FindOne(ctx context.Context, name string, includeDeleted bool) (product_entity.Product, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	product, exists := r.data[name]
 
-	if !exists {
-		return product_entity.Product{}, errors.New("product not found")
+	if !exists || (product.IsDeleted() && !includeDeleted) {
+		return product_entity.Product{}, product_errors.ErrNotFound
 	}
 
 	return product, nil
 }
 
+// FindByID busca um produto pelo identificador
+func (r *ProductRepository) FindByID(ctx context.Context, id string, includeDeleted bool) (product_entity.Product, error) {
+	return r.findFirst(includeDeleted, func(p product_entity.Product) bool {
+		return p.ID == id
+	})
+}
+
+// FindBySku busca um produto pelo SKU
+func (r *ProductRepository) FindBySku(ctx context.Context, sku int, includeDeleted bool) (product_entity.Product, error) {
+	return r.findFirst(includeDeleted, func(p product_entity.Product) bool {
+		return p.Sku == sku
+	})
+}
+
+// Search busca produtos ativos por nome e categoria, ordenados por relevância
+func (r *ProductRepository) Search(ctx context.Context, query string, limit int) ([]product_entity.Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	products := make([]product_entity.Product, 0, len(r.data))
+	for _, product := range r.data {
+		products = append(products, product)
+	}
+
+	return SearchProducts(products, Tokenize(query), limit), nil
+}
+
+// Reindex não tem o que reconstruir: a busca em memória tokeniza os produtos a cada consulta.
+// Apenas conta os produtos, para o andamento do job de reindexação.
+func (r *ProductRepository) Reindex(ctx context.Context, batchSize int, progress func(done, total int)) (int, error) {
+	r.mu.RLock()
+	total := len(r.data)
+	r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	progress(total, total)
+
+	return total, nil
+}
+
+// CountCategory conta os produtos, inclusive excluídos, com a categoria
+func (r *ProductRepository) CountCategory(ctx context.Context, name string) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	count := 0
+	for _, product := range r.data {
+		if hasCategory(product, name) {
+			count++
+		}
+	}
+
+	return count, ctx.Err()
+}
+
+// ReplaceCategory troca as categorias from por to em todos os produtos, inclusive excluídos,
+// sem repeti-la, como na renomeação ou fusão de categorias. Cada produto alterado tem a
+// versão incrementada, entra no change feed e publica product.updated.
+func (r *ProductRepository) ReplaceCategory(ctx context.Context, from []string, to string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	replaced := 0
+	err := r.write(func() ([]shared_events.Event, error) {
+		var events []shared_events.Event
+		for _, product := range r.data {
+			if !product.ReplaceCategories(from, to) {
+				continue
+			}
+
+			events = append(events, product.PullEvents()...)
+			r.store(product)
+			r.touch(product.ID, false)
+			replaced++
+		}
+
+		return events, nil
+	})
+
+	return replaced, err
+}
+
+// Changes retorna os produtos escritos depois da posição since, em ordem de escrita,
+// incluindo os excluídos
+func (r *ProductRepository) Changes(ctx context.Context, since int64, limit int) (ChangePage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var changes []ProductChange
+	for _, product := range r.data {
+		seq := r.sequences[product.ID]
+		if seq.changed <= since {
+			continue
+		}
+		changes = append(changes, ProductChange{
+			Type:     ChangeType(product, seq.created, since),
+			Sequence: seq.changed,
+			Product:  product,
+		})
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Sequence < changes[j].Sequence
+	})
+	if limit > 0 && len(changes) > limit+1 {
+		changes = changes[:limit+1]
+	}
+
+	return NewChangePage(changes, since, limit), nil
+}
+
+// findFirst retorna o primeiro produto que satisfaz match
+func (r *ProductRepository) findFirst(includeDeleted bool, match func(product_entity.Product) bool) (product_entity.Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, product := range r.data {
+		if product.IsDeleted() && !includeDeleted {
+			continue
+		}
+		if match(product) {
+			return product, nil
+		}
+	}
+
+	return product_entity.Product{}, product_errors.ErrNotFound
+}
+
+// Update substitui o produto identificado por name pelos novos dados
+func (r *ProductRepository) Update(ctx context.Context, name string, product product_entity.Product, events ...shared_events.Event) error {
+	if ok, err := product_entity.Validate(product.Name, product.Sku, product.Categories, product.Price); !ok {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return r.write(func() ([]shared_events.Event, error) {
+		current, exists := r.data[name]
+		if !exists || current.IsDeleted() {
+			return nil, product_errors.ErrNotFound
+		}
+		if current.Version != product.ExpectedVersion() {
+			return nil, product_errors.ErrVersionConflict
+		}
+
+		if err := r.checkUnique(product, name); err != nil {
+			return nil, err
+		}
+		if product.Name != name {
+			delete(r.data, name)
+		}
+
+		r.store(product)
+		r.touch(current.ID, false)
+
+		return events, nil
+	})
+}
+
+// Delete marca o produto como excluído sem removê-lo do repositório
+func (r *ProductRepository) Delete(ctx context.Context, name string, expectedVersion int, events ...shared_events.Event) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return r.write(func() ([]shared_events.Event, error) {
+		product, exists := r.data[name]
+		if !exists || product.IsDeleted() {
+			return nil, product_errors.ErrNotFound
+		}
+		if product.Version != expectedVersion {
+			return nil, product_errors.ErrVersionConflict
+		}
+
+		now := time.Now().UTC()
+		product.DeletedAt = &now
+		product.Version++
+		r.store(product)
+		r.touch(product.ID, false)
+
+		return events, nil
+	})
+}
+
+// Restore desfaz a exclusão de um produto excluído
+func (r *ProductRepository) Restore(ctx context.Context, name string, expectedVersion int, events ...shared_events.Event) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return r.write(func() ([]shared_events.Event, error) {
+		product, exists := r.data[name]
+		if !exists || !product.IsDeleted() {
+			return nil, product_errors.ErrNotFound
+		}
+		if product.Version != expectedVersion {
+			return nil, product_errors.ErrVersionConflict
+		}
+
+		product.DeletedAt = nil
+		product.Version++
+		r.store(product)
+		r.touch(product.ID, false)
+
+		return events, nil
+	})
+}
+
 // GetMetrics calcula e retorna métricas do repositório
-func (r *ProductRepository) GetMetrics() RepositoryMetrics {
+func (r *ProductRepository) GetMetrics(ctx context.Context) RepositoryMetrics {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	metrics := RepositoryMetrics{
-		TotalProducts:      len(r.data),
+		TotalValue:         make(map[string]float64),
+		AveragePrice:       make(map[string]float64),
 		ProductsByCategory: make(map[string]int),
 	}
 
-	totalValue := 0
+	countByCurrency := make(map[string]int)
 	for _, product := range r.data {
-		// Valor total
-		totalValue += product.Price
+		// Produtos excluídos não entram nas métricas
+		if product.IsDeleted() {
+			continue
+		}
+		metrics.TotalProducts++
+
+		// Valor total por moeda
+		currency := product.Price.Currency()
+		metrics.TotalValue[currency] += float64(product.Price.Amount())
+		countByCurrency[currency]++
 
 		// Produtos por categoria
 		for _, category := range product.Categories {
@@ -93,9 +534,8 @@ func (r *ProductRepository) GetMetrics() RepositoryMetrics {
 		}
 	}
 
-	metrics.TotalValue = float64(totalValue)
-	if metrics.TotalProducts > 0 {
-		metrics.AveragePrice = metrics.TotalValue / float64(metrics.TotalProducts)
+	for currency, count := range countByCurrency {
+		metrics.AveragePrice[currency] = metrics.TotalValue[currency] / float64(count)
 	}
 
 	return metrics
@@ -1,12 +1,29 @@
 package product_repository
 
 import (
+	"context"
+	"errors"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	product_entity "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/entity"
+	product_errors "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/errors"
+	product_events "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/events"
+	product_valueobject "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/valueobject"
+	shared_events "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/events"
 )
 
+// withPendingChange marca o produto como lido na versão anterior à sua, como um produto
+// carregado do repositório e alterado uma vez antes da gravação
+func withPendingChange(product product_entity.Product) product_entity.Product {
+	product.Version--
+	product.MarkLoaded()
+	product.Version++
+	return product
+}
+
 func TestNewRepository(t *testing.T) {
 	repo := NewRepository()
 
@@ -37,7 +54,7 @@ func TestProductRepository_Add(t *testing.T) {
 				Name:       "Notebook",
 				Sku:        123,
 				Categories: []string{"Electronics"},
-				Price:      3500,
+				Price:      product_valueobject.MustBRL(3500),
 			},
 			wantErr: false,
 		},
@@ -47,16 +64,35 @@ func TestProductRepository_Add(t *testing.T) {
 				Name:       "Notebook",
 				Sku:        123,
 				Categories: []string{"Electronics"},
-				Price:      3500,
+				Price:      product_valueobject.MustBRL(3500),
 			},
 			wantErr: true,
-			errMsg:  "product already exists",
+			errMsg:  "product with this name already exists",
 			setup: func(r *ProductRepository) {
 				r.data["Notebook"] = product_entity.Product{
 					Name:       "Notebook",
 					Sku:        456,
 					Categories: []string{"Electronics"},
-					Price:      2500,
+					Price:      product_valueobject.MustBRL(2500),
+				}
+			},
+		},
+		{
+			name: "add product with duplicate sku",
+			product: product_entity.Product{
+				Name:       "Notebook",
+				Sku:        123,
+				Categories: []string{"Electronics"},
+				Price:      product_valueobject.MustBRL(3500),
+			},
+			wantErr: true,
+			errMsg:  "product with this sku already exists",
+			setup: func(r *ProductRepository) {
+				r.data["Mouse"] = product_entity.Product{
+					Name:       "Mouse",
+					Sku:        123,
+					Categories: []string{"Peripherals"},
+					Price:      product_valueobject.MustBRL(100),
 				}
 			},
 		},
@@ -70,14 +106,14 @@ func TestProductRepository_Add(t *testing.T) {
 				tt.setup(repo)
 			}
 
-			err := repo.Add(tt.product)
+			err := repo.Add(context.Background(), tt.product)
 
 			if tt.wantErr {
 				if err == nil {
 					t.Error("Add() expected error, got nil")
 					return
 				}
-				if err.Error() != tt.errMsg {
+				if err.Error() != tt.errMsg || !errors.Is(err, product_errors.ErrAlreadyExists) {
 					t.Errorf("Add() error = %v, want %v", err.Error(), tt.errMsg)
 				}
 			} else {
@@ -87,7 +123,8 @@ func TestProductRepository_Add(t *testing.T) {
 				}
 
 				// Verificar se o produto foi adicionado
-				products, _ := repo.Find()
+				page, _ := repo.Find(context.Background(), ProductCriteria{})
+				products := page.Products
 				if len(products) != 1 {
 					t.Errorf("Add() products count = %d, want 1", len(products))
 				}
@@ -101,7 +138,8 @@ func TestProductRepository_Find(t *testing.T) {
 
 	// Repositório vazio
 	t.Run("empty repository", func(t *testing.T) {
-		products, err := repo.Find()
+		page, err := repo.Find(context.Background(), ProductCriteria{})
+		products := page.Products
 		if err != nil {
 			t.Errorf("Find() unexpected error = %v", err)
 		}
@@ -112,18 +150,19 @@ func TestProductRepository_Find(t *testing.T) {
 
 	// Adicionar produtos
 	products := []product_entity.Product{
-		{Name: "Product1", Sku: 1, Categories: []string{"Cat1"}, Price: 100},
-		{Name: "Product2", Sku: 2, Categories: []string{"Cat2"}, Price: 200},
-		{Name: "Product3", Sku: 3, Categories: []string{"Cat3"}, Price: 300},
+		{Name: "Product1", Sku: 1, Categories: []string{"Cat1"}, Price: product_valueobject.MustBRL(100)},
+		{Name: "Product2", Sku: 2, Categories: []string{"Cat2"}, Price: product_valueobject.MustBRL(200)},
+		{Name: "Product3", Sku: 3, Categories: []string{"Cat3"}, Price: product_valueobject.MustBRL(300)},
 	}
 
 	for _, p := range products {
-		_ = repo.Add(p)
+		_ = repo.Add(context.Background(), p)
 	}
 
 	// Repositório com produtos
 	t.Run("repository with products", func(t *testing.T) {
-		found, err := repo.Find()
+		page, err := repo.Find(context.Background(), ProductCriteria{})
+		found := page.Products
 		if err != nil {
 			t.Errorf("Find() unexpected error = %v", err)
 		}
@@ -133,6 +172,260 @@ func TestProductRepository_Find(t *testing.T) {
 	})
 }
 
+func TestProductRepository_FindWithCriteria(t *testing.T) {
+	repo := NewRepository()
+	base := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	products := []product_entity.Product{
+		{ID: "00000000-0000-4000-8000-000000000001", Name: "Mouse", Sku: 1, Categories: []string{"Electronics"}, Price: product_valueobject.MustBRL(100), CreatedAt: base},
+		{ID: "00000000-0000-4000-8000-000000000002", Name: "Keyboard", Sku: 2, Categories: []string{"Electronics"}, Price: product_valueobject.MustBRL(300), CreatedAt: base.Add(time.Minute)},
+		{ID: "00000000-0000-4000-8000-000000000003", Name: "Book", Sku: 3, Categories: []string{"Books"}, Price: product_valueobject.MustBRL(200), CreatedAt: base.Add(2 * time.Minute)},
+		{ID: "00000000-0000-4000-8000-000000000004", Name: "Monitor", Sku: 4, Categories: []string{"Electronics"}, Price: product_valueobject.MustBRL(300), CreatedAt: base.Add(3 * time.Minute)},
+	}
+	for _, p := range products {
+		_ = repo.Add(context.Background(), p)
+	}
+
+	names := func(page ProductPage) []string {
+		result := make([]string, 0, len(page.Products))
+		for _, p := range page.Products {
+			result = append(result, p.Name)
+		}
+		return result
+	}
+
+	t.Run("default sort is newest first", func(t *testing.T) {
+		page, _ := repo.Find(context.Background(), ProductCriteria{})
+		if got := names(page); strings.Join(got, ",") != "Monitor,Book,Keyboard,Mouse" {
+			t.Errorf("Find() order = %v", got)
+		}
+	})
+
+	t.Run("filter by category and price range", func(t *testing.T) {
+		minPrice, maxPrice := int64(150), int64(300)
+		page, _ := repo.Find(context.Background(), ProductCriteria{Category: "Electronics", Currency: "BRL", MinPrice: &minPrice, MaxPrice: &maxPrice, Sort: ProductSort{Field: SortByName}})
+		if got := names(page); strings.Join(got, ",") != "Keyboard,Monitor" {
+			t.Errorf("Find() = %v, want [Keyboard Monitor]", got)
+		}
+		if page.Total != 2 {
+			t.Errorf("Total = %d, want 2", page.Total)
+		}
+	})
+
+	t.Run("price filters and sort require a currency", func(t *testing.T) {
+		maxPrice := int64(300)
+		for _, criteria := range []ProductCriteria{{MaxPrice: &maxPrice}, {Sort: ProductSort{Field: SortByPrice}}} {
+			if _, err := repo.Find(context.Background(), criteria); !errors.Is(err, ErrCurrencyRequired) {
+				t.Errorf("Find(%+v) error = %v, want %v", criteria, err, ErrCurrencyRequired)
+			}
+		}
+
+		page, _ := repo.Find(context.Background(), ProductCriteria{Currency: "USD", MaxPrice: &maxPrice})
+		if page.Total != 0 {
+			t.Errorf("Total = %d, want no products priced in USD", page.Total)
+		}
+	})
+
+	t.Run("filter by sku", func(t *testing.T) {
+		sku := 3
+		page, _ := repo.Find(context.Background(), ProductCriteria{Sku: &sku})
+		if got := names(page); len(got) != 1 || got[0] != "Book" {
+			t.Errorf("Find() = %v, want [Book]", got)
+		}
+	})
+
+	t.Run("paginate with cursor and ties on price", func(t *testing.T) {
+		sort := ProductSort{Field: SortByPrice, Descending: true}
+		var (
+			seen   []string
+			cursor *ProductCursor
+		)
+
+		for i := 0; i < 10; i++ {
+			page, err := repo.Find(context.Background(), ProductCriteria{Currency: "BRL", Sort: sort, Limit: 1, After: cursor})
+			if err != nil {
+				t.Fatalf("Find() error = %v", err)
+			}
+			if page.Total != 4 {
+				t.Errorf("Total = %d, want 4", page.Total)
+			}
+			seen = append(seen, names(page)...)
+			if page.NextCursor == "" {
+				break
+			}
+			next, err := DecodeProductCursor(page.NextCursor, sort)
+			if err != nil {
+				t.Fatalf("DecodeProductCursor() error = %v", err)
+			}
+			cursor = &next
+		}
+
+		if got := strings.Join(seen, ","); got != "Keyboard,Monitor,Book,Mouse" {
+			t.Errorf("pages = %v, want Keyboard,Monitor,Book,Mouse", got)
+		}
+	})
+
+	t.Run("last page has no cursor", func(t *testing.T) {
+		page, _ := repo.Find(context.Background(), ProductCriteria{Limit: 4})
+		if page.NextCursor != "" {
+			t.Errorf("NextCursor = %q, want empty", page.NextCursor)
+		}
+	})
+}
+
+func TestProductRepository_Reindex(t *testing.T) {
+	repo := NewRepository()
+	for i, name := range []string{"Mouse", "Keyboard"} {
+		_ = repo.Add(context.Background(), product_entity.Product{Name: name, Sku: i + 1, Categories: []string{"Electronics"}, Price: product_valueobject.MustBRL(100)})
+	}
+
+	all, _ := repo.Changes(context.Background(), 0, 0)
+	since, _ := DecodeChangeToken(all.NextToken)
+
+	var progress []int
+	done, err := repo.Reindex(context.Background(), 100, func(done, total int) {
+		progress = append(progress, done, total)
+	})
+	if err != nil || done != 2 {
+		t.Fatalf("Reindex() = %d, %v; want 2", done, err)
+	}
+	if len(progress) != 2 || progress[0] != 2 || progress[1] != 2 {
+		t.Errorf("progress = %v, want [2 2]", progress)
+	}
+
+	// A reindexação não altera os produtos, então não avança o change feed
+	if page, _ := repo.Changes(context.Background(), since.Sequence, 0); len(page.Changes) != 0 {
+		t.Errorf("Changes() after Reindex = %d changes, want 0", len(page.Changes))
+	}
+}
+
+func TestProductRepository_ReplaceCategory(t *testing.T) {
+	ctx := context.Background()
+	dispatcher := shared_events.NewEventDispatcher()
+	received := make(chan *product_events.ProductUpdatedEvent, 10)
+	dispatcher.Register("product.updated", func(event shared_events.Event) {
+		received <- shared_events.Unwrap(event).(*product_events.ProductUpdatedEvent)
+	})
+	repo := NewRepositoryWithDispatcher(dispatcher)
+	_ = repo.Add(ctx, product_entity.Product{ID: "id-1", Name: "Notebook", Sku: 1, Categories: []string{"Eletronicos", "Computadores"}, Price: product_valueobject.MustBRL(100), Version: 1})
+	_ = repo.Add(ctx, product_entity.Product{ID: "id-2", Name: "Phone", Sku: 2, Categories: []string{"Eletrônicos", "Eletronicos"}, Price: product_valueobject.MustBRL(100), Version: 1})
+	_ = repo.Add(ctx, product_entity.Product{ID: "id-3", Name: "Book", Sku: 3, Categories: []string{"Livros"}, Price: product_valueobject.MustBRL(100), Version: 1})
+
+	if count, err := repo.CountCategory(ctx, "Eletronicos"); err != nil || count != 2 {
+		t.Fatalf("CountCategory() = %d, %v; want 2", count, err)
+	}
+
+	all, _ := repo.Changes(ctx, 0, 0)
+	since, _ := DecodeChangeToken(all.NextToken)
+	replaced, err := repo.ReplaceCategory(ctx, []string{"Eletronicos"}, "Eletrônicos")
+	if err != nil || replaced != 2 {
+		t.Fatalf("ReplaceCategory() = %d, %v; want 2", replaced, err)
+	}
+
+	notebook, _ := repo.FindOne(ctx, "Notebook", false)
+	if strings.Join(notebook.Categories, ",") != "Eletrônicos,Computadores" || notebook.Version != 2 {
+		t.Errorf("Notebook = %v (version %d), want [Eletrônicos Computadores] (version 2)", notebook.Categories, notebook.Version)
+	}
+	// A categoria de destino não é repetida
+	phone, _ := repo.FindOne(ctx, "Phone", false)
+	if strings.Join(phone.Categories, ",") != "Eletrônicos" {
+		t.Errorf("Phone categories = %v, want [Eletrônicos]", phone.Categories)
+	}
+	book, _ := repo.FindOne(ctx, "Book", false)
+	if book.Version != 1 {
+		t.Errorf("Book version = %d, want 1", book.Version)
+	}
+
+	if page, _ := repo.Changes(ctx, since.Sequence, 0); len(page.Changes) != 2 {
+		t.Errorf("Changes() after ReplaceCategory = %d changes, want 2", len(page.Changes))
+	}
+	if count, _ := repo.CountCategory(ctx, "Eletronicos"); count != 0 {
+		t.Errorf("CountCategory() after ReplaceCategory = %d, want 0", count)
+	}
+
+	// Cada produto alterado publica product.updated com as categorias antes e depois da troca
+	updated := make(map[string]*product_events.ProductUpdatedEvent)
+	for len(updated) < 2 {
+		select {
+		case event := <-received:
+			updated[event.After.ID] = event
+		case <-time.After(time.Second):
+			t.Fatalf("received %d product.updated events, want 2", len(updated))
+		}
+	}
+	if event := updated["id-1"]; event == nil ||
+		strings.Join(event.Before.Categories, ",") != "Eletronicos,Computadores" ||
+		strings.Join(event.After.Categories, ",") != "Eletrônicos,Computadores" {
+		t.Errorf("product.updated for id-1 = %+v", event)
+	}
+	if event := updated["id-2"]; event == nil || strings.Join(event.After.Categories, ",") != "Eletrônicos" {
+		t.Errorf("product.updated for id-2 = %+v", event)
+	}
+}
+
+func TestProductRepository_Export(t *testing.T) {
+	repo := NewRepository()
+	base := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	for i, name := range []string{"Mouse", "Keyboard", "Book"} {
+		category := "Electronics"
+		if name == "Book" {
+			category = "Books"
+		}
+		_ = repo.Add(context.Background(), product_entity.Product{Name: name, Sku: i + 1, Categories: []string{category}, Price: product_valueobject.MustBRL(100), CreatedAt: base.Add(time.Duration(i) * time.Minute)})
+	}
+
+	var names []string
+	collect := func(product product_entity.Product) error {
+		names = append(names, product.Name)
+		return nil
+	}
+
+	// Limit e After são ignorados: todos os produtos que satisfazem os filtros são exportados
+	criteria := ProductCriteria{Category: "Electronics", Sort: ProductSort{Field: SortByName}, Limit: 1}
+	if err := repo.Export(context.Background(), criteria, collect); err != nil {
+		t.Fatalf("Export() unexpected error = %v", err)
+	}
+	if strings.Join(names, ",") != "Keyboard,Mouse" {
+		t.Errorf("Export() = %v, want Keyboard,Mouse", names)
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err := repo.Export(context.Background(), ProductCriteria{}, func(product_entity.Product) error {
+		calls++
+		return stop
+	})
+	if err != stop || calls != 1 {
+		t.Errorf("Export() = %v after %d calls, want the fn error after 1", err, calls)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := repo.Export(ctx, ProductCriteria{}, collect); err != context.Canceled {
+		t.Errorf("Export() with canceled ctx = %v, want context.Canceled", err)
+	}
+}
+
+func TestProductRepository_Search(t *testing.T) {
+	repo := NewRepository()
+	_ = repo.Add(context.Background(), product_entity.Product{ID: "id-1", Name: "Notebook", Sku: 1, Categories: []string{"Eletrônicos"}, Price: product_valueobject.MustBRL(100)})
+	_ = repo.Add(context.Background(), product_entity.Product{Name: "Livro", Sku: 2, Categories: []string{"Livros"}, Price: product_valueobject.MustBRL(100)})
+	_ = repo.Delete(context.Background(), "Livro", 1)
+
+	found, err := repo.Search(context.Background(), "eletronicos", 0)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(found) != 1 || found[0].Name != "Notebook" {
+		t.Errorf("Search() = %v, want [Notebook]", found)
+	}
+
+	found, _ = repo.Search(context.Background(), "livro", 0)
+	if len(found) != 0 {
+		t.Errorf("Search() should ignore deleted products, got %v", found)
+	}
+}
+
 func TestProductRepository_FindOne(t *testing.T) {
 	repo := NewRepository()
 
@@ -141,9 +434,9 @@ func TestProductRepository_FindOne(t *testing.T) {
 		Name:       "Test Product",
 		Sku:        999,
 		Categories: []string{"Test"},
-		Price:      500,
+		Price:      product_valueobject.MustBRL(500),
 	}
-	_ = repo.Add(product)
+	_ = repo.Add(context.Background(), product)
 
 	tests := []struct {
 		name     string
@@ -166,7 +459,7 @@ func TestProductRepository_FindOne(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			found, err := repo.FindOne(tt.findName)
+			found, err := repo.FindOne(context.Background(), tt.findName, false)
 
 			if tt.wantErr {
 				if err == nil {
@@ -188,49 +481,580 @@ func TestProductRepository_FindOne(t *testing.T) {
 	}
 }
 
+func TestProductRepository_FindByIDAndSku(t *testing.T) {
+	repo := NewRepository()
+	deletedAt := time.Now()
+	_ = repo.Add(context.Background(), product_entity.Product{ID: "3f2b8c1e-4d5a-4b6c-8d7e-9f0a1b2c3d4e", Name: "Notebook/15\"", Sku: 123, Categories: []string{"Electronics"}, Price: product_valueobject.MustBRL(3500)})
+	_ = repo.Add(context.Background(), product_entity.Product{ID: "9a8b7c6d-5e4f-4a3b-9c2d-1e0f9a8b7c6d", Name: "Mouse", Sku: 456, Categories: []string{"Peripherals"}, Price: product_valueobject.MustBRL(100), DeletedAt: &deletedAt})
+
+	tests := []struct {
+		name     string
+		find     func() (product_entity.Product, error)
+		wantErr  bool
+		wantName string
+	}{
+		{
+			name: "find by id",
+			find: func() (product_entity.Product, error) {
+				return repo.FindByID(context.Background(), "3f2b8c1e-4d5a-4b6c-8d7e-9f0a1b2c3d4e", false)
+			},
+			wantName: "Notebook/15\"",
+		},
+		{
+			name: "find by unknown id",
+			find: func() (product_entity.Product, error) {
+				return repo.FindByID(context.Background(), "00000000-0000-4000-8000-000000000000", false)
+			},
+			wantErr: true,
+		},
+		{
+			name: "find deleted product by id",
+			find: func() (product_entity.Product, error) {
+				return repo.FindByID(context.Background(), "9a8b7c6d-5e4f-4a3b-9c2d-1e0f9a8b7c6d", false)
+			},
+			wantErr: true,
+		},
+		{
+			name: "find deleted product by id including deleted",
+			find: func() (product_entity.Product, error) {
+				return repo.FindByID(context.Background(), "9a8b7c6d-5e4f-4a3b-9c2d-1e0f9a8b7c6d", true)
+			},
+			wantName: "Mouse",
+		},
+		{
+			name:     "find by sku",
+			find:     func() (product_entity.Product, error) { return repo.FindBySku(context.Background(), 123, false) },
+			wantName: "Notebook/15\"",
+		},
+		{
+			name:    "find by unknown sku",
+			find:    func() (product_entity.Product, error) { return repo.FindBySku(context.Background(), 999, false) },
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, err := tt.find()
+
+			if tt.wantErr {
+				if err == nil || err.Error() != "product not found" {
+					t.Errorf("expected 'product not found', got %v", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error = %v", err)
+			}
+			if found.Name != tt.wantName {
+				t.Errorf("Name = %v, want %v", found.Name, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestProductRepository_Update(t *testing.T) {
+	tests := []struct {
+		name       string
+		updateName string
+		product    product_entity.Product
+		wantErr    bool
+		errMsg     string
+		wantKey    string
+	}{
+		{
+			name:       "update price and categories",
+			updateName: "Notebook",
+			product:    product_entity.Product{Name: "Notebook", Sku: 123, Categories: []string{"Electronics", "Computers"}, Price: product_valueobject.MustBRL(4000), Version: 2},
+			wantKey:    "Notebook",
+		},
+		{
+			name:       "rename product",
+			updateName: "Notebook",
+			product:    product_entity.Product{Name: "Notebook Pro", Sku: 123, Categories: []string{"Electronics"}, Price: product_valueobject.MustBRL(3500), Version: 2},
+			wantKey:    "Notebook Pro",
+		},
+		{
+			name:       "stale version",
+			updateName: "Notebook",
+			product:    product_entity.Product{Name: "Notebook", Sku: 123, Categories: []string{"Electronics"}, Price: product_valueobject.MustBRL(4000), Version: 5},
+			wantErr:    true,
+			errMsg:     "product was modified by another request",
+		},
+		{
+			name:       "product not found",
+			updateName: "Non Existing",
+			product:    product_entity.Product{Name: "Non Existing", Sku: 1, Categories: []string{"Test"}, Price: product_valueobject.MustBRL(100)},
+			wantErr:    true,
+			errMsg:     "product not found",
+		},
+		{
+			name:       "rename to existing product",
+			updateName: "Notebook",
+			product:    product_entity.Product{Name: "Mouse", Sku: 123, Categories: []string{"Electronics"}, Price: product_valueobject.MustBRL(3500), Version: 2},
+			wantErr:    true,
+			errMsg:     "product with this name already exists",
+		},
+		{
+			name:       "invalid product",
+			updateName: "Notebook",
+			product:    product_entity.Product{Name: "Notebook", Sku: 123, Categories: []string{"Electronics"}, Price: product_valueobject.MustBRL(0)},
+			wantErr:    true,
+			errMsg:     "price must be positive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewRepository()
+			_ = repo.Add(context.Background(), product_entity.Product{Name: "Notebook", Sku: 123, Categories: []string{"Electronics"}, Price: product_valueobject.MustBRL(3500)})
+			_ = repo.Add(context.Background(), product_entity.Product{Name: "Mouse", Sku: 456, Categories: []string{"Peripherals"}, Price: product_valueobject.MustBRL(100)})
+
+			err := repo.Update(context.Background(), tt.updateName, withPendingChange(tt.product))
+
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Update() expected error, got nil")
+					return
+				}
+				if err.Error() != tt.errMsg {
+					t.Errorf("Update() error = %v, want %v", err.Error(), tt.errMsg)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Update() unexpected error = %v", err)
+			}
+
+			found, err := repo.FindOne(context.Background(), tt.wantKey, false)
+			if err != nil {
+				t.Fatalf("FindOne(%q) unexpected error = %v", tt.wantKey, err)
+			}
+			if found.Price != tt.product.Price {
+				t.Errorf("Update() Price = %v, want %v", found.Price, tt.product.Price)
+			}
+			if len(found.Categories) != len(tt.product.Categories) {
+				t.Errorf("Update() Categories = %v, want %v", found.Categories, tt.product.Categories)
+			}
+			if found.Version != 2 {
+				t.Errorf("Update() Version = %d, want 2", found.Version)
+			}
+
+			if tt.wantKey != tt.updateName {
+				if _, err := repo.FindOne(context.Background(), tt.updateName, false); err == nil {
+					t.Errorf("Update() old name %q still present after rename", tt.updateName)
+				}
+			}
+
+			page, _ := repo.Find(context.Background(), ProductCriteria{})
+			products := page.Products
+			if len(products) != 2 {
+				t.Errorf("Update() products count = %d, want 2", len(products))
+			}
+		})
+	}
+}
+
+func TestProductRepository_UpdateAfterSeveralChanges(t *testing.T) {
+	ctx := context.Background()
+	repo := NewRepository()
+	_ = repo.Add(ctx, product_entity.Product{ID: "id-1", Name: "Notebook", Sku: 123, Categories: []string{"Electronics"}, Price: product_valueobject.MustBRL(3500)})
+
+	// Duas alterações antes da gravação: a versão esperada é a lida, não a anterior à última
+	product, _ := repo.FindOne(ctx, "Notebook", false)
+	_ = product.Update("Notebook", 123, []string{"Electronics"}, product_valueobject.MustBRL(3600))
+	_ = product.Update("Notebook", 123, []string{"Electronics"}, product_valueobject.MustBRL(3700))
+	if err := repo.Update(ctx, "Notebook", product); err != nil {
+		t.Fatalf("Update() unexpected error = %v", err)
+	}
+
+	stored, _ := repo.FindOne(ctx, "Notebook", false)
+	if err := repo.Update(ctx, "Notebook", product); !errors.Is(err, product_errors.ErrVersionConflict) {
+		t.Errorf("Update() with the same product again error = %v, want %v", err, product_errors.ErrVersionConflict)
+	}
+	if stored.Version != 3 || stored.ExpectedVersion() != 3 {
+		t.Errorf("FindOne() = version %d (expected %d), want 3", stored.Version, stored.ExpectedVersion())
+	}
+}
+
+func TestProductRepository_DeleteAndRestore(t *testing.T) {
+	repo := NewRepository()
+	_ = repo.Add(context.Background(), product_entity.Product{Name: "Notebook", Sku: 123, Categories: []string{"Electronics"}, Price: product_valueobject.MustBRL(3500)})
+	_ = repo.Add(context.Background(), product_entity.Product{Name: "Mouse", Sku: 456, Categories: []string{"Peripherals"}, Price: product_valueobject.MustBRL(100)})
+
+	t.Run("delete existing product", func(t *testing.T) {
+		if err := repo.Delete(context.Background(), "Notebook", 1); err != nil {
+			t.Fatalf("Delete() unexpected error = %v", err)
+		}
+	})
+
+	t.Run("delete with stale version", func(t *testing.T) {
+		if err := repo.Delete(context.Background(), "Mouse", 7); !errors.Is(err, product_errors.ErrVersionConflict) {
+			t.Errorf("Delete() error = %v, want ErrVersionConflict", err)
+		}
+	})
+
+	t.Run("deleted product is hidden by default", func(t *testing.T) {
+		if _, err := repo.FindOne(context.Background(), "Notebook", false); err == nil {
+			t.Error("FindOne() expected error for deleted product, got nil")
+		}
+
+		page, _ := repo.Find(context.Background(), ProductCriteria{})
+		products := page.Products
+		if len(products) != 1 {
+			t.Errorf("Find() count = %d, want 1", len(products))
+		}
+	})
+
+	t.Run("deleted product is visible with includeDeleted", func(t *testing.T) {
+		found, err := repo.FindOne(context.Background(), "Notebook", true)
+		if err != nil {
+			t.Fatalf("FindOne(includeDeleted) unexpected error = %v", err)
+		}
+		if !found.IsDeleted() {
+			t.Error("FindOne(includeDeleted) product is not marked as deleted")
+		}
+		if found.Version != 2 {
+			t.Errorf("FindOne(includeDeleted) Version = %d, want 2", found.Version)
+		}
+
+		page, _ := repo.Find(context.Background(), ProductCriteria{IncludeDeleted: true})
+		products := page.Products
+		if len(products) != 2 {
+			t.Errorf("Find(IncludeDeleted) count = %d, want 2", len(products))
+		}
+	})
+
+	t.Run("deleted product is excluded from metrics", func(t *testing.T) {
+		metrics := repo.GetMetrics(context.Background())
+		if metrics.TotalProducts != 1 {
+			t.Errorf("GetMetrics() TotalProducts = %d, want 1", metrics.TotalProducts)
+		}
+		if metrics.TotalValue["BRL"] != 100 {
+			t.Errorf("GetMetrics() TotalValue[BRL] = %f, want 100", metrics.TotalValue["BRL"])
+		}
+		if _, exists := metrics.ProductsByCategory["Electronics"]; exists {
+			t.Error("GetMetrics() counted category of deleted product")
+		}
+	})
+
+	t.Run("deleted product cannot be updated or deleted again", func(t *testing.T) {
+		err := repo.Update(context.Background(), "Notebook", withPendingChange(product_entity.Product{Name: "Notebook", Sku: 123, Categories: []string{"Electronics"}, Price: product_valueobject.MustBRL(1), Version: 3}))
+		if err == nil || err.Error() != "product not found" {
+			t.Errorf("Update() error = %v, want 'product not found'", err)
+		}
+		if err := repo.Delete(context.Background(), "Notebook", 2); err == nil {
+			t.Error("Delete() expected error for deleted product, got nil")
+		}
+	})
+
+	t.Run("restore deleted product", func(t *testing.T) {
+		if err := repo.Restore(context.Background(), "Notebook", 2); err != nil {
+			t.Fatalf("Restore() unexpected error = %v", err)
+		}
+		if _, err := repo.FindOne(context.Background(), "Notebook", false); err != nil {
+			t.Errorf("FindOne() after Restore() unexpected error = %v", err)
+		}
+	})
+
+	t.Run("restore active product", func(t *testing.T) {
+		if err := repo.Restore(context.Background(), "Mouse", 1); err == nil {
+			t.Error("Restore() expected error for active product, got nil")
+		}
+	})
+
+	t.Run("delete non-existing product", func(t *testing.T) {
+		if err := repo.Delete(context.Background(), "Non Existing", 1); err == nil || err.Error() != "product not found" {
+			t.Errorf("Delete() error = %v, want 'product not found'", err)
+		}
+	})
+}
+
+func TestProductRepository_Changes(t *testing.T) {
+	repo := NewRepository()
+	_ = repo.Add(context.Background(), product_entity.Product{ID: "id-1", Name: "Notebook", Sku: 123, Categories: []string{"Electronics"}, Price: product_valueobject.MustBRL(3500)})
+	_ = repo.Add(context.Background(), product_entity.Product{ID: "id-2", Name: "Mouse", Sku: 456, Categories: []string{"Peripherals"}, Price: product_valueobject.MustBRL(100)})
+
+	all, err := repo.Changes(context.Background(), 0, 0)
+	if err != nil {
+		t.Fatalf("Changes() error = %v", err)
+	}
+	if len(all.Changes) != 2 || all.Changes[0].Product.Name != "Notebook" || all.Changes[1].Type != ChangeCreated {
+		t.Fatalf("Changes(0) = %+v", all.Changes)
+	}
+	since, _ := DecodeChangeToken(all.NextToken)
+
+	// Escritas depois do token: uma atualização com renomeação e uma exclusão
+	_ = repo.Update(context.Background(), "Notebook", withPendingChange(product_entity.Product{ID: "id-1", Name: "Notebook Pro", Sku: 123, Categories: []string{"Electronics"}, Price: product_valueobject.MustBRL(4000), Version: 2}))
+	_ = repo.Delete(context.Background(), "Mouse", 1)
+	_ = repo.Add(context.Background(), product_entity.Product{ID: "id-3", Name: "Keyboard", Sku: 789, Categories: []string{"Peripherals"}, Price: product_valueobject.MustBRL(200)})
+	_ = repo.Update(context.Background(), "Notebook Pro", withPendingChange(product_entity.Product{ID: "id-1", Name: "Notebook Pro", Sku: 123, Categories: []string{"Electronics"}, Price: product_valueobject.MustBRL(4100), Version: 3}))
+
+	page, _ := repo.Changes(context.Background(), since.Sequence, 2)
+	if !page.HasMore || len(page.Changes) != 2 {
+		t.Fatalf("Changes(limit 2) = %d changes, more %v", len(page.Changes), page.HasMore)
+	}
+	if page.Changes[0].Type != ChangeDeleted || page.Changes[0].Product.Name != "Mouse" {
+		t.Errorf("first change = %+v, want Mouse deleted", page.Changes[0])
+	}
+	if page.Changes[1].Type != ChangeCreated || page.Changes[1].Product.Name != "Keyboard" {
+		t.Errorf("second change = %+v, want Keyboard created", page.Changes[1])
+	}
+
+	next, _ := DecodeChangeToken(page.NextToken)
+	page, _ = repo.Changes(context.Background(), next.Sequence, 2)
+	if page.HasMore || len(page.Changes) != 1 {
+		t.Fatalf("Changes(next) = %d changes, more %v", len(page.Changes), page.HasMore)
+	}
+	// Produto escrito duas vezes aparece uma vez, com o estado atual, na posição da última escrita
+	if change := page.Changes[0]; change.Type != ChangeUpdated || change.Product.Price.Amount() != 4100 {
+		t.Errorf("last change = %+v, want Notebook Pro updated", change)
+	}
+
+	_ = repo.Restore(context.Background(), "Mouse", 2)
+	page, _ = repo.Changes(context.Background(), page.Changes[0].Sequence, 0)
+	if len(page.Changes) != 1 || page.Changes[0].Type != ChangeUpdated || page.Changes[0].Product.IsDeleted() {
+		t.Errorf("Changes() after Restore() = %+v, want Mouse updated", page.Changes)
+	}
+}
+
+type testEvent struct{ name string }
+
+func (e testEvent) EventName() string { return e.name }
+
+func TestProductRepository_PublishesEventsAfterWrite(t *testing.T) {
+	dispatcher := shared_events.NewEventDispatcher()
+	received := make(chan string, 10)
+	for _, name := range []string{"product.created", "product.deleted"} {
+		dispatcher.Register(name, func(event shared_events.Event) {
+			received <- event.EventName()
+		})
+	}
+
+	repo := NewRepositoryWithDispatcher(dispatcher)
+	product := product_entity.Product{Name: "Notebook", Sku: 123, Categories: []string{"Electronics"}, Price: product_valueobject.MustBRL(3500)}
+
+	if err := repo.Add(context.Background(), product, testEvent{"product.created"}); err != nil {
+		t.Fatalf("Add() unexpected error = %v", err)
+	}
+	// Escritas que falham não publicam eventos
+	if err := repo.Add(context.Background(), product, testEvent{"product.created"}); err == nil {
+		t.Fatal("Add() expected error for duplicated product, got nil")
+	}
+	if err := repo.Delete(context.Background(), "Non Existing", 1, testEvent{"product.deleted"}); err == nil {
+		t.Fatal("Delete() expected error for missing product, got nil")
+	}
+	if err := repo.Delete(context.Background(), "Notebook", 1, testEvent{"product.deleted"}); err != nil {
+		t.Fatalf("Delete() unexpected error = %v", err)
+	}
+
+	var names []string
+	for len(names) < 2 {
+		select {
+		case name := <-received:
+			names = append(names, name)
+		case <-time.After(time.Second):
+			t.Fatalf("received %v events, want 2", names)
+		}
+	}
+
+	select {
+	case name := <-received:
+		t.Errorf("unexpected event %s published after failed write", name)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestProductRepository_HandlersCanReadDuringPublish(t *testing.T) {
+	// Fila sem buffer e um worker: a segunda escrita espera o handler da primeira,
+	// que lê o repositório
+	dispatcher := shared_events.NewEventDispatcherWithPool(shared_events.DispatcherConfig{Workers: 1, QueueSize: 0})
+	repo := NewRepositoryWithDispatcher(dispatcher)
+	dispatcher.Register("product.created", func(event shared_events.Event) {
+		time.Sleep(20 * time.Millisecond)
+		repo.Find(context.Background(), ProductCriteria{})
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i, name := range []string{"Notebook", "Mouse", "Teclado"} {
+			product := product_entity.Product{Name: name, Sku: i + 1, Categories: []string{"Electronics"}, Price: product_valueobject.MustBRL(3500)}
+			if err := repo.Add(context.Background(), product, testEvent{"product.created"}); err != nil {
+				t.Errorf("Add(%s) unexpected error = %v", name, err)
+			}
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Add() deadlocked with a handler reading the repository")
+	}
+	if err := dispatcher.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+}
+
+func TestProductRepository_AddBatch(t *testing.T) {
+	existing := product_entity.Product{ID: "id-1", Name: "Notebook", Sku: 1, Categories: []string{"Electronics"}, Price: product_valueobject.MustBRL(3500)}
+	entries := []BatchEntry{
+		{Product: product_entity.Product{ID: "id-2", Name: "Mouse", Sku: 2, Categories: []string{"Accessories"}, Price: product_valueobject.MustBRL(50)}},
+		{Product: product_entity.Product{ID: "id-3", Name: "Notebook", Sku: 3, Categories: []string{"Electronics"}, Price: product_valueobject.MustBRL(3500)}},
+		{Product: product_entity.Product{ID: "id-4", Name: "Keyboard", Sku: 2, Categories: []string{"Accessories"}, Price: product_valueobject.MustBRL(150)}},
+		{Product: product_entity.Product{ID: "id-5", Name: "Monitor", Sku: 5, Categories: []string{"Electronics"}, Price: product_valueobject.MustBRL(900)}},
+	}
+
+	newRepo := func(t *testing.T) *ProductRepository {
+		repo := NewRepository()
+		if err := repo.Add(context.Background(), existing); err != nil {
+			t.Fatalf("Add() unexpected error = %v", err)
+		}
+		return repo
+	}
+	conflicts := func(result BatchResult) []bool {
+		failed := make([]bool, len(result.Errors))
+		for i, err := range result.Errors {
+			failed[i] = errors.Is(err, product_errors.ErrAlreadyExists)
+		}
+		return failed
+	}
+	want := []bool{false, true, true, false}
+
+	t.Run("best effort skips the conflicting entries", func(t *testing.T) {
+		repo := newRepo(t)
+
+		result, err := repo.AddBatch(context.Background(), entries, false)
+		if err != nil {
+			t.Fatalf("AddBatch() unexpected error = %v", err)
+		}
+		if result.Created != 2 || !equalBools(conflicts(result), want) {
+			t.Errorf("AddBatch() created %d with conflicts %v, want 2 with %v", result.Created, conflicts(result), want)
+		}
+
+		for _, name := range []string{"Mouse", "Monitor"} {
+			product, err := repo.FindOne(context.Background(), name, false)
+			if err != nil || product.Version != 1 {
+				t.Errorf("FindOne(%s) = version %d, %v; want version 1", name, product.Version, err)
+			}
+		}
+		if _, err := repo.FindOne(context.Background(), "Keyboard", false); !errors.Is(err, product_errors.ErrNotFound) {
+			t.Errorf("FindOne(Keyboard) error = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("atomic adds nothing when an entry conflicts", func(t *testing.T) {
+		repo := newRepo(t)
+
+		result, err := repo.AddBatch(context.Background(), entries, true)
+		if err != nil {
+			t.Fatalf("AddBatch() unexpected error = %v", err)
+		}
+		if result.Created != 0 || !equalBools(conflicts(result), want) {
+			t.Errorf("AddBatch() created %d with conflicts %v, want 0 with %v", result.Created, conflicts(result), want)
+		}
+		if page, _ := repo.Find(context.Background(), ProductCriteria{}); page.Total != 1 {
+			t.Errorf("Find() total = %d, want only the existing product", page.Total)
+		}
+	})
+
+	t.Run("atomic adds every entry without conflicts", func(t *testing.T) {
+		repo := newRepo(t)
+
+		result, err := repo.AddBatch(context.Background(), []BatchEntry{entries[0], entries[3]}, true)
+		if err != nil || result.Created != 2 || result.Failed() {
+			t.Errorf("AddBatch() = %+v, %v; want 2 created", result, err)
+		}
+	})
+
+	t.Run("publishes only the events of added products", func(t *testing.T) {
+		dispatcher := shared_events.NewEventDispatcher()
+		received := make(chan string, 10)
+		dispatcher.Register("product.created", func(event shared_events.Event) {
+			received <- event.EventName()
+		})
+		repo := NewRepositoryWithDispatcher(dispatcher)
+		repo.Add(context.Background(), existing)
+
+		withEvents := make([]BatchEntry, len(entries))
+		for i, entry := range entries {
+			entry.Events = []shared_events.Event{testEvent{"product.created"}}
+			withEvents[i] = entry
+		}
+		repo.AddBatch(context.Background(), withEvents, false)
+
+		for i := 0; i < 2; i++ {
+			select {
+			case <-received:
+			case <-time.After(time.Second):
+				t.Fatalf("received %d events, want 2", i)
+			}
+		}
+		select {
+		case <-received:
+			t.Error("event published for a conflicting entry")
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+}
+
+func equalBools(a, b []bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
 func TestProductRepository_GetMetrics(t *testing.T) {
 	repo := NewRepository()
 
 	t.Run("empty repository metrics", func(t *testing.T) {
-		metrics := repo.GetMetrics()
+		metrics := repo.GetMetrics(context.Background())
 
 		if metrics.TotalProducts != 0 {
 			t.Errorf("GetMetrics() TotalProducts = %d, want 0", metrics.TotalProducts)
 		}
-		if metrics.TotalValue != 0 {
-			t.Errorf("GetMetrics() TotalValue = %f, want 0", metrics.TotalValue)
+		if len(metrics.TotalValue) != 0 {
+			t.Errorf("GetMetrics() TotalValue = %v, want empty", metrics.TotalValue)
 		}
-		if metrics.AveragePrice != 0 {
-			t.Errorf("GetMetrics() AveragePrice = %f, want 0", metrics.AveragePrice)
+		if len(metrics.AveragePrice) != 0 {
+			t.Errorf("GetMetrics() AveragePrice = %v, want empty", metrics.AveragePrice)
 		}
 	})
 
 	// Adicionar produtos
 	products := []product_entity.Product{
-		{Name: "Product1", Sku: 1, Categories: []string{"Electronics", "Computers"}, Price: 1000},
-		{Name: "Product2", Sku: 2, Categories: []string{"Electronics"}, Price: 2000},
-		{Name: "Product3", Sku: 3, Categories: []string{"Books"}, Price: 500},
+		{Name: "Product1", Sku: 1, Categories: []string{"Electronics", "Computers"}, Price: product_valueobject.MustBRL(1000)},
+		{Name: "Product2", Sku: 2, Categories: []string{"Electronics"}, Price: product_valueobject.MustBRL(2000)},
+		{Name: "Product3", Sku: 3, Categories: []string{"Books"}, Price: product_valueobject.MustBRL(500)},
 	}
 
 	for _, p := range products {
-		_ = repo.Add(p)
+		_ = repo.Add(context.Background(), p)
 	}
 
 	t.Run("repository with products metrics", func(t *testing.T) {
-		metrics := repo.GetMetrics()
+		metrics := repo.GetMetrics(context.Background())
 
 		if metrics.TotalProducts != 3 {
 			t.Errorf("GetMetrics() TotalProducts = %d, want 3", metrics.TotalProducts)
 		}
 
 		expectedTotal := 3500.0
-		if metrics.TotalValue != expectedTotal {
-			t.Errorf("GetMetrics() TotalValue = %f, want %f", metrics.TotalValue, expectedTotal)
+		if metrics.TotalValue["BRL"] != expectedTotal {
+			t.Errorf("GetMetrics() TotalValue[BRL] = %f, want %f", metrics.TotalValue["BRL"], expectedTotal)
 		}
 
 		expectedAvg := 3500.0 / 3
-		if metrics.AveragePrice != expectedAvg {
-			t.Errorf("GetMetrics() AveragePrice = %f, want %f", metrics.AveragePrice, expectedAvg)
+		if metrics.AveragePrice["BRL"] != expectedAvg {
+			t.Errorf("GetMetrics() AveragePrice[BRL] = %f, want %f", metrics.AveragePrice["BRL"], expectedAvg)
 		}
 
 		// Verificar contagem por categoria
@@ -246,6 +1070,33 @@ func TestProductRepository_GetMetrics(t *testing.T) {
 	})
 }
 
+func TestProductRepository_GetMetrics_PerCurrency(t *testing.T) {
+	repo := NewRepository()
+
+	usd, _ := product_valueobject.NewMoney(2000, "USD")
+	_ = repo.Add(context.Background(), product_entity.Product{ID: "id-1", Name: "Notebook", Sku: 1, Categories: []string{"Electronics"}, Price: product_valueobject.MustBRL(3000)})
+	_ = repo.Add(context.Background(), product_entity.Product{Name: "Mouse", Sku: 2, Categories: []string{"Peripherals"}, Price: product_valueobject.MustBRL(1000)})
+	_ = repo.Add(context.Background(), product_entity.Product{Name: "Keyboard", Sku: 3, Categories: []string{"Peripherals"}, Price: usd})
+
+	metrics := repo.GetMetrics(context.Background())
+
+	if metrics.TotalProducts != 3 {
+		t.Errorf("GetMetrics() TotalProducts = %d, want 3", metrics.TotalProducts)
+	}
+	if metrics.TotalValue["BRL"] != 4000 {
+		t.Errorf("GetMetrics() TotalValue[BRL] = %f, want 4000", metrics.TotalValue["BRL"])
+	}
+	if metrics.AveragePrice["BRL"] != 2000 {
+		t.Errorf("GetMetrics() AveragePrice[BRL] = %f, want 2000", metrics.AveragePrice["BRL"])
+	}
+	if metrics.TotalValue["USD"] != 2000 {
+		t.Errorf("GetMetrics() TotalValue[USD] = %f, want 2000", metrics.TotalValue["USD"])
+	}
+	if metrics.AveragePrice["USD"] != 2000 {
+		t.Errorf("GetMetrics() AveragePrice[USD] = %f, want 2000", metrics.AveragePrice["USD"])
+	}
+}
+
 // Teste de concorrência
 func TestProductRepository_ConcurrentAccess(t *testing.T) {
 	repo := NewRepository()
@@ -262,9 +1113,9 @@ func TestProductRepository_ConcurrentAccess(t *testing.T) {
 				Name:       "Product" + string(rune(id)),
 				Sku:        id,
 				Categories: []string{"Test"},
-				Price:      100,
+				Price:      product_valueobject.MustBRL(100),
 			}
-			_ = repo.Add(product)
+			_ = repo.Add(context.Background(), product)
 		}(i)
 	}
 
@@ -275,19 +1126,56 @@ func TestProductRepository_ConcurrentAccess(t *testing.T) {
 	for i := 0; i < numGoroutines; i++ {
 		go func() {
 			defer wg.Done()
-			_, _ = repo.Find()
+			_, _ = repo.Find(context.Background(), ProductCriteria{})
 		}()
 	}
 
 	wg.Wait()
 
 	// Verificar que não houve race conditions
-	products, _ := repo.Find()
+	page, _ := repo.Find(context.Background(), ProductCriteria{})
+	products := page.Products
 	if len(products) == 0 {
 		t.Error("ConcurrentAccess() no products added")
 	}
 }
 
+func TestProductRepository_CanceledContext(t *testing.T) {
+	repo := NewRepository()
+	_ = repo.Add(context.Background(), product_entity.Product{Name: "Notebook", Sku: 123, Categories: []string{"Electronics"}, Price: product_valueobject.MustBRL(3500)})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	writes := map[string]func() error{
+		"add": func() error {
+			return repo.Add(ctx, product_entity.Product{Name: "Mouse", Sku: 456, Categories: []string{"Peripherals"}, Price: product_valueobject.MustBRL(100)})
+		},
+		"update": func() error {
+			return repo.Update(ctx, "Notebook", withPendingChange(product_entity.Product{Name: "Notebook", Sku: 123, Categories: []string{"Electronics"}, Price: product_valueobject.MustBRL(1), Version: 2}))
+		},
+		"add batch": func() error {
+			_, err := repo.AddBatch(ctx, []BatchEntry{{Product: product_entity.Product{Name: "Mouse", Sku: 456, Categories: []string{"Peripherals"}, Price: product_valueobject.MustBRL(100)}}}, false)
+			return err
+		},
+		"delete":  func() error { return repo.Delete(ctx, "Notebook", 1) },
+		"restore": func() error { return repo.Restore(ctx, "Notebook", 1) },
+	}
+
+	for name, write := range writes {
+		t.Run(name, func(t *testing.T) {
+			if err := write(); err != context.Canceled {
+				t.Errorf("error = %v, want context.Canceled", err)
+			}
+		})
+	}
+
+	product, _ := repo.FindOne(context.Background(), "Notebook", false)
+	if product.Price.Amount() != 3500 {
+		t.Errorf("canceled writes changed the product: %+v", product)
+	}
+}
+
 func TestProductRepository_ConcurrentFindAndAdd(t *testing.T) {
 	repo := NewRepository()
 	var wg sync.WaitGroup
@@ -304,9 +1192,9 @@ func TestProductRepository_ConcurrentFindAndAdd(t *testing.T) {
 				Name:       "ConcurrentProduct" + string(rune(id)),
 				Sku:        id + 1000,
 				Categories: []string{"Concurrent"},
-				Price:      id * 10,
+				Price:      product_valueobject.MustBRL(int64(id * 10)),
 			}
-			_ = repo.Add(product)
+			_ = repo.Add(context.Background(), product)
 		}(i)
 	}
 
@@ -314,7 +1202,7 @@ func TestProductRepository_ConcurrentFindAndAdd(t *testing.T) {
 	for i := 0; i < numOperations; i++ {
 		go func() {
 			defer wg.Done()
-			_ = repo.GetMetrics()
+			_ = repo.GetMetrics(context.Background())
 		}()
 	}
 
@@ -328,13 +1216,13 @@ func BenchmarkProductRepository_Add(b *testing.B) {
 		Name:       "Benchmark Product",
 		Sku:        12345,
 		Categories: []string{"Benchmark"},
-		Price:      999,
+		Price:      product_valueobject.MustBRL(999),
 	}
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		repo = NewRepository() // Reset para evitar duplicatas
-		_ = repo.Add(product)
+		_ = repo.Add(context.Background(), product)
 	}
 }
 
@@ -347,14 +1235,14 @@ func BenchmarkProductRepository_Find(b *testing.B) {
 			Name:       "Product" + string(rune(i)),
 			Sku:        i,
 			Categories: []string{"Test"},
-			Price:      100,
+			Price:      product_valueobject.MustBRL(100),
 		}
-		_ = repo.Add(product)
+		_ = repo.Add(context.Background(), product)
 	}
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, _ = repo.Find()
+		_, _ = repo.Find(context.Background(), ProductCriteria{})
 	}
 }
 
@@ -367,13 +1255,13 @@ func BenchmarkProductRepository_GetMetrics(b *testing.B) {
 			Name:       "Product" + string(rune(i)),
 			Sku:        i,
 			Categories: []string{"Cat1", "Cat2"},
-			Price:      i * 10,
+			Price:      product_valueobject.MustBRL(int64(i * 10)),
 		}
-		_ = repo.Add(product)
+		_ = repo.Add(context.Background(), product)
 	}
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_ = repo.GetMetrics()
+		_ = repo.GetMetrics(context.Background())
 	}
 }
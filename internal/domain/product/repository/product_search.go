@@ -0,0 +1,120 @@
+package product_repository
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+
+	product_entity "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/entity"
+)
+
+// Pesos da busca textual: termos no nome valem mais que termos nas categorias.
+// Equivalem aos pesos 'A' e 'B' do tsvector no Postgres.
+const (
+	searchNameWeight     = 1.0
+	searchCategoryWeight = 0.4
+)
+
+// Tokenize quebra o texto em termos de busca: minúsculos, sem acentos e
+// separados por qualquer caractere que não seja letra ou número.
+// "Eletrônicos & Informática" vira ["eletronicos", "informatica"].
+func Tokenize(text string) []string {
+	var (
+		tokens  []string
+		current strings.Builder
+	)
+
+	for _, r := range norm.NFD.String(text) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// Acentos decompostos pelo NFD são descartados
+		case unicode.IsLetter(r) || unicode.IsNumber(r):
+			current.WriteRune(unicode.ToLower(r))
+		case current.Len() > 0:
+			tokens = append(tokens, current.String())
+			current.Reset()
+		}
+	}
+	if current.Len() > 0 {
+		tokens = append(tokens, current.String())
+	}
+
+	return tokens
+}
+
+// SearchProducts filtra e ordena os produtos pela relevância em relação aos termos.
+// Todos os termos precisam casar (como prefixo) com o nome ou com uma categoria.
+// Produtos excluídos são ignorados; limit zero retorna todos os resultados.
+func SearchProducts(products []product_entity.Product, terms []string, limit int) []product_entity.Product {
+	type scored struct {
+		product product_entity.Product
+		score   float64
+	}
+
+	var results []scored
+	for _, product := range products {
+		if product.IsDeleted() {
+			continue
+		}
+		if score, ok := searchScore(product, terms); ok {
+			results = append(results, scored{product, score})
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].score != results[j].score {
+			return results[i].score > results[j].score
+		}
+		return results[i].product.Name < results[j].product.Name
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+
+	found := make([]product_entity.Product, len(results))
+	for i, result := range results {
+		found[i] = result.product
+	}
+
+	return found
+}
+
+// searchScore soma os pesos dos termos encontrados no nome e nas categorias
+func searchScore(product product_entity.Product, terms []string) (float64, bool) {
+	if len(terms) == 0 {
+		return 0, false
+	}
+
+	nameTokens := Tokenize(product.Name)
+	categoryTokens := Tokenize(strings.Join(product.Categories, " "))
+
+	var score float64
+	for _, term := range terms {
+		matched := false
+		if hasPrefixToken(nameTokens, term) {
+			score += searchNameWeight
+			matched = true
+		}
+		if hasPrefixToken(categoryTokens, term) {
+			score += searchCategoryWeight
+			matched = true
+		}
+		if !matched {
+			return 0, false
+		}
+	}
+
+	return score, true
+}
+
+func hasPrefixToken(tokens []string, term string) bool {
+	for _, token := range tokens {
+		if strings.HasPrefix(token, term) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package product_repository
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	product_entity "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/entity"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{text: "Eletrônicos", want: []string{"eletronicos"}},
+		{text: "Eletrônicos & Informática", want: []string{"eletronicos", "informatica"}},
+		{text: "  Notebook/15\" i7-1165G7 ", want: []string{"notebook", "15", "i7", "1165g7"}},
+		{text: "AÇÃO Câmera", want: []string{"acao", "camera"}},
+		{text: "!!!", want: nil},
+		{text: "", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := Tokenize(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Tokenize(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchProducts(t *testing.T) {
+	deletedAt := time.Now()
+	products := []product_entity.Product{
+		{Name: "Notebook Gamer", Categories: []string{"Eletrônicos", "Computadores"}},
+		{Name: "Mochila", Categories: []string{"Notebooks", "Acessórios"}},
+		{Name: "Fone Bluetooth", Categories: []string{"Eletrônicos"}},
+		{Name: "Notebook Antigo", Categories: []string{"Eletrônicos"}, DeletedAt: &deletedAt},
+	}
+
+	names := func(found []product_entity.Product) []string {
+		result := make([]string, 0, len(found))
+		for _, p := range found {
+			result = append(result, p.Name)
+		}
+		return result
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		limit int
+		want  []string
+	}{
+		{name: "name match ranks above category match", query: "notebook", want: []string{"Notebook Gamer", "Mochila"}},
+		{name: "accent-insensitive", query: "ELETRONICOS", want: []string{"Fone Bluetooth", "Notebook Gamer"}},
+		{name: "prefix match", query: "blue", want: []string{"Fone Bluetooth"}},
+		{name: "all terms must match", query: "notebook computadores", want: []string{"Notebook Gamer"}},
+		{name: "limit", query: "eletronicos", limit: 1, want: []string{"Fone Bluetooth"}},
+		{name: "no terms", query: "???", want: []string{}},
+		{name: "no match", query: "geladeira", want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := names(SearchProducts(products, Tokenize(tt.query), tt.limit))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SearchProducts(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package product_valueobject
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// DefaultCurrency é a moeda usada quando nenhuma é informada
+const DefaultCurrency = "BRL"
+
+// currencyExponents mapeia os códigos ISO-4217 aceitos para o número de casas decimais da moeda
+var currencyExponents = map[string]int{
+	"ARS": 2,
+	"BRL": 2,
+	"CAD": 2,
+	"CHF": 2,
+	"CLP": 0,
+	"CNY": 2,
+	"COP": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"JPY": 0,
+	"MXN": 2,
+	"PYG": 0,
+	"USD": 2,
+	"UYU": 2,
+}
+
+var (
+	ErrInvalidCurrency  = errors.New("invalid currency")
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	ErrAmountOverflow   = errors.New("amount overflow")
+)
+
+// Money representa um valor monetário em unidades menores (ex.: centavos) de uma moeda ISO-4217
+type Money struct {
+	amount   int64
+	currency string
+}
+
+// NewMoney cria um Money validando o código da moeda
+func NewMoney(amount int64, currency string) (Money, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if _, ok := currencyExponents[currency]; !ok {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidCurrency, currency)
+	}
+
+	return Money{amount: amount, currency: currency}, nil
+}
+
+// MustBRL cria um valor em reais (centavos) e entra em pânico se a moeda for recusada.
+// Serve para valores fixos, como os de testes e exemplos
+func MustBRL(amount int64) Money {
+	m, err := NewMoney(amount, DefaultCurrency)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// IsSupportedCurrency indica se o código ISO-4217 é aceito
+func IsSupportedCurrency(currency string) bool {
+	_, ok := currencyExponents[strings.ToUpper(strings.TrimSpace(currency))]
+	return ok
+}
+
+// Amount retorna o valor em unidades menores da moeda
+func (m Money) Amount() int64 {
+	return m.amount
+}
+
+// Currency retorna o código ISO-4217 da moeda
+func (m Money) Currency() string {
+	return m.currency
+}
+
+// IsZero indica se o valor é zero
+func (m Money) IsZero() bool {
+	return m.amount == 0
+}
+
+// IsPositive indica se o valor é maior que zero
+func (m Money) IsPositive() bool {
+	return m.amount > 0
+}
+
+// Equals compara valor e moeda
+func (m Money) Equals(other Money) bool {
+	return m.amount == other.amount && m.currency == other.currency
+}
+
+// Add soma dois valores da mesma moeda
+func (m Money) Add(other Money) (Money, error) {
+	if m.currency != other.currency {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.currency, other.currency)
+	}
+
+	sum := m.amount + other.amount
+	if (other.amount > 0 && sum < m.amount) || (other.amount < 0 && sum > m.amount) {
+		return Money{}, ErrAmountOverflow
+	}
+
+	return Money{amount: sum, currency: m.currency}, nil
+}
+
+// Subtract subtrai dois valores da mesma moeda
+func (m Money) Subtract(other Money) (Money, error) {
+	if other.amount == math.MinInt64 {
+		return Money{}, ErrAmountOverflow
+	}
+
+	return m.Add(Money{amount: -other.amount, currency: other.currency})
+}
+
+// Multiply multiplica o valor por um fator inteiro (ex.: quantidade)
+func (m Money) Multiply(factor int64) (Money, error) {
+	if m.amount == 0 || factor == 0 {
+		return Money{amount: 0, currency: m.currency}, nil
+	}
+
+	product := m.amount * factor
+	if product/factor != m.amount || (m.amount == -1 && factor == math.MinInt64) || (factor == -1 && m.amount == math.MinInt64) {
+		return Money{}, ErrAmountOverflow
+	}
+
+	return Money{amount: product, currency: m.currency}, nil
+}
+
+// String formata o valor com o código da moeda e as casas decimais corretas, ex.: "BRL 35.00"
+func (m Money) String() string {
+	exponent := currencyExponents[m.currency]
+
+	sign := ""
+	amount := m.amount
+	if amount < 0 {
+		sign = "-"
+	}
+
+	// Usar uint64 evita overflow ao negar math.MinInt64
+	abs := uint64(amount)
+	if amount < 0 {
+		abs = uint64(-(amount + 1)) + 1
+	}
+
+	if exponent == 0 {
+		return fmt.Sprintf("%s %s%d", m.currency, sign, abs)
+	}
+
+	divisor := uint64(math.Pow10(exponent))
+	return fmt.Sprintf("%s %s%d.%0*d", m.currency, sign, abs/divisor, exponent, abs%divisor)
+}
+
+// moneyJSON é a representação JSON de Money
+type moneyJSON struct {
+	Amount    int64  `json:"amount" example:"350000"`
+	Currency  string `json:"currency" example:"BRL"`
+	Formatted string `json:"formatted,omitempty" example:"BRL 3500.00"`
+}
+
+// MarshalJSON renderiza o valor em unidades menores, a moeda e o valor formatado
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyJSON{
+		Amount:    m.amount,
+		Currency:  m.currency,
+		Formatted: m.String(),
+	})
+}
+
+// UnmarshalJSON lê amount e currency; o campo formatted é ignorado
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var raw moneyJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	money, err := NewMoney(raw.Amount, raw.Currency)
+	if err != nil {
+		return err
+	}
+
+	*m = money
+	return nil
+}
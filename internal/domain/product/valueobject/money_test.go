@@ -0,0 +1,210 @@
+package product_valueobject
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+func mustMoney(t *testing.T, amount int64, currency string) Money {
+	t.Helper()
+	m, err := NewMoney(amount, currency)
+	if err != nil {
+		t.Fatalf("NewMoney(%d, %q) unexpected error = %v", amount, currency, err)
+	}
+	return m
+}
+
+func TestNewMoney(t *testing.T) {
+	tests := []struct {
+		name         string
+		amount       int64
+		currency     string
+		wantErr      bool
+		wantCurrency string
+	}{
+		{name: "valid BRL", amount: 350000, currency: "BRL", wantCurrency: "BRL"},
+		{name: "lowercase currency is normalized", amount: 100, currency: "usd", wantCurrency: "USD"},
+		{name: "currency with spaces", amount: 100, currency: " EUR ", wantCurrency: "EUR"},
+		{name: "unknown currency", amount: 100, currency: "XYZ", wantErr: true},
+		{name: "empty currency", amount: 100, currency: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewMoney(tt.amount, tt.currency)
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidCurrency) {
+					t.Errorf("NewMoney() error = %v, want ErrInvalidCurrency", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("NewMoney() unexpected error = %v", err)
+			}
+			if m.Amount() != tt.amount {
+				t.Errorf("Amount() = %d, want %d", m.Amount(), tt.amount)
+			}
+			if m.Currency() != tt.wantCurrency {
+				t.Errorf("Currency() = %s, want %s", m.Currency(), tt.wantCurrency)
+			}
+		})
+	}
+}
+
+func TestMoney_Arithmetic(t *testing.T) {
+	brl := mustMoney(t, 1050, "BRL")
+	usd := mustMoney(t, 1000, "USD")
+
+	t.Run("add same currency", func(t *testing.T) {
+		sum, err := brl.Add(mustMoney(t, 950, "BRL"))
+		if err != nil {
+			t.Fatalf("Add() unexpected error = %v", err)
+		}
+		if !sum.Equals(mustMoney(t, 2000, "BRL")) {
+			t.Errorf("Add() = %v, want BRL 20.00", sum)
+		}
+	})
+
+	t.Run("add different currencies", func(t *testing.T) {
+		if _, err := brl.Add(usd); !errors.Is(err, ErrCurrencyMismatch) {
+			t.Errorf("Add() error = %v, want ErrCurrencyMismatch", err)
+		}
+	})
+
+	t.Run("subtract", func(t *testing.T) {
+		diff, err := brl.Subtract(mustMoney(t, 2000, "BRL"))
+		if err != nil {
+			t.Fatalf("Subtract() unexpected error = %v", err)
+		}
+		if diff.Amount() != -950 {
+			t.Errorf("Subtract() = %d, want -950", diff.Amount())
+		}
+	})
+
+	t.Run("multiply", func(t *testing.T) {
+		total, err := brl.Multiply(3)
+		if err != nil {
+			t.Fatalf("Multiply() unexpected error = %v", err)
+		}
+		if total.Amount() != 3150 || total.Currency() != "BRL" {
+			t.Errorf("Multiply() = %v, want BRL 31.50", total)
+		}
+	})
+
+	t.Run("add overflow", func(t *testing.T) {
+		if _, err := mustMoney(t, math.MaxInt64, "BRL").Add(mustMoney(t, 1, "BRL")); !errors.Is(err, ErrAmountOverflow) {
+			t.Errorf("Add() error = %v, want ErrAmountOverflow", err)
+		}
+	})
+
+	t.Run("subtract overflow", func(t *testing.T) {
+		if _, err := mustMoney(t, 0, "BRL").Subtract(mustMoney(t, math.MinInt64, "BRL")); !errors.Is(err, ErrAmountOverflow) {
+			t.Errorf("Subtract() error = %v, want ErrAmountOverflow", err)
+		}
+	})
+
+	t.Run("multiply overflow", func(t *testing.T) {
+		if _, err := mustMoney(t, math.MaxInt64/2+1, "BRL").Multiply(2); !errors.Is(err, ErrAmountOverflow) {
+			t.Errorf("Multiply() error = %v, want ErrAmountOverflow", err)
+		}
+		if _, err := mustMoney(t, math.MinInt64, "BRL").Multiply(-1); !errors.Is(err, ErrAmountOverflow) {
+			t.Errorf("Multiply() error = %v, want ErrAmountOverflow", err)
+		}
+	})
+}
+
+func TestMoney_Predicates(t *testing.T) {
+	if !mustMoney(t, 0, "BRL").IsZero() {
+		t.Error("IsZero() = false for zero amount")
+	}
+	if mustMoney(t, 0, "BRL").IsPositive() {
+		t.Error("IsPositive() = true for zero amount")
+	}
+	if !mustMoney(t, 1, "BRL").IsPositive() {
+		t.Error("IsPositive() = false for positive amount")
+	}
+	if mustMoney(t, 100, "BRL").Equals(mustMoney(t, 100, "USD")) {
+		t.Error("Equals() = true for different currencies")
+	}
+}
+
+func TestMoney_String(t *testing.T) {
+	tests := []struct {
+		amount   int64
+		currency string
+		want     string
+	}{
+		{amount: 350000, currency: "BRL", want: "BRL 3500.00"},
+		{amount: 5, currency: "USD", want: "USD 0.05"},
+		{amount: -1050, currency: "EUR", want: "EUR -10.50"},
+		{amount: 1500, currency: "JPY", want: "JPY 1500"},
+		{amount: math.MinInt64, currency: "BRL", want: "BRL -92233720368547758.08"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := mustMoney(t, tt.amount, tt.currency).String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoney_JSON(t *testing.T) {
+	t.Run("marshal", func(t *testing.T) {
+		data, err := json.Marshal(mustMoney(t, 350000, "BRL"))
+		if err != nil {
+			t.Fatalf("Marshal() unexpected error = %v", err)
+		}
+
+		want := `{"amount":350000,"currency":"BRL","formatted":"BRL 3500.00"}`
+		if string(data) != want {
+			t.Errorf("Marshal() = %s, want %s", data, want)
+		}
+	})
+
+	t.Run("unmarshal", func(t *testing.T) {
+		var m Money
+		if err := json.Unmarshal([]byte(`{"amount":1999,"currency":"usd"}`), &m); err != nil {
+			t.Fatalf("Unmarshal() unexpected error = %v", err)
+		}
+		if !m.Equals(mustMoney(t, 1999, "USD")) {
+			t.Errorf("Unmarshal() = %v, want USD 19.99", m)
+		}
+	})
+
+	t.Run("unmarshal invalid currency", func(t *testing.T) {
+		var m Money
+		if err := json.Unmarshal([]byte(`{"amount":1999,"currency":"XYZ"}`), &m); !errors.Is(err, ErrInvalidCurrency) {
+			t.Errorf("Unmarshal() error = %v, want ErrInvalidCurrency", err)
+		}
+	})
+}
+
+func TestIsSupportedCurrency(t *testing.T) {
+	if !IsSupportedCurrency("brl") {
+		t.Error("IsSupportedCurrency(brl) = false")
+	}
+	if IsSupportedCurrency("XYZ") {
+		t.Error("IsSupportedCurrency(XYZ) = true")
+	}
+}
+
+func TestMustBRL(t *testing.T) {
+	if m := MustBRL(1050); m.Amount() != 1050 || m.Currency() != "BRL" {
+		t.Errorf("MustBRL(1050) = %d %s, want 1050 BRL", m.Amount(), m.Currency())
+	}
+}
+
+func BenchmarkMoney_Add(b *testing.B) {
+	m, _ := NewMoney(1050, "BRL")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = m.Add(m)
+	}
+}
@@ -0,0 +1,45 @@
+package webhook_entity
+
+import (
+	"time"
+
+	shared_identity "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/identity"
+)
+
+// Delivery registra uma tentativa de entrega de um evento a um webhook
+type Delivery struct {
+	ID        string `json:"id"`
+	WebhookID string `json:"webhook_id"`
+	EventID   string `json:"event_id"`
+	EventName string `json:"event_name"`
+	Attempt   int    `json:"attempt"`
+	// StatusCode é 0 quando a requisição não obteve resposta (timeout, conexão recusada)
+	StatusCode  int       `json:"status_code,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	DurationMs  int64     `json:"duration_ms"`
+	Success     bool      `json:"success"`
+	DeliveredAt time.Time `json:"delivered_at"`
+}
+
+// NewDelivery cria o registro de uma tentativa ainda sem resultado
+func NewDelivery(webhookID, eventID, eventName string, attempt int) *Delivery {
+	return &Delivery{
+		ID:          shared_identity.NewUUID(),
+		WebhookID:   webhookID,
+		EventID:     eventID,
+		EventName:   eventName,
+		Attempt:     attempt,
+		DeliveredAt: time.Now().UTC(),
+	}
+}
+
+// Complete registra o resultado da tentativa: sucesso é qualquer resposta 2xx
+func (d *Delivery) Complete(statusCode int, err error, duration time.Duration) {
+	d.StatusCode = statusCode
+	d.DurationMs = duration.Milliseconds()
+	d.Success = err == nil && statusCode >= 200 && statusCode < 300
+
+	if err != nil {
+		d.Error = err.Error()
+	}
+}
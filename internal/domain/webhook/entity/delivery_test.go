@@ -0,0 +1,36 @@
+package webhook_entity
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDelivery_Complete(t *testing.T) {
+	tests := []struct {
+		name        string
+		statusCode  int
+		err         error
+		wantSuccess bool
+		wantError   string
+	}{
+		{name: "2xx response", statusCode: 204, wantSuccess: true},
+		{name: "5xx response", statusCode: 503},
+		{name: "redirect is not followed", statusCode: 301},
+		{name: "connection error", err: errors.New("connection refused"), wantError: "connection refused"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delivery := NewDelivery("webhook-1", "event-1", "product.created", 2)
+			delivery.Complete(tt.statusCode, tt.err, 1500*time.Millisecond)
+
+			if delivery.Success != tt.wantSuccess || delivery.Error != tt.wantError || delivery.StatusCode != tt.statusCode {
+				t.Errorf("Complete() = %+v", delivery)
+			}
+			if delivery.DurationMs != 1500 || delivery.Attempt != 2 || delivery.ID == "" || delivery.DeliveredAt.IsZero() {
+				t.Errorf("NewDelivery() = %+v", delivery)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package webhook_entity
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"time"
+
+	shared_events "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/events"
+	shared_identity "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/identity"
+	shared_validation "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/validation"
+)
+
+// MinSecretLength é o tamanho mínimo do segredo usado para assinar as entregas
+const MinSecretLength = 16
+
+// Webhook é a inscrição de um parceiro para receber eventos de domínio por HTTP
+type Webhook struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+	// Padrões de eventos, como em EventDispatcher.Register ("product.*", "product.created")
+	Events []string `json:"events"`
+	// Secret assina as entregas (HMAC-SHA256) e só é exibido na criação
+	Secret    string    `json:"-"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewWebhook cria uma inscrição ativa; sem segredo informado, um aleatório é gerado
+func NewWebhook(rawURL string, events []string, secret string) (*Webhook, error) {
+	if secret == "" {
+		secret = NewSecret()
+	}
+
+	if ok, err := Validate(rawURL, events, secret); !ok {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	return &Webhook{
+		ID:        shared_identity.NewUUID(),
+		URL:       rawURL,
+		Events:    events,
+		Secret:    secret,
+		Active:    true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// Update substitui os dados da inscrição após validá-los; segredo vazio mantém o atual
+func (w *Webhook) Update(rawURL string, events []string, secret string, active bool) error {
+	if secret == "" {
+		secret = w.Secret
+	}
+
+	if ok, err := Validate(rawURL, events, secret); !ok {
+		return err
+	}
+
+	w.URL = rawURL
+	w.Events = events
+	w.Secret = secret
+	w.Active = active
+	w.UpdatedAt = time.Now().UTC()
+
+	return nil
+}
+
+// Matches indica se a inscrição está ativa e algum dos seus padrões casa com o evento
+func (w *Webhook) Matches(eventName string) bool {
+	if !w.Active {
+		return false
+	}
+
+	for _, pattern := range w.Events {
+		if shared_events.MatchPattern(pattern, eventName) {
+			return true
+		}
+	}
+	return false
+}
+
+func Validate(rawURL string, events []string, secret string) (bool, error) {
+	if err := validate(rawURL, events, secret).Err(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func validate(rawURL string, events []string, secret string) shared_validation.Errors {
+	var violations shared_validation.Errors
+
+	if rawURL == "" {
+		violations = append(violations, shared_validation.NewError("url", shared_validation.CodeRequired, "url is required"))
+	} else if parsed, err := url.Parse(rawURL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		violations = append(violations, shared_validation.NewError("url", shared_validation.CodeInvalid, "url must be an absolute http or https url"))
+	}
+
+	if len(events) == 0 {
+		violations = append(violations, shared_validation.NewError("events", shared_validation.CodeRequired, "events is required"))
+	}
+	// Só o primeiro padrão inválido é reportado
+	for _, pattern := range events {
+		if pattern == "" {
+			violations = append(violations, shared_validation.NewError("events", shared_validation.CodeInvalid, "event pattern must not be empty"))
+			break
+		}
+		if err := shared_events.ValidatePattern(pattern); err != nil {
+			violations = append(violations, shared_validation.NewError("events", shared_validation.CodeInvalid, fmt.Sprintf("invalid event pattern %q", pattern)))
+			break
+		}
+	}
+
+	if len(secret) < MinSecretLength {
+		violations = append(violations, shared_validation.NewError("secret", shared_validation.CodeTooShort, fmt.Sprintf("secret must have at least %d characters", MinSecretLength)))
+	}
+
+	return violations
+}
+
+// NewSecret gera um segredo aleatório de 32 bytes em hexadecimal
+func NewSecret() string {
+	var b [32]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("erro ao gerar segredo: %v", err))
+	}
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,107 @@
+package webhook_entity
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	shared_identity "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/identity"
+	shared_validation "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/validation"
+)
+
+const testSecret = "0123456789abcdef"
+
+func TestNewWebhook(t *testing.T) {
+	tests := []struct {
+		name           string
+		url            string
+		events         []string
+		secret         string
+		wantErr        bool
+		expectedErrMsg string
+	}{
+		{name: "valid webhook", url: "https://partner.example.com/hooks", events: []string{"product.*"}, secret: testSecret},
+		{name: "generated secret", url: "http://localhost:9000/hooks", events: []string{"product.created"}},
+		{name: "empty url", url: "", events: []string{"product.*"}, wantErr: true, expectedErrMsg: "url is required"},
+		{name: "relative url", url: "/hooks", events: []string{"product.*"}, wantErr: true, expectedErrMsg: "url must be an absolute http or https url"},
+		{name: "unsupported scheme", url: "ftp://partner.example.com", events: []string{"product.*"}, wantErr: true, expectedErrMsg: "url must be an absolute http or https url"},
+		{name: "no events", url: "https://partner.example.com/hooks", wantErr: true, expectedErrMsg: "events is required"},
+		{name: "empty pattern", url: "https://partner.example.com/hooks", events: []string{""}, wantErr: true, expectedErrMsg: "event pattern must not be empty"},
+		{name: "malformed pattern", url: "https://partner.example.com/hooks", events: []string{"product.["}, wantErr: true, expectedErrMsg: `invalid event pattern "product.["`},
+		{name: "short secret", url: "https://partner.example.com/hooks", events: []string{"product.*"}, secret: "short", wantErr: true, expectedErrMsg: "secret must have at least 16 characters"},
+		{name: "every violation is reported", url: "/hooks", events: []string{"product.*", "product.["}, secret: "short", wantErr: true,
+			expectedErrMsg: `url must be an absolute http or https url; invalid event pattern "product.["; secret must have at least 16 characters`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			webhook, err := NewWebhook(tt.url, tt.events, tt.secret)
+
+			if tt.wantErr {
+				if err == nil || err.Error() != tt.expectedErrMsg {
+					t.Fatalf("NewWebhook() error = %v, want %q", err, tt.expectedErrMsg)
+				}
+				if !errors.Is(err, shared_validation.ErrValidation) {
+					t.Errorf("NewWebhook() error = %v, want a validation error", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("NewWebhook() unexpected error = %v", err)
+			}
+			if !shared_identity.IsValidUUID(webhook.ID) || !webhook.Active || webhook.CreatedAt.IsZero() {
+				t.Errorf("NewWebhook() = %+v", webhook)
+			}
+			if tt.secret != "" && webhook.Secret != tt.secret {
+				t.Errorf("Secret = %q, want %q", webhook.Secret, tt.secret)
+			}
+			if len(webhook.Secret) < MinSecretLength {
+				t.Errorf("Secret = %q, too short", webhook.Secret)
+			}
+		})
+	}
+}
+
+func TestWebhook_Update(t *testing.T) {
+	webhook, _ := NewWebhook("https://partner.example.com/hooks", []string{"product.*"}, testSecret)
+
+	if err := webhook.Update("https://partner.example.com/v2", []string{"product.created"}, "", false); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if webhook.URL != "https://partner.example.com/v2" || webhook.Events[0] != "product.created" || webhook.Active || webhook.Secret != testSecret {
+		t.Errorf("Update() = %+v", webhook)
+	}
+
+	rotated := strings.Repeat("x", MinSecretLength)
+	if err := webhook.Update(webhook.URL, webhook.Events, rotated, true); err != nil || webhook.Secret != rotated {
+		t.Errorf("Update() with a new secret: err = %v, secret = %q", err, webhook.Secret)
+	}
+
+	if err := webhook.Update("", webhook.Events, "", true); err == nil || webhook.URL != "https://partner.example.com/v2" {
+		t.Errorf("Update() with an invalid url should fail and keep the webhook unchanged, got %v", err)
+	}
+}
+
+func TestWebhook_Matches(t *testing.T) {
+	webhook, _ := NewWebhook("https://partner.example.com/hooks", []string{"product.created", "product.deleted"}, testSecret)
+
+	if !webhook.Matches("product.created") || !webhook.Matches("product.deleted") {
+		t.Error("Matches() should accept the subscribed events")
+	}
+	if webhook.Matches("product.updated") {
+		t.Error("Matches() should reject events outside the subscription")
+	}
+
+	webhook.Active = false
+	if webhook.Matches("product.created") {
+		t.Error("Matches() should reject events for an inactive webhook")
+	}
+}
+
+func TestNewSecret(t *testing.T) {
+	first, second := NewSecret(), NewSecret()
+	if len(first) != 64 || first == second {
+		t.Errorf("NewSecret() = %q, %q", first, second)
+	}
+}
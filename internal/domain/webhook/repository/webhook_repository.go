@@ -0,0 +1,153 @@
+package webhook_repository
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	webhook_entity "github.com/williamkoller/golang-domain-driven-design/internal/domain/webhook/entity"
+)
+
+var (
+	ErrWebhookNotFound      = errors.New("webhook not found")
+	ErrWebhookAlreadyExists = errors.New("webhook already exists")
+	ErrUnavailable          = errors.New("webhook storage unavailable")
+)
+
+// MaxDeliveriesPerWebhook é quantas entregas o repositório in-memory guarda por webhook
+const MaxDeliveriesPerWebhook = 100
+
+// IWebhookRepository persiste as inscrições de webhooks e o log das suas entregas
+type IWebhookRepository interface {
+	Add(ctx context.Context, webhook webhook_entity.Webhook) error
+	// FindAll retorna as inscrições da mais antiga para a mais recente
+	FindAll(ctx context.Context) ([]webhook_entity.Webhook, error)
+	FindByID(ctx context.Context, id string) (webhook_entity.Webhook, error)
+	Update(ctx context.Context, webhook webhook_entity.Webhook) error
+	// Delete remove a inscrição e o seu log de entregas
+	Delete(ctx context.Context, id string) error
+	AddDelivery(ctx context.Context, delivery webhook_entity.Delivery) error
+	// Deliveries retorna as últimas entregas do webhook, da mais recente para a mais antiga
+	Deliveries(ctx context.Context, webhookID string, limit int) ([]webhook_entity.Delivery, error)
+}
+
+type WebhookRepository struct {
+	data       map[string]webhook_entity.Webhook
+	deliveries map[string][]webhook_entity.Delivery
+	mu         sync.RWMutex
+}
+
+func NewRepository() *WebhookRepository {
+	return &WebhookRepository{
+		data:       make(map[string]webhook_entity.Webhook),
+		deliveries: make(map[string][]webhook_entity.Delivery),
+	}
+}
+
+func (r *WebhookRepository) Add(ctx context.Context, webhook webhook_entity.Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.data[webhook.ID]; exists {
+		return ErrWebhookAlreadyExists
+	}
+	r.data[webhook.ID] = webhook
+
+	return nil
+}
+
+func (r *WebhookRepository) FindAll(ctx context.Context) ([]webhook_entity.Webhook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	webhooks := make([]webhook_entity.Webhook, 0, len(r.data))
+	for _, webhook := range r.data {
+		webhooks = append(webhooks, webhook)
+	}
+
+	sort.Slice(webhooks, func(i, j int) bool {
+		if !webhooks[i].CreatedAt.Equal(webhooks[j].CreatedAt) {
+			return webhooks[i].CreatedAt.Before(webhooks[j].CreatedAt)
+		}
+		return webhooks[i].ID < webhooks[j].ID
+	})
+
+	return webhooks, nil
+}
+
+func (r *WebhookRepository) FindByID(ctx context.Context, id string) (webhook_entity.Webhook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	webhook, exists := r.data[id]
+	if !exists {
+		return webhook_entity.Webhook{}, ErrWebhookNotFound
+	}
+
+	return webhook, nil
+}
+
+func (r *WebhookRepository) Update(ctx context.Context, webhook webhook_entity.Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.data[webhook.ID]; !exists {
+		return ErrWebhookNotFound
+	}
+	r.data[webhook.ID] = webhook
+
+	return nil
+}
+
+func (r *WebhookRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.data[id]; !exists {
+		return ErrWebhookNotFound
+	}
+	delete(r.data, id)
+	delete(r.deliveries, id)
+
+	return nil
+}
+
+// AddDelivery registra a entrega; ao passar de MaxDeliveriesPerWebhook, a mais antiga é descartada
+func (r *WebhookRepository) AddDelivery(ctx context.Context, delivery webhook_entity.Delivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.data[delivery.WebhookID]; !exists {
+		return ErrWebhookNotFound
+	}
+
+	deliveries := append(r.deliveries[delivery.WebhookID], delivery)
+	if len(deliveries) > MaxDeliveriesPerWebhook {
+		deliveries = deliveries[len(deliveries)-MaxDeliveriesPerWebhook:]
+	}
+	r.deliveries[delivery.WebhookID] = deliveries
+
+	return nil
+}
+
+func (r *WebhookRepository) Deliveries(ctx context.Context, webhookID string, limit int) ([]webhook_entity.Delivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, exists := r.data[webhookID]; !exists {
+		return nil, ErrWebhookNotFound
+	}
+
+	stored := r.deliveries[webhookID]
+	if limit <= 0 || limit > len(stored) {
+		limit = len(stored)
+	}
+
+	deliveries := make([]webhook_entity.Delivery, 0, limit)
+	for i := len(stored) - 1; i >= 0 && len(deliveries) < limit; i-- {
+		deliveries = append(deliveries, stored[i])
+	}
+
+	return deliveries, nil
+}
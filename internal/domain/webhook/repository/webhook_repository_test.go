@@ -0,0 +1,88 @@
+package webhook_repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	webhook_entity "github.com/williamkoller/golang-domain-driven-design/internal/domain/webhook/entity"
+)
+
+func newTestWebhook(t *testing.T) webhook_entity.Webhook {
+	t.Helper()
+
+	webhook, err := webhook_entity.NewWebhook("https://partner.example.com/hooks", []string{"product.*"}, "0123456789abcdef")
+	if err != nil {
+		t.Fatalf("NewWebhook() error = %v", err)
+	}
+	return *webhook
+}
+
+func TestWebhookRepository_CRUD(t *testing.T) {
+	repo := NewRepository()
+	webhook := newTestWebhook(t)
+
+	if err := repo.Add(context.Background(), webhook); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := repo.Add(context.Background(), webhook); !errors.Is(err, ErrWebhookAlreadyExists) {
+		t.Errorf("Add() duplicate error = %v, want ErrWebhookAlreadyExists", err)
+	}
+
+	found, err := repo.FindByID(context.Background(), webhook.ID)
+	if err != nil || found.URL != webhook.URL || found.Secret != webhook.Secret {
+		t.Fatalf("FindByID() = %+v, %v", found, err)
+	}
+
+	found.Active = false
+	if err := repo.Update(context.Background(), found); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if all, _ := repo.FindAll(context.Background()); len(all) != 1 || all[0].Active {
+		t.Errorf("FindAll() = %+v", all)
+	}
+
+	if err := repo.Delete(context.Background(), webhook.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := repo.FindByID(context.Background(), webhook.ID); !errors.Is(err, ErrWebhookNotFound) {
+		t.Errorf("FindByID() after delete error = %v", err)
+	}
+	if err := repo.Update(context.Background(), webhook); !errors.Is(err, ErrWebhookNotFound) {
+		t.Errorf("Update() missing error = %v", err)
+	}
+	if err := repo.Delete(context.Background(), webhook.ID); !errors.Is(err, ErrWebhookNotFound) {
+		t.Errorf("Delete() missing error = %v", err)
+	}
+}
+
+func TestWebhookRepository_Deliveries(t *testing.T) {
+	repo := NewRepository()
+	webhook := newTestWebhook(t)
+	_ = repo.Add(context.Background(), webhook)
+
+	for attempt := 1; attempt <= MaxDeliveriesPerWebhook+5; attempt++ {
+		if err := repo.AddDelivery(context.Background(), *webhook_entity.NewDelivery(webhook.ID, "event-1", "product.created", attempt)); err != nil {
+			t.Fatalf("AddDelivery() error = %v", err)
+		}
+	}
+
+	latest, err := repo.Deliveries(context.Background(), webhook.ID, 3)
+	if err != nil || len(latest) != 3 || latest[0].Attempt != MaxDeliveriesPerWebhook+5 || latest[2].Attempt != MaxDeliveriesPerWebhook+3 {
+		t.Fatalf("Deliveries(3) = %+v, %v", latest, err)
+	}
+
+	all, _ := repo.Deliveries(context.Background(), webhook.ID, 0)
+	if len(all) != MaxDeliveriesPerWebhook || all[len(all)-1].Attempt != 6 {
+		t.Errorf("Deliveries(0) kept %d entries, oldest attempt %d", len(all), all[len(all)-1].Attempt)
+	}
+
+	if err := repo.AddDelivery(context.Background(), *webhook_entity.NewDelivery("missing", "event-1", "product.created", 1)); !errors.Is(err, ErrWebhookNotFound) {
+		t.Errorf("AddDelivery() for a missing webhook error = %v", err)
+	}
+
+	_ = repo.Delete(context.Background(), webhook.ID)
+	if _, err := repo.Deliveries(context.Background(), webhook.ID, 10); !errors.Is(err, ErrWebhookNotFound) {
+		t.Errorf("Deliveries() after delete error = %v", err)
+	}
+}
@@ -0,0 +1,323 @@
+package product_handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	category_entity "github.com/williamkoller/golang-domain-driven-design/internal/domain/category/entity"
+	category_repository "github.com/williamkoller/golang-domain-driven-design/internal/domain/category/repository"
+	product_repository "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/repository"
+	http_middleware "github.com/williamkoller/golang-domain-driven-design/internal/infra/http/middleware"
+	shared_identity "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/identity"
+)
+
+// Erros do domínio de categorias
+func init() {
+	http_middleware.RegisterErrors(
+		http_middleware.MapInvalid(category_repository.ErrParentNotFound),
+		http_middleware.MapInvalid(category_repository.ErrCategoryCycle),
+		http_middleware.MapInvalid(category_repository.ErrInvalidMerge),
+		http_middleware.MapNotFound(category_repository.ErrCategoryNotFound),
+		http_middleware.MapAlreadyExists(category_repository.ErrCategoryAlreadyExists),
+		http_middleware.MapConflict(category_repository.ErrCategoryInUse),
+		http_middleware.MapUnavailable(category_repository.ErrUnavailable),
+	)
+}
+
+type CategoryHandler struct {
+	repo     category_repository.ICategoryRepository
+	products product_repository.IProductRepository
+}
+
+func NewCategoryHandler(repo category_repository.ICategoryRepository, products product_repository.IProductRepository) *CategoryHandler {
+	return &CategoryHandler{repo, products}
+}
+
+// CategoryInput representa os dados de entrada para criar ou substituir uma categoria
+type CategoryInput struct {
+	Name string `json:"name" binding:"required" example:"Eletrônicos"`
+	// Slug das URLs; gerado a partir do nome na criação e mantido na alteração quando ausente
+	Slug        string `json:"slug,omitempty" example:"eletronicos"`
+	Description string `json:"description,omitempty" example:"Aparelhos eletrônicos e acessórios"`
+	// Categoria pai (UUID); ausente posiciona a categoria na raiz
+	ParentID string `json:"parent_id,omitempty" example:"5b1c3d5e-7f9a-4b2c-8d4e-6f8a0b2c4d6e"`
+}
+
+// MergeCategoryInput representa a categoria que recebe os produtos da categoria fundida
+type MergeCategoryInput struct {
+	// ID ou slug da categoria de destino
+	Into string `json:"into" binding:"required" example:"eletronicos"`
+}
+
+// CategoryListResponse representa as categorias cadastradas
+type CategoryListResponse struct {
+	Items []category_entity.Category `json:"items"`
+	Total int                        `json:"total" example:"7"`
+}
+
+// CategoryMergeResponse representa a categoria de destino de uma fusão
+type CategoryMergeResponse struct {
+	Category category_entity.Category `json:"category"`
+	// Produtos que passaram para a categoria de destino
+	Products int `json:"products" example:"12"`
+}
+
+// Create godoc
+//
+//	@Summary		Criar uma categoria
+//	@Description	Cadastra uma categoria, opcionalmente dentro de outra. Sem slug, ele é gerado a partir do nome.
+//	@Tags			categories
+//	@Accept			json
+//	@Produce		json
+//	@Param			category	body		CategoryInput	true	"Dados da categoria"
+//	@Success		201			{object}	category_entity.Category
+//	@Failure		400			{object}	http_middleware.ProblemDetails
+//	@Failure		409			{object}	http_middleware.ProblemDetails
+//	@Router			/categories [post]
+func (h *CategoryHandler) Create(c *gin.Context) {
+	var input CategoryInput
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(http_middleware.BindingError(err, &input))
+		return
+	}
+
+	category, err := category_entity.NewCategory(input.Name, input.Slug, input.Description, input.ParentID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.repo.Add(c.Request.Context(), *category); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, category)
+}
+
+// FindAll godoc
+//
+//	@Summary		Listar categorias
+//	@Description	Lista todas as categorias em ordem de nome; parent_id monta a hierarquia
+//	@Tags			categories
+//	@Produce		json
+//	@Success		200	{object}	CategoryListResponse
+//	@Failure		500	{object}	http_middleware.ProblemDetails
+//	@Router			/categories [get]
+func (h *CategoryHandler) FindAll(c *gin.Context) {
+	categories, err := h.repo.FindAll(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, CategoryListResponse{Items: categories, Total: len(categories)})
+}
+
+// FindOne godoc
+//
+//	@Summary		Buscar categoria
+//	@Description	Retorna uma categoria pelo ID ou pelo slug
+//	@Tags			categories
+//	@Produce		json
+//	@Param			id	path		string	true	"ID (UUID) ou slug da categoria"
+//	@Success		200	{object}	category_entity.Category
+//	@Failure		404	{object}	http_middleware.ProblemDetails
+//	@Router			/categories/{id} [get]
+func (h *CategoryHandler) FindOne(c *gin.Context) {
+	category, ok := h.find(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+// Update godoc
+//
+//	@Summary		Atualizar categoria
+//	@Description	Substitui o nome, a descrição e a categoria pai. Um novo nome é aplicado a todos os produtos da categoria, que têm a versão incrementada.
+//	@Tags			categories
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string			true	"ID (UUID) ou slug da categoria"
+//	@Param			category	body		CategoryInput	true	"Dados da categoria"
+//	@Success		200			{object}	category_entity.Category
+//	@Failure		400			{object}	http_middleware.ProblemDetails
+//	@Failure		404			{object}	http_middleware.ProblemDetails
+//	@Failure		409			{object}	http_middleware.ProblemDetails
+//	@Router			/categories/{id} [put]
+func (h *CategoryHandler) Update(c *gin.Context) {
+	var input CategoryInput
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(http_middleware.BindingError(err, &input))
+		return
+	}
+
+	category, ok := h.find(c)
+	if !ok {
+		return
+	}
+
+	if err := category.Update(input.Name, input.Slug, input.Description, input.ParentID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.repo.Update(c.Request.Context(), category); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+// Delete godoc
+//
+//	@Summary		Excluir categoria
+//	@Description	Remove uma categoria sem subcategorias nem produtos; para mover os produtos antes, use a fusão
+//	@Tags			categories
+//	@Param			id	path	string	true	"ID (UUID) ou slug da categoria"
+//	@Success		204
+//	@Failure		404	{object}	http_middleware.ProblemDetails
+//	@Failure		409	{object}	http_middleware.ProblemDetails
+//	@Router			/categories/{id} [delete]
+func (h *CategoryHandler) Delete(c *gin.Context) {
+	category, ok := h.find(c)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.Delete(c.Request.Context(), category.ID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Merge godoc
+//
+//	@Summary		Fundir categorias
+//	@Description	Move os produtos e as subcategorias da categoria para a categoria de destino e a remove; útil para corrigir categorias duplicadas, como "Eletronicos" e "Eletrônicos"
+//	@Tags			categories
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string				true	"ID (UUID) ou slug da categoria fundida"
+//	@Param			merge	body		MergeCategoryInput	true	"Categoria de destino"
+//	@Success		200		{object}	CategoryMergeResponse
+//	@Failure		400		{object}	http_middleware.ProblemDetails
+//	@Failure		404		{object}	http_middleware.ProblemDetails
+//	@Router			/categories/{id}/merge [post]
+func (h *CategoryHandler) Merge(c *gin.Context) {
+	var input MergeCategoryInput
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(http_middleware.BindingError(err, &input))
+		return
+	}
+
+	source, ok := h.find(c)
+	if !ok {
+		return
+	}
+	target, err := h.lookup(c.Request.Context(), input.Into)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	merged, err := h.repo.Merge(c.Request.Context(), source.ID, target.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, CategoryMergeResponse{Category: target, Products: merged})
+}
+
+// Products godoc
+//
+//	@Summary		Listar produtos da categoria
+//	@Description	Lista os produtos da categoria e das suas subcategorias, com os filtros, a ordenação e a paginação de GET /products
+//	@Tags			categories
+//	@Produce		json
+//	@Param			id				path		string	true	"ID (UUID) ou slug da categoria"
+//	@Param			descendants		query		bool	false	"Incluir os produtos das subcategorias (padrão true)"
+//	@Param			limit			query		int		false	"Itens por página (padrão 20, máximo 100)"
+//	@Param			after			query		string	false	"Cursor retornado em next_cursor"
+//	@Param			currency		query		string	false	"Moeda dos preços (BRL, USD...); obrigatória com min_price, max_price e sort por preço"
+//	@Param			min_price		query		int		false	"Preço mínimo (unidades menores da moeda)"
+//	@Param			max_price		query		int		false	"Preço máximo (unidades menores da moeda)"
+//	@Param			sort			query		string	false	"Ordenação: price, -price, name ou created_at (padrão: mais recentes primeiro)"
+//	@Param			include_deleted	query		bool	false	"Incluir produtos excluídos"
+//	@Success		200				{object}	ProductListResponse
+//	@Failure		400				{object}	http_middleware.ProblemDetails
+//	@Failure		404				{object}	http_middleware.ProblemDetails
+//	@Failure		503				{object}	http_middleware.ProblemDetails
+//	@Router			/categories/{id}/products [get]
+func (h *CategoryHandler) Products(c *gin.Context) {
+	criteria, err := parseProductCriteria(c)
+	if err != nil {
+		c.Error(http_middleware.BadRequest(err))
+		return
+	}
+
+	descendants := true
+	if value := c.Query("descendants"); value != "" {
+		if descendants, err = strconv.ParseBool(value); err != nil {
+			c.Error(http_middleware.BadRequest(errors.New("invalid descendants")))
+			return
+		}
+	}
+
+	category, ok := h.find(c)
+	if !ok {
+		return
+	}
+
+	criteria.Categories = []string{category.Name}
+	if descendants {
+		categories, err := h.repo.FindAll(c.Request.Context())
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		criteria.Categories = category_entity.Names(category_entity.Subtree(categories, category.ID))
+	}
+
+	page, err := h.products.Find(c.Request.Context(), criteria)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ProductListResponse{
+		Items:      page.Products,
+		NextCursor: page.NextCursor,
+		Total:      page.Total,
+	})
+}
+
+// find busca a categoria do parâmetro id, registrando o erro quando não encontrada
+func (h *CategoryHandler) find(c *gin.Context) (category_entity.Category, bool) {
+	category, err := h.lookup(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return category_entity.Category{}, false
+	}
+	return category, true
+}
+
+// lookup busca a categoria pelo ID, quando key é um UUID, ou pelo slug
+func (h *CategoryHandler) lookup(ctx context.Context, key string) (category_entity.Category, error) {
+	if shared_identity.IsValidUUID(key) {
+		return h.repo.FindByID(ctx, key)
+	}
+	return h.repo.FindBySlug(ctx, key)
+}
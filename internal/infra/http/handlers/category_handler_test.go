@@ -0,0 +1,257 @@
+package product_handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	category_entity "github.com/williamkoller/golang-domain-driven-design/internal/domain/category/entity"
+	category_repository "github.com/williamkoller/golang-domain-driven-design/internal/domain/category/repository"
+	product_entity "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/entity"
+	product_repository "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/repository"
+	product_valueobject "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/valueobject"
+	http_middleware "github.com/williamkoller/golang-domain-driven-design/internal/infra/http/middleware"
+)
+
+func setupCategoryTestRouter(handler *CategoryHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(http_middleware.ErrorHandler())
+	r.POST("/api/v1/categories", handler.Create)
+	r.GET("/api/v1/categories", handler.FindAll)
+	r.GET("/api/v1/categories/:id", handler.FindOne)
+	r.PUT("/api/v1/categories/:id", handler.Update)
+	r.DELETE("/api/v1/categories/:id", handler.Delete)
+	r.POST("/api/v1/categories/:id/merge", handler.Merge)
+	r.GET("/api/v1/categories/:id/products", handler.Products)
+	return r
+}
+
+func serveCategory(router *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func decodeCategory(t *testing.T, w *httptest.ResponseRecorder) category_entity.Category {
+	t.Helper()
+	var category category_entity.Category
+	if err := json.Unmarshal(w.Body.Bytes(), &category); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v (%s)", err, w.Body.String())
+	}
+	return category
+}
+
+// newCategoryTestRouter monta as categorias e os produtos in-memory, com a propagação entre eles
+func newCategoryTestRouter() (*gin.Engine, *product_repository.ProductRepository) {
+	products := product_repository.NewRepository()
+	return setupCategoryTestRouter(NewCategoryHandler(category_repository.NewRepository(products), products)), products
+}
+
+func TestCategoryHandler_Create(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedSlug   string
+		expectedFields []string
+	}{
+		{name: "generated slug", body: `{"name":"Eletrônicos","description":"Aparelhos"}`, expectedStatus: http.StatusCreated, expectedSlug: "eletronicos"},
+		{name: "informed slug", body: `{"name":"Eletrônicos","slug":"eletro"}`, expectedStatus: http.StatusCreated, expectedSlug: "eletro"},
+		{name: "missing name", body: `{"slug":"eletro"}`, expectedStatus: http.StatusBadRequest},
+		{name: "invalid slug", body: `{"name":"Eletrônicos","slug":"Eletro Nicos"}`, expectedStatus: http.StatusBadRequest, expectedFields: []string{"slug"}},
+		{name: "invalid parent", body: `{"name":"Celulares","parent_id":"eletronicos"}`, expectedStatus: http.StatusBadRequest, expectedFields: []string{"parent_id"}},
+		{name: "every violation", body: `{"name":" Celulares","slug":"Celulares","parent_id":"eletronicos"}`, expectedStatus: http.StatusBadRequest, expectedFields: []string{"name", "slug", "parent_id"}},
+		{name: "missing parent", body: `{"name":"Celulares","parent_id":"3f2b8c1e-4d5a-4b6c-8d7e-9f0a1b2c3d4e"}`, expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, _ := newCategoryTestRouter()
+			w := serveCategory(router, http.MethodPost, "/api/v1/categories", tt.body)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d. Body: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.expectedSlug != "" {
+				if category := decodeCategory(t, w); category.ID == "" || category.Slug != tt.expectedSlug {
+					t.Errorf("category = %+v, want slug %q", category, tt.expectedSlug)
+				}
+			}
+			if tt.expectedFields != nil {
+				assertProblemFields(t, w, tt.expectedFields...)
+			}
+		})
+	}
+}
+
+// assertProblemFields verifica que a resposta é um validation-error com os campos, na ordem
+func assertProblemFields(t *testing.T, w *httptest.ResponseRecorder, fields ...string) {
+	t.Helper()
+
+	var problem http_middleware.ProblemDetails
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("invalid problem %s: %v", w.Body.String(), err)
+	}
+	if problem.Type != "/problems/validation-error" || len(problem.Errors) != len(fields) {
+		t.Fatalf("problem = %+v, want validation errors on %v", problem, fields)
+	}
+	for i, field := range fields {
+		if problem.Errors[i].Field != field || problem.Errors[i].Code == "" {
+			t.Errorf("errors[%d] = %+v, want field %q", i, problem.Errors[i], field)
+		}
+	}
+}
+
+func TestCategoryHandler_Lifecycle(t *testing.T) {
+	router, products := newCategoryTestRouter()
+
+	electronics := decodeCategory(t, serveCategory(router, http.MethodPost, "/api/v1/categories", `{"name":"Eletronicos"}`))
+	phones := decodeCategory(t, serveCategory(router, http.MethodPost, "/api/v1/categories",
+		`{"name":"Celulares","parent_id":"`+electronics.ID+`"}`))
+	if phones.ParentID != electronics.ID {
+		t.Fatalf("ParentID = %q, want %q", phones.ParentID, electronics.ID)
+	}
+
+	if w := serveCategory(router, http.MethodPost, "/api/v1/categories", `{"name":"Eletronicos"}`); w.Code != http.StatusConflict {
+		t.Errorf("duplicate status = %d, want 409", w.Code)
+	}
+
+	// Busca pelo ID e pelo slug
+	for _, key := range []string{electronics.ID, "eletronicos"} {
+		if w := serveCategory(router, http.MethodGet, "/api/v1/categories/"+key, ""); w.Code != http.StatusOK || decodeCategory(t, w).ID != electronics.ID {
+			t.Errorf("GET %s status = %d (%s)", key, w.Code, w.Body.String())
+		}
+	}
+	if w := serveCategory(router, http.MethodGet, "/api/v1/categories/missing", ""); w.Code != http.StatusNotFound {
+		t.Errorf("GET missing status = %d, want 404", w.Code)
+	}
+
+	w := serveCategory(router, http.MethodGet, "/api/v1/categories", "")
+	var list CategoryListResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &list)
+	if w.Code != http.StatusOK || list.Total != 2 || list.Items[0].Name != "Celulares" {
+		t.Errorf("FindAll() status = %d, list = %+v", w.Code, list)
+	}
+
+	_ = products.Add(context.Background(), product_entity.Product{ID: "id-1", Name: "Phone", Sku: 1, Categories: []string{"Eletronicos"}, Price: product_valueobject.MustBRL(1000), Version: 1})
+
+	// Uma categoria não pode ficar dentro de uma subcategoria sua
+	w = serveCategory(router, http.MethodPut, "/api/v1/categories/eletronicos", `{"name":"Eletronicos","parent_id":"`+phones.ID+`"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("cycle status = %d, want 400 (%s)", w.Code, w.Body.String())
+	}
+
+	// Nem ser a própria categoria pai
+	w = serveCategory(router, http.MethodPut, "/api/v1/categories/eletronicos", `{"name":"Eletronicos","parent_id":"`+electronics.ID+`"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("own parent status = %d, want 400 (%s)", w.Code, w.Body.String())
+	}
+	assertProblemFields(t, w, "parent_id")
+
+	// A renomeação mantém o slug e chega aos produtos
+	w = serveCategory(router, http.MethodPut, "/api/v1/categories/eletronicos", `{"name":"Eletrônicos","description":"Aparelhos"}`)
+	if renamed := decodeCategory(t, w); w.Code != http.StatusOK || renamed.Name != "Eletrônicos" || renamed.Slug != "eletronicos" {
+		t.Fatalf("PUT status = %d, category = %+v", w.Code, renamed)
+	}
+	if phone, _ := products.FindOne(context.Background(), "Phone", false); phone.Categories[0] != "Eletrônicos" || phone.Version != 2 {
+		t.Errorf("Phone = %v (version %d), want renamed category", phone.Categories, phone.Version)
+	}
+
+	if w := serveCategory(router, http.MethodDelete, "/api/v1/categories/eletronicos", ""); w.Code != http.StatusConflict {
+		t.Errorf("DELETE in use status = %d, want 409", w.Code)
+	}
+	if w := serveCategory(router, http.MethodDelete, "/api/v1/categories/"+phones.ID, ""); w.Code != http.StatusNoContent {
+		t.Errorf("DELETE status = %d, want 204 (%s)", w.Code, w.Body.String())
+	}
+	if w := serveCategory(router, http.MethodDelete, "/api/v1/categories/"+phones.ID, ""); w.Code != http.StatusNotFound {
+		t.Errorf("DELETE missing status = %d, want 404", w.Code)
+	}
+}
+
+func TestCategoryHandler_Merge(t *testing.T) {
+	router, products := newCategoryTestRouter()
+
+	target := decodeCategory(t, serveCategory(router, http.MethodPost, "/api/v1/categories", `{"name":"Eletrônicos"}`))
+	source := decodeCategory(t, serveCategory(router, http.MethodPost, "/api/v1/categories", `{"name":"Eletronicos","slug":"eletronicos-2"}`))
+	_ = products.Add(context.Background(), product_entity.Product{ID: "id-1", Name: "Phone", Sku: 1, Categories: []string{"Eletronicos"}, Price: product_valueobject.MustBRL(1000), Version: 1})
+
+	if w := serveCategory(router, http.MethodPost, "/api/v1/categories/eletronicos-2/merge", `{}`); w.Code != http.StatusBadRequest {
+		t.Errorf("merge without target status = %d, want 400", w.Code)
+	}
+	if w := serveCategory(router, http.MethodPost, "/api/v1/categories/eletronicos-2/merge", `{"into":"eletronicos-2"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("merge into itself status = %d, want 400 (%s)", w.Code, w.Body.String())
+	}
+
+	w := serveCategory(router, http.MethodPost, "/api/v1/categories/eletronicos-2/merge", `{"into":"`+target.ID+`"}`)
+	var response CategoryMergeResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &response)
+	if w.Code != http.StatusOK || response.Category.ID != target.ID || response.Products != 1 {
+		t.Fatalf("merge status = %d, response = %+v (%s)", w.Code, response, w.Body.String())
+	}
+
+	if w := serveCategory(router, http.MethodGet, "/api/v1/categories/"+source.ID, ""); w.Code != http.StatusNotFound {
+		t.Errorf("merged category status = %d, want 404", w.Code)
+	}
+	if phone, _ := products.FindOne(context.Background(), "Phone", false); strings.Join(phone.Categories, ",") != "Eletrônicos" {
+		t.Errorf("Phone categories = %v, want [Eletrônicos]", phone.Categories)
+	}
+}
+
+func TestCategoryHandler_Products(t *testing.T) {
+	router, products := newCategoryTestRouter()
+
+	electronics := decodeCategory(t, serveCategory(router, http.MethodPost, "/api/v1/categories", `{"name":"Eletrônicos"}`))
+	computers := decodeCategory(t, serveCategory(router, http.MethodPost, "/api/v1/categories", `{"name":"Computadores","parent_id":"`+electronics.ID+`"}`))
+	_ = serveCategory(router, http.MethodPost, "/api/v1/categories", `{"name":"Notebooks","parent_id":"`+computers.ID+`"}`)
+	_ = serveCategory(router, http.MethodPost, "/api/v1/categories", `{"name":"Livros"}`)
+
+	ctx := context.Background()
+	_ = products.Add(ctx, product_entity.Product{ID: "id-1", Name: "TV", Sku: 1, Categories: []string{"Eletrônicos"}, Price: product_valueobject.MustBRL(5000), Version: 1})
+	_ = products.Add(ctx, product_entity.Product{ID: "id-2", Name: "Notebook", Sku: 2, Categories: []string{"Notebooks"}, Price: product_valueobject.MustBRL(3000), Version: 1})
+	_ = products.Add(ctx, product_entity.Product{ID: "id-3", Name: "Book", Sku: 3, Categories: []string{"Livros"}, Price: product_valueobject.MustBRL(100), Version: 1})
+
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+		expectedNames  string
+	}{
+		{name: "subtree", path: "/api/v1/categories/eletronicos/products?sort=name", expectedStatus: http.StatusOK, expectedNames: "Notebook,TV"},
+		{name: "only the category", path: "/api/v1/categories/eletronicos/products?descendants=false", expectedStatus: http.StatusOK, expectedNames: "TV"},
+		{name: "subcategory", path: "/api/v1/categories/computadores/products", expectedStatus: http.StatusOK, expectedNames: "Notebook"},
+		{name: "with filters", path: "/api/v1/categories/eletronicos/products?currency=BRL&max_price=4000", expectedStatus: http.StatusOK, expectedNames: "Notebook"},
+		{name: "price filter without currency", path: "/api/v1/categories/eletronicos/products?max_price=4000", expectedStatus: http.StatusBadRequest},
+		{name: "invalid descendants", path: "/api/v1/categories/eletronicos/products?descendants=maybe", expectedStatus: http.StatusBadRequest},
+		{name: "missing category", path: "/api/v1/categories/missing/products", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serveCategory(router, http.MethodGet, tt.path, "")
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d. Body: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var page ProductListResponse
+			_ = json.Unmarshal(w.Body.Bytes(), &page)
+			names := make([]string, len(page.Items))
+			for i, product := range page.Items {
+				names[i] = product.Name
+			}
+			if strings.Join(names, ",") != tt.expectedNames || page.Total != len(names) {
+				t.Errorf("products = %v (total %d), want %s", names, page.Total, tt.expectedNames)
+			}
+		})
+	}
+}
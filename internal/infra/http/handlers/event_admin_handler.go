@@ -0,0 +1,199 @@
+package product_handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	http_middleware "github.com/williamkoller/golang-domain-driven-design/internal/infra/http/middleware"
+	shared_events "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/events"
+)
+
+// Erros do dispatcher, da dead-letter store e das projeções
+func init() {
+	http_middleware.RegisterErrors(
+		http_middleware.MapNotFound(shared_events.ErrDeadLetterNotFound),
+		http_middleware.MapNotFound(shared_events.ErrProjectionNotFound),
+		http_middleware.MapConflict(shared_events.ErrHandlerNotFound),
+		http_middleware.MapUnavailable(shared_events.ErrEventStoreNotConfigured),
+	)
+}
+
+// EventAdminHandler expõe operações administrativas dos eventos de domínio
+type EventAdminHandler struct {
+	dispatcher *shared_events.EventDispatcher
+}
+
+func NewEventAdminHandler(dispatcher *shared_events.EventDispatcher) *EventAdminHandler {
+	return &EventAdminHandler{dispatcher}
+}
+
+// DeadLetterListResponse representa os eventos na dead-letter store
+type DeadLetterListResponse struct {
+	Items []shared_events.DeadLetter `json:"items"`
+	Total int                        `json:"total" example:"3"`
+}
+
+// SubscriptionListResponse representa os handlers registrados no dispatcher
+type SubscriptionListResponse struct {
+	Items []shared_events.SubscriptionInfo `json:"items"`
+	Total int                              `json:"total" example:"4"`
+}
+
+// ListSubscriptions godoc
+//
+//	@Summary		Listar inscrições de eventos
+//	@Description	Lista os padrões de eventos registrados no dispatcher e quantos handlers cada um tem
+//	@Tags			admin
+//	@Produce		json
+//	@Success		200	{object}	SubscriptionListResponse
+//	@Router			/admin/subscriptions [get]
+func (h *EventAdminHandler) ListSubscriptions(c *gin.Context) {
+	subscriptions := h.dispatcher.Subscriptions()
+	c.JSON(http.StatusOK, SubscriptionListResponse{Items: subscriptions, Total: len(subscriptions)})
+}
+
+// ListDeadLetters godoc
+//
+//	@Summary		Listar eventos com falha
+//	@Description	Lista os eventos cujos handlers esgotaram as tentativas, do mais antigo para o mais recente. Com PostgreSQL os registros sobrevivem a reinícios; no modo in-memory eles se perdem quando a aplicação reinicia.
+//	@Tags			admin
+//	@Produce		json
+//	@Success		200	{object}	DeadLetterListResponse
+//	@Failure		500	{object}	http_middleware.ProblemDetails
+//	@Router			/admin/dead-letters [get]
+func (h *EventAdminHandler) ListDeadLetters(c *gin.Context) {
+	entries, err := h.dispatcher.DeadLetters().List()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, DeadLetterListResponse{Items: entries, Total: len(entries)})
+}
+
+// ReplayDeadLetter godoc
+//
+//	@Summary		Reprocessar um evento com falha
+//	@Description	Executa novamente o handler que falhou; em caso de sucesso o evento sai da dead-letter store
+//	@Tags			admin
+//	@Produce		json
+//	@Param			id	path	string	true	"ID do registro"
+//	@Success		204
+//	@Failure		404	{object}	http_middleware.ProblemDetails
+//	@Failure		409	{object}	http_middleware.ProblemDetails
+//	@Failure		502	{object}	http_middleware.ProblemDetails
+//	@Router			/admin/dead-letters/{id}/replay [post]
+func (h *EventAdminHandler) ReplayDeadLetter(c *gin.Context) {
+	err := h.dispatcher.ReplayDeadLetter(c.Param("id"))
+
+	switch {
+	case err == nil:
+		c.Status(http.StatusNoContent)
+	case errors.Is(err, shared_events.ErrDeadLetterNotFound), errors.Is(err, shared_events.ErrHandlerNotFound):
+		c.Error(err)
+	default:
+		// O handler falhou novamente; o registro continua na dead-letter store
+		c.Error(http_middleware.WithStatus(http.StatusBadGateway, err))
+	}
+}
+
+// DeleteDeadLetter godoc
+//
+//	@Summary		Descartar um evento com falha
+//	@Description	Remove o registro da dead-letter store sem reprocessá-lo
+//	@Tags			admin
+//	@Param			id	path	string	true	"ID do registro"
+//	@Success		204
+//	@Failure		404	{object}	http_middleware.ProblemDetails
+//	@Router			/admin/dead-letters/{id} [delete]
+func (h *EventAdminHandler) DeleteDeadLetter(c *gin.Context) {
+	if err := h.dispatcher.DeadLetters().Delete(c.Param("id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ProjectionListResponse representa as projeções registradas no dispatcher
+type ProjectionListResponse struct {
+	Items []string `json:"items" example:"business-metrics"`
+	Total int      `json:"total" example:"1"`
+}
+
+// RebuildProjectionsInput representa a requisição de reconstrução de projeções
+type RebuildProjectionsInput struct {
+	// Projeções a reconstruir; vazio reconstrói todas
+	Projections []string `json:"projections" example:"business-metrics"`
+	// Sequência inicial; acima de 1 reaplica a partir dela sem limpar o estado atual
+	From int64 `json:"from" binding:"gte=0" example:"0"`
+}
+
+// RebuildProjectionsResponse representa o resultado da reconstrução
+type RebuildProjectionsResponse struct {
+	Projections []string `json:"projections" example:"business-metrics"`
+	Replayed    int      `json:"replayed" example:"128"`
+}
+
+// ListProjections godoc
+//
+//	@Summary		Listar projeções
+//	@Description	Lista as projeções que podem ser reconstruídas a partir do histórico de eventos
+//	@Tags			admin
+//	@Produce		json
+//	@Success		200	{object}	ProjectionListResponse
+//	@Router			/admin/projections [get]
+func (h *EventAdminHandler) ListProjections(c *gin.Context) {
+	projections := h.dispatcher.Projections()
+	c.JSON(http.StatusOK, ProjectionListResponse{Items: projections, Total: len(projections)})
+}
+
+// RebuildProjections godoc
+//
+//	@Summary		Reconstruir projeções
+//	@Description	Reaplica o histórico de eventos às projeções escolhidas. Sem "from" (ou com from <= 1) o estado é descartado e o histórico inteiro é reaplicado.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			input	body		RebuildProjectionsInput	false	"Projeções e sequência inicial"
+//	@Success		200		{object}	RebuildProjectionsResponse
+//	@Failure		400		{object}	http_middleware.ProblemDetails
+//	@Failure		404		{object}	http_middleware.ProblemDetails
+//	@Failure		500		{object}	http_middleware.ProblemDetails
+//	@Failure		503		{object}	http_middleware.ProblemDetails
+//	@Router			/admin/projections/rebuild [post]
+func (h *EventAdminHandler) RebuildProjections(c *gin.Context) {
+	var input RebuildProjectionsInput
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.Error(http_middleware.BindingError(err, &input))
+			return
+		}
+	}
+
+	// A reconstrução não é interrompida se o cliente desconectar: a projeção ficaria pela metade
+	ctx := context.WithoutCancel(c.Request.Context())
+
+	var (
+		replayed int
+		err      error
+	)
+	if input.From > 1 {
+		replayed, err = h.dispatcher.ReplayFrom(ctx, input.From, input.Projections...)
+	} else {
+		replayed, err = h.dispatcher.RebuildProjections(ctx, input.Projections...)
+	}
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	projections := input.Projections
+	if len(projections) == 0 {
+		projections = h.dispatcher.Projections()
+	}
+	c.JSON(http.StatusOK, RebuildProjectionsResponse{Projections: projections, Replayed: replayed})
+}
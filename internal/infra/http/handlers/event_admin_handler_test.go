@@ -0,0 +1,247 @@
+package product_handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	http_middleware "github.com/williamkoller/golang-domain-driven-design/internal/infra/http/middleware"
+	shared_events "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/events"
+)
+
+type adminTestEvent struct {
+	Name string `json:"name"`
+}
+
+func (e *adminTestEvent) EventName() string { return "test.event" }
+
+func setupAdminTestRouter(handler *EventAdminHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(http_middleware.ErrorHandler())
+	r.GET("/api/v1/admin/subscriptions", handler.ListSubscriptions)
+	r.GET("/api/v1/admin/projections", handler.ListProjections)
+	r.POST("/api/v1/admin/projections/rebuild", handler.RebuildProjections)
+	r.GET("/api/v1/admin/dead-letters", handler.ListDeadLetters)
+	r.POST("/api/v1/admin/dead-letters/:id/replay", handler.ReplayDeadLetter)
+	r.DELETE("/api/v1/admin/dead-letters/:id", handler.DeleteDeadLetter)
+	return r
+}
+
+func TestEventAdminHandler_DeadLetters(t *testing.T) {
+	dispatcher := shared_events.NewEventDispatcher()
+	var healthy atomic.Bool
+
+	dispatcher.RegisterWithRetry("test.event", "webhook", func(event shared_events.Event) error {
+		if !healthy.Load() {
+			return errors.New("connection refused")
+		}
+		return nil
+	}, shared_events.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond, Multiplier: 2})
+
+	dispatcher.DispatchAndWait("test.event", &adminTestEvent{Name: "Notebook"})
+	dispatcher.DispatchAndWait("test.event", &adminTestEvent{Name: "Mouse"})
+
+	// A segunda tentativa é agendada depois que DispatchAndWait retorna
+	deadline := time.Now().Add(2 * time.Second)
+	for entries, _ := dispatcher.DeadLetters().List(); len(entries) < 2; entries, _ = dispatcher.DeadLetters().List() {
+		if time.Now().After(deadline) {
+			t.Fatalf("dead letters = %d, want 2", len(entries))
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	router := setupAdminTestRouter(NewEventAdminHandler(dispatcher))
+
+	// O evento é uma interface; na resposta basta conferir o JSON bruto do payload
+	var listed struct {
+		Items []struct {
+			ID          string `json:"id"`
+			HandlerName string `json:"handler"`
+			Attempts    int    `json:"attempts"`
+			Error       string `json:"error"`
+			Event       struct {
+				ID      string          `json:"id"`
+				Payload json.RawMessage `json:"payload"`
+			} `json:"event"`
+		} `json:"items"`
+		Total int `json:"total"`
+	}
+	t.Run("list", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/admin/dead-letters", nil))
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &listed); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		if listed.Total != 2 || len(listed.Items) != 2 {
+			t.Fatalf("Total = %d, items = %d; want 2", listed.Total, len(listed.Items))
+		}
+		first := listed.Items[0]
+		if first.HandlerName != "webhook" || first.Attempts != 2 || first.Error != "connection refused" || first.Event.ID == "" || string(first.Event.Payload) != `{"name":"Notebook"}` {
+			t.Errorf("Items[0] = %+v", first)
+		}
+	})
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		before         func()
+		expectedStatus int
+	}{
+		{name: "replay failing again", method: http.MethodPost, path: "/api/v1/admin/dead-letters/" + listed.Items[0].ID + "/replay", expectedStatus: http.StatusBadGateway},
+		{name: "replay succeeds", method: http.MethodPost, path: "/api/v1/admin/dead-letters/" + listed.Items[0].ID + "/replay", before: func() { healthy.Store(true) }, expectedStatus: http.StatusNoContent},
+		{name: "replay removed entry", method: http.MethodPost, path: "/api/v1/admin/dead-letters/" + listed.Items[0].ID + "/replay", expectedStatus: http.StatusNotFound},
+		{name: "delete entry", method: http.MethodDelete, path: "/api/v1/admin/dead-letters/" + listed.Items[1].ID, expectedStatus: http.StatusNoContent},
+		{name: "delete missing entry", method: http.MethodDelete, path: "/api/v1/admin/dead-letters/" + listed.Items[1].ID, expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.before != nil {
+				tt.before()
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d. Body: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	if entries, _ := dispatcher.DeadLetters().List(); len(entries) != 0 {
+		t.Errorf("dead letters left = %d, want 0", len(entries))
+	}
+}
+
+func TestEventAdminHandler_ReplayUnknownHandler(t *testing.T) {
+	dispatcher := shared_events.NewEventDispatcher()
+	_ = dispatcher.DeadLetters().Save(shared_events.DeadLetter{ID: "orphan", HandlerName: "removed", Event: &adminTestEvent{}})
+
+	router := setupAdminTestRouter(NewEventAdminHandler(dispatcher))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/admin/dead-letters/orphan/replay", nil))
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected status 409, got %d. Body: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestEventAdminHandler_ListSubscriptions(t *testing.T) {
+	dispatcher := shared_events.NewEventDispatcher()
+	dispatcher.Register("product.*", func(event shared_events.Event) {})
+	dispatcher.RegisterWithRetry("*", "audit", func(event shared_events.Event) error { return nil }, shared_events.DefaultRetryPolicy())
+
+	router := setupAdminTestRouter(NewEventAdminHandler(dispatcher))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/admin/subscriptions", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	var response SubscriptionListResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if response.Total != 2 || response.Items[0].Pattern != "*" || len(response.Items[0].Named) != 1 || response.Items[1].Pattern != "product.*" {
+		t.Errorf("response = %+v", response)
+	}
+}
+
+// namesProjection guarda os nomes dos eventos aplicados
+type namesProjection struct {
+	applied []string
+}
+
+func (p *namesProjection) Name() string { return "names" }
+func (p *namesProjection) Reset() error { p.applied = nil; return nil }
+func (p *namesProjection) Apply(event shared_events.Event) error {
+	p.applied = append(p.applied, event.EventName())
+	return nil
+}
+
+func TestEventAdminHandler_Projections(t *testing.T) {
+	dispatcher := shared_events.NewEventDispatcher()
+	dispatcher.SetEventStore(shared_events.NewInMemoryEventStore())
+	projection := &namesProjection{}
+	dispatcher.RegisterProjection("*", projection)
+
+	for i := 0; i < 3; i++ {
+		dispatcher.DispatchAndWait("test.event", &adminTestEvent{Name: "Notebook"})
+	}
+
+	router := setupAdminTestRouter(NewEventAdminHandler(dispatcher))
+
+	t.Run("list", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/admin/projections", nil))
+
+		var response ProjectionListResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		if w.Code != http.StatusOK || response.Total != 1 || response.Items[0] != "names" {
+			t.Errorf("status = %d, response = %+v", w.Code, response)
+		}
+	})
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		wantReplayed   int
+		wantApplied    int
+	}{
+		{name: "rebuild all without body", expectedStatus: http.StatusOK, wantReplayed: 3, wantApplied: 3},
+		{name: "rebuild chosen projection", body: `{"projections":["names"]}`, expectedStatus: http.StatusOK, wantReplayed: 3, wantApplied: 3},
+		{name: "replay from sequence keeps state", body: `{"projections":["names"],"from":3}`, expectedStatus: http.StatusOK, wantReplayed: 1, wantApplied: 4},
+		{name: "unknown projection", body: `{"projections":["missing"]}`, expectedStatus: http.StatusNotFound, wantApplied: 4},
+		{name: "invalid body", body: `{"from":-1}`, expectedStatus: http.StatusBadRequest, wantApplied: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/projections/rebuild", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d. Body: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.expectedStatus == http.StatusOK {
+				var response RebuildProjectionsResponse
+				_ = json.Unmarshal(w.Body.Bytes(), &response)
+				if response.Replayed != tt.wantReplayed || len(response.Projections) != 1 {
+					t.Errorf("response = %+v, want %d replayed", response, tt.wantReplayed)
+				}
+			}
+			if len(projection.applied) != tt.wantApplied {
+				t.Errorf("applied = %d events, want %d", len(projection.applied), tt.wantApplied)
+			}
+		})
+	}
+
+	t.Run("without event store", func(t *testing.T) {
+		router := setupAdminTestRouter(NewEventAdminHandler(shared_events.NewEventDispatcher()))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/admin/projections/rebuild", nil))
+
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("Expected status 503, got %d", w.Code)
+		}
+	})
+}
@@ -0,0 +1,162 @@
+package product_handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	http_middleware "github.com/williamkoller/golang-domain-driven-design/internal/infra/http/middleware"
+	http_sse "github.com/williamkoller/golang-domain-driven-design/internal/infra/http/sse"
+	shared_events "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/events"
+)
+
+// Erro do broker encerrado no desligamento da aplicação
+func init() {
+	http_middleware.RegisterErrors(http_middleware.MapUnavailable(http_sse.ErrBrokerClosed))
+}
+
+// DefaultHeartbeatInterval é o intervalo dos comentários que mantêm o stream aberto em proxies
+const DefaultHeartbeatInterval = 15 * time.Second
+
+// StreamResetEvent é enviado quando os eventos desde o Last-Event-ID não estão mais
+// disponíveis; o cliente deve recarregar o estado com GET /products
+const StreamResetEvent = "stream.reset"
+
+// EventStreamHandler transmite os eventos de produto por Server-Sent Events
+type EventStreamHandler struct {
+	broker    *http_sse.Broker
+	heartbeat time.Duration
+}
+
+func NewEventStreamHandler(broker *http_sse.Broker, heartbeat time.Duration) *EventStreamHandler {
+	if heartbeat <= 0 {
+		heartbeat = DefaultHeartbeatInterval
+	}
+	return &EventStreamHandler{broker, heartbeat}
+}
+
+// Stream godoc
+//
+//	@Summary		Stream de eventos de produto
+//	@Description	Transmite os eventos de produto por Server-Sent Events. Reconexões com o header Last-Event-ID (ou o parâmetro last_event_id) recebem os eventos perdidos ainda no buffer; se parte deles já saiu do buffer, ou o ID é de antes de um reinício ou de outra instância, o evento stream.reset é enviado antes.
+//	@Tags			events
+//	@Produce		text/event-stream
+//	@Param			events			query	string	false	"Padrões de eventos separados por vírgula (ex: product.created,product.deleted)"
+//	@Param			last_event_id	query	string	false	"ID do último evento recebido, como alternativa ao header Last-Event-ID"
+//	@Param			Last-Event-ID	header	string	false	"ID do último evento recebido (ex: lq2x8k1c5g-42)"
+//	@Success		200
+//	@Failure		400	{object}	http_middleware.ProblemDetails
+//	@Failure		503	{object}	http_middleware.ProblemDetails
+//	@Router			/events/stream [get]
+func (h *EventStreamHandler) Stream(c *gin.Context) {
+	filter, err := parseEventFilter(c.Query("events"))
+	if err != nil {
+		c.Error(http_middleware.BadRequest(err))
+		return
+	}
+
+	lastEventID, err := parseLastEventID(c)
+	if err != nil {
+		c.Error(http_middleware.BadRequest(err))
+		return
+	}
+
+	sub, backlog, missed, err := h.broker.Subscribe(lastEventID, filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	defer sub.Close()
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	// Desliga o buffer de proxies como o nginx
+	header.Set("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	w := c.Writer
+	fmt.Fprintf(w, "retry: 3000\n\n")
+	if missed {
+		fmt.Fprintf(w, "event: %s\ndata: {}\n\n", StreamResetEvent)
+	}
+	for _, msg := range backlog {
+		writeMessage(w, msg)
+	}
+	w.Flush()
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case msg, ok := <-sub.C:
+			// Canal fechado: encerramento do servidor ou cliente lento demais
+			if !ok {
+				return
+			}
+			writeMessage(w, msg)
+			w.Flush()
+		case <-heartbeat.C:
+			fmt.Fprintf(w, ": heartbeat\n\n")
+			w.Flush()
+		}
+	}
+}
+
+func writeMessage(w gin.ResponseWriter, msg http_sse.Message) {
+	fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", msg.ID, msg.Event, msg.Data)
+}
+
+// parseEventFilter lê os padrões separados por vírgula; vazio aceita todos os eventos
+func parseEventFilter(value string) (func(string) bool, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	var patterns []string
+	for _, pattern := range strings.Split(value, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		if err := shared_events.ValidatePattern(pattern); err != nil {
+			return nil, fmt.Errorf("invalid event pattern %q", pattern)
+		}
+		patterns = append(patterns, pattern)
+	}
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+
+	return func(eventName string) bool {
+		for _, pattern := range patterns {
+			if shared_events.MatchPattern(pattern, eventName) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// parseLastEventID lê o header Last-Event-ID ou, na falta dele, o parâmetro last_event_id
+func parseLastEventID(c *gin.Context) (http_sse.EventID, error) {
+	value := c.GetHeader("Last-Event-ID")
+	if value == "" {
+		value = c.Query("last_event_id")
+	}
+	if value == "" {
+		return http_sse.EventID{}, nil
+	}
+
+	id, err := http_sse.ParseEventID(value)
+	if err != nil {
+		return http_sse.EventID{}, fmt.Errorf("invalid last event id %q", value)
+	}
+	return id, nil
+}
@@ -0,0 +1,270 @@
+package product_handlers
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	http_middleware "github.com/williamkoller/golang-domain-driven-design/internal/infra/http/middleware"
+	http_sse "github.com/williamkoller/golang-domain-driven-design/internal/infra/http/sse"
+)
+
+// sseFrame é um bloco do stream: um evento (id, event, data), o retry ou um comentário
+type sseFrame struct {
+	id, event, data, retry, comment string
+}
+
+// sseClient lê os blocos de um stream aberto em um servidor de teste
+type sseClient struct {
+	resp   *http.Response
+	frames chan sseFrame
+}
+
+func openStream(t *testing.T, server *httptest.Server, path string, headers map[string]string) *sseClient {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+path, nil)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("stream request failed: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+
+	client := &sseClient{resp: resp, frames: make(chan sseFrame, 100)}
+	go func() {
+		defer close(client.frames)
+
+		scanner := bufio.NewScanner(resp.Body)
+		var frame sseFrame
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case line == "":
+				if frame != (sseFrame{}) {
+					client.frames <- frame
+				}
+				frame = sseFrame{}
+			case strings.HasPrefix(line, ":"):
+				frame.comment = strings.TrimSpace(line[1:])
+			case strings.HasPrefix(line, "retry: "):
+				frame.retry = line[7:]
+			case strings.HasPrefix(line, "id: "):
+				frame.id = line[4:]
+			case strings.HasPrefix(line, "event: "):
+				frame.event = line[7:]
+			case strings.HasPrefix(line, "data: "):
+				frame.data = line[6:]
+			}
+		}
+	}()
+
+	return client
+}
+
+// next retorna o próximo evento, ignorando retry e heartbeats
+func (c *sseClient) next(t *testing.T) sseFrame {
+	t.Helper()
+
+	for {
+		select {
+		case frame, ok := <-c.frames:
+			if !ok {
+				t.Fatal("stream closed before the expected event")
+			}
+			if frame.event != "" {
+				return frame
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for an event")
+		}
+	}
+}
+
+func setupStreamTestServer(t *testing.T, broker *http_sse.Broker, heartbeat time.Duration) *httptest.Server {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(http_middleware.ErrorHandler())
+	r.GET("/api/v1/events/stream", NewEventStreamHandler(broker, heartbeat).Stream)
+
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// waitSubscribed espera o primeiro bloco do stream (o retry), enviado depois da inscrição
+// no broker: um evento publicado antes disso não seria entregue ao vivo
+func waitSubscribed(t *testing.T, client *sseClient) {
+	t.Helper()
+
+	select {
+	case frame := <-client.frames:
+		if frame.retry == "" {
+			t.Fatalf("first frame = %+v, want retry", frame)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("stream did not start")
+	}
+}
+
+func TestEventStreamHandler_Live(t *testing.T) {
+	broker := http_sse.NewBroker(10, 10)
+	server := setupStreamTestServer(t, broker, time.Minute)
+
+	all := openStream(t, server, "/api/v1/events/stream", nil)
+	deleted := openStream(t, server, "/api/v1/events/stream?events=product.deleted,product.restored", nil)
+	waitSubscribed(t, all)
+	waitSubscribed(t, deleted)
+
+	if all.resp.StatusCode != http.StatusOK || all.resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("status = %d, content type = %q", all.resp.StatusCode, all.resp.Header.Get("Content-Type"))
+	}
+
+	broker.Publish("product.created", []byte(`{"name":"product.created"}`))
+	broker.Publish("product.deleted", []byte(`{"name":"product.deleted"}`))
+	epoch := broker.LastEventID().Epoch
+
+	if frame := all.next(t); frame.id != epoch+"-1" || frame.event != "product.created" || frame.data != `{"name":"product.created"}` {
+		t.Errorf("first event = %+v", frame)
+	}
+	if frame := all.next(t); frame.id != epoch+"-2" || frame.event != "product.deleted" {
+		t.Errorf("second event = %+v", frame)
+	}
+	if frame := deleted.next(t); frame.id != epoch+"-2" || frame.event != "product.deleted" {
+		t.Errorf("filtered stream event = %+v", frame)
+	}
+}
+
+func TestEventStreamHandler_Resume(t *testing.T) {
+	broker := http_sse.NewBroker(2, 10)
+	server := setupStreamTestServer(t, broker, time.Minute)
+
+	broker.Publish("product.created", []byte(`{}`))
+	broker.Publish("product.updated", []byte(`{}`))
+	broker.Publish("product.deleted", []byte(`{}`))
+	broker.Publish("product.restored", []byte(`{}`))
+	// Buffer com os eventos 3 e 4
+	epoch := broker.LastEventID().Epoch
+
+	t.Run("header inside buffer", func(t *testing.T) {
+		client := openStream(t, server, "/api/v1/events/stream", map[string]string{"Last-Event-ID": epoch + "-3"})
+		if frame := client.next(t); frame.id != epoch+"-4" || frame.event != "product.restored" {
+			t.Errorf("resumed event = %+v", frame)
+		}
+	})
+
+	t.Run("query parameter with gap", func(t *testing.T) {
+		client := openStream(t, server, "/api/v1/events/stream?last_event_id="+epoch+"-1", nil)
+		if frame := client.next(t); frame.event != StreamResetEvent {
+			t.Errorf("first event = %+v, want %s", frame, StreamResetEvent)
+		}
+		if frame := client.next(t); frame.id != epoch+"-3" {
+			t.Errorf("resumed event = %+v", frame)
+		}
+		if frame := client.next(t); frame.id != epoch+"-4" {
+			t.Errorf("resumed event = %+v", frame)
+		}
+	})
+
+	t.Run("id from before a restart", func(t *testing.T) {
+		client := openStream(t, server, "/api/v1/events/stream", map[string]string{"Last-Event-ID": "3"})
+		if frame := client.next(t); frame.event != StreamResetEvent {
+			t.Errorf("first event = %+v, want %s", frame, StreamResetEvent)
+		}
+
+		broker.Publish("product.created", []byte(`{}`))
+		if frame := client.next(t); frame.id != epoch+"-5" {
+			t.Errorf("live event = %+v, want only new events after the reset", frame)
+		}
+	})
+}
+
+func TestEventStreamHandler_Heartbeat(t *testing.T) {
+	broker := http_sse.NewBroker(10, 10)
+	server := setupStreamTestServer(t, broker, 10*time.Millisecond)
+
+	client := openStream(t, server, "/api/v1/events/stream", nil)
+	waitSubscribed(t, client)
+
+	select {
+	case frame := <-client.frames:
+		if frame.comment != "heartbeat" {
+			t.Errorf("frame = %+v, want heartbeat", frame)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no heartbeat received")
+	}
+}
+
+func TestEventStreamHandler_CloseEndsStream(t *testing.T) {
+	broker := http_sse.NewBroker(10, 10)
+	server := setupStreamTestServer(t, broker, time.Minute)
+
+	client := openStream(t, server, "/api/v1/events/stream", nil)
+	waitSubscribed(t, client)
+
+	broker.Close()
+
+	select {
+	case _, ok := <-client.frames:
+		for ok {
+			_, ok = <-client.frames
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("stream not closed after broker.Close()")
+	}
+
+	resp, err := http.Get(server.URL + "/api/v1/events/stream")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("status after close = %d, want 503", resp.StatusCode)
+	}
+}
+
+func TestEventStreamHandler_InvalidParameters(t *testing.T) {
+	server := setupStreamTestServer(t, http_sse.NewBroker(10, 10), time.Minute)
+
+	tests := []struct {
+		name    string
+		path    string
+		headers map[string]string
+	}{
+		{name: "malformed pattern", path: "/api/v1/events/stream?events=product.["},
+		{name: "invalid last event id header", path: "/api/v1/events/stream", headers: map[string]string{"Last-Event-ID": "abc"}},
+		{name: "invalid last event id query", path: "/api/v1/events/stream?last_event_id=-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, server.URL+tt.path, nil)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400", resp.StatusCode)
+			}
+		})
+	}
+}
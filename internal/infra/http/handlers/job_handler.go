@@ -0,0 +1,155 @@
+package product_handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/gin-gonic/gin"
+	http_middleware "github.com/williamkoller/golang-domain-driven-design/internal/infra/http/middleware"
+	shared_jobs "github.com/williamkoller/golang-domain-driven-design/internal/shared/jobs"
+)
+
+// Erros dos jobs em segundo plano
+func init() {
+	http_middleware.RegisterErrors(
+		http_middleware.MapNotFound(shared_jobs.ErrJobNotFound),
+		http_middleware.MapConflict(shared_jobs.ErrJobFinished),
+		http_middleware.MapConflict(shared_jobs.ErrJobNotFinished),
+	)
+}
+
+// JobsPath é o caminho dos jobs na API
+const JobsPath = "/api/v1/jobs"
+
+var (
+	errJobHasNoResult       = errors.New("job has no result file")
+	errJobResultUnavailable = errors.New("job result file is no longer available")
+)
+
+type JobHandler struct {
+	jobs *shared_jobs.Pool
+}
+
+func NewJobHandler(jobs *shared_jobs.Pool) *JobHandler {
+	return &JobHandler{jobs}
+}
+
+// JobResponse representa um job com os endereços de consulta e do arquivo de resultado
+type JobResponse struct {
+	shared_jobs.Job
+	URL       string `json:"url" example:"/api/v1/jobs/3f2b8c1e-4d5a-4b6c-8d7e-9f0a1b2c3d4e"`
+	ResultURL string `json:"result_url,omitempty" example:"/api/v1/jobs/3f2b8c1e-4d5a-4b6c-8d7e-9f0a1b2c3d4e/result"`
+}
+
+func newJobResponse(job shared_jobs.Job) JobResponse {
+	response := JobResponse{Job: job, URL: JobsPath + "/" + job.ID}
+	if job.HasResultFile() {
+		response.ResultURL = response.URL + "/result"
+	}
+	return response
+}
+
+// respondJobAccepted responde 202 com o job criado e o seu endereço no header Location
+func respondJobAccepted(c *gin.Context, job shared_jobs.Job) {
+	response := newJobResponse(job)
+	c.Header("Location", response.URL)
+	c.JSON(http.StatusAccepted, response)
+}
+
+// FindByID godoc
+//
+//	@Summary		Consultar um job
+//	@Description	Retorna a situação do job, o andamento, o resumo do resultado, o endereço do arquivo gerado (result_url) e o erro, se houver
+//	@Tags			jobs
+//	@Produce		json
+//	@Param			id	path		string	true	"ID do job"
+//	@Success		200	{object}	JobResponse
+//	@Failure		404	{object}	http_middleware.ProblemDetails
+//	@Router			/jobs/{id} [get]
+func (h *JobHandler) FindByID(c *gin.Context) {
+	job, err := h.jobs.Find(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newJobResponse(job))
+}
+
+// Cancel godoc
+//
+//	@Summary		Cancelar um job
+//	@Description	Cancela o job na fila (200) ou pede a interrupção do job em execução (202, cancel_requested = true); a situação passa a canceled quando o worker para
+//	@Tags			jobs
+//	@Produce		json
+//	@Param			id	path		string	true	"ID do job"
+//	@Success		200	{object}	JobResponse
+//	@Success		202	{object}	JobResponse
+//	@Failure		404	{object}	http_middleware.ProblemDetails
+//	@Failure		409	{object}	http_middleware.ProblemDetails
+//	@Router			/jobs/{id}/cancel [post]
+func (h *JobHandler) Cancel(c *gin.Context) {
+	job, err := h.jobs.Cancel(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	status := http.StatusOK
+	if !job.Status.Finished() {
+		status = http.StatusAccepted
+	}
+	c.JSON(status, newJobResponse(job))
+}
+
+// Result godoc
+//
+//	@Summary		Baixar o resultado de um job
+//	@Description	Baixa o arquivo gerado pelo job, como o da exportação. O arquivo é removido junto com o job após o período de retenção.
+//	@Tags			jobs
+//	@Produce		text/csv,application/x-ndjson,json
+//	@Param			id	path		string	true	"ID do job"
+//	@Success		200	{file}		file
+//	@Failure		404	{object}	http_middleware.ProblemDetails
+//	@Failure		409	{object}	http_middleware.ProblemDetails
+//	@Router			/jobs/{id}/result [get]
+func (h *JobHandler) Result(c *gin.Context) {
+	job, err := h.jobs.Find(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if !job.Status.Finished() {
+		c.Error(shared_jobs.ErrJobNotFinished)
+		return
+	}
+	if !job.HasResultFile() {
+		c.Error(http_middleware.WithStatus(http.StatusNotFound, errJobHasNoResult))
+		return
+	}
+
+	file, err := os.Open(job.ResultFile)
+	if errors.Is(err, os.ErrNotExist) {
+		c.Error(http_middleware.WithStatus(http.StatusNotFound, errJobResultUnavailable))
+		return
+	}
+	if err != nil {
+		c.Error(fmt.Errorf("erro ao abrir o resultado do job: %w", err))
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		c.Error(fmt.Errorf("erro ao abrir o resultado do job: %w", err))
+		return
+	}
+
+	name := filepath.Base(job.ResultFile)
+	c.Header("Content-Type", job.ResultType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, name))
+	http.ServeContent(c.Writer, c.Request, name, info.ModTime(), file)
+}
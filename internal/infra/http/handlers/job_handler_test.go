@@ -0,0 +1,150 @@
+package product_handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	http_middleware "github.com/williamkoller/golang-domain-driven-design/internal/infra/http/middleware"
+	shared_jobs "github.com/williamkoller/golang-domain-driven-design/internal/shared/jobs"
+)
+
+// setupJobTestRouter registra as rotas de jobs como em product_router.SetupJobRoutes
+func setupJobTestRouter(jobHandler *JobHandler, productJobHandler *ProductJobHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(http_middleware.CorrelationID())
+	router.Use(http_middleware.ErrorHandler())
+
+	jobs := router.Group(JobsPath)
+	{
+		jobs.POST("/imports", productJobHandler.Import)
+		jobs.POST("/exports", productJobHandler.Export)
+		jobs.POST("/reindex", productJobHandler.Reindex)
+		jobs.GET("/:id", jobHandler.FindByID)
+		jobs.POST("/:id/cancel", jobHandler.Cancel)
+		jobs.GET("/:id/result", jobHandler.Result)
+	}
+
+	return router
+}
+
+func decodeJob(t *testing.T, w *httptest.ResponseRecorder) JobResponse {
+	t.Helper()
+	var job JobResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &job); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v (%s)", err, w.Body.String())
+	}
+	return job
+}
+
+func serveJobRequest(router http.Handler, method, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	return w
+}
+
+// newJobTestPool cria um pool com um job do tipo "test" que conclui com o arquivo de path
+func newJobTestPool(path string) *shared_jobs.Pool {
+	pool := shared_jobs.NewPool(shared_jobs.NewInMemoryStore(), shared_jobs.DefaultPoolConfig())
+	pool.Register("test", func(ctx context.Context, job shared_jobs.Job, progress *shared_jobs.Reporter) (shared_jobs.Outcome, error) {
+		if path == "" {
+			return shared_jobs.Outcome{Result: "done"}, nil
+		}
+		os.WriteFile(path, []byte("id,name\n"), 0o644)
+		return shared_jobs.Outcome{ResultFile: path, ResultType: "text/csv; charset=utf-8"}, nil
+	})
+	return pool
+}
+
+func TestJobHandler_FindByID(t *testing.T) {
+	pool := newJobTestPool("")
+	router := setupJobTestRouter(NewJobHandler(pool), nil)
+	queued, _ := pool.Enqueue(context.Background(), shared_jobs.NewJob("test", nil, nil, ""))
+
+	w := serveJobRequest(router, http.MethodGet, "/api/v1/jobs/"+queued.ID)
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET = %d, want 200", w.Code)
+	}
+	if job := decodeJob(t, w); job.Status != shared_jobs.StatusQueued || job.URL != "/api/v1/jobs/"+queued.ID || job.ResultURL != "" {
+		t.Errorf("GET = %+v", job)
+	}
+
+	pool.RunNext(context.Background())
+	if job := decodeJob(t, serveJobRequest(router, http.MethodGet, "/api/v1/jobs/"+queued.ID)); job.Status != shared_jobs.StatusSucceeded || string(job.Result) != `"done"` {
+		t.Errorf("GET after run = %+v", job)
+	}
+
+	if w := serveJobRequest(router, http.MethodGet, "/api/v1/jobs/missing"); w.Code != http.StatusNotFound {
+		t.Errorf("GET missing job = %d, want 404", w.Code)
+	}
+}
+
+func TestJobHandler_Cancel(t *testing.T) {
+	pool := newJobTestPool("")
+	router := setupJobTestRouter(NewJobHandler(pool), nil)
+	queued, _ := pool.Enqueue(context.Background(), shared_jobs.NewJob("test", nil, nil, ""))
+
+	w := serveJobRequest(router, http.MethodPost, "/api/v1/jobs/"+queued.ID+"/cancel")
+	if w.Code != http.StatusOK {
+		t.Fatalf("cancel = %d, want 200", w.Code)
+	}
+	if job := decodeJob(t, w); job.Status != shared_jobs.StatusCanceled || !job.CancelRequested {
+		t.Errorf("cancel = %+v", job)
+	}
+
+	if w := serveJobRequest(router, http.MethodPost, "/api/v1/jobs/"+queued.ID+"/cancel"); w.Code != http.StatusConflict {
+		t.Errorf("cancel of a finished job = %d, want 409", w.Code)
+	}
+	if w := serveJobRequest(router, http.MethodPost, "/api/v1/jobs/missing/cancel"); w.Code != http.StatusNotFound {
+		t.Errorf("cancel of a missing job = %d, want 404", w.Code)
+	}
+}
+
+func TestJobHandler_Result(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "products-job.csv")
+	pool := newJobTestPool(path)
+	router := setupJobTestRouter(NewJobHandler(pool), nil)
+	queued, _ := pool.Enqueue(ctx, shared_jobs.NewJob("test", nil, nil, ""))
+
+	if w := serveJobRequest(router, http.MethodGet, "/api/v1/jobs/"+queued.ID+"/result"); w.Code != http.StatusConflict {
+		t.Errorf("result of a queued job = %d, want 409", w.Code)
+	}
+
+	pool.RunNext(ctx)
+	job := decodeJob(t, serveJobRequest(router, http.MethodGet, "/api/v1/jobs/"+queued.ID))
+	if job.ResultURL != "/api/v1/jobs/"+queued.ID+"/result" {
+		t.Fatalf("result_url = %q", job.ResultURL)
+	}
+
+	w := serveJobRequest(router, http.MethodGet, job.ResultURL)
+	if w.Code != http.StatusOK || w.Body.String() != "id,name\n" {
+		t.Fatalf("result = %d %q", w.Code, w.Body.String())
+	}
+	if w.Header().Get("Content-Type") != "text/csv; charset=utf-8" || w.Header().Get("Content-Disposition") != `attachment; filename="products-job.csv"` {
+		t.Errorf("result headers = %v", w.Header())
+	}
+
+	os.Remove(path)
+	if w := serveJobRequest(router, http.MethodGet, job.ResultURL); w.Code != http.StatusNotFound {
+		t.Errorf("result with the file removed = %d, want 404", w.Code)
+	}
+}
+
+func TestJobHandler_ResultWithoutFile(t *testing.T) {
+	ctx := context.Background()
+	pool := newJobTestPool("")
+	router := setupJobTestRouter(NewJobHandler(pool), nil)
+	queued, _ := pool.Enqueue(ctx, shared_jobs.NewJob("test", nil, nil, ""))
+	pool.RunNext(ctx)
+
+	if w := serveJobRequest(router, http.MethodGet, "/api/v1/jobs/"+queued.ID+"/result"); w.Code != http.StatusNotFound {
+		t.Errorf("result of a job without file = %d, want 404", w.Code)
+	}
+}
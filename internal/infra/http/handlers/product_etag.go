@@ -0,0 +1,77 @@
+package product_handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	product_entity "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/entity"
+	product_errors "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/errors"
+	http_middleware "github.com/williamkoller/golang-domain-driven-design/internal/infra/http/middleware"
+)
+
+// errIfMatchRequired é respondido com 428 quando uma alteração chega sem o header If-Match
+var errIfMatchRequired = errors.New("If-Match header is required")
+
+// productETag identifica a versão do produto. O ID entra na tag para que a versão de um
+// produto não valha para outro que passe a usar o mesmo nome.
+func productETag(product product_entity.Product) string {
+	return fmt.Sprintf(`"%s-%d"`, product.ID, product.Version)
+}
+
+// respondProduct responde o produto com o seu ETag
+func respondProduct(c *gin.Context, status int, product product_entity.Product) {
+	c.Header("ETag", productETag(product))
+	c.JSON(status, product)
+}
+
+// respondProductIfModified responde o produto, ou 304 sem corpo se o If-None-Match
+// do cliente já tem a versão atual
+func respondProductIfModified(c *gin.Context, product product_entity.Product) {
+	etag := productETag(product)
+	if header := c.GetHeader("If-None-Match"); header != "" && etagListContains(header, etag, true) {
+		c.Header("ETag", etag)
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	respondProduct(c, http.StatusOK, product)
+}
+
+// checkIfMatch exige que o If-Match da requisição tenha a versão atual do produto:
+// sem o header a alteração é recusada com 428 e com outra versão com ErrVersionConflict (412)
+func checkIfMatch(c *gin.Context, product product_entity.Product) error {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		return http_middleware.WithStatus(http.StatusPreconditionRequired, errIfMatchRequired)
+	}
+	if !etagListContains(header, productETag(product), false) {
+		return product_errors.ErrVersionConflict
+	}
+	return nil
+}
+
+// etagListContains indica se a lista de tags do header (ou "*") casa com etag. A comparação
+// fraca (If-None-Match) ignora o prefixo W/; a forte (If-Match) não aceita tags fracas.
+func etagListContains(header, etag string, weak bool) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if strings.HasPrefix(tag, "W/") {
+			if !weak {
+				continue
+			}
+			tag = strings.TrimPrefix(tag, "W/")
+		}
+		if tag == etag {
+			return true
+		}
+	}
+
+	return false
+}
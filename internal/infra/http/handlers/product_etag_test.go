@@ -0,0 +1,107 @@
+package product_handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	product_entity "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/entity"
+	product_valueobject "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/valueobject"
+)
+
+func TestEtagListContains(t *testing.T) {
+	etag := `"id-1-2"`
+
+	tests := []struct {
+		name   string
+		header string
+		weak   bool
+		want   bool
+	}{
+		{name: "same tag", header: `"id-1-2"`, want: true},
+		{name: "other version", header: `"id-1-1"`, want: false},
+		{name: "list with the tag", header: `"id-1-1", "id-1-2"`, want: true},
+		{name: "wildcard", header: "*", want: true},
+		{name: "weak tag in strong comparison", header: `W/"id-1-2"`, want: false},
+		{name: "weak tag in weak comparison", header: `W/"id-1-2"`, weak: true, want: true},
+		{name: "unquoted tag", header: "id-1-2", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := etagListContains(tt.header, etag, tt.weak); got != tt.want {
+				t.Errorf("etagListContains(%q) = %v, want %v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProductHandler_ConditionalGet(t *testing.T) {
+	mockRepo := NewMockProductRepository()
+	mockRepo.products["Notebook"] = product_entity.Product{
+		ID:         "3f2b8c1e-4d5a-4b6c-8d7e-9f0a1b2c3d4e",
+		Name:       "Notebook",
+		Sku:        12345,
+		Categories: []string{"Electronics"},
+		Price:      product_valueobject.MustBRL(3500),
+		Version:    4,
+	}
+	router := setupTestRouter(NewProductHandler(mockRepo, createTestMetrics("conditional_get")))
+	etag := `"3f2b8c1e-4d5a-4b6c-8d7e-9f0a1b2c3d4e-4"`
+
+	paths := []string{
+		"/api/v1/products/Notebook",
+		"/api/v1/products/id/3f2b8c1e-4d5a-4b6c-8d7e-9f0a1b2c3d4e",
+		"/api/v1/products/sku/12345",
+	}
+
+	for _, path := range paths {
+		t.Run(path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+			if w.Code != http.StatusOK || w.Header().Get("ETag") != etag {
+				t.Fatalf("GET = %d with ETag %s, want 200 with %s", w.Code, w.Header().Get("ETag"), etag)
+			}
+
+			for header, want := range map[string]int{
+				etag:                   http.StatusNotModified,
+				"W/" + etag:            http.StatusNotModified,
+				`"other", ` + etag:     http.StatusNotModified,
+				`"3f2b8c1e-4d5a-4b6c"`: http.StatusOK,
+			} {
+				req := httptest.NewRequest(http.MethodGet, path, nil)
+				req.Header.Set("If-None-Match", header)
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+
+				if w.Code != want {
+					t.Errorf("If-None-Match %s: status = %d, want %d", header, w.Code, want)
+				}
+				if want == http.StatusNotModified && (w.Body.Len() != 0 || w.Header().Get("ETag") != etag) {
+					t.Errorf("If-None-Match %s: 304 with body %q and ETag %s", header, w.Body.String(), w.Header().Get("ETag"))
+				}
+			}
+		})
+	}
+}
+
+func TestProductHandler_CreateReturnsETag(t *testing.T) {
+	mockRepo := NewMockProductRepository()
+	router := setupTestRouter(NewProductHandler(mockRepo, createTestMetrics("create_etag")))
+
+	body, _ := json.Marshal(CreateProductInput{Name: "Notebook", Sku: 12345, Categories: []string{"Electronics"}, Price: 3500})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/products", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var product product_entity.Product
+	if err := json.Unmarshal(w.Body.Bytes(), &product); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if product.Version != 1 || w.Header().Get("ETag") != productETag(product) {
+		t.Errorf("Create() version %d with ETag %s, want version 1 with %s", product.Version, w.Header().Get("ETag"), productETag(product))
+	}
+}
@@ -0,0 +1,329 @@
+package product_handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	product_entity "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/entity"
+	product_repository "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/repository"
+	http_middleware "github.com/williamkoller/golang-domain-driven-design/internal/infra/http/middleware"
+)
+
+// Formatos da exportação
+const (
+	ExportFormatCSV    = "csv"
+	ExportFormatNDJSON = "ndjson"
+	ExportFormatJSON   = "json"
+)
+
+// Formas de achatar a lista de categorias no CSV
+const (
+	ExportCategoriesJoin = "join" // Uma coluna com as categorias unidas pelo separador
+	ExportCategoriesRows = "rows" // Uma linha por categoria, repetindo os dados do produto
+)
+
+// exportFlushEvery é a quantidade de produtos entre os envios parciais da resposta
+const exportFlushEvery = 100
+
+// exportFormats traz o Content-Type de cada formato da exportação
+var exportFormats = map[string]string{
+	ExportFormatCSV:    "text/csv; charset=utf-8",
+	ExportFormatNDJSON: ContentTypeNDJSON,
+	ExportFormatJSON:   "application/json; charset=utf-8",
+}
+
+// csvExportColumns são as colunas do CSV exportado; name, sku, categories, price e currency
+// seguem o formato da importação, para que o arquivo possa ser importado de volta
+var csvExportColumns = []string{"id", "name", "sku", "categories", "price", "currency", "created_at", "deleted_at", "version"}
+
+// productExporter escreve os produtos exportados em um formato
+type productExporter interface {
+	Write(product product_entity.Product) error
+	// Close escreve o final do arquivo
+	Close() error
+}
+
+// ExportOptions configura o CSV exportado: como as categorias são achatadas e se o
+// arquivo começa com o BOM do UTF-8, que faz planilhas como o Excel lerem os acentos
+type ExportOptions struct {
+	Categories        string
+	CategorySeparator string
+	BOM               bool
+}
+
+// Export godoc
+//
+//	@Summary		Exportar o catálogo
+//	@Description	Exporta os produtos que satisfazem os filtros da listagem como download em CSV, NDJSON ou JSON. Os produtos são lidos do banco com um cursor e enviados aos poucos, sem carregar o catálogo inteiro em memória.
+//	@Tags			products
+//	@Produce		text/csv,application/x-ndjson,json
+//	@Param			format				query		string	false	"Formato do arquivo (padrão csv)"	Enums(csv, ndjson, json)
+//	@Param			categories			query		string	false	"CSV: categorias em uma coluna (join, padrão) ou uma linha por categoria (rows)"	Enums(join, rows)
+//	@Param			category_separator	query		string	false	"CSV: separador das categorias no modo join (padrão |)"
+//	@Param			bom					query		bool	false	"CSV: iniciar o arquivo com o BOM do UTF-8, para abrir no Excel"
+//	@Param			category			query		string	false	"Filtrar por categoria"
+//	@Param			currency			query		string	false	"Moeda dos preços (BRL, USD...); obrigatória com min_price, max_price e sort por preço"
+//	@Param			min_price			query		int		false	"Preço mínimo (unidades menores da moeda)"
+//	@Param			max_price			query		int		false	"Preço máximo (unidades menores da moeda)"
+//	@Param			sku					query		int		false	"Filtrar por SKU"
+//	@Param			sort				query		string	false	"Ordenação: price, -price, name ou created_at (padrão: mais recentes primeiro)"
+//	@Param			include_deleted		query		bool	false	"Incluir produtos excluídos"
+//	@Success		200					{file}		file
+//	@Header			200					{string}	Content-Disposition	"attachment; filename=\"products-20261017-090000.csv\""
+//	@Failure		400					{object}	http_middleware.ProblemDetails
+//	@Failure		503					{object}	http_middleware.ProblemDetails
+//	@Router			/products/export [get]
+func (h *ProductHandler) Export(c *gin.Context) {
+	format := c.DefaultQuery("format", ExportFormatCSV)
+	contentType, ok := exportFormats[format]
+	if !ok {
+		c.Error(http_middleware.BadRequest(fmt.Errorf("format must be %s, %s or %s", ExportFormatCSV, ExportFormatNDJSON, ExportFormatJSON)))
+		return
+	}
+
+	options, err := parseExportOptions(c)
+	if err != nil {
+		c.Error(http_middleware.BadRequest(err))
+		return
+	}
+
+	criteria, err := parseProductFilters(c)
+	if err != nil {
+		c.Error(http_middleware.BadRequest(err))
+		return
+	}
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", contentType)
+	header.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="products-%s.%s"`, time.Now().UTC().Format("20060102-150405"), format))
+	// Desliga o buffer de proxies como o nginx, para o download começar logo
+	header.Set("X-Accel-Buffering", "no")
+
+	// Nada é enviado antes do primeiro envio parcial: uma falha ao abrir a consulta
+	// ainda pode ser respondida como um problema
+	w := bufio.NewWriterSize(c.Writer, 32*1024)
+	count, err := h.exportProducts(c.Request.Context(), w, format, options, criteria, func(int) error {
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		c.Writer.Flush()
+		return nil
+	})
+	if err == nil {
+		err = w.Flush()
+	}
+
+	if err != nil {
+		c.Error(err)
+		if !c.Writer.Written() {
+			header.Del("Content-Disposition")
+			return
+		}
+
+		// Parte do arquivo já foi enviada com status 200: a conexão é encerrada sem o final
+		// da resposta, para o cliente não tomar o arquivo truncado por completo
+		log.Printf("❌ Exportação interrompida após %d produtos: %v", count, err)
+		abortConnection(c)
+		return
+	}
+
+	c.Status(http.StatusOK)
+	c.Writer.WriteHeaderNow()
+}
+
+// exportProducts escreve em w, no formato pedido, os produtos que satisfazem os critérios e
+// retorna quantos foram escritos. checkpoint é chamada a cada exportFlushEvery produtos com
+// a quantidade já escrita; um erro dela interrompe a exportação.
+func (h *ProductHandler) exportProducts(ctx context.Context, w io.Writer, format string, options ExportOptions, criteria product_repository.ProductCriteria, checkpoint func(count int) error) (int, error) {
+	exporter := newProductExporter(format, w, options)
+
+	count := 0
+	err := h.repo.Export(ctx, criteria, func(product product_entity.Product) error {
+		if err := exporter.Write(product); err != nil {
+			return err
+		}
+
+		count++
+		if count%exportFlushEvery == 0 {
+			return checkpoint(count)
+		}
+		return nil
+	})
+	if err != nil {
+		return count, err
+	}
+
+	return count, exporter.Close()
+}
+
+// parseExportOptions lê as opções do CSV exportado
+func parseExportOptions(c *gin.Context) (ExportOptions, error) {
+	options := ExportOptions{
+		Categories:        c.DefaultQuery("categories", ExportCategoriesJoin),
+		CategorySeparator: c.DefaultQuery("category_separator", csvCategorySeparator),
+	}
+
+	if options.Categories != ExportCategoriesJoin && options.Categories != ExportCategoriesRows {
+		return options, fmt.Errorf("categories must be %s or %s", ExportCategoriesJoin, ExportCategoriesRows)
+	}
+	if options.CategorySeparator == "" {
+		return options, errors.New("category_separator must not be empty")
+	}
+
+	if value := c.Query("bom"); value != "" {
+		bom, err := strconv.ParseBool(value)
+		if err != nil {
+			return options, errors.New("bom must be a boolean")
+		}
+		options.BOM = bom
+	}
+
+	return options, nil
+}
+
+// abortConnection fecha a conexão do cliente no meio da resposta
+func abortConnection(c *gin.Context) {
+	conn, _, err := c.Writer.Hijack()
+	if err != nil {
+		return
+	}
+	conn.Close()
+}
+
+func newProductExporter(format string, w io.Writer, options ExportOptions) productExporter {
+	switch format {
+	case ExportFormatNDJSON:
+		return &ndjsonExporter{encoder: json.NewEncoder(w)}
+	case ExportFormatJSON:
+		return &jsonExporter{w: w, encoder: json.NewEncoder(w)}
+	default:
+		return &csvExporter{w: w, csv: csv.NewWriter(w), options: options}
+	}
+}
+
+// csvExporter escreve um produto por linha, ou uma linha por categoria no modo rows
+type csvExporter struct {
+	w       io.Writer
+	csv     *csv.Writer
+	options ExportOptions
+	started bool
+}
+
+func (e *csvExporter) start() error {
+	e.started = true
+	if e.options.BOM {
+		if _, err := io.WriteString(e.w, "\ufeff"); err != nil {
+			return err
+		}
+	}
+
+	columns := csvExportColumns
+	if e.options.Categories == ExportCategoriesRows {
+		columns = append([]string(nil), csvExportColumns...)
+		columns[3] = "category"
+	}
+	return e.csv.Write(columns)
+}
+
+func (e *csvExporter) Write(product product_entity.Product) error {
+	if !e.started {
+		if err := e.start(); err != nil {
+			return err
+		}
+	}
+
+	var deletedAt string
+	if product.DeletedAt != nil {
+		deletedAt = product.DeletedAt.UTC().Format(time.RFC3339)
+	}
+	record := []string{
+		product.ID,
+		product.Name,
+		strconv.Itoa(product.Sku),
+		strings.Join(product.Categories, e.options.CategorySeparator),
+		strconv.FormatInt(product.Price.Amount(), 10),
+		product.Price.Currency(),
+		product.CreatedAt.UTC().Format(time.RFC3339),
+		deletedAt,
+		strconv.Itoa(product.Version),
+	}
+
+	if e.options.Categories == ExportCategoriesRows && len(product.Categories) > 0 {
+		for _, category := range product.Categories {
+			record[3] = category
+			if err := e.csv.Write(record); err != nil {
+				return err
+			}
+		}
+	} else if err := e.csv.Write(record); err != nil {
+		return err
+	}
+
+	// O csv.Writer tem o próprio buffer; esvaziá-lo a cada linha deixa o controle dos envios ao handler
+	e.csv.Flush()
+	return e.csv.Error()
+}
+
+func (e *csvExporter) Close() error {
+	// Um catálogo vazio ainda recebe o cabeçalho
+	if !e.started {
+		if err := e.start(); err != nil {
+			return err
+		}
+	}
+	e.csv.Flush()
+	return e.csv.Error()
+}
+
+// ndjsonExporter escreve um produto JSON por linha
+type ndjsonExporter struct {
+	encoder *json.Encoder
+}
+
+func (e *ndjsonExporter) Write(product product_entity.Product) error {
+	return e.encoder.Encode(product)
+}
+
+func (e *ndjsonExporter) Close() error {
+	return nil
+}
+
+// jsonExporter escreve um array JSON, um produto por linha
+type jsonExporter struct {
+	w       io.Writer
+	encoder *json.Encoder
+	count   int
+}
+
+func (e *jsonExporter) Write(product product_entity.Product) error {
+	separator := ","
+	if e.count == 0 {
+		separator = "["
+	}
+	e.count++
+
+	if _, err := io.WriteString(e.w, separator); err != nil {
+		return err
+	}
+	return e.encoder.Encode(product)
+}
+
+func (e *jsonExporter) Close() error {
+	closing := "]\n"
+	if e.count == 0 {
+		closing = "[]\n"
+	}
+	_, err := io.WriteString(e.w, closing)
+	return err
+}
@@ -0,0 +1,170 @@
+package product_handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	product_entity "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/entity"
+	product_errors "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/errors"
+	product_valueobject "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/valueobject"
+	http_middleware "github.com/williamkoller/golang-domain-driven-design/internal/infra/http/middleware"
+)
+
+func newExportTestRepository() *MockProductRepository {
+	createdAt := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	deletedAt := createdAt.Add(time.Hour)
+
+	mockRepo := NewMockProductRepository()
+	mockRepo.products["Notebook"] = product_entity.Product{ID: "id-1", Name: "Notebook", Sku: 1, Categories: []string{"Computers", "Electronics"}, Price: product_valueobject.MustBRL(350000), CreatedAt: createdAt, Version: 2}
+	mockRepo.products["Mouse, Wireless"] = product_entity.Product{ID: "id-2", Name: "Mouse, Wireless", Sku: 2, Categories: []string{"Accessories"}, Price: product_valueobject.MustBRL(5000), CreatedAt: createdAt.Add(time.Minute), Version: 1}
+	mockRepo.products["Old Phone"] = product_entity.Product{ID: "id-3", Name: "Old Phone", Sku: 3, Categories: []string{"Electronics"}, Price: product_valueobject.MustBRL(90000), CreatedAt: createdAt.Add(2 * time.Minute), DeletedAt: &deletedAt, Version: 2}
+	return mockRepo
+}
+
+func getExport(router http.Handler, query string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/products/export"+query, nil))
+	return w
+}
+
+func TestProductHandler_Export(t *testing.T) {
+	router := setupTestRouter(NewProductHandler(newExportTestRepository(), createTestMetrics("export")))
+
+	t.Run("CSV with the listing filters", func(t *testing.T) {
+		w := getExport(router, "?sort=name")
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200 (%s)", w.Code, w.Body.String())
+		}
+		if w.Header().Get("Content-Type") != "text/csv; charset=utf-8" {
+			t.Errorf("Content-Type = %s", w.Header().Get("Content-Type"))
+		}
+		if disposition := w.Header().Get("Content-Disposition"); !strings.HasPrefix(disposition, `attachment; filename="products-`) || !strings.HasSuffix(disposition, `.csv"`) {
+			t.Errorf("Content-Disposition = %s", disposition)
+		}
+
+		records, err := csv.NewReader(w.Body).ReadAll()
+		if err != nil {
+			t.Fatalf("invalid CSV: %v", err)
+		}
+		want := [][]string{
+			csvExportColumns,
+			{"id-2", "Mouse, Wireless", "2", "Accessories", "5000", "BRL", "2026-10-17T09:01:00Z", "", "1"},
+			{"id-1", "Notebook", "1", "Computers|Electronics", "350000", "BRL", "2026-10-17T09:00:00Z", "", "2"},
+		}
+		if len(records) != len(want) {
+			t.Fatalf("records = %v, want %v", records, want)
+		}
+		for i := range want {
+			if strings.Join(records[i], ";") != strings.Join(want[i], ";") {
+				t.Errorf("record %d = %v, want %v", i, records[i], want[i])
+			}
+		}
+	})
+
+	t.Run("CSV with one row per category", func(t *testing.T) {
+		w := getExport(router, "?category=Electronics&include_deleted=true&sort=name&categories=rows&bom=true")
+
+		body := w.Body.String()
+		if !strings.HasPrefix(body, "\ufeffid,name,sku,category,") {
+			t.Fatalf("body = %q, want the BOM and the category column", body)
+		}
+		records, _ := csv.NewReader(strings.NewReader(strings.TrimPrefix(body, "\ufeff"))).ReadAll()
+		if len(records) != 4 || records[1][3] != "Computers" || records[2][3] != "Electronics" || records[3][0] != "id-3" || records[3][7] != "2026-10-17T10:00:00Z" {
+			t.Errorf("records = %v", records)
+		}
+	})
+
+	t.Run("CSV with a custom separator", func(t *testing.T) {
+		w := getExport(router, "?sku=1&category_separator=%3B")
+		records, _ := csv.NewReader(w.Body).ReadAll()
+		if len(records) != 2 || records[1][3] != "Computers;Electronics" {
+			t.Errorf("records = %v", records)
+		}
+	})
+
+	t.Run("NDJSON", func(t *testing.T) {
+		w := getExport(router, "?format=ndjson&currency=BRL&sort=-price")
+		if w.Header().Get("Content-Type") != ContentTypeNDJSON || !strings.HasSuffix(w.Header().Get("Content-Disposition"), `.ndjson"`) {
+			t.Errorf("headers = %v", w.Header())
+		}
+
+		lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+		var first product_entity.Product
+		if len(lines) != 2 || json.Unmarshal([]byte(lines[0]), &first) != nil || first.Name != "Notebook" || len(first.Categories) != 2 {
+			t.Errorf("lines = %v", lines)
+		}
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		w := getExport(router, "?format=json&include_deleted=true")
+
+		var products []product_entity.Product
+		if err := json.Unmarshal(w.Body.Bytes(), &products); err != nil || len(products) != 3 {
+			t.Fatalf("JSON array = %d products, %v (%s)", len(products), err, w.Body.String())
+		}
+		// Padrão da listagem: mais recentes primeiro
+		if products[0].ID != "id-3" || products[2].ID != "id-1" {
+			t.Errorf("order = %s, %s, %s", products[0].ID, products[1].ID, products[2].ID)
+		}
+	})
+
+	t.Run("empty catalog", func(t *testing.T) {
+		for query, want := range map[string]string{
+			"?format=json":   "[]\n",
+			"?format=ndjson": "",
+			"?format=csv":    strings.Join(csvExportColumns, ",") + "\n",
+		} {
+			w := getExport(router, query+"&category=Books")
+			if w.Code != http.StatusOK || w.Body.String() != want {
+				t.Errorf("%s: %d %q, want 200 %q", query, w.Code, w.Body.String(), want)
+			}
+		}
+	})
+}
+
+func TestProductHandler_ExportErrors(t *testing.T) {
+	t.Run("invalid parameters", func(t *testing.T) {
+		router := setupTestRouter(NewProductHandler(newExportTestRepository(), createTestMetrics("export_invalid")))
+
+		for _, query := range []string{"?format=xlsx", "?categories=columns", "?category_separator=", "?bom=maybe", "?sort=sku", "?min_price=10&max_price=1"} {
+			w := getExport(router, query)
+			if w.Code != http.StatusBadRequest || w.Header().Get("Content-Disposition") != "" {
+				t.Errorf("%s: status = %d with Content-Disposition %q, want 400 without download", query, w.Code, w.Header().Get("Content-Disposition"))
+			}
+		}
+	})
+
+	t.Run("failure before the first row", func(t *testing.T) {
+		mockRepo := NewMockProductRepository()
+		mockRepo.exportError = product_errors.Unavailable(errors.New("connection refused"))
+		router := setupTestRouter(NewProductHandler(mockRepo, createTestMetrics("export_unavailable")))
+
+		w := getExport(router, "")
+		if w.Code != http.StatusServiceUnavailable || w.Header().Get("Content-Type") != http_middleware.ProblemContentType || w.Header().Get("Content-Disposition") != "" {
+			t.Errorf("status = %d with headers %v, want a 503 problem", w.Code, w.Header())
+		}
+	})
+
+	t.Run("failure after rows were sent", func(t *testing.T) {
+		mockRepo := NewMockProductRepository()
+		for i := 1; i <= exportFlushEvery; i++ {
+			name := fmt.Sprintf("Product %03d", i)
+			mockRepo.products[name] = product_entity.Product{ID: name, Name: name, Sku: i, Categories: []string{"A"}, Price: product_valueobject.MustBRL(100)}
+		}
+		mockRepo.exportError = product_errors.Unavailable(errors.New("connection reset"))
+		router := setupTestRouter(NewProductHandler(mockRepo, createTestMetrics("export_interrupted")))
+
+		w := getExport(router, "?format=json")
+		// O status 200 já foi enviado; o array fica sem o fechamento
+		if w.Code != http.StatusOK || strings.HasSuffix(strings.TrimSpace(w.Body.String()), "]") {
+			t.Errorf("status = %d, body ends with %q", w.Code, w.Body.String()[max(0, w.Body.Len()-20):])
+		}
+	})
+}
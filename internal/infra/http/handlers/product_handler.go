@@ -1,23 +1,64 @@
 package product_handlers
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	category_entity "github.com/williamkoller/golang-domain-driven-design/internal/domain/category/entity"
+	category_repository "github.com/williamkoller/golang-domain-driven-design/internal/domain/category/repository"
 	product_entity "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/entity"
+	product_errors "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/errors"
 	product_repository "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/repository"
+	product_valueobject "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/valueobject"
+	http_middleware "github.com/williamkoller/golang-domain-driven-design/internal/infra/http/middleware"
 	"github.com/williamkoller/golang-domain-driven-design/internal/metrics"
 	shared_events "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/events"
+	shared_identity "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/identity"
+)
+
+// Erros do domínio de produtos; ErrVersionConflict também é um ErrConflict e por isso vem antes
+func init() {
+	http_middleware.RegisterErrors(
+		http_middleware.ErrorMapping{Kind: product_errors.ErrValidation, Status: http.StatusBadRequest, Problem: "validation-error", Title: "Validation failed"},
+		http_middleware.MapNotFound(product_errors.ErrNotFound),
+		http_middleware.MapAlreadyExists(product_errors.ErrAlreadyExists),
+		http_middleware.ErrorMapping{Kind: product_errors.ErrVersionConflict, Status: http.StatusPreconditionFailed, Problem: "precondition-failed", Title: "Precondition failed"},
+		http_middleware.MapConflict(product_errors.ErrConflict),
+		http_middleware.MapUnavailable(product_errors.ErrUnavailable),
+		http_middleware.MapInvalid(product_repository.ErrCurrencyRequired),
+	)
+}
+
+// Limites de paginação da listagem de produtos
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
 )
 
 type ProductHandler struct {
-	repo       product_repository.IProductRepository
-	dispatcher *shared_events.EventDispatcher
-	metrics    *metrics.Metrics
+	repo    product_repository.IProductRepository
+	metrics *metrics.Metrics
+	// categories, quando configurado, valida ou cadastra as categorias dos produtos gravados
+	categories       category_repository.ICategoryRepository
+	strictCategories bool
 }
 
-func NewProductHandler(repo product_repository.IProductRepository, dispatcher *shared_events.EventDispatcher, m *metrics.Metrics) *ProductHandler {
-	return &ProductHandler{repo, dispatcher, m}
+func NewProductHandler(repo product_repository.IProductRepository, m *metrics.Metrics) *ProductHandler {
+	return &ProductHandler{repo: repo, metrics: m}
+}
+
+// SetCategories passa a conferir as categorias dos produtos criados e alterados. No modo
+// estrito, uma categoria não cadastrada é rejeitada como violação do campo categories; fora
+// dele, as categorias novas são cadastradas na raiz da hierarquia.
+func (h *ProductHandler) SetCategories(categories category_repository.ICategoryRepository, strict bool) {
+	h.categories = categories
+	h.strictCategories = strict
 }
 
 // CreateProductInput representa os dados de entrada para criar um produto
@@ -25,12 +66,52 @@ type CreateProductInput struct {
 	Name       string   `json:"name" binding:"required" example:"Notebook"`
 	Sku        int      `json:"sku" binding:"required" example:"12345"`
 	Categories []string `json:"categories" binding:"required" example:"Eletrônicos,Computadores"`
-	Price      int      `json:"price" binding:"required" example:"3500"`
+	Price      int64    `json:"price" binding:"required" example:"350000"`
+	Currency   string   `json:"currency,omitempty" example:"BRL"`
+}
+
+// UpdateProductInput representa os dados de entrada para substituir um produto
+type UpdateProductInput struct {
+	Name       string   `json:"name" binding:"required" example:"Notebook"`
+	Sku        int      `json:"sku" binding:"required" example:"12345"`
+	Categories []string `json:"categories" binding:"required" example:"Eletrônicos,Computadores"`
+	Price      int64    `json:"price" binding:"required" example:"350000"`
+	Currency   string   `json:"currency,omitempty" example:"BRL"`
+}
+
+// PatchProductInput representa os dados de entrada para alterar parcialmente um produto
+type PatchProductInput struct {
+	Name       *string  `json:"name,omitempty" example:"Notebook"`
+	Sku        *int     `json:"sku,omitempty" example:"12345"`
+	Categories []string `json:"categories,omitempty" example:"Eletrônicos,Computadores"`
+	Price      *int64   `json:"price,omitempty" example:"350000"`
+	Currency   *string  `json:"currency,omitempty" example:"BRL"`
+}
+
+// ProductListResponse representa uma página da listagem de produtos
+type ProductListResponse struct {
+	Items      []product_entity.Product `json:"items"`
+	NextCursor string                   `json:"next_cursor,omitempty" example:"eyJ2IjoiMzUwMCIsImlkIjoiLi4uIn0"`
+	Total      int                      `json:"total" example:"42"`
+}
+
+// ProductSearchResponse representa o resultado de uma busca textual, do mais ao menos relevante
+type ProductSearchResponse struct {
+	Query string                   `json:"query" example:"notebook"`
+	Items []product_entity.Product `json:"items"`
+}
+
+// ProductChangeResponse representa um produto alterado no change feed, com o estado atual
+type ProductChangeResponse struct {
+	Type    string                 `json:"type" example:"updated" enums:"created,updated,deleted"`
+	Product product_entity.Product `json:"product"`
 }
 
-// ErrorResponse representa uma resposta de erro
-type ErrorResponse struct {
-	Error string `json:"error" example:"error message"`
+// ProductChangeListResponse representa uma página do change feed, em ordem de commit
+type ProductChangeListResponse struct {
+	Items     []ProductChangeResponse `json:"items"`
+	NextToken string                  `json:"next_token" example:"eyJzIjo0Mn0"`
+	HasMore   bool                    `json:"has_more" example:"false"`
 }
 
 // Create godoc
@@ -42,46 +123,166 @@ type ErrorResponse struct {
 //	@Produce		json
 //	@Param			product	body		CreateProductInput	true	"Dados do produto"
 //	@Success		201		{object}	product_entity.Product
-//	@Failure		400		{object}	ErrorResponse
-//	@Failure		409		{object}	ErrorResponse
+//	@Header			201		{string}	ETag	"Versão do produto"
+//	@Failure		400		{object}	http_middleware.ProblemDetails
+//	@Failure		409		{object}	http_middleware.ProblemDetails
+//	@Failure		503		{object}	http_middleware.ProblemDetails
 //	@Router			/products [post]
 func (h *ProductHandler) Create(c *gin.Context) {
 	var input CreateProductInput
 
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(http_middleware.BindingError(err, &input))
 		return
 	}
 
-	product, _, err := product_entity.NewProduct(input.Name, input.Sku, input.Categories, input.Price, h.dispatcher)
+	price, err := newPrice(input.Price, input.Currency)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	if err := h.repo.Add(*product); err != nil {
-		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	product, err := product_entity.NewProduct(input.Name, input.Sku, input.Categories, price)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if err := h.checkCategories(c.Request.Context(), product.Categories); err != nil {
+		c.Error(err)
 		return
 	}
 
-	// Atualizar métricas de negócio
+	// Os eventos são publicados pelo repositório somente se a gravação for confirmada
+	events := pendingEvents(c, product)
+	if err := h.repo.Add(c.Request.Context(), *product, events...); err != nil {
+		c.Error(err)
+		return
+	}
+	h.ensureCategories(c.Request.Context(), product.Categories)
+
+	// Os gauges de negócio são atualizados pela BusinessProjection a partir dos eventos
 	h.metrics.IncrementProductsCreated()
-	h.updateBusinessMetrics()
 
-	c.JSON(http.StatusCreated, product)
+	respondProduct(c, http.StatusCreated, *product)
 }
 
 // FindAll godoc
 //
-//	@Summary		Listar todos os produtos
-//	@Description	Retorna uma lista com todos os produtos cadastrados
+//	@Summary		Listar produtos
+//	@Description	Retorna uma página de produtos, com filtros, ordenação e paginação por cursor
 //	@Tags			products
 //	@Produce		json
-//	@Success		200	{array}	product_entity.Product
+//	@Param			limit			query		int		false	"Itens por página (padrão 20, máximo 100)"
+//	@Param			after			query		string	false	"Cursor retornado em next_cursor"
+//	@Param			category		query		string	false	"Filtrar por categoria"
+//	@Param			currency		query		string	false	"Moeda dos preços (BRL, USD...); obrigatória com min_price, max_price e sort por preço"
+//	@Param			min_price		query		int		false	"Preço mínimo (unidades menores da moeda)"
+//	@Param			max_price		query		int		false	"Preço máximo (unidades menores da moeda)"
+//	@Param			sku				query		int		false	"Filtrar por SKU"
+//	@Param			sort			query		string	false	"Ordenação: price, -price, name ou created_at (padrão: mais recentes primeiro)"
+//	@Param			include_deleted	query		bool	false	"Incluir produtos excluídos"
+//	@Success		200				{object}	ProductListResponse
+//	@Failure		400				{object}	http_middleware.ProblemDetails
+//	@Failure		500				{object}	http_middleware.ProblemDetails
+//	@Failure		503				{object}	http_middleware.ProblemDetails
 //	@Router			/products [get]
 func (h *ProductHandler) FindAll(c *gin.Context) {
-	products, _ := h.repo.Find()
-	c.JSON(http.StatusOK, products)
+	criteria, err := parseProductCriteria(c)
+	if err != nil {
+		c.Error(http_middleware.BadRequest(err))
+		return
+	}
+
+	page, err := h.repo.Find(c.Request.Context(), criteria)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ProductListResponse{
+		Items:      page.Products,
+		NextCursor: page.NextCursor,
+		Total:      page.Total,
+	})
+}
+
+// Search godoc
+//
+//	@Summary		Buscar produtos por texto
+//	@Description	Busca produtos ativos por nome e categoria, sem diferenciar acentos, ordenados por relevância
+//	@Tags			products
+//	@Produce		json
+//	@Param			q		query		string	true	"Termos da busca"
+//	@Param			limit	query		int		false	"Máximo de resultados (padrão 20, máximo 100)"
+//	@Success		200		{object}	ProductSearchResponse
+//	@Failure		400		{object}	http_middleware.ProblemDetails
+//	@Failure		500		{object}	http_middleware.ProblemDetails
+//	@Failure		503		{object}	http_middleware.ProblemDetails
+//	@Router			/products/search [get]
+func (h *ProductHandler) Search(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		c.Error(http_middleware.BadRequest(errors.New("q is required")))
+		return
+	}
+
+	limit, err := parseLimit(c)
+	if err != nil {
+		c.Error(http_middleware.BadRequest(err))
+		return
+	}
+
+	products, err := h.repo.Search(c.Request.Context(), query, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ProductSearchResponse{Query: query, Items: products})
+}
+
+// Changes godoc
+//
+//	@Summary		Change feed de produtos
+//	@Description	Retorna os produtos criados, atualizados ou excluídos depois do token, em ordem de commit, com o estado atual de cada um. Sem since, começa do início. Guarde next_token e use-o como since na próxima consulta; has_more indica que há mais alterações disponíveis agora.
+//	@Tags			products
+//	@Produce		json
+//	@Param			since	query		string	false	"Token retornado em next_token"
+//	@Param			limit	query		int		false	"Alterações por página (padrão 20, máximo 100)"
+//	@Success		200		{object}	ProductChangeListResponse
+//	@Failure		400		{object}	http_middleware.ProblemDetails
+//	@Failure		500		{object}	http_middleware.ProblemDetails
+//	@Failure		503		{object}	http_middleware.ProblemDetails
+//	@Router			/products/changes [get]
+func (h *ProductHandler) Changes(c *gin.Context) {
+	since, err := product_repository.DecodeChangeToken(c.Query("since"))
+	if err != nil {
+		c.Error(http_middleware.BadRequest(err))
+		return
+	}
+
+	limit, err := parseLimit(c)
+	if err != nil {
+		c.Error(http_middleware.BadRequest(err))
+		return
+	}
+
+	page, err := h.repo.Changes(c.Request.Context(), since.Sequence, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	items := make([]ProductChangeResponse, len(page.Changes))
+	for i, change := range page.Changes {
+		items[i] = ProductChangeResponse{Type: change.Type, Product: change.Product}
+	}
+
+	c.JSON(http.StatusOK, ProductChangeListResponse{
+		Items:     items,
+		NextToken: page.NextToken,
+		HasMore:   page.HasMore,
+	})
 }
 
 // FindOne godoc
@@ -90,36 +291,508 @@ func (h *ProductHandler) FindAll(c *gin.Context) {
 //	@Description	Retorna um produto específico pelo nome
 //	@Tags			products
 //	@Produce		json
-//	@Param			name	path		string	true	"Nome do produto"
-//	@Success		200		{object}	product_entity.Product
-//	@Failure		404		{object}	ErrorResponse
+//	@Param			name			path		string	true	"Nome do produto"
+//	@Param			include_deleted	query		bool	false	"Incluir produtos excluídos"
+//	@Param			If-None-Match	header		string	false	"ETag já conhecido pelo cliente"
+//	@Success		200				{object}	product_entity.Product
+//	@Header			200				{string}	ETag	"Versão do produto"
+//	@Success		304
+//	@Failure		404				{object}	http_middleware.ProblemDetails
+//	@Failure		503				{object}	http_middleware.ProblemDetails
 //	@Router			/products/{name} [get]
 func (h *ProductHandler) FindOne(c *gin.Context) {
 	name := c.Param("name")
-	product, err := h.repo.FindOne(name)
+	product, err := h.repo.FindOne(c.Request.Context(), name, includeDeleted(c))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "product not found"})
+		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusOK, product)
+	respondProductIfModified(c, product)
 }
 
-// updateBusinessMetrics atualiza todas as métricas de negócio
-func (h *ProductHandler) updateBusinessMetrics() {
-	repoMetrics := h.repo.GetMetrics()
+// FindByID godoc
+//
+//	@Summary		Buscar produto por ID
+//	@Description	Retorna um produto específico pelo identificador estável (UUID)
+//	@Tags			products
+//	@Produce		json
+//	@Param			id				path		string	true	"ID do produto (UUID)"
+//	@Param			include_deleted	query		bool	false	"Incluir produtos excluídos"
+//	@Param			If-None-Match	header		string	false	"ETag já conhecido pelo cliente"
+//	@Success		200				{object}	product_entity.Product
+//	@Header			200				{string}	ETag	"Versão do produto"
+//	@Success		304
+//	@Failure		400				{object}	http_middleware.ProblemDetails
+//	@Failure		404				{object}	http_middleware.ProblemDetails
+//	@Failure		503				{object}	http_middleware.ProblemDetails
+//	@Router			/products/id/{id} [get]
+func (h *ProductHandler) FindByID(c *gin.Context) {
+	id := c.Param("id")
+	if !shared_identity.IsValidUUID(id) {
+		c.Error(http_middleware.BadRequest(errors.New("invalid product id")))
+		return
+	}
+
+	product, err := h.repo.FindByID(c.Request.Context(), id, includeDeleted(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	respondProductIfModified(c, product)
+}
+
+// FindBySku godoc
+//
+//	@Summary		Buscar produto por SKU
+//	@Description	Retorna um produto específico pelo SKU
+//	@Tags			products
+//	@Produce		json
+//	@Param			sku				path		int		true	"SKU do produto"
+//	@Param			include_deleted	query		bool	false	"Incluir produtos excluídos"
+//	@Param			If-None-Match	header		string	false	"ETag já conhecido pelo cliente"
+//	@Success		200				{object}	product_entity.Product
+//	@Header			200				{string}	ETag	"Versão do produto"
+//	@Success		304
+//	@Failure		400				{object}	http_middleware.ProblemDetails
+//	@Failure		404				{object}	http_middleware.ProblemDetails
+//	@Failure		503				{object}	http_middleware.ProblemDetails
+//	@Router			/products/sku/{sku} [get]
+func (h *ProductHandler) FindBySku(c *gin.Context) {
+	sku, err := strconv.Atoi(c.Param("sku"))
+	if err != nil || sku <= 0 {
+		c.Error(http_middleware.BadRequest(errors.New("invalid sku")))
+		return
+	}
+
+	product, err := h.repo.FindBySku(c.Request.Context(), sku, includeDeleted(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	respondProductIfModified(c, product)
+}
+
+// Update godoc
+//
+//	@Summary		Atualizar um produto
+//	@Description	Substitui todos os dados de um produto existente. O header If-Match deve ter o ETag da versão atual.
+//	@Tags			products
+//	@Accept			json
+//	@Produce		json
+//	@Param			name		path		string				true	"Nome do produto"
+//	@Param			If-Match	header		string				true	"ETag da versão alterada"
+//	@Param			product		body		UpdateProductInput	true	"Novos dados do produto"
+//	@Success		200			{object}	product_entity.Product
+//	@Header			200			{string}	ETag	"Nova versão do produto"
+//	@Failure		400			{object}	http_middleware.ProblemDetails
+//	@Failure		404			{object}	http_middleware.ProblemDetails
+//	@Failure		409			{object}	http_middleware.ProblemDetails
+//	@Failure		412			{object}	http_middleware.ProblemDetails
+//	@Failure		428			{object}	http_middleware.ProblemDetails
+//	@Failure		503			{object}	http_middleware.ProblemDetails
+//	@Router			/products/{name} [put]
+func (h *ProductHandler) Update(c *gin.Context) {
+	var input UpdateProductInput
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(http_middleware.BindingError(err, &input))
+		return
+	}
+
+	price, err := newPrice(input.Price, input.Currency)
+	if err != nil {
+		c.Error(err)
+		return
+	}
 
-	// Atualizar total de produtos
-	h.metrics.ProductsTotal.Set(float64(repoMetrics.TotalProducts))
+	name := c.Param("name")
+	product, err := h.repo.FindOne(c.Request.Context(), name, false)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if err := checkIfMatch(c, product); err != nil {
+		c.Error(err)
+		return
+	}
 
-	// Atualizar valor total
-	h.metrics.UpdateProductsTotalValue(repoMetrics.TotalValue)
+	h.applyUpdate(c, name, &product, input.Name, input.Sku, input.Categories, price)
+}
 
-	// Atualizar preço médio
-	h.metrics.UpdateProductsAveragePrice(repoMetrics.AveragePrice)
+// Patch godoc
+//
+//	@Summary		Atualizar parcialmente um produto
+//	@Description	Altera apenas os campos informados de um produto existente. O header If-Match deve ter o ETag da versão atual.
+//	@Tags			products
+//	@Accept			json
+//	@Produce		json
+//	@Param			name		path		string				true	"Nome do produto"
+//	@Param			If-Match	header		string				true	"ETag da versão alterada"
+//	@Param			product		body		PatchProductInput	true	"Campos a alterar"
+//	@Success		200			{object}	product_entity.Product
+//	@Header			200			{string}	ETag	"Nova versão do produto"
+//	@Failure		400			{object}	http_middleware.ProblemDetails
+//	@Failure		404			{object}	http_middleware.ProblemDetails
+//	@Failure		409			{object}	http_middleware.ProblemDetails
+//	@Failure		412			{object}	http_middleware.ProblemDetails
+//	@Failure		428			{object}	http_middleware.ProblemDetails
+//	@Failure		503			{object}	http_middleware.ProblemDetails
+//	@Router			/products/{name} [patch]
+func (h *ProductHandler) Patch(c *gin.Context) {
+	var input PatchProductInput
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(http_middleware.BindingError(err, &input))
+		return
+	}
+
+	name := c.Param("name")
+	product, err := h.repo.FindOne(c.Request.Context(), name, false)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if err := checkIfMatch(c, product); err != nil {
+		c.Error(err)
+		return
+	}
 
-	// Atualizar produtos por categoria
-	h.metrics.ResetProductsByCategory()
-	for category, count := range repoMetrics.ProductsByCategory {
-		h.metrics.UpdateProductsByCategory(category, float64(count))
+	// Campos ausentes mantêm o valor atual
+	newName, sku, categories, price := product.Name, product.Sku, product.Categories, product.Price
+	if input.Name != nil {
+		newName = *input.Name
 	}
+	if input.Sku != nil {
+		sku = *input.Sku
+	}
+	if input.Categories != nil {
+		categories = input.Categories
+	}
+	if input.Price != nil || input.Currency != nil {
+		// Valor ou moeda ausentes mantêm o valor atual do preço
+		amount, currency := price.Amount(), price.Currency()
+		if input.Price != nil {
+			amount = *input.Price
+		}
+		if input.Currency != nil {
+			currency = *input.Currency
+		}
+
+		price, err = newMoney(amount, currency)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+	}
+
+	h.applyUpdate(c, name, &product, newName, sku, categories, price)
+}
+
+// Delete godoc
+//
+//	@Summary		Excluir um produto
+//	@Description	Marca um produto como excluído (soft delete); ele pode ser restaurado depois. O header If-Match deve ter o ETag da versão atual.
+//	@Tags			products
+//	@Param			name		path	string	true	"Nome do produto"
+//	@Param			If-Match	header	string	true	"ETag da versão excluída"
+//	@Success		204
+//	@Failure		404	{object}	http_middleware.ProblemDetails
+//	@Failure		412	{object}	http_middleware.ProblemDetails
+//	@Failure		428	{object}	http_middleware.ProblemDetails
+//	@Failure		503	{object}	http_middleware.ProblemDetails
+//	@Router			/products/{name} [delete]
+func (h *ProductHandler) Delete(c *gin.Context) {
+	name := c.Param("name")
+	product, err := h.repo.FindOne(c.Request.Context(), name, false)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if err := checkIfMatch(c, product); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := product.Delete(); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.repo.Delete(c.Request.Context(), name, product.ExpectedVersion(), pendingEvents(c, &product)...); err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Atualizar métricas de negócio
+	h.metrics.IncrementProductsDeleted()
+
+	c.Status(http.StatusNoContent)
+}
+
+// Restore godoc
+//
+//	@Summary		Restaurar um produto
+//	@Description	Desfaz a exclusão de um produto excluído. O header If-Match deve ter o ETag da versão excluída.
+//	@Tags			products
+//	@Produce		json
+//	@Param			name		path		string	true	"Nome do produto"
+//	@Param			If-Match	header		string	true	"ETag da versão excluída"
+//	@Success		200			{object}	product_entity.Product
+//	@Header			200			{string}	ETag	"Nova versão do produto"
+//	@Failure		404			{object}	http_middleware.ProblemDetails
+//	@Failure		409			{object}	http_middleware.ProblemDetails
+//	@Failure		412			{object}	http_middleware.ProblemDetails
+//	@Failure		428			{object}	http_middleware.ProblemDetails
+//	@Failure		503			{object}	http_middleware.ProblemDetails
+//	@Router			/products/{name}/restore [post]
+func (h *ProductHandler) Restore(c *gin.Context) {
+	name := c.Param("name")
+	product, err := h.repo.FindOne(c.Request.Context(), name, true)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if err := checkIfMatch(c, product); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := product.Restore(); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.repo.Restore(c.Request.Context(), name, product.ExpectedVersion(), pendingEvents(c, &product)...); err != nil {
+		c.Error(err)
+		return
+	}
+
+	respondProduct(c, http.StatusOK, product)
+}
+
+// applyUpdate valida e persiste as alterações de um produto, respondendo a requisição
+func (h *ProductHandler) applyUpdate(c *gin.Context, name string, product *product_entity.Product, newName string, sku int, categories []string, price product_valueobject.Money) {
+	if err := product.Update(newName, sku, categories, price); err != nil {
+		c.Error(err)
+		return
+	}
+	if err := h.checkCategories(c.Request.Context(), product.Categories); err != nil {
+		c.Error(err)
+		return
+	}
+
+	events := pendingEvents(c, product)
+	if err := h.repo.Update(c.Request.Context(), name, *product, events...); err != nil {
+		c.Error(err)
+		return
+	}
+	h.ensureCategories(c.Request.Context(), product.Categories)
+
+	respondProduct(c, http.StatusOK, *product)
+}
+
+// checkCategories rejeita as categorias do produto que violam as regras do catálogo
+func (h *ProductHandler) checkCategories(ctx context.Context, names []string) error {
+	violations, err := h.categoryViolations(ctx, names)
+	if err != nil {
+		return err
+	}
+
+	return categoryErrors(names, violations)
+}
+
+// categoryViolations retorna a violação de cada nome inválido: no modo estrito, as
+// categorias não cadastradas, com a sugestão da categoria de mesmo slug ("Eletronicos" para
+// "Eletrônicos"); fora dele, os nomes longos demais para uma categoria
+func (h *ProductHandler) categoryViolations(ctx context.Context, names []string) (map[string]*product_errors.Error, error) {
+	violations := make(map[string]*product_errors.Error)
+	if h.categories == nil {
+		return violations, nil
+	}
+
+	if !h.strictCategories {
+		for _, name := range names {
+			if len([]rune(name)) > category_entity.MaxNameLength {
+				violations[name] = product_errors.NewValidationError("categories", product_errors.CodeInvalid,
+					fmt.Sprintf("category name must have at most %d characters", category_entity.MaxNameLength))
+			}
+		}
+		return violations, nil
+	}
+
+	categories, err := h.categories.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool, len(categories))
+	bySlug := make(map[string]string, len(categories))
+	for _, category := range categories {
+		known[category.Name] = true
+		bySlug[category_entity.Slugify(category.Name)] = category.Name
+	}
+
+	for _, name := range names {
+		if known[name] {
+			continue
+		}
+		message := fmt.Sprintf("unknown category %q", name)
+		if suggestion, ok := bySlug[category_entity.Slugify(name)]; ok {
+			message = fmt.Sprintf("unknown category %q (did you mean %q?)", name, suggestion)
+		}
+		violations[name] = product_errors.NewValidationError("categories", product_errors.CodeUnknownCategory, message)
+	}
+	return violations, nil
+}
+
+// ensureCategories cadastra, fora do modo estrito, as categorias que ainda não existem.
+// É chamada só depois que os produtos foram gravados, para que uma gravação recusada não
+// deixe categorias sem produtos no catálogo. No Postgres o repositório de produtos já as
+// cadastrou na mesma transação; como os produtos já foram gravados, uma falha aqui só é
+// registrada no log.
+func (h *ProductHandler) ensureCategories(ctx context.Context, names []string) {
+	if h.categories == nil || h.strictCategories || len(names) == 0 {
+		return
+	}
+	if err := h.categories.Ensure(ctx, names); err != nil {
+		log.Printf("⚠️  Categorias %v não cadastradas no catálogo: %v", names, err)
+	}
+}
+
+// categoryErrors reúne as violações das categorias, na ordem dos nomes
+func categoryErrors(names []string, violations map[string]*product_errors.Error) error {
+	var errs product_errors.ValidationErrors
+	for _, name := range names {
+		if violation, ok := violations[name]; ok {
+			errs = append(errs, violation)
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// pendingEvents retira os eventos registrados no produto, embrulhados com o ID de correlação da requisição
+func pendingEvents(c *gin.Context, product *product_entity.Product) []shared_events.Event {
+	return shared_events.WithCorrelationID(http_middleware.GetCorrelationID(c), product.PullEvents()...)
+}
+
+// newPrice monta o preço a partir do valor em unidades menores e da moeda (BRL quando omitida)
+func newPrice(amount int64, currency string) (product_valueobject.Money, error) {
+	if currency == "" {
+		currency = product_valueobject.DefaultCurrency
+	}
+	return newMoney(amount, currency)
+}
+
+// newMoney monta o preço, tratando uma moeda não suportada como violação do campo currency
+func newMoney(amount int64, currency string) (product_valueobject.Money, error) {
+	price, err := product_valueobject.NewMoney(amount, currency)
+	if err != nil {
+		return price, product_errors.ValidationErrors{
+			product_errors.NewValidationError("currency", product_errors.CodeInvalid, product_valueobject.ErrInvalidCurrency.Error()),
+		}
+	}
+	return price, nil
+}
+
+// parseProductCriteria lê os parâmetros de query da listagem de produtos
+func parseProductCriteria(c *gin.Context) (product_repository.ProductCriteria, error) {
+	criteria, err := parseProductFilters(c)
+	if err != nil {
+		return criteria, err
+	}
+
+	if criteria.Limit, err = parseLimit(c); err != nil {
+		return criteria, err
+	}
+
+	if value := c.Query("after"); value != "" {
+		cursor, err := product_repository.DecodeProductCursor(value, criteria.Sort)
+		if err != nil {
+			return criteria, err
+		}
+		criteria.After = &cursor
+	}
+
+	return criteria, nil
+}
+
+// parseProductFilters lê os filtros e a ordenação da listagem, sem a paginação
+func parseProductFilters(c *gin.Context) (product_repository.ProductCriteria, error) {
+	criteria := product_repository.ProductCriteria{
+		IncludeDeleted: includeDeleted(c),
+		Category:       c.Query("category"),
+	}
+
+	var err error
+	if value := c.Query("sku"); value != "" {
+		sku, err := strconv.Atoi(value)
+		if err != nil {
+			return criteria, errors.New("invalid sku")
+		}
+		criteria.Sku = &sku
+	}
+
+	if criteria.MinPrice, err = parsePriceQuery(c, "min_price"); err != nil {
+		return criteria, err
+	}
+	if criteria.MaxPrice, err = parsePriceQuery(c, "max_price"); err != nil {
+		return criteria, err
+	}
+	if criteria.MinPrice != nil && criteria.MaxPrice != nil && *criteria.MinPrice > *criteria.MaxPrice {
+		return criteria, errors.New("min_price must be less than or equal to max_price")
+	}
+
+	if criteria.Sort, err = product_repository.ParseProductSort(c.Query("sort")); err != nil {
+		return criteria, errors.New("sort must be one of price, -price, name, created_at")
+	}
+
+	if value := c.Query("currency"); value != "" {
+		if !product_valueobject.IsSupportedCurrency(value) {
+			return criteria, errors.New("invalid currency")
+		}
+		criteria.Currency = strings.ToUpper(strings.TrimSpace(value))
+	}
+	if err := criteria.Validate(); err != nil {
+		return criteria, err
+	}
+
+	return criteria, nil
+}
+
+// parseLimit lê o parâmetro limit, DefaultPageLimit quando ausente
+func parseLimit(c *gin.Context) (int, error) {
+	value := c.Query("limit")
+	if value == "" {
+		return DefaultPageLimit, nil
+	}
+
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 || limit > MaxPageLimit {
+		return 0, fmt.Errorf("limit must be between 1 and %d", MaxPageLimit)
+	}
+
+	return limit, nil
+}
+
+// parsePriceQuery lê um filtro de preço em unidades menores da moeda
+func parsePriceQuery(c *gin.Context, name string) (*int64, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	price, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || price < 0 {
+		return nil, fmt.Errorf("invalid %s", name)
+	}
+
+	return &price, nil
+}
+
+// includeDeleted lê o parâmetro de query include_deleted
+func includeDeleted(c *gin.Context) bool {
+	include, _ := strconv.ParseBool(c.Query("include_deleted"))
+	return include
 }
@@ -2,17 +2,26 @@ package product_handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	category_repository "github.com/williamkoller/golang-domain-driven-design/internal/domain/category/repository"
 	product_entity "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/entity"
+	product_errors "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/errors"
 	product_repository "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/repository"
+	product_valueobject "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/valueobject"
+	http_middleware "github.com/williamkoller/golang-domain-driven-design/internal/infra/http/middleware"
 	"github.com/williamkoller/golang-domain-driven-design/internal/metrics"
 	shared_events "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/events"
 )
@@ -53,6 +62,12 @@ func createTestMetrics(testName string) *metrics.Metrics {
 				Help: "Test products created",
 			},
 		),
+		ProductsDeleted: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "test_" + testName + "_products_deleted_total",
+				Help: "Test products deleted",
+			},
+		),
 		ProductsTotal: prometheus.NewGauge(
 			prometheus.GaugeOpts{
 				Name: "test_" + testName + "_products_total",
@@ -66,17 +81,19 @@ func createTestMetrics(testName string) *metrics.Metrics {
 			},
 			[]string{"category"},
 		),
-		ProductsTotalValue: prometheus.NewGauge(
+		ProductsTotalValue: prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Name: "test_" + testName + "_products_total_value",
 				Help: "Test products total value",
 			},
+			[]string{"currency"},
 		),
-		ProductsAveragePrice: prometheus.NewGauge(
+		ProductsAveragePrice: prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Name: "test_" + testName + "_products_average_price",
 				Help: "Test products average price",
 			},
+			[]string{"currency"},
 		),
 	}
 }
@@ -87,7 +104,11 @@ type MockProductRepository struct {
 	addError        error
 	findError       error
 	findOneError    error
+	updateError     error
+	exportError     error // Erro retornado por Export depois de entregar os produtos
 	metricsToReturn product_repository.RepositoryMetrics
+	events          []shared_events.Event // Eventos recebidos em escritas bem-sucedidas
+	lastCtx         context.Context       // ctx recebido pela última chamada a FindOne
 }
 
 func NewMockProductRepository() *MockProductRepository {
@@ -95,22 +116,99 @@ func NewMockProductRepository() *MockProductRepository {
 		products: make(map[string]product_entity.Product),
 		metricsToReturn: product_repository.RepositoryMetrics{
 			TotalProducts:      0,
-			TotalValue:         0,
-			AveragePrice:       0,
+			TotalValue:         make(map[string]float64),
+			AveragePrice:       make(map[string]float64),
 			ProductsByCategory: make(map[string]int),
 		},
 	}
 }
 
-func (m *MockProductRepository) Add(product product_entity.Product) error {
+func (m *MockProductRepository) Add(ctx context.Context, product product_entity.Product, events ...shared_events.Event) error {
 	if m.addError != nil {
 		return m.addError
 	}
 	m.products[product.Name] = product
+	m.events = append(m.events, events...)
 	return nil
 }
 
-func (m *MockProductRepository) Find() ([]product_entity.Product, error) {
+func (m *MockProductRepository) AddBatch(ctx context.Context, entries []product_repository.BatchEntry, atomic bool) (product_repository.BatchResult, error) {
+	if m.addError != nil {
+		return product_repository.BatchResult{}, m.addError
+	}
+
+	uniqueness := product_repository.NewBatchUniqueness()
+	for _, product := range m.products {
+		uniqueness.Use(product.Name, product.Sku)
+	}
+	result := product_repository.BatchResult{Errors: make([]error, len(entries))}
+	for i, entry := range entries {
+		result.Errors[i] = uniqueness.Reserve(entry.Product)
+	}
+	if atomic && result.Failed() {
+		return result, nil
+	}
+
+	for i, entry := range entries {
+		if result.Errors[i] == nil {
+			m.products[entry.Product.Name] = entry.Product
+			m.events = append(m.events, entry.Events...)
+			result.Created++
+		}
+	}
+	return result, nil
+}
+
+func (m *MockProductRepository) Export(ctx context.Context, criteria product_repository.ProductCriteria, fn func(product_entity.Product) error) error {
+	criteria.Limit, criteria.After = 0, nil
+	page, err := m.Find(ctx, criteria)
+	if err != nil {
+		return err
+	}
+	for _, product := range page.Products {
+		if err := fn(product); err != nil {
+			return err
+		}
+	}
+	return m.exportError
+}
+
+func (m *MockProductRepository) Reindex(ctx context.Context, batchSize int, progress func(done, total int)) (int, error) {
+	if m.findError != nil {
+		return 0, m.findError
+	}
+	progress(len(m.products), len(m.products))
+	return len(m.products), nil
+}
+
+func (m *MockProductRepository) Find(ctx context.Context, criteria product_repository.ProductCriteria) (product_repository.ProductPage, error) {
+	if m.findError != nil {
+		return product_repository.ProductPage{}, m.findError
+	}
+	products := make([]product_entity.Product, 0, len(m.products))
+	for _, p := range m.products {
+		if criteria.Matches(p) {
+			products = append(products, p)
+		}
+	}
+
+	order := criteria.OrderBy()
+	sort.Slice(products, func(i, j int) bool { return order.Less(products[i], products[j]) })
+
+	page := product_repository.ProductPage{Total: len(products)}
+	if criteria.After != nil {
+		start := sort.Search(len(products), func(i int) bool { return order.IsAfter(products[i], *criteria.After) })
+		products = products[start:]
+	}
+	if criteria.Limit > 0 && len(products) > criteria.Limit {
+		products = products[:criteria.Limit]
+		page.NextCursor = product_repository.NewProductCursor(products[len(products)-1], order).Encode()
+	}
+	page.Products = products
+	return page, nil
+}
+
+func (m *MockProductRepository) Search(ctx context.Context, query string, limit int) ([]product_entity.Product, error) {
 	if m.findError != nil {
 		return nil, m.findError
 	}
@@ -118,40 +216,145 @@ func (m *MockProductRepository) Find() ([]product_entity.Product, error) {
 	for _, p := range m.products {
 		products = append(products, p)
 	}
-	return products, nil
+	return product_repository.SearchProducts(products, product_repository.Tokenize(query), limit), nil
+}
+
+// Changes trata cada produto, em ordem de nome, como uma escrita com posição 1, 2, ...
+func (m *MockProductRepository) Changes(ctx context.Context, since int64, limit int) (product_repository.ChangePage, error) {
+	if m.findError != nil {
+		return product_repository.ChangePage{}, m.findError
+	}
+	names := make([]string, 0, len(m.products))
+	for name := range m.products {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var changes []product_repository.ProductChange
+	for i, name := range names {
+		seq := int64(i + 1)
+		if seq <= since || (limit > 0 && len(changes) > limit) {
+			continue
+		}
+		product := m.products[name]
+		changes = append(changes, product_repository.ProductChange{
+			Type:     product_repository.ChangeType(product, seq, since),
+			Sequence: seq,
+			Product:  product,
+		})
+	}
+	return product_repository.NewChangePage(changes, since, limit), nil
 }
 
-func (m *MockProductRepository) FindOne(name string) (product_entity.Product, error) {
+func (m *MockProductRepository) FindOne(ctx context.Context, name string, includeDeleted bool) (product_entity.Product, error) {
+	m.lastCtx = ctx
 	if m.findOneError != nil {
 		return product_entity.Product{}, m.findOneError
 	}
 	product, exists := m.products[name]
-	if !exists {
-		return product_entity.Product{}, errors.New("product not found")
+	if !exists || (product.IsDeleted() && !includeDeleted) {
+		return product_entity.Product{}, product_errors.ErrNotFound
 	}
+	product.MarkLoaded()
 	return product, nil
 }
 
-func (m *MockProductRepository) GetMetrics() product_repository.RepositoryMetrics {
+func (m *MockProductRepository) FindByID(ctx context.Context, id string, includeDeleted bool) (product_entity.Product, error) {
+	for _, product := range m.products {
+		if product.ID == id && (includeDeleted || !product.IsDeleted()) {
+			product.MarkLoaded()
+			return product, nil
+		}
+	}
+	return product_entity.Product{}, product_errors.ErrNotFound
+}
+
+func (m *MockProductRepository) FindBySku(ctx context.Context, sku int, includeDeleted bool) (product_entity.Product, error) {
+	for _, product := range m.products {
+		if product.Sku == sku && (includeDeleted || !product.IsDeleted()) {
+			product.MarkLoaded()
+			return product, nil
+		}
+	}
+	return product_entity.Product{}, product_errors.ErrNotFound
+}
+
+func (m *MockProductRepository) Update(ctx context.Context, name string, product product_entity.Product, events ...shared_events.Event) error {
+	if m.updateError != nil {
+		return m.updateError
+	}
+	current, exists := m.products[name]
+	if !exists {
+		return product_errors.ErrNotFound
+	}
+	if current.Version != product.ExpectedVersion() {
+		return product_errors.ErrVersionConflict
+	}
+	delete(m.products, name)
+	m.products[product.Name] = product
+	m.events = append(m.events, events...)
+	return nil
+}
+
+func (m *MockProductRepository) Delete(ctx context.Context, name string, expectedVersion int, events ...shared_events.Event) error {
+	product, exists := m.products[name]
+	if !exists || product.IsDeleted() {
+		return product_errors.ErrNotFound
+	}
+	if product.Version != expectedVersion {
+		return product_errors.ErrVersionConflict
+	}
+	now := time.Now()
+	product.DeletedAt = &now
+	product.Version++
+	m.products[name] = product
+	m.events = append(m.events, events...)
+	return nil
+}
+
+func (m *MockProductRepository) Restore(ctx context.Context, name string, expectedVersion int, events ...shared_events.Event) error {
+	product, exists := m.products[name]
+	if !exists || !product.IsDeleted() {
+		return product_errors.ErrNotFound
+	}
+	if product.Version != expectedVersion {
+		return product_errors.ErrVersionConflict
+	}
+	product.DeletedAt = nil
+	product.Version++
+	m.products[name] = product
+	m.events = append(m.events, events...)
+	return nil
+}
+
+func (m *MockProductRepository) GetMetrics(ctx context.Context) product_repository.RepositoryMetrics {
 	// Calcular métricas reais baseadas nos produtos mock
-	totalValue := 0
+	totalProducts := 0
+	totalValue := make(map[string]float64)
+	countByCurrency := make(map[string]int)
 	productsByCategory := make(map[string]int)
 
 	for _, product := range m.products {
-		totalValue += product.Price
+		if product.IsDeleted() {
+			continue
+		}
+		totalProducts++
+		currency := product.Price.Currency()
+		totalValue[currency] += float64(product.Price.Amount())
+		countByCurrency[currency]++
 		for _, category := range product.Categories {
 			productsByCategory[category]++
 		}
 	}
 
-	avgPrice := 0.0
-	if len(m.products) > 0 {
-		avgPrice = float64(totalValue) / float64(len(m.products))
+	avgPrice := make(map[string]float64)
+	for currency, total := range totalValue {
+		avgPrice[currency] = total / float64(countByCurrency[currency])
 	}
 
 	return product_repository.RepositoryMetrics{
-		TotalProducts:      len(m.products),
-		TotalValue:         float64(totalValue),
+		TotalProducts:      totalProducts,
+		TotalValue:         totalValue,
 		AveragePrice:       avgPrice,
 		ProductsByCategory: productsByCategory,
 	}
@@ -160,12 +363,23 @@ func (m *MockProductRepository) GetMetrics() product_repository.RepositoryMetric
 func setupTestRouter(handler *ProductHandler) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(http_middleware.ErrorHandler())
 
 	v1 := router.Group("/api/v1")
 	{
 		v1.POST("/products", handler.Create)
+		v1.POST("/products/import", handler.Import)
 		v1.GET("/products", handler.FindAll)
+		v1.GET("/products/export", handler.Export)
+		v1.GET("/products/search", handler.Search)
+		v1.GET("/products/changes", handler.Changes)
 		v1.GET("/products/:name", handler.FindOne)
+		v1.GET("/products/id/:id", handler.FindByID)
+		v1.GET("/products/sku/:sku", handler.FindBySku)
+		v1.PUT("/products/:name", handler.Update)
+		v1.PATCH("/products/:name", handler.Patch)
+		v1.DELETE("/products/:name", handler.Delete)
+		v1.POST("/products/:name/restore", handler.Restore)
 	}
 
 	return router
@@ -173,10 +387,9 @@ func setupTestRouter(handler *ProductHandler) *gin.Engine {
 
 func TestNewProductHandler(t *testing.T) {
 	repo := NewMockProductRepository()
-	dispatcher := shared_events.NewEventDispatcher()
 	m := createTestMetrics("new_handler")
 
-	handler := NewProductHandler(repo, dispatcher, m)
+	handler := NewProductHandler(repo, m)
 
 	if handler == nil {
 		t.Fatal("NewProductHandler() returned nil")
@@ -186,15 +399,49 @@ func TestNewProductHandler(t *testing.T) {
 		t.Error("handler.repo is nil")
 	}
 
-	if handler.dispatcher == nil {
-		t.Error("handler.dispatcher is nil")
-	}
-
 	if handler.metrics == nil {
 		t.Error("handler.metrics is nil")
 	}
 }
 
+func TestProductErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantType   string
+		wantDetail string
+		wantFields int
+	}{
+		{
+			name: "validation",
+			err: product_errors.ValidationErrors{
+				product_errors.NewValidationError("name", product_errors.CodeRequired, "name is required"),
+				product_errors.NewValidationError("price", product_errors.CodeMustBePositive, "price must be positive"),
+			},
+			wantStatus: http.StatusBadRequest,
+			wantType:   "/problems/validation-error",
+			wantDetail: "name is required; price must be positive",
+			wantFields: 2,
+		},
+		{name: "not found", err: fmt.Errorf("erro ao buscar produto: %w", product_errors.ErrNotFound), wantStatus: http.StatusNotFound, wantType: "/problems/not-found", wantDetail: "product not found"},
+		{name: "already exists", err: fmt.Errorf("erro ao inserir produto: %w", product_errors.NewAlreadyExistsError("sku")), wantStatus: http.StatusConflict, wantType: "/problems/already-exists", wantDetail: "product with this sku already exists", wantFields: 1},
+		{name: "state conflict", err: product_errors.ErrAlreadyDeleted, wantStatus: http.StatusConflict, wantType: "/problems/conflict", wantDetail: "product already deleted"},
+		{name: "version conflict", err: fmt.Errorf("erro ao atualizar produto: %w", product_errors.ErrVersionConflict), wantStatus: http.StatusPreconditionFailed, wantType: "/problems/precondition-failed", wantDetail: "product was modified by another request"},
+		{name: "unavailable hides the cause", err: product_errors.Unavailable(context.DeadlineExceeded), wantStatus: http.StatusServiceUnavailable, wantType: "/problems/unavailable", wantDetail: "product storage unavailable"},
+		{name: "category storage unavailable", err: fmt.Errorf("%w: %w", category_repository.ErrUnavailable, errors.New("dial tcp")), wantStatus: http.StatusServiceUnavailable, wantType: "/problems/unavailable", wantDetail: "category storage unavailable"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problem := http_middleware.ProblemFor(tt.err)
+			if problem.Status != tt.wantStatus || problem.Type != tt.wantType || problem.Detail != tt.wantDetail || len(problem.Errors) != tt.wantFields {
+				t.Errorf("ProblemFor() = %+v; want status %d, type %q, detail %q, %d fields", problem, tt.wantStatus, tt.wantType, tt.wantDetail, tt.wantFields)
+			}
+		})
+	}
+}
+
 func TestProductHandler_Create(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -221,6 +468,110 @@ func TestProductHandler_Create(t *testing.T) {
 				if response.Name != "Notebook" {
 					t.Errorf("Expected name Notebook, got %s", response.Name)
 				}
+				if response.ID == "" {
+					t.Error("Expected generated ID in response")
+				}
+				if response.Price != product_valueobject.MustBRL(3500) {
+					t.Errorf("Expected default currency BRL, got %s", response.Price)
+				}
+			},
+		},
+		{
+			name: "create product with explicit currency",
+			requestBody: CreateProductInput{
+				Name:       "Camera",
+				Sku:        777,
+				Categories: []string{"Electronics"},
+				Price:      1999,
+				Currency:   "usd",
+			},
+			expectedStatus: http.StatusCreated,
+			setupMock:      func(m *MockProductRepository) {},
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var response product_entity.Product
+				if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+					t.Errorf("Failed to unmarshal response: %v", err)
+				}
+				if response.Price.Currency() != "USD" || response.Price.Amount() != 1999 {
+					t.Errorf("Expected price USD 19.99, got %s", response.Price)
+				}
+			},
+		},
+		{
+			name: "unsupported currency",
+			requestBody: CreateProductInput{
+				Name:       "Camera",
+				Sku:        777,
+				Categories: []string{"Electronics"},
+				Price:      1999,
+				Currency:   "XYZ",
+			},
+			expectedStatus: http.StatusBadRequest,
+			setupMock:      func(m *MockProductRepository) {},
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var response http_middleware.ProblemDetails
+				if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+					t.Errorf("Failed to unmarshal error response: %v", err)
+				}
+				if len(response.Errors) != 1 || response.Errors[0].Field != "currency" || response.Errors[0].Code != "invalid" {
+					t.Errorf("Expected invalid currency field error, got %+v", response.Errors)
+				}
+			},
+		},
+		{
+			name: "every domain violation is reported",
+			requestBody: CreateProductInput{
+				Name:       "Camera",
+				Sku:        -1,
+				Categories: []string{"Electronics"},
+				Price:      -500,
+			},
+			expectedStatus: http.StatusBadRequest,
+			setupMock:      func(m *MockProductRepository) {},
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var response http_middleware.ProblemDetails
+				if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+					t.Errorf("Failed to unmarshal error response: %v", err)
+				}
+				want := []http_middleware.FieldError{
+					{Field: "sku", Code: "must_be_positive", Message: "sku must be positive"},
+					{Field: "price", Code: "must_be_positive", Message: "price must be positive"},
+				}
+				if fmt.Sprint(response.Errors) != fmt.Sprint(want) {
+					t.Errorf("Expected field errors %v, got %v", want, response.Errors)
+				}
+			},
+		},
+		{
+			name:           "every invalid field is reported",
+			requestBody:    `{"categories": ["Electronics"]}`,
+			expectedStatus: http.StatusBadRequest,
+			setupMock:      func(m *MockProductRepository) {},
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				if ct := w.Header().Get("Content-Type"); ct != http_middleware.ProblemContentType {
+					t.Errorf("Expected Content-Type %s, got %s", http_middleware.ProblemContentType, ct)
+				}
+
+				var response http_middleware.ProblemDetails
+				if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+					t.Errorf("Failed to unmarshal error response: %v", err)
+				}
+				if response.Status != http.StatusBadRequest || response.Instance != "/api/v1/products" || response.Type == "" || response.Title == "" {
+					t.Errorf("Unexpected problem details: %+v", response)
+				}
+
+				// A mensagem do validator do Gin não chega ao cliente
+				fields := map[string]string{}
+				for _, fieldErr := range response.Errors {
+					fields[fieldErr.Field] = fieldErr.Code
+				}
+				want := map[string]string{"name": "required", "sku": "required", "price": "required"}
+				if fmt.Sprint(fields) != fmt.Sprint(want) {
+					t.Errorf("Expected field errors %v, got %+v", want, response.Errors)
+				}
+				if strings.Contains(w.Body.String(), "Key:") {
+					t.Errorf("Raw validator message leaked: %s", w.Body.String())
+				}
 			},
 		},
 		{
@@ -229,7 +580,7 @@ func TestProductHandler_Create(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			setupMock:      func(m *MockProductRepository) {},
 			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
-				var response ErrorResponse
+				var response http_middleware.ProblemDetails
 				if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
 					t.Errorf("Failed to unmarshal error response: %v", err)
 				}
@@ -246,7 +597,7 @@ func TestProductHandler_Create(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			setupMock:      func(m *MockProductRepository) {},
 			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
-				var response ErrorResponse
+				var response http_middleware.ProblemDetails
 				if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
 					t.Errorf("Failed to unmarshal error response: %v", err)
 				}
@@ -298,13 +649,29 @@ func TestProductHandler_Create(t *testing.T) {
 			},
 			expectedStatus: http.StatusConflict,
 			setupMock: func(m *MockProductRepository) {
-				m.addError = errors.New("product already exists")
+				m.addError = product_errors.NewAlreadyExistsError("name")
 			},
 			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
-				var response ErrorResponse
+				var response http_middleware.ProblemDetails
 				if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
 					t.Errorf("Failed to unmarshal error response: %v", err)
 				}
+				if response.Detail != "product with this name already exists" {
+					t.Errorf("Expected error 'product with this name already exists', got %s", response.Detail)
+				}
+			},
+		},
+		{
+			name: "repository unavailable",
+			requestBody: CreateProductInput{
+				Name:       "Any Product",
+				Sku:        998,
+				Categories: []string{"Test"},
+				Price:      500,
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+			setupMock: func(m *MockProductRepository) {
+				m.addError = product_errors.Unavailable(errors.New("connection refused"))
 			},
 		},
 	}
@@ -316,14 +683,13 @@ func TestProductHandler_Create(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Setup
 			mockRepo := NewMockProductRepository()
-			dispatcher := shared_events.NewEventDispatcher()
 			m := createTestMetrics(fmt.Sprintf("create_%d", currentTestID))
 
 			if tt.setupMock != nil {
 				tt.setupMock(mockRepo)
 			}
 
-			handler := NewProductHandler(mockRepo, dispatcher, m)
+			handler := NewProductHandler(mockRepo, m)
 			router := setupTestRouter(handler)
 
 			// Criar request
@@ -358,6 +724,166 @@ func TestProductHandler_Create(t *testing.T) {
 	}
 }
 
+func TestProductHandler_ForwardsEventsToRepository(t *testing.T) {
+	mockRepo := NewMockProductRepository()
+	handler := NewProductHandler(mockRepo, createTestMetrics("forward_events"))
+	router := gin.New()
+	router.Use(http_middleware.CorrelationID())
+	router.Use(http_middleware.ErrorHandler())
+	router.POST("/api/v1/products", handler.Create)
+	router.DELETE("/api/v1/products/:name", handler.Delete)
+	router.POST("/api/v1/products/:name/restore", handler.Restore)
+
+	body, _ := json.Marshal(CreateProductInput{Name: "Notebook", Sku: 12345, Categories: []string{"Electronics"}, Price: 3500})
+	requests := []*http.Request{
+		httptest.NewRequest(http.MethodPost, "/api/v1/products", bytes.NewBuffer(body)),
+		httptest.NewRequest(http.MethodDelete, "/api/v1/products/Notebook", nil),
+		httptest.NewRequest(http.MethodPost, "/api/v1/products/Notebook/restore", nil),
+	}
+	for _, req := range requests {
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(http_middleware.CorrelationIDHeader, "req-42")
+		if product, exists := mockRepo.products["Notebook"]; exists {
+			req.Header.Set("If-Match", productETag(product))
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code >= http.StatusBadRequest {
+			t.Fatalf("%s %s status = %d. Body: %s", req.Method, req.URL.Path, w.Code, w.Body.String())
+		}
+	}
+
+	want := []string{"product.created", "product.deleted", "product.restored"}
+	if len(mockRepo.events) != len(want) {
+		t.Fatalf("repository received %d events, want %d", len(mockRepo.events), len(want))
+	}
+	for i, event := range mockRepo.events {
+		envelope, ok := event.(*shared_events.Envelope)
+		if !ok {
+			t.Fatalf("event[%d] is %T, want *shared_events.Envelope", i, event)
+		}
+		if envelope.Name != want[i] || envelope.CorrelationID != "req-42" {
+			t.Errorf("event[%d] = %s (correlation %q), want %s (correlation req-42)", i, envelope.Name, envelope.CorrelationID, want[i])
+		}
+	}
+
+	// Uma escrita recusada pelo repositório não gera eventos publicados
+	mockRepo.addError = product_errors.NewAlreadyExistsError("name")
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/products", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	if len(mockRepo.events) != len(want) {
+		t.Errorf("repository received %d events after failed write, want %d", len(mockRepo.events), len(want))
+	}
+}
+
+func TestProductHandler_Categories(t *testing.T) {
+	post := func(router *gin.Engine, categories ...string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(CreateProductInput{Name: "Notebook", Sku: 12345, Categories: categories, Price: 3500})
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/products", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	newCategories := func(t *testing.T, names ...string) *category_repository.CategoryRepository {
+		t.Helper()
+		categories := category_repository.NewRepository(nil)
+		if err := categories.Ensure(context.Background(), names); err != nil {
+			t.Fatalf("Ensure() error = %v", err)
+		}
+		return categories
+	}
+
+	t.Run("strict mode rejects unknown categories", func(t *testing.T) {
+		mockRepo := NewMockProductRepository()
+		handler := NewProductHandler(mockRepo, createTestMetrics("categories_strict"))
+		handler.SetCategories(newCategories(t, "Eletrônicos", "Computadores"), true)
+		router := setupTestRouter(handler)
+
+		w := post(router, "Eletronicos", "Computadores", "Livros")
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("status = %d, want 400 (%s)", w.Code, w.Body.String())
+		}
+
+		var problem http_middleware.ProblemDetails
+		_ = json.Unmarshal(w.Body.Bytes(), &problem)
+		if len(problem.Errors) != 2 || problem.Errors[0].Code != product_errors.CodeUnknownCategory || problem.Errors[0].Field != "categories" {
+			t.Fatalf("errors = %+v", problem.Errors)
+		}
+		if want := `unknown category "Eletronicos" (did you mean "Eletrônicos"?)`; problem.Errors[0].Message != want {
+			t.Errorf("message = %q, want %q", problem.Errors[0].Message, want)
+		}
+		if want := `unknown category "Livros"`; problem.Errors[1].Message != want {
+			t.Errorf("message = %q, want %q", problem.Errors[1].Message, want)
+		}
+		if len(mockRepo.products) != 0 {
+			t.Errorf("stored %d products, want 0", len(mockRepo.products))
+		}
+
+		if w := post(router, "Eletrônicos", "Computadores"); w.Code != http.StatusCreated {
+			t.Errorf("status with known categories = %d, want 201 (%s)", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("strict mode checks the updated categories", func(t *testing.T) {
+		mockRepo := NewMockProductRepository()
+		mockRepo.products["Notebook"] = product_entity.Product{ID: "id-1", Name: "Notebook", Sku: 12345, Categories: []string{"Eletrônicos"}, Price: product_valueobject.MustBRL(3500), Version: 1}
+		handler := NewProductHandler(mockRepo, createTestMetrics("categories_strict_patch"))
+		handler.SetCategories(newCategories(t, "Eletrônicos"), true)
+		router := setupTestRouter(handler)
+
+		req := httptest.NewRequest(http.MethodPatch, "/api/v1/products/Notebook", bytes.NewBufferString(`{"categories":["Gaming"]}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", productETag(mockRepo.products["Notebook"]))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), product_errors.CodeUnknownCategory) {
+			t.Errorf("status = %d, want 400 with unknown_category (%s)", w.Code, w.Body.String())
+		}
+		if categories := mockRepo.products["Notebook"].Categories; len(categories) != 1 || categories[0] != "Eletrônicos" {
+			t.Errorf("stored categories = %v, want unchanged", categories)
+		}
+	})
+
+	t.Run("default mode registers new categories", func(t *testing.T) {
+		categories := newCategories(t, "Eletrônicos")
+		handler := NewProductHandler(NewMockProductRepository(), createTestMetrics("categories_lenient"))
+		handler.SetCategories(categories, false)
+		router := setupTestRouter(handler)
+
+		if w := post(router, "Eletrônicos", "Eletronicos"); w.Code != http.StatusCreated {
+			t.Fatalf("status = %d, want 201 (%s)", w.Code, w.Body.String())
+		}
+		typo, err := categories.FindBySlug(context.Background(), "eletronicos-2")
+		if err != nil || typo.Name != "Eletronicos" {
+			t.Errorf("registered category = %+v, %v", typo, err)
+		}
+
+		if w := post(router, strings.Repeat("a", 101)); w.Code != http.StatusBadRequest {
+			t.Errorf("status with a long category = %d, want 400 (%s)", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("failed write does not register categories", func(t *testing.T) {
+		categories := newCategories(t)
+		mockRepo := NewMockProductRepository()
+		mockRepo.addError = product_errors.NewAlreadyExistsError("name")
+		handler := NewProductHandler(mockRepo, createTestMetrics("categories_failed_write"))
+		handler.SetCategories(categories, false)
+		router := setupTestRouter(handler)
+
+		if w := post(router, "Gaming"); w.Code != http.StatusConflict {
+			t.Fatalf("status = %d, want 409 (%s)", w.Code, w.Body.String())
+		}
+		if all, _ := categories.FindAll(context.Background()); len(all) != 0 {
+			t.Errorf("registered categories = %+v, want none", all)
+		}
+	})
+}
+
 func TestProductHandler_FindAll(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -380,19 +906,19 @@ func TestProductHandler_FindAll(t *testing.T) {
 					Name:       "Product1",
 					Sku:        1,
 					Categories: []string{"Cat1"},
-					Price:      100,
+					Price:      product_valueobject.MustBRL(100),
 				}
 				m.products["Product2"] = product_entity.Product{
 					Name:       "Product2",
 					Sku:        2,
 					Categories: []string{"Cat2"},
-					Price:      200,
+					Price:      product_valueobject.MustBRL(200),
 				}
 				m.products["Product3"] = product_entity.Product{
 					Name:       "Product3",
 					Sku:        3,
 					Categories: []string{"Cat3"},
-					Price:      300,
+					Price:      product_valueobject.MustBRL(300),
 				}
 			},
 			expectedStatus: http.StatusOK,
@@ -403,7 +929,15 @@ func TestProductHandler_FindAll(t *testing.T) {
 			setupMock: func(m *MockProductRepository) {
 				m.findError = errors.New("database error")
 			},
-			expectedStatus: http.StatusOK, // Handler não retorna erro, retorna array vazio
+			expectedStatus: http.StatusInternalServerError,
+			expectedCount:  0,
+		},
+		{
+			name: "find all products - repository unavailable",
+			setupMock: func(m *MockProductRepository) {
+				m.findError = product_errors.Unavailable(errors.New("connection refused"))
+			},
+			expectedStatus: http.StatusServiceUnavailable,
 			expectedCount:  0,
 		},
 	}
@@ -412,14 +946,13 @@ func TestProductHandler_FindAll(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Setup
 			mockRepo := NewMockProductRepository()
-			dispatcher := shared_events.NewEventDispatcher()
 			m := createTestMetrics("findall_" + tt.name)
 
 			if tt.setupMock != nil {
 				tt.setupMock(mockRepo)
 			}
 
-			handler := NewProductHandler(mockRepo, dispatcher, m)
+			handler := NewProductHandler(mockRepo, m)
 			router := setupTestRouter(handler)
 
 			// Criar request
@@ -434,41 +967,245 @@ func TestProductHandler_FindAll(t *testing.T) {
 				t.Errorf("Expected status %d, got %d", tt.expectedStatus, w.Code)
 			}
 
-			var products []product_entity.Product
-			if err := json.Unmarshal(w.Body.Bytes(), &products); err != nil {
+			var response ProductListResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
 				t.Errorf("Failed to unmarshal response: %v", err)
 			}
 
-			if len(products) != tt.expectedCount {
-				t.Errorf("Expected %d products, got %d", tt.expectedCount, len(products))
+			if len(response.Items) != tt.expectedCount {
+				t.Errorf("Expected %d products, got %d", tt.expectedCount, len(response.Items))
+			}
+			if tt.expectedStatus == http.StatusOK && response.Total != tt.expectedCount {
+				t.Errorf("Expected total %d, got %d", tt.expectedCount, response.Total)
 			}
 		})
 	}
 }
 
-func TestProductHandler_FindOne(t *testing.T) {
-	tests := []struct {
-		name           string
-		productName    string
-		setupMock      func(*MockProductRepository)
-		expectedStatus int
-		checkResponse  func(*testing.T, *httptest.ResponseRecorder)
-	}{
-		{
-			name:        "find existing product",
-			productName: "Notebook",
-			setupMock: func(m *MockProductRepository) {
-				m.products["Notebook"] = product_entity.Product{
-					Name:       "Notebook",
-					Sku:        12345,
-					Categories: []string{"Electronics"},
-					Price:      3500,
-				}
-			},
-			expectedStatus: http.StatusOK,
-			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
-				var product product_entity.Product
-				if err := json.Unmarshal(w.Body.Bytes(), &product); err != nil {
+func TestProductHandler_FindAll_Pagination(t *testing.T) {
+	mockRepo := NewMockProductRepository()
+	base := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	for i, name := range []string{"Mouse", "Keyboard", "Monitor"} {
+		mockRepo.products[name] = product_entity.Product{
+			ID:         fmt.Sprintf("00000000-0000-4000-8000-00000000000%d", i+1),
+			Name:       name,
+			Sku:        i + 1,
+			Categories: []string{"Electronics"},
+			Price:      product_valueobject.MustBRL(int64(100 * (i + 1))),
+			CreatedAt:  base.Add(time.Duration(i) * time.Minute),
+		}
+	}
+	mockRepo.products["Book"] = product_entity.Product{ID: "00000000-0000-4000-8000-000000000009", Name: "Book", Sku: 9, Categories: []string{"Books"}, Price: product_valueobject.MustBRL(50), CreatedAt: base}
+
+	handler := NewProductHandler(mockRepo, createTestMetrics("findall_pagination"))
+	router := setupTestRouter(handler)
+
+	list := func(t *testing.T, query string) (int, ProductListResponse) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/products"+query, nil))
+		var response ProductListResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response
+	}
+
+	t.Run("follow next_cursor until the last page", func(t *testing.T) {
+		var names []string
+		query := "?category=Electronics&currency=BRL&sort=-price&limit=2"
+
+		status, page := list(t, query)
+		for {
+			if status != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d", status)
+			}
+			if page.Total != 3 {
+				t.Errorf("Expected total 3, got %d", page.Total)
+			}
+			for _, p := range page.Items {
+				names = append(names, p.Name)
+			}
+			if page.NextCursor == "" {
+				break
+			}
+			status, page = list(t, query+"&after="+page.NextCursor)
+		}
+
+		if strings.Join(names, ",") != "Monitor,Keyboard,Mouse" {
+			t.Errorf("Expected Monitor,Keyboard,Mouse, got %v", names)
+		}
+	})
+
+	t.Run("price range and sku filters", func(t *testing.T) {
+		_, page := list(t, "?currency=brl&min_price=100&max_price=200&sort=name")
+		if page.Total != 2 || page.Items[0].Name != "Keyboard" || page.Items[1].Name != "Mouse" {
+			t.Errorf("Unexpected page %+v", page)
+		}
+
+		_, page = list(t, "?sku=9")
+		if page.Total != 1 || page.Items[0].Name != "Book" {
+			t.Errorf("Unexpected page %+v", page)
+		}
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		for _, query := range []string{
+			"?limit=0",
+			"?limit=101",
+			"?limit=abc",
+			"?sku=abc",
+			"?min_price=-1",
+			"?currency=BRL&min_price=500&max_price=100",
+			"?sort=sku",
+			"?after=invalid",
+			"?min_price=100",
+			"?sort=price",
+			"?currency=XYZ",
+		} {
+			if status, _ := list(t, query); status != http.StatusBadRequest {
+				t.Errorf("GET %s: expected status 400, got %d", query, status)
+			}
+		}
+	})
+}
+
+func TestProductHandler_Search(t *testing.T) {
+	mockRepo := NewMockProductRepository()
+	mockRepo.products["Notebook Gamer"] = product_entity.Product{Name: "Notebook Gamer", Sku: 1, Categories: []string{"Eletrônicos", "Computadores"}, Price: product_valueobject.MustBRL(5000)}
+	mockRepo.products["Mochila para Notebook"] = product_entity.Product{Name: "Mochila para Notebook", Sku: 2, Categories: []string{"Acessórios"}, Price: product_valueobject.MustBRL(200)}
+	mockRepo.products["Fone Bluetooth"] = product_entity.Product{Name: "Fone Bluetooth", Sku: 3, Categories: []string{"Eletrônicos"}, Price: product_valueobject.MustBRL(300)}
+
+	handler := NewProductHandler(mockRepo, createTestMetrics("search"))
+	router := setupTestRouter(handler)
+
+	search := func(query string) (int, ProductSearchResponse) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/products/search"+query, nil))
+		var response ProductSearchResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response
+	}
+
+	t.Run("accent-insensitive category match", func(t *testing.T) {
+		status, response := search("?q=eletronicos")
+		if status != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", status)
+		}
+		if len(response.Items) != 2 {
+			t.Errorf("Expected 2 results, got %d", len(response.Items))
+		}
+	})
+
+	t.Run("name matches rank first", func(t *testing.T) {
+		_, response := search("?q=note")
+		if len(response.Items) != 2 {
+			t.Fatalf("Expected 2 results, got %d", len(response.Items))
+		}
+		if response.Items[0].Name != "Mochila para Notebook" && response.Items[0].Name != "Notebook Gamer" {
+			t.Errorf("Unexpected first result %s", response.Items[0].Name)
+		}
+
+		_, response = search("?q=notebook%20eletr%C3%B4nicos&limit=1")
+		if len(response.Items) != 1 || response.Items[0].Name != "Notebook Gamer" {
+			t.Errorf("Expected only Notebook Gamer, got %+v", response.Items)
+		}
+	})
+
+	t.Run("missing query", func(t *testing.T) {
+		if status, _ := search("?q=%20"); status != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", status)
+		}
+	})
+
+	t.Run("no match returns empty items", func(t *testing.T) {
+		status, response := search("?q=geladeira")
+		if status != http.StatusOK || len(response.Items) != 0 {
+			t.Errorf("Expected 200 with no items, got %d and %d items", status, len(response.Items))
+		}
+	})
+}
+
+func TestProductHandler_Changes(t *testing.T) {
+	deletedAt := time.Now()
+	mockRepo := NewMockProductRepository()
+	mockRepo.products["Fone"] = product_entity.Product{Name: "Fone", Sku: 1, Categories: []string{"Eletrônicos"}, Price: product_valueobject.MustBRL(300)}
+	mockRepo.products["Mouse"] = product_entity.Product{Name: "Mouse", Sku: 2, Categories: []string{"Periféricos"}, Price: product_valueobject.MustBRL(100), DeletedAt: &deletedAt}
+	mockRepo.products["Notebook"] = product_entity.Product{Name: "Notebook", Sku: 3, Categories: []string{"Eletrônicos"}, Price: product_valueobject.MustBRL(5000)}
+
+	handler := NewProductHandler(mockRepo, createTestMetrics("changes"))
+	router := setupTestRouter(handler)
+
+	changes := func(query string) (int, ProductChangeListResponse) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/products/changes"+query, nil))
+		var response ProductChangeListResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response
+	}
+
+	t.Run("pages follow the continuation token", func(t *testing.T) {
+		status, first := changes("?limit=2")
+		if status != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", status)
+		}
+		if len(first.Items) != 2 || !first.HasMore || first.NextToken == "" {
+			t.Fatalf("Expected 2 changes and more pages, got %+v", first)
+		}
+		if first.Items[1].Type != product_repository.ChangeDeleted || first.Items[1].Product.Name != "Mouse" {
+			t.Errorf("Expected Mouse deleted, got %+v", first.Items[1])
+		}
+
+		_, second := changes("?limit=2&since=" + first.NextToken)
+		if len(second.Items) != 1 || second.HasMore || second.Items[0].Product.Name != "Notebook" {
+			t.Fatalf("Expected only Notebook, got %+v", second)
+		}
+
+		// Sem novas alterações, o token continua o mesmo
+		_, empty := changes("?since=" + second.NextToken)
+		if len(empty.Items) != 0 || empty.NextToken != second.NextToken {
+			t.Errorf("Expected empty page with the same token, got %+v", empty)
+		}
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		for _, query := range []string{"?since=%25%25", "?limit=0"} {
+			if status, _ := changes(query); status != http.StatusBadRequest {
+				t.Errorf("%s: expected status 400, got %d", query, status)
+			}
+		}
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		mockRepo.findError = errors.New("database error")
+		defer func() { mockRepo.findError = nil }()
+
+		if status, _ := changes(""); status != http.StatusInternalServerError {
+			t.Errorf("Expected status 500, got %d", status)
+		}
+	})
+}
+
+func TestProductHandler_FindOne(t *testing.T) {
+	tests := []struct {
+		name           string
+		productName    string
+		setupMock      func(*MockProductRepository)
+		expectedStatus int
+		checkResponse  func(*testing.T, *httptest.ResponseRecorder)
+	}{
+		{
+			name:        "find existing product",
+			productName: "Notebook",
+			setupMock: func(m *MockProductRepository) {
+				m.products["Notebook"] = product_entity.Product{
+					Name:       "Notebook",
+					Sku:        12345,
+					Categories: []string{"Electronics"},
+					Price:      product_valueobject.MustBRL(3500),
+				}
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var product product_entity.Product
+				if err := json.Unmarshal(w.Body.Bytes(), &product); err != nil {
 					t.Errorf("Failed to unmarshal response: %v", err)
 				}
 				if product.Name != "Notebook" {
@@ -487,12 +1224,12 @@ func TestProductHandler_FindOne(t *testing.T) {
 			},
 			expectedStatus: http.StatusNotFound,
 			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
-				var response ErrorResponse
+				var response http_middleware.ProblemDetails
 				if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
 					t.Errorf("Failed to unmarshal error response: %v", err)
 				}
-				if response.Error != "product not found" {
-					t.Errorf("Expected error 'product not found', got %s", response.Error)
+				if response.Detail != "product not found" {
+					t.Errorf("Expected error 'product not found', got %s", response.Detail)
 				}
 			},
 		},
@@ -500,14 +1237,18 @@ func TestProductHandler_FindOne(t *testing.T) {
 			name:        "repository error",
 			productName: "Test",
 			setupMock: func(m *MockProductRepository) {
-				m.findOneError = errors.New("database error")
+				m.findOneError = product_errors.Unavailable(errors.New("connection refused"))
 			},
-			expectedStatus: http.StatusNotFound,
+			expectedStatus: http.StatusServiceUnavailable,
 			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
-				var response ErrorResponse
+				var response http_middleware.ProblemDetails
 				if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
 					t.Errorf("Failed to unmarshal error response: %v", err)
 				}
+				// A causa da indisponibilidade não é exposta ao cliente
+				if response.Detail != "product storage unavailable" {
+					t.Errorf("Expected error 'product storage unavailable', got %s", response.Detail)
+				}
 			},
 		},
 	}
@@ -516,14 +1257,13 @@ func TestProductHandler_FindOne(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Setup
 			mockRepo := NewMockProductRepository()
-			dispatcher := shared_events.NewEventDispatcher()
 			m := createTestMetrics("findone_" + tt.name)
 
 			if tt.setupMock != nil {
 				tt.setupMock(mockRepo)
 			}
 
-			handler := NewProductHandler(mockRepo, dispatcher, m)
+			handler := NewProductHandler(mockRepo, m)
 			router := setupTestRouter(handler)
 
 			// Criar request
@@ -545,13 +1285,551 @@ func TestProductHandler_FindOne(t *testing.T) {
 	}
 }
 
+func TestProductHandler_PassesRequestContext(t *testing.T) {
+	mockRepo := NewMockProductRepository()
+	handler := NewProductHandler(mockRepo, createTestMetrics("request_context"))
+	router := setupTestRouter(handler)
+
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "request")
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/products/Notebook", nil).WithContext(ctx)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	if mockRepo.lastCtx == nil || mockRepo.lastCtx.Value(ctxKey{}) != "request" {
+		t.Error("Expected the repository to receive the request context")
+	}
+}
+
+func TestProductHandler_FindByIDAndSku(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+	}{
+		{
+			name:           "find by id",
+			path:           "/api/v1/products/id/3f2b8c1e-4d5a-4b6c-8d7e-9f0a1b2c3d4e",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "find by unknown id",
+			path:           "/api/v1/products/id/00000000-0000-4000-8000-000000000000",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "find by malformed id",
+			path:           "/api/v1/products/id/not-a-uuid",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "find by sku",
+			path:           "/api/v1/products/sku/12345",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "find by unknown sku",
+			path:           "/api/v1/products/sku/999",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "find by non-numeric sku",
+			path:           "/api/v1/products/sku/abc",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "product named id is still reachable by name",
+			path:           "/api/v1/products/id",
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := NewMockProductRepository()
+			mockRepo.products["Notebook/15\""] = product_entity.Product{
+				ID:         "3f2b8c1e-4d5a-4b6c-8d7e-9f0a1b2c3d4e",
+				Name:       "Notebook/15\"",
+				Sku:        12345,
+				Categories: []string{"Electronics"},
+				Price:      product_valueobject.MustBRL(3500),
+			}
+			mockRepo.products["id"] = product_entity.Product{
+				ID:         "9a8b7c6d-5e4f-4a3b-9c2d-1e0f9a8b7c6d",
+				Name:       "id",
+				Sku:        1,
+				Categories: []string{"Test"},
+				Price:      product_valueobject.MustBRL(1),
+			}
+			m := createTestMetrics("find_by_" + tt.name)
+
+			handler := NewProductHandler(mockRepo, m)
+			router := setupTestRouter(handler)
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d. Body: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestProductHandler_Update(t *testing.T) {
+	tests := []struct {
+		name           string
+		productName    string
+		requestBody    interface{}
+		ifMatch        string // padrão: ETag atual do produto
+		noIfMatch      bool
+		setupMock      func(*MockProductRepository)
+		expectedStatus int
+		checkResponse  func(*testing.T, *httptest.ResponseRecorder, *MockProductRepository)
+	}{
+		{
+			name:        "update product successfully",
+			productName: "Notebook",
+			requestBody: UpdateProductInput{
+				Name:       "Notebook Pro",
+				Sku:        12345,
+				Categories: []string{"Electronics", "Computers"},
+				Price:      4500,
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder, m *MockProductRepository) {
+				var product product_entity.Product
+				if err := json.Unmarshal(w.Body.Bytes(), &product); err != nil {
+					t.Errorf("Failed to unmarshal response: %v", err)
+				}
+				if product.Name != "Notebook Pro" {
+					t.Errorf("Expected name Notebook Pro, got %s", product.Name)
+				}
+				if product.Price != product_valueobject.MustBRL(4500) {
+					t.Errorf("Expected price BRL 45.00, got %s", product.Price)
+				}
+				if _, exists := m.products["Notebook Pro"]; !exists {
+					t.Error("Expected repository to contain renamed product")
+				}
+				if product.Version != 2 || w.Header().Get("ETag") != `"id-1-2"` {
+					t.Errorf("Expected version 2 with ETag \"id-1-2\", got %d with %s", product.Version, w.Header().Get("ETag"))
+				}
+			},
+		},
+		{
+			name:           "missing If-Match",
+			productName:    "Notebook",
+			requestBody:    UpdateProductInput{Name: "Notebook", Sku: 12345, Categories: []string{"Electronics"}, Price: 4500},
+			noIfMatch:      true,
+			expectedStatus: http.StatusPreconditionRequired,
+		},
+		{
+			name:           "stale If-Match",
+			productName:    "Notebook",
+			requestBody:    UpdateProductInput{Name: "Notebook", Sku: 12345, Categories: []string{"Electronics"}, Price: 4500},
+			ifMatch:        `"id-1-0"`,
+			expectedStatus: http.StatusPreconditionFailed,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder, m *MockProductRepository) {
+				if m.products["Notebook"].Price != product_valueobject.MustBRL(3500) {
+					t.Error("Expected product unchanged after failed precondition")
+				}
+			},
+		},
+		{
+			name:        "modified between read and write",
+			productName: "Notebook",
+			requestBody: UpdateProductInput{Name: "Notebook", Sku: 12345, Categories: []string{"Electronics"}, Price: 4500},
+			setupMock: func(m *MockProductRepository) {
+				m.updateError = product_errors.ErrVersionConflict
+			},
+			expectedStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name:           "product not found",
+			productName:    "NonExistent",
+			requestBody:    UpdateProductInput{Name: "NonExistent", Sku: 1, Categories: []string{"Test"}, Price: 100},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "missing required field",
+			productName:    "Notebook",
+			requestBody:    map[string]interface{}{"name": "Notebook", "sku": 12345},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid price",
+			productName:    "Notebook",
+			requestBody:    UpdateProductInput{Name: "Notebook", Sku: 12345, Categories: []string{"Electronics"}, Price: -1},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:        "repository conflict",
+			productName: "Notebook",
+			requestBody: UpdateProductInput{Name: "Mouse", Sku: 12345, Categories: []string{"Electronics"}, Price: 3500},
+			setupMock: func(m *MockProductRepository) {
+				m.updateError = product_errors.NewAlreadyExistsError("name")
+			},
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := NewMockProductRepository()
+			mockRepo.products["Notebook"] = product_entity.Product{
+				ID:         "id-1",
+				Name:       "Notebook",
+				Sku:        12345,
+				Categories: []string{"Electronics"},
+				Price:      product_valueobject.MustBRL(3500),
+				Version:    1,
+			}
+			m := createTestMetrics("update_" + tt.name)
+
+			if tt.setupMock != nil {
+				tt.setupMock(mockRepo)
+			}
+
+			handler := NewProductHandler(mockRepo, m)
+			router := setupTestRouter(handler)
+
+			body, _ := json.Marshal(tt.requestBody)
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/products/"+tt.productName, bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			if !tt.noIfMatch {
+				ifMatch := tt.ifMatch
+				if ifMatch == "" {
+					ifMatch = productETag(mockRepo.products["Notebook"])
+				}
+				req.Header.Set("If-Match", ifMatch)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d. Body: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+
+			if tt.checkResponse != nil {
+				tt.checkResponse(t, w, mockRepo)
+			}
+		})
+	}
+}
+
+func TestProductHandler_Patch(t *testing.T) {
+	tests := []struct {
+		name           string
+		productName    string
+		requestBody    string
+		ifMatch        string
+		expectedStatus int
+		checkResponse  func(*testing.T, product_entity.Product)
+	}{
+		{
+			name:           "patch price only",
+			productName:    "Notebook",
+			requestBody:    `{"price": 4200}`,
+			ifMatch:        `"id-1-1"`,
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, p product_entity.Product) {
+				if p.Price != product_valueobject.MustBRL(4200) {
+					t.Errorf("Expected price BRL 42.00, got %s", p.Price)
+				}
+				if p.Name != "Notebook" || p.Sku != 12345 || len(p.Categories) != 1 {
+					t.Errorf("Expected other fields unchanged, got %+v", p)
+				}
+			},
+		},
+		{
+			name:           "patch currency only keeps amount",
+			productName:    "Notebook",
+			requestBody:    `{"currency": "EUR"}`,
+			ifMatch:        `"id-1-1"`,
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, p product_entity.Product) {
+				if p.Price.Currency() != "EUR" || p.Price.Amount() != 3500 {
+					t.Errorf("Expected price EUR 35.00, got %s", p.Price)
+				}
+			},
+		},
+		{
+			name:           "patch with unsupported currency",
+			productName:    "Notebook",
+			requestBody:    `{"currency": "XYZ"}`,
+			ifMatch:        `"id-1-1"`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "patch categories",
+			productName:    "Notebook",
+			requestBody:    `{"categories": ["Electronics", "Computers"]}`,
+			ifMatch:        `"id-1-1"`,
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, p product_entity.Product) {
+				if len(p.Categories) != 2 {
+					t.Errorf("Expected 2 categories, got %v", p.Categories)
+				}
+			},
+		},
+		{
+			name:           "patch with invalid value",
+			productName:    "Notebook",
+			requestBody:    `{"name": ""}`,
+			ifMatch:        `"id-1-1"`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "patch non-existent product",
+			productName:    "NonExistent",
+			requestBody:    `{"price": 100}`,
+			ifMatch:        `"id-1-1"`,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "patch with weak If-Match",
+			productName:    "Notebook",
+			requestBody:    `{"price": 4200}`,
+			ifMatch:        `W/"id-1-1"`,
+			expectedStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name:           "invalid JSON body",
+			productName:    "Notebook",
+			requestBody:    `invalid json`,
+			ifMatch:        `"id-1-1"`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := NewMockProductRepository()
+			mockRepo.products["Notebook"] = product_entity.Product{
+				ID:         "id-1",
+				Name:       "Notebook",
+				Sku:        12345,
+				Categories: []string{"Electronics"},
+				Price:      product_valueobject.MustBRL(3500),
+				Version:    1,
+			}
+			m := createTestMetrics("patch_" + tt.name)
+
+			handler := NewProductHandler(mockRepo, m)
+			router := setupTestRouter(handler)
+
+			req := httptest.NewRequest(http.MethodPatch, "/api/v1/products/"+tt.productName, bytes.NewBufferString(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("If-Match", tt.ifMatch)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d. Body: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+
+			if tt.checkResponse != nil {
+				var product product_entity.Product
+				if err := json.Unmarshal(w.Body.Bytes(), &product); err != nil {
+					t.Fatalf("Failed to unmarshal response: %v", err)
+				}
+				tt.checkResponse(t, product)
+			}
+		})
+	}
+}
+
+func TestProductHandler_Delete(t *testing.T) {
+	tests := []struct {
+		name           string
+		productName    string
+		ifMatch        string
+		expectedStatus int
+	}{
+		{
+			name:           "delete existing product",
+			productName:    "Notebook",
+			ifMatch:        `"id-1-1"`,
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "delete with stale If-Match",
+			productName:    "Notebook",
+			ifMatch:        `"id-1-0"`,
+			expectedStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name:           "delete without If-Match",
+			productName:    "Notebook",
+			expectedStatus: http.StatusPreconditionRequired,
+		},
+		{
+			name:           "delete already deleted product",
+			productName:    "Deleted",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "delete non-existent product",
+			productName:    "NonExistent",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deletedAt := time.Now()
+			mockRepo := NewMockProductRepository()
+			mockRepo.products["Notebook"] = product_entity.Product{ID: "id-1", Name: "Notebook", Sku: 1, Categories: []string{"Electronics"}, Price: product_valueobject.MustBRL(3500), Version: 1}
+			mockRepo.products["Deleted"] = product_entity.Product{ID: "id-2", Name: "Deleted", Sku: 2, Categories: []string{"Electronics"}, Price: product_valueobject.MustBRL(100), DeletedAt: &deletedAt, Version: 2}
+			m := createTestMetrics("delete_" + tt.name)
+
+			handler := NewProductHandler(mockRepo, m)
+			router := setupTestRouter(handler)
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/v1/products/"+tt.productName, nil)
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d. Body: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	t.Run("deleted product leaves listing", func(t *testing.T) {
+		mockRepo := NewMockProductRepository()
+		m := createTestMetrics("delete_metrics")
+		handler := NewProductHandler(mockRepo, m)
+		router := setupTestRouter(handler)
+
+		for _, name := range []string{"Notebook", "Mouse"} {
+			body, _ := json.Marshal(CreateProductInput{Name: name, Sku: len(name), Categories: []string{"Electronics"}, Price: 1000})
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/products", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(httptest.NewRecorder(), req)
+		}
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodDelete, "/api/v1/products/Notebook", nil)
+		req.Header.Set("If-Match", productETag(mockRepo.products["Notebook"]))
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("Expected status 204, got %d", w.Code)
+		}
+
+		if got := testutil.ToFloat64(m.ProductsDeleted); got != 1 {
+			t.Errorf("Expected ProductsDeleted 1, got %v", got)
+		}
+
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/products", nil))
+		var listing ProductListResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &listing)
+		if len(listing.Items) != 1 {
+			t.Errorf("Expected 1 product in listing, got %d", len(listing.Items))
+		}
+
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/products?include_deleted=true", nil))
+		_ = json.Unmarshal(w.Body.Bytes(), &listing)
+		if products := listing.Items; len(products) != 2 {
+			t.Errorf("Expected 2 products with include_deleted, got %d", len(products))
+		}
+
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/products/Notebook", nil))
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 for deleted product, got %d", w.Code)
+		}
+
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/products/Notebook?include_deleted=true", nil))
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status 200 with include_deleted, got %d", w.Code)
+		}
+	})
+}
+
+func TestProductHandler_Restore(t *testing.T) {
+	tests := []struct {
+		name           string
+		productName    string
+		ifMatch        string
+		expectedStatus int
+	}{
+		{
+			name:           "restore deleted product",
+			productName:    "Deleted",
+			ifMatch:        `"id-2-2"`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "restore active product",
+			productName:    "Notebook",
+			ifMatch:        `"id-1-1"`,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "restore without If-Match",
+			productName:    "Deleted",
+			expectedStatus: http.StatusPreconditionRequired,
+		},
+		{
+			name:           "restore non-existent product",
+			productName:    "NonExistent",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deletedAt := time.Now()
+			mockRepo := NewMockProductRepository()
+			mockRepo.products["Notebook"] = product_entity.Product{ID: "id-1", Name: "Notebook", Sku: 1, Categories: []string{"Electronics"}, Price: product_valueobject.MustBRL(3500), Version: 1}
+			mockRepo.products["Deleted"] = product_entity.Product{ID: "id-2", Name: "Deleted", Sku: 2, Categories: []string{"Electronics"}, Price: product_valueobject.MustBRL(100), DeletedAt: &deletedAt, Version: 2}
+			m := createTestMetrics("restore_" + tt.name)
+
+			handler := NewProductHandler(mockRepo, m)
+			router := setupTestRouter(handler)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/products/"+tt.productName+"/restore", nil)
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d. Body: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+
+			if tt.expectedStatus == http.StatusOK {
+				restored := mockRepo.products[tt.productName]
+				if restored.IsDeleted() {
+					t.Error("Expected product to be restored in repository")
+				}
+				if restored.Version != 3 || w.Header().Get("ETag") != `"id-2-3"` {
+					t.Errorf("Expected version 3 with ETag \"id-2-3\", got %d with %s", restored.Version, w.Header().Get("ETag"))
+				}
+			}
+		})
+	}
+}
+
 func TestProductHandler_Integration(t *testing.T) {
 	t.Run("create and retrieve product", func(t *testing.T) {
 		// Setup
 		mockRepo := NewMockProductRepository()
-		dispatcher := shared_events.NewEventDispatcher()
 		m := createTestMetrics("integration")
-		handler := NewProductHandler(mockRepo, dispatcher, m)
+		handler := NewProductHandler(mockRepo, m)
 		router := setupTestRouter(handler)
 
 		// 1. Criar produto
@@ -598,13 +1876,13 @@ func TestProductHandler_Integration(t *testing.T) {
 			t.Fatalf("Failed to list products: status %d", w.Code)
 		}
 
-		var products []product_entity.Product
-		if err := json.Unmarshal(w.Body.Bytes(), &products); err != nil {
+		var listing ProductListResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &listing); err != nil {
 			t.Fatalf("Failed to unmarshal products: %v", err)
 		}
 
-		if len(products) != 1 {
-			t.Errorf("Expected 1 product, got %d", len(products))
+		if len(listing.Items) != 1 {
+			t.Errorf("Expected 1 product, got %d", len(listing.Items))
 		}
 	})
 }
@@ -612,9 +1890,8 @@ func TestProductHandler_Integration(t *testing.T) {
 // Benchmark
 func BenchmarkProductHandler_Create(b *testing.B) {
 	mockRepo := NewMockProductRepository()
-	dispatcher := shared_events.NewEventDispatcher()
 	m := createTestMetrics("bench_create")
-	handler := NewProductHandler(mockRepo, dispatcher, m)
+	handler := NewProductHandler(mockRepo, m)
 	router := setupTestRouter(handler)
 
 	input := CreateProductInput{
@@ -636,7 +1913,6 @@ func BenchmarkProductHandler_Create(b *testing.B) {
 
 func BenchmarkProductHandler_FindAll(b *testing.B) {
 	mockRepo := NewMockProductRepository()
-	dispatcher := shared_events.NewEventDispatcher()
 	m := createTestMetrics("bench_findall")
 
 	// Adicionar alguns produtos
@@ -645,11 +1921,11 @@ func BenchmarkProductHandler_FindAll(b *testing.B) {
 			Name:       "Product" + string(rune(i)),
 			Sku:        i,
 			Categories: []string{"Test"},
-			Price:      100,
+			Price:      product_valueobject.MustBRL(100),
 		}
 	}
 
-	handler := NewProductHandler(mockRepo, dispatcher, m)
+	handler := NewProductHandler(mockRepo, m)
 	router := setupTestRouter(handler)
 
 	b.ResetTimer()
@@ -662,17 +1938,16 @@ func BenchmarkProductHandler_FindAll(b *testing.B) {
 
 func BenchmarkProductHandler_FindOne(b *testing.B) {
 	mockRepo := NewMockProductRepository()
-	dispatcher := shared_events.NewEventDispatcher()
 	m := createTestMetrics("bench_findone")
 
 	mockRepo.products["TestProduct"] = product_entity.Product{
 		Name:       "TestProduct",
 		Sku:        123,
 		Categories: []string{"Test"},
-		Price:      100,
+		Price:      product_valueobject.MustBRL(100),
 	}
 
-	handler := NewProductHandler(mockRepo, dispatcher, m)
+	handler := NewProductHandler(mockRepo, m)
 	router := setupTestRouter(handler)
 
 	b.ResetTimer()
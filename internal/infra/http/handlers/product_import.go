@@ -0,0 +1,422 @@
+package product_handlers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	product_entity "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/entity"
+	product_errors "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/errors"
+	product_repository "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/repository"
+	http_middleware "github.com/williamkoller/golang-domain-driven-design/internal/infra/http/middleware"
+	shared_events "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/events"
+)
+
+// Modos da importação em lote
+const (
+	ImportModeAllOrNothing = "all_or_nothing" // Qualquer linha com erro impede a importação de todas
+	ImportModeBestEffort   = "best_effort"    // Importa as linhas válidas e relata as demais
+)
+
+// Limites da importação em lote
+const (
+	MaxImportRows  = 10000
+	MaxImportBytes = 10 << 20
+)
+
+// Situação de cada linha no relatório da importação
+const (
+	ImportRowCreated = "created"
+	ImportRowSkipped = "skipped"
+	ImportRowFailed  = "failed"
+)
+
+// Formatos aceitos pela importação, pelo Content-Type
+const (
+	ContentTypeCSV    = "text/csv"
+	ContentTypeNDJSON = "application/x-ndjson"
+)
+
+// importChunkSize é a quantidade de linhas gravadas por lote no modo best_effort
+const importChunkSize = 500
+
+// csvCategorySeparator separa as categorias dentro da coluna categories do CSV
+const csvCategorySeparator = "|"
+
+// importAbortedReason explica, no modo all_or_nothing, por que uma linha válida não foi importada
+const importAbortedReason = "not imported: other rows have errors"
+
+var (
+	errUnsupportedImportFormat = fmt.Errorf("import body must be %s or %s", ContentTypeCSV, ContentTypeNDJSON)
+	errImportTooLarge          = fmt.Errorf("import body exceeds %d bytes", MaxImportBytes)
+	errTooManyImportRows       = fmt.Errorf("import exceeds %d rows", MaxImportRows)
+	errEmptyImport             = errors.New("import has no rows")
+)
+
+// ImportReport é o relatório da importação, com o resultado de cada linha na ordem do arquivo
+type ImportReport struct {
+	Mode    string            `json:"mode" example:"best_effort" enums:"all_or_nothing,best_effort"`
+	Created int               `json:"created" example:"2"`
+	Skipped int               `json:"skipped" example:"1"`
+	Failed  int               `json:"failed" example:"1"`
+	Rows    []ImportRowResult `json:"rows"`
+}
+
+// ImportRowResult é o resultado de uma linha. Row é a linha no arquivo (no CSV o cabeçalho é a linha 1);
+// Reason e Errors explicam por que a linha foi ignorada ou falhou.
+type ImportRowResult struct {
+	Row    int                          `json:"row" example:"2"`
+	Status string                       `json:"status" example:"failed" enums:"created,skipped,failed"`
+	Name   string                       `json:"name,omitempty" example:"Notebook"`
+	ID     string                       `json:"id,omitempty" example:"3f2b8c1e-4d5a-4b6c-8d7e-9f0a1b2c3d4e"`
+	Reason string                       `json:"reason,omitempty" example:"price must be positive"`
+	Errors []http_middleware.FieldError `json:"errors,omitempty"`
+}
+
+// reject marca a linha com status, usando o problema do erro como motivo
+func (r *ImportRowResult) reject(status string, err error) {
+	problem := http_middleware.ProblemFor(err)
+	r.Status, r.Reason, r.Errors = status, problem.Detail, problem.Errors
+}
+
+// importRow é uma linha lida do arquivo: os dados do produto ou o erro de leitura da linha
+type importRow struct {
+	line  int
+	input CreateProductInput
+	err   error
+}
+
+// product monta e valida o produto da linha
+func (r importRow) product() (*product_entity.Product, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	price, err := newPrice(r.input.Price, r.input.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	return product_entity.NewProduct(r.input.Name, r.input.Sku, r.input.Categories, price)
+}
+
+// Import godoc
+//
+//	@Summary		Importar produtos em lote
+//	@Description	Cria produtos a partir de um CSV (colunas name, sku, categories separadas por "|", price e currency opcional) ou NDJSON (um CreateProductInput por linha). No modo all_or_nothing (padrão) nenhuma linha é importada se alguma tiver erro ou já existir (422); no modo best_effort as linhas válidas são importadas e as já existentes ignoradas.
+//	@Tags			products
+//	@Accept			text/csv,application/x-ndjson
+//	@Produce		json
+//	@Param			mode	query		string	false	"Modo da importação"	Enums(all_or_nothing, best_effort)
+//	@Success		200		{object}	ImportReport
+//	@Failure		400		{object}	http_middleware.ProblemDetails
+//	@Failure		413		{object}	http_middleware.ProblemDetails
+//	@Failure		415		{object}	http_middleware.ProblemDetails
+//	@Failure		422		{object}	ImportReport
+//	@Failure		503		{object}	http_middleware.ProblemDetails
+//	@Router			/products/import [post]
+func (h *ProductHandler) Import(c *gin.Context) {
+	mode, err := parseImportMode(c)
+	if err != nil {
+		c.Error(http_middleware.BadRequest(err))
+		return
+	}
+
+	parse, err := importParser(c.ContentType())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	rows, err := parse(http.MaxBytesReader(c.Writer, c.Request.Body, MaxImportBytes))
+	if err != nil {
+		c.Error(importReadError(err))
+		return
+	}
+	if len(rows) == 0 {
+		c.Error(http_middleware.BadRequest(errEmptyImport))
+		return
+	}
+
+	report, err := h.importProducts(c.Request.Context(), rows, mode, http_middleware.GetCorrelationID(c), nil)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	status := http.StatusOK
+	if report.Aborted() {
+		status = http.StatusUnprocessableEntity
+	}
+	c.JSON(status, report)
+}
+
+// Aborted indica se o modo all_or_nothing impediu a importação
+func (r ImportReport) Aborted() bool {
+	return r.Mode == ImportModeAllOrNothing && r.Created < len(r.Rows)
+}
+
+// parseImportMode lê o modo da importação, all_or_nothing quando ausente
+func parseImportMode(c *gin.Context) (string, error) {
+	mode := c.DefaultQuery("mode", ImportModeAllOrNothing)
+	if mode != ImportModeAllOrNothing && mode != ImportModeBestEffort {
+		return "", fmt.Errorf("mode must be %s or %s", ImportModeAllOrNothing, ImportModeBestEffort)
+	}
+	return mode, nil
+}
+
+// importParser retorna o leitor do formato indicado pelo Content-Type, ou 415
+func importParser(contentType string) (func(io.Reader) ([]importRow, error), error) {
+	switch contentType {
+	case ContentTypeCSV:
+		return parseImportCSV, nil
+	case ContentTypeNDJSON, "application/ndjson":
+		return parseImportNDJSON, nil
+	}
+	return nil, http_middleware.WithStatus(http.StatusUnsupportedMediaType, errUnsupportedImportFormat)
+}
+
+// importProducts grava os produtos das linhas e monta o relatório. Os eventos dos produtos
+// criados levam correlationID; progress, se informada, recebe quantas linhas já foram
+// processadas. No modo best_effort as linhas são gravadas em lotes de importChunkSize.
+func (h *ProductHandler) importProducts(ctx context.Context, rows []importRow, mode, correlationID string, progress func(done int)) (ImportReport, error) {
+	report := ImportReport{Mode: mode, Rows: make([]ImportRowResult, len(rows))}
+	if progress == nil {
+		progress = func(int) {}
+	}
+
+	// As categorias de todas as linhas são conferidas de uma vez
+	var names []string
+	for _, row := range rows {
+		names = append(names, row.input.Categories...)
+	}
+	violations, err := h.categoryViolations(ctx, names)
+	if err != nil {
+		return report, err
+	}
+
+	var (
+		entries   []product_repository.BatchEntry
+		entryRows []int // linha do relatório de cada entrada
+	)
+	for i, row := range rows {
+		report.Rows[i] = ImportRowResult{Row: row.line, Name: row.input.Name}
+
+		product, err := row.product()
+		if err == nil {
+			err = categoryErrors(product.Categories, violations)
+		}
+		if err != nil {
+			report.Rows[i].reject(ImportRowFailed, err)
+			continue
+		}
+
+		// Os eventos de cada produto são publicados somente se ele for gravado
+		events := shared_events.WithCorrelationID(correlationID, product.PullEvents()...)
+		entries = append(entries, product_repository.BatchEntry{Product: *product, Events: events})
+		entryRows = append(entryRows, i)
+	}
+
+	// No modo all_or_nothing, uma linha inválida já impede a gravação
+	atomic := mode == ImportModeAllOrNothing
+	result := product_repository.BatchResult{Errors: make([]error, len(entries))}
+	invalid := len(rows) - len(entries)
+	switch {
+	case atomic && len(entries) == len(rows):
+		var err error
+		if result, err = h.repo.AddBatch(ctx, entries, true); err != nil {
+			return report, err
+		}
+	case !atomic:
+		for start := 0; start < len(entries); start += importChunkSize {
+			end := min(start+importChunkSize, len(entries))
+			chunk, err := h.repo.AddBatch(ctx, entries[start:end], false)
+			if err != nil {
+				return report, err
+			}
+			copy(result.Errors[start:end], chunk.Errors)
+			result.Created += chunk.Created
+			progress(invalid + end)
+		}
+	}
+	progress(len(rows))
+
+	aborted := atomic && result.Created < len(rows)
+	var categories []string // categorias dos produtos gravados
+	for j, i := range entryRows {
+		row := &report.Rows[i]
+
+		switch err := result.Errors[j]; {
+		case err != nil && atomic:
+			row.reject(ImportRowFailed, err)
+		case err != nil:
+			row.reject(ImportRowSkipped, err)
+		case aborted:
+			row.Status, row.Reason = ImportRowSkipped, importAbortedReason
+		default:
+			row.Status, row.ID = ImportRowCreated, entries[j].Product.ID
+			h.metrics.IncrementProductsCreated()
+			categories = append(categories, entries[j].Product.Categories...)
+		}
+	}
+	h.ensureCategories(ctx, categories)
+
+	for _, row := range report.Rows {
+		switch row.Status {
+		case ImportRowCreated:
+			report.Created++
+		case ImportRowSkipped:
+			report.Skipped++
+		case ImportRowFailed:
+			report.Failed++
+		}
+	}
+
+	return report, nil
+}
+
+// importReadError converte uma falha na leitura do corpo no erro da resposta
+func importReadError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return http_middleware.WithStatus(http.StatusRequestEntityTooLarge, errImportTooLarge)
+	case errors.Is(err, errTooManyImportRows):
+		return http_middleware.WithStatus(http.StatusRequestEntityTooLarge, err)
+	}
+	return http_middleware.BadRequest(err)
+}
+
+// parseImportCSV lê um CSV com cabeçalho. As colunas name, sku, categories e price são
+// obrigatórias e currency é opcional, em qualquer ordem; colunas desconhecidas são ignoradas.
+func parseImportCSV(body io.Reader) ([]importRow, error) {
+	reader := csv.NewReader(body)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, csvReadError(err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, name := range []string{"name", "sku", "categories", "price"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("CSV header is missing the %s column", name)
+		}
+	}
+
+	var rows []importRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if len(rows) == MaxImportRows {
+			return nil, errTooManyImportRows
+		}
+
+		// Uma linha com outra quantidade de colunas falha sozinha; outros erros tornam o CSV ilegível
+		if err != nil && !errors.Is(err, csv.ErrFieldCount) {
+			return nil, csvReadError(err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		row := importRow{line: line}
+		if err != nil {
+			row.err = http_middleware.BadRequest(fmt.Errorf("row has %d columns, header has %d", len(record), len(header)))
+		} else {
+			row.input, row.err = csvProductInput(record, columns)
+		}
+
+		rows = append(rows, row)
+	}
+}
+
+// csvReadError descreve um CSV malformado; falhas na leitura do corpo são repassadas
+func csvReadError(err error) error {
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return fmt.Errorf("malformed CSV at line %d: %w", parseErr.Line, parseErr.Err)
+	}
+	return err
+}
+
+// csvProductInput lê os campos do produto de uma linha do CSV
+func csvProductInput(record []string, columns map[string]int) (CreateProductInput, error) {
+	field := func(name string) string {
+		if i, ok := columns[name]; ok {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	input := CreateProductInput{Name: field("name"), Currency: field("currency")}
+	for _, category := range strings.Split(field("categories"), csvCategorySeparator) {
+		if category = strings.TrimSpace(category); category != "" {
+			input.Categories = append(input.Categories, category)
+		}
+	}
+
+	var violations product_errors.ValidationErrors
+	sku, err := strconv.Atoi(field("sku"))
+	if err != nil {
+		violations = append(violations, product_errors.NewValidationError("sku", product_errors.CodeInvalid, "sku must be a number"))
+	}
+	price, err := strconv.ParseInt(field("price"), 10, 64)
+	if err != nil {
+		violations = append(violations, product_errors.NewValidationError("price", product_errors.CodeInvalid, "price must be a number"))
+	}
+	if len(violations) > 0 {
+		return input, violations
+	}
+
+	input.Sku, input.Price = sku, price
+	return input, nil
+}
+
+// parseImportNDJSON lê um CreateProductInput por linha; linhas em branco são ignoradas
+func parseImportNDJSON(body io.Reader) ([]importRow, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+
+	var rows []importRow
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		if len(rows) == MaxImportRows {
+			return nil, errTooManyImportRows
+		}
+
+		row := importRow{line: line}
+		if err := json.Unmarshal(data, &row.input); err != nil {
+			row.err = http_middleware.BindingError(err, &row.input)
+		}
+		rows = append(rows, row)
+	}
+
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return nil, errors.New("NDJSON line exceeds 1 MiB")
+		}
+		return nil, err
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,271 @@
+package product_handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	category_repository "github.com/williamkoller/golang-domain-driven-design/internal/domain/category/repository"
+	product_entity "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/entity"
+	product_errors "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/errors"
+	product_valueobject "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/valueobject"
+	http_middleware "github.com/williamkoller/golang-domain-driven-design/internal/infra/http/middleware"
+)
+
+// importCSV tem uma linha válida, um nome já cadastrado, um preço inválido e outra linha válida
+const importCSV = `name,sku,categories,price,currency
+Mouse,2,Accessories,5000,
+Notebook,3,Electronics,350000,BRL
+Keyboard,4,Accessories,abc,BRL
+Monitor,5,Electronics|Accessories,90000,USD
+`
+
+func postImport(router http.Handler, query, contentType, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/products/import"+query, strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func newImportTestRepository() *MockProductRepository {
+	mockRepo := NewMockProductRepository()
+	mockRepo.products["Notebook"] = product_entity.Product{ID: "id-1", Name: "Notebook", Sku: 1, Categories: []string{"Electronics"}, Price: product_valueobject.MustBRL(3500), Version: 1}
+	return mockRepo
+}
+
+func decodeImportReport(t *testing.T, w *httptest.ResponseRecorder) ImportReport {
+	t.Helper()
+	var report ImportReport
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v (%s)", err, w.Body.String())
+	}
+	return report
+}
+
+func rowStatuses(report ImportReport) []string {
+	statuses := make([]string, len(report.Rows))
+	for i, row := range report.Rows {
+		statuses[i] = row.Status
+	}
+	return statuses
+}
+
+func TestProductHandler_Import(t *testing.T) {
+	t.Run("best effort imports the valid rows", func(t *testing.T) {
+		mockRepo := newImportTestRepository()
+		router := setupTestRouter(NewProductHandler(mockRepo, createTestMetrics("import_best_effort")))
+
+		w := postImport(router, "?mode=best_effort", "text/csv; charset=utf-8", importCSV)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200 (%s)", w.Code, w.Body.String())
+		}
+
+		report := decodeImportReport(t, w)
+		want := []string{ImportRowCreated, ImportRowSkipped, ImportRowFailed, ImportRowCreated}
+		if strings.Join(rowStatuses(report), ",") != strings.Join(want, ",") {
+			t.Fatalf("row statuses = %v, want %v", rowStatuses(report), want)
+		}
+		if report.Mode != ImportModeBestEffort || report.Created != 2 || report.Skipped != 1 || report.Failed != 1 {
+			t.Errorf("report = %+v", report)
+		}
+
+		// As linhas do CSV contam a partir do cabeçalho
+		for i, row := range report.Rows {
+			if row.Row != i+2 {
+				t.Errorf("row %d reported as line %d, want %d", i, row.Row, i+2)
+			}
+		}
+		if skipped := report.Rows[1]; skipped.Reason != "product with this name already exists" || len(skipped.Errors) != 1 || skipped.Errors[0].Code != product_errors.CodeAlreadyExists {
+			t.Errorf("skipped row = %+v", skipped)
+		}
+		if failed := report.Rows[2]; len(failed.Errors) != 1 || failed.Errors[0].Field != "price" {
+			t.Errorf("failed row = %+v", failed)
+		}
+
+		monitor, ok := mockRepo.products["Monitor"]
+		if !ok || report.Rows[3].ID != monitor.ID || monitor.Price.Currency() != "USD" || len(monitor.Categories) != 2 {
+			t.Errorf("imported Monitor = %+v, report row %+v", monitor, report.Rows[3])
+		}
+		if len(mockRepo.events) != 2 {
+			t.Errorf("events = %d, want one per imported product", len(mockRepo.events))
+		}
+	})
+
+	t.Run("all or nothing imports nothing when a row has errors", func(t *testing.T) {
+		mockRepo := newImportTestRepository()
+		router := setupTestRouter(NewProductHandler(mockRepo, createTestMetrics("import_atomic_invalid")))
+
+		w := postImport(router, "", "text/csv", importCSV)
+		if w.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("status = %d, want 422 (%s)", w.Code, w.Body.String())
+		}
+
+		report := decodeImportReport(t, w)
+		if report.Mode != ImportModeAllOrNothing || report.Created != 0 || len(mockRepo.products) != 1 {
+			t.Errorf("report = %+v with %d products stored", report, len(mockRepo.products))
+		}
+		if report.Rows[2].Status != ImportRowFailed || report.Rows[0].Status != ImportRowSkipped || report.Rows[0].Reason != importAbortedReason {
+			t.Errorf("rows = %+v", report.Rows)
+		}
+	})
+
+	t.Run("all or nothing reports duplicates as failures", func(t *testing.T) {
+		mockRepo := newImportTestRepository()
+		router := setupTestRouter(NewProductHandler(mockRepo, createTestMetrics("import_atomic_conflict")))
+
+		body := "name,sku,categories,price\nMouse,2,Accessories,5000\nNotebook,3,Electronics,350000\n"
+		w := postImport(router, "?mode=all_or_nothing", "text/csv", body)
+
+		report := decodeImportReport(t, w)
+		want := []string{ImportRowSkipped, ImportRowFailed}
+		if w.Code != http.StatusUnprocessableEntity || strings.Join(rowStatuses(report), ",") != strings.Join(want, ",") {
+			t.Errorf("status = %d with rows %v, want 422 with %v", w.Code, rowStatuses(report), want)
+		}
+		if _, stored := mockRepo.products["Mouse"]; stored {
+			t.Error("all_or_nothing import stored a row of a failed import")
+		}
+	})
+
+	t.Run("all or nothing imports every valid row", func(t *testing.T) {
+		mockRepo := NewMockProductRepository()
+		router := setupTestRouter(NewProductHandler(mockRepo, createTestMetrics("import_atomic")))
+
+		body := `{"name":"Mouse","sku":2,"categories":["Accessories"],"price":5000}
+
+{"name":"Monitor","sku":5,"categories":["Electronics"],"price":90000,"currency":"USD"}
+`
+		w := postImport(router, "", "application/x-ndjson", body)
+
+		report := decodeImportReport(t, w)
+		if w.Code != http.StatusOK || report.Created != 2 || len(mockRepo.products) != 2 {
+			t.Fatalf("status = %d with report %+v", w.Code, report)
+		}
+		// Linhas em branco contam na numeração do NDJSON
+		if report.Rows[0].Row != 1 || report.Rows[1].Row != 3 {
+			t.Errorf("rows = %+v, want lines 1 and 3", report.Rows)
+		}
+	})
+
+	t.Run("invalid NDJSON rows", func(t *testing.T) {
+		mockRepo := NewMockProductRepository()
+		router := setupTestRouter(NewProductHandler(mockRepo, createTestMetrics("import_ndjson_invalid")))
+
+		body := "{\"name\":\"Mouse\",\"sku\":\"two\"}\n{not json\n{\"name\":\"\",\"sku\":2,\"categories\":[\"A\"],\"price\":0}\n"
+		w := postImport(router, "?mode=best_effort", "application/ndjson", body)
+
+		report := decodeImportReport(t, w)
+		if w.Code != http.StatusOK || report.Failed != 3 {
+			t.Fatalf("status = %d with report %+v", w.Code, report)
+		}
+		if report.Rows[0].Errors[0].Code != "invalid_type" || report.Rows[1].Reason != "malformed JSON body" || len(report.Rows[2].Errors) != 2 {
+			t.Errorf("rows = %+v", report.Rows)
+		}
+	})
+
+	t.Run("strict categories fail the rows with unknown categories", func(t *testing.T) {
+		categories := category_repository.NewRepository(nil)
+		_ = categories.Ensure(context.Background(), []string{"Accessories"})
+		mockRepo := NewMockProductRepository()
+		handler := NewProductHandler(mockRepo, createTestMetrics("import_strict_categories"))
+		handler.SetCategories(categories, true)
+
+		body := "name,sku,categories,price\nMouse,2,Accessories,5000\nMonitor,3,Acessories|Screens,90000\n"
+		w := postImport(setupTestRouter(handler), "?mode=best_effort", "text/csv", body)
+
+		report := decodeImportReport(t, w)
+		if w.Code != http.StatusOK || report.Created != 1 || report.Failed != 1 {
+			t.Fatalf("status = %d with report %+v", w.Code, report)
+		}
+		failed := report.Rows[1]
+		if len(failed.Errors) != 2 || failed.Errors[0].Code != product_errors.CodeUnknownCategory || failed.Errors[1].Code != product_errors.CodeUnknownCategory {
+			t.Errorf("failed row = %+v", failed)
+		}
+		if _, ok := mockRepo.products["Monitor"]; ok {
+			t.Error("Monitor was imported with unknown categories")
+		}
+	})
+
+	t.Run("new categories are registered only when rows are imported", func(t *testing.T) {
+		categories := category_repository.NewRepository(nil)
+		handler := NewProductHandler(newImportTestRepository(), createTestMetrics("import_ensure_categories"))
+		handler.SetCategories(categories, false)
+		router := setupTestRouter(handler)
+
+		// No modo all_or_nothing a linha duplicada impede a gravação e nenhuma categoria é criada
+		w := postImport(router, "", "text/csv", importCSV)
+		if all, _ := categories.FindAll(context.Background()); w.Code != http.StatusUnprocessableEntity || len(all) != 0 {
+			t.Fatalf("status = %d with %d categories, want 422 with none", w.Code, len(all))
+		}
+
+		w = postImport(router, "?mode=best_effort", "text/csv", importCSV)
+		all, _ := categories.FindAll(context.Background())
+		if w.Code != http.StatusOK || len(all) != 2 {
+			t.Errorf("status = %d with categories %+v, want Accessories and Electronics", w.Code, all)
+		}
+	})
+
+	t.Run("repository failure", func(t *testing.T) {
+		mockRepo := NewMockProductRepository()
+		mockRepo.addError = product_errors.Unavailable(errors.New("connection refused"))
+		router := setupTestRouter(NewProductHandler(mockRepo, createTestMetrics("import_unavailable")))
+
+		w := postImport(router, "", "text/csv", "name,sku,categories,price\nMouse,2,Accessories,5000\n")
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("status = %d, want 503", w.Code)
+		}
+	})
+}
+
+func TestProductHandler_ImportRejectsRequest(t *testing.T) {
+	router := setupTestRouter(NewProductHandler(NewMockProductRepository(), createTestMetrics("import_rejected")))
+
+	tests := []struct {
+		name        string
+		query       string
+		contentType string
+		body        string
+		wantStatus  int
+	}{
+		{name: "unknown mode", query: "?mode=partial", contentType: "text/csv", body: "name,sku,categories,price\n", wantStatus: http.StatusBadRequest},
+		{name: "unsupported format", contentType: "application/json", body: `[{"name":"Mouse"}]`, wantStatus: http.StatusUnsupportedMediaType},
+		{name: "missing column", contentType: "text/csv", body: "name,sku,categories\nMouse,2,Accessories\n", wantStatus: http.StatusBadRequest},
+		{name: "malformed CSV", contentType: "text/csv", body: "name,sku,categories,price\n\"Mouse,2,Accessories,5000\n", wantStatus: http.StatusBadRequest},
+		{name: "no rows", contentType: "text/csv", body: "name,sku,categories,price\n", wantStatus: http.StatusBadRequest},
+		{name: "body too large", contentType: "application/x-ndjson", body: strings.Repeat("\n", MaxImportBytes+1), wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "too many rows", contentType: "text/csv", body: "name,sku,categories,price\n" + strings.Repeat("Mouse,2,Accessories,5000\n", MaxImportRows+1), wantStatus: http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := postImport(router, tt.query, tt.contentType, tt.body)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if w.Header().Get("Content-Type") != http_middleware.ProblemContentType {
+				t.Errorf("Content-Type = %s, want problem details", w.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestParseImportCSV(t *testing.T) {
+	body := "\ufeffSKU, Name ,Price,Categories,Notes\n7,Mouse,5000, Accessories | Gaming |,promo\n8,Pad\n"
+
+	rows, err := parseImportCSV(strings.NewReader(body))
+	if err != nil || len(rows) != 2 {
+		t.Fatalf("parseImportCSV() = %d rows, %v; want 2 rows", len(rows), err)
+	}
+
+	mouse := rows[0].input
+	if rows[0].err != nil || mouse.Name != "Mouse" || mouse.Sku != 7 || mouse.Price != 5000 || strings.Join(mouse.Categories, ",") != "Accessories,Gaming" {
+		t.Errorf("row 1 = %+v, %v", mouse, rows[0].err)
+	}
+	if rows[1].line != 3 || rows[1].err == nil {
+		t.Errorf("row with missing columns = line %d, err %v; want line 3 with error", rows[1].line, rows[1].err)
+	}
+}
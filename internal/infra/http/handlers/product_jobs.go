@@ -0,0 +1,293 @@
+package product_handlers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/gin-gonic/gin"
+	http_middleware "github.com/williamkoller/golang-domain-driven-design/internal/infra/http/middleware"
+	shared_jobs "github.com/williamkoller/golang-domain-driven-design/internal/shared/jobs"
+)
+
+// Tipos dos jobs de produtos
+const (
+	JobTypeProductImport  = "product.import"
+	JobTypeProductExport  = "product.export"
+	JobTypeProductReindex = "product.reindex"
+)
+
+// reindexBatchSize é a quantidade de produtos reindexados por transação
+const reindexBatchSize = 500
+
+// ImportJobParams são os parâmetros do job de importação; o arquivo fica no Input do job
+type ImportJobParams struct {
+	Mode        string `json:"mode" example:"best_effort"`
+	ContentType string `json:"content_type" example:"text/csv"`
+}
+
+// ExportJobParams são os parâmetros do job de exportação: o formato e a query string da
+// requisição, com os mesmos filtros e opções de GET /products/export
+type ExportJobParams struct {
+	Format string `json:"format" example:"csv"`
+	Query  string `json:"query" example:"format=csv&category=Books"`
+}
+
+// ExportJobResult é o resumo do resultado do job de exportação
+type ExportJobResult struct {
+	Format   string `json:"format" example:"csv"`
+	Products int    `json:"products" example:"12500"`
+}
+
+// ReindexJobResult é o resumo do resultado do job de reindexação
+type ReindexJobResult struct {
+	Products int `json:"products" example:"12500"`
+}
+
+// ProductJobHandler cria os jobs de importação, exportação e reindexação de produtos e os
+// executa com os mesmos passos das rotas síncronas. Os arquivos exportados são gravados em
+// resultDir, que deve ser compartilhado entre as instâncias da aplicação.
+type ProductJobHandler struct {
+	products  *ProductHandler
+	jobs      *shared_jobs.Pool
+	resultDir string
+}
+
+// NewProductJobHandler registra os Runners dos jobs de produtos no pool; deve ser chamado
+// antes de pool.Start
+func NewProductJobHandler(products *ProductHandler, jobs *shared_jobs.Pool, resultDir string) *ProductJobHandler {
+	h := &ProductJobHandler{products: products, jobs: jobs, resultDir: resultDir}
+
+	jobs.Register(JobTypeProductImport, h.runImport)
+	jobs.Register(JobTypeProductExport, h.runExport)
+	jobs.Register(JobTypeProductReindex, h.runReindex)
+
+	return h
+}
+
+// Import godoc
+//
+//	@Summary		Importar produtos em segundo plano
+//	@Description	Valida o arquivo e cria um job que importa os produtos como POST /products/import. O relatório da importação fica no result do job; no modo all_or_nothing, um arquivo com erros faz o job falhar sem importar nada.
+//	@Tags			jobs
+//	@Accept			text/csv,application/x-ndjson
+//	@Produce		json
+//	@Param			mode	query		string	false	"Modo da importação"	Enums(all_or_nothing, best_effort)
+//	@Success		202		{object}	JobResponse
+//	@Header			202		{string}	Location	"/api/v1/jobs/{id}"
+//	@Failure		400		{object}	http_middleware.ProblemDetails
+//	@Failure		413		{object}	http_middleware.ProblemDetails
+//	@Failure		415		{object}	http_middleware.ProblemDetails
+//	@Failure		503		{object}	http_middleware.ProblemDetails
+//	@Router			/jobs/imports [post]
+func (h *ProductJobHandler) Import(c *gin.Context) {
+	mode, err := parseImportMode(c)
+	if err != nil {
+		c.Error(http_middleware.BadRequest(err))
+		return
+	}
+
+	contentType := c.ContentType()
+	parse, err := importParser(contentType)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, MaxImportBytes))
+	if err != nil {
+		c.Error(importReadError(err))
+		return
+	}
+
+	// O arquivo é lido já na criação, para que um arquivo ilegível seja recusado na hora
+	rows, err := parse(bytes.NewReader(body))
+	if err != nil {
+		c.Error(importReadError(err))
+		return
+	}
+	if len(rows) == 0 {
+		c.Error(http_middleware.BadRequest(errEmptyImport))
+		return
+	}
+
+	h.enqueue(c, JobTypeProductImport, ImportJobParams{Mode: mode, ContentType: contentType}, body)
+}
+
+// Export godoc
+//
+//	@Summary		Exportar o catálogo em segundo plano
+//	@Description	Cria um job que grava a exportação em um arquivo, com os mesmos parâmetros de GET /products/export. O arquivo é baixado pelo result_url do job quando ele conclui.
+//	@Tags			jobs
+//	@Produce		json
+//	@Param			format				query		string	false	"Formato do arquivo (padrão csv)"	Enums(csv, ndjson, json)
+//	@Param			categories			query		string	false	"CSV: categorias em uma coluna (join, padrão) ou uma linha por categoria (rows)"	Enums(join, rows)
+//	@Param			category_separator	query		string	false	"CSV: separador das categorias no modo join (padrão |)"
+//	@Param			bom					query		bool	false	"CSV: iniciar o arquivo com o BOM do UTF-8, para abrir no Excel"
+//	@Param			category			query		string	false	"Filtrar por categoria"
+//	@Param			currency			query		string	false	"Moeda dos preços (BRL, USD...); obrigatória com min_price, max_price e sort por preço"
+//	@Param			min_price			query		int		false	"Preço mínimo (unidades menores da moeda)"
+//	@Param			max_price			query		int		false	"Preço máximo (unidades menores da moeda)"
+//	@Param			sku					query		int		false	"Filtrar por SKU"
+//	@Param			sort				query		string	false	"Ordenação: price, -price, name ou created_at (padrão: mais recentes primeiro)"
+//	@Param			include_deleted		query		bool	false	"Incluir produtos excluídos"
+//	@Success		202					{object}	JobResponse
+//	@Header			202					{string}	Location	"/api/v1/jobs/{id}"
+//	@Failure		400					{object}	http_middleware.ProblemDetails
+//	@Failure		503					{object}	http_middleware.ProblemDetails
+//	@Router			/jobs/exports [post]
+func (h *ProductJobHandler) Export(c *gin.Context) {
+	format := c.DefaultQuery("format", ExportFormatCSV)
+	if _, ok := exportFormats[format]; !ok {
+		c.Error(http_middleware.BadRequest(fmt.Errorf("format must be %s, %s or %s", ExportFormatCSV, ExportFormatNDJSON, ExportFormatJSON)))
+		return
+	}
+	if _, err := parseExportOptions(c); err != nil {
+		c.Error(http_middleware.BadRequest(err))
+		return
+	}
+	if _, err := parseProductFilters(c); err != nil {
+		c.Error(http_middleware.BadRequest(err))
+		return
+	}
+
+	h.enqueue(c, JobTypeProductExport, ExportJobParams{Format: format, Query: c.Request.URL.RawQuery}, nil)
+}
+
+// Reindex godoc
+//
+//	@Summary		Reindexar a busca textual
+//	@Description	Cria um job que reconstrói o índice da busca textual de todos os produtos, em lotes
+//	@Tags			jobs
+//	@Produce		json
+//	@Success		202	{object}	JobResponse
+//	@Header			202	{string}	Location	"/api/v1/jobs/{id}"
+//	@Failure		503	{object}	http_middleware.ProblemDetails
+//	@Router			/jobs/reindex [post]
+func (h *ProductJobHandler) Reindex(c *gin.Context) {
+	h.enqueue(c, JobTypeProductReindex, nil, nil)
+}
+
+// enqueue cria o job com os parâmetros e responde 202 com o seu endereço
+func (h *ProductJobHandler) enqueue(c *gin.Context, jobType string, params any, input []byte) {
+	var data json.RawMessage
+	if params != nil {
+		var err error
+		if data, err = json.Marshal(params); err != nil {
+			c.Error(err)
+			return
+		}
+	}
+
+	job, err := h.jobs.Enqueue(c.Request.Context(), shared_jobs.NewJob(jobType, data, input, http_middleware.GetCorrelationID(c)))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	respondJobAccepted(c, job)
+}
+
+// runImport importa o arquivo do job. O job executado de novo após uma interrupção
+// recomeça do início: no modo best_effort, as linhas já importadas são ignoradas.
+func (h *ProductJobHandler) runImport(ctx context.Context, job shared_jobs.Job, progress *shared_jobs.Reporter) (shared_jobs.Outcome, error) {
+	var params ImportJobParams
+	if err := json.Unmarshal(job.Params, &params); err != nil {
+		return shared_jobs.Outcome{}, fmt.Errorf("invalid job params: %w", err)
+	}
+
+	parse, err := importParser(params.ContentType)
+	if err != nil {
+		return shared_jobs.Outcome{}, err
+	}
+	rows, err := parse(bytes.NewReader(job.Input))
+	if err != nil {
+		return shared_jobs.Outcome{}, err
+	}
+
+	progress.Report(0, len(rows))
+	report, err := h.products.importProducts(ctx, rows, params.Mode, job.CorrelationID, func(done int) {
+		progress.Report(done, len(rows))
+	})
+	if err != nil {
+		return shared_jobs.Outcome{}, err
+	}
+
+	outcome := shared_jobs.Outcome{Result: report}
+	if report.Aborted() {
+		return outcome, fmt.Errorf("import aborted: %d rows were not imported", len(report.Rows)-report.Created)
+	}
+	return outcome, nil
+}
+
+// runExport grava a exportação em um arquivo de resultDir
+func (h *ProductJobHandler) runExport(ctx context.Context, job shared_jobs.Job, progress *shared_jobs.Reporter) (shared_jobs.Outcome, error) {
+	var params ExportJobParams
+	if err := json.Unmarshal(job.Params, &params); err != nil {
+		return shared_jobs.Outcome{}, fmt.Errorf("invalid job params: %w", err)
+	}
+
+	query := queryContext(params.Query)
+	options, err := parseExportOptions(query)
+	if err != nil {
+		return shared_jobs.Outcome{}, err
+	}
+	criteria, err := parseProductFilters(query)
+	if err != nil {
+		return shared_jobs.Outcome{}, err
+	}
+
+	// O total só serve ao andamento: produtos criados durante a exportação podem excedê-lo
+	criteria.Limit = 1
+	page, err := h.products.repo.Find(ctx, criteria)
+	if err != nil {
+		return shared_jobs.Outcome{}, err
+	}
+	total := page.Total
+	progress.Report(0, total)
+
+	if err := os.MkdirAll(h.resultDir, 0o755); err != nil {
+		return shared_jobs.Outcome{}, fmt.Errorf("erro ao criar o diretório de resultados: %w", err)
+	}
+	path := filepath.Join(h.resultDir, fmt.Sprintf("products-%s.%s", job.ID, params.Format))
+	file, err := os.Create(path)
+	if err != nil {
+		return shared_jobs.Outcome{}, fmt.Errorf("erro ao criar o arquivo da exportação: %w", err)
+	}
+
+	outcome := shared_jobs.Outcome{ResultFile: path, ResultType: exportFormats[params.Format]}
+	w := bufio.NewWriterSize(file, 32*1024)
+	count, err := h.products.exportProducts(ctx, w, params.Format, options, criteria, func(count int) error {
+		progress.Report(count, max(total, count))
+		return nil
+	})
+	if err == nil {
+		err = w.Flush()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	progress.Report(count, max(total, count))
+	outcome.Result = ExportJobResult{Format: params.Format, Products: count}
+	return outcome, err
+}
+
+// runReindex reconstrói o índice da busca textual
+func (h *ProductJobHandler) runReindex(ctx context.Context, job shared_jobs.Job, progress *shared_jobs.Reporter) (shared_jobs.Outcome, error) {
+	done, err := h.products.repo.Reindex(ctx, reindexBatchSize, progress.Report)
+	return shared_jobs.Outcome{Result: ReindexJobResult{Products: done}}, err
+}
+
+// queryContext monta um gin.Context só com a query string, para que os parâmetros guardados
+// no job sejam lidos pelos mesmos parsers das requisições
+func queryContext(rawQuery string) *gin.Context {
+	return &gin.Context{Request: &http.Request{URL: &url.URL{RawQuery: rawQuery}}}
+}
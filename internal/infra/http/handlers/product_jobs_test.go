@@ -0,0 +1,185 @@
+package product_handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	product_entity "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/entity"
+	product_valueobject "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/valueobject"
+	shared_events "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/events"
+	shared_jobs "github.com/williamkoller/golang-domain-driven-design/internal/shared/jobs"
+)
+
+// newProductJobTest monta o pool e as rotas de jobs sobre o repositório, sem iniciar os
+// workers: os testes executam os jobs com pool.RunNext
+func newProductJobTest(t *testing.T, repo *MockProductRepository, name string) (*shared_jobs.Pool, *gin.Engine) {
+	t.Helper()
+
+	pool := shared_jobs.NewPool(shared_jobs.NewInMemoryStore(), shared_jobs.DefaultPoolConfig())
+	products := NewProductHandler(repo, createTestMetrics(name))
+	router := setupJobTestRouter(NewJobHandler(pool), NewProductJobHandler(products, pool, t.TempDir()))
+
+	return pool, router
+}
+
+func postJob(router http.Handler, path, contentType, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// runJob executa o job criado pela resposta e retorna o job consultado em seguida
+func runJob(t *testing.T, pool *shared_jobs.Pool, router http.Handler, w *httptest.ResponseRecorder) JobResponse {
+	t.Helper()
+
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("POST = %d, want 202 (%s)", w.Code, w.Body.String())
+	}
+	created := decodeJob(t, w)
+	if created.Status != shared_jobs.StatusQueued || w.Header().Get("Location") != created.URL {
+		t.Fatalf("POST = %+v with Location %s", created, w.Header().Get("Location"))
+	}
+
+	if ran, err := pool.RunNext(context.Background()); !ran || err != nil {
+		t.Fatalf("RunNext() = %v, %v", ran, err)
+	}
+	return decodeJob(t, serveJobRequest(router, http.MethodGet, created.URL))
+}
+
+func TestProductJobHandler_Import(t *testing.T) {
+	t.Run("best effort", func(t *testing.T) {
+		mockRepo := newImportTestRepository()
+		pool, router := newProductJobTest(t, mockRepo, "job_import_best_effort")
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/jobs/imports?mode=best_effort", strings.NewReader(importCSV))
+		req.Header.Set("Content-Type", "text/csv")
+		req.Header.Set("X-Correlation-ID", "import-42")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		job := runJob(t, pool, router, w)
+		if job.Type != JobTypeProductImport || job.Status != shared_jobs.StatusSucceeded || job.CorrelationID != "import-42" {
+			t.Fatalf("job = %+v", job)
+		}
+		if job.Progress != (shared_jobs.Progress{Processed: 4, Total: 4}) {
+			t.Errorf("progress = %+v, want 4/4", job.Progress)
+		}
+
+		var report ImportReport
+		json.Unmarshal(job.Result, &report)
+		if report.Created != 2 || report.Skipped != 1 || report.Failed != 1 {
+			t.Errorf("report = %+v", report)
+		}
+		if _, ok := mockRepo.products["Monitor"]; !ok {
+			t.Error("the valid rows should be imported")
+		}
+		if len(mockRepo.events) != 2 {
+			t.Fatalf("repository received %d events, want 2", len(mockRepo.events))
+		}
+		for i, event := range mockRepo.events {
+			// Os eventos levam o ID de correlação da requisição que criou o job
+			if envelope, ok := event.(*shared_events.Envelope); !ok || envelope.CorrelationID != "import-42" {
+				t.Errorf("event[%d] = %+v, want correlation import-42", i, event)
+			}
+		}
+	})
+
+	t.Run("all or nothing fails with the report", func(t *testing.T) {
+		mockRepo := newImportTestRepository()
+		pool, router := newProductJobTest(t, mockRepo, "job_import_all_or_nothing")
+
+		job := runJob(t, pool, router, postJob(router, "/api/v1/jobs/imports", "text/csv", importCSV))
+		if job.Status != shared_jobs.StatusFailed || job.Error != "import aborted: 4 rows were not imported" {
+			t.Fatalf("job = %+v", job)
+		}
+
+		var report ImportReport
+		json.Unmarshal(job.Result, &report)
+		if report.Created != 0 || len(report.Rows) != 4 {
+			t.Errorf("report = %+v", report)
+		}
+		if _, ok := mockRepo.products["Mouse"]; ok {
+			t.Error("no row should be imported")
+		}
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		_, router := newProductJobTest(t, NewMockProductRepository(), "job_import_invalid")
+
+		tests := []struct {
+			name        string
+			query       string
+			contentType string
+			body        string
+			want        int
+		}{
+			{name: "invalid mode", query: "?mode=all", contentType: "text/csv", body: importCSV, want: http.StatusBadRequest},
+			{name: "unsupported format", contentType: "application/json", body: "[]", want: http.StatusUnsupportedMediaType},
+			{name: "missing column", contentType: "text/csv", body: "name,sku\nMouse,1\n", want: http.StatusBadRequest},
+			{name: "empty", contentType: "application/x-ndjson", body: "\n", want: http.StatusBadRequest},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				if w := postJob(router, "/api/v1/jobs/imports"+tt.query, tt.contentType, tt.body); w.Code != tt.want {
+					t.Errorf("POST = %d, want %d (%s)", w.Code, tt.want, w.Body.String())
+				}
+			})
+		}
+	})
+}
+
+func TestProductJobHandler_Export(t *testing.T) {
+	mockRepo := NewMockProductRepository()
+	mockRepo.products["Notebook"] = product_entity.Product{ID: "id-1", Name: "Notebook", Sku: 1, Categories: []string{"Electronics"}, Price: product_valueobject.MustBRL(3500), Version: 1}
+	mockRepo.products["Book"] = product_entity.Product{ID: "id-2", Name: "Book", Sku: 2, Categories: []string{"Books"}, Price: product_valueobject.MustBRL(100), Version: 1}
+	pool, router := newProductJobTest(t, mockRepo, "job_export")
+
+	job := runJob(t, pool, router, postJob(router, "/api/v1/jobs/exports?format=ndjson&category=Books", "", ""))
+	if job.Status != shared_jobs.StatusSucceeded || string(job.Result) != `{"format":"ndjson","products":1}` || job.Progress.Processed != 1 {
+		t.Fatalf("job = %+v", job)
+	}
+
+	var params ExportJobParams
+	json.Unmarshal(job.Params, &params)
+	if params.Format != ExportFormatNDJSON || params.Query != "format=ndjson&category=Books" {
+		t.Errorf("params = %+v", params)
+	}
+
+	w := serveJobRequest(router, http.MethodGet, job.ResultURL)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != ContentTypeNDJSON {
+		t.Fatalf("result = %d with Content-Type %s", w.Code, w.Header().Get("Content-Type"))
+	}
+	if lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n"); len(lines) != 1 || !strings.Contains(lines[0], `"Name":"Book"`) {
+		t.Errorf("result = %q", w.Body.String())
+	}
+
+	for _, query := range []string{"?format=xml", "?categories=columns", "?min_price=-1"} {
+		if w := postJob(router, "/api/v1/jobs/exports"+query, "", ""); w.Code != http.StatusBadRequest {
+			t.Errorf("POST %s = %d, want 400", query, w.Code)
+		}
+	}
+}
+
+func TestProductJobHandler_Reindex(t *testing.T) {
+	mockRepo := NewMockProductRepository()
+	mockRepo.products["Notebook"] = product_entity.Product{ID: "id-1", Name: "Notebook", Sku: 1, Categories: []string{"Electronics"}, Price: product_valueobject.MustBRL(3500), Version: 1}
+	pool, router := newProductJobTest(t, mockRepo, "job_reindex")
+
+	job := runJob(t, pool, router, postJob(router, "/api/v1/jobs/reindex", "", ""))
+	if job.Status != shared_jobs.StatusSucceeded || string(job.Result) != `{"products":1}` || job.Progress != (shared_jobs.Progress{Processed: 1, Total: 1}) {
+		t.Errorf("job = %+v", job)
+	}
+	if job.ResultURL != "" {
+		t.Errorf("result_url = %q, want none", job.ResultURL)
+	}
+}
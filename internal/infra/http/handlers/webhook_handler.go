@@ -0,0 +1,263 @@
+package product_handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	webhook_entity "github.com/williamkoller/golang-domain-driven-design/internal/domain/webhook/entity"
+	webhook_repository "github.com/williamkoller/golang-domain-driven-design/internal/domain/webhook/repository"
+	http_middleware "github.com/williamkoller/golang-domain-driven-design/internal/infra/http/middleware"
+	shared_identity "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/identity"
+	shared_validation "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/validation"
+)
+
+// Erros do domínio de webhooks
+func init() {
+	http_middleware.RegisterErrors(
+		http_middleware.MapNotFound(webhook_repository.ErrWebhookNotFound),
+		http_middleware.MapAlreadyExists(webhook_repository.ErrWebhookAlreadyExists),
+		http_middleware.MapUnavailable(webhook_repository.ErrUnavailable),
+	)
+}
+
+type WebhookHandler struct {
+	repo webhook_repository.IWebhookRepository
+}
+
+func NewWebhookHandler(repo webhook_repository.IWebhookRepository) *WebhookHandler {
+	return &WebhookHandler{repo}
+}
+
+// CreateWebhookInput representa os dados de entrada para criar um webhook
+type CreateWebhookInput struct {
+	URL    string   `json:"url" binding:"required" example:"https://partner.example.com/hooks"`
+	Events []string `json:"events" binding:"required" example:"product.*"`
+	// Segredo da assinatura; gerado automaticamente quando ausente
+	Secret string `json:"secret,omitempty" example:"3c6e0b8a9c15224a8228b9a98ca1531d"`
+}
+
+// UpdateWebhookInput representa os dados de entrada para substituir um webhook
+type UpdateWebhookInput struct {
+	URL    string   `json:"url" binding:"required" example:"https://partner.example.com/hooks"`
+	Events []string `json:"events" binding:"required" example:"product.*"`
+	// Novo segredo da assinatura; ausente mantém o atual
+	Secret string `json:"secret,omitempty" example:"3c6e0b8a9c15224a8228b9a98ca1531d"`
+	Active *bool  `json:"active" binding:"required" example:"true"`
+}
+
+// WebhookSecretResponse representa o webhook com o segredo, exibido só na criação e na troca do segredo
+type WebhookSecretResponse struct {
+	webhook_entity.Webhook
+	Secret string `json:"secret" example:"3c6e0b8a9c15224a8228b9a98ca1531d"`
+}
+
+// WebhookListResponse representa os webhooks cadastrados
+type WebhookListResponse struct {
+	Items []webhook_entity.Webhook `json:"items"`
+	Total int                      `json:"total" example:"2"`
+}
+
+// WebhookDeliveryListResponse representa as últimas entregas de um webhook
+type WebhookDeliveryListResponse struct {
+	Items []webhook_entity.Delivery `json:"items"`
+	Total int                       `json:"total" example:"20"`
+}
+
+// Create godoc
+//
+//	@Summary		Criar um webhook
+//	@Description	Inscreve uma URL para receber os eventos cujo nome casa com os padrões informados. O segredo da assinatura só é retornado nesta resposta.
+//	@Tags			webhooks
+//	@Accept			json
+//	@Produce		json
+//	@Param			webhook	body		CreateWebhookInput	true	"Dados do webhook"
+//	@Success		201		{object}	WebhookSecretResponse
+//	@Failure		400		{object}	http_middleware.ProblemDetails
+//	@Router			/webhooks [post]
+func (h *WebhookHandler) Create(c *gin.Context) {
+	var input CreateWebhookInput
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(http_middleware.BindingError(err, &input))
+		return
+	}
+
+	webhook, err := webhook_entity.NewWebhook(input.URL, input.Events, input.Secret)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.repo.Add(c.Request.Context(), *webhook); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, WebhookSecretResponse{Webhook: *webhook, Secret: webhook.Secret})
+}
+
+// FindAll godoc
+//
+//	@Summary		Listar webhooks
+//	@Description	Lista os webhooks cadastrados, do mais antigo para o mais recente
+//	@Tags			webhooks
+//	@Produce		json
+//	@Success		200	{object}	WebhookListResponse
+//	@Failure		500	{object}	http_middleware.ProblemDetails
+//	@Router			/webhooks [get]
+func (h *WebhookHandler) FindAll(c *gin.Context) {
+	webhooks, err := h.repo.FindAll(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, WebhookListResponse{Items: webhooks, Total: len(webhooks)})
+}
+
+// FindByID godoc
+//
+//	@Summary		Buscar webhook
+//	@Description	Retorna um webhook pelo ID
+//	@Tags			webhooks
+//	@Produce		json
+//	@Param			id	path		string	true	"ID do webhook (UUID)"
+//	@Success		200	{object}	webhook_entity.Webhook
+//	@Failure		400	{object}	http_middleware.ProblemDetails
+//	@Failure		404	{object}	http_middleware.ProblemDetails
+//	@Router			/webhooks/{id} [get]
+func (h *WebhookHandler) FindByID(c *gin.Context) {
+	webhook, ok := h.find(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+// Update godoc
+//
+//	@Summary		Atualizar webhook
+//	@Description	Substitui a URL, os padrões de eventos e o estado do webhook. Um novo segredo, se informado, é retornado na resposta.
+//	@Tags			webhooks
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string				true	"ID do webhook (UUID)"
+//	@Param			webhook	body		UpdateWebhookInput	true	"Dados do webhook"
+//	@Success		200		{object}	webhook_entity.Webhook
+//	@Failure		400		{object}	http_middleware.ProblemDetails
+//	@Failure		404		{object}	http_middleware.ProblemDetails
+//	@Router			/webhooks/{id} [put]
+func (h *WebhookHandler) Update(c *gin.Context) {
+	var input UpdateWebhookInput
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(http_middleware.BindingError(err, &input))
+		return
+	}
+
+	webhook, ok := h.find(c)
+	if !ok {
+		return
+	}
+
+	if err := webhook.Update(input.URL, input.Events, input.Secret, *input.Active); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.repo.Update(c.Request.Context(), webhook); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if input.Secret != "" {
+		c.JSON(http.StatusOK, WebhookSecretResponse{Webhook: webhook, Secret: webhook.Secret})
+		return
+	}
+	c.JSON(http.StatusOK, webhook)
+}
+
+// Delete godoc
+//
+//	@Summary		Excluir webhook
+//	@Description	Remove o webhook e o seu log de entregas
+//	@Tags			webhooks
+//	@Param			id	path	string	true	"ID do webhook (UUID)"
+//	@Success		204
+//	@Failure		400	{object}	http_middleware.ProblemDetails
+//	@Failure		404	{object}	http_middleware.ProblemDetails
+//	@Router			/webhooks/{id} [delete]
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.Delete(c.Request.Context(), id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Deliveries godoc
+//
+//	@Summary		Listar entregas do webhook
+//	@Description	Retorna as últimas tentativas de entrega do webhook, da mais recente para a mais antiga
+//	@Tags			webhooks
+//	@Produce		json
+//	@Param			id		path		string	true	"ID do webhook (UUID)"
+//	@Param			limit	query		int		false	"Máximo de entregas (padrão 20, máximo 100)"
+//	@Success		200		{object}	WebhookDeliveryListResponse
+//	@Failure		400		{object}	http_middleware.ProblemDetails
+//	@Failure		404		{object}	http_middleware.ProblemDetails
+//	@Router			/webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) Deliveries(c *gin.Context) {
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+
+	limit, err := parseLimit(c)
+	if err != nil {
+		c.Error(shared_validation.Errors{shared_validation.NewError("limit", shared_validation.CodeInvalid, err.Error())})
+		return
+	}
+
+	deliveries, err := h.repo.Deliveries(c.Request.Context(), id, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, WebhookDeliveryListResponse{Items: deliveries, Total: len(deliveries)})
+}
+
+// find busca o webhook do parâmetro id, registrando o erro quando não encontrado
+func (h *WebhookHandler) find(c *gin.Context) (webhook_entity.Webhook, bool) {
+	id, ok := webhookID(c)
+	if !ok {
+		return webhook_entity.Webhook{}, false
+	}
+
+	webhook, err := h.repo.FindByID(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return webhook_entity.Webhook{}, false
+	}
+
+	return webhook, true
+}
+
+// webhookID lê o parâmetro id, registrando um erro 400 se não for um UUID
+func webhookID(c *gin.Context) (string, bool) {
+	id := c.Param("id")
+	if !shared_identity.IsValidUUID(id) {
+		c.Error(http_middleware.BadRequest(errors.New("invalid webhook id")))
+		return "", false
+	}
+	return id, true
+}
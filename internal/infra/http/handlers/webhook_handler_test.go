@@ -0,0 +1,181 @@
+package product_handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	webhook_entity "github.com/williamkoller/golang-domain-driven-design/internal/domain/webhook/entity"
+	webhook_repository "github.com/williamkoller/golang-domain-driven-design/internal/domain/webhook/repository"
+	http_middleware "github.com/williamkoller/golang-domain-driven-design/internal/infra/http/middleware"
+)
+
+const missingWebhookID = "3f2b8c1e-4d5a-4b6c-8d7e-9f0a1b2c3d4e"
+
+func setupWebhookTestRouter(handler *WebhookHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(http_middleware.ErrorHandler())
+	r.POST("/api/v1/webhooks", handler.Create)
+	r.GET("/api/v1/webhooks", handler.FindAll)
+	r.GET("/api/v1/webhooks/:id", handler.FindByID)
+	r.PUT("/api/v1/webhooks/:id", handler.Update)
+	r.DELETE("/api/v1/webhooks/:id", handler.Delete)
+	r.GET("/api/v1/webhooks/:id/deliveries", handler.Deliveries)
+	return r
+}
+
+func serveWebhook(router *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestWebhookHandler_Create(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedFields []string
+	}{
+		{name: "generated secret", body: `{"url":"https://partner.example.com/hooks","events":["product.*"]}`, expectedStatus: http.StatusCreated},
+		{name: "informed secret", body: `{"url":"https://partner.example.com/hooks","events":["product.created"],"secret":"0123456789abcdef"}`, expectedStatus: http.StatusCreated},
+		{name: "missing events", body: `{"url":"https://partner.example.com/hooks"}`, expectedStatus: http.StatusBadRequest},
+		{name: "invalid url", body: `{"url":"partner.example.com","events":["product.*"]}`, expectedStatus: http.StatusBadRequest, expectedFields: []string{"url"}},
+		{name: "invalid pattern", body: `{"url":"https://partner.example.com/hooks","events":["product.["]}`, expectedStatus: http.StatusBadRequest, expectedFields: []string{"events"}},
+		{name: "short secret", body: `{"url":"https://partner.example.com/hooks","events":["product.*"],"secret":"short"}`, expectedStatus: http.StatusBadRequest, expectedFields: []string{"secret"}},
+		{name: "every violation", body: `{"url":"ftp://partner.example.com","events":[""],"secret":"short"}`, expectedStatus: http.StatusBadRequest, expectedFields: []string{"url", "events", "secret"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := webhook_repository.NewRepository()
+			w := serveWebhook(setupWebhookTestRouter(NewWebhookHandler(repo)), http.MethodPost, "/api/v1/webhooks", tt.body)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d. Body: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.expectedFields != nil {
+				assertProblemFields(t, w, tt.expectedFields...)
+			}
+			if tt.expectedStatus != http.StatusCreated {
+				return
+			}
+
+			var response WebhookSecretResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			stored, err := repo.FindByID(context.Background(), response.ID)
+			if err != nil || response.Secret == "" || stored.Secret != response.Secret || !response.Active {
+				t.Errorf("response = %+v, stored = %+v, err = %v", response, stored, err)
+			}
+		})
+	}
+}
+
+func TestWebhookHandler_Lifecycle(t *testing.T) {
+	repo := webhook_repository.NewRepository()
+	webhook, _ := webhook_entity.NewWebhook("https://partner.example.com/hooks", []string{"product.*"}, "0123456789abcdef")
+	_ = repo.Add(context.Background(), *webhook)
+	_ = repo.AddDelivery(context.Background(), *webhook_entity.NewDelivery(webhook.ID, "event-1", "product.created", 1))
+	_ = repo.AddDelivery(context.Background(), *webhook_entity.NewDelivery(webhook.ID, "event-1", "product.created", 2))
+
+	router := setupWebhookTestRouter(NewWebhookHandler(repo))
+	path := "/api/v1/webhooks/" + webhook.ID
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+		check          func(t *testing.T, body []byte)
+	}{
+		{
+			name: "list", method: http.MethodGet, path: "/api/v1/webhooks", expectedStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var response WebhookListResponse
+				_ = json.Unmarshal(body, &response)
+				if response.Total != 1 || response.Items[0].ID != webhook.ID || bytes.Contains(body, []byte("0123456789abcdef")) {
+					t.Errorf("response = %s", body)
+				}
+			},
+		},
+		{name: "find", method: http.MethodGet, path: path, expectedStatus: http.StatusOK},
+		{name: "find invalid id", method: http.MethodGet, path: "/api/v1/webhooks/abc", expectedStatus: http.StatusBadRequest},
+		{name: "find missing", method: http.MethodGet, path: "/api/v1/webhooks/" + missingWebhookID, expectedStatus: http.StatusNotFound},
+		{
+			name: "deliveries", method: http.MethodGet, path: path + "/deliveries?limit=1", expectedStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var response WebhookDeliveryListResponse
+				_ = json.Unmarshal(body, &response)
+				if response.Total != 1 || response.Items[0].Attempt != 2 {
+					t.Errorf("response = %s", body)
+				}
+			},
+		},
+		{
+			name: "deliveries invalid limit", method: http.MethodGet, path: path + "/deliveries?limit=0", expectedStatus: http.StatusBadRequest,
+			check: func(t *testing.T, body []byte) {
+				if !bytes.Contains(body, []byte(`"field":"limit"`)) {
+					t.Errorf("response = %s, want a validation error on limit", body)
+				}
+			},
+		},
+		{name: "deliveries missing webhook", method: http.MethodGet, path: "/api/v1/webhooks/" + missingWebhookID + "/deliveries", expectedStatus: http.StatusNotFound},
+		{
+			name: "update keeps secret", method: http.MethodPut, path: path, expectedStatus: http.StatusOK,
+			body: `{"url":"https://partner.example.com/v2","events":["product.created"],"active":false}`,
+			check: func(t *testing.T, body []byte) {
+				stored, _ := repo.FindByID(context.Background(), webhook.ID)
+				if stored.URL != "https://partner.example.com/v2" || stored.Active || stored.Secret != "0123456789abcdef" || bytes.Contains(body, []byte(`"secret"`)) {
+					t.Errorf("stored = %+v, response = %s", stored, body)
+				}
+			},
+		},
+		{
+			name: "update rotates secret", method: http.MethodPut, path: path, expectedStatus: http.StatusOK,
+			body: `{"url":"https://partner.example.com/v2","events":["product.created"],"active":true,"secret":"fedcba9876543210"}`,
+			check: func(t *testing.T, body []byte) {
+				var response WebhookSecretResponse
+				_ = json.Unmarshal(body, &response)
+				if response.Secret != "fedcba9876543210" || !response.Active {
+					t.Errorf("response = %s", body)
+				}
+			},
+		},
+		{name: "update without active", method: http.MethodPut, path: path, body: `{"url":"https://partner.example.com/v2","events":["product.created"]}`, expectedStatus: http.StatusBadRequest},
+		{
+			name: "update invalid", method: http.MethodPut, path: path, expectedStatus: http.StatusBadRequest,
+			body: `{"url":"https://partner.example.com/v2","events":["product.["],"active":true}`,
+			check: func(t *testing.T, body []byte) {
+				if !bytes.Contains(body, []byte(`"field":"events"`)) {
+					t.Errorf("response = %s, want a validation error on events", body)
+				}
+			},
+		},
+		{name: "update missing", method: http.MethodPut, path: "/api/v1/webhooks/" + missingWebhookID, body: `{"url":"https://partner.example.com/v2","events":["product.*"],"active":true}`, expectedStatus: http.StatusNotFound},
+		{name: "delete", method: http.MethodDelete, path: path, expectedStatus: http.StatusNoContent},
+		{name: "delete missing", method: http.MethodDelete, path: path, expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serveWebhook(router, tt.method, tt.path, tt.body)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d. Body: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.check != nil {
+				tt.check(t, w.Body.Bytes())
+			}
+		})
+	}
+}
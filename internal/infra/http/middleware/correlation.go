@@ -0,0 +1,38 @@
+package http_middleware
+
+import (
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	shared_identity "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/identity"
+)
+
+// CorrelationIDHeader é o header que carrega o ID de correlação da requisição
+const CorrelationIDHeader = "X-Correlation-ID"
+
+const correlationIDKey = "correlation_id"
+
+// Aceita IDs gerados por outros serviços, desde que curtos e sem caracteres especiais
+var correlationIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,64}$`)
+
+// CorrelationID associa um ID de correlação a cada requisição: reutiliza o recebido no
+// header X-Correlation-ID ou gera um novo, e o devolve no mesmo header da resposta.
+// Os eventos gerados pela requisição carregam esse ID no envelope.
+func CorrelationID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(CorrelationIDHeader)
+		if !correlationIDPattern.MatchString(id) {
+			id = shared_identity.NewUUID()
+		}
+
+		c.Set(correlationIDKey, id)
+		c.Header(CorrelationIDHeader, id)
+
+		c.Next()
+	}
+}
+
+// GetCorrelationID retorna o ID de correlação da requisição, ou vazio sem o middleware
+func GetCorrelationID(c *gin.Context) string {
+	return c.GetString(correlationIDKey)
+}
@@ -0,0 +1,60 @@
+package http_middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	shared_identity "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/identity"
+)
+
+func TestCorrelationID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(CorrelationID())
+	r.GET("/test", func(c *gin.Context) {
+		c.String(http.StatusOK, GetCorrelationID(c))
+	})
+
+	tests := []struct {
+		name     string
+		header   string
+		wantSame bool
+	}{
+		{name: "reuses the received id", header: "req-123:abc", wantSame: true},
+		{name: "generates an id when missing", header: ""},
+		{name: "replaces an invalid id", header: "bad id\n"},
+		{name: "replaces a too long id", header: strings.Repeat("a", 65)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			if tt.header != "" {
+				req.Header.Set(CorrelationIDHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			got := w.Header().Get(CorrelationIDHeader)
+			if got != w.Body.String() {
+				t.Errorf("header = %q, context = %q; want equal", got, w.Body.String())
+			}
+			if tt.wantSame && got != tt.header {
+				t.Errorf("correlation id = %q, want %q", got, tt.header)
+			}
+			if !tt.wantSame && !shared_identity.IsValidUUID(got) {
+				t.Errorf("correlation id = %q, want a generated uuid", got)
+			}
+		})
+	}
+}
+
+func TestGetCorrelationID_WithoutMiddleware(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	if got := GetCorrelationID(c); got != "" {
+		t.Errorf("GetCorrelationID() = %q, want empty", got)
+	}
+}
@@ -0,0 +1,225 @@
+package http_middleware
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	product_errors "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/errors"
+	shared_validation "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/validation"
+	shared_idempotency "github.com/williamkoller/golang-domain-driven-design/internal/shared/idempotency"
+)
+
+// StatusClientClosedRequest é o status registrado quando o cliente desconecta antes da
+// resposta (convenção do nginx); o cliente não chega a recebê-lo
+const StatusClientClosedRequest = 499
+
+// ErrorMapping associa um tipo de erro ao problema da resposta
+type ErrorMapping struct {
+	Kind    error
+	Status  int
+	Problem string
+	Title   string
+	// Message substitui a mensagem do erro no detail, para não expor a causa
+	Message string
+}
+
+// errorMappings são os tipos de erro reconhecidos, na ordem de verificação: os da própria
+// requisição e os registrados pelos domínios com RegisterErrors
+var errorMappings = []ErrorMapping{
+	{Kind: errBadRequest, Status: http.StatusBadRequest, Problem: "bad-request", Title: "Invalid request"},
+	{Kind: shared_validation.ErrValidation, Status: http.StatusBadRequest, Problem: "validation-error", Title: "Validation failed"},
+	{Kind: shared_idempotency.ErrKeyReused, Status: http.StatusUnprocessableEntity, Problem: "idempotency-key-reused", Title: "Idempotency key reused"},
+	{Kind: shared_idempotency.ErrRequestInProgress, Status: http.StatusConflict, Problem: "request-in-progress", Title: "Request in progress"},
+}
+
+// timeoutMapping é verificado por último, para que um tipo registrado que envolve o
+// timeout (a indisponibilidade do banco, por exemplo) tenha precedência
+var timeoutMapping = ErrorMapping{Kind: context.DeadlineExceeded, Status: http.StatusServiceUnavailable, Problem: "timeout", Title: "Request timed out", Message: "request timed out"}
+
+// RegisterErrors acrescenta os tipos de erro de um domínio aos respondidos pelo
+// ErrorHandler. Os tipos são verificados na ordem de registro, então um tipo mais
+// específico deve vir antes do mais geral que ele também satisfaz. Deve ser chamada na
+// inicialização (init), antes de servir requisições.
+func RegisterErrors(mappings ...ErrorMapping) {
+	errorMappings = append(errorMappings, mappings...)
+}
+
+// MapInvalid responde o tipo de erro com 400, como um erro nos dados da requisição
+func MapInvalid(kind error) ErrorMapping {
+	return ErrorMapping{Kind: kind, Status: http.StatusBadRequest, Problem: "bad-request", Title: "Invalid request"}
+}
+
+// MapNotFound responde o tipo de erro com 404
+func MapNotFound(kind error) ErrorMapping {
+	return ErrorMapping{Kind: kind, Status: http.StatusNotFound, Problem: "not-found", Title: "Resource not found"}
+}
+
+// MapAlreadyExists responde o tipo de erro com 409, como um recurso duplicado
+func MapAlreadyExists(kind error) ErrorMapping {
+	return ErrorMapping{Kind: kind, Status: http.StatusConflict, Problem: "already-exists", Title: "Resource already exists"}
+}
+
+// MapConflict responde o tipo de erro com 409, como uma operação inválida no estado atual
+func MapConflict(kind error) ErrorMapping {
+	return ErrorMapping{Kind: kind, Status: http.StatusConflict, Problem: "conflict", Title: "State conflict"}
+}
+
+// MapUnavailable responde o tipo de erro com 503; o detail é só a mensagem do tipo, sem a causa
+func MapUnavailable(kind error) ErrorMapping {
+	return ErrorMapping{Kind: kind, Status: http.StatusServiceUnavailable, Problem: "unavailable", Title: "Service unavailable", Message: kind.Error()}
+}
+
+var errBadRequest = errors.New("bad request")
+
+// requestError é um erro nos dados da requisição (corpo, parâmetros)
+type requestError struct {
+	err    error
+	detail string
+	fields []FieldError
+}
+
+// BadRequest marca err como um erro nos dados da requisição, respondido com 400
+func BadRequest(err error) error {
+	return &requestError{err: err, detail: err.Error()}
+}
+
+func (e *requestError) Error() string        { return e.err.Error() }
+func (e *requestError) Is(target error) bool { return target == errBadRequest }
+func (e *requestError) Unwrap() error        { return e.err }
+
+// statusError é um erro que o handler responde com um status específico
+type statusError struct {
+	status int
+	err    error
+}
+
+// WithStatus faz o erro ser respondido com status, quando os tipos registrados não se aplicam
+// (por exemplo, 502 para a falha de um serviço externo)
+func WithStatus(status int, err error) error {
+	return &statusError{status, err}
+}
+
+func (e *statusError) Error() string { return e.err.Error() }
+func (e *statusError) Unwrap() error { return e.err }
+
+// ErrorHandler responde os erros registrados pelos handlers com c.Error como
+// application/problem+json (RFC 7807): o último erro define o problema. Erros sem tipo
+// conhecido viram 500 sem expor a causa.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		respondError(c)
+	}
+}
+
+// respondError responde com o problema do último erro registrado na requisição
+func respondError(c *gin.Context) {
+	err := c.Errors.Last().Err
+	problem := ProblemFor(err)
+
+	// Cliente desconectado: a consulta foi cancelada por isso, não por falha do banco
+	if errors.Is(c.Request.Context().Err(), context.Canceled) {
+		problem = ProblemDetails{
+			Type:   "about:blank",
+			Title:  "Client Closed Request",
+			Status: StatusClientClosedRequest,
+			Detail: "client closed request",
+		}
+	}
+
+	if problem.Status >= http.StatusInternalServerError {
+		log.Printf("❌ %s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+	}
+
+	problem.Instance = c.Request.URL.Path
+	WriteProblem(c, problem)
+}
+
+// ProblemFor monta o problema da resposta para o erro, sem o instance
+func ProblemFor(err error) ProblemDetails {
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		return ProblemDetails{
+			Type:   "about:blank",
+			Title:  http.StatusText(statusErr.status),
+			Status: statusErr.status,
+			Detail: statusErr.err.Error(),
+		}
+	}
+
+	for _, mapping := range errorMappings {
+		if errors.Is(err, mapping.Kind) {
+			return mapping.problem(err)
+		}
+	}
+	if errors.Is(err, timeoutMapping.Kind) {
+		return timeoutMapping.problem(err)
+	}
+
+	return ProblemDetails{
+		Type:   "about:blank",
+		Title:  http.StatusText(http.StatusInternalServerError),
+		Status: http.StatusInternalServerError,
+		Detail: "internal server error",
+	}
+}
+
+// problem monta o problema da resposta para err, um erro do tipo m.Kind
+func (m ErrorMapping) problem(err error) ProblemDetails {
+	problem := ProblemDetails{
+		Type:   ProblemTypePrefix + m.Problem,
+		Title:  m.Title,
+		Status: m.Status,
+		Detail: m.Message,
+	}
+	if problem.Detail == "" {
+		problem.Detail, problem.Errors = publicDetail(err, m.Kind)
+	}
+	return problem
+}
+
+// publicDetail retorna a mensagem e os campos inválidos do erro do domínio ou da requisição,
+// sem o contexto acrescentado pelas camadas de infraestrutura ("erro ao inserir produto: ...")
+func publicDetail(err, kind error) (string, []FieldError) {
+	var violations product_errors.ValidationErrors
+	if errors.As(err, &violations) {
+		fields := make([]FieldError, len(violations))
+		for i, violation := range violations {
+			fields[i] = FieldError{Field: violation.Field, Code: violation.Code, Message: violation.Message}
+		}
+		return violations.Error(), fields
+	}
+
+	var sharedViolations shared_validation.Errors
+	if errors.As(err, &sharedViolations) {
+		fields := make([]FieldError, len(sharedViolations))
+		for i, violation := range sharedViolations {
+			fields[i] = FieldError{Field: violation.Field, Code: violation.Code, Message: violation.Message}
+		}
+		return sharedViolations.Error(), fields
+	}
+
+	var domainErr *product_errors.Error
+	if errors.As(err, &domainErr) {
+		var fields []FieldError
+		if domainErr.Field != "" && domainErr.Code != "" {
+			fields = []FieldError{{Field: domainErr.Field, Code: domainErr.Code, Message: domainErr.Message}}
+		}
+		return domainErr.Message, fields
+	}
+
+	var reqErr *requestError
+	if errors.As(err, &reqErr) {
+		return reqErr.detail, reqErr.fields
+	}
+
+	return kind.Error(), nil
+}
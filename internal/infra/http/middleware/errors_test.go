@@ -0,0 +1,159 @@
+package http_middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	product_errors "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/errors"
+	shared_validation "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/validation"
+)
+
+// Tipos de erro de teste, registrados como os handlers registram os do seu domínio;
+// errTestStale também é um errTestConflict e por isso vem antes
+var (
+	errTestNotFound    = errors.New("item not found")
+	errTestExists      = errors.New("item already exists")
+	errTestConflict    = errors.New("item state conflict")
+	errTestStale       = fmt.Errorf("item was modified: %w", errTestConflict)
+	errTestUnavailable = errors.New("item storage unavailable")
+)
+
+func init() {
+	RegisterErrors(
+		MapNotFound(errTestNotFound),
+		MapAlreadyExists(errTestExists),
+		ErrorMapping{Kind: errTestStale, Status: http.StatusPreconditionFailed, Problem: "precondition-failed", Title: "Precondition failed"},
+		MapConflict(errTestConflict),
+		MapUnavailable(errTestUnavailable),
+	)
+}
+
+func TestProblemFor(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantType   string
+		wantDetail string
+		wantFields []FieldError
+	}{
+		{name: "bad request", err: BadRequest(errors.New("invalid limit")), wantStatus: http.StatusBadRequest, wantType: "/problems/bad-request", wantDetail: "invalid limit"},
+		{
+			name: "invalid fields",
+			err: BadRequest(product_errors.ValidationErrors{
+				product_errors.NewValidationError("name", product_errors.CodeRequired, "name is required"),
+				product_errors.NewValidationError("price", product_errors.CodeMustBePositive, "price must be positive"),
+			}),
+			wantStatus: http.StatusBadRequest,
+			wantType:   "/problems/bad-request",
+			wantDetail: "name is required; price must be positive",
+			wantFields: []FieldError{
+				{Field: "name", Code: "required", Message: "name is required"},
+				{Field: "price", Code: "must_be_positive", Message: "price must be positive"},
+			},
+		},
+		{
+			name: "shared validation",
+			err: shared_validation.Errors{
+				shared_validation.NewError("slug", shared_validation.CodeInvalid, "invalid slug"),
+				shared_validation.NewError("parent_id", shared_validation.CodeInvalid, "invalid parent_id"),
+			},
+			wantStatus: http.StatusBadRequest,
+			wantType:   "/problems/validation-error",
+			wantDetail: "invalid slug; invalid parent_id",
+			wantFields: []FieldError{
+				{Field: "slug", Code: "invalid", Message: "invalid slug"},
+				{Field: "parent_id", Code: "invalid", Message: "invalid parent_id"},
+			},
+		},
+		{name: "not found", err: fmt.Errorf("erro ao buscar item: %w", errTestNotFound), wantStatus: http.StatusNotFound, wantType: "/problems/not-found", wantDetail: "item not found"},
+		{name: "already exists", err: fmt.Errorf("erro ao inserir item: %w", errTestExists), wantStatus: http.StatusConflict, wantType: "/problems/already-exists", wantDetail: "item already exists"},
+		{name: "state conflict", err: errTestConflict, wantStatus: http.StatusConflict, wantType: "/problems/conflict", wantDetail: "item state conflict"},
+		{name: "specific kind first", err: fmt.Errorf("erro ao atualizar item: %w", errTestStale), wantStatus: http.StatusPreconditionFailed, wantType: "/problems/precondition-failed", wantDetail: "item was modified: item state conflict"},
+		{name: "unavailable hides the cause", err: fmt.Errorf("%w: dial tcp: connection refused", errTestUnavailable), wantStatus: http.StatusServiceUnavailable, wantType: "/problems/unavailable", wantDetail: "item storage unavailable"},
+		{name: "deadline", err: fmt.Errorf("erro ao listar itens: %w", context.DeadlineExceeded), wantStatus: http.StatusServiceUnavailable, wantType: "/problems/timeout", wantDetail: "request timed out"},
+		{name: "registered kind before the deadline", err: fmt.Errorf("%w: %w", errTestUnavailable, context.DeadlineExceeded), wantStatus: http.StatusServiceUnavailable, wantType: "/problems/unavailable", wantDetail: "item storage unavailable"},
+		{name: "explicit status", err: WithStatus(http.StatusBadGateway, errors.New("handler failed")), wantStatus: http.StatusBadGateway, wantType: "about:blank", wantDetail: "handler failed"},
+		{name: "unknown", err: errors.New("pq: syntax error"), wantStatus: http.StatusInternalServerError, wantType: "about:blank", wantDetail: "internal server error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problem := ProblemFor(tt.err)
+			if problem.Status != tt.wantStatus || problem.Type != tt.wantType || problem.Detail != tt.wantDetail || problem.Title == "" {
+				t.Errorf("ProblemFor() = %+v; want status %d, type %q, detail %q", problem, tt.wantStatus, tt.wantType, tt.wantDetail)
+			}
+			if !reflect.DeepEqual(problem.Errors, tt.wantFields) {
+				t.Errorf("ProblemFor() errors = %+v, want %+v", problem.Errors, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestErrorHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(ErrorHandler())
+	r.GET("/not-found", func(c *gin.Context) {
+		c.Error(errTestNotFound)
+	})
+	r.GET("/written", func(c *gin.Context) {
+		c.Error(errTestNotFound)
+		c.JSON(http.StatusAccepted, gin.H{"status": "accepted"})
+	})
+	r.GET("/ok", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+
+	tests := []struct {
+		name       string
+		path       string
+		cancel     bool
+		wantStatus int
+		wantDetail string
+	}{
+		{name: "maps the error", path: "/not-found", wantStatus: http.StatusNotFound, wantDetail: "item not found"},
+		{name: "keeps a written response", path: "/written", wantStatus: http.StatusAccepted},
+		{name: "no errors", path: "/ok", wantStatus: http.StatusOK},
+		{name: "client closed request", path: "/not-found", cancel: true, wantStatus: StatusClientClosedRequest, wantDetail: "client closed request"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.cancel {
+				ctx, cancel := context.WithCancel(req.Context())
+				cancel()
+				req = req.WithContext(ctx)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+
+			if tt.wantDetail == "" {
+				return
+			}
+
+			var problem ProblemDetails
+			if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+				t.Fatalf("invalid body %q: %v", w.Body.String(), err)
+			}
+			if got := w.Header().Get("Content-Type"); got != ProblemContentType {
+				t.Errorf("Content-Type = %q, want %q", got, ProblemContentType)
+			}
+			if problem.Status != tt.wantStatus || problem.Detail != tt.wantDetail || problem.Instance != tt.path {
+				t.Errorf("problem = %+v; want status %d, detail %q, instance %q", problem, tt.wantStatus, tt.wantDetail, tt.path)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package http_middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	shared_idempotency "github.com/williamkoller/golang-domain-driven-design/internal/shared/idempotency"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	// DefaultIdempotencyTTL é por quanto tempo a resposta de uma chave é guardada
+	DefaultIdempotencyTTL = 24 * time.Hour
+
+	maxIdempotencyKeyLength = 255
+
+	// maxIdempotentBodyBytes limita o corpo lido para calcular o fingerprint; as rotas com
+	// Idempotency-Key recebem um único recurso em JSON
+	maxIdempotentBodyBytes = 1 << 20
+)
+
+var errIdempotentBodyTooLarge = fmt.Errorf("request body exceeds %d bytes", maxIdempotentBodyBytes)
+
+// replayedHeaders são os headers da resposta original repetidos nos reenvios
+var replayedHeaders = []string{"Content-Type", "Location", "ETag", "Last-Modified"}
+
+// Idempotency torna a rota segura para reenvios com o header Idempotency-Key: a primeira
+// requisição com a chave é executada e a resposta guardada por ttl; um reenvio com o mesmo
+// método, URI e corpo recebe a mesma resposta sem executar o handler de novo. A chave
+// reutilizada com outra requisição é recusada com 422, e um reenvio enquanto a original
+// ainda está em andamento com 409. Respostas 5xx não são guardadas, para que o cliente
+// possa tentar de novo. Corpos maiores que 1 MiB são recusados com 413. Requisições sem o
+// header seguem normalmente.
+func Idempotency(store shared_idempotency.Store, ttl time.Duration) gin.HandlerFunc {
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyTTL
+	}
+
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if !validIdempotencyKey(key) {
+			c.Error(BadRequest(errors.New("invalid idempotency key")))
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxIdempotentBodyBytes))
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				c.Error(WithStatus(http.StatusRequestEntityTooLarge, errIdempotentBodyTooLarge))
+			} else {
+				c.Error(BadRequest(errors.New("invalid request body")))
+			}
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		fingerprint := shared_idempotency.Fingerprint([]byte(c.Request.Method), []byte(c.Request.URL.RequestURI()), body)
+
+		record, reserved, err := store.Reserve(c.Request.Context(), key, fingerprint, ttl)
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+
+		if !reserved {
+			switch {
+			case record.Fingerprint != fingerprint:
+				c.Error(shared_idempotency.ErrKeyReused)
+			case record.Response == nil:
+				c.Error(shared_idempotency.ErrRequestInProgress)
+			default:
+				replay(c, record.Response)
+			}
+			c.Abort()
+			return
+		}
+
+		// A resposta é guardada mesmo se o cliente desconectar no meio da requisição
+		ctx := context.WithoutCancel(c.Request.Context())
+
+		// Um panic no handler libera a chave; o Recovery do Gin responde depois
+		defer func() {
+			if p := recover(); p != nil {
+				releaseKey(ctx, store, key, record.Token)
+				panic(p)
+			}
+		}()
+
+		recorder := &bodyRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		// Os erros ainda não respondidos são escritos aqui, e não no ErrorHandler, para que
+		// o problema também seja guardado
+		if !c.Writer.Written() && len(c.Errors) > 0 {
+			respondError(c)
+		}
+
+		status := c.Writer.Status()
+		if status >= http.StatusInternalServerError || status == StatusClientClosedRequest {
+			releaseKey(ctx, store, key, record.Token)
+			return
+		}
+
+		header := http.Header{}
+		for _, name := range replayedHeaders {
+			if value := c.Writer.Header().Get(name); value != "" {
+				header.Set(name, value)
+			}
+		}
+
+		response := shared_idempotency.Response{Status: status, Header: header, Body: recorder.body.Bytes()}
+		if err := store.Complete(ctx, key, record.Token, response); err != nil {
+			log.Printf("❌ Erro ao guardar a resposta da chave de idempotência: %v", err)
+		}
+	}
+}
+
+// replay repete a resposta guardada, marcada com o header Idempotent-Replayed
+func replay(c *gin.Context, response *shared_idempotency.Response) {
+	for name, values := range response.Header {
+		for _, value := range values {
+			c.Writer.Header().Add(name, value)
+		}
+	}
+	c.Header(IdempotentReplayedHeader, "true")
+	c.Status(response.Status)
+	c.Writer.Write(response.Body)
+}
+
+func releaseKey(ctx context.Context, store shared_idempotency.Store, key, token string) {
+	if err := store.Release(ctx, key, token); err != nil {
+		log.Printf("❌ Erro ao liberar a chave de idempotência: %v", err)
+	}
+}
+
+// validIdempotencyKey aceita até 255 caracteres ASCII visíveis
+func validIdempotencyKey(key string) bool {
+	if len(key) > maxIdempotencyKeyLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < '!' || key[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// bodyRecorder copia o corpo da resposta enquanto ele é escrito
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *bodyRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,192 @@
+package http_middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	shared_idempotency "github.com/williamkoller/golang-domain-driven-design/internal/shared/idempotency"
+)
+
+// setupIdempotencyRouter cria uma rota que responde 201 com um contador de execuções;
+// o corpo "fail" registra um erro de indisponibilidade e "conflict" um item duplicado
+func setupIdempotencyRouter(store shared_idempotency.Store) (*gin.Engine, *atomic.Int32) {
+	gin.SetMode(gin.TestMode)
+
+	var calls atomic.Int32
+	r := gin.New()
+	r.Use(ErrorHandler())
+	r.POST("/items", Idempotency(store, time.Hour), func(c *gin.Context) {
+		calls.Add(1)
+
+		var body bytes.Buffer
+		body.ReadFrom(c.Request.Body)
+		switch body.String() {
+		case "fail":
+			c.Error(fmt.Errorf("%w: connection refused", errTestUnavailable))
+			return
+		case "conflict":
+			c.Error(errTestExists)
+			return
+		}
+
+		c.Header("Location", "/items/1")
+		c.JSON(http.StatusCreated, gin.H{"call": calls.Load(), "body": body.String()})
+	})
+
+	return r, &calls
+}
+
+func postItem(r *gin.Engine, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotency(t *testing.T) {
+	t.Run("replays the original response", func(t *testing.T) {
+		r, calls := setupIdempotencyRouter(shared_idempotency.NewInMemoryStore())
+
+		first := postItem(r, "key-1", `{"name":"Notebook"}`)
+		second := postItem(r, "key-1", `{"name":"Notebook"}`)
+
+		if calls.Load() != 1 {
+			t.Errorf("handler ran %d times, want 1", calls.Load())
+		}
+		if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() {
+			t.Errorf("replay = %d %s, want %d %s", second.Code, second.Body.String(), first.Code, first.Body.String())
+		}
+		if second.Header().Get(IdempotentReplayedHeader) != "true" || second.Header().Get("Location") != "/items/1" || second.Header().Get("Content-Type") != first.Header().Get("Content-Type") {
+			t.Errorf("replay headers = %v", second.Header())
+		}
+		if first.Header().Get(IdempotentReplayedHeader) != "" {
+			t.Error("original response should not be marked as replayed")
+		}
+	})
+
+	t.Run("key reused with a different body", func(t *testing.T) {
+		r, calls := setupIdempotencyRouter(shared_idempotency.NewInMemoryStore())
+
+		postItem(r, "key-1", `{"name":"Notebook"}`)
+		w := postItem(r, "key-1", `{"name":"Mouse"}`)
+
+		if w.Code != http.StatusUnprocessableEntity || calls.Load() != 1 {
+			t.Errorf("status = %d after %d calls, want 422 after 1", w.Code, calls.Load())
+		}
+		var problem ProblemDetails
+		json.Unmarshal(w.Body.Bytes(), &problem)
+		if problem.Type != "/problems/idempotency-key-reused" {
+			t.Errorf("problem = %+v", problem)
+		}
+	})
+
+	t.Run("client errors are stored", func(t *testing.T) {
+		r, calls := setupIdempotencyRouter(shared_idempotency.NewInMemoryStore())
+
+		first := postItem(r, "key-1", "conflict")
+		second := postItem(r, "key-1", "conflict")
+
+		if first.Code != http.StatusConflict || second.Code != http.StatusConflict || calls.Load() != 1 {
+			t.Errorf("statuses = %d, %d after %d calls; want 409, 409 after 1", first.Code, second.Code, calls.Load())
+		}
+		if second.Body.String() != first.Body.String() || second.Header().Get("Content-Type") != ProblemContentType {
+			t.Errorf("replay = %s (%s)", second.Body.String(), second.Header().Get("Content-Type"))
+		}
+	})
+
+	t.Run("server errors release the key", func(t *testing.T) {
+		r, calls := setupIdempotencyRouter(shared_idempotency.NewInMemoryStore())
+
+		first := postItem(r, "key-1", "fail")
+		second := postItem(r, "key-1", "fail")
+
+		if first.Code != http.StatusServiceUnavailable || second.Code != http.StatusServiceUnavailable || calls.Load() != 2 {
+			t.Errorf("statuses = %d, %d after %d calls; want 503 twice, executed twice", first.Code, second.Code, calls.Load())
+		}
+	})
+
+	t.Run("request in progress", func(t *testing.T) {
+		store := shared_idempotency.NewInMemoryStore()
+		r, calls := setupIdempotencyRouter(store)
+
+		body := `{"name":"Notebook"}`
+		fingerprint := shared_idempotency.Fingerprint([]byte(http.MethodPost), []byte("/items"), []byte(body))
+		store.Reserve(context.Background(), "key-1", fingerprint, time.Hour)
+
+		w := postItem(r, "key-1", body)
+		if w.Code != http.StatusConflict || calls.Load() != 0 {
+			t.Errorf("status = %d after %d calls, want 409 without running the handler", w.Code, calls.Load())
+		}
+	})
+
+	t.Run("without the header", func(t *testing.T) {
+		r, calls := setupIdempotencyRouter(shared_idempotency.NewInMemoryStore())
+
+		postItem(r, "", `{}`)
+		postItem(r, "", `{}`)
+
+		if calls.Load() != 2 {
+			t.Errorf("handler ran %d times, want 2", calls.Load())
+		}
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		r, calls := setupIdempotencyRouter(shared_idempotency.NewInMemoryStore())
+
+		for _, key := range []string{"has space", strings.Repeat("k", 256)} {
+			if w := postItem(r, key, `{}`); w.Code != http.StatusBadRequest {
+				t.Errorf("key %q: status = %d, want 400", key, w.Code)
+			}
+		}
+		if calls.Load() != 0 {
+			t.Errorf("handler ran %d times, want 0", calls.Load())
+		}
+	})
+
+	t.Run("body too large", func(t *testing.T) {
+		store := shared_idempotency.NewInMemoryStore()
+		r, calls := setupIdempotencyRouter(store)
+
+		w := postItem(r, "key-1", strings.Repeat("x", maxIdempotentBodyBytes+1))
+		if w.Code != http.StatusRequestEntityTooLarge || calls.Load() != 0 {
+			t.Errorf("status = %d after %d calls, want 413 without running the handler", w.Code, calls.Load())
+		}
+
+		// A chave não chega a ser reservada
+		if _, reserved, _ := store.Reserve(context.Background(), "key-1", "fp", time.Hour); !reserved {
+			t.Error("the key should not be reserved")
+		}
+	})
+}
+
+func TestIdempotency_PanicReleasesKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := shared_idempotency.NewInMemoryStore()
+
+	r := gin.New()
+	r.Use(gin.CustomRecovery(func(c *gin.Context, _ any) { c.AbortWithStatus(http.StatusInternalServerError) }))
+	r.POST("/items", Idempotency(store, time.Hour), func(c *gin.Context) {
+		panic("boom")
+	})
+
+	if w := postItem(r, "key-1", `{}`); w.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", w.Code)
+	}
+
+	fingerprint := shared_idempotency.Fingerprint([]byte(http.MethodPost), []byte("/items"), []byte(`{}`))
+	if _, reserved, _ := store.Reserve(context.Background(), "key-1", fingerprint, time.Hour); !reserved {
+		t.Error("key should be released after a panic")
+	}
+}
@@ -0,0 +1,129 @@
+package http_middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// ProblemContentType é o media type das respostas de erro (RFC 7807)
+const ProblemContentType = "application/problem+json"
+
+// ProblemTypePrefix é o prefixo das URIs (relativas à API) que identificam os tipos de problema
+const ProblemTypePrefix = "/problems/"
+
+// ProblemDetails representa uma resposta de erro no formato RFC 7807
+type ProblemDetails struct {
+	Type     string       `json:"type" example:"/problems/validation-error"`
+	Title    string       `json:"title" example:"Validation failed"`
+	Status   int          `json:"status" example:"400"`
+	Detail   string       `json:"detail,omitempty" example:"name is required; price must be positive"`
+	Instance string       `json:"instance,omitempty" example:"/api/v1/products"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError representa um campo inválido, com um código estável para tratamento automático
+type FieldError struct {
+	Field   string `json:"field" example:"price"`
+	Code    string `json:"code" example:"must_be_positive"`
+	Message string `json:"message" example:"price must be positive"`
+}
+
+// WriteProblem responde a requisição com o problema como application/problem+json
+func WriteProblem(c *gin.Context, problem ProblemDetails) {
+	c.Header("Content-Type", ProblemContentType)
+	c.JSON(problem.Status, problem)
+}
+
+// BindingError converte o erro de c.ShouldBindJSON(input) em um 400 que lista os campos
+// inválidos pelo nome JSON, sem repassar o texto do validator ao cliente
+func BindingError(err error, input any) error {
+	var validationErrs validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+
+	switch {
+	case errors.As(err, &validationErrs):
+		fields := make([]FieldError, len(validationErrs))
+		for i, fieldErr := range validationErrs {
+			name := jsonFieldName(input, fieldErr.StructField())
+			fields[i] = FieldError{Field: name, Code: fieldErr.Tag(), Message: ruleMessage(name, fieldErr)}
+		}
+		return &requestError{err: err, detail: joinMessages(fields), fields: fields}
+
+	case errors.As(err, &typeErr):
+		field := FieldError{Field: typeErr.Field, Code: "invalid_type", Message: fmt.Sprintf("%s must be %s", typeErr.Field, jsonTypeName(typeErr.Type))}
+		return &requestError{err: err, detail: field.Message, fields: []FieldError{field}}
+
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return &requestError{err: err, detail: "malformed JSON body"}
+
+	case errors.Is(err, io.EOF):
+		return &requestError{err: err, detail: "request body is empty"}
+	}
+
+	return &requestError{err: err, detail: "invalid request body"}
+}
+
+// jsonFieldName retorna o nome JSON do campo da struct de entrada
+func jsonFieldName(input any, structField string) string {
+	t := reflect.TypeOf(input)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Kind() == reflect.Struct {
+		if field, ok := t.FieldByName(structField); ok {
+			if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" && name != "-" {
+				return name
+			}
+		}
+	}
+	return structField
+}
+
+// ruleMessage descreve a regra do validator violada pelo campo
+func ruleMessage(field string, fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required":
+		return field + " is required"
+	case "gte", "min":
+		return fmt.Sprintf("%s must be at least %s", field, fieldErr.Param())
+	case "lte", "max":
+		return fmt.Sprintf("%s must be at most %s", field, fieldErr.Param())
+	case "oneof":
+		return fmt.Sprintf("%s must be one of %s", field, fieldErr.Param())
+	}
+	return field + " is invalid"
+}
+
+// jsonTypeName descreve o tipo JSON esperado para o tipo Go do campo
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	}
+	return "an object"
+}
+
+func joinMessages(fields []FieldError) string {
+	messages := make([]string, len(fields))
+	for i, field := range fields {
+		messages[i] = field.Message
+	}
+	return strings.Join(messages, "; ")
+}
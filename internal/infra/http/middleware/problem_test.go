@@ -0,0 +1,88 @@
+package http_middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type bindingInput struct {
+	Name       string   `json:"name" binding:"required"`
+	Sku        int      `json:"sku" binding:"required"`
+	Categories []string `json:"categories,omitempty" binding:"required"`
+	From       int64    `json:"from" binding:"gte=0"`
+}
+
+func TestBindingError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		body       string
+		wantDetail string
+		wantFields []FieldError
+	}{
+		{
+			name:       "every missing field",
+			body:       `{"from": -1}`,
+			wantDetail: "name is required; sku is required; categories is required; from must be at least 0",
+			wantFields: []FieldError{
+				{Field: "name", Code: "required", Message: "name is required"},
+				{Field: "sku", Code: "required", Message: "sku is required"},
+				{Field: "categories", Code: "required", Message: "categories is required"},
+				{Field: "from", Code: "gte", Message: "from must be at least 0"},
+			},
+		},
+		{
+			name:       "wrong type",
+			body:       `{"name": "Notebook", "sku": "abc"}`,
+			wantDetail: "sku must be a number",
+			wantFields: []FieldError{{Field: "sku", Code: "invalid_type", Message: "sku must be a number"}},
+		},
+		{name: "malformed json", body: `{"name": `, wantDetail: "malformed JSON body"},
+		{name: "empty body", body: ``, wantDetail: "request body is empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(tt.body))
+
+			var input bindingInput
+			err := c.ShouldBindJSON(&input)
+			if err == nil {
+				t.Fatal("ShouldBindJSON() error = nil")
+			}
+
+			problem := ProblemFor(BindingError(err, &input))
+			if problem.Status != http.StatusBadRequest || problem.Detail != tt.wantDetail {
+				t.Errorf("problem = %+v; want 400 with detail %q", problem, tt.wantDetail)
+			}
+			if !reflect.DeepEqual(problem.Errors, tt.wantFields) {
+				t.Errorf("errors = %+v, want %+v", problem.Errors, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestWriteProblem(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	WriteProblem(c, ProblemDetails{Type: "about:blank", Title: "Not Found", Status: http.StatusNotFound})
+
+	var problem ProblemDetails
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("invalid body: %v", err)
+	}
+	if w.Code != http.StatusNotFound || w.Header().Get("Content-Type") != ProblemContentType || problem.Title != "Not Found" {
+		t.Errorf("response = %d %q %s", w.Code, w.Header().Get("Content-Type"), w.Body.String())
+	}
+}
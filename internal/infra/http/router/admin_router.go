@@ -0,0 +1,19 @@
+package product_router
+
+import (
+	"github.com/gin-gonic/gin"
+	product_handlers "github.com/williamkoller/golang-domain-driven-design/internal/infra/http/handlers"
+)
+
+// SetupAdminRoutes registra as rotas administrativas em /api/v1/admin
+func SetupAdminRoutes(r *gin.Engine, eventAdminHandler *product_handlers.EventAdminHandler) {
+	admin := r.Group("/api/v1/admin")
+	{
+		admin.GET("/subscriptions", eventAdminHandler.ListSubscriptions)
+		admin.GET("/projections", eventAdminHandler.ListProjections)
+		admin.POST("/projections/rebuild", eventAdminHandler.RebuildProjections)
+		admin.GET("/dead-letters", eventAdminHandler.ListDeadLetters)
+		admin.POST("/dead-letters/:id/replay", eventAdminHandler.ReplayDeadLetter)
+		admin.DELETE("/dead-letters/:id", eventAdminHandler.DeleteDeadLetter)
+	}
+}
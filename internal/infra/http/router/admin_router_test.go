@@ -0,0 +1,38 @@
+package product_router
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	product_handlers "github.com/williamkoller/golang-domain-driven-design/internal/infra/http/handlers"
+	shared_events "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/events"
+)
+
+func TestSetupAdminRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	SetupAdminRoutes(r, product_handlers.NewEventAdminHandler(shared_events.NewEventDispatcher()))
+
+	expectedRoutes := map[string]bool{
+		"GET-/api/v1/admin/subscriptions":            false,
+		"GET-/api/v1/admin/projections":              false,
+		"POST-/api/v1/admin/projections/rebuild":     false,
+		"GET-/api/v1/admin/dead-letters":             false,
+		"POST-/api/v1/admin/dead-letters/:id/replay": false,
+		"DELETE-/api/v1/admin/dead-letters/:id":      false,
+	}
+
+	for _, route := range r.Routes() {
+		key := route.Method + "-" + route.Path
+		if _, exists := expectedRoutes[key]; exists {
+			expectedRoutes[key] = true
+		}
+	}
+
+	for route, found := range expectedRoutes {
+		if !found {
+			t.Errorf("Expected route %s not found", route)
+		}
+	}
+}
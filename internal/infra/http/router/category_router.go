@@ -0,0 +1,21 @@
+package product_router
+
+import (
+	"github.com/gin-gonic/gin"
+	product_handlers "github.com/williamkoller/golang-domain-driven-design/internal/infra/http/handlers"
+)
+
+// SetupCategoryRoutes registra as rotas das categorias em /api/v1/categories. idempotency é
+// o middleware do header Idempotency-Key, aplicado à criação e à fusão.
+func SetupCategoryRoutes(r *gin.Engine, categoryHandler *product_handlers.CategoryHandler, idempotency gin.HandlerFunc) {
+	categories := r.Group("/api/v1/categories")
+	{
+		categories.POST("", idempotency, categoryHandler.Create)
+		categories.GET("", categoryHandler.FindAll)
+		categories.GET("/:id", categoryHandler.FindOne)
+		categories.PUT("/:id", categoryHandler.Update)
+		categories.DELETE("/:id", categoryHandler.Delete)
+		categories.POST("/:id/merge", idempotency, categoryHandler.Merge)
+		categories.GET("/:id/products", categoryHandler.Products)
+	}
+}
@@ -0,0 +1,78 @@
+package product_router
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	category_repository "github.com/williamkoller/golang-domain-driven-design/internal/domain/category/repository"
+	product_repository "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/repository"
+	product_handlers "github.com/williamkoller/golang-domain-driven-design/internal/infra/http/handlers"
+	http_middleware "github.com/williamkoller/golang-domain-driven-design/internal/infra/http/middleware"
+)
+
+func TestSetupCategoryRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	products := product_repository.NewRepository()
+	handler := product_handlers.NewCategoryHandler(category_repository.NewRepository(products), products)
+
+	r := gin.New()
+	SetupCategoryRoutes(r, handler, newTestIdempotency())
+
+	expectedRoutes := map[string]bool{
+		"POST-/api/v1/categories":             false,
+		"GET-/api/v1/categories":              false,
+		"GET-/api/v1/categories/:id":          false,
+		"PUT-/api/v1/categories/:id":          false,
+		"DELETE-/api/v1/categories/:id":       false,
+		"POST-/api/v1/categories/:id/merge":   false,
+		"GET-/api/v1/categories/:id/products": false,
+	}
+
+	for _, route := range r.Routes() {
+		key := route.Method + "-" + route.Path
+		if _, exists := expectedRoutes[key]; exists {
+			expectedRoutes[key] = true
+		}
+	}
+
+	for route, found := range expectedRoutes {
+		if !found {
+			t.Errorf("Expected route %s not found", route)
+		}
+	}
+}
+
+func TestSetupCategoryRoutes_Idempotency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	products := product_repository.NewRepository()
+	categories := category_repository.NewRepository(products)
+
+	r := gin.New()
+	SetupCategoryRoutes(r, product_handlers.NewCategoryHandler(categories, products), newTestIdempotency())
+
+	create := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/categories", strings.NewReader(`{"name":"Eletronicos"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(http_middleware.IdempotencyKeyHeader, "create-eletronicos")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	first, retry := create(), create()
+	if first.Code != http.StatusCreated || retry.Code != http.StatusCreated {
+		t.Fatalf("status = %d, %d, want 201 for both", first.Code, retry.Code)
+	}
+	if retry.Header().Get(http_middleware.IdempotentReplayedHeader) != "true" || retry.Body.String() != first.Body.String() {
+		t.Errorf("retry = %s (%v), want the replayed response %s", retry.Body.String(), retry.Header(), first.Body.String())
+	}
+	if all, _ := categories.FindAll(context.Background()); len(all) != 1 {
+		t.Errorf("categories = %d, want 1", len(all))
+	}
+}
@@ -0,0 +1,24 @@
+package product_router
+
+import (
+	"github.com/gin-gonic/gin"
+	product_handlers "github.com/williamkoller/golang-domain-driven-design/internal/infra/http/handlers"
+)
+
+// SetupJobRoutes registra as rotas dos jobs assíncronos em /api/v1/jobs: a criação dos jobs
+// de produtos, que respondem 202 com o endereço do job, a consulta, o cancelamento e o
+// download do resultado. idempotency é o middleware do header Idempotency-Key, aplicado à
+// criação e ao cancelamento dos jobs.
+func SetupJobRoutes(r *gin.Engine, jobHandler *product_handlers.JobHandler, productJobHandler *product_handlers.ProductJobHandler, idempotency gin.HandlerFunc) {
+	jobs := r.Group(product_handlers.JobsPath)
+	{
+		// Como POST /products/import, a importação não guarda a resposta por
+		// Idempotency-Key: o arquivo pode ter até MaxImportBytes
+		jobs.POST("/imports", productJobHandler.Import)
+		jobs.POST("/exports", idempotency, productJobHandler.Export)
+		jobs.POST("/reindex", idempotency, productJobHandler.Reindex)
+		jobs.GET("/:id", jobHandler.FindByID)
+		jobs.POST("/:id/cancel", idempotency, jobHandler.Cancel)
+		jobs.GET("/:id/result", jobHandler.Result)
+	}
+}
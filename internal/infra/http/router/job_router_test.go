@@ -0,0 +1,42 @@
+package product_router
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	product_repository "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/repository"
+	product_handlers "github.com/williamkoller/golang-domain-driven-design/internal/infra/http/handlers"
+	shared_jobs "github.com/williamkoller/golang-domain-driven-design/internal/shared/jobs"
+)
+
+func TestSetupJobRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	pool := shared_jobs.NewPool(shared_jobs.NewInMemoryStore(), shared_jobs.DefaultPoolConfig())
+	productHandler := product_handlers.NewProductHandler(product_repository.NewRepository(), createTestMetrics("job_routes"))
+
+	r := gin.New()
+	SetupJobRoutes(r, product_handlers.NewJobHandler(pool), product_handlers.NewProductJobHandler(productHandler, pool, t.TempDir()), newTestIdempotency())
+
+	expectedRoutes := map[string]bool{
+		"POST-/api/v1/jobs/imports":    false,
+		"POST-/api/v1/jobs/exports":    false,
+		"POST-/api/v1/jobs/reindex":    false,
+		"GET-/api/v1/jobs/:id":         false,
+		"POST-/api/v1/jobs/:id/cancel": false,
+		"GET-/api/v1/jobs/:id/result":  false,
+	}
+
+	for _, route := range r.Routes() {
+		key := route.Method + "-" + route.Path
+		if _, exists := expectedRoutes[key]; exists {
+			expectedRoutes[key] = true
+		}
+	}
+
+	for route, found := range expectedRoutes {
+		if !found {
+			t.Errorf("Expected route %s not found", route)
+		}
+	}
+}
@@ -6,19 +6,28 @@ import (
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	product_handlers "github.com/williamkoller/golang-domain-driven-design/internal/infra/http/handlers"
+	http_middleware "github.com/williamkoller/golang-domain-driven-design/internal/infra/http/middleware"
 	"github.com/williamkoller/golang-domain-driven-design/internal/metrics"
 )
 
-func SetupProductRouter(productHandler *product_handlers.ProductHandler, m *metrics.Metrics) *gin.Engine {
+// SetupProductRouter cria o engine com as rotas de produtos. idempotency é o middleware do
+// header Idempotency-Key, aplicado às rotas de criação.
+func SetupProductRouter(productHandler *product_handlers.ProductHandler, streamHandler *product_handlers.EventStreamHandler, idempotency gin.HandlerFunc, m *metrics.Metrics) *gin.Engine {
 	r := gin.New()
 
 	// Middleware padrão do Gin
 	r.Use(gin.Logger())
 	r.Use(gin.Recovery())
 
+	// ID de correlação propagado para os eventos de domínio
+	r.Use(http_middleware.CorrelationID())
+
 	// Middleware de métricas Prometheus (Golden Signals)
 	r.Use(metrics.PrometheusMiddleware(m))
 
+	// Converte os erros registrados pelos handlers no status HTTP correspondente
+	r.Use(http_middleware.ErrorHandler())
+
 	// Prometheus metrics endpoint
 	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
@@ -32,9 +41,24 @@ func SetupProductRouter(productHandler *product_handlers.ProductHandler, m *metr
 
 	v1 := r.Group("/api/v1")
 	{
-		v1.POST("/products", productHandler.Create)
+		v1.POST("/products", idempotency, productHandler.Create)
+		// A importação não guarda a resposta por Idempotency-Key: o arquivo pode ter até
+		// MaxImportBytes e, em best_effort, reenviá-lo só ignora as linhas já importadas
+		v1.POST("/products/import", productHandler.Import)
 		v1.GET("/products", productHandler.FindAll)
+		v1.GET("/products/search", productHandler.Search)
+		v1.GET("/products/export", productHandler.Export)
+		v1.GET("/products/changes", productHandler.Changes)
 		v1.GET("/products/:name", productHandler.FindOne)
+		v1.GET("/products/id/:id", productHandler.FindByID)
+		v1.GET("/products/sku/:sku", productHandler.FindBySku)
+		v1.PUT("/products/:name", productHandler.Update)
+		v1.PATCH("/products/:name", productHandler.Patch)
+		v1.DELETE("/products/:name", productHandler.Delete)
+		v1.POST("/products/:name/restore", productHandler.Restore)
+
+		// Stream dos eventos de produto (Server-Sent Events)
+		v1.GET("/events/stream", streamHandler.Stream)
 	}
 
 	return r
@@ -1,21 +1,39 @@
 package product_router
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
-	"errors"
+	product_errors "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/errors"
 
 	"github.com/gin-gonic/gin"
 	product_repository "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/repository"
+	product_valueobject "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/valueobject"
 	product_entity "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/entity"
 	product_handlers "github.com/williamkoller/golang-domain-driven-design/internal/infra/http/handlers"
+	http_middleware "github.com/williamkoller/golang-domain-driven-design/internal/infra/http/middleware"
+	http_sse "github.com/williamkoller/golang-domain-driven-design/internal/infra/http/sse"
 	"github.com/williamkoller/golang-domain-driven-design/internal/metrics"
 	shared_events "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/events"
+	shared_idempotency "github.com/williamkoller/golang-domain-driven-design/internal/shared/idempotency"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+// newTestIdempotency cria o middleware de Idempotency-Key com as chaves em memória
+func newTestIdempotency() gin.HandlerFunc {
+	return http_middleware.Idempotency(shared_idempotency.NewInMemoryStore(), time.Hour)
+}
+
+// newTestStreamHandler cria o handler do stream de eventos com um broker vazio
+func newTestStreamHandler() *product_handlers.EventStreamHandler {
+	return product_handlers.NewEventStreamHandler(http_sse.NewBroker(http_sse.DefaultBufferSize, http_sse.DefaultSubscriberSize), 0)
+}
+
 // MockProductRepository para testes do router
 type MockProductRepository struct {
 	products map[string]product_entity.Product
@@ -27,32 +45,100 @@ func NewMockProductRepository() *MockProductRepository {
 	}
 }
 
-func (m *MockProductRepository) Add(product product_entity.Product) error {
+func (m *MockProductRepository) Add(ctx context.Context, product product_entity.Product, events ...shared_events.Event) error {
 	m.products[product.Name] = product
 	return nil
 }
 
-func (m *MockProductRepository) Find() ([]product_entity.Product, error) {
+func (m *MockProductRepository) AddBatch(ctx context.Context, entries []product_repository.BatchEntry, atomic bool) (product_repository.BatchResult, error) {
+	for _, entry := range entries {
+		m.products[entry.Product.Name] = entry.Product
+	}
+	return product_repository.BatchResult{Errors: make([]error, len(entries)), Created: len(entries)}, nil
+}
+
+func (m *MockProductRepository) Find(ctx context.Context, criteria product_repository.ProductCriteria) (product_repository.ProductPage, error) {
 	products := make([]product_entity.Product, 0, len(m.products))
 	for _, p := range m.products {
 		products = append(products, p)
 	}
-	return products, nil
+	return product_repository.ProductPage{Products: products, Total: len(products)}, nil
+}
+
+func (m *MockProductRepository) Export(ctx context.Context, criteria product_repository.ProductCriteria, fn func(product_entity.Product) error) error {
+	for _, p := range m.products {
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *MockProductRepository) Search(ctx context.Context, query string, limit int) ([]product_entity.Product, error) {
+	return []product_entity.Product{}, nil
 }
 
-func (m *MockProductRepository) FindOne(name string) (product_entity.Product, error) {
+func (m *MockProductRepository) Reindex(ctx context.Context, batchSize int, progress func(done, total int)) (int, error) {
+	progress(len(m.products), len(m.products))
+	return len(m.products), nil
+}
+
+func (m *MockProductRepository) Changes(ctx context.Context, since int64, limit int) (product_repository.ChangePage, error) {
+	return product_repository.NewChangePage(nil, since, limit), nil
+}
+
+func (m *MockProductRepository) FindOne(ctx context.Context, name string, includeDeleted bool) (product_entity.Product, error) {
 	product, exists := m.products[name]
 	if !exists {
-		return product_entity.Product{}, errors.New("product not found")
+		return product_entity.Product{}, product_errors.ErrNotFound
 	}
 	return product, nil
 }
 
-func (m *MockProductRepository) GetMetrics() product_repository.RepositoryMetrics {
+func (m *MockProductRepository) FindByID(ctx context.Context, id string, includeDeleted bool) (product_entity.Product, error) {
+	for _, product := range m.products {
+		if product.ID == id {
+			return product, nil
+		}
+	}
+	return product_entity.Product{}, product_errors.ErrNotFound
+}
+
+func (m *MockProductRepository) FindBySku(ctx context.Context, sku int, includeDeleted bool) (product_entity.Product, error) {
+	for _, product := range m.products {
+		if product.Sku == sku {
+			return product, nil
+		}
+	}
+	return product_entity.Product{}, product_errors.ErrNotFound
+}
+
+func (m *MockProductRepository) Update(ctx context.Context, name string, product product_entity.Product, events ...shared_events.Event) error {
+	if _, exists := m.products[name]; !exists {
+		return product_errors.ErrNotFound
+	}
+	delete(m.products, name)
+	m.products[product.Name] = product
+	return nil
+}
+
+func (m *MockProductRepository) Delete(ctx context.Context, name string, expectedVersion int, events ...shared_events.Event) error {
+	if _, exists := m.products[name]; !exists {
+		return product_errors.ErrNotFound
+	}
+	delete(m.products, name)
+	return nil
+}
+
+func (m *MockProductRepository) Restore(ctx context.Context, name string, expectedVersion int, events ...shared_events.Event) error {
+	return product_errors.ErrNotFound
+}
+
+func (m *MockProductRepository) GetMetrics(ctx context.Context) product_repository.RepositoryMetrics {
 	return product_repository.RepositoryMetrics{
 		TotalProducts:      len(m.products),
-		TotalValue:         0,
-		AveragePrice:       0,
+		TotalValue:         make(map[string]float64),
+		AveragePrice:       make(map[string]float64),
 		ProductsByCategory: make(map[string]int),
 	}
 }
@@ -92,6 +178,12 @@ func createTestMetrics(testName string) *metrics.Metrics {
 				Help: "Test products created",
 			},
 		),
+		ProductsDeleted: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "test_router_" + testName + "_products_deleted_total",
+				Help: "Test products deleted",
+			},
+		),
 		ProductsTotal: prometheus.NewGauge(
 			prometheus.GaugeOpts{
 				Name: "test_router_" + testName + "_products_total",
@@ -105,17 +197,19 @@ func createTestMetrics(testName string) *metrics.Metrics {
 			},
 			[]string{"category"},
 		),
-		ProductsTotalValue: prometheus.NewGauge(
+		ProductsTotalValue: prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Name: "test_router_" + testName + "_products_total_value",
 				Help: "Test products total value",
 			},
+			[]string{"currency"},
 		),
-		ProductsAveragePrice: prometheus.NewGauge(
+		ProductsAveragePrice: prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Name: "test_router_" + testName + "_products_average_price",
 				Help: "Test products average price",
 			},
+			[]string{"currency"},
 		),
 	}
 }
@@ -124,11 +218,10 @@ func TestSetupProductRouter(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	repo := NewMockProductRepository()
-	dispatcher := shared_events.NewEventDispatcher()
 	m := createTestMetrics("setup")
-	handler := product_handlers.NewProductHandler(repo, dispatcher, m)
+	handler := product_handlers.NewProductHandler(repo, m)
 
-	router := SetupProductRouter(handler, m)
+	router := SetupProductRouter(handler, newTestStreamHandler(), newTestIdempotency(), m)
 
 	if router == nil {
 		t.Fatal("SetupProductRouter() returned nil")
@@ -142,8 +235,19 @@ func TestSetupProductRouter(t *testing.T) {
 		"GET-/swagger/*any":         false,
 		"GET-/health":               false,
 		"POST-/api/v1/products":     false,
+		"POST-/api/v1/products/import": false,
 		"GET-/api/v1/products":      false,
+		"GET-/api/v1/products/search": false,
+		"GET-/api/v1/products/export": false,
+		"GET-/api/v1/products/changes": false,
 		"GET-/api/v1/products/:name": false,
+		"GET-/api/v1/products/id/:id": false,
+		"GET-/api/v1/products/sku/:sku": false,
+		"PUT-/api/v1/products/:name": false,
+		"PATCH-/api/v1/products/:name": false,
+		"DELETE-/api/v1/products/:name": false,
+		"POST-/api/v1/products/:name/restore": false,
+		"GET-/api/v1/events/stream": false,
 	}
 
 	for _, route := range routes {
@@ -161,14 +265,35 @@ func TestSetupProductRouter(t *testing.T) {
 	}
 }
 
+// Um produto com o nome de um segmento das rotas GET /api/v1/products/{segmento} seria
+// encontrado pela outra rota, e não por GET /api/v1/products/:name; esses nomes são recusados
+func TestProductRouter_ReservedProductNames(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	m := createTestMetrics("reserved_names")
+	router := SetupProductRouter(product_handlers.NewProductHandler(NewMockProductRepository(), m), newTestStreamHandler(), newTestIdempotency(), m)
+
+	for _, route := range router.Routes() {
+		segment, ok := strings.CutPrefix(route.Path, "/api/v1/products/")
+		if route.Method != http.MethodGet || !ok || strings.HasPrefix(segment, ":") {
+			continue
+		}
+		segment, _, _ = strings.Cut(segment, "/")
+
+		_, err := product_entity.Validate(segment, 1, []string{"Eletrônicos"}, product_valueobject.MustBRL(100))
+		if !errors.Is(err, product_errors.ErrValidation) {
+			t.Errorf("Validate(%q) error = %v; the name would be shadowed by GET %s", segment, err, route.Path)
+		}
+	}
+}
+
 func TestProductRouter_HealthEndpoint(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	repo := NewMockProductRepository()
-	dispatcher := shared_events.NewEventDispatcher()
 	m := createTestMetrics("health")
-	handler := product_handlers.NewProductHandler(repo, dispatcher, m)
-	router := SetupProductRouter(handler, m)
+	handler := product_handlers.NewProductHandler(repo, m)
+	router := SetupProductRouter(handler, newTestStreamHandler(), newTestIdempotency(), m)
 
 	req := httptest.NewRequest(http.MethodGet, "/health", nil)
 	w := httptest.NewRecorder()
@@ -189,10 +314,9 @@ func TestProductRouter_MetricsEndpoint(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	repo := NewMockProductRepository()
-	dispatcher := shared_events.NewEventDispatcher()
 	m := createTestMetrics("metrics_endpoint")
-	handler := product_handlers.NewProductHandler(repo, dispatcher, m)
-	router := SetupProductRouter(handler, m)
+	handler := product_handlers.NewProductHandler(repo, m)
+	router := SetupProductRouter(handler, newTestStreamHandler(), newTestIdempotency(), m)
 
 	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
 	w := httptest.NewRecorder()
@@ -220,10 +344,9 @@ func TestProductRouter_SwaggerEndpoint(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	repo := NewMockProductRepository()
-	dispatcher := shared_events.NewEventDispatcher()
 	m := createTestMetrics("swagger")
-	handler := product_handlers.NewProductHandler(repo, dispatcher, m)
-	router := SetupProductRouter(handler, m)
+	handler := product_handlers.NewProductHandler(repo, m)
+	router := SetupProductRouter(handler, newTestStreamHandler(), newTestIdempotency(), m)
 
 	tests := []struct {
 		name           string
@@ -261,10 +384,9 @@ func TestProductRouter_APIv1Endpoints(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	repo := NewMockProductRepository()
-	dispatcher := shared_events.NewEventDispatcher()
 	m := createTestMetrics("apiv1")
-	handler := product_handlers.NewProductHandler(repo, dispatcher, m)
-	router := SetupProductRouter(handler, m)
+	handler := product_handlers.NewProductHandler(repo, m)
+	router := SetupProductRouter(handler, newTestStreamHandler(), newTestIdempotency(), m)
 
 	tests := []struct {
 		name           string
@@ -310,10 +432,9 @@ func TestProductRouter_NotFoundRoute(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	repo := NewMockProductRepository()
-	dispatcher := shared_events.NewEventDispatcher()
 	m := createTestMetrics("notfound")
-	handler := product_handlers.NewProductHandler(repo, dispatcher, m)
-	router := SetupProductRouter(handler, m)
+	handler := product_handlers.NewProductHandler(repo, m)
+	router := SetupProductRouter(handler, newTestStreamHandler(), newTestIdempotency(), m)
 
 	req := httptest.NewRequest(http.MethodGet, "/non-existent-route", nil)
 	w := httptest.NewRecorder()
@@ -329,10 +450,9 @@ func TestProductRouter_MethodNotAllowed(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	repo := NewMockProductRepository()
-	dispatcher := shared_events.NewEventDispatcher()
 	m := createTestMetrics("method_not_allowed")
-	handler := product_handlers.NewProductHandler(repo, dispatcher, m)
-	router := SetupProductRouter(handler, m)
+	handler := product_handlers.NewProductHandler(repo, m)
+	router := SetupProductRouter(handler, newTestStreamHandler(), newTestIdempotency(), m)
 
 	tests := []struct {
 		name   string
@@ -375,10 +495,9 @@ func TestProductRouter_Middlewares(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	repo := NewMockProductRepository()
-	dispatcher := shared_events.NewEventDispatcher()
 	m := createTestMetrics("middlewares")
-	handler := product_handlers.NewProductHandler(repo, dispatcher, m)
-	router := SetupProductRouter(handler, m)
+	handler := product_handlers.NewProductHandler(repo, m)
+	router := SetupProductRouter(handler, newTestStreamHandler(), newTestIdempotency(), m)
 
 	// Fazer uma requisição para verificar que middlewares estão sendo executados
 	req := httptest.NewRequest(http.MethodGet, "/health", nil)
@@ -398,10 +517,9 @@ func TestProductRouter_CORSHeaders(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	repo := NewMockProductRepository()
-	dispatcher := shared_events.NewEventDispatcher()
 	m := createTestMetrics("cors")
-	handler := product_handlers.NewProductHandler(repo, dispatcher, m)
-	router := SetupProductRouter(handler, m)
+	handler := product_handlers.NewProductHandler(repo, m)
+	router := SetupProductRouter(handler, newTestStreamHandler(), newTestIdempotency(), m)
 
 	req := httptest.NewRequest(http.MethodOptions, "/api/v1/products", nil)
 	req.Header.Set("Origin", "http://localhost:3000")
@@ -419,10 +537,9 @@ func BenchmarkProductRouter_Health(b *testing.B) {
 	gin.SetMode(gin.TestMode)
 
 	repo := NewMockProductRepository()
-	dispatcher := shared_events.NewEventDispatcher()
 	m := createTestMetrics("bench_health")
-	handler := product_handlers.NewProductHandler(repo, dispatcher, m)
-	router := SetupProductRouter(handler, m)
+	handler := product_handlers.NewProductHandler(repo, m)
+	router := SetupProductRouter(handler, newTestStreamHandler(), newTestIdempotency(), m)
 
 	req := httptest.NewRequest(http.MethodGet, "/health", nil)
 
@@ -437,10 +554,9 @@ func BenchmarkProductRouter_Metrics(b *testing.B) {
 	gin.SetMode(gin.TestMode)
 
 	repo := NewMockProductRepository()
-	dispatcher := shared_events.NewEventDispatcher()
 	m := createTestMetrics("bench_metrics")
-	handler := product_handlers.NewProductHandler(repo, dispatcher, m)
-	router := SetupProductRouter(handler, m)
+	handler := product_handlers.NewProductHandler(repo, m)
+	router := SetupProductRouter(handler, newTestStreamHandler(), newTestIdempotency(), m)
 
 	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
 
@@ -455,17 +571,16 @@ func BenchmarkProductRouter_APIv1Products(b *testing.B) {
 	gin.SetMode(gin.TestMode)
 
 	repo := NewMockProductRepository()
-	dispatcher := shared_events.NewEventDispatcher()
 	m := createTestMetrics("bench_apiv1")
-	handler := product_handlers.NewProductHandler(repo, dispatcher, m)
-	router := SetupProductRouter(handler, m)
+	handler := product_handlers.NewProductHandler(repo, m)
+	router := SetupProductRouter(handler, newTestStreamHandler(), newTestIdempotency(), m)
 
 	// Adicionar alguns produtos
 	repo.products["Product1"] = product_entity.Product{
 		Name:       "Product1",
 		Sku:        1,
 		Categories: []string{"Test"},
-		Price:      100,
+		Price:      product_valueobject.MustBRL(100),
 	}
 
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/products", nil)
@@ -0,0 +1,20 @@
+package product_router
+
+import (
+	"github.com/gin-gonic/gin"
+	product_handlers "github.com/williamkoller/golang-domain-driven-design/internal/infra/http/handlers"
+)
+
+// SetupWebhookRoutes registra as rotas de gerenciamento de webhooks em /api/v1/webhooks.
+// idempotency é o middleware do header Idempotency-Key, aplicado à criação.
+func SetupWebhookRoutes(r *gin.Engine, webhookHandler *product_handlers.WebhookHandler, idempotency gin.HandlerFunc) {
+	webhooks := r.Group("/api/v1/webhooks")
+	{
+		webhooks.POST("", idempotency, webhookHandler.Create)
+		webhooks.GET("", webhookHandler.FindAll)
+		webhooks.GET("/:id", webhookHandler.FindByID)
+		webhooks.PUT("/:id", webhookHandler.Update)
+		webhooks.DELETE("/:id", webhookHandler.Delete)
+		webhooks.GET("/:id/deliveries", webhookHandler.Deliveries)
+	}
+}
@@ -0,0 +1,38 @@
+package product_router
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	webhook_repository "github.com/williamkoller/golang-domain-driven-design/internal/domain/webhook/repository"
+	product_handlers "github.com/williamkoller/golang-domain-driven-design/internal/infra/http/handlers"
+)
+
+func TestSetupWebhookRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	SetupWebhookRoutes(r, product_handlers.NewWebhookHandler(webhook_repository.NewRepository()), newTestIdempotency())
+
+	expectedRoutes := map[string]bool{
+		"POST-/api/v1/webhooks":               false,
+		"GET-/api/v1/webhooks":                false,
+		"GET-/api/v1/webhooks/:id":            false,
+		"PUT-/api/v1/webhooks/:id":            false,
+		"DELETE-/api/v1/webhooks/:id":         false,
+		"GET-/api/v1/webhooks/:id/deliveries": false,
+	}
+
+	for _, route := range r.Routes() {
+		key := route.Method + "-" + route.Path
+		if _, exists := expectedRoutes[key]; exists {
+			expectedRoutes[key] = true
+		}
+	}
+
+	for route, found := range expectedRoutes {
+		if !found {
+			t.Errorf("Expected route %s not found", route)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package http_sse
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	shared_events "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/events"
+)
+
+var ErrBrokerClosed = errors.New("event stream closed")
+
+// Capacidades padrão do histórico de reconexão e da fila de cada cliente
+const (
+	DefaultBufferSize     = 1000
+	DefaultSubscriberSize = 64
+)
+
+// Message é um evento pronto para ser enviado pelo stream
+type Message struct {
+	ID    EventID
+	Event string
+	Data  []byte
+}
+
+// EventID identifica um evento do stream pela época do broker e pela sequência do evento
+// nela. A sequência recomeça a cada início do processo; a época, diferente a cada início e
+// em cada instância, faz um Last-Event-ID de outro processo ser reconhecido como perda em
+// vez de coincidir com a sequência nova.
+type EventID struct {
+	Epoch string
+	Seq   uint64
+}
+
+// String formata o ID como "<época>-<sequência>", o id enviado ao cliente
+func (id EventID) String() string {
+	return id.Epoch + "-" + strconv.FormatUint(id.Seq, 10)
+}
+
+// ParseEventID lê o ID no formato de String. Um número sem época, como os IDs de versões
+// anteriores, é aceito com a época vazia e tratado como de outro processo.
+func ParseEventID(value string) (EventID, error) {
+	epoch, seq, found := strings.Cut(value, "-")
+	if !found {
+		epoch, seq = "", value
+	}
+
+	n, err := strconv.ParseUint(seq, 10, 64)
+	if err != nil || (found && epoch == "") {
+		return EventID{}, fmt.Errorf("invalid event id %q", value)
+	}
+	return EventID{Epoch: epoch, Seq: n}, nil
+}
+
+// Broker distribui os eventos do dispatcher aos clientes conectados ao stream.
+// Os últimos eventos ficam em um buffer circular para que um cliente que reconecte
+// com Last-Event-ID receba o que perdeu.
+type Broker struct {
+	mu          sync.Mutex
+	epoch       string
+	buffer      []Message
+	start       int // posição do evento mais antigo no buffer
+	size        int
+	lastID      uint64
+	subscribers map[*Subscriber]struct{}
+	queueSize   int
+	closed      bool
+}
+
+// Subscriber é um cliente conectado; C é fechado quando o broker é encerrado ou
+// quando o cliente não consome os eventos a tempo
+type Subscriber struct {
+	C      <-chan Message
+	ch     chan Message
+	filter func(eventName string) bool
+	broker *Broker
+}
+
+func NewBroker(bufferSize, subscriberSize int) *Broker {
+	if bufferSize < 1 {
+		bufferSize = DefaultBufferSize
+	}
+	if subscriberSize < 1 {
+		subscriberSize = DefaultSubscriberSize
+	}
+
+	return &Broker{
+		// O instante do início, em base 36, distingue os processos
+		epoch:       strconv.FormatInt(time.Now().UnixNano(), 36),
+		buffer:      make([]Message, bufferSize),
+		subscribers: make(map[*Subscriber]struct{}),
+		queueSize:   subscriberSize,
+	}
+}
+
+// Handle é o EventHandler registrado no dispatcher; publica o envelope do evento em JSON
+func (b *Broker) Handle(event shared_events.Event) {
+	envelope := shared_events.Wrap(event)
+
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		log.Printf("❌ Erro ao serializar o evento %s para o stream: %v", envelope.Name, err)
+		return
+	}
+
+	b.Publish(envelope.Name, data)
+}
+
+// Publish grava o evento no buffer e o entrega aos clientes cujo filtro o aceita.
+// Um cliente com a fila cheia é desconectado; ele pode reconectar com Last-Event-ID.
+func (b *Broker) Publish(eventName string, data []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+
+	b.lastID++
+	msg := Message{ID: EventID{Epoch: b.epoch, Seq: b.lastID}, Event: eventName, Data: data}
+
+	if b.size < len(b.buffer) {
+		b.buffer[(b.start+b.size)%len(b.buffer)] = msg
+		b.size++
+	} else {
+		b.buffer[b.start] = msg
+		b.start = (b.start + 1) % len(b.buffer)
+	}
+
+	for sub := range b.subscribers {
+		if !sub.filter(eventName) {
+			continue
+		}
+		select {
+		case sub.ch <- msg:
+		default:
+			log.Printf("⚠️  Cliente do stream de eventos desconectado: fila cheia")
+			b.remove(sub)
+		}
+	}
+}
+
+// Subscribe conecta um cliente. Com um lastEventID, retorna também os eventos
+// posteriores a ele ainda no buffer; missed indica que parte deles já saiu do buffer,
+// ou que o ID é de outro processo, e o cliente precisa recarregar o estado.
+func (b *Broker) Subscribe(lastEventID EventID, filter func(eventName string) bool) (sub *Subscriber, backlog []Message, missed bool, err error) {
+	if filter == nil {
+		filter = func(string) bool { return true }
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, nil, false, ErrBrokerClosed
+	}
+
+	if lastEventID != (EventID{}) {
+		backlog, missed = b.since(lastEventID, filter)
+	}
+
+	ch := make(chan Message, b.queueSize)
+	sub = &Subscriber{C: ch, ch: ch, filter: filter, broker: b}
+	b.subscribers[sub] = struct{}{}
+
+	return sub, backlog, missed, nil
+}
+
+// Close desconecta o cliente; pode ser chamado mais de uma vez
+func (s *Subscriber) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+
+	s.broker.remove(s)
+}
+
+// Close encerra o broker: os clientes são desconectados e novos são recusados
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for sub := range b.subscribers {
+		b.remove(sub)
+	}
+}
+
+// LastEventID retorna o ID do último evento publicado
+func (b *Broker) LastEventID() EventID {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return EventID{Epoch: b.epoch, Seq: b.lastID}
+}
+
+// since retorna os eventos do buffer posteriores ao ID; exige o lock.
+// Um ID de outra época (de antes de um reinício ou de outra instância) ou maior que o
+// último publicado também conta como perda.
+func (b *Broker) since(lastEventID EventID, filter func(string) bool) ([]Message, bool) {
+	if lastEventID.Epoch != b.epoch || lastEventID.Seq > b.lastID {
+		return nil, true
+	}
+
+	oldest := b.lastID - uint64(b.size) + 1
+	missed := lastEventID.Seq+1 < oldest
+
+	var messages []Message
+	for i := 0; i < b.size; i++ {
+		msg := b.buffer[(b.start+i)%len(b.buffer)]
+		if msg.ID.Seq > lastEventID.Seq && filter(msg.Event) {
+			messages = append(messages, msg)
+		}
+	}
+
+	return messages, missed
+}
+
+// remove desconecta o cliente fechando o seu canal; exige o lock
+func (b *Broker) remove(sub *Subscriber) {
+	if _, ok := b.subscribers[sub]; !ok {
+		return
+	}
+	delete(b.subscribers, sub)
+	close(sub.ch)
+}
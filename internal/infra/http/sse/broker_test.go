@@ -0,0 +1,213 @@
+package http_sse
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	product_events "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/events"
+)
+
+func ids(messages []Message) []uint64 {
+	result := make([]uint64, len(messages))
+	for i, msg := range messages {
+		result[i] = msg.ID.Seq
+	}
+	return result
+}
+
+func equalIDs(got []Message, want ...uint64) bool {
+	gotIDs := ids(got)
+	if len(gotIDs) != len(want) {
+		return false
+	}
+	for i := range want {
+		if gotIDs[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestEventID(t *testing.T) {
+	id := EventID{Epoch: "lq2x8k1c5g", Seq: 42}
+	if id.String() != "lq2x8k1c5g-42" {
+		t.Errorf("String() = %q", id.String())
+	}
+
+	tests := []struct {
+		value   string
+		want    EventID
+		wantErr bool
+	}{
+		{value: "lq2x8k1c5g-42", want: id},
+		{value: "42", want: EventID{Seq: 42}},
+		{value: "-42", wantErr: true},
+		{value: "lq2x8k1c5g-", wantErr: true},
+		{value: "abc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseEventID(tt.value)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseEventID(%q) = %+v, %v; want %+v, error %v", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestBroker_NewEpochPerBroker(t *testing.T) {
+	first := NewBroker(10, 10)
+	first.Publish("product.created", []byte(`{}`))
+	time.Sleep(time.Microsecond)
+
+	// Um broker novo (processo reiniciado) recomeça a sequência em outra época: o ID
+	// recebido do anterior é reconhecido como perda em vez de coincidir com o evento 1
+	restarted := NewBroker(10, 10)
+	restarted.Publish("product.created", []byte(`{}`))
+
+	if first.epoch == restarted.epoch {
+		t.Fatalf("both brokers use epoch %q", first.epoch)
+	}
+	_, backlog, missed, _ := restarted.Subscribe(first.LastEventID(), nil)
+	if len(backlog) != 0 || !missed {
+		t.Errorf("Subscribe(%v) = %v, missed %v; want missed", first.LastEventID(), ids(backlog), missed)
+	}
+}
+
+func TestBroker_PublishAndSubscribe(t *testing.T) {
+	broker := NewBroker(10, 10)
+
+	all, _, _, _ := broker.Subscribe(EventID{}, nil)
+	deleted, _, _, _ := broker.Subscribe(EventID{}, func(name string) bool { return name == "product.deleted" })
+
+	broker.Publish("product.created", []byte(`{"n":1}`))
+	broker.Publish("product.deleted", []byte(`{"n":2}`))
+
+	if msg := <-all.C; msg.ID != (EventID{Epoch: broker.epoch, Seq: 1}) || msg.Event != "product.created" || string(msg.Data) != `{"n":1}` {
+		t.Errorf("all received %+v", msg)
+	}
+	if msg := <-all.C; msg.ID.Seq != 2 {
+		t.Errorf("all received %+v", msg)
+	}
+	if msg := <-deleted.C; msg.ID.Seq != 2 || msg.Event != "product.deleted" {
+		t.Errorf("filtered subscriber received %+v", msg)
+	}
+	if len(deleted.C) != 0 {
+		t.Error("filtered subscriber should not receive other events")
+	}
+	if broker.LastEventID() != (EventID{Epoch: broker.epoch, Seq: 2}) {
+		t.Errorf("LastEventID() = %v, want 2", broker.LastEventID())
+	}
+}
+
+func TestBroker_Resume(t *testing.T) {
+	broker := NewBroker(3, 10)
+	for i := 0; i < 5; i++ {
+		name := "product.created"
+		if i%2 == 1 {
+			name = "product.deleted"
+		}
+		broker.Publish(name, []byte(`{}`))
+	}
+	// Buffer com os eventos 3, 4 e 5
+	at := func(seq uint64) EventID { return EventID{Epoch: broker.epoch, Seq: seq} }
+
+	tests := []struct {
+		name        string
+		lastEventID EventID
+		filter      func(string) bool
+		wantIDs     []uint64
+		wantMissed  bool
+	}{
+		{name: "new client"},
+		{name: "resume inside buffer", lastEventID: at(3), wantIDs: []uint64{4, 5}},
+		{name: "resume from oldest gap boundary", lastEventID: at(2), wantIDs: []uint64{3, 4, 5}},
+		{name: "up to date", lastEventID: at(5)},
+		{name: "events left the buffer", lastEventID: at(1), wantIDs: []uint64{3, 4, 5}, wantMissed: true},
+		{name: "id ahead of the sequence", lastEventID: at(99), wantMissed: true},
+		{name: "id from another process", lastEventID: EventID{Epoch: "other", Seq: 3}, wantMissed: true},
+		{name: "id without epoch", lastEventID: EventID{Seq: 3}, wantMissed: true},
+		{name: "resume with filter", lastEventID: at(2), filter: func(name string) bool { return name == "product.deleted" }, wantIDs: []uint64{4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, backlog, missed, err := broker.Subscribe(tt.lastEventID, tt.filter)
+			if err != nil {
+				t.Fatalf("Subscribe() error = %v", err)
+			}
+			defer sub.Close()
+
+			if !equalIDs(backlog, tt.wantIDs...) || missed != tt.wantMissed {
+				t.Errorf("Subscribe(%v) = %v, missed %v; want %v, missed %v", tt.lastEventID, ids(backlog), missed, tt.wantIDs, tt.wantMissed)
+			}
+		})
+	}
+}
+
+func TestBroker_SlowSubscriberIsDisconnected(t *testing.T) {
+	broker := NewBroker(10, 2)
+	slow, _, _, _ := broker.Subscribe(EventID{}, nil)
+
+	for i := 0; i < 3; i++ {
+		broker.Publish("product.created", []byte(`{}`))
+	}
+
+	received := 0
+	for range slow.C {
+		received++
+	}
+	if received != 2 {
+		t.Errorf("slow subscriber received %d events before being disconnected, want 2", received)
+	}
+
+	// Reconectando com o último ID recebido, o evento perdido vem do buffer
+	_, backlog, missed, _ := broker.Subscribe(EventID{Epoch: broker.epoch, Seq: 2}, nil)
+	if !equalIDs(backlog, 3) || missed {
+		t.Errorf("resume = %v, missed %v", ids(backlog), missed)
+	}
+}
+
+func TestBroker_Close(t *testing.T) {
+	broker := NewBroker(10, 10)
+	sub, _, _, _ := broker.Subscribe(EventID{}, nil)
+
+	broker.Close()
+	broker.Close()
+	sub.Close()
+
+	if _, ok := <-sub.C; ok {
+		t.Error("subscriber channel should be closed")
+	}
+	if _, _, _, err := broker.Subscribe(EventID{}, nil); !errors.Is(err, ErrBrokerClosed) {
+		t.Errorf("Subscribe() after Close error = %v", err)
+	}
+
+	broker.Publish("product.created", []byte(`{}`))
+	if broker.LastEventID().Seq != 0 {
+		t.Error("Publish() after Close should be ignored")
+	}
+}
+
+func TestBroker_Handle(t *testing.T) {
+	broker := NewBroker(10, 10)
+	sub, _, _, _ := broker.Subscribe(EventID{}, nil)
+
+	broker.Handle(product_events.NewProductRestoredEvent("id-1", "Notebook", 12345))
+
+	msg := <-sub.C
+	var envelope struct {
+		ID      string `json:"id"`
+		Name    string `json:"name"`
+		Payload struct {
+			Name string `json:"name"`
+		} `json:"payload"`
+	}
+	if err := json.Unmarshal(msg.Data, &envelope); err != nil {
+		t.Fatalf("invalid data: %v", err)
+	}
+	if msg.Event != "product.restored" || envelope.ID == "" || envelope.Name != "product.restored" || envelope.Payload.Name != "Notebook" {
+		t.Errorf("message = %+v (%s)", msg, msg.Data)
+	}
+}
@@ -0,0 +1,163 @@
+package persistence
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	shared_events "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/events"
+)
+
+// PostgresDeadLetterStore guarda a dead-letter store do EventDispatcher na tabela dead_letters,
+// para que os eventos com falha sobrevivam a um reinício e continuem disponíveis para replay.
+// Ao contrário da store em memória, não há limite de registros.
+type PostgresDeadLetterStore struct {
+	db       *sql.DB
+	registry *shared_events.EventRegistry
+}
+
+func NewPostgresDeadLetterStore(db *sql.DB, registry *shared_events.EventRegistry) *PostgresDeadLetterStore {
+	return &PostgresDeadLetterStore{db: db, registry: registry}
+}
+
+// Save grava o registro junto com o envelope do evento; um registro existente
+// (replay que falhou novamente) tem o erro, as tentativas e a data atualizados
+func (s *PostgresDeadLetterStore) Save(entry shared_events.DeadLetter) error {
+	envelope := shared_events.Wrap(entry.Event)
+	payload, err := json.Marshal(envelope.Payload)
+	if err != nil {
+		return fmt.Errorf("erro ao serializar evento %s: %w", envelope.Name, err)
+	}
+
+	_, err = s.db.Exec(`
+		INSERT INTO dead_letters (id, handler, event_id, event_name, event_version, aggregate_id, correlation_id, occurred_at, payload, error, attempts, failed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (id) DO UPDATE
+		SET error = EXCLUDED.error, attempts = EXCLUDED.attempts, failed_at = EXCLUDED.failed_at
+	`, entry.ID, entry.HandlerName, envelope.ID, envelope.Name, envelope.Version, envelope.AggregateID,
+		sql.NullString{String: envelope.CorrelationID, Valid: envelope.CorrelationID != ""},
+		envelope.OccurredAt, payload, entry.Error, entry.Attempts, entry.FailedAt)
+	if err != nil {
+		return fmt.Errorf("erro ao gravar evento na dead-letter store: %w", err)
+	}
+
+	return nil
+}
+
+// List retorna os registros do mais antigo para o mais recente. Registros de eventos
+// sem tipo registrado ou inválidos são pulados com um aviso.
+func (s *PostgresDeadLetterStore) List() ([]shared_events.DeadLetter, error) {
+	rows, err := s.db.Query(`
+		SELECT ` + deadLetterColumns + `
+		FROM dead_letters
+		ORDER BY failed_at, id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar a dead-letter store: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []shared_events.DeadLetter{}
+	for rows.Next() {
+		entry, err := s.scan(rows)
+		if err != nil {
+			var decodeErr *deadLetterDecodeError
+			if errors.As(err, &decodeErr) {
+				log.Printf("⚠️  Registro %s da dead-letter store ignorado: %v", decodeErr.id, decodeErr.err)
+				continue
+			}
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao ler a dead-letter store: %w", err)
+	}
+
+	return entries, nil
+}
+
+func (s *PostgresDeadLetterStore) Get(id string) (shared_events.DeadLetter, error) {
+	entry, err := s.scan(s.db.QueryRow(`
+		SELECT `+deadLetterColumns+`
+		FROM dead_letters
+		WHERE id = $1
+	`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return shared_events.DeadLetter{}, shared_events.ErrDeadLetterNotFound
+	}
+
+	return entry, err
+}
+
+func (s *PostgresDeadLetterStore) Delete(id string) error {
+	result, err := s.db.Exec(`DELETE FROM dead_letters WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("erro ao remover evento da dead-letter store: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("erro ao verificar linhas afetadas: %w", err)
+	}
+	if deleted == 0 {
+		return shared_events.ErrDeadLetterNotFound
+	}
+
+	return nil
+}
+
+const deadLetterColumns = `id, handler, event_id, event_name, event_version, aggregate_id, correlation_id, occurred_at, payload, error, attempts, failed_at`
+
+// deadLetterDecodeError indica um registro cujo evento não pôde ser desserializado
+type deadLetterDecodeError struct {
+	id  string
+	err error
+}
+
+func (e *deadLetterDecodeError) Error() string {
+	return fmt.Sprintf("registro %s da dead-letter store inválido: %v", e.id, e.err)
+}
+
+func (e *deadLetterDecodeError) Unwrap() error {
+	return e.err
+}
+
+// scan lê um registro e reconstrói o envelope do evento, convertendo versões antigas pelo registry
+func (s *PostgresDeadLetterStore) scan(row rowScanner) (shared_events.DeadLetter, error) {
+	var (
+		entry         shared_events.DeadLetter
+		envelope      shared_events.Envelope
+		correlationID sql.NullString
+		occurredAt    time.Time
+		failedAt      time.Time
+		payload       []byte
+	)
+	err := row.Scan(&entry.ID, &entry.HandlerName, &envelope.ID, &envelope.Name, &envelope.Version, &envelope.AggregateID,
+		&correlationID, &occurredAt, &payload, &entry.Error, &entry.Attempts, &failedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entry, err
+	}
+	if err != nil {
+		return entry, fmt.Errorf("erro ao escanear a dead-letter store: %w", err)
+	}
+
+	event, version, err := s.registry.Decode(envelope.Name, envelope.Version, payload)
+	if err != nil {
+		return entry, &deadLetterDecodeError{id: entry.ID, err: err}
+	}
+
+	envelope.Version = version
+	envelope.CorrelationID = correlationID.String
+	envelope.OccurredAt = occurredAt.UTC()
+	envelope.Payload = event
+
+	entry.EventName = envelope.Name
+	entry.Event = &envelope
+	entry.FailedAt = failedAt.UTC()
+	return entry, nil
+}
@@ -0,0 +1,161 @@
+package persistence
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	product_events "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/events"
+	shared_events "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/events"
+)
+
+const testDeadLetterID = "0b6f3f8e-9c4d-4f7a-a1e2-3c5d7e9f1a2b"
+
+var deadLetterRowColumns = []string{"id", "handler", "event_id", "event_name", "event_version", "aggregate_id", "correlation_id", "occurred_at", "payload", "error", "attempts", "failed_at"}
+
+func TestPostgresDeadLetterStore_Save(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	envelope := shared_events.WithCorrelationID("req-1", product_events.NewProductRestoredEvent(testPublicID, "Notebook", 12345))[0].(*shared_events.Envelope)
+	payload, _ := json.Marshal(envelope.Payload)
+	failedAt := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	// Um replay que falha novamente atualiza o registro existente
+	mock.ExpectExec("INSERT INTO dead_letters (.+) ON CONFLICT \\(id\\) DO UPDATE SET error = EXCLUDED.error, attempts = EXCLUDED.attempts, failed_at = EXCLUDED.failed_at").
+		WithArgs(testDeadLetterID, "notify-erp", envelope.ID, "product.restored", 1, testPublicID, "req-1", envelope.OccurredAt, payload, "timeout", 5, failedAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = NewPostgresDeadLetterStore(db, product_events.NewEventRegistry()).Save(shared_events.DeadLetter{
+		ID:          testDeadLetterID,
+		EventName:   "product.restored",
+		HandlerName: "notify-erp",
+		Event:       envelope,
+		Error:       "timeout",
+		Attempts:    5,
+		FailedAt:    failedAt,
+	})
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestPostgresDeadLetterStore_ListAndGet(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	restored, _ := json.Marshal(product_events.NewProductRestoredEvent(testPublicID, "Notebook", 12345))
+	at := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT (.+) FROM dead_letters ORDER BY failed_at, id").
+		WillReturnRows(sqlmock.NewRows(deadLetterRowColumns).
+			AddRow(testDeadLetterID, "notify-erp", "evt-1", "product.restored", 1, testPublicID, "req-1", at, restored, "timeout", 5, at).
+			AddRow("other-id", "notify-erp", "evt-2", "product.unknown", 1, testPublicID, nil, at, []byte(`{}`), "timeout", 5, at))
+	mock.ExpectQuery("SELECT (.+) FROM dead_letters WHERE id = \\$1").
+		WithArgs(testDeadLetterID).
+		WillReturnRows(sqlmock.NewRows(deadLetterRowColumns).
+			AddRow(testDeadLetterID, "notify-erp", "evt-1", "product.restored", 1, testPublicID, nil, at, restored, "timeout", 5, at))
+	mock.ExpectQuery("SELECT (.+) FROM dead_letters WHERE id = \\$1").
+		WithArgs("missing").
+		WillReturnRows(sqlmock.NewRows(deadLetterRowColumns))
+
+	store := NewPostgresDeadLetterStore(db, product_events.NewEventRegistry())
+
+	// O registro do evento sem tipo registrado é pulado
+	entries, err := store.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("List() = %d entries, want 1", len(entries))
+	}
+	entry := entries[0]
+	if entry.ID != testDeadLetterID || entry.HandlerName != "notify-erp" || entry.EventName != "product.restored" || entry.Attempts != 5 || !entry.FailedAt.Equal(at) {
+		t.Errorf("entries[0] = %+v", entry)
+	}
+	envelope, ok := entry.Event.(*shared_events.Envelope)
+	if !ok || envelope.ID != "evt-1" || envelope.CorrelationID != "req-1" {
+		t.Fatalf("entries[0].Event = %+v, want the original envelope", entry.Event)
+	}
+	if restored, ok := envelope.Payload.(*product_events.ProductRestoredEvent); !ok || restored.Name != "Notebook" {
+		t.Errorf("entries[0].Event.Payload = %+v", envelope.Payload)
+	}
+
+	if entry, err := store.Get(testDeadLetterID); err != nil || entry.Event.(*shared_events.Envelope).ID != "evt-1" {
+		t.Errorf("Get() = %+v, %v", entry, err)
+	}
+	if _, err := store.Get("missing"); !errors.Is(err, shared_events.ErrDeadLetterNotFound) {
+		t.Errorf("Get(missing) error = %v, want %v", err, shared_events.ErrDeadLetterNotFound)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestPostgresDeadLetterStore_Delete(t *testing.T) {
+	tests := []struct {
+		name      string
+		mockSetup func(sqlmock.Sqlmock)
+		wantErr   error
+	}{
+		{
+			name: "existing entry",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("DELETE FROM dead_letters WHERE id = \\$1").
+					WithArgs(testDeadLetterID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "missing entry",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("DELETE FROM dead_letters").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr: shared_events.ErrDeadLetterNotFound,
+		},
+		{
+			name: "database error",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("DELETE FROM dead_letters").
+					WillReturnError(sql.ErrConnDone)
+			},
+			wantErr: sql.ErrConnDone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Failed to create mock database: %v", err)
+			}
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			err = NewPostgresDeadLetterStore(db, product_events.NewEventRegistry()).Delete(testDeadLetterID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Delete() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
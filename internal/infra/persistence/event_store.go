@@ -0,0 +1,113 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	shared_events "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/events"
+)
+
+// PostgresEventStore guarda o histórico de eventos na tabela events.
+// A sequência vem de um BIGSERIAL, então a ordem global só é confiável com um único
+// escritor: o relay do outbox, que entrega os eventos um de cada vez.
+type PostgresEventStore struct {
+	db       *sql.DB
+	registry *shared_events.EventRegistry
+	timeouts QueryTimeouts
+}
+
+func NewPostgresEventStore(db *sql.DB, registry *shared_events.EventRegistry) *PostgresEventStore {
+	return NewPostgresEventStoreWithTimeouts(db, registry, DefaultQueryTimeouts())
+}
+
+// NewPostgresEventStoreWithTimeouts cria o histórico com timeouts próprios
+func NewPostgresEventStoreWithTimeouts(db *sql.DB, registry *shared_events.EventRegistry, timeouts QueryTimeouts) *PostgresEventStore {
+	return &PostgresEventStore{db: db, registry: registry, timeouts: timeouts}
+}
+
+// Append grava o envelope; um evento reentregue pelo outbox (mesmo event_id) não é duplicado
+func (s *PostgresEventStore) Append(ctx context.Context, envelope *shared_events.Envelope) (int64, error) {
+	payload, err := json.Marshal(envelope.Payload)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao serializar evento %s: %w", envelope.Name, err)
+	}
+
+	ctx, cancel := withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+
+	var sequence int64
+	err = s.db.QueryRowContext(ctx, `
+		INSERT INTO events (event_id, event_name, event_version, aggregate_id, correlation_id, occurred_at, payload)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (event_id) DO NOTHING
+		RETURNING sequence
+	`, envelope.ID, envelope.Name, envelope.Version, envelope.AggregateID,
+		sql.NullString{String: envelope.CorrelationID, Valid: envelope.CorrelationID != ""},
+		envelope.OccurredAt, payload).Scan(&sequence)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		err = s.db.QueryRowContext(ctx, `SELECT sequence FROM events WHERE event_id = $1`, envelope.ID).Scan(&sequence)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("erro ao gravar evento no histórico: %w", err)
+	}
+
+	return sequence, nil
+}
+
+// ReadFrom lê o histórico a partir da sequência. Eventos sem tipo registrado ou
+// inválidos são pulados com um aviso, para não travar a reconstrução das projeções.
+func (s *PostgresEventStore) ReadFrom(ctx context.Context, sequence int64, limit int) ([]*shared_events.Envelope, int64, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Read)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT sequence, event_id, event_name, event_version, aggregate_id, correlation_id, occurred_at, payload
+		FROM events
+		WHERE sequence >= $1
+		ORDER BY sequence
+		LIMIT $2
+	`, sequence, limit)
+	if err != nil {
+		return nil, sequence, fmt.Errorf("erro ao buscar eventos do histórico: %w", err)
+	}
+	defer rows.Close()
+
+	next := sequence
+	var events []*shared_events.Envelope
+	for rows.Next() {
+		var (
+			stored        shared_events.Envelope
+			correlationID sql.NullString
+			occurredAt    time.Time
+			payload       []byte
+		)
+		if err := rows.Scan(&stored.Sequence, &stored.ID, &stored.Name, &stored.Version, &stored.AggregateID, &correlationID, &occurredAt, &payload); err != nil {
+			return nil, sequence, fmt.Errorf("erro ao escanear evento do histórico: %w", err)
+		}
+		next = stored.Sequence + 1
+
+		event, version, err := s.registry.Decode(stored.Name, stored.Version, payload)
+		if err != nil {
+			log.Printf("⚠️  Evento %s (histórico #%d) ignorado: %v", stored.Name, stored.Sequence, err)
+			continue
+		}
+
+		stored.Version = version
+		stored.CorrelationID = correlationID.String
+		stored.OccurredAt = occurredAt.UTC()
+		stored.Payload = event
+		events = append(events, &stored)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, sequence, fmt.Errorf("erro ao ler eventos do histórico: %w", err)
+	}
+
+	return events, next, nil
+}
@@ -0,0 +1,147 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	product_events "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/events"
+	shared_events "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/events"
+)
+
+func TestPostgresEventStore_Append(t *testing.T) {
+	envelope := shared_events.WithCorrelationID("req-1", product_events.NewProductRestoredEvent(testPublicID, "Notebook", 12345))[0].(*shared_events.Envelope)
+	payload, _ := json.Marshal(envelope.Payload)
+
+	tests := []struct {
+		name         string
+		mockSetup    func(sqlmock.Sqlmock)
+		wantSequence int64
+		wantErr      bool
+	}{
+		{
+			name: "new event",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("INSERT INTO events (.+) ON CONFLICT \\(event_id\\) DO NOTHING RETURNING sequence").
+					WithArgs(envelope.ID, "product.restored", 1, testPublicID, "req-1", envelope.OccurredAt, payload).
+					WillReturnRows(sqlmock.NewRows([]string{"sequence"}).AddRow(int64(7)))
+			},
+			wantSequence: 7,
+		},
+		{
+			name: "event already recorded",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("INSERT INTO events").
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery("SELECT sequence FROM events WHERE event_id = \\$1").
+					WithArgs(envelope.ID).
+					WillReturnRows(sqlmock.NewRows([]string{"sequence"}).AddRow(int64(3)))
+			},
+			wantSequence: 3,
+		},
+		{
+			name: "database error",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("INSERT INTO events").
+					WillReturnError(sql.ErrConnDone)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Failed to create mock database: %v", err)
+			}
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			sequence, err := NewPostgresEventStore(db, product_events.NewEventRegistry()).Append(context.Background(), envelope)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Append() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if sequence != tt.wantSequence {
+				t.Errorf("Append() sequence = %d, want %d", sequence, tt.wantSequence)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestPostgresEventStore_ReadFrom(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	restored, _ := json.Marshal(product_events.NewProductRestoredEvent(testPublicID, "Notebook", 12345))
+	occurredAt := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	columns := []string{"sequence", "event_id", "event_name", "event_version", "aggregate_id", "correlation_id", "occurred_at", "payload"}
+
+	mock.ExpectQuery("SELECT sequence, event_id, event_name, event_version, aggregate_id, correlation_id, occurred_at, payload FROM events WHERE sequence >= \\$1 ORDER BY sequence LIMIT \\$2").
+		WithArgs(int64(10), 3).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(int64(10), "evt-10", "product.restored", 1, testPublicID, "req-1", occurredAt, restored).
+			AddRow(int64(11), "evt-11", "product.unknown", 1, testPublicID, nil, occurredAt, []byte(`{}`)).
+			AddRow(int64(12), "evt-12", "product.restored", 1, testPublicID, nil, occurredAt, restored))
+	mock.ExpectQuery("SELECT (.+) FROM events").
+		WithArgs(int64(13), 3).
+		WillReturnRows(sqlmock.NewRows(columns))
+
+	store := NewPostgresEventStore(db, product_events.NewEventRegistry())
+
+	events, next, err := store.ReadFrom(context.Background(), 10, 3)
+	if err != nil {
+		t.Fatalf("ReadFrom() error = %v", err)
+	}
+	// O evento sem tipo registrado é pulado, mas a leitura continua depois dele
+	if len(events) != 2 || next != 13 {
+		t.Fatalf("ReadFrom() = %d events, next %d; want 2 events, next 13", len(events), next)
+	}
+	first := events[0]
+	if first.Sequence != 10 || first.ID != "evt-10" || first.CorrelationID != "req-1" || !first.OccurredAt.Equal(occurredAt) {
+		t.Errorf("events[0] = %+v", first)
+	}
+	if restored, ok := first.Payload.(*product_events.ProductRestoredEvent); !ok || restored.Name != "Notebook" {
+		t.Errorf("events[0].Payload = %+v", first.Payload)
+	}
+
+	events, next, err = store.ReadFrom(context.Background(), 13, 3)
+	if err != nil || len(events) != 0 || next != 13 {
+		t.Errorf("ReadFrom(13) = %d events, next %d, err %v; want end of history", len(events), next, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestPostgresEventStore_QueryTimeouts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("INSERT INTO events").
+		WillDelayFor(time.Second).
+		WillReturnRows(sqlmock.NewRows([]string{"sequence"}).AddRow(int64(1)))
+
+	store := NewPostgresEventStoreWithTimeouts(db, product_events.NewEventRegistry(), QueryTimeouts{Write: 10 * time.Millisecond})
+	envelope := shared_events.NewEnvelope(product_events.NewProductRestoredEvent(testPublicID, "Notebook", 12345))
+	start := time.Now()
+	_, err = store.Append(context.Background(), envelope)
+	if err == nil || time.Since(start) > 500*time.Millisecond {
+		t.Errorf("Expected the insert to be canceled by the timeout, got %v after %v", err, time.Since(start))
+	}
+}
@@ -0,0 +1,326 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	shared_events "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/events"
+)
+
+// outboxLockKey identifica o advisory lock do relay: só uma instância entrega eventos por vez,
+// o que mantém a ordem de entrega por agregado mesmo com várias réplicas da aplicação
+const outboxLockKey = 7_001_001
+
+// insertOutboxEvents grava os eventos no outbox dentro da transação informada,
+// junto com os metadados do envelope. Eles só serão entregues pelo relay se a
+// transação for confirmada.
+func insertOutboxEvents(ctx context.Context, tx *sql.Tx, events []shared_events.Event) error {
+	for _, event := range events {
+		if event == nil {
+			continue
+		}
+
+		envelope := shared_events.Wrap(event)
+		payload, err := json.Marshal(envelope.Payload)
+		if err != nil {
+			return fmt.Errorf("erro ao serializar evento %s: %w", envelope.Name, err)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO outbox (event_id, aggregate_id, event_name, event_version, correlation_id, occurred_at, payload)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`, envelope.ID, envelope.AggregateID, envelope.Name, envelope.Version,
+			sql.NullString{String: envelope.CorrelationID, Valid: envelope.CorrelationID != ""},
+			envelope.OccurredAt, payload)
+
+		if err != nil {
+			return fmt.Errorf("erro ao gravar evento no outbox: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// insertOutboxEventBatch grava os eventos no outbox com um único comando, para as escritas
+// em lote em que um INSERT por evento custaria uma ida ao banco por produto
+func insertOutboxEventBatch(ctx context.Context, tx *sql.Tx, events []shared_events.Event) error {
+	var (
+		ids, aggregateIDs, names, correlationIDs, occurredAt, payloads []string
+		versions                                                       []int64
+	)
+	for _, event := range events {
+		if event == nil {
+			continue
+		}
+
+		envelope := shared_events.Wrap(event)
+		payload, err := json.Marshal(envelope.Payload)
+		if err != nil {
+			return fmt.Errorf("erro ao serializar evento %s: %w", envelope.Name, err)
+		}
+
+		ids = append(ids, envelope.ID)
+		aggregateIDs = append(aggregateIDs, envelope.AggregateID)
+		names = append(names, envelope.Name)
+		versions = append(versions, int64(envelope.Version))
+		correlationIDs = append(correlationIDs, envelope.CorrelationID)
+		occurredAt = append(occurredAt, envelope.OccurredAt.UTC().Format(time.RFC3339Nano))
+		payloads = append(payloads, string(payload))
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO outbox (event_id, aggregate_id, event_name, event_version, correlation_id, occurred_at, payload)
+		SELECT event_id, aggregate_id, event_name, event_version, NULLIF(correlation_id, ''), occurred_at, payload
+		FROM unnest($1::uuid[], $2::text[], $3::text[], $4::integer[], $5::text[], $6::timestamp[], $7::jsonb[])
+			AS e (event_id, aggregate_id, event_name, event_version, correlation_id, occurred_at, payload)
+	`, pq.Array(ids), pq.Array(aggregateIDs), pq.Array(names), pq.Array(versions),
+		pq.Array(correlationIDs), pq.Array(occurredAt), pq.Array(payloads))
+	if err != nil {
+		return fmt.Errorf("erro ao gravar eventos no outbox: %w", err)
+	}
+
+	return nil
+}
+
+// OutboxRelayConfig contém as configurações do relay do outbox
+type OutboxRelayConfig struct {
+	PollInterval    time.Duration // Intervalo entre leituras do outbox
+	BatchSize       int           // Máximo de eventos entregues por leitura
+	Retention       time.Duration // Tempo que eventos entregues ficam no outbox antes da limpeza
+	CleanupInterval time.Duration // Intervalo entre limpezas do outbox
+}
+
+// DefaultOutboxRelayConfig retorna a configuração padrão do relay
+func DefaultOutboxRelayConfig() OutboxRelayConfig {
+	return OutboxRelayConfig{
+		PollInterval:    time.Second,
+		BatchSize:       100,
+		Retention:       24 * time.Hour,
+		CleanupInterval: time.Hour,
+	}
+}
+
+// OutboxRelay lê os eventos pendentes do outbox e os entrega ao EventDispatcher.
+// A entrega é at-least-once: um evento só é marcado como entregue depois que todos
+// os handlers terminam, então uma falha no meio do caminho faz o evento ser reentregue.
+type OutboxRelay struct {
+	db         *sql.DB
+	dispatcher *shared_events.EventDispatcher
+	registry   *shared_events.EventRegistry
+	config     OutboxRelayConfig
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	once   sync.Once
+}
+
+func NewOutboxRelay(db *sql.DB, dispatcher *shared_events.EventDispatcher, registry *shared_events.EventRegistry, config OutboxRelayConfig) *OutboxRelay {
+	return &OutboxRelay{
+		db:         db,
+		dispatcher: dispatcher,
+		registry:   registry,
+		config:     config,
+	}
+}
+
+type outboxEntry struct {
+	id            int64
+	eventID       string
+	aggregateID   string
+	eventName     string
+	eventVersion  int
+	correlationID sql.NullString
+	occurredAt    time.Time
+	payload       []byte
+}
+
+// Start inicia o relay em uma goroutine; use Stop para encerrá-lo
+func (r *OutboxRelay) Start() {
+	r.once.Do(func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		r.cancel = cancel
+		r.done = make(chan struct{})
+
+		go r.run(ctx)
+	})
+}
+
+// Stop encerra o relay e espera o lote em andamento terminar, respeitando o prazo do ctx
+func (r *OutboxRelay) Stop(ctx context.Context) error {
+	if r.cancel == nil {
+		return nil
+	}
+	r.cancel()
+
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *OutboxRelay) run(ctx context.Context) {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+
+	lastCleanup := time.Now()
+
+	for {
+		// Continuar lendo enquanto houver lotes cheios para escoar o backlog
+		for {
+			delivered, err := r.RelayBatch(ctx)
+			if err != nil {
+				log.Printf("❌ Erro ao entregar eventos do outbox: %v", err)
+				break
+			}
+			if delivered < r.config.BatchSize || ctx.Err() != nil {
+				break
+			}
+		}
+
+		if time.Since(lastCleanup) >= r.config.CleanupInterval {
+			if _, err := r.Cleanup(ctx); err != nil {
+				log.Printf("❌ Erro ao limpar o outbox: %v", err)
+			}
+			lastCleanup = time.Now()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayBatch entrega um lote de eventos pendentes, em ordem de gravação,
+// e retorna quantos foram entregues
+func (r *OutboxRelay) RelayBatch(ctx context.Context) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Outra instância já está entregando eventos
+	var locked bool
+	if err := tx.QueryRowContext(ctx, `SELECT pg_try_advisory_xact_lock($1)`, outboxLockKey).Scan(&locked); err != nil {
+		return 0, fmt.Errorf("erro ao obter lock do outbox: %w", err)
+	}
+	if !locked {
+		return 0, nil
+	}
+
+	entries, err := r.pendingEntries(ctx, tx)
+	if err != nil {
+		return 0, err
+	}
+	if len(entries) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]int64, 0, len(entries))
+	for _, entry := range entries {
+		// Com o dispatcher encerrado, o restante do lote fica pendente para a próxima execução
+		if err := r.deliver(entry); err != nil {
+			log.Printf("⚠️  Entrega do outbox interrompida em #%d: %v", entry.id, err)
+			break
+		}
+		ids = append(ids, entry.id)
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE outbox
+		SET delivered_at = CURRENT_TIMESTAMP
+		WHERE id = ANY($1)
+	`, pq.Int64Array(ids))
+	if err != nil {
+		return 0, fmt.Errorf("erro ao marcar eventos como entregues: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("erro ao commitar transação: %w", err)
+	}
+
+	return len(ids), nil
+}
+
+// Cleanup remove os eventos entregues há mais tempo que a retenção configurada
+func (r *OutboxRelay) Cleanup(ctx context.Context) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM outbox
+		WHERE delivered_at IS NOT NULL AND delivered_at < $1
+	`, time.Now().UTC().Add(-r.config.Retention))
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func (r *OutboxRelay) pendingEntries(ctx context.Context, tx *sql.Tx) ([]outboxEntry, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, event_id, aggregate_id, event_name, event_version, correlation_id, occurred_at, payload
+		FROM outbox
+		WHERE delivered_at IS NULL
+		ORDER BY id
+		LIMIT $1
+	`, r.config.BatchSize)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar eventos do outbox: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []outboxEntry
+	for rows.Next() {
+		var entry outboxEntry
+		if err := rows.Scan(&entry.id, &entry.eventID, &entry.aggregateID, &entry.eventName, &entry.eventVersion, &entry.correlationID, &entry.occurredAt, &entry.payload); err != nil {
+			return nil, fmt.Errorf("erro ao escanear evento do outbox: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// deliver desserializa o evento, convertendo versões antigas pelo registry, e o entrega
+// no envelope original, esperando os handlers terminarem para que o próximo evento do
+// mesmo agregado não passe na frente. Um evento inválido é descartado; o erro indica que
+// o dispatcher recusou o evento, que deve continuar pendente.
+func (r *OutboxRelay) deliver(entry outboxEntry) error {
+	event, version, err := r.registry.Decode(entry.eventName, entry.eventVersion, entry.payload)
+	if err != nil {
+		if errors.Is(err, shared_events.ErrUnknownEvent) {
+			log.Printf("⚠️  Evento %s (outbox #%d) sem tipo registrado; descartado", entry.eventName, entry.id)
+		} else {
+			log.Printf("⚠️  Evento %s (outbox #%d) inválido; descartado: %v", entry.eventName, entry.id, err)
+		}
+		return nil
+	}
+
+	return r.dispatcher.DispatchAndWait(entry.eventName, &shared_events.Envelope{
+		ID:            entry.eventID,
+		Name:          entry.eventName,
+		Version:       version,
+		AggregateID:   entry.aggregateID,
+		CorrelationID: entry.correlationID.String,
+		OccurredAt:    entry.occurredAt,
+		Payload:       event,
+	})
+}
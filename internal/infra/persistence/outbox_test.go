@@ -0,0 +1,283 @@
+package persistence
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	product_events "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/events"
+	shared_events "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/events"
+)
+
+func TestInsertOutboxEvents(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	event := product_events.NewProductRestoredEvent(testPublicID, "Notebook", 12345)
+	payload, _ := json.Marshal(event)
+	envelope := shared_events.WithCorrelationID("req-1", product_events.NewProductRestoredEvent(testPublicID, "Mouse", 54321))[0].(*shared_events.Envelope)
+	envelopePayload, _ := json.Marshal(envelope.Payload)
+
+	mock.ExpectBegin()
+	// Eventos sem envelope ganham ID e data novos e não têm correlação
+	mock.ExpectExec("INSERT INTO outbox \\(event_id, aggregate_id, event_name, event_version, correlation_id, occurred_at, payload\\)").
+		WithArgs(sqlmock.AnyArg(), testPublicID, "product.restored", 1, nil, sqlmock.AnyArg(), payload).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO outbox").
+		WithArgs(envelope.ID, testPublicID, "product.restored", 1, "req-1", envelope.OccurredAt, envelopePayload).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectCommit()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+	// Eventos nulos são ignorados
+	if err := insertOutboxEvents(context.Background(), tx, []shared_events.Event{nil, event, envelope}); err != nil {
+		t.Fatalf("insertOutboxEvents() error = %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestInsertOutboxEventBatch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	event := product_events.NewProductRestoredEvent(testPublicID, "Notebook", 12345)
+	payload, _ := json.Marshal(event)
+	envelope := shared_events.WithCorrelationID("req-1", product_events.NewProductRestoredEvent(testPublicID, "Mouse", 54321))[0].(*shared_events.Envelope)
+	envelopePayload, _ := json.Marshal(envelope.Payload)
+
+	mock.ExpectBegin()
+	// Um único INSERT para todos os eventos; a correlação vazia vira NULL no próprio comando
+	mock.ExpectExec("INSERT INTO outbox .* FROM unnest").
+		WithArgs(sqlmock.AnyArg(), pq.Array([]string{testPublicID, testPublicID}), pq.Array([]string{"product.restored", "product.restored"}),
+			pq.Array([]int64{1, 1}), pq.Array([]string{"", "req-1"}), sqlmock.AnyArg(), pq.Array([]string{string(payload), string(envelopePayload)})).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+	if err := insertOutboxEventBatch(context.Background(), tx, []shared_events.Event{nil, event, envelope}); err != nil {
+		t.Fatalf("insertOutboxEventBatch() error = %v", err)
+	}
+	// Sem eventos, nada é gravado
+	if err := insertOutboxEventBatch(context.Background(), tx, nil); err != nil {
+		t.Fatalf("insertOutboxEventBatch() error = %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestOutboxRelay_RelayBatch(t *testing.T) {
+	restored, _ := json.Marshal(product_events.NewProductRestoredEvent(testPublicID, "Notebook", 12345))
+	occurredAt := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	columns := []string{"id", "event_id", "aggregate_id", "event_name", "event_version", "correlation_id", "occurred_at", "payload"}
+
+	tests := []struct {
+		name          string
+		mockSetup     func(sqlmock.Sqlmock)
+		closed        bool
+		wantDelivered int
+		wantEvents    []string
+	}{
+		{
+			name: "another instance holds the lock",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT pg_try_advisory_xact_lock").
+					WithArgs(outboxLockKey).
+					WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_xact_lock"}).AddRow(false))
+				mock.ExpectRollback()
+			},
+			wantDelivered: 0,
+		},
+		{
+			name: "no pending events",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT pg_try_advisory_xact_lock").
+					WithArgs(outboxLockKey).
+					WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_xact_lock"}).AddRow(true))
+				mock.ExpectQuery("SELECT id, event_id, aggregate_id, event_name, event_version, correlation_id, occurred_at, payload FROM outbox WHERE delivered_at IS NULL ORDER BY id LIMIT \\$1").
+					WithArgs(10).
+					WillReturnRows(sqlmock.NewRows(columns))
+				mock.ExpectRollback()
+			},
+			wantDelivered: 0,
+		},
+		{
+			name: "delivers pending events in order and marks them as delivered",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT pg_try_advisory_xact_lock").
+					WithArgs(outboxLockKey).
+					WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_xact_lock"}).AddRow(true))
+				mock.ExpectQuery("SELECT (.+) FROM outbox").
+					WithArgs(10).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(int64(1), "evt-1", testPublicID, "product.restored", 1, "req-1", occurredAt, restored).
+						AddRow(int64(2), "evt-2", testPublicID, "product.unknown", 1, nil, occurredAt, []byte(`{}`)).
+						AddRow(int64(3), "evt-3", testPublicID, "product.restored", 1, nil, occurredAt, []byte(`not-json`)).
+						AddRow(int64(4), "evt-4", testPublicID, "product.restored", 2, nil, occurredAt, restored))
+				// Eventos desconhecidos, inválidos ou de versão futura são descartados para não travar o outbox
+				mock.ExpectExec("UPDATE outbox SET delivered_at = CURRENT_TIMESTAMP WHERE id = ANY\\(\\$1\\)").
+					WithArgs("{1,2,3,4}").
+					WillReturnResult(sqlmock.NewResult(0, 4))
+				mock.ExpectCommit()
+			},
+			wantDelivered: 4,
+			wantEvents:    []string{"evt-1 req-1 Notebook"},
+		},
+		{
+			name: "closed dispatcher leaves events pending",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT pg_try_advisory_xact_lock").
+					WithArgs(outboxLockKey).
+					WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_xact_lock"}).AddRow(true))
+				mock.ExpectQuery("SELECT (.+) FROM outbox").
+					WithArgs(10).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(int64(1), "evt-1", testPublicID, "product.restored", 1, "req-1", occurredAt, restored))
+				// Nada é marcado como entregue
+				mock.ExpectRollback()
+			},
+			closed:        true,
+			wantDelivered: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Failed to create mock database: %v", err)
+			}
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			var (
+				mu       sync.Mutex
+				received []string
+			)
+			dispatcher := shared_events.NewEventDispatcher()
+			dispatcher.Register("product.restored", func(event shared_events.Event) {
+				mu.Lock()
+				defer mu.Unlock()
+				envelope := event.(*shared_events.Envelope)
+				restored := envelope.Payload.(*product_events.ProductRestoredEvent)
+				received = append(received, envelope.ID+" "+envelope.CorrelationID+" "+restored.Name)
+			})
+
+			config := DefaultOutboxRelayConfig()
+			config.BatchSize = 10
+			relay := NewOutboxRelay(db, dispatcher, product_events.NewEventRegistry(), config)
+			if tt.closed {
+				_ = dispatcher.Shutdown(context.Background())
+			}
+
+			delivered, err := relay.RelayBatch(context.Background())
+			if err != nil {
+				t.Fatalf("RelayBatch() error = %v", err)
+			}
+			if delivered != tt.wantDelivered {
+				t.Errorf("RelayBatch() delivered = %d, want %d", delivered, tt.wantDelivered)
+			}
+
+			// DispatchAndWait garante que os handlers já terminaram
+			mu.Lock()
+			defer mu.Unlock()
+			if len(received) != len(tt.wantEvents) {
+				t.Fatalf("handlers received %v, want %v", received, tt.wantEvents)
+			}
+			for i := range received {
+				if received[i] != tt.wantEvents[i] {
+					t.Errorf("handlers received %v, want %v", received, tt.wantEvents)
+				}
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestOutboxRelay_Cleanup(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("DELETE FROM outbox WHERE delivered_at IS NOT NULL AND delivered_at < \\$1").
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 42))
+
+	relay := NewOutboxRelay(db, shared_events.NewEventDispatcher(), product_events.NewEventRegistry(), DefaultOutboxRelayConfig())
+
+	removed, err := relay.Cleanup(context.Background())
+	if err != nil {
+		t.Fatalf("Cleanup() error = %v", err)
+	}
+	if removed != 42 {
+		t.Errorf("Cleanup() removed = %d, want 42", removed)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestOutboxRelay_StartStop(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT pg_try_advisory_xact_lock").
+		WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_xact_lock"}).AddRow(false))
+	mock.ExpectRollback()
+
+	relay := NewOutboxRelay(db, shared_events.NewEventDispatcher(), product_events.NewEventRegistry(), DefaultOutboxRelayConfig())
+
+	// Parar um relay que não foi iniciado não faz nada
+	if err := relay.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() before Start() error = %v", err)
+	}
+
+	relay.Start()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := relay.Stop(ctx); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+}
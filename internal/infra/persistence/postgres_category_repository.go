@@ -0,0 +1,423 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	category_entity "github.com/williamkoller/golang-domain-driven-design/internal/domain/category/entity"
+	category_repository "github.com/williamkoller/golang-domain-driven-design/internal/domain/category/repository"
+	product_entity "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/entity"
+	shared_events "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/events"
+)
+
+// categoryColumns são as colunas lidas por scanCategory; parent é a categoria pai
+const categoryColumns = `c.public_id, c.name, c.slug, c.description, COALESCE(parent.public_id::text, ''), c.created_at, c.updated_at
+	FROM categories c
+	LEFT JOIN categories parent ON parent.id = c.parent_id`
+
+// PostgresCategoryRepository guarda as categorias na tabela categories, a mesma usada pelos
+// produtos: product_categories referencia a linha da categoria, então renomeá-la já a
+// renomeia nos produtos, e os triggers da busca textual refazem o índice deles
+type PostgresCategoryRepository struct {
+	db       *sql.DB
+	timeouts QueryTimeouts
+}
+
+func NewPostgresCategoryRepository(db *sql.DB) *PostgresCategoryRepository {
+	return NewPostgresCategoryRepositoryWithTimeouts(db, DefaultQueryTimeouts())
+}
+
+// NewPostgresCategoryRepositoryWithTimeouts cria o repositório com timeouts próprios
+func NewPostgresCategoryRepositoryWithTimeouts(db *sql.DB, timeouts QueryTimeouts) *PostgresCategoryRepository {
+	return &PostgresCategoryRepository{db: db, timeouts: timeouts}
+}
+
+func (r *PostgresCategoryRepository) Add(ctx context.Context, category category_entity.Category) error {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Write)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação: %w", translateCategoryError(err))
+	}
+	defer tx.Rollback()
+
+	parentID, err := categoryParentID(ctx, tx, category.ParentID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO categories (public_id, name, slug, description, parent_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, category.ID, category.Name, category.Slug, category.Description, parentID, category.CreatedAt, category.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("erro ao inserir categoria: %w", translateCategoryError(err))
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("erro ao commitar transação: %w", translateCategoryError(err))
+	}
+
+	return nil
+}
+
+func (r *PostgresCategoryRepository) FindAll(ctx context.Context) ([]category_entity.Category, error) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+categoryColumns+` ORDER BY c.name, c.public_id`)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar categorias: %w", translateCategoryError(err))
+	}
+	defer rows.Close()
+
+	categories := []category_entity.Category{}
+	for rows.Next() {
+		var category category_entity.Category
+		if err := scanCategory(rows, &category); err != nil {
+			return nil, fmt.Errorf("erro ao escanear categoria: %w", err)
+		}
+		categories = append(categories, category)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao ler categorias: %w", translateCategoryError(err))
+	}
+
+	return categories, nil
+}
+
+func (r *PostgresCategoryRepository) FindByID(ctx context.Context, id string) (category_entity.Category, error) {
+	return r.findOne(ctx, `c.public_id = $1`, id)
+}
+
+func (r *PostgresCategoryRepository) FindBySlug(ctx context.Context, slug string) (category_entity.Category, error) {
+	return r.findOne(ctx, `c.slug = $1`, slug)
+}
+
+// Update grava a categoria. Com um novo nome, os produtos da categoria têm a versão
+// incrementada, entram no change feed e gravam product.updated no outbox, pois a sua
+// representação mudou.
+func (r *PostgresCategoryRepository) Update(ctx context.Context, category category_entity.Category) error {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Write)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação: %w", translateCategoryError(err))
+	}
+	defer tx.Rollback()
+
+	var (
+		id          int64
+		currentName string
+	)
+	err = tx.QueryRowContext(ctx, `SELECT id, name FROM categories WHERE public_id = $1 FOR UPDATE`, category.ID).Scan(&id, &currentName)
+	if errors.Is(err, sql.ErrNoRows) {
+		return category_repository.ErrCategoryNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao buscar categoria: %w", translateCategoryError(err))
+	}
+
+	parentID, err := categoryParentID(ctx, tx, category.ParentID)
+	if err != nil {
+		return err
+	}
+	if parentID.Valid {
+		cycle, err := inCategorySubtree(ctx, tx, id, parentID.Int64)
+		if err != nil {
+			return err
+		}
+		if cycle {
+			return category_repository.ErrCategoryCycle
+		}
+	}
+
+	// Os produtos são lidos com o nome atual, antes da renomeação, para o evento
+	if category.Name != currentName {
+		if _, err = touchCategoryProducts(ctx, tx, id, currentName, category.Name); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE categories
+		SET name = $2, slug = $3, description = $4, parent_id = $5, updated_at = $6
+		WHERE id = $1
+	`, id, category.Name, category.Slug, category.Description, parentID, category.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar categoria: %w", translateCategoryError(err))
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("erro ao commitar transação: %w", translateCategoryError(err))
+	}
+
+	return nil
+}
+
+// Delete remove a categoria sem subcategorias nem produtos
+func (r *PostgresCategoryRepository) Delete(ctx context.Context, id string) error {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Write)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM categories c
+		WHERE c.public_id = $1
+		  AND NOT EXISTS (SELECT 1 FROM categories child WHERE child.parent_id = c.id)
+		  AND NOT EXISTS (SELECT 1 FROM product_categories pc WHERE pc.category_id = c.id)
+	`, id)
+	if err != nil {
+		return fmt.Errorf("erro ao excluir categoria: %w", translateCategoryError(err))
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("erro ao verificar linhas afetadas: %w", err)
+	}
+	if affected == 0 {
+		// A categoria não existe ou está em uso
+		if _, err := r.FindByID(ctx, id); err != nil {
+			return err
+		}
+		return category_repository.ErrCategoryInUse
+	}
+
+	return nil
+}
+
+// Merge move os produtos e as subcategorias de sourceID para targetID e remove a categoria
+// de origem, na mesma transação; os produtos que já tinham as duas ficam só com a de destino
+func (r *PostgresCategoryRepository) Merge(ctx context.Context, sourceID, targetID string) (int, error) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Write)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao iniciar transação: %w", translateCategoryError(err))
+	}
+	defer tx.Rollback()
+
+	source, sourceName, err := lockCategory(ctx, tx, sourceID)
+	if err != nil {
+		return 0, err
+	}
+	target, targetName, err := lockCategory(ctx, tx, targetID)
+	if err != nil {
+		return 0, err
+	}
+
+	invalid, err := inCategorySubtree(ctx, tx, source, target)
+	if err != nil {
+		return 0, err
+	}
+	if invalid {
+		return 0, category_repository.ErrInvalidMerge
+	}
+
+	merged, err := touchCategoryProducts(ctx, tx, source, sourceName, targetName)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err = tx.ExecContext(ctx, `
+		INSERT INTO product_categories (product_id, category_id)
+		SELECT product_id, $2 FROM product_categories WHERE category_id = $1
+		ON CONFLICT DO NOTHING
+	`, source, target); err != nil {
+		return 0, fmt.Errorf("erro ao mover produtos da categoria: %w", translateCategoryError(err))
+	}
+
+	if _, err = tx.ExecContext(ctx, `UPDATE categories SET parent_id = $2 WHERE parent_id = $1`, source, target); err != nil {
+		return 0, fmt.Errorf("erro ao mover subcategorias: %w", translateCategoryError(err))
+	}
+
+	// Os vínculos restantes da origem são removidos em cascata
+	if _, err = tx.ExecContext(ctx, `DELETE FROM categories WHERE id = $1`, source); err != nil {
+		return 0, fmt.Errorf("erro ao excluir categoria: %w", translateCategoryError(err))
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("erro ao commitar transação: %w", translateCategoryError(err))
+	}
+
+	return merged, nil
+}
+
+// Ensure cadastra as categorias que não existem; o slug é gerado pelo trigger
+// set_categories_slug, como nas categorias criadas pelos produtos
+func (r *PostgresCategoryRepository) Ensure(ctx context.Context, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+
+	ctx, cancel := withTimeout(ctx, r.timeouts.Write)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO categories (name)
+		SELECT DISTINCT unnest($1::text[])
+		ON CONFLICT (name) DO NOTHING
+	`, pq.Array(names))
+	if err != nil {
+		return fmt.Errorf("erro ao inserir categorias: %w", translateCategoryError(err))
+	}
+
+	return nil
+}
+
+func (r *PostgresCategoryRepository) findOne(ctx context.Context, condition string, arg interface{}) (category_entity.Category, error) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+
+	var category category_entity.Category
+
+	err := scanCategory(r.db.QueryRowContext(ctx, `SELECT `+categoryColumns+` WHERE `+condition, arg), &category)
+	if errors.Is(err, sql.ErrNoRows) {
+		return category_entity.Category{}, category_repository.ErrCategoryNotFound
+	}
+	if err != nil {
+		return category_entity.Category{}, fmt.Errorf("erro ao buscar categoria: %w", translateCategoryError(err))
+	}
+
+	return category, nil
+}
+
+// categoryParentID retorna o id interno da categoria pai, nulo na raiz
+func categoryParentID(ctx context.Context, tx *sql.Tx, parentID string) (sql.NullInt64, error) {
+	if parentID == "" {
+		return sql.NullInt64{}, nil
+	}
+
+	var id int64
+	err := tx.QueryRowContext(ctx, `SELECT id FROM categories WHERE public_id = $1`, parentID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return sql.NullInt64{}, category_repository.ErrParentNotFound
+	}
+	if err != nil {
+		return sql.NullInt64{}, fmt.Errorf("erro ao buscar categoria pai: %w", translateCategoryError(err))
+	}
+
+	return sql.NullInt64{Int64: id, Valid: true}, nil
+}
+
+// lockCategory trava a categoria até o fim da transação e retorna o seu id interno e o nome
+func lockCategory(ctx context.Context, tx *sql.Tx, publicID string) (int64, string, error) {
+	var (
+		id   int64
+		name string
+	)
+	err := tx.QueryRowContext(ctx, `SELECT id, name FROM categories WHERE public_id = $1 FOR UPDATE`, publicID).Scan(&id, &name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, "", category_repository.ErrCategoryNotFound
+	}
+	if err != nil {
+		return 0, "", fmt.Errorf("erro ao buscar categoria: %w", translateCategoryError(err))
+	}
+	return id, name, nil
+}
+
+// inCategorySubtree informa se a categoria id é root ou uma das suas descendentes
+func inCategorySubtree(ctx context.Context, tx *sql.Tx, root, id int64) (bool, error) {
+	var found bool
+	err := tx.QueryRowContext(ctx, `
+		WITH RECURSIVE subtree AS (
+			SELECT id FROM categories WHERE id = $1
+			UNION
+			SELECT c.id FROM categories c INNER JOIN subtree s ON c.parent_id = s.id
+		)
+		SELECT EXISTS (SELECT 1 FROM subtree WHERE id = $2)
+	`, root, id).Scan(&found)
+	if err != nil {
+		return false, fmt.Errorf("erro ao verificar hierarquia das categorias: %w", translateCategoryError(err))
+	}
+	return found, nil
+}
+
+// touchCategoryProducts incrementa a versão dos produtos da categoria, o que também os
+// coloca no change feed, e grava no outbox o product.updated de cada um, com a categoria
+// from trocada por to. Deve ser chamada antes de alterar os vínculos e o nome da categoria,
+// pois os produtos retornados pelo UPDATE têm as categorias anteriores à troca.
+func touchCategoryProducts(ctx context.Context, tx *sql.Tx, categoryID int64, from, to string) (int, error) {
+	rows, err := tx.QueryContext(ctx, `
+		UPDATE products p
+		SET version = version + 1
+		WHERE p.id IN (SELECT product_id FROM product_categories WHERE category_id = $1)
+		RETURNING p.id, p.public_id, p.name, p.sku, p.price, p.currency, p.created_at, p.deleted_at, p.version,
+			ARRAY(SELECT c.name FROM product_categories pc INNER JOIN categories c ON c.id = pc.category_id WHERE pc.product_id = p.id ORDER BY c.name)
+	`, categoryID)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao atualizar produtos da categoria: %w", translateCategoryError(err))
+	}
+	defer rows.Close()
+
+	var events []shared_events.Event
+	for rows.Next() {
+		var (
+			id         int
+			product    product_entity.Product
+			categories pq.StringArray
+		)
+		if err := scanProduct(categoriesScanner{rows, &categories}, &id, &product); err != nil {
+			return 0, fmt.Errorf("erro ao escanear produto: %w", translateCategoryError(err))
+		}
+
+		// A versão já foi incrementada pelo UPDATE; ReplaceCategories a incrementa de novo
+		product.Categories = categories
+		product.Version--
+		product.ReplaceCategories([]string{from}, to)
+		events = append(events, product.PullEvents()...)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("erro ao ler produtos da categoria: %w", translateCategoryError(err))
+	}
+	rows.Close()
+
+	if err := insertOutboxEventBatch(ctx, tx, events); err != nil {
+		return 0, err
+	}
+
+	return len(events), nil
+}
+
+// categoryUniqueConstraints são as constraints únicas de nome e slug das categorias
+var categoryUniqueConstraints = map[string]bool{
+	"categories_name_key": true,
+	"idx_categories_slug": true,
+}
+
+// translateCategoryError converte os erros do driver nos erros do domínio de categorias: a
+// violação das constraints únicas de nome ou slug vira ErrCategoryAlreadyExists e as falhas
+// de conexão, timeout ou cancelamento da consulta viram ErrUnavailable. Os demais seguem
+// como estão.
+func translateCategoryError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		if categoryUniqueConstraints[pqErr.Constraint] {
+			return category_repository.ErrCategoryAlreadyExists
+		}
+		return err
+	}
+
+	if isUnavailable(err) {
+		return fmt.Errorf("%w: %w", category_repository.ErrUnavailable, err)
+	}
+
+	return err
+}
+
+func scanCategory(row rowScanner, category *category_entity.Category) error {
+	if err := row.Scan(&category.ID, &category.Name, &category.Slug, &category.Description, &category.ParentID,
+		&category.CreatedAt, &category.UpdatedAt); err != nil {
+		return err
+	}
+
+	category.CreatedAt, category.UpdatedAt = category.CreatedAt.UTC(), category.UpdatedAt.UTC()
+	return nil
+}
@@ -0,0 +1,435 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+
+	category_entity "github.com/williamkoller/golang-domain-driven-design/internal/domain/category/entity"
+	category_repository "github.com/williamkoller/golang-domain-driven-design/internal/domain/category/repository"
+	product_errors "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/errors"
+)
+
+const testParentID = "5b1c3d5e-7f9a-4b2c-8d4e-6f8a0b2c4d6e"
+
+var categoryRowColumns = []string{"public_id", "name", "slug", "description", "parent_id", "created_at", "updated_at"}
+
+// touchedProductColumns são as colunas retornadas por touchCategoryProducts
+var touchedProductColumns = []string{"id", "public_id", "name", "sku", "price", "currency", "created_at", "deleted_at", "version", "categories"}
+
+func newTestCategory(t *testing.T, parentID string) category_entity.Category {
+	t.Helper()
+
+	category, err := category_entity.NewCategory("Computadores", "", "Notebooks e desktops", parentID)
+	if err != nil {
+		t.Fatalf("NewCategory() error = %v", err)
+	}
+	category.ID = testPublicID
+	return *category
+}
+
+func TestTranslateCategoryError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantKind error
+	}{
+		{name: "unique name", err: &pq.Error{Code: "23505", Constraint: "categories_name_key"}, wantKind: category_repository.ErrCategoryAlreadyExists},
+		{name: "unique slug", err: &pq.Error{Code: "23505", Constraint: "idx_categories_slug"}, wantKind: category_repository.ErrCategoryAlreadyExists},
+		{name: "connection failure", err: &pq.Error{Code: "08006"}, wantKind: category_repository.ErrUnavailable},
+		{name: "deadline", err: context.DeadlineExceeded, wantKind: category_repository.ErrUnavailable},
+		{name: "product unique constraint is kept", err: &pq.Error{Code: "23505", Constraint: "products_name_key"}},
+		{name: "syntax error is kept", err: &pq.Error{Code: "42601"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := translateCategoryError(tt.err)
+
+			if tt.wantKind == nil {
+				if err != tt.err {
+					t.Errorf("translateCategoryError() = %v, want the original error", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantKind) {
+				t.Errorf("translateCategoryError() = %v, want %v", err, tt.wantKind)
+			}
+			// A falha de infraestrutura não é confundida com um erro de produtos
+			if errors.Is(err, product_errors.ErrUnavailable) || errors.Is(err, product_errors.ErrAlreadyExists) {
+				t.Errorf("translateCategoryError() = %v, want a category error", err)
+			}
+		})
+	}
+}
+
+func TestPostgresCategoryRepository_QueryTimeouts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT (.+) FROM categories c").
+		WillDelayFor(time.Second).
+		WillReturnRows(sqlmock.NewRows(categoryRowColumns))
+
+	repo := NewPostgresCategoryRepositoryWithTimeouts(db, QueryTimeouts{Read: 10 * time.Millisecond})
+	start := time.Now()
+	_, err = repo.FindAll(context.Background())
+	if err == nil || time.Since(start) > 500*time.Millisecond {
+		t.Errorf("Expected the query to be canceled by the timeout, got %v after %v", err, time.Since(start))
+	}
+}
+
+func TestPostgresCategoryRepository_Add(t *testing.T) {
+	category := newTestCategory(t, testParentID)
+
+	tests := []struct {
+		name      string
+		mockSetup func(sqlmock.Sqlmock)
+		wantErr   error
+	}{
+		{
+			name: "successful insert",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT id FROM categories WHERE public_id = \\$1").
+					WithArgs(testParentID).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(1)))
+				mock.ExpectExec("INSERT INTO categories").
+					WithArgs(testPublicID, "Computadores", "computadores", "Notebooks e desktops", int64(1), category.CreatedAt, category.UpdatedAt).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "parent not found",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT id FROM categories WHERE public_id = \\$1").
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			wantErr: category_repository.ErrParentNotFound,
+		},
+		{
+			name: "duplicate name or slug",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT id FROM categories WHERE public_id = \\$1").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(1)))
+				mock.ExpectExec("INSERT INTO categories").
+					WillReturnError(&pq.Error{Code: "23505", Constraint: "idx_categories_slug"})
+				mock.ExpectRollback()
+			},
+			wantErr: category_repository.ErrCategoryAlreadyExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Failed to create mock database: %v", err)
+			}
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			err = NewPostgresCategoryRepository(db).Add(context.Background(), category)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Add() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestPostgresCategoryRepository_Find(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	createdAt := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT (.+) FROM categories c LEFT JOIN categories parent ON parent.id = c.parent_id ORDER BY c.name, c.public_id").
+		WillReturnRows(sqlmock.NewRows(categoryRowColumns).
+			AddRow(testPublicID, "Computadores", "computadores", "", testParentID, createdAt, createdAt).
+			AddRow(testParentID, "Eletrônicos", "eletronicos", "", "", createdAt, createdAt))
+	mock.ExpectQuery("SELECT (.+) WHERE c.slug = \\$1").
+		WithArgs("eletronicos").
+		WillReturnRows(sqlmock.NewRows(categoryRowColumns).
+			AddRow(testParentID, "Eletrônicos", "eletronicos", "Aparelhos", "", createdAt, createdAt))
+	mock.ExpectQuery("SELECT (.+) WHERE c.public_id = \\$1").
+		WithArgs(testPublicID).
+		WillReturnError(sql.ErrNoRows)
+
+	repo := NewPostgresCategoryRepository(db)
+
+	categories, err := repo.FindAll(context.Background())
+	if err != nil || len(categories) != 2 || categories[0].ParentID != testParentID || categories[1].ParentID != "" {
+		t.Errorf("FindAll() = %+v, %v", categories, err)
+	}
+
+	category, err := repo.FindBySlug(context.Background(), "eletronicos")
+	if err != nil || category.ID != testParentID || category.Description != "Aparelhos" {
+		t.Errorf("FindBySlug() = %+v, %v", category, err)
+	}
+
+	if _, err := repo.FindByID(context.Background(), testPublicID); !errors.Is(err, category_repository.ErrCategoryNotFound) {
+		t.Errorf("FindByID() missing error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestPostgresCategoryRepository_Update(t *testing.T) {
+	tests := []struct {
+		name      string
+		parentID  string
+		mockSetup func(sqlmock.Sqlmock, category_entity.Category)
+		wantErr   error
+	}{
+		{
+			name: "rename touches the products",
+			mockSetup: func(mock sqlmock.Sqlmock, category category_entity.Category) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT id, name FROM categories WHERE public_id = \\$1 FOR UPDATE").
+					WithArgs(testPublicID).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(int64(2), "Computadorez"))
+				// Os produtos são lidos antes da renomeação e publicam product.updated pelo outbox
+				mock.ExpectQuery("UPDATE products p SET version = version \\+ 1 (.+) RETURNING").
+					WithArgs(int64(2)).
+					WillReturnRows(sqlmock.NewRows(touchedProductColumns).
+						AddRow(1, testPublicID, "Notebook", 1, 350000, "BRL", testCreatedAt, nil, 3, "{Computadorez,Notebooks}").
+						AddRow(2, testParentID, "Desktop", 2, 450000, "BRL", testCreatedAt, testCreatedAt, 2, "{Computadorez}"))
+				mock.ExpectExec("INSERT INTO outbox .* FROM unnest").
+					WithArgs(sqlmock.AnyArg(), pq.Array([]string{testPublicID, testParentID}), pq.Array([]string{"product.updated", "product.updated"}),
+						pq.Array([]int64{1, 1}), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec("UPDATE categories").
+					WithArgs(int64(2), "Computadores", "computadores", "Notebooks e desktops", nil, category.UpdatedAt).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:     "move under a subcategory",
+			parentID: testParentID,
+			mockSetup: func(mock sqlmock.Sqlmock, category category_entity.Category) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT id, name FROM categories").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(int64(2), "Computadores"))
+				mock.ExpectQuery("SELECT id FROM categories WHERE public_id = \\$1").
+					WithArgs(testParentID).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(5)))
+				mock.ExpectQuery("WITH RECURSIVE subtree").
+					WithArgs(int64(2), int64(5)).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectRollback()
+			},
+			wantErr: category_repository.ErrCategoryCycle,
+		},
+		{
+			name: "category not found",
+			mockSetup: func(mock sqlmock.Sqlmock, category category_entity.Category) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT id, name FROM categories").
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			wantErr: category_repository.ErrCategoryNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Failed to create mock database: %v", err)
+			}
+			defer db.Close()
+
+			category := newTestCategory(t, tt.parentID)
+			tt.mockSetup(mock, category)
+
+			err = NewPostgresCategoryRepository(db).Update(context.Background(), category)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Update() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestPostgresCategoryRepository_Delete(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	createdAt := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectExec("DELETE FROM categories c WHERE c.public_id = \\$1").
+		WithArgs(testPublicID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// Em uso: a categoria existe, mas nada foi excluído
+	mock.ExpectExec("DELETE FROM categories").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT (.+) WHERE c.public_id = \\$1").
+		WillReturnRows(sqlmock.NewRows(categoryRowColumns).
+			AddRow(testPublicID, "Computadores", "computadores", "", "", createdAt, createdAt))
+	mock.ExpectExec("DELETE FROM categories").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT (.+) WHERE c.public_id = \\$1").
+		WillReturnError(sql.ErrNoRows)
+
+	repo := NewPostgresCategoryRepository(db)
+
+	if err := repo.Delete(context.Background(), testPublicID); err != nil {
+		t.Errorf("Delete() error = %v", err)
+	}
+	if err := repo.Delete(context.Background(), testPublicID); !errors.Is(err, category_repository.ErrCategoryInUse) {
+		t.Errorf("Delete() in use error = %v, want ErrCategoryInUse", err)
+	}
+	if err := repo.Delete(context.Background(), testPublicID); !errors.Is(err, category_repository.ErrCategoryNotFound) {
+		t.Errorf("Delete() missing error = %v, want ErrCategoryNotFound", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestPostgresCategoryRepository_Merge(t *testing.T) {
+	expectLocks := func(mock sqlmock.Sqlmock) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id, name FROM categories WHERE public_id = \\$1 FOR UPDATE").
+			WithArgs(testPublicID).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(int64(2), "Notebooks"))
+		mock.ExpectQuery("SELECT id, name FROM categories WHERE public_id = \\$1 FOR UPDATE").
+			WithArgs(testParentID).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(int64(1), "Computadores"))
+	}
+
+	tests := []struct {
+		name       string
+		mockSetup  func(sqlmock.Sqlmock)
+		wantMerged int
+		wantErr    error
+	}{
+		{
+			name: "successful merge",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				expectLocks(mock)
+				mock.ExpectQuery("WITH RECURSIVE subtree").
+					WithArgs(int64(2), int64(1)).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+				mock.ExpectQuery("UPDATE products p SET version = version \\+ 1 (.+) RETURNING").
+					WithArgs(int64(2)).
+					WillReturnRows(sqlmock.NewRows(touchedProductColumns).
+						AddRow(1, testPublicID, "Notebook", 1, 350000, "BRL", testCreatedAt, nil, 2, "{Computadores,Notebooks}").
+						AddRow(2, testParentID, "Ultrabook", 2, 550000, "BRL", testCreatedAt, nil, 4, "{Notebooks}"))
+				mock.ExpectExec("INSERT INTO outbox .* FROM unnest").
+					WithArgs(sqlmock.AnyArg(), pq.Array([]string{testPublicID, testParentID}), pq.Array([]string{"product.updated", "product.updated"}),
+						pq.Array([]int64{1, 1}), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec("INSERT INTO product_categories").
+					WithArgs(int64(2), int64(1)).
+					WillReturnResult(sqlmock.NewResult(0, 3))
+				mock.ExpectExec("UPDATE categories SET parent_id = \\$2 WHERE parent_id = \\$1").
+					WithArgs(int64(2), int64(1)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("DELETE FROM categories WHERE id = \\$1").
+					WithArgs(int64(2)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			wantMerged: 2,
+		},
+		{
+			name: "merge into a subcategory",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				expectLocks(mock)
+				mock.ExpectQuery("WITH RECURSIVE subtree").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectRollback()
+			},
+			wantErr: category_repository.ErrInvalidMerge,
+		},
+		{
+			name: "source not found",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT id, name FROM categories").
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			wantErr: category_repository.ErrCategoryNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Failed to create mock database: %v", err)
+			}
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			merged, err := NewPostgresCategoryRepository(db).Merge(context.Background(), testPublicID, testParentID)
+			if !errors.Is(err, tt.wantErr) || merged != tt.wantMerged {
+				t.Errorf("Merge() = %d, %v; want %d, %v", merged, err, tt.wantMerged, tt.wantErr)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestPostgresCategoryRepository_Ensure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("INSERT INTO categories \\(name\\) SELECT DISTINCT unnest\\(\\$1::text\\[\\]\\) ON CONFLICT \\(name\\) DO NOTHING").
+		WithArgs("{\"Eletrônicos\",\"Livros\"}").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	repo := NewPostgresCategoryRepository(db)
+
+	if err := repo.Ensure(context.Background(), []string{"Eletrônicos", "Livros"}); err != nil {
+		t.Errorf("Ensure() error = %v", err)
+	}
+	// Sem nomes, nada é consultado
+	if err := repo.Ensure(context.Background(), nil); err != nil {
+		t.Errorf("Ensure() without names error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
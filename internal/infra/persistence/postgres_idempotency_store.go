@@ -0,0 +1,145 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	shared_identity "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/identity"
+	shared_idempotency "github.com/williamkoller/golang-domain-driven-design/internal/shared/idempotency"
+)
+
+// idempotencyPurgeInterval é o intervalo mínimo entre as limpezas das chaves expiradas
+const idempotencyPurgeInterval = time.Minute
+
+// PostgresIdempotencyStore guarda as chaves de idempotência na tabela idempotency_keys,
+// compartilhadas entre as instâncias da aplicação
+type PostgresIdempotencyStore struct {
+	db  *sql.DB
+	now func() time.Time
+
+	mu        sync.Mutex
+	lastPurge time.Time
+}
+
+func NewPostgresIdempotencyStore(db *sql.DB) *PostgresIdempotencyStore {
+	return &PostgresIdempotencyStore{db: db, now: func() time.Time { return time.Now().UTC() }}
+}
+
+// Reserve insere a chave como em andamento. Uma chave expirada ou com a reserva abandonada
+// é substituída no mesmo comando; caso contrário o registro existente é lido.
+func (s *PostgresIdempotencyStore) Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (shared_idempotency.Record, bool, error) {
+	now := s.now()
+	s.purgeExpired(ctx, now)
+
+	record := shared_idempotency.Record{Key: key, Fingerprint: fingerprint, Token: shared_identity.NewUUID(), CreatedAt: now, ExpiresAt: now.Add(ttl)}
+
+	var reservedKey string
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO idempotency_keys (key, fingerprint, reservation_token, created_at, expires_at)
+		VALUES ($1, $2, $6, $3, $4)
+		ON CONFLICT (key) DO UPDATE
+		SET fingerprint = EXCLUDED.fingerprint,
+		    reservation_token = EXCLUDED.reservation_token,
+		    status_code = NULL,
+		    headers = NULL,
+		    body = NULL,
+		    created_at = EXCLUDED.created_at,
+		    expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= $3
+		   OR (idempotency_keys.status_code IS NULL AND idempotency_keys.created_at <= $5)
+		RETURNING key
+	`, key, fingerprint, now, record.ExpiresAt, now.Add(-shared_idempotency.PendingTimeout), record.Token).Scan(&reservedKey)
+
+	if err == nil {
+		return record, true, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return shared_idempotency.Record{}, false, fmt.Errorf("erro ao reservar chave de idempotência: %w", err)
+	}
+
+	existing, err := s.find(ctx, key)
+	if err != nil {
+		return shared_idempotency.Record{}, false, err
+	}
+
+	return existing, false, nil
+}
+
+// Complete só grava a resposta se a reserva ainda for a do token: uma requisição mais lenta
+// que o PendingTimeout não sobrescreve a reserva feita depois por outra
+func (s *PostgresIdempotencyStore) Complete(ctx context.Context, key, token string, response shared_idempotency.Response) error {
+	headers, err := json.Marshal(response.Header)
+	if err != nil {
+		return fmt.Errorf("erro ao serializar headers da resposta: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		UPDATE idempotency_keys
+		SET status_code = $3, headers = $4, body = $5
+		WHERE key = $1 AND reservation_token = $2 AND status_code IS NULL
+	`, key, token, response.Status, headers, response.Body)
+	if err != nil {
+		return fmt.Errorf("erro ao guardar resposta da chave de idempotência: %w", err)
+	}
+
+	return nil
+}
+
+func (s *PostgresIdempotencyStore) Release(ctx context.Context, key, token string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE key = $1 AND reservation_token = $2 AND status_code IS NULL`, key, token)
+	if err != nil {
+		return fmt.Errorf("erro ao liberar chave de idempotência: %w", err)
+	}
+
+	return nil
+}
+
+func (s *PostgresIdempotencyStore) find(ctx context.Context, key string) (shared_idempotency.Record, error) {
+	record := shared_idempotency.Record{Key: key}
+
+	var (
+		status  sql.NullInt64
+		headers []byte
+		body    []byte
+	)
+	err := s.db.QueryRowContext(ctx, `
+		SELECT fingerprint, status_code, headers, body, created_at, expires_at
+		FROM idempotency_keys
+		WHERE key = $1
+	`, key).Scan(&record.Fingerprint, &status, &headers, &body, &record.CreatedAt, &record.ExpiresAt)
+	if err != nil {
+		return record, fmt.Errorf("erro ao buscar chave de idempotência: %w", err)
+	}
+
+	if status.Valid {
+		response := &shared_idempotency.Response{Status: int(status.Int64), Header: http.Header{}, Body: body}
+		if len(headers) > 0 {
+			if err := json.Unmarshal(headers, &response.Header); err != nil {
+				return record, fmt.Errorf("erro ao ler headers da resposta: %w", err)
+			}
+		}
+		record.Response = response
+	}
+
+	return record, nil
+}
+
+// purgeExpired remove as chaves expiradas, no máximo uma vez por idempotencyPurgeInterval.
+// Uma falha na limpeza não impede a reserva.
+func (s *PostgresIdempotencyStore) purgeExpired(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	if now.Sub(s.lastPurge) < idempotencyPurgeInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastPurge = now
+	s.mu.Unlock()
+
+	s.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= $1`, now)
+}
@@ -0,0 +1,134 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	shared_idempotency "github.com/williamkoller/golang-domain-driven-design/internal/shared/idempotency"
+)
+
+func newTestIdempotencyStore(t *testing.T) (*PostgresIdempotencyStore, sqlmock.Sqlmock, time.Time) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	store := NewPostgresIdempotencyStore(db)
+	store.now = func() time.Time { return now }
+	// A limpeza das chaves expiradas é testada à parte
+	store.lastPurge = now
+
+	return store, mock, now
+}
+
+func TestPostgresIdempotencyStore_Reserve(t *testing.T) {
+	columns := []string{"fingerprint", "status_code", "headers", "body", "created_at", "expires_at"}
+
+	t.Run("new key", func(t *testing.T) {
+		store, mock, now := newTestIdempotencyStore(t)
+		mock.ExpectQuery("INSERT INTO idempotency_keys").
+			WithArgs("key-1", "fp", now, now.Add(time.Hour), now.Add(-shared_idempotency.PendingTimeout), sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"key"}).AddRow("key-1"))
+
+		record, reserved, err := store.Reserve(context.Background(), "key-1", "fp", time.Hour)
+		if err != nil || !reserved || record.ExpiresAt != now.Add(time.Hour) || record.Token == "" {
+			t.Errorf("Reserve() = %+v, %v, %v; want reserved", record, reserved, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("completed key", func(t *testing.T) {
+		store, mock, now := newTestIdempotencyStore(t)
+		mock.ExpectQuery("INSERT INTO idempotency_keys").WillReturnError(sql.ErrNoRows)
+		mock.ExpectQuery("SELECT fingerprint, status_code, headers, body, created_at, expires_at").
+			WithArgs("key-1").
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow("fp", int64(201), []byte(`{"Content-Type":["application/json"]}`), []byte(`{"id":"1"}`), now, now.Add(time.Hour)))
+
+		record, reserved, err := store.Reserve(context.Background(), "key-1", "fp", time.Hour)
+		if err != nil || reserved {
+			t.Fatalf("Reserve() = %v, %v; want the existing record", reserved, err)
+		}
+		if record.Response == nil || record.Response.Status != http.StatusCreated || record.Response.Header.Get("Content-Type") != "application/json" || string(record.Response.Body) != `{"id":"1"}` {
+			t.Errorf("Reserve() response = %+v", record.Response)
+		}
+	})
+
+	t.Run("pending key", func(t *testing.T) {
+		store, mock, now := newTestIdempotencyStore(t)
+		mock.ExpectQuery("INSERT INTO idempotency_keys").WillReturnError(sql.ErrNoRows)
+		mock.ExpectQuery("SELECT fingerprint").
+			WillReturnRows(sqlmock.NewRows(columns).AddRow("fp", nil, nil, nil, now, now.Add(time.Hour)))
+
+		record, reserved, err := store.Reserve(context.Background(), "key-1", "fp", time.Hour)
+		if err != nil || reserved || record.Response != nil {
+			t.Errorf("Reserve() = %+v, %v, %v; want the pending record", record, reserved, err)
+		}
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		store, mock, _ := newTestIdempotencyStore(t)
+		mock.ExpectQuery("INSERT INTO idempotency_keys").WillReturnError(sql.ErrConnDone)
+
+		if _, _, err := store.Reserve(context.Background(), "key-1", "fp", time.Hour); !errors.Is(err, sql.ErrConnDone) {
+			t.Errorf("Reserve() error = %v, want ErrConnDone", err)
+		}
+	})
+
+	t.Run("purges expired keys", func(t *testing.T) {
+		store, mock, now := newTestIdempotencyStore(t)
+		store.lastPurge = time.Time{}
+		mock.ExpectExec("DELETE FROM idempotency_keys WHERE expires_at").
+			WithArgs(now).
+			WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectQuery("INSERT INTO idempotency_keys").
+			WillReturnRows(sqlmock.NewRows([]string{"key"}).AddRow("key-1"))
+		mock.ExpectQuery("INSERT INTO idempotency_keys").
+			WillReturnRows(sqlmock.NewRows([]string{"key"}).AddRow("key-2"))
+
+		store.Reserve(context.Background(), "key-1", "fp", time.Hour)
+		store.Reserve(context.Background(), "key-2", "fp", time.Hour)
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func TestPostgresIdempotencyStore_CompleteAndRelease(t *testing.T) {
+	store, mock, _ := newTestIdempotencyStore(t)
+
+	mock.ExpectExec("UPDATE idempotency_keys (.+) WHERE key = \\$1 AND reservation_token = \\$2 AND status_code IS NULL").
+		WithArgs("key-1", "token-1", 201, []byte(`{"Content-Type":["application/json"]}`), []byte(`{"id":"1"}`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM idempotency_keys WHERE key = \\$1 AND reservation_token = \\$2").
+		WithArgs("key-2", "token-2").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	response := shared_idempotency.Response{
+		Status: http.StatusCreated,
+		Header: http.Header{"Content-Type": []string{"application/json"}},
+		Body:   []byte(`{"id":"1"}`),
+	}
+	if err := store.Complete(context.Background(), "key-1", "token-1", response); err != nil {
+		t.Errorf("Complete() error = %v", err)
+	}
+	if err := store.Release(context.Background(), "key-2", "token-2"); err != nil {
+		t.Errorf("Release() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
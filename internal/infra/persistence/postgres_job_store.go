@@ -0,0 +1,260 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	shared_jobs "github.com/williamkoller/golang-domain-driven-design/internal/shared/jobs"
+)
+
+// jobColumns são as colunas lidas por scanJob, sem o input
+const jobColumns = `id, type, status, params, correlation_id, processed, total, result, result_file, result_type,
+	error, attempts, cancel_requested, created_at, updated_at, started_at, heartbeat_at, finished_at`
+
+// PostgresJobStore guarda os jobs na tabela jobs. A fila é compartilhada entre as
+// instâncias da aplicação: cada job é retirado por um único worker (FOR UPDATE SKIP LOCKED).
+type PostgresJobStore struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+func NewPostgresJobStore(db *sql.DB) *PostgresJobStore {
+	return &PostgresJobStore{db: db, now: func() time.Time { return time.Now().UTC() }}
+}
+
+func (s *PostgresJobStore) Create(ctx context.Context, job shared_jobs.Job) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO jobs (id, type, status, params, input, correlation_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, job.ID, job.Type, job.Status, nullBytes(job.Params), nullBytes(job.Input),
+		nullString(job.CorrelationID), job.CreatedAt, job.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("erro ao inserir job: %w", translateError(err))
+	}
+
+	return nil
+}
+
+func (s *PostgresJobStore) Find(ctx context.Context, id string) (shared_jobs.Job, error) {
+	var job shared_jobs.Job
+
+	err := scanJob(s.db.QueryRowContext(ctx, `SELECT `+jobColumns+` FROM jobs WHERE id = $1`, id), &job)
+	if errors.Is(err, sql.ErrNoRows) {
+		return shared_jobs.Job{}, shared_jobs.ErrJobNotFound
+	}
+	if err != nil {
+		return shared_jobs.Job{}, fmt.Errorf("erro ao buscar job: %w", translateError(err))
+	}
+
+	return job, nil
+}
+
+// Claim marca o job mais antigo da fila como em execução; os jobs já travados por
+// outra instância são pulados
+func (s *PostgresJobStore) Claim(ctx context.Context) (shared_jobs.Job, bool, error) {
+	var job shared_jobs.Job
+
+	err := scanJob(s.db.QueryRowContext(ctx, `
+		UPDATE jobs
+		SET status = 'running', attempts = attempts + 1, started_at = $1, heartbeat_at = $1, updated_at = $1
+		WHERE id = (
+			SELECT id FROM jobs
+			WHERE status = 'queued'
+			ORDER BY created_at, id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+jobColumns+`, input
+	`, s.now()), &job, &job.Input)
+	if errors.Is(err, sql.ErrNoRows) {
+		return shared_jobs.Job{}, false, nil
+	}
+	if err != nil {
+		return shared_jobs.Job{}, false, fmt.Errorf("erro ao retirar job da fila: %w", translateError(err))
+	}
+
+	return job, true, nil
+}
+
+func (s *PostgresJobStore) Heartbeat(ctx context.Context, id string, progress shared_jobs.Progress) (bool, error) {
+	var cancelRequested bool
+
+	err := s.db.QueryRowContext(ctx, `
+		UPDATE jobs
+		SET processed = $2, total = $3, heartbeat_at = $4, updated_at = $4
+		WHERE id = $1 AND status = 'running'
+		RETURNING cancel_requested
+	`, id, progress.Processed, progress.Total, s.now()).Scan(&cancelRequested)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, shared_jobs.ErrJobFinished
+	}
+	if err != nil {
+		return false, fmt.Errorf("erro ao gravar heartbeat do job: %w", translateError(err))
+	}
+
+	return cancelRequested, nil
+}
+
+func (s *PostgresJobStore) Finish(ctx context.Context, job shared_jobs.Job) error {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE jobs
+		SET status = $2, processed = $3, total = $4, result = $5, result_file = $6, result_type = $7,
+		    error = $8, input = NULL, finished_at = $9, updated_at = $9
+		WHERE id = $1 AND status = 'running'
+	`, job.ID, job.Status, job.Progress.Processed, job.Progress.Total, nullBytes(job.Result),
+		nullString(job.ResultFile), nullString(job.ResultType), nullString(job.Error), s.now())
+	if err != nil {
+		return fmt.Errorf("erro ao finalizar job: %w", translateError(err))
+	}
+
+	return requireRunningJob(result)
+}
+
+func (s *PostgresJobStore) Requeue(ctx context.Context, id string) error {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE jobs
+		SET status = 'queued', processed = 0, total = 0, started_at = NULL, heartbeat_at = NULL, updated_at = $2
+		WHERE id = $1 AND status = 'running'
+	`, id, s.now())
+	if err != nil {
+		return fmt.Errorf("erro ao devolver job à fila: %w", translateError(err))
+	}
+
+	return requireRunningJob(result)
+}
+
+func (s *PostgresJobStore) Cancel(ctx context.Context, id string) (shared_jobs.Job, error) {
+	var job shared_jobs.Job
+
+	// As expressões do SET leem os valores anteriores da linha
+	err := scanJob(s.db.QueryRowContext(ctx, `
+		UPDATE jobs
+		SET cancel_requested = TRUE,
+		    status = CASE WHEN status = 'queued' THEN 'canceled' ELSE status END,
+		    input = CASE WHEN status = 'queued' THEN NULL ELSE input END,
+		    finished_at = CASE WHEN status = 'queued' THEN $2 ELSE finished_at END,
+		    updated_at = $2
+		WHERE id = $1 AND status IN ('queued', 'running')
+		RETURNING `+jobColumns+`
+	`, id, s.now()), &job)
+	if errors.Is(err, sql.ErrNoRows) {
+		// O job não existe ou já terminou
+		if _, err := s.Find(ctx, id); err != nil {
+			return shared_jobs.Job{}, err
+		}
+		return shared_jobs.Job{}, shared_jobs.ErrJobFinished
+	}
+	if err != nil {
+		return shared_jobs.Job{}, fmt.Errorf("erro ao cancelar job: %w", translateError(err))
+	}
+
+	return job, nil
+}
+
+// Recover encerra os jobs abandonados sem tentativas restantes ou com cancelamento pedido
+// e devolve os demais à fila
+func (s *PostgresJobStore) Recover(ctx context.Context, staleBefore time.Time, maxAttempts int) (int, error) {
+	now := s.now()
+
+	finished, err := s.db.ExecContext(ctx, `
+		UPDATE jobs
+		SET status = CASE WHEN cancel_requested THEN 'canceled' ELSE 'failed' END,
+		    error = CASE WHEN cancel_requested THEN error ELSE $3 END,
+		    input = NULL, finished_at = $4, updated_at = $4
+		WHERE status = 'running' AND heartbeat_at < $1 AND (cancel_requested OR attempts >= $2)
+	`, staleBefore, maxAttempts, shared_jobs.InterruptedError, now)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao encerrar jobs abandonados: %w", translateError(err))
+	}
+
+	requeued, err := s.db.ExecContext(ctx, `
+		UPDATE jobs
+		SET status = 'queued', processed = 0, total = 0, started_at = NULL, heartbeat_at = NULL, updated_at = $2
+		WHERE status = 'running' AND heartbeat_at < $1
+	`, staleBefore, now)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao devolver jobs abandonados à fila: %w", translateError(err))
+	}
+
+	finishedCount, _ := finished.RowsAffected()
+	requeuedCount, _ := requeued.RowsAffected()
+	return int(finishedCount + requeuedCount), nil
+}
+
+func (s *PostgresJobStore) Purge(ctx context.Context, before time.Time) ([]shared_jobs.Job, error) {
+	rows, err := s.db.QueryContext(ctx, `DELETE FROM jobs WHERE finished_at < $1 RETURNING `+jobColumns, before)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao remover jobs terminados: %w", translateError(err))
+	}
+	defer rows.Close()
+
+	var jobs []shared_jobs.Job
+	for rows.Next() {
+		var job shared_jobs.Job
+		if err := scanJob(rows, &job); err != nil {
+			return nil, fmt.Errorf("erro ao escanear job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, rows.Err()
+}
+
+// scanJob lê as colunas de jobColumns e, em seguida, os destinos extras
+func scanJob(row rowScanner, job *shared_jobs.Job, extra ...interface{}) error {
+	var (
+		params, result                                []byte
+		correlationID, resultFile, resultType, jobErr sql.NullString
+		startedAt, heartbeatAt, finishedAt            sql.NullTime
+	)
+
+	dest := append([]interface{}{
+		&job.ID, &job.Type, &job.Status, &params, &correlationID, &job.Progress.Processed, &job.Progress.Total,
+		&result, &resultFile, &resultType, &jobErr, &job.Attempts, &job.CancelRequested,
+		&job.CreatedAt, &job.UpdatedAt, &startedAt, &heartbeatAt, &finishedAt,
+	}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return err
+	}
+
+	job.Params, job.Result = params, result
+	job.CorrelationID, job.ResultFile, job.ResultType, job.Error = correlationID.String, resultFile.String, resultType.String, jobErr.String
+	job.CreatedAt, job.UpdatedAt = job.CreatedAt.UTC(), job.UpdatedAt.UTC()
+	job.StartedAt, job.HeartbeatAt, job.FinishedAt = utcTime(startedAt), utcTime(heartbeatAt), utcTime(finishedAt)
+	return nil
+}
+
+// requireRunningJob retorna ErrJobFinished se nenhum job em execução foi alterado
+func requireRunningJob(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("erro ao verificar linhas afetadas: %w", err)
+	}
+	if affected == 0 {
+		return shared_jobs.ErrJobFinished
+	}
+	return nil
+}
+
+// nullBytes grava NULL para um conteúdo vazio
+func nullBytes(data []byte) interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	return data
+}
+
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+func utcTime(value sql.NullTime) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	t := value.Time.UTC()
+	return &t
+}
@@ -0,0 +1,237 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	shared_jobs "github.com/williamkoller/golang-domain-driven-design/internal/shared/jobs"
+)
+
+var jobColumnNames = []string{"id", "type", "status", "params", "correlation_id", "processed", "total", "result", "result_file", "result_type",
+	"error", "attempts", "cancel_requested", "created_at", "updated_at", "started_at", "heartbeat_at", "finished_at"}
+
+func newTestJobStore(t *testing.T) (*PostgresJobStore, sqlmock.Sqlmock, time.Time) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	store := NewPostgresJobStore(db)
+	store.now = func() time.Time { return now }
+
+	return store, mock, now
+}
+
+// runningJobRow é a linha de um job em execução desde now
+func runningJobRow(now time.Time) []driver.Value {
+	return []driver.Value{"job-1", "product.import", "running", []byte(`{"mode":"best_effort"}`), "corr-1", 5, 10, nil, nil, nil,
+		nil, 1, false, now, now, now, now, nil}
+}
+
+func TestPostgresJobStore_Create(t *testing.T) {
+	store, mock, now := newTestJobStore(t)
+	job := shared_jobs.NewJob("product.import", json.RawMessage(`{"mode":"best_effort"}`), []byte("name,sku"), "")
+	job.CreatedAt, job.UpdatedAt = now, now
+
+	mock.ExpectExec("INSERT INTO jobs").
+		WithArgs(job.ID, "product.import", shared_jobs.StatusQueued, []byte(`{"mode":"best_effort"}`), []byte("name,sku"), sql.NullString{}, now, now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := store.Create(context.Background(), job); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPostgresJobStore_Find(t *testing.T) {
+	t.Run("found", func(t *testing.T) {
+		store, mock, now := newTestJobStore(t)
+		mock.ExpectQuery("SELECT id, type, status, (.+) FROM jobs WHERE id = \\$1").
+			WithArgs("job-1").
+			WillReturnRows(sqlmock.NewRows(jobColumnNames).AddRow(runningJobRow(now)...))
+
+		job, err := store.Find(context.Background(), "job-1")
+		if err != nil {
+			t.Fatalf("Find() error = %v", err)
+		}
+		if job.Status != shared_jobs.StatusRunning || job.CorrelationID != "corr-1" || job.Progress != (shared_jobs.Progress{Processed: 5, Total: 10}) {
+			t.Errorf("Find() = %+v", job)
+		}
+		if job.StartedAt == nil || !job.StartedAt.Equal(now) || job.FinishedAt != nil || job.Result != nil {
+			t.Errorf("Find() = %+v", job)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		store, mock, _ := newTestJobStore(t)
+		mock.ExpectQuery("FROM jobs").WillReturnError(sql.ErrNoRows)
+
+		if _, err := store.Find(context.Background(), "missing"); !errors.Is(err, shared_jobs.ErrJobNotFound) {
+			t.Errorf("Find() error = %v, want ErrJobNotFound", err)
+		}
+	})
+}
+
+func TestPostgresJobStore_Claim(t *testing.T) {
+	t.Run("oldest queued job", func(t *testing.T) {
+		store, mock, now := newTestJobStore(t)
+		mock.ExpectQuery("UPDATE jobs SET status = 'running', attempts = attempts \\+ 1(.+)FOR UPDATE SKIP LOCKED(.+)RETURNING (.+), input").
+			WithArgs(now).
+			WillReturnRows(sqlmock.NewRows(append(jobColumnNames, "input")).AddRow(append(runningJobRow(now), []byte("name,sku"))...))
+
+		job, ok, err := store.Claim(context.Background())
+		if err != nil || !ok {
+			t.Fatalf("Claim() = %v, %v", ok, err)
+		}
+		if job.ID != "job-1" || string(job.Input) != "name,sku" || job.Attempts != 1 {
+			t.Errorf("Claim() = %+v", job)
+		}
+	})
+
+	t.Run("empty queue", func(t *testing.T) {
+		store, mock, _ := newTestJobStore(t)
+		mock.ExpectQuery("UPDATE jobs").WillReturnError(sql.ErrNoRows)
+
+		if _, ok, err := store.Claim(context.Background()); ok || err != nil {
+			t.Errorf("Claim() = %v, %v; want an empty queue", ok, err)
+		}
+	})
+}
+
+func TestPostgresJobStore_Heartbeat(t *testing.T) {
+	store, mock, now := newTestJobStore(t)
+	mock.ExpectQuery("UPDATE jobs SET processed = \\$2, total = \\$3, heartbeat_at = \\$4(.+)RETURNING cancel_requested").
+		WithArgs("job-1", 5, 10, now).
+		WillReturnRows(sqlmock.NewRows([]string{"cancel_requested"}).AddRow(true))
+	mock.ExpectQuery("UPDATE jobs").WillReturnError(sql.ErrNoRows)
+
+	if canceled, err := store.Heartbeat(context.Background(), "job-1", shared_jobs.Progress{Processed: 5, Total: 10}); err != nil || !canceled {
+		t.Errorf("Heartbeat() = %v, %v; want the cancel request", canceled, err)
+	}
+	if _, err := store.Heartbeat(context.Background(), "job-1", shared_jobs.Progress{}); !errors.Is(err, shared_jobs.ErrJobFinished) {
+		t.Errorf("Heartbeat() of a finished job error = %v, want ErrJobFinished", err)
+	}
+}
+
+func TestPostgresJobStore_Finish(t *testing.T) {
+	store, mock, now := newTestJobStore(t)
+	job := shared_jobs.Job{
+		ID:         "job-1",
+		Status:     shared_jobs.StatusSucceeded,
+		Progress:   shared_jobs.Progress{Processed: 10, Total: 10},
+		Result:     json.RawMessage(`{"products":10}`),
+		ResultFile: "/tmp/jobs/products-job-1.csv",
+		ResultType: "text/csv; charset=utf-8",
+	}
+
+	mock.ExpectExec("UPDATE jobs SET status = \\$2(.+)input = NULL(.+)WHERE id = \\$1 AND status = 'running'").
+		WithArgs("job-1", shared_jobs.StatusSucceeded, 10, 10, []byte(`{"products":10}`),
+			nullString(job.ResultFile), nullString(job.ResultType), sql.NullString{}, now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE jobs").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := store.Finish(context.Background(), job); err != nil {
+		t.Fatalf("Finish() error = %v", err)
+	}
+	if err := store.Finish(context.Background(), job); !errors.Is(err, shared_jobs.ErrJobFinished) {
+		t.Errorf("Finish() of a finished job error = %v, want ErrJobFinished", err)
+	}
+}
+
+func TestPostgresJobStore_Requeue(t *testing.T) {
+	store, mock, now := newTestJobStore(t)
+	mock.ExpectExec("UPDATE jobs SET status = 'queued', processed = 0(.+)WHERE id = \\$1 AND status = 'running'").
+		WithArgs("job-1", now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := store.Requeue(context.Background(), "job-1"); err != nil {
+		t.Fatalf("Requeue() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPostgresJobStore_Cancel(t *testing.T) {
+	t.Run("running job", func(t *testing.T) {
+		store, mock, now := newTestJobStore(t)
+		row := runningJobRow(now)
+		row[12] = true
+		mock.ExpectQuery("UPDATE jobs SET cancel_requested = TRUE(.+)WHERE id = \\$1 AND status IN \\('queued', 'running'\\)").
+			WithArgs("job-1", now).
+			WillReturnRows(sqlmock.NewRows(jobColumnNames).AddRow(row...))
+
+		job, err := store.Cancel(context.Background(), "job-1")
+		if err != nil || !job.CancelRequested || job.Status != shared_jobs.StatusRunning {
+			t.Errorf("Cancel() = %+v, %v", job, err)
+		}
+	})
+
+	t.Run("finished job", func(t *testing.T) {
+		store, mock, now := newTestJobStore(t)
+		mock.ExpectQuery("UPDATE jobs").WillReturnError(sql.ErrNoRows)
+		mock.ExpectQuery("FROM jobs WHERE id = \\$1").WillReturnRows(sqlmock.NewRows(jobColumnNames).AddRow(runningJobRow(now)...))
+
+		if _, err := store.Cancel(context.Background(), "job-1"); !errors.Is(err, shared_jobs.ErrJobFinished) {
+			t.Errorf("Cancel() error = %v, want ErrJobFinished", err)
+		}
+	})
+
+	t.Run("missing job", func(t *testing.T) {
+		store, mock, _ := newTestJobStore(t)
+		mock.ExpectQuery("UPDATE jobs").WillReturnError(sql.ErrNoRows)
+		mock.ExpectQuery("FROM jobs WHERE id = \\$1").WillReturnError(sql.ErrNoRows)
+
+		if _, err := store.Cancel(context.Background(), "missing"); !errors.Is(err, shared_jobs.ErrJobNotFound) {
+			t.Errorf("Cancel() error = %v, want ErrJobNotFound", err)
+		}
+	})
+}
+
+func TestPostgresJobStore_Recover(t *testing.T) {
+	store, mock, now := newTestJobStore(t)
+	staleBefore := now.Add(-time.Minute)
+
+	mock.ExpectExec("UPDATE jobs SET status = CASE WHEN cancel_requested THEN 'canceled' ELSE 'failed' END(.+)attempts >= \\$2").
+		WithArgs(staleBefore, 3, shared_jobs.InterruptedError, now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE jobs SET status = 'queued'(.+)WHERE status = 'running' AND heartbeat_at < \\$1").
+		WithArgs(staleBefore, now).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	recovered, err := store.Recover(context.Background(), staleBefore, 3)
+	if err != nil || recovered != 3 {
+		t.Errorf("Recover() = %d, %v; want 3", recovered, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPostgresJobStore_Purge(t *testing.T) {
+	store, mock, now := newTestJobStore(t)
+	row := runningJobRow(now)
+	row[2], row[8], row[17] = "succeeded", "/tmp/jobs/products-job-1.csv", now
+
+	mock.ExpectQuery("DELETE FROM jobs WHERE finished_at < \\$1 RETURNING").
+		WithArgs(now).
+		WillReturnRows(sqlmock.NewRows(jobColumnNames).AddRow(row...))
+
+	jobs, err := store.Purge(context.Background(), now)
+	if err != nil || len(jobs) != 1 || jobs[0].ResultFile != "/tmp/jobs/products-job-1.csv" || jobs[0].FinishedAt == nil {
+		t.Errorf("Purge() = %+v, %v", jobs, err)
+	}
+}
@@ -1,48 +1,539 @@
 package persistence
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
 
 	product_entity "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/entity"
+	product_errors "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/errors"
 	product_repository "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/repository"
+	product_valueobject "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/valueobject"
+	shared_events "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/events"
 )
 
 type PostgresProductRepository struct {
-	db *sql.DB
+	db       *sql.DB
+	timeouts QueryTimeouts
+}
+
+// QueryTimeouts limita a duração de cada operação no banco, além do cancelamento
+// do ctx recebido; zero desliga o limite
+type QueryTimeouts struct {
+	Read  time.Duration // Consultas de leitura
+	Write time.Duration // Transações de escrita, do BEGIN ao COMMIT
+}
+
+// DefaultQueryTimeouts retorna os timeouts padrão das operações
+func DefaultQueryTimeouts() QueryTimeouts {
+	return QueryTimeouts{Read: 5 * time.Second, Write: 10 * time.Second}
 }
 
 func NewPostgresProductRepository(db *sql.DB) *PostgresProductRepository {
-	return &PostgresProductRepository{db: db}
+	return NewPostgresProductRepositoryWithTimeouts(db, DefaultQueryTimeouts())
+}
+
+// NewPostgresProductRepositoryWithTimeouts cria o repositório com timeouts próprios
+func NewPostgresProductRepositoryWithTimeouts(db *sql.DB, timeouts QueryTimeouts) *PostgresProductRepository {
+	return &PostgresProductRepository{db: db, timeouts: timeouts}
+}
+
+// withTimeout aplica o timeout ao ctx da operação. Ao expirar, o lib/pq pede ao
+// servidor o cancelamento da consulta em andamento.
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
 }
 
 // Add adiciona um novo produto ao banco de dados
-func (r *PostgresProductRepository) Add(product product_entity.Product) error {
-	tx, err := r.db.Begin()
+// e grava os eventos no outbox na mesma transação
+func (r *PostgresProductRepository) Add(ctx context.Context, product product_entity.Product, events ...shared_events.Event) error {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Write)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("erro ao iniciar transação: %w", err)
+		return fmt.Errorf("erro ao iniciar transação: %w", translateError(err))
 	}
 	defer tx.Rollback()
 
 	// Inserir produto
 	var productID int
-	err = tx.QueryRow(`
-		INSERT INTO products (name, sku, price)
-		VALUES ($1, $2, $3)
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO products (public_id, name, sku, price, currency, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
 		RETURNING id
-	`, product.Name, product.Sku, product.Price).Scan(&productID)
+	`, product.ID, product.Name, product.Sku, product.Price.Amount(), product.Price.Currency(), product.CreatedAt).Scan(&productID)
 
 	if err != nil {
-		return fmt.Errorf("erro ao inserir produto: %w", err)
+		return fmt.Errorf("erro ao inserir produto: %w", translateError(err))
 	}
 
 	// Inserir categorias e relacionamentos
-	for _, categoryName := range product.Categories {
+	if err = insertProductCategories(ctx, tx, productID, product.Categories); err != nil {
+		return err
+	}
+
+	if err = insertOutboxEvents(ctx, tx, events); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("erro ao commitar transação: %w", translateError(err))
+	}
+
+	return nil
+}
+
+// AddBatch inclui os produtos em lote, em uma única transação: os conflitos com produtos
+// existentes são detectados em uma consulta e os produtos, categorias e eventos são
+// inseridos com um comando por tabela, em vez de uma ida ao banco por produto
+func (r *PostgresProductRepository) AddBatch(ctx context.Context, entries []product_repository.BatchEntry, atomic bool) (product_repository.BatchResult, error) {
+	result := product_repository.BatchResult{Errors: make([]error, len(entries))}
+	if len(entries) == 0 {
+		return result, nil
+	}
+
+	ctx, cancel := withTimeout(ctx, r.timeouts.Write)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return result, fmt.Errorf("erro ao iniciar transação: %w", translateError(err))
+	}
+	defer tx.Rollback()
+
+	if err = reserveBatch(ctx, tx, entries, result.Errors); err != nil {
+		return result, err
+	}
+	if atomic && result.Failed() {
+		return result, nil
+	}
+
+	productIDs, err := insertBatchProducts(ctx, tx, entries, result.Errors)
+	if err != nil {
+		return result, err
+	}
+	// Um produto gravado por outra transação depois da consulta de conflitos fica fora do lote
+	if atomic && result.Failed() {
+		return result, nil
+	}
+
+	var (
+		events  []shared_events.Event
+		created []product_entity.Product
+		ids     []int
+	)
+	for i, entry := range entries {
+		if result.Errors[i] != nil {
+			continue
+		}
+		created = append(created, entry.Product)
+		ids = append(ids, productIDs[entry.Product.ID])
+		events = append(events, entry.Events...)
+	}
+
+	if err = insertBatchCategories(ctx, tx, ids, created); err != nil {
+		return result, err
+	}
+
+	if err = insertOutboxEventBatch(ctx, tx, events); err != nil {
+		return result, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return result, fmt.Errorf("erro ao commitar transação: %w", translateError(err))
+	}
+
+	result.Created = len(created)
+	return result, nil
+}
+
+// reserveBatch preenche errs com os conflitos de nome e SKU das entradas, entre si e com
+// os produtos já gravados (inclusive excluídos, como nas constraints)
+func reserveBatch(ctx context.Context, tx *sql.Tx, entries []product_repository.BatchEntry, errs []error) error {
+	names := make([]string, len(entries))
+	skus := make([]int64, len(entries))
+	for i, entry := range entries {
+		names[i] = entry.Product.Name
+		skus[i] = int64(entry.Product.Sku)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT name, sku
+		FROM products
+		WHERE name = ANY($1) OR sku = ANY($2)
+	`, pq.Array(names), pq.Array(skus))
+	if err != nil {
+		return fmt.Errorf("erro ao buscar produtos existentes: %w", translateError(err))
+	}
+	defer rows.Close()
+
+	uniqueness := product_repository.NewBatchUniqueness()
+	for rows.Next() {
+		var (
+			name string
+			sku  int
+		)
+		if err := rows.Scan(&name, &sku); err != nil {
+			return fmt.Errorf("erro ao ler produto existente: %w", translateError(err))
+		}
+		uniqueness.Use(name, sku)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("erro ao iterar produtos existentes: %w", translateError(err))
+	}
+
+	for i, entry := range entries {
+		errs[i] = uniqueness.Reserve(entry.Product)
+	}
+
+	return nil
+}
+
+// insertBatchProducts insere as entradas sem erro em errs e retorna o id interno de cada
+// produto inserido pelo seu ID público. Uma entrada que colide com um produto gravado
+// concorrentemente não é inserida e recebe o erro de duplicidade em errs.
+func insertBatchProducts(ctx context.Context, tx *sql.Tx, entries []product_repository.BatchEntry, errs []error) (map[string]int, error) {
+	var (
+		publicIDs, names, currencies []string
+		skus, prices                 []int64
+		createdAts                   []time.Time
+	)
+	for i, entry := range entries {
+		if errs[i] != nil {
+			continue
+		}
+		product := entry.Product
+		publicIDs = append(publicIDs, product.ID)
+		names = append(names, product.Name)
+		skus = append(skus, int64(product.Sku))
+		prices = append(prices, product.Price.Amount())
+		currencies = append(currencies, product.Price.Currency())
+		createdAts = append(createdAts, product.CreatedAt)
+	}
+
+	productIDs := make(map[string]int, len(publicIDs))
+	if len(publicIDs) == 0 {
+		return productIDs, nil
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		INSERT INTO products (public_id, name, sku, price, currency, created_at)
+		SELECT * FROM unnest($1::uuid[], $2::text[], $3::integer[], $4::bigint[], $5::text[], $6::timestamp[])
+		ON CONFLICT DO NOTHING
+		RETURNING id, public_id
+	`, pq.Array(publicIDs), pq.Array(names), pq.Array(skus), pq.Array(prices), pq.Array(currencies), pq.Array(createdAts))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao inserir produtos: %w", translateError(err))
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id       int
+			publicID string
+		)
+		if err := rows.Scan(&id, &publicID); err != nil {
+			return nil, fmt.Errorf("erro ao ler produto inserido: %w", translateError(err))
+		}
+		productIDs[publicID] = id
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao inserir produtos: %w", translateError(err))
+	}
+
+	for i, entry := range entries {
+		if errs[i] != nil {
+			continue
+		}
+		if _, inserted := productIDs[entry.Product.ID]; !inserted {
+			errs[i] = product_errors.NewAlreadyExistsError("")
+		}
+	}
+
+	return productIDs, nil
+}
+
+// insertBatchCategories cria as categorias que ainda não existem e associa cada produto
+// (de id interno ids[i]) às suas categorias
+func insertBatchCategories(ctx context.Context, tx *sql.Tx, ids []int, products []product_entity.Product) error {
+	var names []string
+	seen := make(map[string]bool)
+	for _, product := range products {
+		for _, category := range product.Categories {
+			if !seen[category] {
+				seen[category] = true
+				names = append(names, category)
+			}
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		INSERT INTO categories (name)
+		SELECT unnest($1::text[])
+		ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+		RETURNING id, name
+	`, pq.Array(names))
+	if err != nil {
+		return fmt.Errorf("erro ao inserir categorias: %w", translateError(err))
+	}
+	defer rows.Close()
+
+	categoryIDs := make(map[string]int64, len(names))
+	for rows.Next() {
+		var (
+			id   int64
+			name string
+		)
+		if err := rows.Scan(&id, &name); err != nil {
+			return fmt.Errorf("erro ao ler categoria: %w", translateError(err))
+		}
+		categoryIDs[name] = id
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("erro ao inserir categorias: %w", translateError(err))
+	}
+
+	var productIDs, links []int64
+	for i, product := range products {
+		for _, category := range product.Categories {
+			productIDs = append(productIDs, int64(ids[i]))
+			links = append(links, categoryIDs[category])
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO product_categories (product_id, category_id)
+		SELECT * FROM unnest($1::integer[], $2::integer[])
+		ON CONFLICT DO NOTHING
+	`, pq.Array(productIDs), pq.Array(links))
+	if err != nil {
+		return fmt.Errorf("erro ao associar categorias aos produtos: %w", translateError(err))
+	}
+
+	return nil
+}
+
+// Update substitui os dados do produto identificado por name, se a versão armazenada
+// ainda for a ExpectedVersion do produto
+func (r *PostgresProductRepository) Update(ctx context.Context, name string, product product_entity.Product, events ...shared_events.Event) error {
+	if ok, err := product_entity.Validate(product.Name, product.Sku, product.Categories, product.Price); !ok {
+		return err
+	}
+
+	ctx, cancel := withTimeout(ctx, r.timeouts.Write)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação: %w", translateError(err))
+	}
+	defer tx.Rollback()
+
+	// Atualizar produto
+	var productID int
+	err = tx.QueryRowContext(ctx, `
+		UPDATE products
+		SET name = $1, sku = $2, price = $3, currency = $4, version = version + 1
+		WHERE name = $5 AND deleted_at IS NULL AND version = $6
+		RETURNING id
+	`, product.Name, product.Sku, product.Price.Amount(), product.Price.Currency(), name, product.ExpectedVersion()).Scan(&productID)
+
+	if err == sql.ErrNoRows {
+		return notUpdatedError(ctx, tx, name, "deleted_at IS NULL")
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar produto: %w", translateError(err))
+	}
+
+	// Reescrever relacionamentos produto-categoria
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM product_categories
+		WHERE product_id = $1
+	`, productID)
+
+	if err != nil {
+		return fmt.Errorf("erro ao remover categorias do produto: %w", translateError(err))
+	}
+
+	if err = insertProductCategories(ctx, tx, productID, product.Categories); err != nil {
+		return err
+	}
+
+	if err = insertOutboxEvents(ctx, tx, events); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("erro ao commitar transação: %w", translateError(err))
+	}
+
+	return nil
+}
+
+// Delete marca o produto como excluído preenchendo deleted_at
+func (r *PostgresProductRepository) Delete(ctx context.Context, name string, expectedVersion int, events ...shared_events.Event) error {
+	return r.setDeletedAt(ctx, "CURRENT_TIMESTAMP", "deleted_at IS NULL", name, expectedVersion, events, "erro ao excluir produto")
+}
+
+// Restore desfaz a exclusão de um produto excluído
+func (r *PostgresProductRepository) Restore(ctx context.Context, name string, expectedVersion int, events ...shared_events.Event) error {
+	return r.setDeletedAt(ctx, "NULL", "deleted_at IS NOT NULL", name, expectedVersion, events, "erro ao restaurar produto")
+}
+
+// setDeletedAt atribui value a deleted_at do produto que satisfaz condition, se a versão
+// armazenada for expectedVersion, e grava os eventos no outbox na mesma transação
+func (r *PostgresProductRepository) setDeletedAt(ctx context.Context, value, condition, name string, expectedVersion int, events []shared_events.Event, errMsg string) error {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Write)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação: %w", translateError(err))
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE products
+		SET deleted_at = `+value+`, version = version + 1
+		WHERE name = $1 AND `+condition+` AND version = $2
+	`, name, expectedVersion)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errMsg, translateError(err))
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("erro ao verificar linhas afetadas: %w", translateError(err))
+	}
+	if affected == 0 {
+		return notUpdatedError(ctx, tx, name, condition)
+	}
+
+	if err = insertOutboxEvents(ctx, tx, events); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("erro ao commitar transação: %w", translateError(err))
+	}
+
+	return nil
+}
+
+// notUpdatedError explica por que a alteração do produto não afetou nenhuma linha: ele existe
+// no estado esperado (condition) com outra versão, ou não existe
+func notUpdatedError(ctx context.Context, tx *sql.Tx, name, condition string) error {
+	var exists bool
+	err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM products WHERE name = $1 AND `+condition+`)`, name).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("erro ao verificar versão do produto: %w", translateError(err))
+	}
+	if exists {
+		return product_errors.ErrVersionConflict
+	}
+
+	return product_errors.ErrNotFound
+}
+
+// translateError converte os erros do driver nos erros do domínio de produtos: a violação
+// das constraints únicas de name ou sku vira AlreadyExists e as falhas de conexão,
+// timeout ou cancelamento da consulta viram Unavailable. Os demais, inclusive a violação de
+// outras constraints únicas, seguem como estão.
+func translateError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		if field, ok := uniqueFields[pqErr.Constraint]; ok {
+			return product_errors.NewAlreadyExistsError(field)
+		}
+		return err
+	}
+
+	if isUnavailable(err) {
+		return product_errors.Unavailable(err)
+	}
+
+	return err
+}
+
+// isUnavailable informa se err é uma falha de conexão, timeout ou cancelamento da consulta,
+// e não um erro da própria operação
+func isUnavailable(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		// 08: falha de conexão, 53: recursos insuficientes, 57: consulta cancelada ou servidor encerrando
+		class := pqErr.Code.Class()
+		return class == "08" || class == "53" || class == "57"
+	}
+
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, sql.ErrConnDone) || errors.As(err, &netErr)
+}
+
+// uniqueFields são os campos do produto protegidos pelas constraints únicas de products
+var uniqueFields = map[string]string{
+	"products_name_key": "name",
+	"products_sku_key":  "sku",
+}
+
+// rowScanner é satisfeito por *sql.Row e *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanProduct lê as colunas id, public_id, name, sku, price, currency, created_at, deleted_at e version
+func scanProduct(row rowScanner, id *int, product *product_entity.Product) error {
+	var (
+		amount    int64
+		currency  string
+		deletedAt sql.NullTime
+	)
+
+	if err := row.Scan(id, &product.ID, &product.Name, &product.Sku, &amount, &currency, &product.CreatedAt, &deletedAt, &product.Version); err != nil {
+		return err
+	}
+
+	price, err := product_valueobject.NewMoney(amount, currency)
+	if err != nil {
+		return fmt.Errorf("preço inválido: %w", err)
+	}
+	product.Price = price
+	product.DeletedAt = nullTimePtr(deletedAt)
+	product.MarkLoaded()
+
+	return nil
+}
+
+// nullTimePtr converte um sql.NullTime em ponteiro, nil quando ausente
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+// insertProductCategories cria as categorias que ainda não existem e as associa ao produto
+func insertProductCategories(ctx context.Context, tx *sql.Tx, productID int, categories []string) error {
+	for _, categoryName := range categories {
 		var categoryID int
 
 		// Inserir categoria se não existir (ou pegar ID se já existe)
-		err = tx.QueryRow(`
+		err := tx.QueryRowContext(ctx, `
 			INSERT INTO categories (name)
 			VALUES ($1)
 			ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
@@ -50,134 +541,580 @@ func (r *PostgresProductRepository) Add(product product_entity.Product) error {
 		`, categoryName).Scan(&categoryID)
 
 		if err != nil {
-			return fmt.Errorf("erro ao inserir categoria: %w", err)
+			return fmt.Errorf("erro ao inserir categoria: %w", translateError(err))
 		}
 
 		// Criar relacionamento produto-categoria
-		_, err = tx.Exec(`
+		_, err = tx.ExecContext(ctx, `
 			INSERT INTO product_categories (product_id, category_id)
 			VALUES ($1, $2)
 		`, productID, categoryID)
 
 		if err != nil {
-			return fmt.Errorf("erro ao associar categoria ao produto: %w", err)
+			return fmt.Errorf("erro ao associar categoria ao produto: %w", translateError(err))
+		}
+	}
+
+	return nil
+}
+
+// Find retorna a página de produtos que satisfaz os critérios.
+// As categorias da página são carregadas em uma única consulta.
+func (r *PostgresProductRepository) Find(ctx context.Context, criteria product_repository.ProductCriteria) (product_repository.ProductPage, error) {
+	if err := criteria.Validate(); err != nil {
+		return product_repository.ProductPage{}, err
+	}
+
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+
+	var page product_repository.ProductPage
+
+	filters := newProductFilters(criteria)
+
+	// Total de produtos que satisfazem os filtros, sem paginação
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM products p`+filters.where(), filters.args...).Scan(&page.Total)
+	if err != nil {
+		return page, fmt.Errorf("erro ao contar produtos: %w", translateError(err))
+	}
+
+	order := criteria.OrderBy()
+	column, direction := productOrder(order)
+
+	// Paginação por cursor (keyset): continuar após o último produto da página anterior
+	if criteria.After != nil {
+		value, err := cursorValue(*criteria.After, order)
+		if err != nil {
+			return page, err
+		}
+
+		operator := ">"
+		if order.Descending {
+			operator = "<"
+		}
+		valueArg, idArg := filters.arg(value), filters.arg(criteria.After.ID)
+		filters.conditions = append(filters.conditions, fmt.Sprintf(
+			"(%s %s %s OR (%s = %s AND p.public_id > %s))",
+			column, operator, valueArg, column, valueArg, idArg,
+		))
+	}
+
+	query := `
+		SELECT p.id, p.public_id, p.name, p.sku, p.price, p.currency, p.created_at, p.deleted_at, p.version
+		FROM products p` + filters.where() + `
+		ORDER BY ` + column + ` ` + direction + `, p.public_id ASC`
+
+	// Buscar um produto a mais para saber se existe próxima página
+	if criteria.Limit > 0 {
+		query += ` LIMIT ` + filters.arg(criteria.Limit+1)
+	}
+
+	products, ids, err := r.queryProducts(ctx, query, filters.args...)
+	if err != nil {
+		return page, err
+	}
+
+	if criteria.Limit > 0 && len(products) > criteria.Limit {
+		products, ids = products[:criteria.Limit], ids[:criteria.Limit]
+		page.NextCursor = product_repository.NewProductCursor(products[len(products)-1], order).Encode()
+	}
+
+	if err := r.loadCategories(ctx, products, ids); err != nil {
+		return page, err
+	}
+
+	page.Products = products
+
+	return page, nil
+}
+
+// exportCursor é o cursor do servidor aberto pela exportação, e exportFetchSize a quantidade
+// de produtos lida por FETCH: só um lote fica em memória por vez
+const (
+	exportCursor    = "product_export"
+	exportFetchSize = 500
+)
+
+// Export percorre os produtos que satisfazem os filtros com um cursor do servidor, em uma
+// transação somente leitura. A exportação dura o tempo do download, então o timeout de
+// leitura vale para cada FETCH, não para a exportação inteira.
+func (r *PostgresProductRepository) Export(ctx context.Context, criteria product_repository.ProductCriteria, fn func(product_entity.Product) error) error {
+	if err := criteria.Validate(); err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação: %w", translateError(err))
+	}
+	defer tx.Rollback()
+
+	filters := newProductFilters(criteria)
+	column, direction := productOrder(criteria.OrderBy())
+
+	// As categorias vêm na mesma linha, para que cada lote seja lido com um único FETCH
+	_, err = tx.ExecContext(ctx, `
+		DECLARE `+exportCursor+` NO SCROLL CURSOR FOR
+		SELECT p.id, p.public_id, p.name, p.sku, p.price, p.currency, p.created_at, p.deleted_at, p.version,
+			ARRAY(
+				SELECT c.name
+				FROM product_categories pc
+				INNER JOIN categories c ON c.id = pc.category_id
+				WHERE pc.product_id = p.id
+				ORDER BY c.name
+			)
+		FROM products p`+filters.where()+`
+		ORDER BY `+column+` `+direction+`, p.public_id ASC`, filters.args...)
+	if err != nil {
+		return fmt.Errorf("erro ao abrir cursor de exportação: %w", translateError(err))
+	}
+
+	for {
+		products, err := r.fetchExport(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		for _, product := range products {
+			if err := fn(product); err != nil {
+				return err
+			}
+		}
+
+		if len(products) < exportFetchSize {
+			break
 		}
 	}
 
 	if err = tx.Commit(); err != nil {
-		return fmt.Errorf("erro ao commitar transação: %w", err)
+		return fmt.Errorf("erro ao encerrar exportação: %w", translateError(err))
 	}
 
 	return nil
 }
 
-// Find retorna todos os produtos
-func (r *PostgresProductRepository) Find() ([]product_entity.Product, error) {
-	rows, err := r.db.Query(`
-		SELECT DISTINCT p.id, p.name, p.sku, p.price
-		FROM products p
-		ORDER BY p.created_at DESC
-	`)
+// fetchExport lê o próximo lote do cursor de exportação
+func (r *PostgresProductRepository) fetchExport(ctx context.Context, tx *sql.Tx) ([]product_entity.Product, error) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+
+	rows, err := tx.QueryContext(ctx, `FETCH FORWARD `+strconv.Itoa(exportFetchSize)+` FROM `+exportCursor)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao buscar produtos: %w", err)
+		return nil, fmt.Errorf("erro ao ler cursor de exportação: %w", translateError(err))
 	}
 	defer rows.Close()
 
-	var products []product_entity.Product
-
+	products := make([]product_entity.Product, 0, exportFetchSize)
 	for rows.Next() {
 		var (
-			id    int
-			name  string
-			sku   int
-			price int
+			id         int
+			product    product_entity.Product
+			categories pq.StringArray
 		)
 
-		if err := rows.Scan(&id, &name, &sku, &price); err != nil {
-			return nil, fmt.Errorf("erro ao escanear produto: %w", err)
+		if err := scanProduct(categoriesScanner{rows, &categories}, &id, &product); err != nil {
+			return nil, fmt.Errorf("erro ao escanear produto: %w", translateError(err))
 		}
+		product.Categories = categories
+
+		products = append(products, product)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao ler cursor de exportação: %w", translateError(err))
+	}
+
+	return products, nil
+}
+
+// categoriesScanner lê, depois das colunas de scanProduct, a coluna com as categorias do produto
+type categoriesScanner struct {
+	row        rowScanner
+	categories *pq.StringArray
+}
 
-		// Buscar categorias do produto
-		categories, err := r.getProductCategories(id)
+func (s categoriesScanner) Scan(dest ...interface{}) error {
+	return s.row.Scan(append(dest, s.categories)...)
+}
+
+// Search busca produtos ativos por nome e categoria usando o índice de busca textual,
+// ordenados por relevância. Cada termo casa como prefixo, sem diferenciar acentos.
+func (r *PostgresProductRepository) Search(ctx context.Context, query string, limit int) ([]product_entity.Product, error) {
+	terms := product_repository.Tokenize(query)
+	if len(terms) == 0 {
+		return []product_entity.Product{}, nil
+	}
+
+	// Os termos já saem do tokenizador só com letras e números: "note:* & eletronicos:*"
+	for i, term := range terms {
+		terms[i] = term + ":*"
+	}
+
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+
+	sqlQuery := `
+		SELECT p.id, p.public_id, p.name, p.sku, p.price, p.currency, p.created_at, p.deleted_at, p.version
+		FROM products p
+		WHERE p.deleted_at IS NULL AND p.search_vector @@ to_tsquery('simple', $1)
+		ORDER BY ts_rank(p.search_vector, to_tsquery('simple', $1)) DESC, p.name ASC`
+	args := []interface{}{strings.Join(terms, " & ")}
+	if limit > 0 {
+		sqlQuery += ` LIMIT $2`
+		args = append(args, limit)
+	}
+
+	products, ids, err := r.queryProducts(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.loadCategories(ctx, products, ids); err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+// Reindex reconstrói o documento de busca (search_vector) dos produtos, em ordem de id e
+// em lotes de batchSize, cada um na sua própria transação: um job interrompido não desfaz
+// os lotes já reconstruídos
+func (r *PostgresProductRepository) Reindex(ctx context.Context, batchSize int, progress func(done, total int)) (int, error) {
+	var total int
+	countCtx, cancel := withTimeout(ctx, r.timeouts.Read)
+	err := r.db.QueryRowContext(countCtx, `SELECT COUNT(*) FROM products`).Scan(&total)
+	cancel()
+	if err != nil {
+		return 0, fmt.Errorf("erro ao contar produtos: %w", translateError(err))
+	}
+
+	var (
+		done   int
+		lastID int64
+	)
+	for {
+		var (
+			count int
+			maxID sql.NullInt64
+		)
+		batchCtx, cancel := withTimeout(ctx, r.timeouts.Write)
+		err := r.db.QueryRowContext(batchCtx, `
+			SELECT COUNT(*), MAX(batch.id)
+			FROM (SELECT id FROM products WHERE id > $1 ORDER BY id LIMIT $2) batch
+			CROSS JOIN LATERAL refresh_product_search_vector(batch.id)
+		`, lastID, batchSize).Scan(&count, &maxID)
+		cancel()
 		if err != nil {
-			return nil, fmt.Errorf("erro ao buscar categorias do produto %d: %w", id, err)
+			return done, fmt.Errorf("erro ao reindexar produtos: %w", translateError(err))
+		}
+		if count == 0 {
+			return done, nil
+		}
+
+		done += count
+		lastID = maxID.Int64
+		// Produtos criados durante a reindexação aumentam o total
+		if done > total {
+			total = done
 		}
+		progress(done, total)
+	}
+}
 
-		products = append(products, product_entity.Product{
-			Name:       name,
-			Sku:        sku,
-			Categories: categories,
-			Price:      price,
-		})
+// Changes retorna os produtos escritos depois da posição since, na ordem de commit
+// garantida pelo trigger de change_seq, incluindo os excluídos
+func (r *PostgresProductRepository) Changes(ctx context.Context, since int64, limit int) (product_repository.ChangePage, error) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+
+	query := `
+		SELECT p.id, p.public_id, p.name, p.sku, p.price, p.currency, p.created_at, p.deleted_at,
+			p.version, p.change_seq, p.created_seq
+		FROM products p
+		WHERE p.change_seq > $1
+		ORDER BY p.change_seq ASC`
+	args := []interface{}{since}
+	// Buscar uma alteração a mais para saber se existe próxima página
+	if limit > 0 {
+		query += ` LIMIT $2`
+		args = append(args, limit+1)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return product_repository.ChangePage{}, fmt.Errorf("erro ao buscar alterações de produtos: %w", translateError(err))
 	}
+	defer rows.Close()
 
+	var (
+		ids     []int64
+		changes []product_repository.ProductChange
+	)
+	for rows.Next() {
+		var (
+			id         int
+			createdSeq int64
+			change     product_repository.ProductChange
+		)
+
+		if err := scanProduct(changeScanner{rows, &change.Sequence, &createdSeq}, &id, &change.Product); err != nil {
+			return product_repository.ChangePage{}, fmt.Errorf("erro ao escanear alteração de produto: %w", translateError(err))
+		}
+		change.Type = product_repository.ChangeType(change.Product, createdSeq, since)
+
+		ids = append(ids, int64(id))
+		changes = append(changes, change)
+	}
 	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("erro ao iterar produtos: %w", err)
+		return product_repository.ChangePage{}, fmt.Errorf("erro ao iterar alterações de produtos: %w", translateError(err))
 	}
 
-	return products, nil
+	categories, err := r.getCategoriesByProduct(ctx, ids)
+	if err != nil {
+		return product_repository.ChangePage{}, fmt.Errorf("erro ao buscar categorias dos produtos: %w", translateError(err))
+	}
+	for i, id := range ids {
+		changes[i].Product.Categories = categories[id]
+	}
+
+	return product_repository.NewChangePage(changes, since, limit), nil
+}
+
+// changeScanner acrescenta as colunas change_seq e created_seq às lidas por scanProduct
+type changeScanner struct {
+	rows       rowScanner
+	changeSeq  *int64
+	createdSeq *int64
+}
+
+func (s changeScanner) Scan(dest ...interface{}) error {
+	return s.rows.Scan(append(dest, s.changeSeq, s.createdSeq)...)
+}
+
+// queryProducts executa uma consulta de produtos e retorna também os ids internos
+func (r *PostgresProductRepository) queryProducts(ctx context.Context, query string, args ...interface{}) ([]product_entity.Product, []int64, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao buscar produtos: %w", translateError(err))
+	}
+	defer rows.Close()
+
+	var ids []int64
+	products := []product_entity.Product{}
+
+	for rows.Next() {
+		var (
+			id      int
+			product product_entity.Product
+		)
+
+		if err := scanProduct(rows, &id, &product); err != nil {
+			return nil, nil, fmt.Errorf("erro ao escanear produto: %w", translateError(err))
+		}
+
+		ids = append(ids, int64(id))
+		products = append(products, product)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("erro ao iterar produtos: %w", translateError(err))
+	}
+
+	return products, ids, nil
+}
+
+// loadCategories preenche as categorias de todos os produtos em uma única consulta
+func (r *PostgresProductRepository) loadCategories(ctx context.Context, products []product_entity.Product, ids []int64) error {
+	categories, err := r.getCategoriesByProduct(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("erro ao buscar categorias dos produtos: %w", translateError(err))
+	}
+	for i, id := range ids {
+		products[i].Categories = categories[id]
+	}
+
+	return nil
+}
+
+// productSortColumns mapeia os campos de ordenação para as colunas da tabela
+var productSortColumns = map[string]string{
+	product_repository.SortByCreatedAt: "p.created_at",
+	product_repository.SortByName:      "p.name",
+	product_repository.SortByPrice:     "p.price",
+}
+
+// productOrder retorna a coluna e a direção do ORDER BY da ordenação
+func productOrder(order product_repository.ProductSort) (column, direction string) {
+	if order.Descending {
+		return productSortColumns[order.Field], "DESC"
+	}
+	return productSortColumns[order.Field], "ASC"
+}
+
+// productFilters acumula as condições do WHERE e seus argumentos posicionais
+type productFilters struct {
+	conditions []string
+	args       []interface{}
+}
+
+// newProductFilters traduz os filtros dos critérios (sem paginação) para SQL
+func newProductFilters(criteria product_repository.ProductCriteria) *productFilters {
+	f := &productFilters{}
+
+	if !criteria.IncludeDeleted {
+		f.conditions = append(f.conditions, "p.deleted_at IS NULL")
+	}
+	if criteria.Sku != nil {
+		f.conditions = append(f.conditions, "p.sku = "+f.arg(*criteria.Sku))
+	}
+	if criteria.Currency != "" {
+		f.conditions = append(f.conditions, "p.currency = "+f.arg(criteria.Currency))
+	}
+	if criteria.MinPrice != nil {
+		f.conditions = append(f.conditions, "p.price >= "+f.arg(*criteria.MinPrice))
+	}
+	if criteria.MaxPrice != nil {
+		f.conditions = append(f.conditions, "p.price <= "+f.arg(*criteria.MaxPrice))
+	}
+	if criteria.Category != "" {
+		f.conditions = append(f.conditions, `EXISTS (
+			SELECT 1
+			FROM product_categories pc
+			INNER JOIN categories c ON c.id = pc.category_id
+			WHERE pc.product_id = p.id AND c.name = `+f.arg(criteria.Category)+`
+		)`)
+	}
+	if len(criteria.Categories) > 0 {
+		f.conditions = append(f.conditions, `EXISTS (
+			SELECT 1
+			FROM product_categories pc
+			INNER JOIN categories c ON c.id = pc.category_id
+			WHERE pc.product_id = p.id AND c.name = ANY(`+f.arg(pq.Array(criteria.Categories))+`)
+		)`)
+	}
+
+	return f
+}
+
+// arg adiciona um argumento e retorna o placeholder correspondente ($1, $2, ...)
+func (f *productFilters) arg(value interface{}) string {
+	f.args = append(f.args, value)
+	return "$" + strconv.Itoa(len(f.args))
+}
+
+// where monta a cláusula WHERE, vazia quando não há condições
+func (f *productFilters) where() string {
+	if len(f.conditions) == 0 {
+		return ""
+	}
+	return "\n\t\tWHERE " + strings.Join(f.conditions, " AND ")
+}
+
+// cursorValue converte o valor do cursor para o tipo da coluna de ordenação
+func cursorValue(cursor product_repository.ProductCursor, order product_repository.ProductSort) (interface{}, error) {
+	switch order.Field {
+	case product_repository.SortByPrice:
+		return cursor.PriceValue()
+	case product_repository.SortByCreatedAt:
+		return cursor.TimeValue()
+	default:
+		return cursor.Value, nil
+	}
+}
+
+// FindOne busca um produto pelo nome, incluindo os excluídos se includeDeleted for true
+func (r *PostgresProductRepository) FindOne(ctx context.Context, name string, includeDeleted bool) (product_entity.Product, error) {
+	return r.findOneBy(ctx, "name", name, includeDeleted)
 }
 
-// FindOne busca um produto pelo nome
-func (r *PostgresProductRepository) FindOne(name string) (product_entity.Product, error) {
+// FindByID busca um produto pelo identificador público
+func (r *PostgresProductRepository) FindByID(ctx context.Context, id string, includeDeleted bool) (product_entity.Product, error) {
+	return r.findOneBy(ctx, "public_id", id, includeDeleted)
+}
+
+// FindBySku busca um produto pelo SKU
+func (r *PostgresProductRepository) FindBySku(ctx context.Context, sku int, includeDeleted bool) (product_entity.Product, error) {
+	return r.findOneBy(ctx, "sku", sku, includeDeleted)
+}
+
+// findOneBy busca um único produto pela coluna informada (name, public_id ou sku)
+func (r *PostgresProductRepository) findOneBy(ctx context.Context, column string, value interface{}, includeDeleted bool) (product_entity.Product, error) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+
 	var (
-		id    int
-		sku   int
-		price int
+		id      int
+		product product_entity.Product
 	)
 
-	err := r.db.QueryRow(`
-		SELECT id, name, sku, price
+	query := `
+		SELECT id, public_id, name, sku, price, currency, created_at, deleted_at, version
 		FROM products
-		WHERE name = $1
-	`, name).Scan(&id, &name, &sku, &price)
+		WHERE ` + column + ` = $1
+	`
+	if !includeDeleted {
+		query += `AND deleted_at IS NULL`
+	}
+
+	err := scanProduct(r.db.QueryRowContext(ctx, query, value), &id, &product)
 
 	if err == sql.ErrNoRows {
-		return product_entity.Product{}, errors.New("product not found")
+		return product_entity.Product{}, product_errors.ErrNotFound
 	}
 	if err != nil {
-		return product_entity.Product{}, fmt.Errorf("erro ao buscar produto: %w", err)
+		return product_entity.Product{}, fmt.Errorf("erro ao buscar produto: %w", translateError(err))
 	}
 
 	// Buscar categorias do produto
-	categories, err := r.getProductCategories(id)
+	product.Categories, err = r.getProductCategories(ctx, id)
 	if err != nil {
-		return product_entity.Product{}, fmt.Errorf("erro ao buscar categorias: %w", err)
+		return product_entity.Product{}, fmt.Errorf("erro ao buscar categorias: %w", translateError(err))
 	}
 
-	return product_entity.Product{
-		Name:       name,
-		Sku:        sku,
-		Categories: categories,
-		Price:      price,
-	}, nil
+	return product, nil
 }
 
 // GetMetrics retorna métricas do repositório
-func (r *PostgresProductRepository) GetMetrics() product_repository.RepositoryMetrics {
+func (r *PostgresProductRepository) GetMetrics(ctx context.Context) product_repository.RepositoryMetrics {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+
 	metrics := product_repository.RepositoryMetrics{
+		TotalValue:         make(map[string]float64),
+		AveragePrice:       make(map[string]float64),
 		ProductsByCategory: make(map[string]int),
 	}
 
-	// Total de produtos
-	r.db.QueryRow("SELECT COUNT(*) FROM products").Scan(&metrics.TotalProducts)
+	// Total de produtos (produtos excluídos não entram nas métricas)
+	r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM products WHERE deleted_at IS NULL").Scan(&metrics.TotalProducts)
 
-	// Valor total e preço médio
-	var totalValue sql.NullFloat64
-	r.db.QueryRow("SELECT SUM(price), AVG(price) FROM products").
-		Scan(&totalValue, &metrics.AveragePrice)
-
-	if totalValue.Valid {
-		metrics.TotalValue = totalValue.Float64
+	// Valor total e preço médio por moeda (valores de moedas diferentes não são somados)
+	valueRows, err := r.db.QueryContext(ctx, `
+		SELECT currency, SUM(price), AVG(price)
+		FROM products
+		WHERE deleted_at IS NULL
+		GROUP BY currency
+	`)
+	if err == nil {
+		defer valueRows.Close()
+		for valueRows.Next() {
+			var currency string
+			var totalValue, averagePrice sql.NullFloat64
+			if err := valueRows.Scan(&currency, &totalValue, &averagePrice); err == nil {
+				metrics.TotalValue[currency] = totalValue.Float64
+				metrics.AveragePrice[currency] = averagePrice.Float64
+			}
+		}
 	}
 
 	// Produtos por categoria
-	rows, err := r.db.Query(`
-		SELECT c.name, COUNT(pc.product_id)
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT c.name, COUNT(p.id)
 		FROM categories c
 		LEFT JOIN product_categories pc ON c.id = pc.category_id
+		LEFT JOIN products p ON p.id = pc.product_id AND p.deleted_at IS NULL
 		GROUP BY c.name
-		ORDER BY COUNT(pc.product_id) DESC
+		ORDER BY COUNT(p.id) DESC
 	`)
 	if err == nil {
 		defer rows.Close()
@@ -193,9 +1130,42 @@ func (r *PostgresProductRepository) GetMetrics() product_repository.RepositoryMe
 	return metrics
 }
 
+// getCategoriesByProduct retorna as categorias de vários produtos, indexadas pelo id
+func (r *PostgresProductRepository) getCategoriesByProduct(ctx context.Context, productIDs []int64) (map[int64][]string, error) {
+	categories := make(map[int64][]string, len(productIDs))
+	if len(productIDs) == 0 {
+		return categories, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT pc.product_id, c.name
+		FROM product_categories pc
+		INNER JOIN categories c ON c.id = pc.category_id
+		WHERE pc.product_id = ANY($1)
+		ORDER BY c.name
+	`, pq.Int64Array(productIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			productID int64
+			category  string
+		)
+		if err := rows.Scan(&productID, &category); err != nil {
+			return nil, err
+		}
+		categories[productID] = append(categories[productID], category)
+	}
+
+	return categories, rows.Err()
+}
+
 // getProductCategories retorna as categorias de um produto
-func (r *PostgresProductRepository) getProductCategories(productID int) ([]string, error) {
-	rows, err := r.db.Query(`
+func (r *PostgresProductRepository) getProductCategories(ctx context.Context, productID int) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
 		SELECT c.name
 		FROM categories c
 		INNER JOIN product_categories pc ON c.id = pc.category_id
@@ -1,14 +1,43 @@
 package persistence
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
 	product_entity "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/entity"
+	product_errors "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/errors"
+	product_events "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/events"
 	product_repository "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/repository"
+	product_valueobject "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/valueobject"
+	shared_events "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/events"
 )
 
+var testCreatedAt = time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+
+const testPublicID = "3f2b8c1e-4d5a-4b6c-8d7e-9f0a1b2c3d4e"
+
+// withPendingChange marca o produto como lido na versão anterior à sua, como um produto
+// carregado do repositório e alterado uma vez antes da gravação
+func withPendingChange(product product_entity.Product) product_entity.Product {
+	product.Version--
+	product.MarkLoaded()
+	product.Version++
+	return product
+}
+
 func TestNewPostgresProductRepository(t *testing.T) {
 	db, _, err := sqlmock.New()
 	if err != nil {
@@ -25,22 +54,109 @@ func TestNewPostgresProductRepository(t *testing.T) {
 	if repo.db == nil {
 		t.Error("repository db is nil")
 	}
+	if repo.timeouts != DefaultQueryTimeouts() {
+		t.Errorf("repository timeouts = %+v, want defaults", repo.timeouts)
+	}
+}
+
+func TestTranslateError(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantKind  error
+		wantField string
+	}{
+		{name: "unique name", err: &pq.Error{Code: "23505", Constraint: "products_name_key"}, wantKind: product_errors.ErrAlreadyExists, wantField: "name"},
+		{name: "unique sku", err: &pq.Error{Code: "23505", Constraint: "products_sku_key"}, wantKind: product_errors.ErrAlreadyExists, wantField: "sku"},
+		{name: "connection failure", err: &pq.Error{Code: "08006"}, wantKind: product_errors.ErrUnavailable},
+		{name: "query canceled", err: &pq.Error{Code: "57014"}, wantKind: product_errors.ErrUnavailable},
+		{name: "too many connections", err: &pq.Error{Code: "53300"}, wantKind: product_errors.ErrUnavailable},
+		{name: "deadline", err: context.DeadlineExceeded, wantKind: product_errors.ErrUnavailable},
+		{name: "closed connection", err: sql.ErrConnDone, wantKind: product_errors.ErrUnavailable},
+		{name: "network", err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}, wantKind: product_errors.ErrUnavailable},
+		{name: "syntax error is kept", err: &pq.Error{Code: "42601"}},
+		{name: "other unique constraint is kept", err: &pq.Error{Code: "23505", Constraint: "categories_name_key"}},
+		{name: "duplicated product category is kept", err: &pq.Error{Code: "23505", Constraint: "product_categories_pkey"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := translateError(tt.err)
+
+			if tt.wantKind == nil {
+				if err != tt.err {
+					t.Errorf("translateError() = %v, want the original error", err)
+				}
+				return
+			}
+
+			var domainErr *product_errors.Error
+			if !errors.Is(err, tt.wantKind) || !errors.As(err, &domainErr) || domainErr.Field != tt.wantField {
+				t.Errorf("translateError() = %v, want %v on field %q", err, tt.wantKind, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestPostgresProductRepository_QueryTimeouts(t *testing.T) {
+	t.Run("read timeout cancels a slow query", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("Failed to create mock database: %v", err)
+		}
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT id, public_id, name, sku, price, currency, created_at, deleted_at, version FROM products").
+			WillDelayFor(time.Second).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		repo := NewPostgresProductRepositoryWithTimeouts(db, QueryTimeouts{Read: 10 * time.Millisecond})
+		start := time.Now()
+		_, err = repo.FindOne(context.Background(), "Notebook", false)
+		if err == nil || time.Since(start) > 500*time.Millisecond {
+			t.Errorf("Expected the query to be canceled by the timeout, got %v after %v", err, time.Since(start))
+		}
+	})
+
+	t.Run("canceled request does not start the transaction", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("Failed to create mock database: %v", err)
+		}
+		defer db.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		repo := NewPostgresProductRepository(db)
+		err = repo.Delete(ctx, "Notebook", 1)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("Unfulfilled expectations: %v", err)
+		}
+	})
 }
 
 func TestPostgresProductRepository_Add(t *testing.T) {
 	tests := []struct {
 		name          string
 		product       product_entity.Product
+		events        []shared_events.Event
 		mockSetup     func(sqlmock.Sqlmock)
 		expectedError bool
 	}{
 		{
 			name: "add product successfully",
 			product: product_entity.Product{
+				ID:         "3f2b8c1e-4d5a-4b6c-8d7e-9f0a1b2c3d4e",
 				Name:       "Notebook",
 				Sku:        12345,
 				Categories: []string{"Electronics", "Computers"},
-				Price:      3500,
+				Price:      product_valueobject.MustBRL(3500),
+				CreatedAt:  testCreatedAt,
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				// Expect BEGIN
@@ -49,7 +165,7 @@ func TestPostgresProductRepository_Add(t *testing.T) {
 				// Expect INSERT into products with RETURNING id
 				rows := sqlmock.NewRows([]string{"id"}).AddRow(1)
 				mock.ExpectQuery("INSERT INTO products").
-					WithArgs("Notebook", 12345, 3500).
+					WithArgs("3f2b8c1e-4d5a-4b6c-8d7e-9f0a1b2c3d4e", "Notebook", 12345, int64(3500), "BRL", testCreatedAt).
 					WillReturnRows(rows)
 
 				// Expect INSERT for each category (2 times)
@@ -79,17 +195,19 @@ func TestPostgresProductRepository_Add(t *testing.T) {
 		{
 			name: "product with single category",
 			product: product_entity.Product{
+				ID:         "9a8b7c6d-5e4f-4a3b-9c2d-1e0f9a8b7c6d",
 				Name:       "Book",
 				Sku:        999,
 				Categories: []string{"Books"},
-				Price:      50,
+				Price:      product_valueobject.MustBRL(50),
+				CreatedAt:  testCreatedAt,
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				mock.ExpectBegin()
 
 				rows := sqlmock.NewRows([]string{"id"}).AddRow(2)
 				mock.ExpectQuery("INSERT INTO products").
-					WithArgs("Book", 999, 50).
+					WithArgs("9a8b7c6d-5e4f-4a3b-9c2d-1e0f9a8b7c6d", "Book", 999, int64(50), "BRL", testCreatedAt).
 					WillReturnRows(rows)
 
 				catRows := sqlmock.NewRows([]string{"id"}).AddRow(3)
@@ -105,18 +223,50 @@ func TestPostgresProductRepository_Add(t *testing.T) {
 			},
 			expectedError: false,
 		},
+		{
+			name: "add product with event writes to outbox",
+			product: product_entity.Product{
+				ID:         testPublicID,
+				Name:       "Mouse",
+				Sku:        777,
+				Categories: []string{"Accessories"},
+				Price:      product_valueobject.MustBRL(120),
+				CreatedAt:  testCreatedAt,
+			},
+			events: []shared_events.Event{
+				product_events.NewProductCreatedEvent(testPublicID, "Mouse", 777, []string{"Accessories"}, product_valueobject.MustBRL(120)),
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("INSERT INTO products").
+					WithArgs(testPublicID, "Mouse", 777, int64(120), "BRL", testCreatedAt).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+				mock.ExpectQuery("INSERT INTO categories").
+					WithArgs("Accessories").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+				mock.ExpectExec("INSERT INTO product_categories").
+					WithArgs(3, 4).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO outbox").
+					WithArgs(sqlmock.AnyArg(), testPublicID, "product.created", 1, nil, sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			expectedError: false,
+		},
 		{
 			name: "database error on insert",
 			product: product_entity.Product{
 				Name:       "ErrorProduct",
 				Sku:        111,
 				Categories: []string{"Test"},
-				Price:      100,
+				Price:      product_valueobject.MustBRL(100),
+				CreatedAt:  testCreatedAt,
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				mock.ExpectBegin()
 				mock.ExpectQuery("INSERT INTO products").
-					WithArgs("ErrorProduct", 111, 100).
+					WithArgs("", "ErrorProduct", 111, int64(100), "BRL", testCreatedAt).
 					WillReturnError(sql.ErrConnDone)
 				mock.ExpectRollback()
 			},
@@ -137,7 +287,7 @@ func TestPostgresProductRepository_Add(t *testing.T) {
 			}
 
 			repo := NewPostgresProductRepository(db)
-			err = repo.Add(tt.product)
+			err = repo.Add(context.Background(), tt.product, tt.events...)
 
 			if tt.expectedError {
 				if err == nil {
@@ -157,48 +307,260 @@ func TestPostgresProductRepository_Add(t *testing.T) {
 	}
 }
 
+func TestPostgresProductRepository_AddBatch(t *testing.T) {
+	const (
+		mouseID   = "9a8b7c6d-5e4f-4a3b-9c2d-1e0f9a8b7c6d"
+		monitorID = "1c2d3e4f-5a6b-4c7d-8e9f-0a1b2c3d4e5f"
+	)
+	entries := []product_repository.BatchEntry{
+		{
+			Product: product_entity.Product{ID: mouseID, Name: "Mouse", Sku: 2, Categories: []string{"Accessories"}, Price: product_valueobject.MustBRL(50), CreatedAt: testCreatedAt},
+			Events:  []shared_events.Event{product_events.NewProductCreatedEvent(mouseID, "Mouse", 2, []string{"Accessories"}, product_valueobject.MustBRL(50))},
+		},
+		{
+			Product: product_entity.Product{ID: testPublicID, Name: "Notebook", Sku: 3, Categories: []string{"Electronics"}, Price: product_valueobject.MustBRL(3500), CreatedAt: testCreatedAt},
+		},
+		{
+			Product: product_entity.Product{ID: monitorID, Name: "Monitor", Sku: 5, Categories: []string{"Electronics", "Accessories"}, Price: product_valueobject.MustBRL(900), CreatedAt: testCreatedAt},
+			Events:  []shared_events.Event{product_events.NewProductCreatedEvent(monitorID, "Monitor", 5, []string{"Electronics", "Accessories"}, product_valueobject.MustBRL(900))},
+		},
+	}
+	expectExisting := func(mock sqlmock.Sqlmock, rows *sqlmock.Rows) {
+		mock.ExpectQuery("SELECT name, sku FROM products WHERE name = ANY\\(\\$1\\) OR sku = ANY\\(\\$2\\)").
+			WithArgs(pq.Array([]string{"Mouse", "Notebook", "Monitor"}), pq.Array([]int64{2, 3, 5})).
+			WillReturnRows(rows)
+	}
+
+	tests := []struct {
+		name        string
+		atomic      bool
+		mockSetup   func(sqlmock.Sqlmock)
+		wantCreated int
+		wantErrors  []error
+		wantErr     error
+	}{
+		{
+			name: "best effort inserts the entries without conflicts",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectExisting(mock, sqlmock.NewRows([]string{"name", "sku"}).AddRow("Notebook", 1))
+				mock.ExpectQuery("INSERT INTO products \\(public_id, name, sku, price, currency, created_at\\) SELECT \\* FROM unnest").
+					WithArgs(pq.Array([]string{mouseID, monitorID}), pq.Array([]string{"Mouse", "Monitor"}),
+						pq.Array([]int64{2, 5}), pq.Array([]int64{50, 900}), pq.Array([]string{"BRL", "BRL"}),
+						pq.Array([]time.Time{testCreatedAt, testCreatedAt})).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "public_id"}).AddRow(10, mouseID).AddRow(11, monitorID))
+				mock.ExpectQuery("INSERT INTO categories \\(name\\) SELECT unnest").
+					WithArgs(pq.Array([]string{"Accessories", "Electronics"})).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Accessories").AddRow(2, "Electronics"))
+				mock.ExpectExec("INSERT INTO product_categories").
+					WithArgs(pq.Array([]int64{10, 11, 11}), pq.Array([]int64{1, 2, 1})).
+					WillReturnResult(sqlmock.NewResult(0, 3))
+				mock.ExpectExec("INSERT INTO outbox").
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectCommit()
+			},
+			wantCreated: 2,
+			wantErrors:  []error{nil, product_errors.ErrAlreadyExists, nil},
+		},
+		{
+			name:   "atomic rolls back when an entry conflicts",
+			atomic: true,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectExisting(mock, sqlmock.NewRows([]string{"name", "sku"}).AddRow("Other", 5))
+				mock.ExpectRollback()
+			},
+			wantErrors: []error{nil, nil, product_errors.ErrAlreadyExists},
+		},
+		{
+			name:   "atomic rolls back when a concurrent write takes a name",
+			atomic: true,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectExisting(mock, sqlmock.NewRows([]string{"name", "sku"}))
+				mock.ExpectQuery("INSERT INTO products").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "public_id"}).AddRow(10, mouseID).AddRow(11, monitorID))
+				mock.ExpectRollback()
+			},
+			wantErrors: []error{nil, product_errors.ErrAlreadyExists, nil},
+		},
+		{
+			name: "database error",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectExisting(mock, sqlmock.NewRows([]string{"name", "sku"}))
+				mock.ExpectQuery("INSERT INTO products").WillReturnError(&pq.Error{Code: "57014"})
+				mock.ExpectRollback()
+			},
+			wantErrors: []error{nil, nil, nil},
+			wantErr:    product_errors.ErrUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Failed to create mock database: %v", err)
+			}
+			defer db.Close()
+			tt.mockSetup(mock)
+
+			repo := NewPostgresProductRepository(db)
+			result, err := repo.AddBatch(context.Background(), entries, tt.atomic)
+
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("AddBatch() error = %v, want %v", err, tt.wantErr)
+			}
+			if result.Created != tt.wantCreated {
+				t.Errorf("AddBatch() created = %d, want %d", result.Created, tt.wantCreated)
+			}
+			for i, want := range tt.wantErrors {
+				if (want == nil) != (result.Errors[i] == nil) || !errors.Is(result.Errors[i], want) {
+					t.Errorf("AddBatch() entry %d error = %v, want %v", i, result.Errors[i], want)
+				}
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
 func TestPostgresProductRepository_Find(t *testing.T) {
+	productColumns := []string{"id", "public_id", "name", "sku", "price", "currency", "created_at", "deleted_at", "version"}
+
 	tests := []struct {
-		name          string
-		mockSetup     func(sqlmock.Sqlmock)
-		expectedCount int
-		expectedError bool
+		name           string
+		criteria       product_repository.ProductCriteria
+		mockSetup      func(sqlmock.Sqlmock)
+		expectedCount  int
+		expectedTotal  int
+		expectedCursor bool
+		expectedError  bool
 	}{
 		{
-			name: "find all products successfully",
+			name: "find all products with categories in a single query",
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"id", "name", "sku", "price"}).
-					AddRow(1, "Product1", 1, 100).
-					AddRow(2, "Product2", 2, 200).
-					AddRow(3, "Product3", 3, 300)
-				mock.ExpectQuery("SELECT DISTINCT p.id, p.name, p.sku, p.price FROM products p").
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM products p WHERE p.deleted_at IS NULL$").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+				rows := sqlmock.NewRows(productColumns).
+					AddRow(1, "00000000-0000-4000-8000-000000000001", "Product1", 1, 100, "BRL", testCreatedAt, nil, 1).
+					AddRow(2, "00000000-0000-4000-8000-000000000002", "Product2", 2, 200, "BRL", testCreatedAt, nil, 1).
+					AddRow(3, "00000000-0000-4000-8000-000000000003", "Product3", 3, 300, "BRL", testCreatedAt, nil, 1)
+				mock.ExpectQuery("SELECT p.id, p.public_id, p.name, p.sku, p.price, p.currency, p.created_at, p.deleted_at, p.version FROM products p WHERE p.deleted_at IS NULL ORDER BY p.created_at DESC, p.public_id ASC$").
 					WillReturnRows(rows)
 
-				// Para cada produto, esperar query de categorias
-				catRows1 := sqlmock.NewRows([]string{"name"}).AddRow("Cat1")
-				mock.ExpectQuery("SELECT c.name FROM categories c").
-					WithArgs(1).
-					WillReturnRows(catRows1)
+				catRows := sqlmock.NewRows([]string{"product_id", "name"}).
+					AddRow(1, "Cat1").
+					AddRow(2, "Cat2").
+					AddRow(3, "Cat3")
+				mock.ExpectQuery("SELECT pc.product_id, c.name FROM product_categories pc .* WHERE pc.product_id = ANY\\(\\$1\\)").
+					WithArgs("{1,2,3}").
+					WillReturnRows(catRows)
+			},
+			expectedCount: 3,
+			expectedTotal: 3,
+		},
+		{
+			name: "filters, sort and limit",
+			criteria: func() product_repository.ProductCriteria {
+				sku, minPrice, maxPrice := 1, int64(50), int64(500)
+				return product_repository.ProductCriteria{
+					Category: "Cat1",
+					Sku:      &sku,
+					Currency: "BRL",
+					MinPrice: &minPrice,
+					MaxPrice: &maxPrice,
+					Sort:     product_repository.ProductSort{Field: product_repository.SortByPrice, Descending: true},
+					Limit:    1,
+				}
+			}(),
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				where := "WHERE p.deleted_at IS NULL AND p.sku = \\$1 AND p.currency = \\$2 AND p.price >= \\$3 AND p.price <= \\$4 AND EXISTS \\( SELECT 1 FROM product_categories pc INNER JOIN categories c ON c.id = pc.category_id WHERE pc.product_id = p.id AND c.name = \\$5 \\)"
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM products p "+where+"$").
+					WithArgs(1, "BRL", int64(50), int64(500), "Cat1").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
 
-				catRows2 := sqlmock.NewRows([]string{"name"}).AddRow("Cat2")
-				mock.ExpectQuery("SELECT c.name FROM categories c").
-					WithArgs(2).
-					WillReturnRows(catRows2)
+				rows := sqlmock.NewRows(productColumns).
+					AddRow(1, "00000000-0000-4000-8000-000000000001", "Product1", 1, 300, "BRL", testCreatedAt, nil, 1).
+					AddRow(2, "00000000-0000-4000-8000-000000000002", "Product2", 1, 200, "BRL", testCreatedAt, nil, 1)
+				mock.ExpectQuery("FROM products p "+where+" ORDER BY p.price DESC, p.public_id ASC LIMIT \\$6$").
+					WithArgs(1, "BRL", int64(50), int64(500), "Cat1", 2).
+					WillReturnRows(rows)
 
-				catRows3 := sqlmock.NewRows([]string{"name"}).AddRow("Cat3")
-				mock.ExpectQuery("SELECT c.name FROM categories c").
-					WithArgs(3).
-					WillReturnRows(catRows3)
+				mock.ExpectQuery("SELECT pc.product_id, c.name FROM product_categories pc").
+					WithArgs("{1}").
+					WillReturnRows(sqlmock.NewRows([]string{"product_id", "name"}).AddRow(1, "Cat1"))
 			},
-			expectedCount: 3,
-			expectedError: false,
+			expectedCount:  1,
+			expectedTotal:  2,
+			expectedCursor: true,
 		},
 		{
-			name: "find no products - empty database",
+			name: "category subtree",
+			criteria: product_repository.ProductCriteria{
+				Categories: []string{"Eletrônicos", "Computadores"},
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				where := "WHERE p.deleted_at IS NULL AND EXISTS \\( SELECT 1 FROM product_categories pc INNER JOIN categories c ON c.id = pc.category_id WHERE pc.product_id = p.id AND c.name = ANY\\(\\$1\\) \\)"
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM products p " + where + "$").
+					WithArgs(`{"Eletrônicos","Computadores"}`).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+				rows := sqlmock.NewRows(productColumns).
+					AddRow(1, "00000000-0000-4000-8000-000000000001", "Product1", 1, 300, "BRL", testCreatedAt, nil, 1)
+				mock.ExpectQuery("FROM products p " + where + " ORDER BY p.created_at DESC, p.public_id ASC$").
+					WithArgs(`{"Eletrônicos","Computadores"}`).
+					WillReturnRows(rows)
+
+				mock.ExpectQuery("SELECT pc.product_id, c.name FROM product_categories pc").
+					WithArgs("{1}").
+					WillReturnRows(sqlmock.NewRows([]string{"product_id", "name"}).AddRow(1, "Computadores"))
+			},
+			expectedCount: 1,
+			expectedTotal: 1,
+		},
+		{
+			name: "continue after cursor",
+			criteria: product_repository.ProductCriteria{
+				IncludeDeleted: true,
+				Sort:           product_repository.ProductSort{Field: product_repository.SortByName},
+				After:          &product_repository.ProductCursor{Value: "Product1", ID: "00000000-0000-4000-8000-000000000001"},
+			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"id", "name", "sku", "price"})
-				mock.ExpectQuery("SELECT DISTINCT p.id, p.name, p.sku, p.price FROM products p").
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM products p$").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+				rows := sqlmock.NewRows(productColumns).
+					AddRow(2, "00000000-0000-4000-8000-000000000002", "Product2", 2, 200, "BRL", testCreatedAt, nil, 1)
+				mock.ExpectQuery("FROM products p WHERE \\(p.name > \\$1 OR \\(p.name = \\$1 AND p.public_id > \\$2\\)\\) ORDER BY p.name ASC, p.public_id ASC$").
+					WithArgs("Product1", "00000000-0000-4000-8000-000000000001").
 					WillReturnRows(rows)
+
+				mock.ExpectQuery("SELECT pc.product_id, c.name FROM product_categories pc").
+					WithArgs("{2}").
+					WillReturnRows(sqlmock.NewRows([]string{"product_id", "name"}))
+			},
+			expectedCount: 1,
+			expectedTotal: 2,
+		},
+		{
+			name: "price filter without currency",
+			criteria: func() product_repository.ProductCriteria {
+				maxPrice := int64(500)
+				return product_repository.ProductCriteria{MaxPrice: &maxPrice}
+			}(),
+			expectedError: true,
+		},
+		{
+			name: "find no products - empty database",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM products p").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery("SELECT p.id, p.public_id, p.name, p.sku, p.price, p.currency, p.created_at, p.deleted_at, p.version FROM products p").
+					WillReturnRows(sqlmock.NewRows(productColumns))
 			},
 			expectedCount: 0,
 			expectedError: false,
@@ -206,7 +568,7 @@ func TestPostgresProductRepository_Find(t *testing.T) {
 		{
 			name: "database error on query",
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				mock.ExpectQuery("SELECT DISTINCT p.id, p.name, p.sku, p.price FROM products p").
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM products p").
 					WillReturnError(sql.ErrConnDone)
 			},
 			expectedCount: 0,
@@ -227,7 +589,7 @@ func TestPostgresProductRepository_Find(t *testing.T) {
 			}
 
 			repo := NewPostgresProductRepository(db)
-			products, err := repo.Find()
+			page, err := repo.Find(context.Background(), tt.criteria)
 
 			if tt.expectedError {
 				if err == nil {
@@ -237,8 +599,19 @@ func TestPostgresProductRepository_Find(t *testing.T) {
 				if err != nil {
 					t.Errorf("Unexpected error: %v", err)
 				}
-				if len(products) != tt.expectedCount {
-					t.Errorf("Expected %d products, got %d", tt.expectedCount, len(products))
+				if len(page.Products) != tt.expectedCount {
+					t.Errorf("Expected %d products, got %d", tt.expectedCount, len(page.Products))
+				}
+				if page.Total != tt.expectedTotal {
+					t.Errorf("Expected total %d, got %d", tt.expectedTotal, page.Total)
+				}
+				if (page.NextCursor != "") != tt.expectedCursor {
+					t.Errorf("NextCursor = %q, expected cursor %v", page.NextCursor, tt.expectedCursor)
+				}
+				for _, p := range page.Products {
+					if p.Sku == 1 && len(p.Categories) != 1 {
+						t.Errorf("Expected categories loaded for %s, got %v", p.Name, p.Categories)
+					}
 				}
 			}
 
@@ -249,6 +622,296 @@ func TestPostgresProductRepository_Find(t *testing.T) {
 	}
 }
 
+func TestPostgresProductRepository_Export(t *testing.T) {
+	columns := []string{"id", "public_id", "name", "sku", "price", "currency", "created_at", "deleted_at", "version", "categories"}
+
+	t.Run("reads the cursor in batches", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("Failed to create mock database: %v", err)
+		}
+		defer db.Close()
+
+		full := sqlmock.NewRows(columns)
+		for i := 1; i <= exportFetchSize; i++ {
+			full.AddRow(i, testPublicID, "Product", i, 100, "BRL", testCreatedAt, nil, 1, "{Books}")
+		}
+
+		mock.ExpectBegin()
+		mock.ExpectExec("DECLARE product_export NO SCROLL CURSOR FOR SELECT p.id, .*ARRAY\\( SELECT c.name .* FROM products p WHERE p.deleted_at IS NULL AND p.currency = \\$1 AND p.price >= \\$2 ORDER BY p.name ASC, p.public_id ASC$").
+			WithArgs("BRL", int64(50)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("FETCH FORWARD 500 FROM product_export").WillReturnRows(full)
+		mock.ExpectQuery("FETCH FORWARD 500 FROM product_export").
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(501, testPublicID, "Last", 501, 100, "BRL", testCreatedAt, nil, 1, `{"Home & Garden",Tools}`))
+		mock.ExpectCommit()
+
+		minPrice := int64(50)
+		criteria := product_repository.ProductCriteria{Currency: "BRL", MinPrice: &minPrice, Sort: product_repository.ProductSort{Field: product_repository.SortByName}, Limit: 10}
+
+		var products []product_entity.Product
+		err = NewPostgresProductRepository(db).Export(context.Background(), criteria, func(product product_entity.Product) error {
+			products = append(products, product)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Export() unexpected error = %v", err)
+		}
+		if len(products) != exportFetchSize+1 {
+			t.Fatalf("Export() delivered %d products, want %d", len(products), exportFetchSize+1)
+		}
+		if last := products[exportFetchSize]; last.Name != "Last" || strings.Join(last.Categories, ",") != "Home & Garden,Tools" || last.Price.Amount() != 100 {
+			t.Errorf("last product = %+v", last)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("Unfulfilled expectations: %v", err)
+		}
+	})
+
+	t.Run("fn error stops the export", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("Failed to create mock database: %v", err)
+		}
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectExec("DECLARE product_export").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("FETCH FORWARD").
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(1, testPublicID, "Notebook", 1, 100, "BRL", testCreatedAt, nil, 1, "{}").
+				AddRow(2, testPublicID, "Mouse", 2, 100, "BRL", testCreatedAt, nil, 1, "{}"))
+		mock.ExpectRollback()
+
+		stop := errors.New("client gone")
+		calls := 0
+		err = NewPostgresProductRepository(db).Export(context.Background(), product_repository.ProductCriteria{}, func(product_entity.Product) error {
+			calls++
+			return stop
+		})
+		if !errors.Is(err, stop) || calls != 1 {
+			t.Errorf("Export() = %v after %d calls, want the fn error after 1", err, calls)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("Unfulfilled expectations: %v", err)
+		}
+	})
+
+	t.Run("failure to open the cursor", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("Failed to create mock database: %v", err)
+		}
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectExec("DECLARE product_export").WillReturnError(&pq.Error{Code: "57P01"})
+		mock.ExpectRollback()
+
+		err = NewPostgresProductRepository(db).Export(context.Background(), product_repository.ProductCriteria{}, func(product_entity.Product) error { return nil })
+		if !errors.Is(err, product_errors.ErrUnavailable) {
+			t.Errorf("Export() error = %v, want ErrUnavailable", err)
+		}
+	})
+}
+
+func TestPostgresProductRepository_Search(t *testing.T) {
+	t.Run("builds an unaccented prefix tsquery", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("Failed to create mock database: %v", err)
+		}
+		defer db.Close()
+
+		rows := sqlmock.NewRows([]string{"id", "public_id", "name", "sku", "price", "currency", "created_at", "deleted_at", "version"}).
+			AddRow(1, "00000000-0000-4000-8000-000000000001", "Notebook", 1, 100, "BRL", testCreatedAt, nil, 1)
+		mock.ExpectQuery("WHERE p.deleted_at IS NULL AND p.search_vector @@ to_tsquery\\('simple', \\$1\\) ORDER BY ts_rank\\(p.search_vector, to_tsquery\\('simple', \\$1\\)\\) DESC, p.name ASC LIMIT \\$2$").
+			WithArgs("note:* & eletronicos:*", 10).
+			WillReturnRows(rows)
+		mock.ExpectQuery("SELECT pc.product_id, c.name FROM product_categories pc").
+			WithArgs("{1}").
+			WillReturnRows(sqlmock.NewRows([]string{"product_id", "name"}).AddRow(1, "Eletrônicos"))
+
+		repo := NewPostgresProductRepository(db)
+		products, err := repo.Search(context.Background(), "Note Eletrônicos", 10)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(products) != 1 || len(products[0].Categories) != 1 {
+			t.Errorf("Expected 1 product with categories, got %+v", products)
+		}
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("Unfulfilled expectations: %v", err)
+		}
+	})
+
+	t.Run("query without terms does not hit the database", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("Failed to create mock database: %v", err)
+		}
+		defer db.Close()
+
+		repo := NewPostgresProductRepository(db)
+		products, err := repo.Search(context.Background(), "?!", 10)
+		if err != nil || len(products) != 0 {
+			t.Errorf("Expected no products and no error, got %v, %v", products, err)
+		}
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("Unfulfilled expectations: %v", err)
+		}
+	})
+}
+
+func TestPostgresProductRepository_Reindex(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	batch := "SELECT COUNT\\(\\*\\), MAX\\(batch.id\\) FROM \\(SELECT id FROM products WHERE id > \\$1 ORDER BY id LIMIT \\$2\\) batch CROSS JOIN LATERAL refresh_product_search_vector\\(batch.id\\)"
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM products$").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectQuery(batch).WithArgs(int64(0), 2).WillReturnRows(sqlmock.NewRows([]string{"count", "max"}).AddRow(2, 7))
+	// Um produto criado durante a reindexação entra no último lote
+	mock.ExpectQuery(batch).WithArgs(int64(7), 2).WillReturnRows(sqlmock.NewRows([]string{"count", "max"}).AddRow(2, 12))
+	mock.ExpectQuery(batch).WithArgs(int64(12), 2).WillReturnRows(sqlmock.NewRows([]string{"count", "max"}).AddRow(0, nil))
+
+	var reports []string
+	done, err := NewPostgresProductRepository(db).Reindex(context.Background(), 2, func(done, total int) {
+		reports = append(reports, fmt.Sprintf("%d/%d", done, total))
+	})
+	if err != nil || done != 4 {
+		t.Fatalf("Reindex() = %d, %v; want 4", done, err)
+	}
+	if strings.Join(reports, ",") != "2/3,4/4" {
+		t.Errorf("progress = %v, want 2/3,4/4", reports)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+// A reindexação só grava search_vector; a versão vigente do trigger set_products_change_seq,
+// a da última migration que o cria, não pode disparar nesse UPDATE, senão todos os produtos
+// reapareceriam no change feed
+func TestPostgresProductRepository_ReindexDoesNotAdvanceChanges(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("..", "..", "..", "db", "migrations", "V*__*.sql"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("migrations not found: %v", err)
+	}
+	version := func(file string) int {
+		n, _ := strconv.Atoi(strings.TrimPrefix(strings.SplitN(filepath.Base(file), "__", 2)[0], "V"))
+		return n
+	}
+	sort.Slice(files, func(i, j int) bool { return version(files[i]) < version(files[j]) })
+
+	trigger := regexp.MustCompile(`(?s)CREATE TRIGGER set_products_change_seq (.+?) ON products`)
+	var events, migration string
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("ReadFile(%s) error = %v", file, err)
+		}
+		if match := trigger.FindSubmatch(content); match != nil {
+			events, migration = string(match[1]), filepath.Base(file)
+		}
+	}
+
+	columns := regexp.MustCompile(`UPDATE OF ([a-z_, ]+)$`).FindStringSubmatch(events)
+	if columns == nil {
+		t.Fatalf("%s: set_products_change_seq fires on %q, want UPDATE OF a column list", migration, events)
+	}
+	written := map[string]bool{}
+	for _, column := range strings.Split(columns[1], ",") {
+		written[strings.TrimSpace(column)] = true
+	}
+	if written["search_vector"] {
+		t.Errorf("%s: set_products_change_seq fires on search_vector updates", migration)
+	}
+	// Toda escrita do produto incrementa version e precisa continuar no feed
+	for _, column := range []string{"name", "sku", "price", "currency", "deleted_at", "version"} {
+		if !written[column] {
+			t.Errorf("%s: set_products_change_seq does not fire on %s updates", migration, column)
+		}
+	}
+}
+
+func TestPostgresProductRepository_Changes(t *testing.T) {
+	columns := []string{"id", "public_id", "name", "sku", "price", "currency", "created_at", "deleted_at", "version", "change_seq", "created_seq"}
+
+	t.Run("classifies changes and fetches one extra row", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("Failed to create mock database: %v", err)
+		}
+		defer db.Close()
+
+		rows := sqlmock.NewRows(columns).
+			AddRow(1, "00000000-0000-4000-8000-000000000001", "Notebook", 1, 100, "BRL", testCreatedAt, nil, 1, 11, 3).
+			AddRow(2, "00000000-0000-4000-8000-000000000002", "Mouse", 2, 50, "BRL", testCreatedAt, testCreatedAt, 1, 12, 4).
+			AddRow(3, "00000000-0000-4000-8000-000000000003", "Keyboard", 3, 80, "BRL", testCreatedAt, nil, 1, 13, 13)
+		mock.ExpectQuery("WHERE p.change_seq > \\$1 ORDER BY p.change_seq ASC LIMIT \\$2$").
+			WithArgs(10, 3).
+			WillReturnRows(rows)
+		mock.ExpectQuery("SELECT pc.product_id, c.name FROM product_categories pc").
+			WithArgs("{1,2,3}").
+			WillReturnRows(sqlmock.NewRows([]string{"product_id", "name"}).AddRow(1, "Eletrônicos"))
+
+		repo := NewPostgresProductRepository(db)
+		page, err := repo.Changes(context.Background(), 10, 2)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if len(page.Changes) != 2 || !page.HasMore {
+			t.Fatalf("Expected 2 changes and more pages, got %d (more %v)", len(page.Changes), page.HasMore)
+		}
+		if change := page.Changes[0]; change.Type != product_repository.ChangeUpdated || change.Sequence != 11 || len(change.Product.Categories) != 1 {
+			t.Errorf("Unexpected first change: %+v", change)
+		}
+		if change := page.Changes[1]; change.Type != product_repository.ChangeDeleted || !change.Product.IsDeleted() {
+			t.Errorf("Unexpected second change: %+v", change)
+		}
+		if next, _ := product_repository.DecodeChangeToken(page.NextToken); next.Sequence != 12 {
+			t.Errorf("Expected next token at 12, got %d", next.Sequence)
+		}
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("Unfulfilled expectations: %v", err)
+		}
+	})
+
+	t.Run("without changes keeps the position", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("Failed to create mock database: %v", err)
+		}
+		defer db.Close()
+
+		mock.ExpectQuery("WHERE p.change_seq > \\$1 ORDER BY p.change_seq ASC$").
+			WithArgs(7).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		repo := NewPostgresProductRepository(db)
+		page, err := repo.Changes(context.Background(), 7, 0)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		next, _ := product_repository.DecodeChangeToken(page.NextToken)
+		if len(page.Changes) != 0 || page.HasMore || next.Sequence != 7 {
+			t.Errorf("Expected empty page at 7, got %+v", page)
+		}
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("Unfulfilled expectations: %v", err)
+		}
+	})
+}
+
 func TestPostgresProductRepository_FindOne(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -261,9 +924,9 @@ func TestPostgresProductRepository_FindOne(t *testing.T) {
 			name:        "find existing product",
 			productName: "Notebook",
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"id", "name", "sku", "price"}).
-					AddRow(1, "Notebook", 12345, 3500)
-				mock.ExpectQuery("SELECT id, name, sku, price FROM products WHERE name").
+				rows := sqlmock.NewRows([]string{"id", "public_id", "name", "sku", "price", "currency", "created_at", "deleted_at", "version"}).
+					AddRow(1, "00000000-0000-4000-8000-000000000001", "Notebook", 12345, 3500, "BRL", testCreatedAt, nil, 3)
+				mock.ExpectQuery("SELECT id, public_id, name, sku, price, currency, created_at, deleted_at, version FROM products WHERE name = \\$1 AND deleted_at IS NULL").
 					WithArgs("Notebook").
 					WillReturnRows(rows)
 
@@ -276,25 +939,31 @@ func TestPostgresProductRepository_FindOne(t *testing.T) {
 			},
 			expectedError: false,
 			checkProduct: func(t *testing.T, p product_entity.Product) {
+				if p.ID != "00000000-0000-4000-8000-000000000001" {
+					t.Errorf("Expected ID 00000000-0000-4000-8000-000000000001, got %s", p.ID)
+				}
 				if p.Name != "Notebook" {
 					t.Errorf("Expected name Notebook, got %s", p.Name)
 				}
 				if p.Sku != 12345 {
 					t.Errorf("Expected SKU 12345, got %d", p.Sku)
 				}
-				if p.Price != 3500 {
-					t.Errorf("Expected price 3500, got %d", p.Price)
+				if p.Price != product_valueobject.MustBRL(3500) {
+					t.Errorf("Expected price BRL 35.00, got %s", p.Price)
 				}
 				if len(p.Categories) != 2 {
 					t.Errorf("Expected 2 categories, got %d", len(p.Categories))
 				}
+				if p.Version != 3 {
+					t.Errorf("Expected version 3, got %d", p.Version)
+				}
 			},
 		},
 		{
 			name:        "product not found",
 			productName: "NonExistent",
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				mock.ExpectQuery("SELECT id, name, sku, price FROM products WHERE name").
+				mock.ExpectQuery("SELECT id, public_id, name, sku, price, currency, created_at, deleted_at, version FROM products WHERE name = \\$1 AND deleted_at IS NULL").
 					WithArgs("NonExistent").
 					WillReturnError(sql.ErrNoRows)
 			},
@@ -304,7 +973,7 @@ func TestPostgresProductRepository_FindOne(t *testing.T) {
 			name:        "database error",
 			productName: "Test",
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				mock.ExpectQuery("SELECT id, name, sku, price FROM products WHERE name").
+				mock.ExpectQuery("SELECT id, public_id, name, sku, price, currency, created_at, deleted_at, version FROM products WHERE name = \\$1 AND deleted_at IS NULL").
 					WithArgs("Test").
 					WillReturnError(sql.ErrConnDone)
 			},
@@ -325,7 +994,7 @@ func TestPostgresProductRepository_FindOne(t *testing.T) {
 			}
 
 			repo := NewPostgresProductRepository(db)
-			product, err := repo.FindOne(tt.productName)
+			product, err := repo.FindOne(context.Background(), tt.productName, false)
 
 			if tt.expectedError {
 				if err == nil {
@@ -347,6 +1016,466 @@ func TestPostgresProductRepository_FindOne(t *testing.T) {
 	}
 }
 
+func TestPostgresProductRepository_Update(t *testing.T) {
+	tests := []struct {
+		name          string
+		updateName    string
+		product       product_entity.Product
+		mockSetup     func(sqlmock.Sqlmock)
+		expectedError bool
+		expectedMsg   string
+	}{
+		{
+			name:       "update product successfully",
+			updateName: "Notebook",
+			product: product_entity.Product{
+				Name:       "Notebook Pro",
+				Sku:        12345,
+				Categories: []string{"Electronics", "Computers"},
+				Price:      product_valueobject.MustBRL(4500),
+				Version:    3,
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+
+				rows := sqlmock.NewRows([]string{"id"}).AddRow(1)
+				mock.ExpectQuery("UPDATE products SET name = \\$1, sku = \\$2, price = \\$3, currency = \\$4, version = version \\+ 1 WHERE name = \\$5 AND deleted_at IS NULL AND version = \\$6").
+					WithArgs("Notebook Pro", 12345, int64(4500), "BRL", "Notebook", 2).
+					WillReturnRows(rows)
+
+				mock.ExpectExec("DELETE FROM product_categories").
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+
+				catRows1 := sqlmock.NewRows([]string{"id"}).AddRow(1)
+				mock.ExpectQuery("INSERT INTO categories").
+					WithArgs("Electronics").
+					WillReturnRows(catRows1)
+				mock.ExpectExec("INSERT INTO product_categories").
+					WithArgs(1, 1).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+
+				catRows2 := sqlmock.NewRows([]string{"id"}).AddRow(2)
+				mock.ExpectQuery("INSERT INTO categories").
+					WithArgs("Computers").
+					WillReturnRows(catRows2)
+				mock.ExpectExec("INSERT INTO product_categories").
+					WithArgs(1, 2).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+
+				mock.ExpectCommit()
+			},
+			expectedError: false,
+		},
+		{
+			name:       "product not found",
+			updateName: "NonExistent",
+			product: product_entity.Product{
+				Name:       "NonExistent",
+				Sku:        1,
+				Categories: []string{"Test"},
+				Price:      product_valueobject.MustBRL(100),
+				Version:    2,
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("UPDATE products SET name").
+					WithArgs("NonExistent", 1, int64(100), "BRL", "NonExistent", 1).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery("SELECT EXISTS \\(SELECT 1 FROM products WHERE name = \\$1 AND deleted_at IS NULL\\)").
+					WithArgs("NonExistent").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+				mock.ExpectRollback()
+			},
+			expectedError: true,
+			expectedMsg:   "product not found",
+		},
+		{
+			name:       "stale version",
+			updateName: "Notebook",
+			product: product_entity.Product{
+				Name:       "Notebook",
+				Sku:        12345,
+				Categories: []string{"Electronics"},
+				Price:      product_valueobject.MustBRL(4500),
+				Version:    2,
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("UPDATE products SET name").
+					WithArgs("Notebook", 12345, int64(4500), "BRL", "Notebook", 1).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery("SELECT EXISTS").
+					WithArgs("Notebook").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectRollback()
+			},
+			expectedError: true,
+			expectedMsg:   "product was modified by another request",
+		},
+		{
+			name:       "invalid product is rejected before touching the database",
+			updateName: "Notebook",
+			product: product_entity.Product{
+				Name:       "Notebook",
+				Sku:        12345,
+				Categories: []string{},
+				Price:      product_valueobject.MustBRL(4500),
+			},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: true,
+			expectedMsg:   "categories is required",
+		},
+		{
+			name:       "database error on delete categories",
+			updateName: "Notebook",
+			product: product_entity.Product{
+				Name:       "Notebook",
+				Sku:        12345,
+				Categories: []string{"Electronics"},
+				Price:      product_valueobject.MustBRL(4500),
+				Version:    2,
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				rows := sqlmock.NewRows([]string{"id"}).AddRow(1)
+				mock.ExpectQuery("UPDATE products SET name").
+					WithArgs("Notebook", 12345, int64(4500), "BRL", "Notebook", 1).
+					WillReturnRows(rows)
+				mock.ExpectExec("DELETE FROM product_categories").
+					WithArgs(1).
+					WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Failed to create mock database: %v", err)
+			}
+			defer db.Close()
+
+			if tt.mockSetup != nil {
+				tt.mockSetup(mock)
+			}
+
+			repo := NewPostgresProductRepository(db)
+			err = repo.Update(context.Background(), tt.updateName, withPendingChange(tt.product))
+
+			if tt.expectedError {
+				if err == nil {
+					t.Error("Expected error, got nil")
+				} else if tt.expectedMsg != "" && err.Error() != tt.expectedMsg {
+					t.Errorf("Expected error %q, got %q", tt.expectedMsg, err.Error())
+				}
+			} else {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestPostgresProductRepository_FindByIDAndSku(t *testing.T) {
+	tests := []struct {
+		name          string
+		find          func(*PostgresProductRepository) (product_entity.Product, error)
+		mockSetup     func(sqlmock.Sqlmock)
+		expectedError bool
+	}{
+		{
+			name: "find by id",
+			find: func(r *PostgresProductRepository) (product_entity.Product, error) {
+				return r.FindByID(context.Background(), "3f2b8c1e-4d5a-4b6c-8d7e-9f0a1b2c3d4e", false)
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "public_id", "name", "sku", "price", "currency", "created_at", "deleted_at", "version"}).
+					AddRow(7, "3f2b8c1e-4d5a-4b6c-8d7e-9f0a1b2c3d4e", "Notebook/15\"", 12345, 3500, "BRL", testCreatedAt, nil, 1)
+				mock.ExpectQuery("SELECT id, public_id, name, sku, price, currency, created_at, deleted_at, version FROM products WHERE public_id = \\$1 AND deleted_at IS NULL").
+					WithArgs("3f2b8c1e-4d5a-4b6c-8d7e-9f0a1b2c3d4e").
+					WillReturnRows(rows)
+				mock.ExpectQuery("SELECT c.name FROM categories c").
+					WithArgs(7).
+					WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("Electronics"))
+			},
+		},
+		{
+			name: "find by sku",
+			find: func(r *PostgresProductRepository) (product_entity.Product, error) {
+				return r.FindBySku(context.Background(), 12345, false)
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "public_id", "name", "sku", "price", "currency", "created_at", "deleted_at", "version"}).
+					AddRow(7, "3f2b8c1e-4d5a-4b6c-8d7e-9f0a1b2c3d4e", "Notebook/15\"", 12345, 3500, "BRL", testCreatedAt, nil, 1)
+				mock.ExpectQuery("SELECT id, public_id, name, sku, price, currency, created_at, deleted_at, version FROM products WHERE sku = \\$1 AND deleted_at IS NULL").
+					WithArgs(12345).
+					WillReturnRows(rows)
+				mock.ExpectQuery("SELECT c.name FROM categories c").
+					WithArgs(7).
+					WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("Electronics"))
+			},
+		},
+		{
+			name: "find by unknown sku",
+			find: func(r *PostgresProductRepository) (product_entity.Product, error) {
+				return r.FindBySku(context.Background(), 999, false)
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT id, public_id, name, sku, price, currency, created_at, deleted_at, version FROM products WHERE sku").
+					WithArgs(999).
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Failed to create mock database: %v", err)
+			}
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			product, err := tt.find(NewPostgresProductRepository(db))
+
+			if tt.expectedError {
+				if err == nil || err.Error() != "product not found" {
+					t.Errorf("Expected 'product not found', got %v", err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if product.ID != "3f2b8c1e-4d5a-4b6c-8d7e-9f0a1b2c3d4e" || product.Name != "Notebook/15\"" {
+					t.Errorf("Unexpected product: %+v", product)
+				}
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestPostgresProductRepository_FindIncludingDeleted(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	deletedAt := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	rows := sqlmock.NewRows([]string{"id", "public_id", "name", "sku", "price", "currency", "created_at", "deleted_at", "version"}).
+		AddRow(1, "00000000-0000-4000-8000-000000000001", "Active", 1, 100, "BRL", testCreatedAt, nil, 1).
+		AddRow(2, "00000000-0000-4000-8000-000000000002", "Deleted", 2, 200, "BRL", testCreatedAt, deletedAt, 1)
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM products p$").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery("SELECT p.id, p.public_id, p.name, p.sku, p.price, p.currency, p.created_at, p.deleted_at, p.version FROM products p ORDER BY").
+		WillReturnRows(rows)
+	mock.ExpectQuery("SELECT pc.product_id, c.name FROM product_categories pc").
+		WithArgs("{1,2}").
+		WillReturnRows(sqlmock.NewRows([]string{"product_id", "name"}).AddRow(1, "Cat1").AddRow(2, "Cat2"))
+
+	mock.ExpectQuery("SELECT id, public_id, name, sku, price, currency, created_at, deleted_at, version FROM products WHERE name = \\$1\\s*$").
+		WithArgs("Deleted").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "public_id", "name", "sku", "price", "currency", "created_at", "deleted_at", "version"}).
+			AddRow(2, "00000000-0000-4000-8000-000000000002", "Deleted", 2, 200, "BRL", testCreatedAt, deletedAt, 1))
+	mock.ExpectQuery("SELECT c.name FROM categories c").
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("Cat2"))
+
+	repo := NewPostgresProductRepository(db)
+
+	page, err := repo.Find(context.Background(), product_repository.ProductCriteria{IncludeDeleted: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	products := page.Products
+	if len(products) != 2 {
+		t.Fatalf("Expected 2 products, got %d", len(products))
+	}
+	if products[0].IsDeleted() {
+		t.Error("Expected first product to be active")
+	}
+	if !products[1].IsDeleted() || !products[1].DeletedAt.Equal(deletedAt) {
+		t.Errorf("Expected second product deleted at %v, got %v", deletedAt, products[1].DeletedAt)
+	}
+
+	product, err := repo.FindOne(context.Background(), "Deleted", true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !product.IsDeleted() {
+		t.Error("Expected product to be deleted")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestPostgresProductRepository_DeleteAndRestore(t *testing.T) {
+	tests := []struct {
+		name        string
+		call        func(*PostgresProductRepository) error
+		mockSetup   func(sqlmock.Sqlmock)
+		expectedErr string
+	}{
+		{
+			name: "delete active product",
+			call: func(r *PostgresProductRepository) error { return r.Delete(context.Background(), "Notebook", 1) },
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE products SET deleted_at = CURRENT_TIMESTAMP, version = version \\+ 1 WHERE name = \\$1 AND deleted_at IS NULL AND version = \\$2").
+					WithArgs("Notebook", 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "delete missing or already deleted product",
+			call: func(r *PostgresProductRepository) error { return r.Delete(context.Background(), "Notebook", 1) },
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE products SET deleted_at = CURRENT_TIMESTAMP").
+					WithArgs("Notebook", 1).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery("SELECT EXISTS \\(SELECT 1 FROM products WHERE name = \\$1 AND deleted_at IS NULL\\)").
+					WithArgs("Notebook").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+				mock.ExpectRollback()
+			},
+			expectedErr: "product not found",
+		},
+		{
+			name: "delete with stale version",
+			call: func(r *PostgresProductRepository) error { return r.Delete(context.Background(), "Notebook", 1) },
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE products SET deleted_at = CURRENT_TIMESTAMP").
+					WithArgs("Notebook", 1).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery("SELECT EXISTS").
+					WithArgs("Notebook").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectRollback()
+			},
+			expectedErr: "product was modified by another request",
+		},
+		{
+			name: "delete with database error",
+			call: func(r *PostgresProductRepository) error { return r.Delete(context.Background(), "Notebook", 1) },
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE products SET deleted_at = CURRENT_TIMESTAMP").
+					WithArgs("Notebook", 1).
+					WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
+			},
+			expectedErr: "erro ao excluir produto: product storage unavailable: sql: connection is already closed",
+		},
+		{
+			name: "restore deleted product",
+			call: func(r *PostgresProductRepository) error { return r.Restore(context.Background(), "Notebook", 2) },
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE products SET deleted_at = NULL, version = version \\+ 1 WHERE name = \\$1 AND deleted_at IS NOT NULL AND version = \\$2").
+					WithArgs("Notebook", 2).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "restore product that is not deleted",
+			call: func(r *PostgresProductRepository) error { return r.Restore(context.Background(), "Notebook", 2) },
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE products SET deleted_at = NULL").
+					WithArgs("Notebook", 2).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery("SELECT EXISTS \\(SELECT 1 FROM products WHERE name = \\$1 AND deleted_at IS NOT NULL\\)").
+					WithArgs("Notebook").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+				mock.ExpectRollback()
+			},
+			expectedErr: "product not found",
+		},
+		{
+			name: "delete writes event to outbox",
+			call: func(r *PostgresProductRepository) error {
+				return r.Delete(context.Background(), "Notebook", 1, product_events.NewProductDeletedEvent(testPublicID, "Notebook", 12345, testCreatedAt))
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE products SET deleted_at = CURRENT_TIMESTAMP").
+					WithArgs("Notebook", 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO outbox \\(event_id, aggregate_id, event_name, event_version, correlation_id, occurred_at, payload\\)").
+					WithArgs(sqlmock.AnyArg(), testPublicID, "product.deleted", 1, nil, sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "restore not deleted product skips outbox",
+			call: func(r *PostgresProductRepository) error {
+				return r.Restore(context.Background(), "Notebook", 2, product_events.NewProductRestoredEvent(testPublicID, "Notebook", 12345))
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE products SET deleted_at = NULL").
+					WithArgs("Notebook", 2).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery("SELECT EXISTS \\(SELECT 1 FROM products WHERE name = \\$1 AND deleted_at IS NOT NULL\\)").
+					WithArgs("Notebook").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+				mock.ExpectRollback()
+			},
+			expectedErr: "product not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Failed to create mock database: %v", err)
+			}
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			err = tt.call(NewPostgresProductRepository(db))
+
+			if tt.expectedErr != "" {
+				if err == nil || err.Error() != tt.expectedErr {
+					t.Errorf("Expected error %q, got %v", tt.expectedErr, err)
+				}
+			} else if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
 func TestPostgresProductRepository_GetMetrics(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -359,12 +1488,14 @@ func TestPostgresProductRepository_GetMetrics(t *testing.T) {
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				// Total products
 				countRows := sqlmock.NewRows([]string{"count"}).AddRow(10)
-				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM products").
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM products WHERE deleted_at IS NULL").
 					WillReturnRows(countRows)
 
-				// Total value and Average price
-				sumAvgRows := sqlmock.NewRows([]string{"sum", "avg"}).AddRow(5000, 500.0)
-				mock.ExpectQuery("SELECT SUM\\(price\\), AVG\\(price\\) FROM products").
+				// Total value and Average price per currency
+				sumAvgRows := sqlmock.NewRows([]string{"currency", "sum", "avg"}).
+					AddRow("BRL", 5000, 500.0).
+					AddRow("USD", 300, 150.0)
+				mock.ExpectQuery("SELECT currency, SUM\\(price\\), AVG\\(price\\) FROM products WHERE deleted_at IS NULL GROUP BY currency").
 					WillReturnRows(sumAvgRows)
 
 				// Products by category
@@ -372,7 +1503,7 @@ func TestPostgresProductRepository_GetMetrics(t *testing.T) {
 					AddRow("Electronics", 5).
 					AddRow("Books", 3).
 					AddRow("Toys", 2)
-				mock.ExpectQuery("SELECT c.name, COUNT\\(pc.product_id\\)").
+				mock.ExpectQuery("SELECT c.name, COUNT\\(p.id\\)").
 					WillReturnRows(catRows)
 			},
 			expectedError: false,
@@ -380,11 +1511,14 @@ func TestPostgresProductRepository_GetMetrics(t *testing.T) {
 				if m.TotalProducts != 10 {
 					t.Errorf("Expected TotalProducts 10, got %d", m.TotalProducts)
 				}
-				if m.TotalValue != 5000.0 {
-					t.Errorf("Expected TotalValue 5000.0, got %f", m.TotalValue)
+				if m.TotalValue["BRL"] != 5000.0 {
+					t.Errorf("Expected TotalValue[BRL] 5000.0, got %f", m.TotalValue["BRL"])
+				}
+				if m.AveragePrice["BRL"] != 500.0 {
+					t.Errorf("Expected AveragePrice[BRL] 500.0, got %f", m.AveragePrice["BRL"])
 				}
-				if m.AveragePrice != 500.0 {
-					t.Errorf("Expected AveragePrice 500.0, got %f", m.AveragePrice)
+				if m.TotalValue["USD"] != 300.0 {
+					t.Errorf("Expected TotalValue[USD] 300.0, got %f", m.TotalValue["USD"])
 				}
 				if len(m.ProductsByCategory) != 3 {
 					t.Errorf("Expected 3 categories, got %d", len(m.ProductsByCategory))
@@ -398,15 +1532,15 @@ func TestPostgresProductRepository_GetMetrics(t *testing.T) {
 			name: "empty database metrics",
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				countRows := sqlmock.NewRows([]string{"count"}).AddRow(0)
-				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM products").
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM products WHERE deleted_at IS NULL").
 					WillReturnRows(countRows)
 
-				sumAvgRows := sqlmock.NewRows([]string{"sum", "avg"}).AddRow(nil, 0.0)
-				mock.ExpectQuery("SELECT SUM\\(price\\), AVG\\(price\\) FROM products").
+				sumAvgRows := sqlmock.NewRows([]string{"currency", "sum", "avg"})
+				mock.ExpectQuery("SELECT currency, SUM\\(price\\), AVG\\(price\\) FROM products WHERE deleted_at IS NULL GROUP BY currency").
 					WillReturnRows(sumAvgRows)
 
 				catRows := sqlmock.NewRows([]string{"name", "count"})
-				mock.ExpectQuery("SELECT c.name, COUNT\\(pc.product_id\\)").
+				mock.ExpectQuery("SELECT c.name, COUNT\\(p.id\\)").
 					WillReturnRows(catRows)
 			},
 			expectedError: false,
@@ -414,18 +1548,18 @@ func TestPostgresProductRepository_GetMetrics(t *testing.T) {
 				if m.TotalProducts != 0 {
 					t.Errorf("Expected TotalProducts 0, got %d", m.TotalProducts)
 				}
-				if m.TotalValue != 0.0 {
-					t.Errorf("Expected TotalValue 0.0, got %f", m.TotalValue)
+				if len(m.TotalValue) != 0 {
+					t.Errorf("Expected no TotalValue entries, got %v", m.TotalValue)
 				}
-				if m.AveragePrice != 0.0 {
-					t.Errorf("Expected AveragePrice 0.0, got %f", m.AveragePrice)
+				if len(m.AveragePrice) != 0 {
+					t.Errorf("Expected no AveragePrice entries, got %v", m.AveragePrice)
 				}
 			},
 		},
 		{
 			name: "database error on count query",
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM products").
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM products WHERE deleted_at IS NULL").
 					WillReturnError(sql.ErrConnDone)
 			},
 			expectedError: false, // GetMetrics não retorna erro, apenas valores zerados
@@ -450,7 +1584,7 @@ func TestPostgresProductRepository_GetMetrics(t *testing.T) {
 			}
 
 			repo := NewPostgresProductRepository(db)
-			metrics := repo.GetMetrics()
+			metrics := repo.GetMetrics(context.Background())
 
 			if tt.checkMetrics != nil {
 				tt.checkMetrics(t, metrics)
@@ -478,7 +1612,7 @@ func BenchmarkPostgresProductRepository_Add(b *testing.B) {
 		Name:       "BenchProduct",
 		Sku:        99999,
 		Categories: []string{"Bench"},
-		Price:      1000,
+		Price:      product_valueobject.MustBRL(1000),
 	}
 
 	// Setup expectations for each iteration
@@ -497,7 +1631,7 @@ func BenchmarkPostgresProductRepository_Add(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_ = repo.Add(product)
+		_ = repo.Add(context.Background(), product)
 	}
 }
 
@@ -509,19 +1643,20 @@ func BenchmarkPostgresProductRepository_Find(b *testing.B) {
 	defer db.Close()
 
 	for i := 0; i < b.N; i++ {
-		rows := sqlmock.NewRows([]string{"name", "sku", "price"}).
-			AddRow("Product1", 1, 100)
-		mock.ExpectQuery("SELECT DISTINCT p.name, p.sku, p.price FROM products p").
+		mock.ExpectQuery("SELECT COUNT").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		rows := sqlmock.NewRows([]string{"id", "public_id", "name", "sku", "price", "currency", "created_at", "deleted_at", "version"}).
+			AddRow(1, "00000000-0000-4000-8000-000000000001", "Product1", 1, 100, "BRL", testCreatedAt, nil, 1)
+		mock.ExpectQuery("SELECT p.id, p.public_id, p.name, p.sku, p.price, p.currency, p.created_at, p.deleted_at, p.version FROM products p").
 			WillReturnRows(rows)
-		catRows := sqlmock.NewRows([]string{"name"}).AddRow("Cat1")
-		mock.ExpectQuery("SELECT c.name FROM categories c").WillReturnRows(catRows)
+		catRows := sqlmock.NewRows([]string{"product_id", "name"}).AddRow(1, "Cat1")
+		mock.ExpectQuery("SELECT pc.product_id, c.name FROM product_categories pc").WillReturnRows(catRows)
 	}
 
 	repo := NewPostgresProductRepository(db)
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, _ = repo.Find()
+		_, _ = repo.Find(context.Background(), product_repository.ProductCriteria{})
 	}
 }
 
@@ -550,6 +1685,6 @@ func BenchmarkPostgresProductRepository_GetMetrics(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_ = repo.GetMetrics()
+		_ = repo.GetMetrics(context.Background())
 	}
 }
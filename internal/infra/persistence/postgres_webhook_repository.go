@@ -0,0 +1,240 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	webhook_entity "github.com/williamkoller/golang-domain-driven-design/internal/domain/webhook/entity"
+	webhook_repository "github.com/williamkoller/golang-domain-driven-design/internal/domain/webhook/repository"
+)
+
+// PostgresWebhookRepository guarda as inscrições na tabela webhooks e o log de
+// entregas em webhook_deliveries
+type PostgresWebhookRepository struct {
+	db       *sql.DB
+	timeouts QueryTimeouts
+}
+
+func NewPostgresWebhookRepository(db *sql.DB) *PostgresWebhookRepository {
+	return NewPostgresWebhookRepositoryWithTimeouts(db, DefaultQueryTimeouts())
+}
+
+// NewPostgresWebhookRepositoryWithTimeouts cria o repositório com timeouts próprios
+func NewPostgresWebhookRepositoryWithTimeouts(db *sql.DB, timeouts QueryTimeouts) *PostgresWebhookRepository {
+	return &PostgresWebhookRepository{db: db, timeouts: timeouts}
+}
+
+func (r *PostgresWebhookRepository) Add(ctx context.Context, webhook webhook_entity.Webhook) error {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Write)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO webhooks (id, url, events, secret, active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, webhook.ID, webhook.URL, pq.Array(webhook.Events), webhook.Secret, webhook.Active, webhook.CreatedAt, webhook.UpdatedAt)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return webhook_repository.ErrWebhookAlreadyExists
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao inserir webhook: %w", translateWebhookError(err))
+	}
+
+	return nil
+}
+
+func (r *PostgresWebhookRepository) FindAll(ctx context.Context) ([]webhook_entity.Webhook, error) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, url, events, secret, active, created_at, updated_at
+		FROM webhooks
+		ORDER BY created_at, id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar webhooks: %w", translateWebhookError(err))
+	}
+	defer rows.Close()
+
+	webhooks := []webhook_entity.Webhook{}
+	for rows.Next() {
+		var webhook webhook_entity.Webhook
+		if err := scanWebhook(rows, &webhook); err != nil {
+			return nil, fmt.Errorf("erro ao escanear webhook: %w", err)
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao ler webhooks: %w", translateWebhookError(err))
+	}
+
+	return webhooks, nil
+}
+
+func (r *PostgresWebhookRepository) FindByID(ctx context.Context, id string) (webhook_entity.Webhook, error) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+
+	var webhook webhook_entity.Webhook
+
+	err := scanWebhook(r.db.QueryRowContext(ctx, `
+		SELECT id, url, events, secret, active, created_at, updated_at
+		FROM webhooks
+		WHERE id = $1
+	`, id), &webhook)
+
+	if err == sql.ErrNoRows {
+		return webhook_entity.Webhook{}, webhook_repository.ErrWebhookNotFound
+	}
+	if err != nil {
+		return webhook_entity.Webhook{}, fmt.Errorf("erro ao buscar webhook: %w", translateWebhookError(err))
+	}
+
+	return webhook, nil
+}
+
+func (r *PostgresWebhookRepository) Update(ctx context.Context, webhook webhook_entity.Webhook) error {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Write)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE webhooks
+		SET url = $1, events = $2, secret = $3, active = $4, updated_at = $5
+		WHERE id = $6
+	`, webhook.URL, pq.Array(webhook.Events), webhook.Secret, webhook.Active, webhook.UpdatedAt, webhook.ID)
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar webhook: %w", translateWebhookError(err))
+	}
+
+	return requireAffectedWebhook(result)
+}
+
+// Delete remove a inscrição; as entregas são removidas em cascata pela foreign key
+func (r *PostgresWebhookRepository) Delete(ctx context.Context, id string) error {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Write)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM webhooks WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("erro ao excluir webhook: %w", translateWebhookError(err))
+	}
+
+	return requireAffectedWebhook(result)
+}
+
+func (r *PostgresWebhookRepository) AddDelivery(ctx context.Context, delivery webhook_entity.Delivery) error {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Write)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO webhook_deliveries (id, webhook_id, event_id, event_name, attempt, status_code, error, duration_ms, success, delivered_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`, delivery.ID, delivery.WebhookID, delivery.EventID, delivery.EventName, delivery.Attempt,
+		sql.NullInt64{Int64: int64(delivery.StatusCode), Valid: delivery.StatusCode != 0},
+		sql.NullString{String: delivery.Error, Valid: delivery.Error != ""},
+		delivery.DurationMs, delivery.Success, delivery.DeliveredAt)
+
+	// O webhook foi excluído durante a entrega
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+		return webhook_repository.ErrWebhookNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao registrar entrega do webhook: %w", translateWebhookError(err))
+	}
+
+	return nil
+}
+
+func (r *PostgresWebhookRepository) Deliveries(ctx context.Context, webhookID string, limit int) ([]webhook_entity.Delivery, error) {
+	if _, err := r.FindByID(ctx, webhookID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT id, webhook_id, event_id, event_name, attempt, status_code, error, duration_ms, success, delivered_at
+		FROM webhook_deliveries
+		WHERE webhook_id = $1
+		ORDER BY delivered_at DESC, attempt DESC
+	`
+	args := []interface{}{webhookID}
+	if limit > 0 {
+		query += `LIMIT $2`
+		args = append(args, limit)
+	}
+
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar entregas do webhook: %w", translateWebhookError(err))
+	}
+	defer rows.Close()
+
+	deliveries := []webhook_entity.Delivery{}
+	for rows.Next() {
+		var (
+			delivery   webhook_entity.Delivery
+			statusCode sql.NullInt64
+			deliverErr sql.NullString
+		)
+		if err := rows.Scan(&delivery.ID, &delivery.WebhookID, &delivery.EventID, &delivery.EventName, &delivery.Attempt,
+			&statusCode, &deliverErr, &delivery.DurationMs, &delivery.Success, &delivery.DeliveredAt); err != nil {
+			return nil, fmt.Errorf("erro ao escanear entrega do webhook: %w", err)
+		}
+		delivery.StatusCode = int(statusCode.Int64)
+		delivery.Error = deliverErr.String
+		delivery.DeliveredAt = delivery.DeliveredAt.UTC()
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao ler entregas do webhook: %w", translateWebhookError(err))
+	}
+
+	return deliveries, nil
+}
+
+// translateWebhookError traduz as falhas de conexão e de timeout em ErrUnavailable
+func translateWebhookError(err error) error {
+	if isUnavailable(err) {
+		return fmt.Errorf("%w: %w", webhook_repository.ErrUnavailable, err)
+	}
+
+	return err
+}
+
+// scanWebhook lê as colunas id, url, events, secret, active, created_at e updated_at
+func scanWebhook(row rowScanner, webhook *webhook_entity.Webhook) error {
+	var events pq.StringArray
+
+	if err := row.Scan(&webhook.ID, &webhook.URL, &events, &webhook.Secret, &webhook.Active, &webhook.CreatedAt, &webhook.UpdatedAt); err != nil {
+		return err
+	}
+
+	webhook.Events = []string(events)
+	webhook.CreatedAt = webhook.CreatedAt.UTC()
+	webhook.UpdatedAt = webhook.UpdatedAt.UTC()
+	return nil
+}
+
+// requireAffectedWebhook retorna ErrWebhookNotFound se nenhuma linha foi alterada
+func requireAffectedWebhook(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("erro ao verificar linhas afetadas: %w", err)
+	}
+	if affected == 0 {
+		return webhook_repository.ErrWebhookNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,263 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+
+	webhook_entity "github.com/williamkoller/golang-domain-driven-design/internal/domain/webhook/entity"
+	webhook_repository "github.com/williamkoller/golang-domain-driven-design/internal/domain/webhook/repository"
+)
+
+var webhookColumns = []string{"id", "url", "events", "secret", "active", "created_at", "updated_at"}
+
+func newTestWebhook(t *testing.T) webhook_entity.Webhook {
+	t.Helper()
+
+	webhook, err := webhook_entity.NewWebhook("https://partner.example.com/hooks", []string{"product.*", "product.created"}, "0123456789abcdef")
+	if err != nil {
+		t.Fatalf("NewWebhook() error = %v", err)
+	}
+	webhook.ID = testPublicID
+	return *webhook
+}
+
+func TestTranslateWebhookError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantKind error
+	}{
+		{name: "connection failure", err: &pq.Error{Code: "08006"}, wantKind: webhook_repository.ErrUnavailable},
+		{name: "deadline", err: context.DeadlineExceeded, wantKind: webhook_repository.ErrUnavailable},
+		{name: "closed connection", err: sql.ErrConnDone, wantKind: webhook_repository.ErrUnavailable},
+		{name: "syntax error is kept", err: &pq.Error{Code: "42601"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := translateWebhookError(tt.err)
+
+			if tt.wantKind == nil {
+				if err != tt.err {
+					t.Errorf("translateWebhookError() = %v, want the original error", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantKind) || !errors.Is(err, tt.err) {
+				t.Errorf("translateWebhookError() = %v, want %v wrapping %v", err, tt.wantKind, tt.err)
+			}
+		})
+	}
+}
+
+func TestPostgresWebhookRepository_QueryTimeouts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT (.+) FROM webhooks").
+		WillDelayFor(time.Second).
+		WillReturnRows(sqlmock.NewRows(webhookColumns))
+
+	repo := NewPostgresWebhookRepositoryWithTimeouts(db, QueryTimeouts{Read: 10 * time.Millisecond})
+	start := time.Now()
+	_, err = repo.FindAll(context.Background())
+	if err == nil || time.Since(start) > 500*time.Millisecond {
+		t.Errorf("Expected the query to be canceled by the timeout, got %v after %v", err, time.Since(start))
+	}
+}
+
+func TestPostgresWebhookRepository_Add(t *testing.T) {
+	webhook := newTestWebhook(t)
+
+	tests := []struct {
+		name      string
+		mockSetup func(sqlmock.Sqlmock)
+		wantErr   error
+	}{
+		{
+			name: "successful insert",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("INSERT INTO webhooks").
+					WithArgs(testPublicID, webhook.URL, "{\"product.*\",\"product.created\"}", webhook.Secret, true, webhook.CreatedAt, webhook.UpdatedAt).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "duplicate id",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("INSERT INTO webhooks").
+					WillReturnError(&pq.Error{Code: "23505"})
+			},
+			wantErr: webhook_repository.ErrWebhookAlreadyExists,
+		},
+		{
+			name: "database error",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("INSERT INTO webhooks").
+					WillReturnError(sql.ErrConnDone)
+			},
+			wantErr: sql.ErrConnDone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Failed to create mock database: %v", err)
+			}
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			err = NewPostgresWebhookRepository(db).Add(context.Background(), webhook)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Add() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestPostgresWebhookRepository_Find(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	createdAt := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT id, url, events, secret, active, created_at, updated_at FROM webhooks ORDER BY created_at, id").
+		WillReturnRows(sqlmock.NewRows(webhookColumns).
+			AddRow(testPublicID, "https://partner.example.com/hooks", "{product.*}", "0123456789abcdef", true, createdAt, createdAt))
+	mock.ExpectQuery("SELECT (.+) FROM webhooks WHERE id = \\$1").
+		WithArgs(testPublicID).
+		WillReturnRows(sqlmock.NewRows(webhookColumns).
+			AddRow(testPublicID, "https://partner.example.com/hooks", "{product.created,product.deleted}", "0123456789abcdef", false, createdAt, createdAt))
+	mock.ExpectQuery("SELECT (.+) FROM webhooks WHERE id = \\$1").
+		WithArgs("missing").
+		WillReturnError(sql.ErrNoRows)
+
+	repo := NewPostgresWebhookRepository(db)
+
+	webhooks, err := repo.FindAll(context.Background())
+	if err != nil || len(webhooks) != 1 || webhooks[0].Events[0] != "product.*" || !webhooks[0].Active {
+		t.Errorf("FindAll() = %+v, %v", webhooks, err)
+	}
+
+	webhook, err := repo.FindByID(context.Background(), testPublicID)
+	if err != nil || len(webhook.Events) != 2 || webhook.Active || webhook.Secret != "0123456789abcdef" {
+		t.Errorf("FindByID() = %+v, %v", webhook, err)
+	}
+
+	if _, err := repo.FindByID(context.Background(), "missing"); !errors.Is(err, webhook_repository.ErrWebhookNotFound) {
+		t.Errorf("FindByID() missing error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestPostgresWebhookRepository_UpdateAndDelete(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	webhook := newTestWebhook(t)
+
+	mock.ExpectExec("UPDATE webhooks SET url = \\$1, events = \\$2, secret = \\$3, active = \\$4, updated_at = \\$5 WHERE id = \\$6").
+		WithArgs(webhook.URL, "{\"product.*\",\"product.created\"}", webhook.Secret, true, webhook.UpdatedAt, testPublicID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE webhooks").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM webhooks WHERE id = \\$1").
+		WithArgs(testPublicID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM webhooks").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	repo := NewPostgresWebhookRepository(db)
+
+	if err := repo.Update(context.Background(), webhook); err != nil {
+		t.Errorf("Update() error = %v", err)
+	}
+	if err := repo.Update(context.Background(), webhook); !errors.Is(err, webhook_repository.ErrWebhookNotFound) {
+		t.Errorf("Update() missing error = %v", err)
+	}
+	if err := repo.Delete(context.Background(), testPublicID); err != nil {
+		t.Errorf("Delete() error = %v", err)
+	}
+	if err := repo.Delete(context.Background(), testPublicID); !errors.Is(err, webhook_repository.ErrWebhookNotFound) {
+		t.Errorf("Delete() missing error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestPostgresWebhookRepository_Deliveries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	createdAt := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	failed := webhook_entity.NewDelivery(testPublicID, "evt-1", "product.created", 1)
+	failed.Complete(0, errors.New("connection refused"), 30*time.Millisecond)
+
+	mock.ExpectExec("INSERT INTO webhook_deliveries").
+		WithArgs(failed.ID, testPublicID, "evt-1", "product.created", 1, nil, "connection refused", int64(30), false, failed.DeliveredAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO webhook_deliveries").
+		WillReturnError(&pq.Error{Code: "23503"})
+
+	mock.ExpectQuery("SELECT (.+) FROM webhooks WHERE id = \\$1").
+		WithArgs(testPublicID).
+		WillReturnRows(sqlmock.NewRows(webhookColumns).
+			AddRow(testPublicID, "https://partner.example.com/hooks", "{product.*}", "0123456789abcdef", true, createdAt, createdAt))
+	mock.ExpectQuery("SELECT (.+) FROM webhook_deliveries WHERE webhook_id = \\$1 ORDER BY delivered_at DESC, attempt DESC LIMIT \\$2").
+		WithArgs(testPublicID, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "webhook_id", "event_id", "event_name", "attempt", "status_code", "error", "duration_ms", "success", "delivered_at"}).
+			AddRow("d-2", testPublicID, "evt-1", "product.created", 2, int64(200), nil, int64(12), true, createdAt).
+			AddRow("d-1", testPublicID, "evt-1", "product.created", 1, nil, "connection refused", int64(30), false, createdAt))
+
+	repo := NewPostgresWebhookRepository(db)
+
+	if err := repo.AddDelivery(context.Background(), *failed); err != nil {
+		t.Errorf("AddDelivery() error = %v", err)
+	}
+	if err := repo.AddDelivery(context.Background(), *failed); !errors.Is(err, webhook_repository.ErrWebhookNotFound) {
+		t.Errorf("AddDelivery() for a deleted webhook error = %v", err)
+	}
+
+	deliveries, err := repo.Deliveries(context.Background(), testPublicID, 10)
+	if err != nil || len(deliveries) != 2 {
+		t.Fatalf("Deliveries() = %+v, %v", deliveries, err)
+	}
+	if deliveries[0].StatusCode != 200 || !deliveries[0].Success || deliveries[1].StatusCode != 0 || deliveries[1].Error != "connection refused" {
+		t.Errorf("Deliveries() = %+v", deliveries)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
@@ -0,0 +1,275 @@
+package webhook_notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	webhook_entity "github.com/williamkoller/golang-domain-driven-design/internal/domain/webhook/entity"
+	webhook_repository "github.com/williamkoller/golang-domain-driven-design/internal/domain/webhook/repository"
+	shared_events "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/events"
+)
+
+// Headers enviados em toda entrega
+const (
+	// SignatureHeader contém "sha256=" seguido do HMAC-SHA256 em hexadecimal de "<timestamp>.<corpo>"
+	SignatureHeader = "X-Alderaan-Signature"
+	// TimestampHeader contém o instante da tentativa em segundos Unix; o receptor deve
+	// rejeitar timestamps antigos para evitar replay
+	TimestampHeader = "X-Alderaan-Timestamp"
+	EventHeader     = "X-Alderaan-Event"
+	// DeliveryHeader contém o ID do evento, o mesmo em todas as tentativas, para deduplicação
+	DeliveryHeader = "X-Alderaan-Delivery"
+)
+
+// DeliveryEventName é o nome das entregas pendentes na fila do notifier
+const DeliveryEventName = "webhook.delivery"
+
+// DeliveryHandlerName identifica o handler de entrega na dead-letter store e no replay
+const DeliveryHandlerName = "webhook-delivery"
+
+// Config define o timeout de cada requisição, a política de retry das entregas
+// e o pool de workers que as executa
+type Config struct {
+	Timeout   time.Duration
+	Retry     shared_events.RetryPolicy
+	Workers   int // Entregas executadas em paralelo
+	QueueSize int // Entregas aguardando um worker; com a fila cheia, Handle espera espaço
+}
+
+// DefaultConfig retorna a configuração padrão: timeout de 5s, 5 tentativas de 1s até 30s
+// e 4 workers com fila de 1000 entregas
+func DefaultConfig() Config {
+	return Config{
+		Timeout: 5 * time.Second,
+		Retry: shared_events.RetryPolicy{
+			MaxAttempts:    5,
+			InitialBackoff: time.Second,
+			MaxBackoff:     30 * time.Second,
+			Multiplier:     2,
+		},
+		Workers:   4,
+		QueueSize: 1000,
+	}
+}
+
+// PendingDelivery é a entrega de um evento a um webhook aguardando um worker.
+// Guarda o corpo já serializado para que todas as tentativas enviem o mesmo conteúdo.
+type PendingDelivery struct {
+	WebhookID string          `json:"webhook_id"`
+	EventID   string          `json:"event_id"`
+	Event     string          `json:"event"`
+	Body      json.RawMessage `json:"body"`
+
+	attempts int // Tentativas feitas nesta execução do handler
+}
+
+func (d *PendingDelivery) EventName() string {
+	return DeliveryEventName
+}
+
+// AggregateID mantém as entregas de um webhook agrupadas na dead-letter store
+func (d *PendingDelivery) AggregateID() string {
+	return d.WebhookID
+}
+
+// RegisterEvents registra as entregas pendentes no registry, usado para desserializá-las
+// da dead-letter store persistida
+func RegisterEvents(registry *shared_events.EventRegistry) {
+	registry.Register(func() shared_events.Event { return &PendingDelivery{} })
+}
+
+// Notifier entrega os eventos de domínio aos webhooks inscritos. Cada webhook recebe
+// o envelope do evento em JSON, assinado com o seu segredo. Handle só enfileira as
+// entregas; um pool de workers próprio as executa com a política de retry do
+// EventDispatcher, e as que esgotam as tentativas vão para a dead-letter store.
+// Todas as tentativas são registradas no log de entregas.
+type Notifier struct {
+	repo   webhook_repository.IWebhookRepository
+	client *http.Client
+	config Config
+	queue  *shared_events.EventDispatcher
+	// ctx limita as consultas ao repositório e é cancelado por Stop
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func NewNotifier(repo webhook_repository.IWebhookRepository, config Config) *Notifier {
+	if config.Retry.MaxAttempts < 1 {
+		config.Retry.MaxAttempts = 1
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	n := &Notifier{
+		repo: repo,
+		client: &http.Client{
+			Timeout: config.Timeout,
+			// Redirecionamentos não são seguidos: a URL inscrita é a única que recebe o segredo
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		config: config,
+		queue: shared_events.NewEventDispatcherWithPool(shared_events.DispatcherConfig{
+			Workers:   config.Workers,
+			QueueSize: config.QueueSize,
+			Policy:    shared_events.QueuePolicyBlock,
+		}),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	n.queue.RegisterWithRetry(DeliveryEventName, DeliveryHandlerName, n.deliver, config.Retry)
+
+	return n
+}
+
+// Subscribe inscreve o notifier nos eventos do dispatcher que casam com o padrão.
+// As entregas que esgotam as tentativas vão para a dead-letter store do dispatcher,
+// onde o handler de entrega também é registrado para o replay.
+func (n *Notifier) Subscribe(dispatcher *shared_events.EventDispatcher, pattern string) {
+	n.queue.SetDeadLetterStore(dispatcher.DeadLetters())
+	dispatcher.RegisterWithRetry(DeliveryEventName, DeliveryHandlerName, n.deliver, n.config.Retry)
+	dispatcher.Register(pattern, n.Handle)
+}
+
+// Handle é o EventHandler registrado no dispatcher. Enfileira uma entrega para cada
+// webhook inscrito no evento e retorna sem esperar as requisições, para não segurar
+// o relay do outbox.
+func (n *Notifier) Handle(event shared_events.Event) {
+	envelope := shared_events.Wrap(event)
+
+	webhooks, err := n.repo.FindAll(n.ctx)
+	if err != nil {
+		log.Printf("❌ Erro ao buscar webhooks para o evento %s: %v", envelope.Name, err)
+		return
+	}
+
+	var body []byte
+	for _, webhook := range webhooks {
+		if !webhook.Matches(envelope.Name) {
+			continue
+		}
+
+		if body == nil {
+			if body, err = json.Marshal(envelope); err != nil {
+				log.Printf("❌ Erro ao serializar o evento %s para webhooks: %v", envelope.Name, err)
+				return
+			}
+		}
+
+		delivery := &PendingDelivery{WebhookID: webhook.ID, EventID: envelope.ID, Event: envelope.Name, Body: body}
+		if err := n.queue.Dispatch(DeliveryEventName, delivery); err != nil {
+			log.Printf("❌ Entrega do evento %s ao webhook %s não enfileirada: %v", envelope.Name, webhook.ID, err)
+		}
+	}
+}
+
+// Stop recusa novas entregas e espera as enfileiradas, respeitando o prazo do ctx.
+// As esperas entre tentativas são interrompidas e essas entregas vão para a dead-letter store.
+func (n *Notifier) Stop(ctx context.Context) error {
+	defer n.cancel()
+	return n.queue.Shutdown(ctx)
+}
+
+// deliver faz uma tentativa de entrega; o erro faz o dispatcher repetir a entrega.
+// Respostas que não adianta repetir e webhooks removidos ou desativados encerram a entrega.
+func (n *Notifier) deliver(event shared_events.Event) error {
+	delivery, ok := shared_events.Unwrap(event).(*PendingDelivery)
+	if !ok {
+		return fmt.Errorf("entrega inválida: %T", shared_events.Unwrap(event))
+	}
+
+	webhook, err := n.repo.FindByID(n.ctx, delivery.WebhookID)
+	if errors.Is(err, webhook_repository.ErrWebhookNotFound) || (err == nil && !webhook.Active) {
+		log.Printf("⚠️  Entrega do evento %s descartada: webhook %s removido ou desativado", delivery.Event, delivery.WebhookID)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao buscar webhook: %w", err)
+	}
+
+	delivery.attempts++
+	record := n.attempt(webhook, delivery)
+	if err := n.repo.AddDelivery(n.ctx, *record); err != nil {
+		log.Printf("⚠️  Erro ao registrar a entrega do evento %s ao webhook %s: %v", delivery.Event, webhook.ID, err)
+	}
+
+	if record.Success {
+		return nil
+	}
+	if !retryable(record.StatusCode) {
+		log.Printf("❌ Webhook %s recusou o evento %s: %s", webhook.ID, delivery.Event, failure(record))
+		return nil
+	}
+
+	return errors.New(failure(record))
+}
+
+// attempt faz uma requisição assinada e retorna o seu registro de entrega
+func (n *Notifier) attempt(webhook webhook_entity.Webhook, delivery *PendingDelivery) *webhook_entity.Delivery {
+	record := webhook_entity.NewDelivery(webhook.ID, delivery.EventID, delivery.Event, delivery.attempts)
+
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(delivery.Body))
+	if err != nil {
+		record.Complete(0, fmt.Errorf("erro ao criar requisição: %w", err), 0)
+		return record
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Alderaan-Webhooks/1.0")
+	req.Header.Set(EventHeader, delivery.Event)
+	req.Header.Set(DeliveryHeader, delivery.EventID)
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(webhook.Secret, timestamp, delivery.Body))
+
+	start := time.Now()
+	resp, err := n.client.Do(req)
+	if err != nil {
+		record.Complete(0, err, time.Since(start))
+		return record
+	}
+	defer resp.Body.Close()
+
+	record.Complete(resp.StatusCode, nil, time.Since(start))
+	return record
+}
+
+// Sign calcula a assinatura enviada em SignatureHeader
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify confere a assinatura de uma entrega em tempo constante; é o que um receptor faz
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// retryable indica se vale repetir a entrega: falhas de rede, 408, 429 e 5xx.
+// Os demais 4xx indicam um problema do lado do receptor que outra tentativa não resolve.
+func retryable(statusCode int) bool {
+	return statusCode == 0 ||
+		statusCode == http.StatusRequestTimeout ||
+		statusCode == http.StatusTooManyRequests ||
+		statusCode >= 500
+}
+
+func failure(delivery *webhook_entity.Delivery) string {
+	if delivery.Error != "" {
+		return delivery.Error
+	}
+	return fmt.Sprintf("status %d", delivery.StatusCode)
+}
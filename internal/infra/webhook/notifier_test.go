@@ -0,0 +1,337 @@
+package webhook_notifier
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	product_events "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/events"
+	webhook_entity "github.com/williamkoller/golang-domain-driven-design/internal/domain/webhook/entity"
+	webhook_repository "github.com/williamkoller/golang-domain-driven-design/internal/domain/webhook/repository"
+	shared_events "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/events"
+)
+
+const testSecret = "0123456789abcdef"
+
+// receiver é um servidor de teste que confere a assinatura e responde com os status informados
+type receiver struct {
+	server   *httptest.Server
+	statuses []int
+	calls    atomic.Int32
+
+	mu       sync.Mutex
+	bodies   [][]byte
+	headers  []http.Header
+	verified []bool
+}
+
+func newReceiver(t *testing.T, statuses ...int) *receiver {
+	t.Helper()
+
+	r := &receiver{statuses: statuses}
+	r.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		call := int(r.calls.Add(1))
+		body, _ := io.ReadAll(req.Body)
+		timestamp, _ := strconv.ParseInt(req.Header.Get(TimestampHeader), 10, 64)
+
+		r.mu.Lock()
+		r.bodies = append(r.bodies, body)
+		r.headers = append(r.headers, req.Header.Clone())
+		r.verified = append(r.verified, Verify(testSecret, timestamp, body, req.Header.Get(SignatureHeader)))
+		r.mu.Unlock()
+
+		status := http.StatusOK
+		if call <= len(r.statuses) {
+			status = r.statuses[call-1]
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(r.server.Close)
+
+	return r
+}
+
+func testConfig(maxAttempts int) Config {
+	return Config{
+		Timeout:   time.Second,
+		Retry:     shared_events.RetryPolicy{MaxAttempts: maxAttempts, InitialBackoff: time.Millisecond, Multiplier: 2},
+		Workers:   2,
+		QueueSize: 10,
+	}
+}
+
+// waitForDeliveries espera o log de entregas do webhook chegar a want registros
+func waitForDeliveries(t *testing.T, repo *webhook_repository.WebhookRepository, webhookID string, want int) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		deliveries, _ := repo.Deliveries(context.Background(), webhookID, 0)
+		if len(deliveries) >= want {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("delivery log has %d entries, want %d", len(deliveries), want)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// stop encerra o notifier, esperando as entregas enfileiradas terminarem
+func stop(t *testing.T, notifier *Notifier) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := notifier.Stop(ctx); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+}
+
+func addWebhook(t *testing.T, repo *webhook_repository.WebhookRepository, url string, events ...string) webhook_entity.Webhook {
+	t.Helper()
+
+	webhook, err := webhook_entity.NewWebhook(url, events, testSecret)
+	if err != nil {
+		t.Fatalf("NewWebhook() error = %v", err)
+	}
+	_ = repo.Add(context.Background(), *webhook)
+	return *webhook
+}
+
+func TestNotifier_Handle(t *testing.T) {
+	event := shared_events.WithCorrelationID("req-1", product_events.NewProductRestoredEvent("id-1", "Notebook", 12345))[0]
+	envelope := event.(*shared_events.Envelope)
+
+	tests := []struct {
+		name           string
+		statuses       []int
+		maxAttempts    int
+		wantCalls      int32
+		wantDeliveries []int
+		wantSuccess    bool
+		wantDeadLetter bool
+	}{
+		{name: "first attempt succeeds", wantCalls: 1, maxAttempts: 3, wantDeliveries: []int{200}, wantSuccess: true},
+		{name: "retries server errors", statuses: []int{500, 503}, maxAttempts: 3, wantCalls: 3, wantDeliveries: []int{200, 503, 500}, wantSuccess: true},
+		{name: "gives up after max attempts", statuses: []int{500, 500, 500}, maxAttempts: 3, wantCalls: 3, wantDeliveries: []int{500, 500, 500}, wantDeadLetter: true},
+		{name: "does not retry client errors", statuses: []int{410}, maxAttempts: 3, wantCalls: 1, wantDeliveries: []int{410}},
+		{name: "retries rate limiting", statuses: []int{429}, maxAttempts: 3, wantCalls: 2, wantDeliveries: []int{200, 429}, wantSuccess: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver := newReceiver(t, tt.statuses...)
+			repo := webhook_repository.NewRepository()
+			webhook := addWebhook(t, repo, receiver.server.URL, "product.*")
+
+			notifier := NewNotifier(repo, testConfig(tt.maxAttempts))
+			notifier.Handle(event)
+			waitForDeliveries(t, repo, webhook.ID, len(tt.wantDeliveries))
+			stop(t, notifier)
+
+			if got := receiver.calls.Load(); got != tt.wantCalls {
+				t.Fatalf("receiver got %d calls, want %d", got, tt.wantCalls)
+			}
+
+			deliveries, _ := repo.Deliveries(context.Background(), webhook.ID, 0)
+			if len(deliveries) != len(tt.wantDeliveries) {
+				t.Fatalf("delivery log has %d entries, want %d", len(deliveries), len(tt.wantDeliveries))
+			}
+			for i, status := range tt.wantDeliveries {
+				if deliveries[i].StatusCode != status || deliveries[i].EventID != envelope.ID || deliveries[i].Attempt != len(deliveries)-i {
+					t.Errorf("deliveries[%d] = %+v, want status %d", i, deliveries[i], status)
+				}
+			}
+			if deliveries[0].Success != tt.wantSuccess {
+				t.Errorf("last delivery success = %v, want %v", deliveries[0].Success, tt.wantSuccess)
+			}
+			if entries, _ := notifier.queue.DeadLetters().List(); (len(entries) == 1) != tt.wantDeadLetter {
+				t.Errorf("dead letters = %+v, want dead letter %v", entries, tt.wantDeadLetter)
+			}
+
+			for i, header := range receiver.headers {
+				if !receiver.verified[i] {
+					t.Errorf("call %d: invalid signature %q", i, header.Get(SignatureHeader))
+				}
+				if header.Get(EventHeader) != "product.restored" || header.Get(DeliveryHeader) != envelope.ID {
+					t.Errorf("call %d: headers = %v", i, header)
+				}
+			}
+
+			var received struct {
+				ID            string `json:"id"`
+				Name          string `json:"name"`
+				CorrelationID string `json:"correlation_id"`
+				Payload       struct {
+					Name string `json:"name"`
+				} `json:"payload"`
+			}
+			if err := json.Unmarshal(receiver.bodies[0], &received); err != nil {
+				t.Fatalf("invalid body: %v", err)
+			}
+			if received.ID != envelope.ID || received.Name != "product.restored" || received.CorrelationID != "req-1" || received.Payload.Name != "Notebook" {
+				t.Errorf("body = %s", receiver.bodies[0])
+			}
+		})
+	}
+}
+
+func TestNotifier_HandleOnlyMatchingWebhooks(t *testing.T) {
+	created := newReceiver(t)
+	all := newReceiver(t)
+	inactive := newReceiver(t)
+
+	repo := webhook_repository.NewRepository()
+	addWebhook(t, repo, created.server.URL, "product.created")
+	addWebhook(t, repo, all.server.URL, "*")
+	disabled := addWebhook(t, repo, inactive.server.URL, "product.*")
+	disabled.Active = false
+	_ = repo.Update(context.Background(), disabled)
+
+	notifier := NewNotifier(repo, testConfig(1))
+	notifier.Handle(product_events.NewProductDeletedEvent("id-1", "Notebook", 12345, time.Now()))
+	stop(t, notifier)
+
+	if created.calls.Load() != 0 || all.calls.Load() != 1 || inactive.calls.Load() != 0 {
+		t.Errorf("calls: created = %d, all = %d, inactive = %d", created.calls.Load(), all.calls.Load(), inactive.calls.Load())
+	}
+}
+
+func TestNotifier_NetworkError(t *testing.T) {
+	receiver := newReceiver(t)
+	receiver.server.Close()
+
+	repo := webhook_repository.NewRepository()
+	webhook := addWebhook(t, repo, receiver.server.URL, "*")
+
+	notifier := NewNotifier(repo, testConfig(2))
+	notifier.Handle(product_events.NewProductRestoredEvent("id-1", "Notebook", 12345))
+	waitForDeliveries(t, repo, webhook.ID, 2)
+	stop(t, notifier)
+
+	deliveries, _ := repo.Deliveries(context.Background(), webhook.ID, 0)
+	if len(deliveries) != 2 || deliveries[0].StatusCode != 0 || deliveries[0].Error == "" || deliveries[0].Success {
+		t.Errorf("deliveries = %+v", deliveries)
+	}
+}
+
+func TestNotifier_HandleDoesNotWait(t *testing.T) {
+	release := make(chan struct{})
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		calls.Add(1)
+		<-release
+	}))
+	t.Cleanup(server.Close)
+
+	repo := webhook_repository.NewRepository()
+	webhook := addWebhook(t, repo, server.URL, "*")
+	notifier := NewNotifier(repo, testConfig(1))
+
+	// O receptor só responde depois que Handle retorna
+	notifier.Handle(product_events.NewProductRestoredEvent("id-1", "Notebook", 12345))
+	close(release)
+	stop(t, notifier)
+
+	if deliveries, _ := repo.Deliveries(context.Background(), webhook.ID, 0); calls.Load() != 1 || len(deliveries) != 1 || !deliveries[0].Success {
+		t.Errorf("calls = %d, deliveries = %+v", calls.Load(), deliveries)
+	}
+}
+
+func TestNotifier_Stop(t *testing.T) {
+	receiver := newReceiver(t, 500, 500)
+
+	repo := webhook_repository.NewRepository()
+	webhook := addWebhook(t, repo, receiver.server.URL, "*")
+
+	config := testConfig(3)
+	config.Retry.InitialBackoff = time.Hour
+	notifier := NewNotifier(repo, config)
+
+	notifier.Handle(product_events.NewProductRestoredEvent("id-1", "Notebook", 12345))
+
+	// Espera a primeira tentativa antes de parar
+	for receiver.calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	stop(t, notifier)
+	stop(t, notifier)
+
+	if deliveries, _ := repo.Deliveries(context.Background(), webhook.ID, 0); len(deliveries) != 1 {
+		t.Errorf("deliveries = %d, want 1", len(deliveries))
+	}
+	// A entrega interrompida pode ser reenviada pelo replay
+	if entries, _ := notifier.queue.DeadLetters().List(); len(entries) != 1 || entries[0].HandlerName != DeliveryHandlerName {
+		t.Errorf("dead letters = %+v, want the interrupted delivery", entries)
+	}
+}
+
+func TestNotifier_Subscribe(t *testing.T) {
+	receiver := newReceiver(t, 500)
+
+	repo := webhook_repository.NewRepository()
+	webhook := addWebhook(t, repo, receiver.server.URL, "product.*")
+
+	dispatcher := shared_events.NewEventDispatcher()
+	notifier := NewNotifier(repo, testConfig(1))
+	notifier.Subscribe(dispatcher, "product.*")
+
+	dispatcher.DispatchAndWait("product.restored", product_events.NewProductRestoredEvent("id-1", "Notebook", 12345))
+	stop(t, notifier)
+
+	// A entrega que falhou fica na dead-letter store do dispatcher e é reenviada pelo replay
+	entries, _ := dispatcher.DeadLetters().List()
+	if len(entries) != 1 || entries[0].EventName != DeliveryEventName {
+		t.Fatalf("dead letters = %+v, want the failed delivery", entries)
+	}
+	if err := dispatcher.ReplayDeadLetter(entries[0].ID); err != nil {
+		t.Fatalf("ReplayDeadLetter() error = %v", err)
+	}
+
+	deliveries, _ := repo.Deliveries(context.Background(), webhook.ID, 0)
+	if len(deliveries) != 2 || !deliveries[0].Success || deliveries[1].Success {
+		t.Errorf("deliveries = %+v, want a failure followed by a successful replay", deliveries)
+	}
+	if entries, _ := dispatcher.DeadLetters().List(); len(entries) != 0 {
+		t.Errorf("dead letters after replay = %d, want 0", len(entries))
+	}
+}
+
+func TestPendingDelivery_Registry(t *testing.T) {
+	registry := shared_events.NewEventRegistry()
+	RegisterEvents(registry)
+
+	delivery := &PendingDelivery{WebhookID: "wh-1", EventID: "evt-1", Event: "product.created", Body: json.RawMessage(`{"id":"evt-1"}`)}
+	payload, _ := json.Marshal(delivery)
+
+	event, _, err := registry.Decode(DeliveryEventName, 1, payload)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if decoded, ok := event.(*PendingDelivery); !ok || decoded.WebhookID != "wh-1" || string(decoded.Body) != `{"id":"evt-1"}` {
+		t.Errorf("Decode() = %+v", event)
+	}
+}
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"name":"product.created"}`)
+	signature := Sign(testSecret, 1700000000, body)
+
+	if len(signature) != len("sha256=")+64 || signature[:7] != "sha256=" {
+		t.Errorf("Sign() = %q", signature)
+	}
+	if !Verify(testSecret, 1700000000, body, signature) {
+		t.Error("Verify() should accept its own signature")
+	}
+	if Verify(testSecret, 1700000001, body, signature) || Verify("another-secret-value", 1700000000, body, signature) {
+		t.Error("Verify() should reject a different timestamp or secret")
+	}
+}
@@ -0,0 +1,116 @@
+package metrics
+
+import (
+	"sync"
+
+	product_events "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/events"
+	shared_events "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/events"
+)
+
+// BusinessProjectionName identifica a projeção das métricas de negócio no replay
+const BusinessProjectionName = "business-metrics"
+
+// productState é o que a projeção precisa saber de cada produto
+type productState struct {
+	categories []string
+	currency   string
+	amount     int64
+}
+
+// BusinessProjection mantém os gauges de negócio (total de produtos, valor total e preço
+// médio por moeda, produtos por categoria) a partir dos eventos de produto. Pode ser
+// reconstruída do zero reaplicando o histórico de eventos.
+type BusinessProjection struct {
+	metrics *Metrics
+
+	mu      sync.Mutex
+	active  map[string]productState
+	deleted map[string]productState // Guardados para o caso de o produto ser restaurado
+}
+
+func NewBusinessProjection(m *Metrics) *BusinessProjection {
+	return &BusinessProjection{
+		metrics: m,
+		active:  make(map[string]productState),
+		deleted: make(map[string]productState),
+	}
+}
+
+func (p *BusinessProjection) Name() string {
+	return BusinessProjectionName
+}
+
+// Reset descarta o estado e zera os gauges
+func (p *BusinessProjection) Reset() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.active = make(map[string]productState)
+	p.deleted = make(map[string]productState)
+	p.publish()
+
+	return nil
+}
+
+// Apply atualiza o estado com um evento de produto; outros eventos são ignorados
+func (p *BusinessProjection) Apply(event shared_events.Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch e := shared_events.Unwrap(event).(type) {
+	case *product_events.ProductCreatedEvent:
+		p.active[e.ID] = productState{categories: e.Categories, currency: e.Price.Currency(), amount: e.Price.Amount()}
+	case *product_events.ProductUpdatedEvent:
+		state := productState{categories: e.After.Categories, currency: e.After.Price.Currency(), amount: e.After.Price.Amount()}
+		// Produtos excluídos também são alterados, pela renomeação ou fusão de categorias
+		if _, ok := p.deleted[e.After.ID]; ok {
+			p.deleted[e.After.ID] = state
+		} else {
+			p.active[e.After.ID] = state
+		}
+	case *product_events.ProductDeletedEvent:
+		if state, ok := p.active[e.ID]; ok {
+			p.deleted[e.ID] = state
+			delete(p.active, e.ID)
+		}
+	case *product_events.ProductRestoredEvent:
+		if state, ok := p.deleted[e.ID]; ok {
+			p.active[e.ID] = state
+			delete(p.deleted, e.ID)
+		}
+	default:
+		return nil
+	}
+
+	p.publish()
+	return nil
+}
+
+// publish recalcula os gauges a partir do estado; exige o lock
+func (p *BusinessProjection) publish() {
+	totalValue := make(map[string]int64)
+	countByCurrency := make(map[string]int)
+	byCategory := make(map[string]int)
+
+	for _, state := range p.active {
+		totalValue[state.currency] += state.amount
+		countByCurrency[state.currency]++
+		for _, category := range state.categories {
+			byCategory[category]++
+		}
+	}
+
+	p.metrics.ProductsTotal.Set(float64(len(p.active)))
+
+	// Valores de moedas diferentes não são somados
+	p.metrics.ResetProductsValue()
+	for currency, total := range totalValue {
+		p.metrics.UpdateProductsTotalValue(currency, float64(total))
+		p.metrics.UpdateProductsAveragePrice(currency, float64(total)/float64(countByCurrency[currency]))
+	}
+
+	p.metrics.ResetProductsByCategory()
+	for category, count := range byCategory {
+		p.metrics.UpdateProductsByCategory(category, float64(count))
+	}
+}
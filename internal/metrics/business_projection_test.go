@@ -0,0 +1,123 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	product_events "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/events"
+	product_valueobject "github.com/williamkoller/golang-domain-driven-design/internal/domain/product/valueobject"
+	shared_events "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/events"
+)
+
+func newBusinessTestMetrics() *Metrics {
+	return &Metrics{
+		ProductsTotal: prometheus.NewGauge(prometheus.GaugeOpts{Name: "test_projection_products_total", Help: "Test"}),
+		ProductsByCategory: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "test_projection_products_by_category", Help: "Test"},
+			[]string{"category"},
+		),
+		ProductsTotalValue: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "test_projection_products_total_value", Help: "Test"},
+			[]string{"currency"},
+		),
+		ProductsAveragePrice: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "test_projection_products_average_price", Help: "Test"},
+			[]string{"currency"},
+		),
+	}
+}
+
+func money(t *testing.T, amount int64, currency string) product_valueobject.Money {
+	t.Helper()
+	m, err := product_valueobject.NewMoney(amount, currency)
+	if err != nil {
+		t.Fatalf("NewMoney() error = %v", err)
+	}
+	return m
+}
+
+func TestBusinessProjection(t *testing.T) {
+	m := newBusinessTestMetrics()
+	projection := NewBusinessProjection(m)
+
+	dispatcher := shared_events.NewEventDispatcher()
+	dispatcher.SetEventStore(shared_events.NewInMemoryEventStore())
+	dispatcher.RegisterProjection("product.*", projection)
+
+	notebook := money(t, 350000, "BRL")
+	events := []shared_events.Event{
+		product_events.NewProductCreatedEvent("p-1", "Notebook", 1, []string{"Electronics", "Computers"}, notebook),
+		product_events.NewProductCreatedEvent("p-2", "Mouse", 2, []string{"Electronics"}, money(t, 25000, "BRL")),
+		product_events.NewProductCreatedEvent("p-3", "Keyboard", 3, []string{"Peripherals"}, money(t, 9900, "USD")),
+		product_events.NewProductUpdatedEvent(
+			product_events.ProductSnapshot{ID: "p-2", Name: "Mouse", Sku: 2, Categories: []string{"Electronics"}, Price: money(t, 25000, "BRL")},
+			product_events.ProductSnapshot{ID: "p-2", Name: "Mouse", Sku: 2, Categories: []string{"Peripherals"}, Price: money(t, 30000, "BRL")},
+		),
+		product_events.NewProductDeletedEvent("p-1", "Notebook", 1, time.Now()),
+		product_events.NewProductRestoredEvent("p-1", "Notebook", 1),
+		product_events.NewProductDeletedEvent("p-3", "Keyboard", 3, time.Now()),
+		// A renomeação de uma categoria altera também os excluídos, sem restaurá-los
+		product_events.NewProductUpdatedEvent(
+			product_events.ProductSnapshot{ID: "p-3", Name: "Keyboard", Sku: 3, Categories: []string{"Peripherals"}, Price: money(t, 9900, "USD")},
+			product_events.ProductSnapshot{ID: "p-3", Name: "Keyboard", Sku: 3, Categories: []string{"Accessories"}, Price: money(t, 9900, "USD")},
+		),
+	}
+	for _, event := range events {
+		dispatcher.DispatchAndWait(event.EventName(), event)
+	}
+
+	assertGauges := func(t *testing.T) {
+		t.Helper()
+
+		if got := testutil.ToFloat64(m.ProductsTotal); got != 2 {
+			t.Errorf("ProductsTotal = %v, want 2", got)
+		}
+		if got := testutil.ToFloat64(m.ProductsTotalValue.WithLabelValues("BRL")); got != 380000 {
+			t.Errorf("ProductsTotalValue[BRL] = %v, want 380000", got)
+		}
+		if got := testutil.ToFloat64(m.ProductsAveragePrice.WithLabelValues("BRL")); got != 190000 {
+			t.Errorf("ProductsAveragePrice[BRL] = %v, want 190000", got)
+		}
+		// p-3 foi excluído: USD some das métricas de valor
+		if count := testutil.CollectAndCount(m.ProductsTotalValue); count != 1 {
+			t.Errorf("ProductsTotalValue series = %d, want 1", count)
+		}
+		for category, want := range map[string]float64{"Electronics": 1, "Computers": 1, "Peripherals": 1} {
+			if got := testutil.ToFloat64(m.ProductsByCategory.WithLabelValues(category)); got != want {
+				t.Errorf("ProductsByCategory[%s] = %v, want %v", category, got, want)
+			}
+		}
+	}
+
+	t.Run("live events", assertGauges)
+
+	t.Run("rebuild from history", func(t *testing.T) {
+		// Simula gauges divergentes, como após reiniciar a aplicação
+		m.ProductsTotal.Set(99)
+		m.ResetProductsValue()
+
+		replayed, err := dispatcher.RebuildProjections(context.Background(), BusinessProjectionName)
+		if err != nil {
+			t.Fatalf("RebuildProjections() error = %v", err)
+		}
+		if replayed != len(events) {
+			t.Errorf("replayed = %d, want %d", replayed, len(events))
+		}
+		assertGauges(t)
+	})
+
+	t.Run("reset clears the gauges", func(t *testing.T) {
+		if err := projection.Reset(); err != nil {
+			t.Fatalf("Reset() error = %v", err)
+		}
+		if got := testutil.ToFloat64(m.ProductsTotal); got != 0 {
+			t.Errorf("ProductsTotal = %v, want 0", got)
+		}
+		if count := testutil.CollectAndCount(m.ProductsByCategory); count != 0 {
+			t.Errorf("ProductsByCategory series = %d, want 0", count)
+		}
+	})
+}
@@ -21,10 +21,14 @@ type Metrics struct {
 
 	// Métricas de Negócio
 	ProductsCreated      prometheus.Counter
+	ProductsDeleted      prometheus.Counter
 	ProductsTotal        prometheus.Gauge
 	ProductsByCategory   *prometheus.GaugeVec
-	ProductsTotalValue   prometheus.Gauge
-	ProductsAveragePrice prometheus.Gauge
+	ProductsTotalValue   *prometheus.GaugeVec
+	ProductsAveragePrice *prometheus.GaugeVec
+
+	// Eventos de Domínio
+	EventsDropped *prometheus.CounterVec
 }
 
 // NewMetrics cria e registra todas as métricas
@@ -91,6 +95,14 @@ func NewMetrics() *Metrics {
 			},
 		),
 
+		// Métricas de Negócio - Produtos Excluídos
+		ProductsDeleted: promauto.NewCounter(
+			prometheus.CounterOpts{
+				Name: "products_deleted_total",
+				Help: "Total de produtos excluídos desde o início da aplicação",
+			},
+		),
+
 		// Métricas de Negócio - Total de Produtos
 		ProductsTotal: promauto.NewGauge(
 			prometheus.GaugeOpts{
@@ -109,19 +121,30 @@ func NewMetrics() *Metrics {
 		),
 
 		// Métricas de Negócio - Valor Total dos Produtos
-		ProductsTotalValue: promauto.NewGauge(
+		ProductsTotalValue: promauto.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Name: "products_total_value",
-				Help: "Valor total de todos os produtos em estoque",
+				Help: "Valor total de todos os produtos em estoque, em unidades menores da moeda",
 			},
+			[]string{"currency"},
 		),
 
 		// Métricas de Negócio - Preço Médio dos Produtos
-		ProductsAveragePrice: promauto.NewGauge(
+		ProductsAveragePrice: promauto.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Name: "products_average_price",
-				Help: "Preço médio dos produtos",
+				Help: "Preço médio dos produtos, em unidades menores da moeda",
 			},
+			[]string{"currency"},
+		),
+
+		// Eventos de Domínio - Descartados por fila cheia
+		EventsDropped: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "domain_events_dropped_total",
+				Help: "Total de eventos de domínio descartados porque a fila do dispatcher estava cheia",
+			},
+			[]string{"event"},
 		),
 	}
 }
@@ -144,10 +167,15 @@ func (m *Metrics) RecordHTTPRequest(method, endpoint, status string, duration fl
 	}
 }
 
-// IncrementProductsCreated incrementa o contador de produtos criados
+// IncrementProductsCreated incrementa o contador de produtos criados.
+// O gauge ProductsTotal é mantido pela BusinessProjection.
 func (m *Metrics) IncrementProductsCreated() {
 	m.ProductsCreated.Inc()
-	m.ProductsTotal.Inc()
+}
+
+// IncrementProductsDeleted incrementa o contador de produtos excluídos
+func (m *Metrics) IncrementProductsDeleted() {
+	m.ProductsDeleted.Inc()
 }
 
 // UpdateProductsByCategory atualiza a contagem de produtos por categoria
@@ -155,17 +183,28 @@ func (m *Metrics) UpdateProductsByCategory(category string, count float64) {
 	m.ProductsByCategory.WithLabelValues(category).Set(count)
 }
 
-// UpdateProductsTotalValue atualiza o valor total dos produtos
-func (m *Metrics) UpdateProductsTotalValue(totalValue float64) {
-	m.ProductsTotalValue.Set(totalValue)
+// UpdateProductsTotalValue atualiza o valor total dos produtos de uma moeda
+func (m *Metrics) UpdateProductsTotalValue(currency string, totalValue float64) {
+	m.ProductsTotalValue.WithLabelValues(currency).Set(totalValue)
 }
 
-// UpdateProductsAveragePrice atualiza o preço médio dos produtos
-func (m *Metrics) UpdateProductsAveragePrice(avgPrice float64) {
-	m.ProductsAveragePrice.Set(avgPrice)
+// UpdateProductsAveragePrice atualiza o preço médio dos produtos de uma moeda
+func (m *Metrics) UpdateProductsAveragePrice(currency string, avgPrice float64) {
+	m.ProductsAveragePrice.WithLabelValues(currency).Set(avgPrice)
+}
+
+// ResetProductsValue limpa as métricas de valor por moeda (útil antes de recalcular)
+func (m *Metrics) ResetProductsValue() {
+	m.ProductsTotalValue.Reset()
+	m.ProductsAveragePrice.Reset()
 }
 
 // ResetProductsByCategory limpa as métricas de categoria (útil antes de recalcular)
 func (m *Metrics) ResetProductsByCategory() {
 	m.ProductsByCategory.Reset()
 }
+
+// IncrementEventsDropped conta um evento descartado pelo dispatcher
+func (m *Metrics) IncrementEventsDropped(eventName string) {
+	m.EventsDropped.WithLabelValues(eventName).Inc()
+}
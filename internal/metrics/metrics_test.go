@@ -30,6 +30,9 @@ func TestNewMetrics(t *testing.T) {
 	if m.ProductsCreated == nil {
 		t.Error("ProductsCreated is nil")
 	}
+	if m.ProductsDeleted == nil {
+		t.Error("ProductsDeleted is nil")
+	}
 	if m.ProductsTotal == nil {
 		t.Error("ProductsTotal is nil")
 	}
@@ -42,6 +45,9 @@ func TestNewMetrics(t *testing.T) {
 	if m.ProductsAveragePrice == nil {
 		t.Error("ProductsAveragePrice is nil")
 	}
+	if m.EventsDropped == nil {
+		t.Error("EventsDropped is nil")
+	}
 }
 
 func TestMetrics_RecordHTTPRequest(t *testing.T) {
@@ -134,16 +140,9 @@ func TestMetrics_IncrementProductsCreated(t *testing.T) {
 				Help: "Test products created",
 			},
 		),
-		ProductsTotal: prometheus.NewGauge(
-			prometheus.GaugeOpts{
-				Name: "test_products_total",
-				Help: "Test products total",
-			},
-		),
 	}
 
 	reg.MustRegister(m.ProductsCreated)
-	reg.MustRegister(m.ProductsTotal)
 
 	// Incrementar 3 vezes
 	for i := 0; i < 3; i++ {
@@ -151,11 +150,26 @@ func TestMetrics_IncrementProductsCreated(t *testing.T) {
 	}
 
 	// Verificar contadores
-	if testutil.CollectAndCount(m.ProductsCreated) == 0 {
-		t.Error("ProductsCreated not incremented")
+	if got := testutil.ToFloat64(m.ProductsCreated); got != 3 {
+		t.Errorf("ProductsCreated = %v, want 3", got)
 	}
-	if testutil.CollectAndCount(m.ProductsTotal) == 0 {
-		t.Error("ProductsTotal not incremented")
+}
+
+func TestMetrics_IncrementProductsDeleted(t *testing.T) {
+	m := &Metrics{
+		ProductsDeleted: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "test_products_deleted_total",
+				Help: "Test products deleted",
+			},
+		),
+	}
+
+	m.IncrementProductsDeleted()
+	m.IncrementProductsDeleted()
+
+	if got := testutil.ToFloat64(m.ProductsDeleted); got != 2 {
+		t.Errorf("ProductsDeleted = %v, want 2", got)
 	}
 }
 
@@ -189,20 +203,25 @@ func TestMetrics_UpdateProductsTotalValue(t *testing.T) {
 	reg := prometheus.NewRegistry()
 
 	m := &Metrics{
-		ProductsTotalValue: prometheus.NewGauge(
+		ProductsTotalValue: prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Name: "test_products_total_value",
 				Help: "Test products total value",
 			},
+			[]string{"currency"},
 		),
 	}
 
 	reg.MustRegister(m.ProductsTotalValue)
 
-	m.UpdateProductsTotalValue(15000.50)
+	m.UpdateProductsTotalValue("BRL", 15000.50)
+	m.UpdateProductsTotalValue("USD", 2000)
 
-	if testutil.CollectAndCount(m.ProductsTotalValue) == 0 {
-		t.Error("ProductsTotalValue not set")
+	if count := testutil.CollectAndCount(m.ProductsTotalValue); count != 2 {
+		t.Errorf("ProductsTotalValue count = %d, want 2", count)
+	}
+	if got := testutil.ToFloat64(m.ProductsTotalValue.WithLabelValues("BRL")); got != 15000.50 {
+		t.Errorf("ProductsTotalValue[BRL] = %v, want 15000.50", got)
 	}
 }
 
@@ -210,20 +229,52 @@ func TestMetrics_UpdateProductsAveragePrice(t *testing.T) {
 	reg := prometheus.NewRegistry()
 
 	m := &Metrics{
-		ProductsAveragePrice: prometheus.NewGauge(
+		ProductsAveragePrice: prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Name: "test_products_average_price",
 				Help: "Test products average price",
 			},
+			[]string{"currency"},
 		),
 	}
 
 	reg.MustRegister(m.ProductsAveragePrice)
 
-	m.UpdateProductsAveragePrice(299.99)
+	m.UpdateProductsAveragePrice("BRL", 299.99)
 
-	if testutil.CollectAndCount(m.ProductsAveragePrice) == 0 {
-		t.Error("ProductsAveragePrice not set")
+	if got := testutil.ToFloat64(m.ProductsAveragePrice.WithLabelValues("BRL")); got != 299.99 {
+		t.Errorf("ProductsAveragePrice[BRL] = %v, want 299.99", got)
+	}
+}
+
+func TestMetrics_ResetProductsValue(t *testing.T) {
+	m := &Metrics{
+		ProductsTotalValue: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "test_products_total_value_reset",
+				Help: "Test products total value reset",
+			},
+			[]string{"currency"},
+		),
+		ProductsAveragePrice: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "test_products_average_price_reset",
+				Help: "Test products average price reset",
+			},
+			[]string{"currency"},
+		),
+	}
+
+	m.UpdateProductsTotalValue("BRL", 100)
+	m.UpdateProductsAveragePrice("BRL", 100)
+
+	m.ResetProductsValue()
+
+	if count := testutil.CollectAndCount(m.ProductsTotalValue); count != 0 {
+		t.Errorf("ProductsTotalValue count after reset = %d, want 0", count)
+	}
+	if count := testutil.CollectAndCount(m.ProductsAveragePrice); count != 0 {
+		t.Errorf("ProductsAveragePrice count after reset = %d, want 0", count)
 	}
 }
 
@@ -254,6 +305,29 @@ func TestMetrics_ResetProductsByCategory(t *testing.T) {
 	// Este teste confirma que não há panic
 }
 
+func TestMetrics_IncrementEventsDropped(t *testing.T) {
+	m := &Metrics{
+		EventsDropped: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "test_domain_events_dropped_total",
+				Help: "Test domain events dropped",
+			},
+			[]string{"event"},
+		),
+	}
+
+	m.IncrementEventsDropped("product.created")
+	m.IncrementEventsDropped("product.created")
+	m.IncrementEventsDropped("product.deleted")
+
+	if got := testutil.ToFloat64(m.EventsDropped.WithLabelValues("product.created")); got != 2 {
+		t.Errorf("EventsDropped[product.created] = %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(m.EventsDropped.WithLabelValues("product.deleted")); got != 1 {
+		t.Errorf("EventsDropped[product.deleted] = %v, want 1", got)
+	}
+}
+
 // Benchmark tests
 func BenchmarkMetrics_RecordHTTPRequest(b *testing.B) {
 	m := NewMetrics()
@@ -2,13 +2,19 @@ package config
 
 import (
 	"os"
+	"path/filepath"
 	"strconv"
 )
 
 // Config contém todas as configurações da aplicação
 type Config struct {
-	Database DatabaseConfig
-	Server   ServerConfig
+	Database    DatabaseConfig
+	Server      ServerConfig
+	Events      EventsConfig
+	Webhooks    WebhooksConfig
+	Idempotency IdempotencyConfig
+	Jobs        JobsConfig
+	Categories  CategoriesConfig
 }
 
 // DatabaseConfig contém configurações do banco de dados
@@ -19,6 +25,9 @@ type DatabaseConfig struct {
 	Password string
 	DBName   string
 	SSLMode  string
+
+	ReadTimeoutMs  int // Timeout de cada consulta de leitura; 0 desliga
+	WriteTimeoutMs int // Timeout de cada transação de escrita; 0 desliga
 }
 
 // ServerConfig contém configurações do servidor
@@ -27,6 +36,36 @@ type ServerConfig struct {
 	GinMode string
 }
 
+// EventsConfig contém configurações do dispatcher de eventos de domínio
+type EventsConfig struct {
+	Workers     int    // Workers do pool; 0 executa cada handler em uma goroutine própria
+	QueueSize   int    // Capacidade da fila de eventos aguardando um worker
+	QueuePolicy string // Comportamento com a fila cheia: block, drop ou error
+}
+
+// WebhooksConfig contém configurações da entrega de eventos aos webhooks
+type WebhooksConfig struct {
+	TimeoutSeconds int // Timeout de cada requisição ao webhook
+	MaxAttempts    int // Tentativas por entrega, incluindo a primeira
+}
+
+// IdempotencyConfig contém configurações do header Idempotency-Key
+type IdempotencyConfig struct {
+	TTLSeconds int // Por quanto tempo a resposta de uma chave é guardada
+}
+
+// JobsConfig contém configurações dos jobs em segundo plano
+type JobsConfig struct {
+	Workers        int    // Jobs executados ao mesmo tempo por instância
+	ResultDir      string // Diretório dos arquivos gerados; compartilhado entre as instâncias
+	RetentionHours int    // Por quanto tempo os jobs terminados e os seus arquivos são guardados
+}
+
+// CategoriesConfig contém configurações das categorias dos produtos
+type CategoriesConfig struct {
+	Strict bool // Rejeita produtos com categorias não cadastradas em vez de cadastrá-las
+}
+
 // Load carrega as configurações das variáveis de ambiente
 func Load() *Config {
 	return &Config{
@@ -37,11 +76,34 @@ func Load() *Config {
 			Password: getEnv("DB_PASSWORD", "alderaan123"),
 			DBName:   getEnv("DB_NAME", "alderaan_db"),
 			SSLMode:  getEnv("DB_SSLMODE", "disable"),
+
+			ReadTimeoutMs:  getEnvAsInt("DB_READ_TIMEOUT_MS", 5000),
+			WriteTimeoutMs: getEnvAsInt("DB_WRITE_TIMEOUT_MS", 10000),
 		},
 		Server: ServerConfig{
 			Port:    getEnv("SERVER_PORT", "8080"),
 			GinMode: getEnv("GIN_MODE", "debug"),
 		},
+		Events: EventsConfig{
+			Workers:     getEnvAsInt("EVENTS_WORKERS", 8),
+			QueueSize:   getEnvAsInt("EVENTS_QUEUE_SIZE", 1000),
+			QueuePolicy: getEnv("EVENTS_QUEUE_POLICY", "block"),
+		},
+		Webhooks: WebhooksConfig{
+			TimeoutSeconds: getEnvAsInt("WEBHOOKS_TIMEOUT_SECONDS", 5),
+			MaxAttempts:    getEnvAsInt("WEBHOOKS_MAX_ATTEMPTS", 5),
+		},
+		Idempotency: IdempotencyConfig{
+			TTLSeconds: getEnvAsInt("IDEMPOTENCY_TTL_SECONDS", 86400),
+		},
+		Jobs: JobsConfig{
+			Workers:        getEnvAsInt("JOBS_WORKERS", 2),
+			ResultDir:      getEnv("JOBS_RESULT_DIR", filepath.Join(os.TempDir(), "alderaan-jobs")),
+			RetentionHours: getEnvAsInt("JOBS_RETENTION_HOURS", 24),
+		},
+		Categories: CategoriesConfig{
+			Strict: getEnvAsBool("CATEGORIES_STRICT", false),
+		},
 	}
 }
 
@@ -62,3 +124,13 @@ func getEnvAsInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+// getEnvAsBool retorna o valor da variável de ambiente como bool ou um valor padrão
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
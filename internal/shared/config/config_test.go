@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -10,7 +11,10 @@ func TestLoad(t *testing.T) {
 	originalEnv := make(map[string]string)
 	envVars := []string{
 		"DB_HOST", "DB_PORT", "DB_USER", "DB_PASSWORD",
-		"DB_NAME", "DB_SSLMODE", "SERVER_PORT",
+		"DB_NAME", "DB_SSLMODE", "DB_READ_TIMEOUT_MS", "DB_WRITE_TIMEOUT_MS", "SERVER_PORT",
+		"EVENTS_WORKERS", "EVENTS_QUEUE_SIZE", "EVENTS_QUEUE_POLICY",
+		"WEBHOOKS_TIMEOUT_SECONDS", "WEBHOOKS_MAX_ATTEMPTS", "IDEMPOTENCY_TTL_SECONDS",
+		"JOBS_WORKERS", "JOBS_RESULT_DIR", "JOBS_RETENTION_HOURS", "CATEGORIES_STRICT",
 	}
 
 	for _, key := range envVars {
@@ -36,7 +40,19 @@ func TestLoad(t *testing.T) {
 		os.Setenv("DB_PASSWORD", "testpass")
 		os.Setenv("DB_NAME", "testdb")
 		os.Setenv("DB_SSLMODE", "require")
+		os.Setenv("DB_READ_TIMEOUT_MS", "1500")
+		os.Setenv("DB_WRITE_TIMEOUT_MS", "0")
 		os.Setenv("SERVER_PORT", "9090")
+		os.Setenv("EVENTS_WORKERS", "4")
+		os.Setenv("EVENTS_QUEUE_SIZE", "50")
+		os.Setenv("EVENTS_QUEUE_POLICY", "drop")
+		os.Setenv("WEBHOOKS_TIMEOUT_SECONDS", "2")
+		os.Setenv("WEBHOOKS_MAX_ATTEMPTS", "3")
+		os.Setenv("IDEMPOTENCY_TTL_SECONDS", "600")
+		os.Setenv("JOBS_WORKERS", "4")
+		os.Setenv("JOBS_RESULT_DIR", "/data/jobs")
+		os.Setenv("JOBS_RETENTION_HOURS", "48")
+		os.Setenv("CATEGORIES_STRICT", "true")
 
 		cfg := Load()
 
@@ -58,9 +74,27 @@ func TestLoad(t *testing.T) {
 		if cfg.Database.SSLMode != "require" {
 			t.Errorf("DB_SSLMODE = %v, want require", cfg.Database.SSLMode)
 		}
+		if cfg.Database.ReadTimeoutMs != 1500 || cfg.Database.WriteTimeoutMs != 0 {
+			t.Errorf("DB timeouts = %d/%d, want 1500/0", cfg.Database.ReadTimeoutMs, cfg.Database.WriteTimeoutMs)
+		}
 		if cfg.Server.Port != "9090" {
 			t.Errorf("SERVER_PORT = %v, want 9090", cfg.Server.Port)
 		}
+		if cfg.Events.Workers != 4 || cfg.Events.QueueSize != 50 || cfg.Events.QueuePolicy != "drop" {
+			t.Errorf("Events = %+v, want {4 50 drop}", cfg.Events)
+		}
+		if cfg.Webhooks.TimeoutSeconds != 2 || cfg.Webhooks.MaxAttempts != 3 {
+			t.Errorf("Webhooks = %+v, want {2 3}", cfg.Webhooks)
+		}
+		if cfg.Idempotency.TTLSeconds != 600 {
+			t.Errorf("IDEMPOTENCY_TTL_SECONDS = %v, want 600", cfg.Idempotency.TTLSeconds)
+		}
+		if cfg.Jobs.Workers != 4 || cfg.Jobs.ResultDir != "/data/jobs" || cfg.Jobs.RetentionHours != 48 {
+			t.Errorf("Jobs = %+v, want {4 /data/jobs 48}", cfg.Jobs)
+		}
+		if !cfg.Categories.Strict {
+			t.Errorf("CATEGORIES_STRICT = %v, want true", cfg.Categories.Strict)
+		}
 	})
 
 	t.Run("load with default values", func(t *testing.T) {
@@ -90,9 +124,30 @@ func TestLoad(t *testing.T) {
 		if cfg.Database.SSLMode != "disable" {
 			t.Errorf("default DB_SSLMODE = %v, want disable", cfg.Database.SSLMode)
 		}
+		if cfg.Database.ReadTimeoutMs != 5000 || cfg.Database.WriteTimeoutMs != 10000 {
+			t.Errorf("default DB timeouts = %d/%d, want 5000/10000", cfg.Database.ReadTimeoutMs, cfg.Database.WriteTimeoutMs)
+		}
 		if cfg.Server.Port != "8080" {
 			t.Errorf("default SERVER_PORT = %v, want 8080", cfg.Server.Port)
 		}
+		if cfg.Events.Workers != 8 || cfg.Events.QueueSize != 1000 || cfg.Events.QueuePolicy != "block" {
+			t.Errorf("default Events = %+v, want {8 1000 block}", cfg.Events)
+		}
+		if cfg.Webhooks.TimeoutSeconds != 5 || cfg.Webhooks.MaxAttempts != 5 {
+			t.Errorf("default Webhooks = %+v, want {5 5}", cfg.Webhooks)
+		}
+		if cfg.Idempotency.TTLSeconds != 86400 {
+			t.Errorf("default IDEMPOTENCY_TTL_SECONDS = %v, want 86400", cfg.Idempotency.TTLSeconds)
+		}
+		if cfg.Jobs.Workers != 2 || cfg.Jobs.RetentionHours != 24 {
+			t.Errorf("default Jobs = %+v, want {2 _ 24}", cfg.Jobs)
+		}
+		if want := filepath.Join(os.TempDir(), "alderaan-jobs"); cfg.Jobs.ResultDir != want {
+			t.Errorf("default JOBS_RESULT_DIR = %v, want %v", cfg.Jobs.ResultDir, want)
+		}
+		if cfg.Categories.Strict {
+			t.Errorf("default CATEGORIES_STRICT = %v, want false", cfg.Categories.Strict)
+		}
 	})
 
 	t.Run("load with partial environment variables", func(t *testing.T) {
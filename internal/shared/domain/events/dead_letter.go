@@ -0,0 +1,117 @@
+package shared_events
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+var ErrDeadLetterNotFound = errors.New("dead letter not found")
+
+// DeadLetter é um evento cujo handler esgotou as tentativas
+type DeadLetter struct {
+	ID          string    `json:"id"`
+	EventName   string    `json:"event_name"`
+	HandlerName string    `json:"handler"`
+	Event       Event     `json:"event"`
+	Error       string    `json:"error"`
+	Attempts    int       `json:"attempts"`
+	FailedAt    time.Time `json:"failed_at"`
+}
+
+// DeadLetterStore guarda os eventos que falharam para consulta e replay
+type DeadLetterStore interface {
+	// Save inclui o registro ou substitui o registro com o mesmo ID
+	Save(entry DeadLetter) error
+	// List retorna os registros do mais antigo para o mais recente
+	List() ([]DeadLetter, error)
+	Get(id string) (DeadLetter, error)
+	Delete(id string) error
+}
+
+// DefaultDeadLetterCapacity é a capacidade da store criada por NewEventDispatcher
+const DefaultDeadLetterCapacity = 1000
+
+// InMemoryDeadLetterStore guarda até capacity registros; ao lotar, descarta o mais antigo
+type InMemoryDeadLetterStore struct {
+	entries  map[string]DeadLetter
+	capacity int
+	mu       sync.RWMutex
+}
+
+func NewInMemoryDeadLetterStore(capacity int) *InMemoryDeadLetterStore {
+	return &InMemoryDeadLetterStore{
+		entries:  make(map[string]DeadLetter),
+		capacity: capacity,
+	}
+}
+
+func (s *InMemoryDeadLetterStore) Save(entry DeadLetter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.entries[entry.ID]; !exists && s.capacity > 0 && len(s.entries) >= s.capacity {
+		delete(s.entries, s.oldest())
+	}
+	s.entries[entry.ID] = entry
+
+	return nil
+}
+
+func (s *InMemoryDeadLetterStore) List() ([]DeadLetter, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries := make([]DeadLetter, 0, len(s.entries))
+	for _, entry := range s.entries {
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].FailedAt.Equal(entries[j].FailedAt) {
+			return entries[i].FailedAt.Before(entries[j].FailedAt)
+		}
+		return entries[i].ID < entries[j].ID
+	})
+
+	return entries, nil
+}
+
+func (s *InMemoryDeadLetterStore) Get(id string) (DeadLetter, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entry, exists := s.entries[id]
+	if !exists {
+		return DeadLetter{}, ErrDeadLetterNotFound
+	}
+
+	return entry, nil
+}
+
+func (s *InMemoryDeadLetterStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.entries[id]; !exists {
+		return ErrDeadLetterNotFound
+	}
+	delete(s.entries, id)
+
+	return nil
+}
+
+// oldest retorna o ID do registro com falha mais antiga; deve ser chamado com o lock
+func (s *InMemoryDeadLetterStore) oldest() string {
+	var (
+		oldestID string
+		oldestAt time.Time
+	)
+	for id, entry := range s.entries {
+		if oldestID == "" || entry.FailedAt.Before(oldestAt) {
+			oldestID, oldestAt = id, entry.FailedAt
+		}
+	}
+	return oldestID
+}
@@ -0,0 +1,51 @@
+package shared_events
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInMemoryDeadLetterStore(t *testing.T) {
+	store := NewInMemoryDeadLetterStore(2)
+	base := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	for i, id := range []string{"a", "b", "c"} {
+		entry := DeadLetter{ID: id, EventName: "test.event", FailedAt: base.Add(time.Duration(i) * time.Minute)}
+		if err := store.Save(entry); err != nil {
+			t.Fatalf("Save(%s) error = %v", id, err)
+		}
+	}
+
+	t.Run("evicts the oldest entry when full", func(t *testing.T) {
+		entries, _ := store.List()
+		if len(entries) != 2 || entries[0].ID != "b" || entries[1].ID != "c" {
+			t.Errorf("List() = %+v, want [b c]", entries)
+		}
+	})
+
+	t.Run("save with existing id replaces the entry", func(t *testing.T) {
+		if err := store.Save(DeadLetter{ID: "b", Attempts: 7, FailedAt: base.Add(time.Hour)}); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+
+		entry, err := store.Get("b")
+		if err != nil || entry.Attempts != 7 {
+			t.Errorf("Get(b) = %+v, %v; want Attempts 7", entry, err)
+		}
+		if entries, _ := store.List(); len(entries) != 2 {
+			t.Errorf("List() count = %d, want 2", len(entries))
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		if err := store.Delete("c"); err != nil {
+			t.Fatalf("Delete(c) error = %v", err)
+		}
+		if err := store.Delete("c"); err != ErrDeadLetterNotFound {
+			t.Errorf("Delete(c) again error = %v, want %v", err, ErrDeadLetterNotFound)
+		}
+		if _, err := store.Get("c"); err != ErrDeadLetterNotFound {
+			t.Errorf("Get(c) error = %v, want %v", err, ErrDeadLetterNotFound)
+		}
+	})
+}
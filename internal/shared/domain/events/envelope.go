@@ -0,0 +1,92 @@
+package shared_events
+
+import (
+	"time"
+
+	shared_identity "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/identity"
+)
+
+// VersionedEvent é um evento que declara a versão do seu schema.
+// Eventos que não implementam a interface estão na versão 1.
+type VersionedEvent interface {
+	Event
+	SchemaVersion() int
+}
+
+// Envelope embrulha um evento com os metadados usados para deduplicá-lo e rastreá-lo.
+// É o que os handlers recebem: Dispatch embrulha todo evento que ainda não for um Envelope.
+type Envelope struct {
+	ID            string    `json:"id"`
+	Name          string    `json:"name"`
+	Version       int       `json:"version"`
+	AggregateID   string    `json:"aggregate_id,omitempty"`
+	CorrelationID string    `json:"correlation_id,omitempty"`
+	OccurredAt    time.Time `json:"occurred_at"`
+	Payload       Event     `json:"payload"`
+
+	// Sequence é a posição global do evento no EventStore (0 enquanto não foi gravado)
+	Sequence int64 `json:"sequence,omitempty"`
+}
+
+// NewEnvelope cria um envelope com ID e data novos para o evento
+func NewEnvelope(event Event) *Envelope {
+	envelope := &Envelope{
+		ID:         shared_identity.NewUUID(),
+		Name:       event.EventName(),
+		Version:    schemaVersion(event),
+		OccurredAt: time.Now().UTC(),
+		Payload:    event,
+	}
+
+	if aggregate, ok := event.(AggregateEvent); ok {
+		envelope.AggregateID = aggregate.AggregateID()
+	}
+
+	return envelope
+}
+
+func (e *Envelope) EventName() string {
+	return e.Name
+}
+
+// Wrap retorna o evento como Envelope, criando um se ele ainda não estiver embrulhado
+func Wrap(event Event) *Envelope {
+	if envelope, ok := event.(*Envelope); ok {
+		return envelope
+	}
+	return NewEnvelope(event)
+}
+
+// Unwrap retorna o evento original de um Envelope; outros eventos são retornados como estão
+func Unwrap(event Event) Event {
+	if envelope, ok := event.(*Envelope); ok {
+		return envelope.Payload
+	}
+	return event
+}
+
+// WithCorrelationID embrulha os eventos e associa o ID de correlação aos que ainda não têm um.
+// Eventos nulos são descartados.
+func WithCorrelationID(correlationID string, events ...Event) []Event {
+	wrapped := make([]Event, 0, len(events))
+	for _, event := range events {
+		if event == nil {
+			continue
+		}
+
+		envelope := Wrap(event)
+		if envelope.CorrelationID == "" {
+			envelope.CorrelationID = correlationID
+		}
+		wrapped = append(wrapped, envelope)
+	}
+
+	return wrapped
+}
+
+func schemaVersion(event Event) int {
+	if versioned, ok := event.(VersionedEvent); ok {
+		return versioned.SchemaVersion()
+	}
+	return 1
+}
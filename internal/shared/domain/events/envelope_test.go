@@ -0,0 +1,95 @@
+package shared_events
+
+import (
+	"testing"
+
+	shared_identity "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/identity"
+)
+
+type aggregateTestEvent struct {
+	ID string `json:"id"`
+}
+
+func (e *aggregateTestEvent) EventName() string   { return "aggregate.changed" }
+func (e *aggregateTestEvent) AggregateID() string { return e.ID }
+func (e *aggregateTestEvent) SchemaVersion() int  { return 3 }
+
+func TestNewEnvelope(t *testing.T) {
+	t.Run("plain event", func(t *testing.T) {
+		event := &mockEvent{name: "test.event"}
+		envelope := NewEnvelope(event)
+
+		if !shared_identity.IsValidUUID(envelope.ID) {
+			t.Errorf("ID = %q, want a uuid", envelope.ID)
+		}
+		if envelope.Name != "test.event" || envelope.EventName() != "test.event" {
+			t.Errorf("Name = %q, EventName() = %q; want test.event", envelope.Name, envelope.EventName())
+		}
+		if envelope.Version != 1 || envelope.AggregateID != "" || envelope.OccurredAt.IsZero() {
+			t.Errorf("envelope = %+v, want version 1, no aggregate and an occurrence date", envelope)
+		}
+		if envelope.Payload != event {
+			t.Errorf("Payload = %v, want the original event", envelope.Payload)
+		}
+	})
+
+	t.Run("aggregate and versioned event", func(t *testing.T) {
+		envelope := NewEnvelope(&aggregateTestEvent{ID: "agg-1"})
+
+		if envelope.AggregateID != "agg-1" || envelope.Version != 3 {
+			t.Errorf("AggregateID = %q, Version = %d; want agg-1 and 3", envelope.AggregateID, envelope.Version)
+		}
+	})
+}
+
+func TestWrapAndUnwrap(t *testing.T) {
+	event := &mockEvent{name: "test.event"}
+	envelope := Wrap(event)
+
+	if Wrap(envelope) != envelope {
+		t.Error("Wrap() of an envelope should return the same envelope")
+	}
+	if Unwrap(envelope) != event {
+		t.Error("Unwrap() should return the original event")
+	}
+	if Unwrap(event) != event {
+		t.Error("Unwrap() of a plain event should return it unchanged")
+	}
+}
+
+func TestWithCorrelationID(t *testing.T) {
+	existing := NewEnvelope(&mockEvent{name: "b"})
+	existing.CorrelationID = "upstream"
+
+	events := WithCorrelationID("req-1", &mockEvent{name: "a"}, nil, existing)
+
+	if len(events) != 2 {
+		t.Fatalf("WithCorrelationID() returned %d events, want 2", len(events))
+	}
+	if got := events[0].(*Envelope).CorrelationID; got != "req-1" {
+		t.Errorf("events[0] correlation = %q, want req-1", got)
+	}
+	if got := events[1].(*Envelope).CorrelationID; got != "upstream" {
+		t.Errorf("events[1] correlation = %q, want upstream", got)
+	}
+}
+
+func TestEventDispatcher_DispatchWrapsEvents(t *testing.T) {
+	dispatcher := NewEventDispatcher()
+
+	received := make(chan Event, 2)
+	dispatcher.Register("test.event", func(event Event) {
+		received <- event
+	})
+
+	original := NewEnvelope(&mockEvent{name: "test.event"})
+	dispatcher.DispatchAndWait("test.event", &mockEvent{name: "test.event"})
+	dispatcher.DispatchAndWait("test.event", original)
+
+	if envelope, ok := (<-received).(*Envelope); !ok || envelope.ID == "" {
+		t.Errorf("handler should receive a new envelope, got %+v", envelope)
+	}
+	if got := <-received; got != original {
+		t.Errorf("handler should receive the original envelope, got %+v", got)
+	}
+}
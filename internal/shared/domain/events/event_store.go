@@ -0,0 +1,111 @@
+package shared_events
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+)
+
+var ErrEventStoreNotConfigured = errors.New("event store not configured")
+
+// EventStore guarda o histórico de eventos em ordem global de sequência
+type EventStore interface {
+	// Append grava o envelope e retorna a sequência atribuída. Um envelope com ID
+	// já gravado não é duplicado: a sequência original é retornada.
+	Append(ctx context.Context, envelope *Envelope) (int64, error)
+
+	// ReadFrom retorna até limit eventos com sequência >= sequence, em ordem, e a
+	// sequência de onde a próxima leitura deve continuar; next igual a sequence
+	// indica que não há mais eventos. Eventos que não podem ser lidos são pulados.
+	ReadFrom(ctx context.Context, sequence int64, limit int) (events []*Envelope, next int64, err error)
+}
+
+// InMemoryEventStore guarda o histórico em memória, sem limite de tamanho.
+// Adequado para desenvolvimento e testes: o histórico se perde ao reiniciar.
+type InMemoryEventStore struct {
+	mu     sync.RWMutex
+	events []*Envelope
+	byID   map[string]int64
+}
+
+func NewInMemoryEventStore() *InMemoryEventStore {
+	return &InMemoryEventStore{byID: make(map[string]int64)}
+}
+
+func (s *InMemoryEventStore) Append(ctx context.Context, envelope *Envelope) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if sequence, ok := s.byID[envelope.ID]; ok {
+		return sequence, nil
+	}
+
+	stored := *envelope
+	stored.Sequence = int64(len(s.events)) + 1
+	s.events = append(s.events, &stored)
+	s.byID[stored.ID] = stored.Sequence
+
+	return stored.Sequence, nil
+}
+
+func (s *InMemoryEventStore) ReadFrom(ctx context.Context, sequence int64, limit int) ([]*Envelope, int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if sequence < 1 {
+		sequence = 1
+	}
+	if sequence > int64(len(s.events)) {
+		return nil, sequence, nil
+	}
+
+	end := sequence - 1 + int64(limit)
+	if limit <= 0 || end > int64(len(s.events)) {
+		end = int64(len(s.events))
+	}
+
+	events := make([]*Envelope, 0, end-sequence+1)
+	for _, stored := range s.events[sequence-1 : end] {
+		envelope := *stored
+		events = append(events, &envelope)
+	}
+
+	return events, end + 1, nil
+}
+
+// SetEventStore define onde os eventos despachados são gravados; nil desativa o histórico
+func (d *EventDispatcher) SetEventStore(store EventStore) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.store = store
+}
+
+// EventStore retorna o histórico de eventos, ou nil se não configurado
+func (d *EventDispatcher) EventStore() EventStore {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return d.store
+}
+
+// record grava o envelope no EventStore e preenche a sequência. Envelopes já gravados
+// (como os reprocessados da dead-letter store) não são gravados de novo. Uma falha na
+// gravação não impede a entrega aos handlers. Dispatch não recebe um ctx, então a
+// gravação é limitada só pelo timeout do próprio EventStore.
+func (d *EventDispatcher) record(envelope *Envelope) *Envelope {
+	store := d.EventStore()
+	if store == nil || envelope.Sequence != 0 {
+		return envelope
+	}
+
+	sequence, err := store.Append(context.Background(), envelope)
+	if err != nil {
+		log.Printf("❌ Erro ao gravar evento %s (%s) no histórico: %v", envelope.Name, envelope.ID, err)
+		return envelope
+	}
+
+	envelope.Sequence = sequence
+	return envelope
+}
@@ -0,0 +1,110 @@
+package shared_events
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestInMemoryEventStore(t *testing.T) {
+	store := NewInMemoryEventStore()
+
+	first := NewEnvelope(&mockEvent{name: "a"})
+	for i, envelope := range []*Envelope{first, NewEnvelope(&mockEvent{name: "b"}), NewEnvelope(&mockEvent{name: "c"})} {
+		sequence, err := store.Append(context.Background(), envelope)
+		if err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+		if sequence != int64(i+1) {
+			t.Errorf("Append() sequence = %d, want %d", sequence, i+1)
+		}
+	}
+
+	t.Run("duplicate id keeps the original sequence", func(t *testing.T) {
+		sequence, err := store.Append(context.Background(), first)
+		if err != nil || sequence != 1 {
+			t.Errorf("Append(duplicate) = %d, %v; want 1", sequence, err)
+		}
+		if first.Sequence != 0 {
+			t.Errorf("Append() should not modify the envelope, got sequence %d", first.Sequence)
+		}
+	})
+
+	tests := []struct {
+		name      string
+		from      int64
+		limit     int
+		wantNames []string
+		wantNext  int64
+	}{
+		{name: "first page", from: 1, limit: 2, wantNames: []string{"a", "b"}, wantNext: 3},
+		{name: "last page", from: 3, limit: 2, wantNames: []string{"c"}, wantNext: 4},
+		{name: "end of history", from: 4, limit: 2, wantNext: 4},
+		{name: "zero is the beginning", from: 0, limit: 0, wantNames: []string{"a", "b", "c"}, wantNext: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, next, err := store.ReadFrom(context.Background(), tt.from, tt.limit)
+			if err != nil {
+				t.Fatalf("ReadFrom() error = %v", err)
+			}
+			if next != tt.wantNext || len(events) != len(tt.wantNames) {
+				t.Fatalf("ReadFrom() = %d events, next %d; want %d events, next %d", len(events), next, len(tt.wantNames), tt.wantNext)
+			}
+			for i, envelope := range events {
+				if envelope.Name != tt.wantNames[i] || envelope.Sequence == 0 {
+					t.Errorf("events[%d] = %s #%d, want %s", i, envelope.Name, envelope.Sequence, tt.wantNames[i])
+				}
+			}
+		})
+	}
+}
+
+// failingEventStore simula um banco indisponível
+type failingEventStore struct{}
+
+func (failingEventStore) Append(context.Context, *Envelope) (int64, error) {
+	return 0, errors.New("db down")
+}
+func (failingEventStore) ReadFrom(context.Context, int64, int) ([]*Envelope, int64, error) {
+	return nil, 0, errors.New("db down")
+}
+
+func TestEventDispatcher_RecordsEvents(t *testing.T) {
+	dispatcher := NewEventDispatcher()
+	store := NewInMemoryEventStore()
+	dispatcher.SetEventStore(store)
+
+	received := make(chan *Envelope, 1)
+	dispatcher.Register("handled.event", func(event Event) {
+		received <- event.(*Envelope)
+	})
+
+	// Eventos sem handlers também entram no histórico
+	if err := dispatcher.Dispatch("unhandled.event", &mockEvent{name: "unhandled.event"}); err != nil {
+		t.Fatalf("Dispatch() error = %v", err)
+	}
+	dispatcher.DispatchAndWait("handled.event", &mockEvent{name: "handled.event"})
+
+	envelope := <-received
+	if envelope.Sequence != 2 {
+		t.Errorf("handler received sequence %d, want 2", envelope.Sequence)
+	}
+
+	// Um envelope já gravado não é gravado de novo
+	dispatcher.DispatchAndWait("handled.event", envelope)
+	<-received
+	if events, _, _ := store.ReadFrom(context.Background(), 1, 0); len(events) != 2 {
+		t.Errorf("history has %d events, want 2", len(events))
+	}
+
+	t.Run("store failure does not block delivery", func(t *testing.T) {
+		dispatcher.SetEventStore(failingEventStore{})
+		dispatcher.DispatchAndWait("handled.event", &mockEvent{name: "handled.event"})
+
+		if envelope := <-received; envelope.Sequence != 0 {
+			t.Errorf("sequence = %d, want 0", envelope.Sequence)
+		}
+	})
+}
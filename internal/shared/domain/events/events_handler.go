@@ -1,7 +1,11 @@
 package shared_events
 
 import (
+	"context"
 	"fmt"
+	"log"
+	"path"
+	"sort"
 	"sync"
 )
 
@@ -9,35 +13,251 @@ type Event interface {
 	EventName() string
 }
 
+// AggregateEvent é um evento que pertence a um agregado identificado.
+// O ID do agregado é usado para manter a ordem de entrega dos eventos.
+type AggregateEvent interface {
+	Event
+	AggregateID() string
+}
+
 type EventHandler func(event Event)
 
+// EventFactory cria uma instância vazia de um evento, usada para desserializá-lo
+type EventFactory func() Event
+
+// subscription é um handler registrado; o id preserva a ordem de registro
+type subscription struct {
+	id      uint64
+	handler EventHandler
+}
+
 type EventDispatcher struct {
-	handlers map[string][]EventHandler
-	mu       sync.RWMutex
+	// Handlers indexados pelo padrão registrado: um nome exato ou um glob como "product.*"
+	handlers      map[string][]subscription
+	retryHandlers map[string]retryHandler
+	deadLetters   DeadLetterStore
+	store         EventStore
+	projections   map[string]*projectionRunner
+	nextID        uint64
+	debugLog      *log.Logger
+	mu            sync.RWMutex
+
+	// Pool de workers; nil no modo sem limite, em que cada handler roda na própria goroutine
+	pool *workerPool
+
+	// Tentativas dos handlers com retry aguardando o backoff
+	retries retryQueue
+
+	// closed é protegido por stateMu, que Dispatch segura só para conferir o estado e
+	// contar o evento em inFlight; stop é fechado por Shutdown e libera os envios
+	// bloqueados na fila e as esperas entre tentativas
+	stateMu      sync.RWMutex
+	closed       bool
+	inFlight     sync.WaitGroup
+	stop         chan struct{}
+	drained      chan struct{}
+	shutdownOnce sync.Once
 }
 
+// NewEventDispatcher cria um dispatcher sem limite: cada handler roda em uma nova goroutine
 func NewEventDispatcher() *EventDispatcher {
 	return &EventDispatcher{
-		handlers: make(map[string][]EventHandler),
+		handlers:      make(map[string][]subscription),
+		retryHandlers: make(map[string]retryHandler),
+		deadLetters:   NewInMemoryDeadLetterStore(DefaultDeadLetterCapacity),
+		projections:   make(map[string]*projectionRunner),
+		retries:       retryQueue{pending: make(map[*pendingRetry]struct{})},
+		stop:          make(chan struct{}),
 	}
 }
 
-func (d *EventDispatcher) Register(eventName string, handler EventHandler) {
+// Register registra um handler para os eventos cujo nome casa com o padrão.
+// O padrão é um nome exato ("product.created") ou um glob ("product.*", "*"),
+// com a sintaxe de path.Match. Um padrão malformado causa panic.
+// Handlers de um evento rodam na ordem de registro, independentemente do padrão.
+func (d *EventDispatcher) Register(pattern string, handler EventHandler) *Subscription {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
-	d.handlers[eventName] = append(d.handlers[eventName], handler)
+	return d.subscribe(pattern, handler)
 }
 
-func (d *EventDispatcher) Dispatch(eventName string, event Event) {
+// subscribe adiciona o handler ao padrão; exige o lock de escrita
+func (d *EventDispatcher) subscribe(pattern string, handler EventHandler) *Subscription {
+	if err := ValidatePattern(pattern); err != nil {
+		panic(fmt.Sprintf("padrão de evento inválido %q: %v", pattern, err))
+	}
+
+	d.nextID++
+	d.handlers[pattern] = append(d.handlers[pattern], subscription{id: d.nextID, handler: handler})
+
+	return &Subscription{dispatcher: d, pattern: pattern, id: d.nextID}
+}
+
+// SetDebugLogger define o logger das mensagens de diagnóstico, como eventos sem handlers.
+// Com nil (o padrão) essas mensagens são descartadas.
+func (d *EventDispatcher) SetDebugLogger(logger *log.Logger) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.debugLog = logger
+}
+
+// handlersFor retorna os handlers cujo padrão casa com o nome do evento, em ordem de registro
+func (d *EventDispatcher) handlersFor(eventName string) []EventHandler {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
 
-	if handlers, ok := d.handlers[eventName]; ok {
-		for _, handler := range handlers {
-			go handler(event)
+	var matched []subscription
+	for pattern, subs := range d.handlers {
+		if MatchPattern(pattern, eventName) {
+			matched = append(matched, subs...)
+		}
+	}
+	if len(matched) == 0 {
+		if d.debugLog != nil {
+			d.debugLog.Printf("Event dispatched: %s (no handlers registered)", eventName)
+		}
+		return nil
+	}
+
+	sort.Slice(matched, func(i, j int) bool { return matched[i].id < matched[j].id })
+
+	handlers := make([]EventHandler, len(matched))
+	for i, sub := range matched {
+		handlers[i] = sub.handler
+	}
+	return handlers
+}
+
+// ValidatePattern verifica se o padrão é um nome de evento ou um glob válido
+func ValidatePattern(pattern string) error {
+	_, err := path.Match(pattern, "")
+	return err
+}
+
+// MatchPattern indica se o nome do evento casa com o padrão (nome exato ou glob)
+func MatchPattern(pattern, eventName string) bool {
+	if pattern == eventName {
+		return true
+	}
+	ok, _ := path.Match(pattern, eventName)
+	return ok
+}
+
+// Dispatch entrega o evento aos handlers de forma assíncrona, embrulhado em um Envelope.
+// Com um EventStore configurado, o evento é gravado no histórico antes da entrega.
+// Com pool de workers, a fila cheia é tratada conforme a QueuePolicy configurada:
+// ErrQueueFull só é retornado pela política QueuePolicyError.
+// Após Shutdown, retorna ErrDispatcherClosed, inclusive para um envio que esperava
+// espaço na fila.
+func (d *EventDispatcher) Dispatch(eventName string, event Event) error {
+	return d.dispatch(eventName, event, nil)
+}
+
+// DispatchAndWait executa os handlers do evento, embrulhado em um Envelope, e só retorna quando todos terminarem.
+// Dos handlers com retry, só a primeira tentativa é esperada; as demais são agendadas.
+// Usado por quem precisa entregar eventos em ordem, como o relay do outbox. O evento passa
+// pelo pool e é esperado por Shutdown como os demais; com a fila cheia, a chamada espera
+// espaço qualquer que seja a QueuePolicy, para que o evento não seja descartado.
+// Após Shutdown, retorna ErrDispatcherClosed sem executar os handlers.
+func (d *EventDispatcher) DispatchAndWait(eventName string, event Event) error {
+	done := make(chan struct{})
+	if err := d.dispatch(eventName, event, done); err != nil {
+		return err
+	}
+
+	<-done
+	return nil
+}
+
+// dispatch conta o evento em inFlight e o entrega aos handlers; done, quando informado,
+// é fechado depois que todos os handlers terminarem
+func (d *EventDispatcher) dispatch(eventName string, event Event, done chan struct{}) error {
+	if !d.accept() {
+		return ErrDispatcherClosed
+	}
+
+	event = d.record(Wrap(event))
+
+	handlers := d.handlersFor(eventName)
+	if len(handlers) == 0 {
+		d.inFlight.Done()
+		if done != nil {
+			close(done)
+		}
+		return nil
+	}
+
+	if d.pool != nil {
+		err := d.pool.submit(eventName, job{event: event, handlers: handlers, done: done, finish: d.inFlight.Done}, done != nil, d.stop)
+		if err != nil {
+			d.inFlight.Done()
 		}
-	} else {
-		fmt.Printf("Event dispatched: %s (no handlers registered)\n", eventName)
+		return err
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(len(handlers))
+	for _, handler := range handlers {
+		go func(handler EventHandler) {
+			defer wg.Done()
+			runHandler(handler, event)
+		}(handler)
+	}
+	go func() {
+		wg.Wait()
+		d.inFlight.Done()
+		if done != nil {
+			close(done)
+		}
+	}()
+
+	return nil
+}
+
+// accept conta um novo evento em inFlight, a menos que o dispatcher esteja encerrado.
+// O lock garante que nenhum evento seja contado depois que Shutdown começa a esperar.
+func (d *EventDispatcher) accept() bool {
+	d.stateMu.RLock()
+	defer d.stateMu.RUnlock()
+
+	if d.closed {
+		return false
+	}
+	d.inFlight.Add(1)
+	return true
+}
+
+// Shutdown recusa novos eventos e espera os já aceitos serem processados,
+// incluindo os que ainda estão na fila, respeitando o prazo do ctx.
+// Envios que esperavam espaço na fila são recusados e as esperas entre tentativas
+// dos handlers com retry são interrompidas.
+func (d *EventDispatcher) Shutdown(ctx context.Context) error {
+	d.shutdownOnce.Do(func() {
+		d.stateMu.Lock()
+		d.closed = true
+		d.stateMu.Unlock()
+		close(d.stop)
+
+		d.drained = make(chan struct{})
+		go func() {
+			d.abortRetries()
+
+			// Depois de inFlight zerar ninguém mais envia para a fila, que pode ser fechada
+			d.inFlight.Wait()
+			if d.pool != nil {
+				d.pool.close()
+				d.pool.wait()
+			}
+			close(d.drained)
+		}()
+	})
+
+	select {
+	case <-d.drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
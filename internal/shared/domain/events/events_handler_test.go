@@ -114,7 +114,7 @@ func TestEventDispatcher_Dispatch(t *testing.T) {
 		dispatcher := NewEventDispatcher()
 		event := &mockEvent{name: "unregistered.event"}
 
-		// Deve apenas printar mensagem, não causar panic
+		// Vai apenas para o logger de debug, sem causar panic
 		dispatcher.Dispatch("unregistered.event", event)
 	})
 
@@ -252,3 +252,53 @@ func BenchmarkEventDispatcher_RegisterAndDispatch(b *testing.B) {
 		dispatcher.Dispatch("bench.event", event)
 	}
 }
+
+func TestEventDispatcher_DispatchAndWait(t *testing.T) {
+	t.Run("waits for every handler", func(t *testing.T) {
+		dispatcher := NewEventDispatcher()
+
+		var counter int32
+		for i := 0; i < 3; i++ {
+			dispatcher.Register("test.event", func(event Event) {
+				time.Sleep(10 * time.Millisecond)
+				atomic.AddInt32(&counter, 1)
+			})
+		}
+
+		dispatcher.DispatchAndWait("test.event", &mockEvent{name: "test.event"})
+
+		if got := atomic.LoadInt32(&counter); got != 3 {
+			t.Errorf("expected 3 handlers to finish before returning, got %d", got)
+		}
+	})
+
+	t.Run("no handlers registered", func(t *testing.T) {
+		dispatcher := NewEventDispatcher()
+		if err := dispatcher.DispatchAndWait("unknown.event", &mockEvent{name: "unknown.event"}); err != nil {
+			t.Errorf("DispatchAndWait() error = %v", err)
+		}
+	})
+
+	t.Run("waits for a free slot regardless of the queue policy", func(t *testing.T) {
+		dispatcher, release, handled := blockingDispatcher(t, DispatcherConfig{Workers: 1, QueueSize: 1, Policy: QueuePolicyDrop})
+
+		// Ocupa a fila: um Dispatch agora seria descartado
+		if err := dispatcher.Dispatch("test.event", &mockEvent{name: "test.event"}); err != nil {
+			t.Fatalf("Dispatch() error = %v", err)
+		}
+
+		done := make(chan error, 1)
+		go func() {
+			done <- dispatcher.DispatchAndWait("test.event", &mockEvent{name: "test.event"})
+		}()
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+
+		if err := <-done; err != nil {
+			t.Fatalf("DispatchAndWait() error = %v", err)
+		}
+		if got := handled.Load(); got != 3 {
+			t.Errorf("handled = %d, want 3", got)
+		}
+	})
+}
@@ -0,0 +1,175 @@
+package shared_events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+)
+
+var ErrProjectionNotFound = errors.New("projection not found")
+
+// ReplayBatchSize é a quantidade de eventos lidos do EventStore por vez durante o replay
+const ReplayBatchSize = 500
+
+// Projection é um read model construído a partir dos eventos, que pode ser
+// reconstruído reaplicando o histórico do EventStore
+type Projection interface {
+	// Name identifica a projeção no replay
+	Name() string
+	// Reset descarta o estado para uma reconstrução completa
+	Reset() error
+	// Apply aplica um evento, recebido embrulhado em um Envelope
+	Apply(event Event) error
+}
+
+// projectionRunner entrega eventos a uma projeção, ao vivo ou pelo replay.
+// O mutex impede que eventos ao vivo sejam aplicados no meio de um replay;
+// replayedThrough evita aplicar duas vezes um evento que o replay já aplicou.
+type projectionRunner struct {
+	pattern         string
+	projection      Projection
+	mu              sync.Mutex
+	replayedThrough int64
+}
+
+// apply aplica um evento ao vivo, esperando um replay em andamento terminar
+func (r *projectionRunner) apply(event Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if envelope, ok := event.(*Envelope); ok && envelope.Sequence != 0 && envelope.Sequence <= r.replayedThrough {
+		return
+	}
+
+	if err := callErrorHandler(r.projection.Apply, event); err != nil {
+		log.Printf("❌ Erro ao aplicar evento %s na projeção %s: %v", event.EventName(), r.projection.Name(), err)
+	}
+}
+
+// RegisterProjection registra a projeção para os eventos que casam com o padrão.
+// Os eventos ao vivo chegam como em Register; o histórico é reaplicado com ReplayFrom.
+// O nome da projeção deve ser único no dispatcher.
+func (d *EventDispatcher) RegisterProjection(pattern string, projection Projection) *Subscription {
+	runner := &projectionRunner{pattern: pattern, projection: projection}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.projections[projection.Name()] = runner
+	return d.subscribe(pattern, runner.apply)
+}
+
+// Projections retorna os nomes das projeções registradas, em ordem alfabética
+func (d *EventDispatcher) Projections() []string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	names := make([]string, 0, len(d.projections))
+	for name := range d.projections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RebuildProjections descarta o estado das projeções informadas (todas, se nenhuma
+// for informada) e reaplica o histórico inteiro. Retorna quantos eventos foram reaplicados.
+func (d *EventDispatcher) RebuildProjections(ctx context.Context, names ...string) (int, error) {
+	return d.replay(ctx, 1, true, names)
+}
+
+// ReplayFrom reaplica às projeções informadas (todas, se nenhuma for informada) os
+// eventos do histórico a partir da sequência, sem descartar o estado atual.
+// Eventos ao vivo para essas projeções esperam o replay terminar.
+func (d *EventDispatcher) ReplayFrom(ctx context.Context, sequence int64, names ...string) (int, error) {
+	return d.replay(ctx, sequence, false, names)
+}
+
+func (d *EventDispatcher) replay(ctx context.Context, sequence int64, reset bool, names []string) (int, error) {
+	store := d.EventStore()
+	if store == nil {
+		return 0, ErrEventStoreNotConfigured
+	}
+
+	runners, err := d.projectionRunners(names)
+	if err != nil {
+		return 0, err
+	}
+
+	// Ordem alfabética de lock evita deadlock entre replays simultâneos
+	for _, runner := range runners {
+		runner.mu.Lock()
+		defer runner.mu.Unlock()
+	}
+
+	if reset {
+		for _, runner := range runners {
+			if err := runner.projection.Reset(); err != nil {
+				return 0, fmt.Errorf("erro ao limpar a projeção %s: %w", runner.projection.Name(), err)
+			}
+			runner.replayedThrough = 0
+		}
+	}
+
+	replayed := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return replayed, err
+		}
+
+		events, next, err := store.ReadFrom(ctx, sequence, ReplayBatchSize)
+		if err != nil {
+			return replayed, fmt.Errorf("erro ao ler o histórico de eventos: %w", err)
+		}
+
+		for _, envelope := range events {
+			for _, runner := range runners {
+				if !MatchPattern(runner.pattern, envelope.Name) {
+					continue
+				}
+				if err := callErrorHandler(runner.projection.Apply, envelope); err != nil {
+					return replayed, fmt.Errorf("erro ao aplicar o evento #%d na projeção %s: %w", envelope.Sequence, runner.projection.Name(), err)
+				}
+				if envelope.Sequence > runner.replayedThrough {
+					runner.replayedThrough = envelope.Sequence
+				}
+			}
+			replayed++
+		}
+
+		if next <= sequence {
+			return replayed, nil
+		}
+		sequence = next
+	}
+}
+
+// projectionRunners retorna as projeções pelo nome, em ordem alfabética
+func (d *EventDispatcher) projectionRunners(names []string) ([]*projectionRunner, error) {
+	if len(names) == 0 {
+		names = d.Projections()
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	sorted := append([]string(nil), names...)
+	sort.Strings(sorted)
+
+	runners := make([]*projectionRunner, 0, len(sorted))
+	for i, name := range sorted {
+		if i > 0 && sorted[i-1] == name {
+			continue
+		}
+		runner, ok := d.projections[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrProjectionNotFound, name)
+		}
+		runners = append(runners, runner)
+	}
+
+	return runners, nil
+}
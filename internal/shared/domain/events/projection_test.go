@@ -0,0 +1,177 @@
+package shared_events
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// countingProjection conta os eventos aplicados por nome
+type countingProjection struct {
+	name    string
+	mu      sync.Mutex
+	counts  map[string]int
+	resets  int
+	failOn  string
+	applied []int64
+}
+
+func newCountingProjection(name string) *countingProjection {
+	return &countingProjection{name: name, counts: make(map[string]int)}
+}
+
+func (p *countingProjection) Name() string { return p.name }
+
+func (p *countingProjection) Reset() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.counts = make(map[string]int)
+	p.applied = nil
+	p.resets++
+	return nil
+}
+
+func (p *countingProjection) Apply(event Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if event.EventName() == p.failOn {
+		return errors.New("cannot apply")
+	}
+	p.counts[event.EventName()]++
+	p.applied = append(p.applied, event.(*Envelope).Sequence)
+	return nil
+}
+
+func (p *countingProjection) snapshot() (map[string]int, []int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	counts := make(map[string]int, len(p.counts))
+	for name, count := range p.counts {
+		counts[name] = count
+	}
+	return counts, append([]int64(nil), p.applied...)
+}
+
+func newReplayDispatcher(t *testing.T) (*EventDispatcher, *countingProjection, *countingProjection) {
+	t.Helper()
+
+	dispatcher := NewEventDispatcher()
+	dispatcher.SetEventStore(NewInMemoryEventStore())
+
+	products := newCountingProjection("products")
+	all := newCountingProjection("all")
+	dispatcher.RegisterProjection("product.*", products)
+	dispatcher.RegisterProjection("*", all)
+
+	for _, name := range []string{"product.created", "order.placed", "product.created", "product.deleted"} {
+		dispatcher.DispatchAndWait(name, &mockEvent{name: name})
+	}
+
+	return dispatcher, products, all
+}
+
+func TestEventDispatcher_RebuildProjections(t *testing.T) {
+	dispatcher, products, all := newReplayDispatcher(t)
+
+	if got := dispatcher.Projections(); !reflect.DeepEqual(got, []string{"all", "products"}) {
+		t.Errorf("Projections() = %v", got)
+	}
+
+	replayed, err := dispatcher.RebuildProjections(context.Background(), "products")
+	if err != nil {
+		t.Fatalf("RebuildProjections() error = %v", err)
+	}
+	if replayed != 4 {
+		t.Errorf("replayed = %d, want 4", replayed)
+	}
+
+	counts, applied := products.snapshot()
+	if !reflect.DeepEqual(counts, map[string]int{"product.created": 2, "product.deleted": 1}) || products.resets != 1 {
+		t.Errorf("products = %v (resets %d), want the rebuilt counts", counts, products.resets)
+	}
+	if !reflect.DeepEqual(applied, []int64{1, 3, 4}) {
+		t.Errorf("applied sequences = %v, want [1 3 4]", applied)
+	}
+	// Projeções não escolhidas ficam intactas
+	if counts, _ := all.snapshot(); len(counts) != 3 || all.resets != 0 {
+		t.Errorf("all = %v (resets %d), want the live counts", counts, all.resets)
+	}
+
+	t.Run("live events already replayed are skipped", func(t *testing.T) {
+		replayedEvents, _, _ := dispatcher.EventStore().ReadFrom(context.Background(), 3, 1)
+		dispatcher.DispatchAndWait("product.created", replayedEvents[0])
+
+		if counts, _ := products.snapshot(); counts["product.created"] != 2 {
+			t.Errorf("product.created = %d, want 2", counts["product.created"])
+		}
+	})
+
+	t.Run("new live events are applied", func(t *testing.T) {
+		dispatcher.DispatchAndWait("product.restored", &mockEvent{name: "product.restored"})
+
+		if counts, _ := products.snapshot(); counts["product.restored"] != 1 {
+			t.Errorf("product.restored = %d, want 1", counts["product.restored"])
+		}
+	})
+}
+
+func TestEventDispatcher_ReplayFrom(t *testing.T) {
+	dispatcher, products, all := newReplayDispatcher(t)
+
+	// Sem reset: os eventos a partir da sequência 3 são aplicados de novo
+	replayed, err := dispatcher.ReplayFrom(context.Background(), 3)
+	if err != nil {
+		t.Fatalf("ReplayFrom() error = %v", err)
+	}
+	if replayed != 2 {
+		t.Errorf("replayed = %d, want 2", replayed)
+	}
+
+	if counts, _ := products.snapshot(); counts["product.created"] != 3 || counts["product.deleted"] != 2 || products.resets != 0 {
+		t.Errorf("products = %v (resets %d)", counts, products.resets)
+	}
+	if counts, _ := all.snapshot(); counts["order.placed"] != 1 {
+		t.Errorf("all order.placed = %d, want 1", counts["order.placed"])
+	}
+}
+
+func TestEventDispatcher_ReplayErrors(t *testing.T) {
+	t.Run("without event store", func(t *testing.T) {
+		dispatcher := NewEventDispatcher()
+		if _, err := dispatcher.RebuildProjections(context.Background()); err != ErrEventStoreNotConfigured {
+			t.Errorf("RebuildProjections() error = %v, want %v", err, ErrEventStoreNotConfigured)
+		}
+	})
+
+	t.Run("unknown projection", func(t *testing.T) {
+		dispatcher, _, _ := newReplayDispatcher(t)
+		if _, err := dispatcher.RebuildProjections(context.Background(), "missing"); !errors.Is(err, ErrProjectionNotFound) {
+			t.Errorf("RebuildProjections() error = %v, want %v", err, ErrProjectionNotFound)
+		}
+	})
+
+	t.Run("projection failure stops the replay", func(t *testing.T) {
+		dispatcher, products, _ := newReplayDispatcher(t)
+		products.failOn = "product.deleted"
+
+		replayed, err := dispatcher.RebuildProjections(context.Background(), "products")
+		if err == nil {
+			t.Fatal("RebuildProjections() expected error, got nil")
+		}
+		if replayed != 3 {
+			t.Errorf("replayed = %d, want 3", replayed)
+		}
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		dispatcher, _, _ := newReplayDispatcher(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		if _, err := dispatcher.RebuildProjections(ctx); err != context.Canceled {
+			t.Errorf("RebuildProjections() error = %v, want %v", err, context.Canceled)
+		}
+	})
+}
@@ -0,0 +1,175 @@
+package shared_events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	ErrUnknownEvent            = errors.New("unknown event type")
+	ErrUnsupportedEventVersion = errors.New("unsupported event version")
+)
+
+// Upcaster converte o payload de uma versão do evento para a versão seguinte
+type Upcaster func(payload json.RawMessage) (json.RawMessage, error)
+
+// EventRegistry conhece os tipos de evento e suas versões de schema.
+// Serializa envelopes em JSON versionado e, ao ler, aplica os upcasters
+// em sequência até o payload chegar à versão atual do evento.
+type EventRegistry struct {
+	mu    sync.RWMutex
+	types map[string]*eventType
+}
+
+type eventType struct {
+	version   int
+	factory   EventFactory
+	upcasters map[int]Upcaster
+}
+
+func NewEventRegistry() *EventRegistry {
+	return &EventRegistry{types: make(map[string]*eventType)}
+}
+
+// Register registra um tipo de evento; o nome e a versão atual vêm da instância criada pela fábrica
+func (r *EventRegistry) Register(factory EventFactory) {
+	event := factory()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t := r.typeFor(event.EventName())
+	t.version = schemaVersion(event)
+	t.factory = factory
+}
+
+// RegisterUpcaster registra a conversão do payload da versão fromVersion para fromVersion+1
+func (r *EventRegistry) RegisterUpcaster(eventName string, fromVersion int, upcaster Upcaster) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.typeFor(eventName).upcasters[fromVersion] = upcaster
+}
+
+// Names retorna os nomes dos eventos registrados, em ordem alfabética
+func (r *EventRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.types))
+	for name, t := range r.types {
+		if t.factory != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Marshal serializa o evento como um envelope JSON; eventos sem envelope ganham um novo
+func (r *EventRegistry) Marshal(event Event) ([]byte, error) {
+	envelope := Wrap(event)
+	if _, err := r.lookup(envelope.Name); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(envelope)
+}
+
+// Unmarshal lê um envelope JSON, convertendo o payload para a versão atual do evento
+func (r *EventRegistry) Unmarshal(data []byte) (*Envelope, error) {
+	var raw struct {
+		ID            string          `json:"id"`
+		Name          string          `json:"name"`
+		Version       int             `json:"version"`
+		AggregateID   string          `json:"aggregate_id"`
+		CorrelationID string          `json:"correlation_id"`
+		OccurredAt    time.Time       `json:"occurred_at"`
+		Payload       json.RawMessage `json:"payload"`
+		Sequence      int64           `json:"sequence"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("erro ao desserializar envelope: %w", err)
+	}
+
+	event, version, err := r.Decode(raw.Name, raw.Version, raw.Payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Envelope{
+		ID:            raw.ID,
+		Name:          raw.Name,
+		Version:       version,
+		AggregateID:   raw.AggregateID,
+		CorrelationID: raw.CorrelationID,
+		OccurredAt:    raw.OccurredAt,
+		Payload:       event,
+		Sequence:      raw.Sequence,
+	}, nil
+}
+
+// Decode desserializa o payload gravado na versão informada e retorna o evento na versão atual.
+// Versão 0 é tratada como 1, a versão dos eventos gravados antes do versionamento.
+func (r *EventRegistry) Decode(eventName string, version int, payload []byte) (Event, int, error) {
+	t, err := r.lookup(eventName)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if version < 1 {
+		version = 1
+	}
+	if version > t.version {
+		return nil, 0, fmt.Errorf("%w: %s v%d (atual: v%d)", ErrUnsupportedEventVersion, eventName, version, t.version)
+	}
+
+	data := json.RawMessage(payload)
+	for ; version < t.version; version++ {
+		upcaster, ok := t.upcasters[version]
+		if !ok {
+			return nil, 0, fmt.Errorf("%w: %s v%d sem upcaster", ErrUnsupportedEventVersion, eventName, version)
+		}
+		if data, err = upcaster(data); err != nil {
+			return nil, 0, fmt.Errorf("erro ao converter %s da v%d: %w", eventName, version, err)
+		}
+	}
+
+	event := t.factory()
+	if err := json.Unmarshal(data, event); err != nil {
+		return nil, 0, fmt.Errorf("erro ao desserializar evento %s: %w", eventName, err)
+	}
+
+	return event, t.version, nil
+}
+
+func (r *EventRegistry) lookup(eventName string) (*eventType, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	t, ok := r.types[eventName]
+	if !ok || t.factory == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEvent, eventName)
+	}
+
+	// Cópia dos upcasters para não segurar o lock durante a conversão
+	upcasters := make(map[int]Upcaster, len(t.upcasters))
+	for version, upcaster := range t.upcasters {
+		upcasters[version] = upcaster
+	}
+	return &eventType{version: t.version, factory: t.factory, upcasters: upcasters}, nil
+}
+
+// typeFor retorna o tipo registrado com o nome, criando-o se necessário; exige o lock de escrita
+func (r *EventRegistry) typeFor(eventName string) *eventType {
+	t, ok := r.types[eventName]
+	if !ok {
+		t = &eventType{upcasters: make(map[int]Upcaster)}
+		r.types[eventName] = t
+	}
+	return t
+}
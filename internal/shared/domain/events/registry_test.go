@@ -0,0 +1,116 @@
+package shared_events
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// priceChangedV2 guarda o preço em centavos com moeda; a v1 guardava o valor em reais
+type priceChangedV2 struct {
+	ProductID string `json:"product_id"`
+	Amount    int64  `json:"amount"`
+	Currency  string `json:"currency"`
+}
+
+func (e *priceChangedV2) EventName() string  { return "price.changed" }
+func (e *priceChangedV2) SchemaVersion() int { return 2 }
+
+func newPriceRegistry() *EventRegistry {
+	registry := NewEventRegistry()
+	registry.Register(func() Event { return &priceChangedV2{} })
+	registry.RegisterUpcaster("price.changed", 1, func(payload json.RawMessage) (json.RawMessage, error) {
+		var v1 struct {
+			ProductID string  `json:"product_id"`
+			Price     float64 `json:"price"`
+		}
+		if err := json.Unmarshal(payload, &v1); err != nil {
+			return nil, err
+		}
+		return json.Marshal(priceChangedV2{ProductID: v1.ProductID, Amount: int64(v1.Price * 100), Currency: "BRL"})
+	})
+	return registry
+}
+
+func TestEventRegistry_RoundTrip(t *testing.T) {
+	registry := newPriceRegistry()
+
+	envelope := NewEnvelope(&priceChangedV2{ProductID: "p-1", Amount: 3500, Currency: "BRL"})
+	envelope.CorrelationID = "req-1"
+	envelope.OccurredAt = time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	data, err := registry.Marshal(envelope)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if !strings.Contains(string(data), `"version":2`) {
+		t.Errorf("Marshal() = %s, want version 2", data)
+	}
+
+	decoded, err := registry.Unmarshal(data)
+	if err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if !reflect.DeepEqual(decoded, envelope) {
+		t.Errorf("round trip = %+v, want %+v", decoded, envelope)
+	}
+}
+
+func TestEventRegistry_Upcast(t *testing.T) {
+	registry := newPriceRegistry()
+
+	stored := `{"id":"evt-1","name":"price.changed","version":1,"occurred_at":"2026-10-17T12:00:00Z","payload":{"product_id":"p-1","price":35.5}}`
+
+	envelope, err := registry.Unmarshal([]byte(stored))
+	if err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if envelope.Version != 2 || envelope.ID != "evt-1" {
+		t.Errorf("envelope = %+v, want evt-1 at version 2", envelope)
+	}
+	want := &priceChangedV2{ProductID: "p-1", Amount: 3550, Currency: "BRL"}
+	if !reflect.DeepEqual(envelope.Payload, want) {
+		t.Errorf("Payload = %+v, want %+v", envelope.Payload, want)
+	}
+}
+
+func TestEventRegistry_Errors(t *testing.T) {
+	registry := newPriceRegistry()
+	registry.Register(func() Event { return &aggregateTestEvent{} }) // v3 sem upcasters
+
+	tests := []struct {
+		name    string
+		event   string
+		version int
+		payload string
+		wantErr error
+	}{
+		{name: "unknown event", event: "missing.event", version: 1, payload: `{}`, wantErr: ErrUnknownEvent},
+		{name: "version from the future", event: "price.changed", version: 3, payload: `{}`, wantErr: ErrUnsupportedEventVersion},
+		{name: "missing upcaster", event: "aggregate.changed", version: 1, payload: `{}`, wantErr: ErrUnsupportedEventVersion},
+		{name: "upcaster failure", event: "price.changed", version: 1, payload: `[]`},
+		{name: "invalid payload", event: "price.changed", version: 2, payload: `not-json`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := registry.Decode(tt.event, tt.version, []byte(tt.payload))
+			if err == nil {
+				t.Fatal("Decode() expected error, got nil")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Decode() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := registry.Marshal(&mockEvent{name: "missing.event"}); !errors.Is(err, ErrUnknownEvent) {
+		t.Errorf("Marshal() error = %v, want %v", err, ErrUnknownEvent)
+	}
+	if got := registry.Names(); !reflect.DeepEqual(got, []string{"aggregate.changed", "price.changed"}) {
+		t.Errorf("Names() = %v", got)
+	}
+}
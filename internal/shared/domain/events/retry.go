@@ -0,0 +1,306 @@
+package shared_events
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	shared_identity "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/identity"
+)
+
+var ErrHandlerNotFound = errors.New("event handler not found")
+
+// ErrorEventHandler é um handler que informa falhas; elas disparam novas tentativas
+type ErrorEventHandler func(event Event) error
+
+// RetryPolicy define quantas vezes um ErrorEventHandler é executado e o intervalo
+// entre as tentativas, que cresce exponencialmente até MaxBackoff
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+}
+
+// DefaultRetryPolicy retorna a política padrão: 5 tentativas, de 100ms até 5s
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    5,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Multiplier:     2,
+	}
+}
+
+// Backoff retorna a espera depois da tentativa informada (começando em 1)
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	backoff := float64(p.InitialBackoff)
+	for i := 1; i < attempt; i++ {
+		backoff *= p.Multiplier
+		if p.MaxBackoff > 0 && backoff >= float64(p.MaxBackoff) {
+			return p.MaxBackoff
+		}
+	}
+	return time.Duration(backoff)
+}
+
+// retryHandler é um ErrorEventHandler registrado com nome e política de retry
+type retryHandler struct {
+	pattern        string
+	subscriptionID uint64
+	handler        ErrorEventHandler
+	policy         RetryPolicy
+}
+
+// pendingRetry é uma tentativa agendada de um handler com retry, aguardando o backoff
+type pendingRetry struct {
+	handlerName string
+	event       Event
+	attempt     int
+	err         error // Erro da tentativa anterior
+	timer       *time.Timer
+}
+
+// retryQueue guarda as tentativas agendadas; nil depois de Shutdown, quando as novas
+// falhas vão direto para a dead-letter store
+type retryQueue struct {
+	mu      sync.Mutex
+	pending map[*pendingRetry]struct{}
+}
+
+// RegisterWithRetry registra um handler que pode falhar, com os mesmos padrões de Register.
+// Falhas e panics são repetidos conforme a política; esgotadas as tentativas, ou com o
+// dispatcher encerrado durante a espera entre elas, o evento vai para a dead-letter store.
+// A espera não ocupa o worker: a próxima tentativa é agendada e volta para a fila quando o
+// backoff vence, então Dispatch e DispatchAndWait só acompanham a primeira tentativa.
+// O nome identifica o handler no replay e deve ser único no dispatcher.
+func (d *EventDispatcher) RegisterWithRetry(pattern, handlerName string, handler ErrorEventHandler, policy RetryPolicy) *Subscription {
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	sub := d.subscribe(pattern, func(event Event) {
+		d.runAttempt(handlerName, event, 1)
+	})
+	d.retryHandlers[handlerName] = retryHandler{pattern: pattern, subscriptionID: sub.id, handler: handler, policy: policy}
+
+	return sub
+}
+
+// SetDeadLetterStore troca a store dos eventos que esgotaram as tentativas
+func (d *EventDispatcher) SetDeadLetterStore(store DeadLetterStore) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.deadLetters = store
+}
+
+// DeadLetters retorna a store dos eventos que esgotaram as tentativas
+func (d *EventDispatcher) DeadLetters() DeadLetterStore {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return d.deadLetters
+}
+
+// ReplayDeadLetter executa novamente o handler que falhou, com a mesma política de retry.
+// Em caso de sucesso o evento sai da dead-letter store; caso contrário o registro é atualizado.
+func (d *EventDispatcher) ReplayDeadLetter(id string) error {
+	store := d.DeadLetters()
+
+	entry, err := store.Get(id)
+	if err != nil {
+		return err
+	}
+
+	attempts, err := d.runWithRetry(entry.HandlerName, entry.Event)
+	if err != nil {
+		if errors.Is(err, ErrHandlerNotFound) {
+			return err
+		}
+
+		entry.Attempts += attempts
+		entry.Error = err.Error()
+		entry.FailedAt = time.Now().UTC()
+		if saveErr := store.Save(entry); saveErr != nil {
+			return saveErr
+		}
+		return err
+	}
+
+	return store.Delete(id)
+}
+
+// runAttempt executa a tentativa informada do handler. Com falha, agenda a próxima ou,
+// esgotadas as tentativas, envia o evento para a dead-letter store.
+func (d *EventDispatcher) runAttempt(handlerName string, event Event, attempt int) {
+	d.mu.RLock()
+	rh, ok := d.retryHandlers[handlerName]
+	d.mu.RUnlock()
+
+	if !ok {
+		// Handler removido por Unsubscribe enquanto a tentativa aguardava o backoff
+		d.deadLetter(event.EventName(), handlerName, event, attempt-1, ErrHandlerNotFound)
+		return
+	}
+
+	err := callErrorHandler(rh.handler, event)
+	if err == nil {
+		return
+	}
+
+	if attempt >= rh.policy.MaxAttempts || !d.scheduleRetry(handlerName, event, attempt+1, rh.policy.Backoff(attempt), err) {
+		d.deadLetter(event.EventName(), handlerName, event, attempt, err)
+	}
+}
+
+// scheduleRetry agenda a tentativa para depois do backoff. Ela é contada em inFlight até
+// terminar, para que Shutdown a espere; retorna false se o dispatcher já foi encerrado.
+func (d *EventDispatcher) scheduleRetry(handlerName string, event Event, attempt int, backoff time.Duration, lastErr error) bool {
+	d.retries.mu.Lock()
+	defer d.retries.mu.Unlock()
+
+	if d.retries.pending == nil {
+		return false
+	}
+
+	retry := &pendingRetry{handlerName: handlerName, event: event, attempt: attempt, err: lastErr}
+	d.inFlight.Add(1)
+	d.retries.pending[retry] = struct{}{}
+	retry.timer = time.AfterFunc(backoff, func() { d.enqueueRetry(retry) })
+
+	return true
+}
+
+// enqueueRetry devolve a tentativa à fila quando o backoff vence. Sem pool, ela roda na
+// goroutine do timer.
+func (d *EventDispatcher) enqueueRetry(retry *pendingRetry) {
+	d.retries.mu.Lock()
+	_, ok := d.retries.pending[retry]
+	delete(d.retries.pending, retry)
+	d.retries.mu.Unlock()
+
+	// Já tratada por abortRetries
+	if !ok {
+		return
+	}
+
+	run := func(event Event) {
+		d.runAttempt(retry.handlerName, event, retry.attempt)
+	}
+
+	if d.pool == nil {
+		runHandler(run, retry.event)
+		d.inFlight.Done()
+		return
+	}
+
+	// Como em DispatchAndWait, a tentativa espera espaço na fila qualquer que seja a QueuePolicy
+	j := job{event: retry.event, handlers: []EventHandler{run}, finish: d.inFlight.Done}
+	if err := d.pool.submit(retry.event.EventName(), j, true, d.stop); err != nil {
+		d.deadLetter(retry.event.EventName(), retry.handlerName, retry.event, retry.attempt-1, retry.err)
+		d.inFlight.Done()
+	}
+}
+
+// abortRetries interrompe as esperas entre tentativas no encerramento: os eventos vão
+// para a dead-letter store e podem ser reprocessados pelo replay
+func (d *EventDispatcher) abortRetries() {
+	d.retries.mu.Lock()
+	pending := d.retries.pending
+	d.retries.pending = nil
+	d.retries.mu.Unlock()
+
+	for retry := range pending {
+		retry.timer.Stop()
+		d.deadLetter(retry.event.EventName(), retry.handlerName, retry.event, retry.attempt-1, retry.err)
+		d.inFlight.Done()
+	}
+}
+
+// runWithRetry executa o handler até ter sucesso ou esgotar as tentativas, retornando
+// quantas tentativas foram feitas e o último erro. Usado pelo replay, que roda na
+// goroutine de quem o chama e não em um worker.
+func (d *EventDispatcher) runWithRetry(handlerName string, event Event) (int, error) {
+	d.mu.RLock()
+	rh, ok := d.retryHandlers[handlerName]
+	d.mu.RUnlock()
+
+	if !ok {
+		return 0, ErrHandlerNotFound
+	}
+
+	var err error
+	for attempt := 1; attempt <= rh.policy.MaxAttempts; attempt++ {
+		if err = callErrorHandler(rh.handler, event); err == nil {
+			return attempt, nil
+		}
+		if attempt < rh.policy.MaxAttempts && !d.wait(rh.policy.Backoff(attempt)) {
+			// Dispatcher encerrado: o evento vai para a dead-letter store e pode ser reprocessado
+			return attempt, err
+		}
+	}
+
+	return rh.policy.MaxAttempts, err
+}
+
+// wait espera o intervalo entre tentativas sem segurar o encerramento:
+// retorna false se Shutdown for chamado antes
+func (d *EventDispatcher) wait(backoff time.Duration) bool {
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-d.stop:
+		return false
+	}
+}
+
+func (d *EventDispatcher) deadLetter(eventName, handlerName string, event Event, attempts int, err error) {
+	entry := DeadLetter{
+		ID:          shared_identity.NewUUID(),
+		EventName:   eventName,
+		HandlerName: handlerName,
+		Event:       event,
+		Error:       err.Error(),
+		Attempts:    attempts,
+		FailedAt:    time.Now().UTC(),
+	}
+
+	if saveErr := d.DeadLetters().Save(entry); saveErr != nil {
+		log.Printf("❌ Evento %s perdido: handler %s falhou (%v) e a dead-letter store recusou: %v", eventName, handlerName, err, saveErr)
+		return
+	}
+
+	log.Printf("⚠️  Evento %s enviado para a dead-letter store após %d tentativas do handler %s: %v", eventName, attempts, handlerName, err)
+}
+
+// callErrorHandler executa o handler convertendo um panic em erro
+func callErrorHandler(handler ErrorEventHandler, event Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return handler(event)
+}
+
+// runHandler executa um handler simples isolando panics, para que um handler
+// com defeito não derrube o processo
+func runHandler(handler EventHandler, event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("❌ Panic no handler do evento %s: %v", event.EventName(), r)
+		}
+	}()
+
+	handler(event)
+}
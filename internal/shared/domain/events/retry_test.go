@@ -0,0 +1,274 @@
+package shared_events
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fastRetry evita esperas longas nos testes
+var fastRetry = RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 4 * time.Millisecond, Multiplier: 2}
+
+// eventually espera a condição: as novas tentativas rodam depois de DispatchAndWait retornar
+func eventually(t *testing.T, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met before the deadline")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Multiplier: 2}
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 1, want: 100 * time.Millisecond},
+		{attempt: 2, want: 200 * time.Millisecond},
+		{attempt: 4, want: 800 * time.Millisecond},
+		{attempt: 5, want: time.Second},
+		{attempt: 10, want: time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := policy.Backoff(tt.attempt); got != tt.want {
+			t.Errorf("Backoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestEventDispatcher_RegisterWithRetry(t *testing.T) {
+	t.Run("retries until the handler succeeds", func(t *testing.T) {
+		dispatcher := NewEventDispatcher()
+		var calls atomic.Int32
+
+		dispatcher.RegisterWithRetry("test.event", "flaky", func(event Event) error {
+			if calls.Add(1) < 3 {
+				return errors.New("temporary failure")
+			}
+			return nil
+		}, fastRetry)
+
+		dispatcher.DispatchAndWait("test.event", &mockEvent{name: "test.event"})
+
+		eventually(t, func() bool { return calls.Load() == 3 })
+		if err := dispatcher.Shutdown(context.Background()); err != nil {
+			t.Fatalf("Shutdown() error = %v", err)
+		}
+		if entries, _ := dispatcher.DeadLetters().List(); len(entries) != 0 {
+			t.Errorf("dead letters = %d, want 0", len(entries))
+		}
+	})
+
+	t.Run("exhausted retries go to the dead-letter store", func(t *testing.T) {
+		dispatcher := NewEventDispatcher()
+		var calls atomic.Int32
+
+		dispatcher.RegisterWithRetry("test.event", "broken", func(event Event) error {
+			calls.Add(1)
+			return errors.New("permanent failure")
+		}, fastRetry)
+
+		dispatcher.DispatchAndWait("test.event", &mockEvent{name: "test.event"})
+
+		var entries []DeadLetter
+		eventually(t, func() bool {
+			entries, _ = dispatcher.DeadLetters().List()
+			return len(entries) == 1
+		})
+		if got := calls.Load(); got != 3 {
+			t.Errorf("handler calls = %d, want 3", got)
+		}
+		entry := entries[0]
+		if entry.EventName != "test.event" || entry.HandlerName != "broken" || entry.Attempts != 3 || entry.Error != "permanent failure" {
+			t.Errorf("dead letter = %+v", entry)
+		}
+	})
+
+	t.Run("panics are recovered and retried", func(t *testing.T) {
+		dispatcher := NewEventDispatcher()
+
+		dispatcher.RegisterWithRetry("test.event", "panicking", func(event Event) error {
+			panic("boom")
+		}, fastRetry)
+
+		dispatcher.DispatchAndWait("test.event", &mockEvent{name: "test.event"})
+
+		var entries []DeadLetter
+		eventually(t, func() bool {
+			entries, _ = dispatcher.DeadLetters().List()
+			return len(entries) == 1
+		})
+		if entries[0].Error != "panic: boom" {
+			t.Errorf("dead letters = %+v, want one entry with the panic", entries)
+		}
+	})
+
+	t.Run("the backoff does not hold the worker", func(t *testing.T) {
+		dispatcher := NewEventDispatcherWithPool(DispatcherConfig{Workers: 1, QueueSize: 1})
+		var calls atomic.Int32
+
+		dispatcher.RegisterWithRetry("test.event", "slow", func(event Event) error {
+			calls.Add(1)
+			return errors.New("temporary failure")
+		}, RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Minute, Multiplier: 2})
+		handled := make(chan struct{}, 1)
+		dispatcher.Register("other.event", func(event Event) {
+			handled <- struct{}{}
+		})
+
+		// DispatchAndWait acompanha só a primeira tentativa, e o único worker segue livre
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_ = dispatcher.DispatchAndWait("test.event", &mockEvent{name: "test.event"})
+			_ = dispatcher.DispatchAndWait("other.event", &mockEvent{name: "other.event"})
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("DispatchAndWait() waited for the backoff")
+		}
+		select {
+		case <-handled:
+		default:
+			t.Error("other.event was not handled while the retry waited")
+		}
+		if got := calls.Load(); got != 1 {
+			t.Errorf("handler calls = %d, want 1", got)
+		}
+
+		if err := dispatcher.Shutdown(context.Background()); err != nil {
+			t.Fatalf("Shutdown() error = %v", err)
+		}
+	})
+
+	t.Run("shutdown interrupts the backoff", func(t *testing.T) {
+		dispatcher := NewEventDispatcherWithPool(DispatcherConfig{Workers: 1, QueueSize: 1})
+		failed := make(chan struct{}, 1)
+
+		dispatcher.RegisterWithRetry("test.event", "slow", func(event Event) error {
+			failed <- struct{}{}
+			return errors.New("temporary failure")
+		}, RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Minute, Multiplier: 2})
+
+		if err := dispatcher.Dispatch("test.event", &mockEvent{name: "test.event"}); err != nil {
+			t.Fatalf("Dispatch() error = %v", err)
+		}
+		<-failed
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if err := dispatcher.Shutdown(ctx); err != nil {
+			t.Fatalf("Shutdown() error = %v, want the backoff to be interrupted", err)
+		}
+
+		entries, _ := dispatcher.DeadLetters().List()
+		if len(entries) != 1 || entries[0].Attempts != 1 {
+			t.Errorf("dead letters = %+v, want one entry after 1 attempt", entries)
+		}
+	})
+}
+
+func TestEventDispatcher_PanicIsolation(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		dispatcher *EventDispatcher
+	}{
+		{name: "unbounded", dispatcher: NewEventDispatcher()},
+		{name: "pool", dispatcher: NewEventDispatcherWithPool(DispatcherConfig{Workers: 1, QueueSize: 2})},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var handled atomic.Int32
+			tt.dispatcher.Register("test.event", func(event Event) {
+				panic("handler bug")
+			})
+			tt.dispatcher.Register("test.event", func(event Event) {
+				handled.Add(1)
+			})
+
+			// Dois eventos: o worker precisa sobreviver ao panic do primeiro
+			for i := 0; i < 2; i++ {
+				if err := tt.dispatcher.Dispatch("test.event", &mockEvent{name: "test.event"}); err != nil {
+					t.Fatalf("Dispatch() error = %v", err)
+				}
+			}
+			if err := tt.dispatcher.Shutdown(context.Background()); err != nil {
+				t.Fatalf("Shutdown() error = %v", err)
+			}
+
+			if got := handled.Load(); got != 2 {
+				t.Errorf("healthy handler calls = %d, want 2", got)
+			}
+		})
+	}
+}
+
+func TestEventDispatcher_ReplayDeadLetter(t *testing.T) {
+	dispatcher := NewEventDispatcher()
+	var healthy atomic.Bool
+
+	dispatcher.RegisterWithRetry("test.event", "recovering", func(event Event) error {
+		if !healthy.Load() {
+			return errors.New("downstream unavailable")
+		}
+		return nil
+	}, fastRetry)
+
+	dispatcher.DispatchAndWait("test.event", &mockEvent{name: "test.event"})
+
+	var entries []DeadLetter
+	eventually(t, func() bool {
+		entries, _ = dispatcher.DeadLetters().List()
+		return len(entries) == 1
+	})
+	id := entries[0].ID
+
+	t.Run("replay that fails again keeps the entry", func(t *testing.T) {
+		if err := dispatcher.ReplayDeadLetter(id); err == nil {
+			t.Fatal("ReplayDeadLetter() expected error, got nil")
+		}
+
+		entry, err := dispatcher.DeadLetters().Get(id)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if entry.Attempts != 6 {
+			t.Errorf("Attempts = %d, want 6", entry.Attempts)
+		}
+	})
+
+	t.Run("successful replay removes the entry", func(t *testing.T) {
+		healthy.Store(true)
+
+		if err := dispatcher.ReplayDeadLetter(id); err != nil {
+			t.Fatalf("ReplayDeadLetter() error = %v", err)
+		}
+		if _, err := dispatcher.DeadLetters().Get(id); err != ErrDeadLetterNotFound {
+			t.Errorf("Get() after replay error = %v, want %v", err, ErrDeadLetterNotFound)
+		}
+	})
+
+	t.Run("unknown entry", func(t *testing.T) {
+		if err := dispatcher.ReplayDeadLetter("missing"); err != ErrDeadLetterNotFound {
+			t.Errorf("ReplayDeadLetter() error = %v, want %v", err, ErrDeadLetterNotFound)
+		}
+	})
+
+	t.Run("handler no longer registered", func(t *testing.T) {
+		_ = dispatcher.DeadLetters().Save(DeadLetter{ID: "orphan", EventName: "test.event", HandlerName: "removed", Event: &mockEvent{name: "test.event"}})
+
+		if err := dispatcher.ReplayDeadLetter("orphan"); err != ErrHandlerNotFound {
+			t.Errorf("ReplayDeadLetter() error = %v, want %v", err, ErrHandlerNotFound)
+		}
+	})
+}
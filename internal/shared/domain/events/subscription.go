@@ -0,0 +1,77 @@
+package shared_events
+
+import (
+	"sort"
+	"sync"
+)
+
+// Subscription identifica um handler registrado no EventDispatcher
+type Subscription struct {
+	dispatcher *EventDispatcher
+	pattern    string
+	id         uint64
+	once       sync.Once
+}
+
+// Pattern retorna o padrão de nomes de evento da inscrição
+func (s *Subscription) Pattern() string {
+	return s.pattern
+}
+
+// Unsubscribe remove o handler; eventos já aceitos por Dispatch ainda podem chegar a ele.
+// Handlers registrados com RegisterWithRetry deixam de poder ser usados no replay.
+// Chamadas repetidas não fazem nada.
+func (s *Subscription) Unsubscribe() {
+	s.once.Do(func() {
+		d := s.dispatcher
+
+		d.mu.Lock()
+		defer d.mu.Unlock()
+
+		subs := d.handlers[s.pattern]
+		for i, sub := range subs {
+			if sub.id == s.id {
+				subs = append(subs[:i:i], subs[i+1:]...)
+				break
+			}
+		}
+		if len(subs) == 0 {
+			delete(d.handlers, s.pattern)
+		} else {
+			d.handlers[s.pattern] = subs
+		}
+
+		for name, rh := range d.retryHandlers {
+			if rh.subscriptionID == s.id {
+				delete(d.retryHandlers, name)
+			}
+		}
+	})
+}
+
+// SubscriptionInfo descreve os handlers registrados para um padrão
+type SubscriptionInfo struct {
+	Pattern  string   `json:"pattern" example:"product.*"`
+	Handlers int      `json:"handlers" example:"2"`
+	Named    []string `json:"named,omitempty"` // Handlers com retry, identificados pelo nome
+}
+
+// Subscriptions lista os padrões registrados, em ordem alfabética, com a quantidade de handlers de cada um
+func (d *EventDispatcher) Subscriptions() []SubscriptionInfo {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	named := make(map[string][]string)
+	for name, rh := range d.retryHandlers {
+		named[rh.pattern] = append(named[rh.pattern], name)
+	}
+
+	infos := make([]SubscriptionInfo, 0, len(d.handlers))
+	for pattern, subs := range d.handlers {
+		sort.Strings(named[pattern])
+		infos = append(infos, SubscriptionInfo{Pattern: pattern, Handlers: len(subs), Named: named[pattern]})
+	}
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Pattern < infos[j].Pattern })
+	return infos
+}
@@ -0,0 +1,207 @@
+package shared_events
+
+import (
+	"bytes"
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recordingHandler registra a ordem em que os handlers recebem os eventos
+type recordingHandler struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (r *recordingHandler) handler(label string) EventHandler {
+	return func(event Event) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.calls = append(r.calls, label+":"+event.EventName())
+	}
+}
+
+func (r *recordingHandler) reset() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	calls := r.calls
+	r.calls = nil
+	return calls
+}
+
+func TestEventDispatcher_PatternSubscriptions(t *testing.T) {
+	dispatcher := NewEventDispatcher()
+	recorder := &recordingHandler{}
+
+	dispatcher.Register("*", recorder.handler("audit"))
+	dispatcher.Register("product.created", recorder.handler("exact"))
+	dispatcher.Register("product.*", recorder.handler("product"))
+	dispatcher.Register("order.*", recorder.handler("order"))
+
+	tests := []struct {
+		event string
+		want  []string
+	}{
+		{event: "product.created", want: []string{"audit:product.created", "exact:product.created", "product:product.created"}},
+		{event: "product.deleted", want: []string{"audit:product.deleted", "product:product.deleted"}},
+		{event: "category.created", want: []string{"audit:category.created"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.event, func(t *testing.T) {
+			dispatcher.DispatchAndWait(tt.event, &mockEvent{name: tt.event})
+
+			// DispatchAndWait roda os handlers em paralelo; a ordem é conferida no teste do pool
+			got := recorder.reset()
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("calls = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("handlers of one event run in registration order in a worker", func(t *testing.T) {
+		pooled := NewEventDispatcherWithPool(DispatcherConfig{Workers: 1, QueueSize: 1})
+		pooled.Register("product.*", recorder.handler("first"))
+		pooled.Register("*", recorder.handler("second"))
+		pooled.Register("product.created", recorder.handler("third"))
+
+		if err := pooled.Dispatch("product.created", &mockEvent{name: "product.created"}); err != nil {
+			t.Fatalf("Dispatch() error = %v", err)
+		}
+		if err := pooled.Shutdown(t.Context()); err != nil {
+			t.Fatalf("Shutdown() error = %v", err)
+		}
+
+		want := []string{"first:product.created", "second:product.created", "third:product.created"}
+		if got := recorder.reset(); !reflect.DeepEqual(got, want) {
+			t.Errorf("calls = %v, want %v", got, want)
+		}
+	})
+}
+
+func TestEventDispatcher_InvalidPattern(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Register() with a malformed pattern should panic")
+		}
+	}()
+
+	NewEventDispatcher().Register("product.[", func(event Event) {})
+}
+
+func TestSubscription_Unsubscribe(t *testing.T) {
+	dispatcher := NewEventDispatcher()
+	recorder := &recordingHandler{}
+
+	keep := dispatcher.Register("product.*", recorder.handler("keep"))
+	remove := dispatcher.Register("product.*", recorder.handler("remove"))
+	only := dispatcher.Register("*", recorder.handler("only"))
+
+	if remove.Pattern() != "product.*" {
+		t.Errorf("Pattern() = %q, want product.*", remove.Pattern())
+	}
+
+	remove.Unsubscribe()
+	remove.Unsubscribe() // idempotente
+	only.Unsubscribe()
+
+	dispatcher.DispatchAndWait("product.created", &mockEvent{name: "product.created"})
+
+	if got := recorder.reset(); !reflect.DeepEqual(got, []string{"keep:product.created"}) {
+		t.Errorf("calls = %v, want only the remaining handler", got)
+	}
+	if subs := dispatcher.Subscriptions(); len(subs) != 1 || subs[0].Pattern != "product.*" || subs[0].Handlers != 1 {
+		t.Errorf("Subscriptions() = %+v, want one handler on product.*", subs)
+	}
+
+	keep.Unsubscribe()
+	if subs := dispatcher.Subscriptions(); len(subs) != 0 {
+		t.Errorf("Subscriptions() = %+v, want none", subs)
+	}
+}
+
+func TestSubscription_UnsubscribeRetryHandler(t *testing.T) {
+	dispatcher := NewEventDispatcher()
+
+	sub := dispatcher.RegisterWithRetry("product.*", "webhook", func(event Event) error {
+		return nil
+	}, fastRetry)
+	_ = dispatcher.DeadLetters().Save(DeadLetter{ID: "dl-1", EventName: "product.created", HandlerName: "webhook", Event: &mockEvent{name: "product.created"}})
+
+	sub.Unsubscribe()
+
+	if err := dispatcher.ReplayDeadLetter("dl-1"); err != ErrHandlerNotFound {
+		t.Errorf("ReplayDeadLetter() error = %v, want %v", err, ErrHandlerNotFound)
+	}
+}
+
+func TestEventDispatcher_Subscriptions(t *testing.T) {
+	dispatcher := NewEventDispatcher()
+	dispatcher.Register("product.created", func(event Event) {})
+	dispatcher.Register("*", func(event Event) {})
+	dispatcher.Register("product.created", func(event Event) {})
+	dispatcher.RegisterWithRetry("product.*", "search-index", func(event Event) error { return nil }, fastRetry)
+	dispatcher.RegisterWithRetry("product.*", "audit", func(event Event) error { return nil }, fastRetry)
+
+	want := []SubscriptionInfo{
+		{Pattern: "*", Handlers: 1},
+		{Pattern: "product.*", Handlers: 2, Named: []string{"audit", "search-index"}},
+		{Pattern: "product.created", Handlers: 2},
+	}
+	if got := dispatcher.Subscriptions(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Subscriptions() = %+v, want %+v", got, want)
+	}
+}
+
+func TestEventDispatcher_DebugLogger(t *testing.T) {
+	dispatcher := NewEventDispatcher()
+
+	// Sem logger, eventos sem handlers são ignorados em silêncio
+	if err := dispatcher.Dispatch("unmatched.event", &mockEvent{name: "unmatched.event"}); err != nil {
+		t.Fatalf("Dispatch() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	dispatcher.SetDebugLogger(log.New(&buf, "", 0))
+
+	if err := dispatcher.Dispatch("unmatched.event", &mockEvent{name: "unmatched.event"}); err != nil {
+		t.Fatalf("Dispatch() error = %v", err)
+	}
+	dispatcher.DispatchAndWait("other.event", &mockEvent{name: "other.event"})
+
+	output := buf.String()
+	if !strings.Contains(output, "unmatched.event (no handlers registered)") || !strings.Contains(output, "other.event (no handlers registered)") {
+		t.Errorf("debug log = %q, want both unmatched events", output)
+	}
+}
+
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern   string
+		eventName string
+		want      bool
+	}{
+		{"product.created", "product.created", true},
+		{"product.*", "product.deleted", true},
+		{"*", "product.created", true},
+		{"product.*", "category.created", false},
+		{"product.created", "product.updated", false},
+	}
+
+	for _, tt := range tests {
+		if got := MatchPattern(tt.pattern, tt.eventName); got != tt.want {
+			t.Errorf("MatchPattern(%q, %q) = %v, want %v", tt.pattern, tt.eventName, got, tt.want)
+		}
+	}
+
+	if err := ValidatePattern("product.["); err == nil {
+		t.Error("ValidatePattern() with a malformed pattern should fail")
+	}
+	if err := ValidatePattern("product.*"); err != nil {
+		t.Errorf("ValidatePattern() error = %v", err)
+	}
+}
@@ -0,0 +1,152 @@
+package shared_events
+
+import (
+	"errors"
+	"sync"
+)
+
+var (
+	ErrQueueFull        = errors.New("event queue is full")
+	ErrDispatcherClosed = errors.New("event dispatcher is shut down")
+)
+
+// QueuePolicy define o que Dispatch faz quando a fila do pool está cheia
+type QueuePolicy string
+
+const (
+	// QueuePolicyBlock espera até haver espaço na fila
+	QueuePolicyBlock QueuePolicy = "block"
+	// QueuePolicyDrop descarta o evento e avisa OnDrop
+	QueuePolicyDrop QueuePolicy = "drop"
+	// QueuePolicyError recusa o evento retornando ErrQueueFull
+	QueuePolicyError QueuePolicy = "error"
+)
+
+// DispatcherConfig configura o pool de workers do EventDispatcher
+type DispatcherConfig struct {
+	Workers   int         // Número de goroutines que executam os handlers
+	QueueSize int         // Capacidade da fila de eventos aguardando um worker
+	Policy    QueuePolicy // Comportamento com a fila cheia (padrão: QueuePolicyBlock)
+
+	// OnDrop é chamado para cada evento descartado pela política QueuePolicyDrop,
+	// normalmente para incrementar uma métrica
+	OnDrop func(eventName string)
+}
+
+// ParseQueuePolicy converte o valor de configuração em uma QueuePolicy válida
+func ParseQueuePolicy(value string) (QueuePolicy, error) {
+	switch policy := QueuePolicy(value); policy {
+	case QueuePolicyBlock, QueuePolicyDrop, QueuePolicyError:
+		return policy, nil
+	case "":
+		return QueuePolicyBlock, nil
+	default:
+		return "", errors.New("invalid queue policy")
+	}
+}
+
+// NewEventDispatcherWithPool cria um dispatcher que executa os handlers em um número
+// fixo de workers alimentados por uma fila limitada. Use Shutdown para encerrá-lo.
+// Com QueuePolicyBlock, handlers não devem disparar eventos de forma síncrona:
+// com a fila cheia, o worker esperaria por si mesmo.
+func NewEventDispatcherWithPool(config DispatcherConfig) *EventDispatcher {
+	dispatcher := NewEventDispatcher()
+	dispatcher.pool = newWorkerPool(config)
+	return dispatcher
+}
+
+// job é a execução dos handlers de um evento; finish é chamado ao terminar e done,
+// quando informado, é fechado em seguida
+type job struct {
+	event    Event
+	handlers []EventHandler
+	done     chan struct{}
+	finish   func()
+}
+
+type workerPool struct {
+	queue  chan job
+	policy QueuePolicy
+	onDrop func(eventName string)
+	wg     sync.WaitGroup
+}
+
+func newWorkerPool(config DispatcherConfig) *workerPool {
+	workers := config.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	queueSize := config.QueueSize
+	if queueSize < 0 {
+		queueSize = 0
+	}
+	policy := config.Policy
+	if policy == "" {
+		policy = QueuePolicyBlock
+	}
+
+	pool := &workerPool{
+		queue:  make(chan job, queueSize),
+		policy: policy,
+		onDrop: config.OnDrop,
+	}
+
+	pool.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go pool.work()
+	}
+
+	return pool
+}
+
+// work executa os jobs até a fila ser fechada e esvaziada
+func (p *workerPool) work() {
+	defer p.wg.Done()
+
+	for j := range p.queue {
+		for _, handler := range j.handlers {
+			runHandler(handler, j.event)
+		}
+		j.finish()
+		if j.done != nil {
+			close(j.done)
+		}
+	}
+}
+
+// submit enfileira o job; os handlers do mesmo evento rodam no mesmo worker, em ordem de registro.
+// Com block, ou na política QueuePolicyBlock, espera espaço na fila até stop ser fechado.
+func (p *workerPool) submit(eventName string, j job, block bool, stop <-chan struct{}) error {
+	if block || p.policy == QueuePolicyBlock {
+		select {
+		case p.queue <- j:
+			return nil
+		case <-stop:
+			return ErrDispatcherClosed
+		}
+	}
+
+	select {
+	case p.queue <- j:
+		return nil
+	default:
+	}
+
+	if p.policy == QueuePolicyDrop {
+		if p.onDrop != nil {
+			p.onDrop(eventName)
+		}
+		return nil
+	}
+
+	return ErrQueueFull
+}
+
+// close impede novos jobs; os workers terminam depois de esvaziar a fila
+func (p *workerPool) close() {
+	close(p.queue)
+}
+
+func (p *workerPool) wait() {
+	p.wg.Wait()
+}
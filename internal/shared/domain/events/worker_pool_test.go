@@ -0,0 +1,248 @@
+package shared_events
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseQueuePolicy(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    QueuePolicy
+		wantErr bool
+	}{
+		{value: "", want: QueuePolicyBlock},
+		{value: "block", want: QueuePolicyBlock},
+		{value: "drop", want: QueuePolicyDrop},
+		{value: "error", want: QueuePolicyError},
+		{value: "ignore", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseQueuePolicy(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseQueuePolicy(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseQueuePolicy(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+// blockingDispatcher cria um dispatcher com um worker ocupado até release ser fechado
+func blockingDispatcher(t *testing.T, config DispatcherConfig) (*EventDispatcher, chan struct{}, *atomic.Int32) {
+	t.Helper()
+
+	dispatcher := NewEventDispatcherWithPool(config)
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	var handled atomic.Int32
+
+	dispatcher.Register("test.event", func(event Event) {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+		handled.Add(1)
+	})
+
+	// Ocupa o único worker; com a política block a fila sem buffer espera o worker ficar livre
+	if err := dispatcher.Dispatch("test.event", &mockEvent{name: "test.event"}); err != nil {
+		t.Fatalf("Dispatch() error = %v", err)
+	}
+	<-started
+
+	return dispatcher, release, &handled
+}
+
+func TestEventDispatcher_PoolQueuePolicies(t *testing.T) {
+	t.Run("error policy rejects when queue is full", func(t *testing.T) {
+		dispatcher, release, _ := blockingDispatcher(t, DispatcherConfig{Workers: 1, QueueSize: 1, Policy: QueuePolicyError})
+		defer close(release)
+
+		if err := dispatcher.Dispatch("test.event", &mockEvent{name: "test.event"}); err != nil {
+			t.Fatalf("Dispatch() with free queue slot error = %v", err)
+		}
+		if err := dispatcher.Dispatch("test.event", &mockEvent{name: "test.event"}); err != ErrQueueFull {
+			t.Errorf("Dispatch() with full queue error = %v, want %v", err, ErrQueueFull)
+		}
+	})
+
+	t.Run("drop policy discards and reports", func(t *testing.T) {
+		var dropped []string
+		var mu sync.Mutex
+		dispatcher, release, _ := blockingDispatcher(t, DispatcherConfig{
+			Workers:   1,
+			QueueSize: 1,
+			Policy:    QueuePolicyDrop,
+			OnDrop: func(eventName string) {
+				mu.Lock()
+				defer mu.Unlock()
+				dropped = append(dropped, eventName)
+			},
+		})
+		defer close(release)
+
+		// O primeiro ocupa a fila, o segundo é descartado
+		for i := 0; i < 2; i++ {
+			if err := dispatcher.Dispatch("test.event", &mockEvent{name: "test.event"}); err != nil {
+				t.Fatalf("Dispatch() with drop policy error = %v", err)
+			}
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if len(dropped) != 1 || dropped[0] != "test.event" {
+			t.Errorf("OnDrop calls = %v, want [test.event]", dropped)
+		}
+	})
+
+	t.Run("block policy waits for a free slot", func(t *testing.T) {
+		dispatcher, release, handled := blockingDispatcher(t, DispatcherConfig{Workers: 1, QueueSize: 0, Policy: QueuePolicyBlock})
+
+		accepted := make(chan error, 1)
+		go func() {
+			accepted <- dispatcher.Dispatch("test.event", &mockEvent{name: "test.event"})
+		}()
+
+		select {
+		case <-accepted:
+			t.Fatal("Dispatch() returned while the queue was full")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		close(release)
+		if err := <-accepted; err != nil {
+			t.Fatalf("Dispatch() error = %v", err)
+		}
+
+		if err := dispatcher.Shutdown(context.Background()); err != nil {
+			t.Fatalf("Shutdown() error = %v", err)
+		}
+		if got := handled.Load(); got != 2 {
+			t.Errorf("handled = %d, want 2", got)
+		}
+	})
+}
+
+func TestEventDispatcher_Shutdown(t *testing.T) {
+	t.Run("drains queued events", func(t *testing.T) {
+		dispatcher, release, handled := blockingDispatcher(t, DispatcherConfig{Workers: 1, QueueSize: 10})
+
+		for i := 0; i < 5; i++ {
+			if err := dispatcher.Dispatch("test.event", &mockEvent{name: "test.event"}); err != nil {
+				t.Fatalf("Dispatch() error = %v", err)
+			}
+		}
+		close(release)
+
+		if err := dispatcher.Shutdown(context.Background()); err != nil {
+			t.Fatalf("Shutdown() error = %v", err)
+		}
+		if got := handled.Load(); got != 6 {
+			t.Errorf("handled = %d, want 6", got)
+		}
+
+		if err := dispatcher.Dispatch("test.event", &mockEvent{name: "test.event"}); err != ErrDispatcherClosed {
+			t.Errorf("Dispatch() after Shutdown() error = %v, want %v", err, ErrDispatcherClosed)
+		}
+	})
+
+	t.Run("respects context deadline", func(t *testing.T) {
+		dispatcher, release, _ := blockingDispatcher(t, DispatcherConfig{Workers: 1, QueueSize: 1})
+		defer close(release)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		if err := dispatcher.Shutdown(ctx); err != context.DeadlineExceeded {
+			t.Errorf("Shutdown() error = %v, want %v", err, context.DeadlineExceeded)
+		}
+	})
+
+	t.Run("releases dispatches blocked on a full queue", func(t *testing.T) {
+		dispatcher, release, _ := blockingDispatcher(t, DispatcherConfig{Workers: 1, QueueSize: 0, Policy: QueuePolicyBlock})
+		defer close(release)
+
+		accepted := make(chan error, 1)
+		go func() {
+			accepted <- dispatcher.Dispatch("test.event", &mockEvent{name: "test.event"})
+		}()
+		time.Sleep(20 * time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		// O worker continua ocupado: Shutdown retorna no prazo e o envio bloqueado é recusado
+		if err := dispatcher.Shutdown(ctx); err != context.DeadlineExceeded {
+			t.Errorf("Shutdown() error = %v, want %v", err, context.DeadlineExceeded)
+		}
+		select {
+		case err := <-accepted:
+			if err != ErrDispatcherClosed {
+				t.Errorf("blocked Dispatch() error = %v, want %v", err, ErrDispatcherClosed)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("blocked Dispatch() did not return after Shutdown()")
+		}
+	})
+
+	t.Run("waits for DispatchAndWait", func(t *testing.T) {
+		dispatcher := NewEventDispatcherWithPool(DispatcherConfig{Workers: 1, QueueSize: 1})
+		started := make(chan struct{})
+		var handled atomic.Bool
+		dispatcher.Register("test.event", func(event Event) {
+			close(started)
+			time.Sleep(20 * time.Millisecond)
+			handled.Store(true)
+		})
+
+		go dispatcher.DispatchAndWait("test.event", &mockEvent{name: "test.event"})
+		<-started
+
+		if err := dispatcher.Shutdown(context.Background()); err != nil {
+			t.Fatalf("Shutdown() error = %v", err)
+		}
+		if !handled.Load() {
+			t.Error("Shutdown() returned before the DispatchAndWait handler finished")
+		}
+
+		if err := dispatcher.DispatchAndWait("test.event", &mockEvent{name: "test.event"}); err != ErrDispatcherClosed {
+			t.Errorf("DispatchAndWait() after Shutdown() error = %v, want %v", err, ErrDispatcherClosed)
+		}
+	})
+
+	t.Run("waits for unbounded handlers", func(t *testing.T) {
+		dispatcher := NewEventDispatcher()
+		var handled atomic.Bool
+		dispatcher.Register("test.event", func(event Event) {
+			time.Sleep(20 * time.Millisecond)
+			handled.Store(true)
+		})
+
+		_ = dispatcher.Dispatch("test.event", &mockEvent{name: "test.event"})
+
+		if err := dispatcher.Shutdown(context.Background()); err != nil {
+			t.Fatalf("Shutdown() error = %v", err)
+		}
+		if !handled.Load() {
+			t.Error("Shutdown() returned before the handler finished")
+		}
+	})
+
+	t.Run("is idempotent", func(t *testing.T) {
+		dispatcher := NewEventDispatcherWithPool(DispatcherConfig{Workers: 2, QueueSize: 2})
+
+		for i := 0; i < 2; i++ {
+			if err := dispatcher.Shutdown(context.Background()); err != nil {
+				t.Fatalf("Shutdown() call %d error = %v", i+1, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,63 @@
+package shared_identity
+
+import (
+	"testing"
+)
+
+func TestNewUUID(t *testing.T) {
+	id := NewUUID()
+
+	if !IsValidUUID(id) {
+		t.Fatalf("NewUUID() = %q, not a valid uuid", id)
+	}
+
+	if id[14] != '4' {
+		t.Errorf("NewUUID() version = %c, want 4", id[14])
+	}
+
+	switch id[19] {
+	case '8', '9', 'a', 'b':
+	default:
+		t.Errorf("NewUUID() variant = %c, want one of 8, 9, a, b", id[19])
+	}
+}
+
+func TestNewUUID_Unique(t *testing.T) {
+	seen := make(map[string]bool)
+
+	for i := 0; i < 1000; i++ {
+		id := NewUUID()
+		if seen[id] {
+			t.Fatalf("NewUUID() generated duplicate %q", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestIsValidUUID(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  bool
+	}{
+		{name: "valid uuid", input: "3f2b8c1e-4d5a-4b6c-8d7e-9f0a1b2c3d4e", want: true},
+		{name: "empty string", input: "", want: false},
+		{name: "uppercase", input: "3F2B8C1E-4D5A-4B6C-8D7E-9F0A1B2C3D4E", want: false},
+		{name: "missing dashes", input: "3f2b8c1e4d5a4b6c8d7e9f0a1b2c3d4e", want: false},
+		{name: "product name", input: "Notebook", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsValidUUID(tt.input); got != tt.want {
+				t.Errorf("IsValidUUID(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func BenchmarkNewUUID(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_ = NewUUID()
+	}
+}
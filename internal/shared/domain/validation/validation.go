@@ -0,0 +1,71 @@
+package shared_validation
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrValidation é o tipo dos erros de validação das entidades que não têm um pacote de
+// erros próprio (categorias e webhooks). Use errors.Is para classificar um erro.
+var ErrValidation = errors.New("validation failed")
+
+// Códigos das violações, estáveis para que os clientes possam tratá-las sem ler a mensagem.
+// Seguem os de product_errors.
+const (
+	CodeRequired = "required"
+	CodeInvalid  = "invalid"
+	CodeTooLong  = "too_long"
+	CodeTooShort = "too_short"
+)
+
+// Error é a violação de uma regra por um campo
+type Error struct {
+	Field   string
+	Code    string
+	Message string
+}
+
+func NewError(field, code, message string) *Error {
+	return &Error{Field: field, Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Is faz errors.Is(err, ErrValidation) casar com qualquer violação
+func (e *Error) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// Errors reúne todas as violações encontradas ao validar uma entidade
+type Errors []*Error
+
+func (v Errors) Error() string {
+	messages := make([]string, len(v))
+	for i, err := range v {
+		messages[i] = err.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (v Errors) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// Unwrap expõe cada violação para errors.As
+func (v Errors) Unwrap() []error {
+	errs := make([]error, len(v))
+	for i, err := range v {
+		errs[i] = err
+	}
+	return errs
+}
+
+// Err retorna as violações como error, ou nil se não há nenhuma
+func (v Errors) Err() error {
+	if len(v) == 0 {
+		return nil
+	}
+	return v
+}
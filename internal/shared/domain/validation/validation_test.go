@@ -0,0 +1,35 @@
+package shared_validation
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestErrors(t *testing.T) {
+	err := fmt.Errorf("erro ao validar categoria: %w", Errors{
+		NewError("name", CodeRequired, "name is required"),
+		NewError("slug", CodeTooLong, "slug must have at most 100 characters"),
+	}.Err())
+
+	if !errors.Is(err, ErrValidation) {
+		t.Errorf("errors.Is(%v, ErrValidation) = false", err)
+	}
+	if err.Error() != "erro ao validar categoria: name is required; slug must have at most 100 characters" {
+		t.Errorf("Error() = %q", err.Error())
+	}
+
+	var violations Errors
+	if !errors.As(err, &violations) || len(violations) != 2 || violations[1].Field != "slug" || violations[1].Code != CodeTooLong {
+		t.Errorf("errors.As(Errors) = %v", violations)
+	}
+
+	var first *Error
+	if !errors.As(err, &first) || first.Field != "name" {
+		t.Errorf("errors.As(*Error) = %v, want the first violation", first)
+	}
+
+	if Errors(nil).Err() != nil {
+		t.Error("Err() without violations should be nil")
+	}
+}
@@ -0,0 +1,165 @@
+package shared_idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	shared_identity "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/identity"
+)
+
+var (
+	ErrKeyReused         = errors.New("idempotency key already used with a different request")
+	ErrRequestInProgress = errors.New("a request with this idempotency key is still in progress")
+)
+
+// PendingTimeout é o tempo após o qual uma reserva sem resposta é considerada abandonada
+// (processo reiniciado no meio da requisição) e a chave pode ser reservada de novo
+const PendingTimeout = time.Minute
+
+// Response é a resposta guardada para ser repetida nos reenvios da mesma chave
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// Record é o estado de uma chave: Response é nil enquanto a requisição original está em andamento
+type Record struct {
+	Key         string
+	Fingerprint string
+	// Token identifica a reserva; só quem a fez pode completá-la ou liberá-la
+	Token     string
+	Response  *Response
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// Store guarda as chaves de idempotência com a resposta da primeira requisição
+type Store interface {
+	// Reserve grava a chave como em andamento se ela não existir, tiver expirado ou a
+	// reserva tiver sido abandonada. Se a chave já existe, retorna o registro guardado
+	// e reserved = false.
+	Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (record Record, reserved bool, err error)
+
+	// Complete guarda a resposta da requisição que reservou a chave. Não faz nada se a
+	// reserva do token foi abandonada e a chave reservada de novo por outra requisição.
+	Complete(ctx context.Context, key, token string, response Response) error
+
+	// Release remove a reserva do token para que a requisição possa ser repetida (falha do
+	// servidor)
+	Release(ctx context.Context, key, token string) error
+}
+
+// Fingerprint identifica a requisição (método, URI e corpo) pelo hash SHA-256 das partes
+func Fingerprint(parts ...[]byte) string {
+	hash := sha256.New()
+	for _, part := range parts {
+		// O tamanho de cada parte evita que partes diferentes concatenadas coincidam
+		var size [8]byte
+		binary.BigEndian.PutUint64(size[:], uint64(len(part)))
+		hash.Write(size[:])
+		hash.Write(part)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// available indica se o registro guardado pode ser substituído por uma nova reserva
+func (r Record) available(now time.Time) bool {
+	if !now.Before(r.ExpiresAt) {
+		return true
+	}
+	return r.Response == nil && !now.Before(r.CreatedAt.Add(PendingTimeout))
+}
+
+// InMemoryStore guarda as chaves em memória; adequado para uma única instância e para testes
+type InMemoryStore struct {
+	mu        sync.Mutex
+	records   map[string]Record
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewInMemoryStore() *InMemoryStore {
+	return &InMemoryStore{records: make(map[string]Record), now: time.Now}
+}
+
+func (s *InMemoryStore) Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (Record, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return Record{}, false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	if record, ok := s.records[key]; ok && !record.available(now) {
+		return copyRecord(record), false, nil
+	}
+
+	record := Record{Key: key, Fingerprint: fingerprint, Token: shared_identity.NewUUID(), CreatedAt: now, ExpiresAt: now.Add(ttl)}
+	s.records[key] = record
+
+	return record, true, nil
+}
+
+func (s *InMemoryStore) Complete(ctx context.Context, key, token string, response Response) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.records[key]
+	if !ok || record.Token != token || record.Response != nil {
+		return nil
+	}
+
+	stored := Response{Status: response.Status, Header: response.Header.Clone(), Body: append([]byte(nil), response.Body...)}
+	record.Response = &stored
+	s.records[key] = record
+
+	return nil
+}
+
+func (s *InMemoryStore) Release(ctx context.Context, key, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if record, ok := s.records[key]; ok && record.Token == token && record.Response == nil {
+		delete(s.records, key)
+	}
+
+	return nil
+}
+
+// sweep remove as chaves expiradas, no máximo uma vez por minuto; exige o lock
+func (s *InMemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+
+	for key, record := range s.records {
+		if !now.Before(record.ExpiresAt) {
+			delete(s.records, key)
+		}
+	}
+}
+
+// copyRecord evita que quem chama altere a resposta guardada; o token da reserva é só
+// de quem a fez
+func copyRecord(record Record) Record {
+	record.Token = ""
+	if record.Response != nil {
+		response := *record.Response
+		response.Header = response.Header.Clone()
+		response.Body = append([]byte(nil), response.Body...)
+		record.Response = &response
+	}
+	return record
+}
@@ -0,0 +1,115 @@
+package shared_idempotency
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestFingerprint(t *testing.T) {
+	a := Fingerprint([]byte("POST"), []byte("/api/v1/products"), []byte(`{"name":"Notebook"}`))
+
+	if a != Fingerprint([]byte("POST"), []byte("/api/v1/products"), []byte(`{"name":"Notebook"}`)) {
+		t.Error("Fingerprint() should be deterministic")
+	}
+	if a == Fingerprint([]byte("POST"), []byte("/api/v1/products"), []byte(`{"name":"Mouse"}`)) {
+		t.Error("Fingerprint() should change with the body")
+	}
+	if Fingerprint([]byte("ab"), []byte("c")) == Fingerprint([]byte("a"), []byte("bc")) {
+		t.Error("Fingerprint() should separate the parts")
+	}
+}
+
+func TestInMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	store := NewInMemoryStore()
+	store.now = func() time.Time { return now }
+
+	first, reserved, err := store.Reserve(ctx, "key-1", "fp", time.Hour)
+	if err != nil || !reserved || first.Token == "" {
+		t.Fatalf("Reserve() = %+v, %v, %v; want reserved with a token", first, reserved, err)
+	}
+
+	t.Run("pending reservation", func(t *testing.T) {
+		record, reserved, _ := store.Reserve(ctx, "key-1", "fp", time.Hour)
+		if reserved || record.Response != nil || record.Fingerprint != "fp" || record.Token != "" {
+			t.Errorf("Reserve() = %+v, %v; want the pending record without the token", record, reserved)
+		}
+	})
+
+	t.Run("other token", func(t *testing.T) {
+		store.Complete(ctx, "key-1", "other-token", Response{Status: http.StatusOK})
+		store.Release(ctx, "key-1", "other-token")
+		record, reserved, _ := store.Reserve(ctx, "key-1", "fp", time.Hour)
+		if reserved || record.Response != nil {
+			t.Errorf("Reserve() = %+v, %v; another token should not complete or release the key", record, reserved)
+		}
+	})
+
+	t.Run("completed reservation", func(t *testing.T) {
+		header := http.Header{"Content-Type": []string{"application/json"}}
+		if err := store.Complete(ctx, "key-1", first.Token, Response{Status: http.StatusCreated, Header: header, Body: []byte(`{"id":"1"}`)}); err != nil {
+			t.Fatalf("Complete() error = %v", err)
+		}
+
+		record, reserved, _ := store.Reserve(ctx, "key-1", "other", time.Hour)
+		if reserved || record.Response == nil || record.Response.Status != http.StatusCreated || string(record.Response.Body) != `{"id":"1"}` {
+			t.Errorf("Reserve() = %+v, %v; want the stored response", record, reserved)
+		}
+
+		// O registro retornado é uma cópia
+		record.Response.Body[0] = 'X'
+		again, _, _ := store.Reserve(ctx, "key-1", "fp", time.Hour)
+		if string(again.Response.Body) != `{"id":"1"}` {
+			t.Errorf("stored body changed to %s", again.Response.Body)
+		}
+	})
+
+	t.Run("release keeps completed responses", func(t *testing.T) {
+		store.Release(ctx, "key-1", first.Token)
+		if _, reserved, _ := store.Reserve(ctx, "key-1", "fp", time.Hour); reserved {
+			t.Error("Release() should not remove a completed key")
+		}
+	})
+
+	t.Run("released reservation", func(t *testing.T) {
+		record, _, _ := store.Reserve(ctx, "key-2", "fp", time.Hour)
+		store.Release(ctx, "key-2", record.Token)
+		if _, reserved, _ := store.Reserve(ctx, "key-2", "fp", time.Hour); !reserved {
+			t.Error("Reserve() after Release() should reserve again")
+		}
+	})
+
+	t.Run("abandoned reservation", func(t *testing.T) {
+		abandoned, _, _ := store.Reserve(ctx, "key-3", "fp", time.Hour)
+		now = now.Add(PendingTimeout)
+		current, reserved, _ := store.Reserve(ctx, "key-3", "fp", time.Hour)
+		if !reserved || current.Token == abandoned.Token {
+			t.Fatalf("Reserve() = %+v, %v; should take over an abandoned reservation", current, reserved)
+		}
+
+		// A requisição original, mais lenta que o PendingTimeout, não altera a reserva nova
+		store.Complete(ctx, "key-3", abandoned.Token, Response{Status: http.StatusCreated})
+		store.Release(ctx, "key-3", abandoned.Token)
+		if record := store.records["key-3"]; record.Token != current.Token || record.Response != nil {
+			t.Errorf("record = %+v; the abandoned reservation overwrote the current one", record)
+		}
+
+		store.Complete(ctx, "key-3", current.Token, Response{Status: http.StatusOK})
+		if record := store.records["key-3"]; record.Response == nil || record.Response.Status != http.StatusOK {
+			t.Errorf("record = %+v; want the response of the current reservation", record)
+		}
+	})
+
+	t.Run("expired key", func(t *testing.T) {
+		now = now.Add(2 * time.Hour)
+		if _, reserved, _ := store.Reserve(ctx, "key-1", "other", time.Hour); !reserved {
+			t.Error("Reserve() should reuse an expired key")
+		}
+		if len(store.records) != 1 {
+			t.Errorf("expired keys were not swept: %d records", len(store.records))
+		}
+	})
+}
@@ -0,0 +1,85 @@
+package shared_jobs
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	shared_identity "github.com/williamkoller/golang-domain-driven-design/internal/shared/domain/identity"
+)
+
+var (
+	ErrJobNotFound    = errors.New("job not found")
+	ErrJobFinished    = errors.New("job already finished")
+	ErrJobNotFinished = errors.New("job has not finished yet")
+	ErrUnknownJobType = errors.New("unknown job type")
+)
+
+// Status é a situação de um job
+type Status string
+
+const (
+	StatusQueued    Status = "queued"    // Aguardando um worker
+	StatusRunning   Status = "running"   // Em execução por um worker
+	StatusSucceeded Status = "succeeded" // Concluído; o resultado está em Result e ResultFile
+	StatusFailed    Status = "failed"    // Concluído com erro, descrito em Error
+	StatusCanceled  Status = "canceled"  // Cancelado antes de concluir
+)
+
+// Finished indica se o job não vai mais ser executado
+func (s Status) Finished() bool {
+	return s == StatusSucceeded || s == StatusFailed || s == StatusCanceled
+}
+
+// Progress é o andamento do job; Total é 0 enquanto não é conhecido
+type Progress struct {
+	Processed int `json:"processed" example:"1500"`
+	Total     int `json:"total,omitempty" example:"10000"`
+}
+
+// Job é uma operação demorada executada em segundo plano por um worker do Pool.
+// Params e Input são os dados da operação, lidos pelo Runner do tipo do job; Result é o
+// resumo JSON do resultado e ResultFile o arquivo gerado, se houver.
+type Job struct {
+	ID     string          `json:"id" example:"3f2b8c1e-4d5a-4b6c-8d7e-9f0a1b2c3d4e"`
+	Type   string          `json:"type" example:"product.import"`
+	Status Status          `json:"status" example:"running" enums:"queued,running,succeeded,failed,canceled"`
+	Params json.RawMessage `json:"params,omitempty" swaggertype:"object"`
+	// Input é o conteúdo enviado na criação (o arquivo da importação); não é exposto na API
+	Input         []byte   `json:"-"`
+	CorrelationID string   `json:"correlation_id,omitempty" example:"7b0c2f4e-1a2b-4c3d-9e8f-0a1b2c3d4e5f"`
+	Progress      Progress `json:"progress"`
+	// Result é o resumo do resultado, como o relatório da importação
+	Result json.RawMessage `json:"result,omitempty" swaggertype:"object"`
+	// ResultFile é o caminho do arquivo gerado, como o da exportação, e ResultType o seu Content-Type
+	ResultFile      string     `json:"-"`
+	ResultType      string     `json:"-"`
+	Error           string     `json:"error,omitempty" example:"import aborted: 2 rows were not imported"`
+	Attempts        int        `json:"attempts" example:"1"`
+	CancelRequested bool       `json:"cancel_requested,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	StartedAt       *time.Time `json:"started_at,omitempty"`
+	HeartbeatAt     *time.Time `json:"-"`
+	FinishedAt      *time.Time `json:"finished_at,omitempty"`
+}
+
+// NewJob cria um job na fila
+func NewJob(jobType string, params json.RawMessage, input []byte, correlationID string) Job {
+	now := time.Now().UTC()
+	return Job{
+		ID:            shared_identity.NewUUID(),
+		Type:          jobType,
+		Status:        StatusQueued,
+		Params:        params,
+		Input:         input,
+		CorrelationID: correlationID,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+}
+
+// HasResultFile indica se o job concluiu com um arquivo para download
+func (j Job) HasResultFile() bool {
+	return j.Status == StatusSucceeded && j.ResultFile != ""
+}
@@ -0,0 +1,63 @@
+package shared_jobs
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNewJob(t *testing.T) {
+	job := NewJob("product.import", json.RawMessage(`{"mode":"best_effort"}`), []byte("name,sku"), "corr-1")
+
+	if job.ID == "" || job.Type != "product.import" || job.Status != StatusQueued || job.CorrelationID != "corr-1" {
+		t.Errorf("NewJob() = %+v", job)
+	}
+	if job.CreatedAt.IsZero() || !job.CreatedAt.Equal(job.UpdatedAt) || job.StartedAt != nil || job.FinishedAt != nil {
+		t.Errorf("NewJob() timestamps = %v, %v, %v, %v", job.CreatedAt, job.UpdatedAt, job.StartedAt, job.FinishedAt)
+	}
+
+	data, _ := json.Marshal(job)
+	var fields map[string]any
+	json.Unmarshal(data, &fields)
+	if _, ok := fields["Input"]; ok {
+		t.Error("the input should not be serialized")
+	}
+	if _, ok := fields["result_file"]; ok {
+		t.Error("the result file path should not be serialized")
+	}
+}
+
+func TestStatus_Finished(t *testing.T) {
+	tests := map[Status]bool{
+		StatusQueued:    false,
+		StatusRunning:   false,
+		StatusSucceeded: true,
+		StatusFailed:    true,
+		StatusCanceled:  true,
+	}
+
+	for status, want := range tests {
+		if got := status.Finished(); got != want {
+			t.Errorf("%s.Finished() = %v, want %v", status, got, want)
+		}
+	}
+}
+
+func TestJob_HasResultFile(t *testing.T) {
+	tests := []struct {
+		name string
+		job  Job
+		want bool
+	}{
+		{name: "succeeded with file", job: Job{Status: StatusSucceeded, ResultFile: "/tmp/a.csv"}, want: true},
+		{name: "succeeded without file", job: Job{Status: StatusSucceeded}, want: false},
+		{name: "failed with file", job: Job{Status: StatusFailed, ResultFile: "/tmp/a.csv"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.job.HasResultFile(); got != tt.want {
+				t.Errorf("HasResultFile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
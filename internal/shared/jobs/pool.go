@@ -0,0 +1,415 @@
+package shared_jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// finishTimeout limita a gravação do fim de um job, feita fora do ctx já cancelado da execução
+const finishTimeout = 5 * time.Second
+
+// Runner executa um job do tipo em que foi registrado. O ctx é cancelado quando o
+// cancelamento do job é pedido ou o Pool é encerrado; progress recebe o andamento.
+// O Outcome é gravado também quando há erro (por exemplo, o relatório de uma importação
+// recusada), mas o arquivo de resultado só é mantido se o job concluir.
+type Runner func(ctx context.Context, job Job, progress *Reporter) (Outcome, error)
+
+// Outcome é o resultado de um Runner: Result é serializado em JSON e ResultFile é o
+// arquivo gerado, entregue para download com o Content-Type ResultType
+type Outcome struct {
+	Result     any
+	ResultFile string
+	ResultType string
+}
+
+// Reporter recebe o andamento do job, gravado a cada heartbeat; o valor zero está pronto para uso
+type Reporter struct {
+	mu       sync.Mutex
+	progress Progress
+}
+
+// Report registra quantos itens já foram processados de um total (0 se desconhecido)
+func (r *Reporter) Report(processed, total int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.progress = Progress{Processed: processed, Total: total}
+}
+
+// Progress retorna o último andamento registrado
+func (r *Reporter) Progress() Progress {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.progress
+}
+
+// PoolConfig contém as configurações do pool de workers
+type PoolConfig struct {
+	Workers           int           // Jobs executados ao mesmo tempo
+	PollInterval      time.Duration // Intervalo entre leituras da fila vazia
+	HeartbeatInterval time.Duration // Intervalo entre as gravações do andamento de um job em execução
+	StaleAfter        time.Duration // Tempo sem heartbeat após o qual um job em execução é dado como abandonado
+	MaxAttempts       int           // Execuções de um job interrompido antes de ele falhar
+	Retention         time.Duration // Tempo que os jobs terminados e os seus arquivos são mantidos
+	CleanupInterval   time.Duration // Intervalo entre limpezas dos jobs terminados
+}
+
+// DefaultPoolConfig retorna a configuração padrão do pool
+func DefaultPoolConfig() PoolConfig {
+	return PoolConfig{
+		Workers:           2,
+		PollInterval:      time.Second,
+		HeartbeatInterval: 5 * time.Second,
+		StaleAfter:        time.Minute,
+		MaxAttempts:       3,
+		Retention:         24 * time.Hour,
+		CleanupInterval:   time.Hour,
+	}
+}
+
+// Pool executa os jobs da fila do Store com um número fixo de workers.
+//
+// Um job em execução grava heartbeats; se o processo termina sem concluí-lo, o job é
+// devolvido à fila quando o heartbeat fica mais antigo que StaleAfter e é executado de
+// novo do início, até MaxAttempts vezes. No encerramento pelo Stop, os jobs em execução
+// são interrompidos e devolvidos à fila na hora, para a próxima execução da aplicação.
+type Pool struct {
+	store   Store
+	config  PoolConfig
+	runners map[string]Runner
+	wake    chan struct{}
+
+	mu      sync.Mutex
+	running map[string]*execution
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	once   sync.Once
+}
+
+// execution é um job em execução neste processo
+type execution struct {
+	cancel   context.CancelFunc
+	canceled atomic.Bool // Cancelamento pedido pelo cliente
+	lost     atomic.Bool // O job deixou de estar em execução no Store (recuperado por outra instância)
+}
+
+func NewPool(store Store, config PoolConfig) *Pool {
+	if config.Workers <= 0 {
+		config.Workers = 1
+	}
+	return &Pool{
+		store:   store,
+		config:  config,
+		runners: make(map[string]Runner),
+		wake:    make(chan struct{}, config.Workers),
+		running: make(map[string]*execution),
+	}
+}
+
+// Register associa o Runner ao tipo de job; deve ser chamado antes do Start
+func (p *Pool) Register(jobType string, runner Runner) {
+	p.runners[jobType] = runner
+}
+
+// Enqueue grava o job na fila e acorda um worker livre
+func (p *Pool) Enqueue(ctx context.Context, job Job) (Job, error) {
+	if _, ok := p.runners[job.Type]; !ok {
+		return Job{}, fmt.Errorf("%w: %s", ErrUnknownJobType, job.Type)
+	}
+
+	if err := p.store.Create(ctx, job); err != nil {
+		return Job{}, err
+	}
+
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+
+	job.Input = nil
+	return job, nil
+}
+
+// Find busca o job pelo ID
+func (p *Pool) Find(ctx context.Context, id string) (Job, error) {
+	return p.store.Find(ctx, id)
+}
+
+// Cancel cancela o job na fila, ou interrompe o job em execução: neste processo na hora,
+// em outra instância no próximo heartbeat. O job retornado continua em execução até o
+// worker parar.
+func (p *Pool) Cancel(ctx context.Context, id string) (Job, error) {
+	job, err := p.store.Cancel(ctx, id)
+	if err != nil {
+		return Job{}, err
+	}
+
+	p.mu.Lock()
+	if exec, ok := p.running[id]; ok {
+		exec.canceled.Store(true)
+		exec.cancel()
+	}
+	p.mu.Unlock()
+
+	return job, nil
+}
+
+// Start inicia os workers e a manutenção da fila; use Stop para encerrá-los
+func (p *Pool) Start() {
+	p.once.Do(func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		p.cancel = cancel
+		p.done = make(chan struct{})
+
+		var wg sync.WaitGroup
+		wg.Add(p.config.Workers + 1)
+		for i := 0; i < p.config.Workers; i++ {
+			go func() {
+				defer wg.Done()
+				p.work(ctx)
+			}()
+		}
+		go func() {
+			defer wg.Done()
+			p.maintain(ctx)
+		}()
+
+		go func() {
+			wg.Wait()
+			close(p.done)
+		}()
+	})
+}
+
+// Stop interrompe os jobs em execução, que voltam para a fila, e espera os workers
+// terminarem, respeitando o prazo do ctx
+func (p *Pool) Stop(ctx context.Context) error {
+	if p.cancel == nil {
+		return nil
+	}
+	p.cancel()
+
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *Pool) work(ctx context.Context) {
+	ticker := time.NewTicker(p.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Continuar executando enquanto houver jobs na fila
+		for ctx.Err() == nil {
+			ran, err := p.RunNext(ctx)
+			if err != nil {
+				log.Printf("❌ Erro ao buscar job na fila: %v", err)
+				break
+			}
+			if !ran {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-p.wake:
+		}
+	}
+}
+
+// maintain devolve à fila os jobs abandonados, já na partida, e remove os jobs antigos
+func (p *Pool) maintain(ctx context.Context) {
+	ticker := time.NewTicker(p.config.StaleAfter)
+	defer ticker.Stop()
+
+	lastCleanup := time.Time{}
+
+	for {
+		if recovered, err := p.store.Recover(ctx, time.Now().UTC().Add(-p.config.StaleAfter), p.config.MaxAttempts); err != nil {
+			log.Printf("❌ Erro ao recuperar jobs abandonados: %v", err)
+		} else if recovered > 0 {
+			log.Printf("♻️  %d jobs abandonados recuperados", recovered)
+		}
+
+		if time.Since(lastCleanup) >= p.config.CleanupInterval {
+			if _, err := p.Purge(ctx); err != nil {
+				log.Printf("❌ Erro ao limpar jobs terminados: %v", err)
+			}
+			lastCleanup = time.Now()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge remove os jobs terminados há mais tempo que a retenção e os seus arquivos
+func (p *Pool) Purge(ctx context.Context) (int, error) {
+	purged, err := p.store.Purge(ctx, time.Now().UTC().Add(-p.config.Retention))
+	if err != nil {
+		return 0, err
+	}
+
+	for _, job := range purged {
+		removeResultFile(job.ResultFile)
+	}
+	return len(purged), nil
+}
+
+// RunNext executa o próximo job da fila e retorna se havia algum. O ctx é o do worker:
+// cancelá-lo interrompe o job, que volta para a fila.
+func (p *Pool) RunNext(ctx context.Context) (bool, error) {
+	job, ok, err := p.store.Claim(ctx)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	p.execute(ctx, job)
+	return true, nil
+}
+
+func (p *Pool) execute(ctx context.Context, job Job) {
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	exec := &execution{cancel: cancel}
+	p.mu.Lock()
+	p.running[job.ID] = exec
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		delete(p.running, job.ID)
+		p.mu.Unlock()
+	}()
+
+	reporter := &Reporter{}
+	heartbeatDone := make(chan struct{})
+	go func() {
+		defer close(heartbeatDone)
+		p.heartbeat(runCtx, job.ID, reporter, exec)
+	}()
+
+	outcome, err := p.run(runCtx, job, reporter)
+	cancel()
+	<-heartbeatDone
+
+	finishCtx, cancelFinish := context.WithTimeout(context.Background(), finishTimeout)
+	defer cancelFinish()
+
+	switch {
+	case exec.lost.Load():
+		removeResultFile(outcome.ResultFile)
+		log.Printf("⚠️  Job %s (%s) não está mais em execução; resultado descartado", job.ID, job.Type)
+		return
+	case err != nil && exec.canceled.Load():
+		job.Status = StatusCanceled
+	case err != nil && ctx.Err() != nil:
+		// Encerramento do worker: o job volta para a fila e é executado de novo do início
+		removeResultFile(outcome.ResultFile)
+		if err := p.store.Requeue(finishCtx, job.ID); err != nil {
+			log.Printf("❌ Erro ao devolver o job %s à fila: %v", job.ID, err)
+			return
+		}
+		log.Printf("⏸️  Job %s (%s) interrompido pelo encerramento; devolvido à fila", job.ID, job.Type)
+		return
+	case err != nil:
+		job.Status, job.Error = StatusFailed, err.Error()
+	default:
+		job.Status = StatusSucceeded
+	}
+
+	job.Progress = reporter.Progress()
+	if outcome.Result != nil {
+		result, marshalErr := json.Marshal(outcome.Result)
+		if marshalErr != nil {
+			job.Status, job.Error = StatusFailed, fmt.Sprintf("invalid job result: %v", marshalErr)
+		}
+		job.Result = result
+	}
+	if job.Status == StatusSucceeded {
+		job.ResultFile, job.ResultType = outcome.ResultFile, outcome.ResultType
+	} else {
+		removeResultFile(outcome.ResultFile)
+	}
+
+	if err := p.store.Finish(finishCtx, job); err != nil {
+		log.Printf("❌ Erro ao gravar o fim do job %s: %v", job.ID, err)
+		return
+	}
+	if job.Status == StatusFailed {
+		log.Printf("❌ Job %s (%s) falhou: %s", job.ID, job.Type, job.Error)
+	}
+}
+
+// run executa o Runner do job, convertendo um panic em falha do job
+func (p *Pool) run(ctx context.Context, job Job, reporter *Reporter) (outcome Outcome, err error) {
+	runner, ok := p.runners[job.Type]
+	if !ok {
+		return Outcome{}, fmt.Errorf("%w: %s", ErrUnknownJobType, job.Type)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+
+	return runner(ctx, job, reporter)
+}
+
+// heartbeat grava o andamento do job a cada HeartbeatInterval e interrompe a execução
+// quando o cancelamento é pedido em outra instância ou o job deixa de estar em execução
+func (p *Pool) heartbeat(ctx context.Context, id string, reporter *Reporter, exec *execution) {
+	ticker := time.NewTicker(p.config.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		cancelRequested, err := p.store.Heartbeat(ctx, id, reporter.Progress())
+		switch {
+		case errors.Is(err, ErrJobFinished), errors.Is(err, ErrJobNotFound):
+			exec.lost.Store(true)
+			exec.cancel()
+			return
+		case err != nil:
+			if ctx.Err() == nil {
+				log.Printf("❌ Erro ao gravar o heartbeat do job %s: %v", id, err)
+			}
+		case cancelRequested:
+			exec.canceled.Store(true)
+			exec.cancel()
+			return
+		}
+	}
+}
+
+// removeResultFile apaga o arquivo de resultado de um job, se houver
+func removeResultFile(path string) {
+	if path == "" {
+		return
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Printf("❌ Erro ao remover o arquivo %s: %v", path, err)
+	}
+}
@@ -0,0 +1,256 @@
+package shared_jobs
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func testPoolConfig() PoolConfig {
+	config := DefaultPoolConfig()
+	config.PollInterval = 10 * time.Millisecond
+	config.HeartbeatInterval = 10 * time.Millisecond
+	return config
+}
+
+// waitForStatus espera o job chegar ao status, falhando o teste após 2s
+func waitForStatus(t *testing.T, pool *Pool, id string, status Status) Job {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		job, err := pool.Find(context.Background(), id)
+		if err == nil && job.Status == status {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s status = %s (%v), want %s", id, job.Status, err, status)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestPool_Enqueue(t *testing.T) {
+	pool := NewPool(NewInMemoryStore(), testPoolConfig())
+	pool.Register("known", func(ctx context.Context, job Job, progress *Reporter) (Outcome, error) {
+		return Outcome{}, nil
+	})
+
+	if _, err := pool.Enqueue(context.Background(), NewJob("unknown", nil, nil, "")); !errors.Is(err, ErrUnknownJobType) {
+		t.Errorf("Enqueue(unknown) error = %v, want ErrUnknownJobType", err)
+	}
+
+	job, err := pool.Enqueue(context.Background(), NewJob("known", nil, []byte("input"), ""))
+	if err != nil || job.Status != StatusQueued || job.Input != nil {
+		t.Errorf("Enqueue() = %+v, %v", job, err)
+	}
+}
+
+func TestPool_RunNext(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	pool := NewPool(NewInMemoryStore(), testPoolConfig())
+	pool.Register("succeed", func(ctx context.Context, job Job, progress *Reporter) (Outcome, error) {
+		path := filepath.Join(dir, job.ID+".csv")
+		os.WriteFile(path, []byte("id\n"), 0o644)
+		progress.Report(10, 10)
+		return Outcome{Result: map[string]int{"products": 10}, ResultFile: path, ResultType: "text/csv"}, nil
+	})
+	pool.Register("fail", func(ctx context.Context, job Job, progress *Reporter) (Outcome, error) {
+		path := filepath.Join(dir, job.ID+".csv")
+		os.WriteFile(path, []byte("id\n"), 0o644)
+		progress.Report(1, 10)
+		return Outcome{Result: map[string]int{"failed": 1}, ResultFile: path}, errors.New("import aborted")
+	})
+	pool.Register("panic", func(ctx context.Context, job Job, progress *Reporter) (Outcome, error) {
+		panic("boom")
+	})
+
+	if ran, err := pool.RunNext(ctx); ran || err != nil {
+		t.Fatalf("RunNext() on an empty queue = %v, %v", ran, err)
+	}
+
+	t.Run("succeeded", func(t *testing.T) {
+		queued, _ := pool.Enqueue(ctx, NewJob("succeed", nil, nil, ""))
+		if ran, err := pool.RunNext(ctx); !ran || err != nil {
+			t.Fatalf("RunNext() = %v, %v", ran, err)
+		}
+
+		job, _ := pool.Find(ctx, queued.ID)
+		if job.Status != StatusSucceeded || string(job.Result) != `{"products":10}` || job.Progress.Processed != 10 {
+			t.Errorf("job = %+v", job)
+		}
+		if !job.HasResultFile() || job.ResultType != "text/csv" {
+			t.Errorf("result file = %q (%s)", job.ResultFile, job.ResultType)
+		}
+	})
+
+	t.Run("failed keeps the result and removes the file", func(t *testing.T) {
+		queued, _ := pool.Enqueue(ctx, NewJob("fail", nil, nil, ""))
+		pool.RunNext(ctx)
+
+		job, _ := pool.Find(ctx, queued.ID)
+		if job.Status != StatusFailed || job.Error != "import aborted" || string(job.Result) != `{"failed":1}` || job.ResultFile != "" {
+			t.Errorf("job = %+v", job)
+		}
+		if _, err := os.Stat(filepath.Join(dir, job.ID+".csv")); !os.IsNotExist(err) {
+			t.Errorf("the result file of a failed job should be removed, stat error = %v", err)
+		}
+	})
+
+	t.Run("panic fails the job", func(t *testing.T) {
+		queued, _ := pool.Enqueue(ctx, NewJob("panic", nil, nil, ""))
+		pool.RunNext(ctx)
+
+		if job, _ := pool.Find(ctx, queued.ID); job.Status != StatusFailed || job.Error != "job panicked: boom" {
+			t.Errorf("job = %+v", job)
+		}
+	})
+}
+
+// blockingRunner roda até o ctx ser cancelado, avisando em started quando começa
+func blockingRunner(started chan<- string) Runner {
+	return func(ctx context.Context, job Job, progress *Reporter) (Outcome, error) {
+		progress.Report(1, 0)
+		started <- job.ID
+		<-ctx.Done()
+		return Outcome{}, ctx.Err()
+	}
+}
+
+func TestPool_Cancel(t *testing.T) {
+	ctx := context.Background()
+	store := NewInMemoryStore()
+	pool := NewPool(store, testPoolConfig())
+	started := make(chan string, 1)
+	pool.Register("block", blockingRunner(started))
+
+	t.Run("running in this process", func(t *testing.T) {
+		queued, _ := pool.Enqueue(ctx, NewJob("block", nil, nil, ""))
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			pool.RunNext(ctx)
+		}()
+		<-started
+
+		job, err := pool.Cancel(ctx, queued.ID)
+		if err != nil || !job.CancelRequested {
+			t.Fatalf("Cancel() = %+v, %v", job, err)
+		}
+		<-done
+
+		if job, _ := pool.Find(ctx, queued.ID); job.Status != StatusCanceled {
+			t.Errorf("status = %s, want canceled", job.Status)
+		}
+		if _, err := pool.Cancel(ctx, queued.ID); !errors.Is(err, ErrJobFinished) {
+			t.Errorf("Cancel() twice error = %v, want ErrJobFinished", err)
+		}
+	})
+
+	t.Run("requested by another instance", func(t *testing.T) {
+		queued, _ := pool.Enqueue(ctx, NewJob("block", nil, nil, ""))
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			pool.RunNext(ctx)
+		}()
+		<-started
+
+		// O pedido gravado direto no Store chega ao worker pelo heartbeat
+		store.Cancel(ctx, queued.ID)
+		<-done
+
+		if job, _ := pool.Find(ctx, queued.ID); job.Status != StatusCanceled {
+			t.Errorf("status = %s, want canceled", job.Status)
+		}
+	})
+
+	t.Run("queued", func(t *testing.T) {
+		queued, _ := pool.Enqueue(ctx, NewJob("block", nil, nil, ""))
+		if job, err := pool.Cancel(ctx, queued.ID); err != nil || job.Status != StatusCanceled {
+			t.Errorf("Cancel() = %+v, %v", job, err)
+		}
+	})
+}
+
+func TestPool_StopRequeuesRunningJobs(t *testing.T) {
+	ctx := context.Background()
+	started := make(chan string, 1)
+	pool := NewPool(NewInMemoryStore(), testPoolConfig())
+	pool.Register("block", blockingRunner(started))
+
+	queued, _ := pool.Enqueue(ctx, NewJob("block", nil, nil, ""))
+	pool.Start()
+	<-started
+
+	stopCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	if err := pool.Stop(stopCtx); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+
+	job, _ := pool.Find(ctx, queued.ID)
+	if job.Status != StatusQueued || job.Attempts != 1 || job.Progress != (Progress{}) {
+		t.Errorf("job after Stop() = %+v, want queued again", job)
+	}
+}
+
+func TestPool_StartRunsQueuedJobs(t *testing.T) {
+	ctx := context.Background()
+	store := NewInMemoryStore()
+	pool := NewPool(store, testPoolConfig())
+	pool.Register("succeed", func(ctx context.Context, job Job, progress *Reporter) (Outcome, error) {
+		return Outcome{Result: string(job.Input)}, nil
+	})
+
+	// Job abandonado por um processo anterior: volta para a fila na partida
+	abandoned := NewJob("succeed", nil, []byte("abandoned"), "")
+	store.Create(ctx, abandoned)
+	store.Claim(ctx)
+	stale := time.Now().UTC().Add(-time.Hour)
+	store.jobs[abandoned.ID] = func(job Job) Job { job.HeartbeatAt = &stale; return job }(store.jobs[abandoned.ID])
+
+	pool.Start()
+	defer pool.Stop(ctx)
+
+	queued, _ := pool.Enqueue(ctx, NewJob("succeed", nil, []byte("queued"), ""))
+
+	if job := waitForStatus(t, pool, queued.ID, StatusSucceeded); string(job.Result) != `"queued"` {
+		t.Errorf("result = %s", job.Result)
+	}
+	if job := waitForStatus(t, pool, abandoned.ID, StatusSucceeded); job.Attempts != 2 || string(job.Result) != `"abandoned"` {
+		t.Errorf("recovered job = %+v, want a second attempt with the input", job)
+	}
+}
+
+func TestPool_Purge(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "result.csv")
+
+	config := testPoolConfig()
+	config.Retention = -time.Minute
+	pool := NewPool(NewInMemoryStore(), config)
+	pool.Register("succeed", func(ctx context.Context, job Job, progress *Reporter) (Outcome, error) {
+		os.WriteFile(path, []byte("id\n"), 0o644)
+		return Outcome{ResultFile: path}, nil
+	})
+
+	queued, _ := pool.Enqueue(ctx, NewJob("succeed", nil, nil, ""))
+	pool.RunNext(ctx)
+
+	if purged, err := pool.Purge(ctx); err != nil || purged != 1 {
+		t.Fatalf("Purge() = %d, %v; want 1", purged, err)
+	}
+	if _, err := pool.Find(ctx, queued.ID); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("Find() after Purge() error = %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Purge() should remove the result file, stat error = %v", err)
+	}
+}
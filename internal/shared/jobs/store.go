@@ -0,0 +1,264 @@
+package shared_jobs
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// InterruptedError é o erro dos jobs que ficaram sem worker (processo encerrado no meio
+// da execução) mais vezes do que o limite de tentativas
+const InterruptedError = "job was interrupted too many times"
+
+// Store guarda os jobs e a fila de execução
+type Store interface {
+	Create(ctx context.Context, job Job) error
+
+	// Find busca o job pelo ID, sem o Input
+	Find(ctx context.Context, id string) (Job, error)
+
+	// Claim retira da fila o job mais antigo e o marca como em execução, contando mais
+	// uma tentativa; ok = false quando a fila está vazia. O job retornado inclui o Input.
+	Claim(ctx context.Context) (job Job, ok bool, err error)
+
+	// Heartbeat grava o andamento do job em execução e retorna se o seu cancelamento foi
+	// pedido; ErrJobFinished se o job não está mais em execução
+	Heartbeat(ctx context.Context, id string, progress Progress) (cancelRequested bool, err error)
+
+	// Finish grava o fim do job (Status, Progress, Result, ResultFile, ResultType e Error)
+	// e descarta o seu Input; ErrJobFinished se o job não está mais em execução
+	Finish(ctx context.Context, job Job) error
+
+	// Requeue devolve à fila, do início, o job interrompido pelo encerramento do worker
+	Requeue(ctx context.Context, id string) error
+
+	// Cancel cancela o job na fila, ou pede o cancelamento do job em execução ao seu worker;
+	// ErrJobFinished se o job já terminou
+	Cancel(ctx context.Context, id string) (Job, error)
+
+	// Recover devolve à fila os jobs em execução sem heartbeat desde staleBefore, cujo
+	// worker parou sem terminá-los; os que já tiveram maxAttempts tentativas falham com
+	// InterruptedError e os com cancelamento pedido são cancelados
+	Recover(ctx context.Context, staleBefore time.Time, maxAttempts int) (int, error)
+
+	// Purge remove os jobs terminados antes de before e os retorna, para que os seus
+	// arquivos de resultado sejam apagados
+	Purge(ctx context.Context, before time.Time) ([]Job, error)
+}
+
+// InMemoryStore guarda os jobs em memória; adequado para uma única instância e para testes.
+// Os jobs não sobrevivem ao reinício do processo.
+type InMemoryStore struct {
+	mu   sync.Mutex
+	jobs map[string]Job
+}
+
+func NewInMemoryStore() *InMemoryStore {
+	return &InMemoryStore{jobs: make(map[string]Job)}
+}
+
+func (s *InMemoryStore) Create(ctx context.Context, job Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.jobs[job.ID] = job
+	return nil
+}
+
+func (s *InMemoryStore) Find(ctx context.Context, id string) (Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, exists := s.jobs[id]
+	if !exists {
+		return Job{}, ErrJobNotFound
+	}
+
+	job.Input = nil
+	return job, nil
+}
+
+func (s *InMemoryStore) Claim(ctx context.Context) (Job, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var queued []Job
+	for _, job := range s.jobs {
+		if job.Status == StatusQueued {
+			queued = append(queued, job)
+		}
+	}
+	if len(queued) == 0 {
+		return Job{}, false, nil
+	}
+
+	sort.Slice(queued, func(i, j int) bool {
+		if !queued[i].CreatedAt.Equal(queued[j].CreatedAt) {
+			return queued[i].CreatedAt.Before(queued[j].CreatedAt)
+		}
+		return queued[i].ID < queued[j].ID
+	})
+
+	now := time.Now().UTC()
+	job := queued[0]
+	job.Status = StatusRunning
+	job.Attempts++
+	job.StartedAt = &now
+	job.HeartbeatAt = &now
+	job.UpdatedAt = now
+	s.jobs[job.ID] = job
+
+	return job, true, nil
+}
+
+func (s *InMemoryStore) Heartbeat(ctx context.Context, id string, progress Progress) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, err := s.running(id)
+	if err != nil {
+		return false, err
+	}
+
+	now := time.Now().UTC()
+	job.Progress = progress
+	job.HeartbeatAt = &now
+	job.UpdatedAt = now
+	s.jobs[id] = job
+
+	return job.CancelRequested, nil
+}
+
+func (s *InMemoryStore) Finish(ctx context.Context, finished Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, err := s.running(finished.ID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	job.Status = finished.Status
+	job.Progress = finished.Progress
+	job.Result = finished.Result
+	job.ResultFile = finished.ResultFile
+	job.ResultType = finished.ResultType
+	job.Error = finished.Error
+	job.Input = nil
+	job.FinishedAt = &now
+	job.UpdatedAt = now
+	s.jobs[job.ID] = job
+
+	return nil
+}
+
+func (s *InMemoryStore) Requeue(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, err := s.running(id)
+	if err != nil {
+		return err
+	}
+
+	job.requeue()
+	s.jobs[id] = job
+	return nil
+}
+
+func (s *InMemoryStore) Cancel(ctx context.Context, id string) (Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, exists := s.jobs[id]
+	if !exists {
+		return Job{}, ErrJobNotFound
+	}
+	if job.Status.Finished() {
+		return Job{}, ErrJobFinished
+	}
+
+	now := time.Now().UTC()
+	job.CancelRequested = true
+	job.UpdatedAt = now
+	if job.Status == StatusQueued {
+		job.Status = StatusCanceled
+		job.Input = nil
+		job.FinishedAt = &now
+	}
+	s.jobs[id] = job
+
+	job.Input = nil
+	return job, nil
+}
+
+func (s *InMemoryStore) Recover(ctx context.Context, staleBefore time.Time, maxAttempts int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	recovered := 0
+	for id, job := range s.jobs {
+		if job.Status != StatusRunning || job.HeartbeatAt == nil || !job.HeartbeatAt.Before(staleBefore) {
+			continue
+		}
+
+		now := time.Now().UTC()
+		switch {
+		case job.CancelRequested:
+			job.Status = StatusCanceled
+		case job.Attempts >= maxAttempts:
+			job.Status, job.Error = StatusFailed, InterruptedError
+		default:
+			job.requeue()
+			s.jobs[id] = job
+			recovered++
+			continue
+		}
+
+		job.Input = nil
+		job.FinishedAt = &now
+		job.UpdatedAt = now
+		s.jobs[id] = job
+		recovered++
+	}
+
+	return recovered, nil
+}
+
+func (s *InMemoryStore) Purge(ctx context.Context, before time.Time) ([]Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var purged []Job
+	for id, job := range s.jobs {
+		if job.FinishedAt != nil && job.FinishedAt.Before(before) {
+			purged = append(purged, job)
+			delete(s.jobs, id)
+		}
+	}
+
+	return purged, nil
+}
+
+// running retorna o job em execução; chamado com o lock
+func (s *InMemoryStore) running(id string) (Job, error) {
+	job, exists := s.jobs[id]
+	if !exists {
+		return Job{}, ErrJobNotFound
+	}
+	if job.Status != StatusRunning {
+		return Job{}, ErrJobFinished
+	}
+	return job, nil
+}
+
+// requeue devolve o job à fila, descartando o andamento da execução interrompida
+func (j *Job) requeue() {
+	j.Status = StatusQueued
+	j.Progress = Progress{}
+	j.StartedAt = nil
+	j.HeartbeatAt = nil
+	j.UpdatedAt = time.Now().UTC()
+}
@@ -0,0 +1,136 @@
+package shared_jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestInMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewInMemoryStore()
+
+	first := NewJob("product.import", nil, []byte("name,sku"), "")
+	second := NewJob("product.export", nil, nil, "")
+	second.CreatedAt = first.CreatedAt.Add(time.Second)
+	store.Create(ctx, second)
+	store.Create(ctx, first)
+
+	t.Run("find hides the input", func(t *testing.T) {
+		job, err := store.Find(ctx, first.ID)
+		if err != nil || job.Input != nil || job.Status != StatusQueued {
+			t.Errorf("Find() = %+v, %v", job, err)
+		}
+		if _, err := store.Find(ctx, "missing"); !errors.Is(err, ErrJobNotFound) {
+			t.Errorf("Find(missing) error = %v, want ErrJobNotFound", err)
+		}
+	})
+
+	t.Run("claim takes the oldest job with its input", func(t *testing.T) {
+		job, ok, err := store.Claim(ctx)
+		if err != nil || !ok || job.ID != first.ID {
+			t.Fatalf("Claim() = %s, %v, %v; want %s", job.ID, ok, err, first.ID)
+		}
+		if job.Status != StatusRunning || job.Attempts != 1 || string(job.Input) != "name,sku" || job.StartedAt == nil {
+			t.Errorf("Claim() = %+v", job)
+		}
+	})
+
+	t.Run("heartbeat saves the progress", func(t *testing.T) {
+		canceled, err := store.Heartbeat(ctx, first.ID, Progress{Processed: 5, Total: 10})
+		if err != nil || canceled {
+			t.Fatalf("Heartbeat() = %v, %v", canceled, err)
+		}
+		job, _ := store.Find(ctx, first.ID)
+		if job.Progress != (Progress{Processed: 5, Total: 10}) {
+			t.Errorf("Progress = %+v", job.Progress)
+		}
+	})
+
+	t.Run("cancel of a running job is a request", func(t *testing.T) {
+		job, err := store.Cancel(ctx, first.ID)
+		if err != nil || job.Status != StatusRunning || !job.CancelRequested {
+			t.Fatalf("Cancel() = %+v, %v", job, err)
+		}
+		if canceled, _ := store.Heartbeat(ctx, first.ID, Progress{}); !canceled {
+			t.Error("Heartbeat() should report the cancel request")
+		}
+	})
+
+	t.Run("finish", func(t *testing.T) {
+		finished := first
+		finished.Status, finished.Error = StatusCanceled, "context canceled"
+		if err := store.Finish(ctx, finished); err != nil {
+			t.Fatalf("Finish() error = %v", err)
+		}
+
+		job, _ := store.Find(ctx, first.ID)
+		if job.Status != StatusCanceled || job.FinishedAt == nil {
+			t.Errorf("Find() = %+v", job)
+		}
+		if err := store.Finish(ctx, finished); !errors.Is(err, ErrJobFinished) {
+			t.Errorf("Finish() twice error = %v, want ErrJobFinished", err)
+		}
+		if _, err := store.Cancel(ctx, first.ID); !errors.Is(err, ErrJobFinished) {
+			t.Errorf("Cancel() of a finished job error = %v, want ErrJobFinished", err)
+		}
+	})
+
+	t.Run("cancel of a queued job", func(t *testing.T) {
+		job, err := store.Cancel(ctx, second.ID)
+		if err != nil || job.Status != StatusCanceled || job.FinishedAt == nil {
+			t.Fatalf("Cancel() = %+v, %v", job, err)
+		}
+		if _, ok, _ := store.Claim(ctx); ok {
+			t.Error("Claim() should not take a canceled job")
+		}
+	})
+
+	t.Run("purge", func(t *testing.T) {
+		purged, err := store.Purge(ctx, time.Now().UTC().Add(time.Minute))
+		if err != nil || len(purged) != 2 {
+			t.Fatalf("Purge() = %d jobs, %v; want 2", len(purged), err)
+		}
+		if _, err := store.Find(ctx, first.ID); !errors.Is(err, ErrJobNotFound) {
+			t.Errorf("Find() after Purge() error = %v", err)
+		}
+	})
+}
+
+func TestInMemoryStore_RequeueAndRecover(t *testing.T) {
+	ctx := context.Background()
+	store := NewInMemoryStore()
+
+	job := NewJob("product.reindex", nil, nil, "")
+	store.Create(ctx, job)
+	store.Claim(ctx)
+	store.Heartbeat(ctx, job.ID, Progress{Processed: 3})
+
+	if err := store.Requeue(ctx, job.ID); err != nil {
+		t.Fatalf("Requeue() error = %v", err)
+	}
+	requeued, _ := store.Find(ctx, job.ID)
+	if requeued.Status != StatusQueued || requeued.Progress != (Progress{}) || requeued.Attempts != 1 {
+		t.Errorf("Requeue() = %+v", requeued)
+	}
+
+	// Execução abandonada: heartbeat antigo
+	store.Claim(ctx)
+	if recovered, _ := store.Recover(ctx, time.Now().UTC().Add(-time.Minute), 3); recovered != 0 {
+		t.Errorf("Recover() = %d, want 0 for a recent heartbeat", recovered)
+	}
+	if recovered, _ := store.Recover(ctx, time.Now().UTC().Add(time.Minute), 3); recovered != 1 {
+		t.Errorf("Recover() = %d, want 1", recovered)
+	}
+	if recovered, _ := store.Find(ctx, job.ID); recovered.Status != StatusQueued {
+		t.Errorf("recovered job status = %s, want queued", recovered.Status)
+	}
+
+	// Sem tentativas restantes, o job falha
+	store.Claim(ctx)
+	store.Recover(ctx, time.Now().UTC().Add(time.Minute), 3)
+	if failed, _ := store.Find(ctx, job.ID); failed.Status != StatusFailed || failed.Error != InterruptedError || failed.Attempts != 3 {
+		t.Errorf("recovered job = %+v, want failed after 3 attempts", failed)
+	}
+}